@@ -3,39 +3,160 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/alecthomas/kingpin"
 	"github.com/thorstenhirsch/gitbatch/internal/app"
+	"github.com/thorstenhirsch/gitbatch/internal/askpass"
 	"github.com/thorstenhirsch/gitbatch/internal/tui"
 )
 
 var version = "dev"
 
 func main() {
+	// git and ssh start gitbatch as their askpass helper with the prompt as
+	// the only argument; answer it before any flag parsing happens.
+	if len(os.Args) > 1 && os.Args[1] == "--askpass" {
+		os.Exit(askpass.Main(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	kingpin.Version("gitbatch " + version)
 	tui.Version = version
 
 	dirs := kingpin.Flag("directory", "Directory(s) to roam for git repositories.").Short('d').Strings()
-	mode := kingpin.Flag("mode", "Operation mode: fetch, pull, merge, rebase, push.").Short('m').String()
+	mode := kingpin.Flag("mode", "Operation mode: fetch, pull, merge, rebase, push, a composite job, or auto to pick pull or push from the workspace after the startup fetch.").Short('m').String()
 	recursionDepth := kingpin.Flag("recursive-depth", "Find directories recursively.").Default("0").Short('r').Int()
 	quick := kingpin.Flag("quick", "Runs without gui and fetches/pull remote upstream.").Short('q').Bool()
 	trace := kingpin.Flag("trace", "Trace application events to gitbatch.log").Short('t').Bool()
+	includeRemotes := kingpin.Flag("include-remote", "Only load repositories with a remote matching this host/org pattern (repeatable).").Strings()
+	excludeRemotes := kingpin.Flag("exclude-remote", "Skip repositories with a remote matching this host/org pattern (repeatable).").Strings()
+	imports := kingpin.Flag("import", "Load repositories listed in a repo XML, vcstool .repos, gita repos.csv or gitbatch manifest (repeatable).").Strings()
+	importFormat := kingpin.Flag("import-format", "Manifest format: repo, vcstool, gita, gitbatch. Detected from the file extension by default.").String()
+	cloneMissing := kingpin.Flag("clone-missing", "Clone manifest repositories that are not checked out yet.").Bool()
+	noVerify := kingpin.Flag("no-verify", "Skip pre-commit, commit-msg, pre-merge-commit and pre-push hooks in all repositories.").Bool()
+	noFetch := kingpin.Flag("no-fetch", "Start with the local state of the repositories and fetch only when a job needs it.").Bool()
+	chaos := kingpin.Flag("chaos", "Delay queued git commands at random and fail some fetches, pulls and pushes with network errors, to test against a flaky network.").Bool()
+	sortOrder := kingpin.Flag("sort", "Order the overview starts in: name, time, size or behind.").Enum("name", "time", "size", "behind")
+	filter := kingpin.Flag("filter", "Start with only the dirty, behind or failed repositories, or all of them.").Enum("all", "dirty", "behind", "failed")
+	export := kingpin.Flag("export", "Write a YAML or JSON manifest of the discovered repositories to this file (- for stdout) and exit.").String()
+	configFile := kingpin.Flag("config", "Read the configuration from this file instead of the default location.").String()
+
+	kingpin.Command("run", "Start gitbatch (default).").Default()
+	configCmd := kingpin.Command("config", "Share gitbatch configurations.")
+	configExport := configCmd.Command("export", "Write the effective configuration as YAML, without the scanned paths.")
+	configExportTarget := configExport.Arg("file", "Target file, - for stdout.").Default("-").String()
+	configImport := configCmd.Command("import", "Merge a shared configuration into the active configuration file.")
+	configImportSource := configImport.Arg("file", "Configuration file to import.").Required().ExistingFile()
+	doctor := kingpin.Command("doctor", "Check git, lazygit, ssh-agent, credential helpers and the terminal.")
+	agentCmd := kingpin.Command("agent", "Fetch the repositories periodically in the background and write a status file.")
+	agentInterval := agentCmd.Flag("interval", "Time between two runs, e.g. 15m. Defaults to agent_interval from the configuration.").Duration()
+	agentOnce := agentCmd.Flag("once", "Fetch once, write the status file and exit.").Bool()
+	statusCmd := kingpin.Command("status", "Print the state of the repositories without fetching, from the agent status file when it is fresh.")
+	statusShort := statusCmd.Flag("short", "Print a one-line summary like \"↓3 ⚠2 ✗1\" for shell prompts and tmux status lines.").Bool()
+	changesCmd := kingpin.Command("changes", "Print the commits and their authors per repository since a date or the last gitbatch run.")
+	changesSince := changesCmd.Flag("since", "A date like 2026-10-01, a time in RFC 3339 or a period like 7d, 2w or 36h. Defaults to the start of the last run.").String()
+	changesCommits := changesCmd.Flag("commits", "List the commits as well.").Bool()
+	authorsCmd := kingpin.Command("authors", "Print the commits per author across the repositories, counted with git shortlog.")
+	authorsSince := authorsCmd.Flag("since", "A date like 2026-10-01, a time in RFC 3339 or a period like 7d, 2w or 36h.").Default("30d").String()
+	authorsCSV := authorsCmd.Flag("csv", "Write author,repository,commits rows to this file (- for stdout) instead.").String()
+	freezeCmd := kingpin.Command("freeze", "List the commits that reached protected branches after a freeze date, per their reflog, for release audits. Exits with 1 when there are any.")
+	freezeDate := freezeCmd.Arg("date", "The freeze: a date like 2026-10-01, a time in RFC 3339 or a period like 7d.").Required().String()
+	freezeBranches := freezeCmd.Flag("branch", "Branch or glob to check instead of the branches of branch_protection (repeatable).").Strings()
+	replayCmd := kingpin.Command("replay", "Replay the work status transitions of a trace log against the current state machine.").Hidden()
+	replayTrace := replayCmd.Arg("trace", "gitbatch.log or gitbatch.jsonl written with --trace.").Required().ExistingFile()
 
-	kingpin.Parse()
+	command := kingpin.Parse()
+	app.SetConfigFile(*configFile)
 
-	if err := run(*dirs, *recursionDepth, *quick, *mode, *trace); err != nil {
+	switch command {
+	case configExport.FullCommand():
+		if err := app.ExportConfig(*configExportTarget); err != nil {
+			fmt.Fprintf(os.Stderr, "could not export the configuration: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case configImport.FullCommand():
+		if err := app.ImportConfig(*configImportSource); err != nil {
+			fmt.Fprintf(os.Stderr, "could not import the configuration: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case doctor.FullCommand():
+		if err := app.Doctor(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "doctor: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case statusCmd.FullCommand():
+		if err := printStatus(*dirs, *recursionDepth, *includeRemotes, *excludeRemotes, *imports, *importFormat, *statusShort); err != nil {
+			fmt.Fprintf(os.Stderr, "status: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case changesCmd.FullCommand():
+		if err := printChanges(*dirs, *recursionDepth, *includeRemotes, *excludeRemotes, *imports, *importFormat, *changesSince, *changesCommits); err != nil {
+			fmt.Fprintf(os.Stderr, "changes: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case authorsCmd.FullCommand():
+		if err := printAuthors(*dirs, *recursionDepth, *includeRemotes, *excludeRemotes, *imports, *importFormat, *authorsSince, *authorsCSV); err != nil {
+			fmt.Fprintf(os.Stderr, "authors: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case freezeCmd.FullCommand():
+		commits, err := printFreeze(*dirs, *recursionDepth, *includeRemotes, *excludeRemotes, *imports, *importFormat, *freezeDate, *freezeBranches)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "freeze: %v\n", err)
+			os.Exit(1)
+		}
+		if commits > 0 {
+			os.Exit(1)
+		}
+		return
+	case replayCmd.FullCommand():
+		anomalies, err := app.Replay(*replayTrace, os.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+			os.Exit(1)
+		}
+		if anomalies > 0 {
+			os.Exit(1)
+		}
+		return
+	case agentCmd.FullCommand():
+		if err := runAgent(*dirs, *recursionDepth, *trace, *includeRemotes, *excludeRemotes, *imports, *importFormat, *agentInterval, *agentOnce); err != nil {
+			fmt.Fprintf(os.Stderr, "agent: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := run(*dirs, *recursionDepth, *quick, *mode, *trace, *includeRemotes, *excludeRemotes, *imports, *importFormat, *cloneMissing, *noVerify, *noFetch, *chaos, *sortOrder, *filter, *export); err != nil {
 		fmt.Fprintf(os.Stderr, "application quit with an unhandled error: %v", err)
 		os.Exit(1)
 	}
 }
 
-func run(dirs []string, depth int, quick bool, mode string, trace bool) error {
+func run(dirs []string, depth int, quick bool, mode string, trace bool, includeRemotes, excludeRemotes, imports []string, importFormat string, cloneMissing, noVerify, noFetch, chaos bool, sortOrder, filter, export string) error {
 	app, err := app.New(&app.Config{
-		Directories: dirs,
-		Depth:       depth,
-		QuickMode:   quick,
-		Mode:        mode,
-		Trace:       trace,
+		Directories:    dirs,
+		Depth:          depth,
+		QuickMode:      quick,
+		Mode:           mode,
+		Trace:          trace,
+		IncludeRemotes: includeRemotes,
+		ExcludeRemotes: excludeRemotes,
+		Imports:        imports,
+		ImportFormat:   importFormat,
+		CloneMissing:   cloneMissing,
+		NoVerify:       noVerify,
+		NoFetch:        noFetch,
+		Chaos:          chaos,
+		Sort:           sortOrder,
+		Filter:         filter,
+		Export:         export,
 	})
 	if err != nil {
 		return err
@@ -43,3 +164,84 @@ func run(dirs []string, depth int, quick bool, mode string, trace bool) error {
 
 	return app.Run()
 }
+
+func runAgent(dirs []string, depth int, trace bool, includeRemotes, excludeRemotes, imports []string, importFormat string, interval time.Duration, once bool) error {
+	app, err := app.New(&app.Config{
+		Directories:    dirs,
+		Depth:          depth,
+		Trace:          trace,
+		IncludeRemotes: includeRemotes,
+		ExcludeRemotes: excludeRemotes,
+		Imports:        imports,
+		ImportFormat:   importFormat,
+	})
+	if err != nil {
+		return err
+	}
+
+	return app.RunAgent(interval, once)
+}
+
+func printStatus(dirs []string, depth int, includeRemotes, excludeRemotes, imports []string, importFormat string, short bool) error {
+	app, err := app.New(&app.Config{
+		Directories:    dirs,
+		Depth:          depth,
+		IncludeRemotes: includeRemotes,
+		ExcludeRemotes: excludeRemotes,
+		Imports:        imports,
+		ImportFormat:   importFormat,
+	})
+	if err != nil {
+		return err
+	}
+
+	return app.PrintStatus(os.Stdout, short)
+}
+
+func printChanges(dirs []string, depth int, includeRemotes, excludeRemotes, imports []string, importFormat, since string, commits bool) error {
+	app, err := app.New(&app.Config{
+		Directories:    dirs,
+		Depth:          depth,
+		IncludeRemotes: includeRemotes,
+		ExcludeRemotes: excludeRemotes,
+		Imports:        imports,
+		ImportFormat:   importFormat,
+	})
+	if err != nil {
+		return err
+	}
+
+	return app.PrintChanges(os.Stdout, since, commits)
+}
+
+func printAuthors(dirs []string, depth int, includeRemotes, excludeRemotes, imports []string, importFormat, since, csvFile string) error {
+	app, err := app.New(&app.Config{
+		Directories:    dirs,
+		Depth:          depth,
+		IncludeRemotes: includeRemotes,
+		ExcludeRemotes: excludeRemotes,
+		Imports:        imports,
+		ImportFormat:   importFormat,
+	})
+	if err != nil {
+		return err
+	}
+
+	return app.PrintAuthors(os.Stdout, since, csvFile)
+}
+
+func printFreeze(dirs []string, depth int, includeRemotes, excludeRemotes, imports []string, importFormat, freeze string, branches []string) (int, error) {
+	app, err := app.New(&app.Config{
+		Directories:    dirs,
+		Depth:          depth,
+		IncludeRemotes: includeRemotes,
+		ExcludeRemotes: excludeRemotes,
+		Imports:        imports,
+		ImportFormat:   importFormat,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return app.PrintFreeze(os.Stdout, freeze, branches)
+}
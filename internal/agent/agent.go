@@ -0,0 +1,158 @@
+// Package agent fetches repositories in the background and records their
+// state in a status file, which the TUI and shell prompts read on startup
+// instead of contacting every remote again.
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// Options configures Run.
+type Options struct {
+	// Directories returns the repositories to fetch. It is called before every
+	// run so that new checkouts are picked up.
+	Directories func() []string
+	Interval    time.Duration
+	StatusFile  string
+	// Once stops after the first run.
+	Once bool
+	// Log receives one line per run; nil discards it.
+	Log io.Writer
+}
+
+// Run fetches all repositories and writes the status file every interval
+// until ctx is cancelled.
+func Run(ctx context.Context, options Options) error {
+	if options.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	if options.Log == nil {
+		options.Log = io.Discard
+	}
+	a := &agent{repos: make(map[string]*git.Repository)}
+	for {
+		start := time.Now()
+		status := &Status{
+			Interval:     options.Interval.String(),
+			Repositories: a.fetchAll(ctx, options.Directories()),
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		status.Updated = time.Now()
+		if err := WriteStatus(options.StatusFile, status); err != nil {
+			return err
+		}
+		fmt.Fprintf(options.Log, "%s: fetched %d repositories in %s\n", status.Updated.Format(time.RFC3339), len(status.Repositories), time.Since(start).Round(time.Millisecond))
+		if options.Once {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(options.Interval):
+		}
+	}
+}
+
+// agent keeps the repositories between runs; every initialized repository
+// owns event queue goroutines, so they are refreshed instead of recreated.
+type agent struct {
+	mu    sync.Mutex
+	repos map[string]*git.Repository
+}
+
+func (a *agent) fetchAll(ctx context.Context, dirs []string) []RepositoryStatus {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0)*4)
+	results := make([]RepositoryStatus, len(dirs))
+	for i, dir := range dirs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, dir string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = a.fetch(ctx, dir)
+		}(i, dir)
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	return results
+}
+
+func (a *agent) fetch(ctx context.Context, dir string) RepositoryStatus {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	status := RepositoryStatus{Path: dir, Name: filepath.Base(dir)}
+	r, err := a.repository(dir)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	// Local repositories have nothing to fetch but still count as dirty.
+	if r.HasRemote() {
+		if _, err := command.FetchWithContext(ctx, r, &command.FetchOptions{}); err != nil {
+			status.Error = git.NormalizeGitErrorMessage(err.Error())
+			return status
+		}
+		status.Fetched = time.Now()
+	}
+	inspect(r, &status)
+	return status
+}
+
+// Inspect returns the status of the repository in dir without fetching it.
+func Inspect(dir string) RepositoryStatus {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	status := RepositoryStatus{Path: dir, Name: filepath.Base(dir)}
+	r, err := git.InitializeRepo(dir)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	inspect(r, &status)
+	return status
+}
+
+func inspect(r *git.Repository, status *RepositoryStatus) {
+	if r.State.Branch != nil {
+		r.RefreshBranchCounts()
+		status.Branch = r.State.Branch.Name
+		status.Ahead, _ = strconv.Atoi(r.State.Branch.Pushables)
+		status.Behind, _ = strconv.Atoi(r.State.Branch.Pullables)
+	}
+	if worktree, err := r.GetWorkTreeStatus(); err == nil {
+		status.Dirty = !worktree.Clean
+	}
+}
+
+func (a *agent) repository(dir string) (*git.Repository, error) {
+	a.mu.Lock()
+	r, ok := a.repos[dir]
+	a.mu.Unlock()
+	if ok {
+		return r, r.Refresh()
+	}
+	r, err := git.InitializeRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	a.repos[dir] = r
+	a.mu.Unlock()
+	return r, nil
+}
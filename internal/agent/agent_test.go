@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	_, err := command.Run(dir, "git", args)
+	require.NoError(t, err, "git %v", args)
+}
+
+func TestRunWritesTheStatusOfFetchedRepositories(t *testing.T) {
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	repo := filepath.Join(root, "repo")
+	other := filepath.Join(root, "other")
+	local := filepath.Join(root, "local")
+	for _, dir := range []string{remote, local} {
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+	}
+	runGit(t, remote, "init", "--bare", "--initial-branch=main")
+	runGit(t, local, "init", "--initial-branch=main")
+	runGit(t, root, "clone", remote, repo)
+	for _, dir := range []string{repo, local} {
+		runGit(t, dir, "config", "user.email", "test@example.com")
+		runGit(t, dir, "config", "user.name", "Test User")
+		runGit(t, dir, "commit", "--allow-empty", "-m", "initial commit")
+	}
+	runGit(t, repo, "push", "-u", "origin", "main")
+	runGit(t, root, "clone", remote, other)
+	runGit(t, other, "config", "user.email", "test@example.com")
+	runGit(t, other, "config", "user.name", "Test User")
+	runGit(t, other, "commit", "--allow-empty", "-m", "incoming")
+	runGit(t, other, "push")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "new.txt"), []byte("x"), 0o644))
+
+	statusFile := filepath.Join(root, "cache", "status.json")
+	err := Run(context.Background(), Options{
+		Directories: func() []string { return []string{repo, local} },
+		Interval:    time.Minute,
+		StatusFile:  statusFile,
+		Once:        true,
+	})
+	require.NoError(t, err)
+
+	status, err := ReadStatus(statusFile)
+	require.NoError(t, err)
+	assert.Equal(t, "1m0s", status.Interval)
+	assert.True(t, status.Fresh(status.Updated.Add(2*time.Minute)))
+	assert.False(t, status.Fresh(status.Updated.Add(3*time.Minute)))
+
+	entry, ok := status.Repository(repo)
+	require.True(t, ok)
+	assert.Equal(t, "main", entry.Branch)
+	assert.Equal(t, 1, entry.Behind)
+	assert.Equal(t, 0, entry.Ahead)
+	assert.True(t, entry.Dirty)
+	assert.False(t, entry.Fetched.IsZero())
+	assert.Empty(t, entry.Error)
+
+	entry, ok = status.Repository(local)
+	require.True(t, ok)
+	assert.Empty(t, entry.Error, "local repositories are not fetched")
+	assert.True(t, entry.Fetched.IsZero())
+}
+
+func TestRunStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	statusFile := filepath.Join(t.TempDir(), "status.json")
+	runs := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, Options{
+			Directories: func() []string {
+				runs++
+				return nil
+			},
+			Interval:   time.Hour,
+			StatusFile: statusFile,
+		})
+	}()
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(statusFile)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, 1, runs)
+}
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Status is the content of the status file written after every agent run.
+type Status struct {
+	// Updated is when the run finished.
+	Updated time.Time `json:"updated"`
+	// Interval is the time between runs, e.g. "15m0s".
+	Interval     string             `json:"interval"`
+	Repositories []RepositoryStatus `json:"repositories"`
+}
+
+// RepositoryStatus is the state of one repository after the agent fetched it.
+type RepositoryStatus struct {
+	Path    string    `json:"path"`
+	Name    string    `json:"name"`
+	Branch  string    `json:"branch,omitempty"`
+	Ahead   int       `json:"ahead"`
+	Behind  int       `json:"behind"`
+	Dirty   bool      `json:"dirty"`
+	Fetched time.Time `json:"fetched"`
+	// Error is set when the repository could not be fetched.
+	Error string `json:"error,omitempty"`
+}
+
+// DefaultStatusFile returns the status file in the user's cache directory.
+func DefaultStatusFile() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "gitbatch", "status.json")
+}
+
+// ReadStatus reads a status file.
+func ReadStatus(path string) (*Status, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Status
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// WriteStatus replaces the status file. The file is written next to the old
+// one and renamed, so readers never see half of it.
+func WriteStatus(path string, s *Status) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".status-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Fresh reports whether the status is recent enough to be trusted instead of
+// fetching again. One late run is tolerated: the status stays fresh for two
+// intervals.
+func (s *Status) Fresh(now time.Time) bool {
+	if s == nil {
+		return false
+	}
+	interval, err := time.ParseDuration(s.Interval)
+	if err != nil || interval <= 0 {
+		return false
+	}
+	return now.Sub(s.Updated) <= 2*interval
+}
+
+// Repository returns the status of the repository at path.
+func (s *Status) Repository(path string) (RepositoryStatus, bool) {
+	if s == nil {
+		return RepositoryStatus{}, false
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	for _, repo := range s.Repositories {
+		if repo.Path == path {
+			return repo, true
+		}
+	}
+	return RepositoryStatus{}, false
+}
@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Summary counts the repositories of a workspace that need attention.
+type Summary struct {
+	// Behind counts repositories with incoming commits.
+	Behind int
+	// Ahead counts repositories with commits to push.
+	Ahead int
+	// Dirty counts repositories with local changes.
+	Dirty int
+	// Failed counts repositories that could not be fetched or read.
+	Failed int
+}
+
+// Summarize counts the repositories in repos.
+func Summarize(repos []RepositoryStatus) Summary {
+	var s Summary
+	for _, repo := range repos {
+		if repo.Error != "" {
+			s.Failed++
+			continue
+		}
+		if repo.Behind > 0 {
+			s.Behind++
+		}
+		if repo.Ahead > 0 {
+			s.Ahead++
+		}
+		if repo.Dirty {
+			s.Dirty++
+		}
+	}
+	return s
+}
+
+// Short formats the summary for shell prompts and status lines, e.g.
+// "↓3 ⚠2 ✗1". Counts of zero are left out, so an up-to-date workspace gives
+// an empty string.
+func (s Summary) Short() string {
+	parts := make([]string, 0, 4)
+	for _, part := range []struct {
+		symbol string
+		count  int
+	}{{"↓", s.Behind}, {"↑", s.Ahead}, {"⚠", s.Dirty}, {"✗", s.Failed}} {
+		if part.count > 0 {
+			parts = append(parts, fmt.Sprintf("%s%d", part.symbol, part.count))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Flags formats the state of one repository like Short, with commit counts
+// instead of repository counts.
+func (r RepositoryStatus) Flags() string {
+	if r.Error != "" {
+		return "✗ " + r.Error
+	}
+	parts := make([]string, 0, 3)
+	if r.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("↓%d", r.Behind))
+	}
+	if r.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("↑%d", r.Ahead))
+	}
+	if r.Dirty {
+		parts = append(parts, "⚠")
+	}
+	if len(parts) == 0 {
+		return "✓"
+	}
+	return strings.Join(parts, " ")
+}
+
+// Workspace returns the status of the repositories in dirs, sorted by path.
+// Repositories covered by a fresh agent status are taken from it, the others
+// are inspected locally without fetching.
+func Workspace(dirs []string, status *Status, now time.Time) []RepositoryStatus {
+	if !status.Fresh(now) {
+		status = nil
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0)*4)
+	results := make([]RepositoryStatus, len(dirs))
+	for i, dir := range dirs {
+		if cached, ok := status.Repository(dir); ok {
+			results[i] = cached
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, dir string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = Inspect(dir)
+		}(i, dir)
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	return results
+}
@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryShort(t *testing.T) {
+	summary := Summarize([]RepositoryStatus{
+		{Behind: 2, Dirty: true},
+		{Behind: 1},
+		{Ahead: 1, Behind: 4},
+		{Dirty: true},
+		{Error: "could not read Username", Behind: 3},
+		{},
+	})
+	assert.Equal(t, Summary{Behind: 3, Ahead: 1, Dirty: 2, Failed: 1}, summary)
+	assert.Equal(t, "↓3 ↑1 ⚠2 ✗1", summary.Short())
+	assert.Empty(t, Summary{}.Short())
+
+	assert.Equal(t, "↓4 ↑1", RepositoryStatus{Ahead: 1, Behind: 4}.Flags())
+	assert.Equal(t, "✗ timeout", RepositoryStatus{Error: "timeout", Dirty: true}.Flags())
+	assert.Equal(t, "✓", RepositoryStatus{}.Flags())
+}
+
+func TestWorkspaceUsesFreshStatusAndInspectsTheRest(t *testing.T) {
+	root := t.TempDir()
+	cached := filepath.Join(root, "cached")
+	local := filepath.Join(root, "local")
+	for _, dir := range []string{cached, local} {
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		runGit(t, dir, "init", "--initial-branch=main")
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(local, "new.txt"), []byte("x"), 0o644))
+	now := time.Now()
+	status := &Status{
+		Updated:      now,
+		Interval:     "15m0s",
+		Repositories: []RepositoryStatus{{Path: cached, Name: "cached", Branch: "main", Behind: 2}},
+	}
+
+	repos := Workspace([]string{local, cached}, status, now)
+	require.Len(t, repos, 2)
+	assert.Equal(t, 2, repos[0].Behind, "taken from the agent status")
+	assert.Equal(t, local, repos[1].Path)
+	assert.True(t, repos[1].Dirty)
+
+	repos = Workspace([]string{cached}, status, now.Add(time.Hour))
+	assert.Zero(t, repos[0].Behind, "a stale status is not used")
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/thorstenhirsch/gitbatch/internal/agent"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// RunAgent fetches the workspaces every interval and writes the status file
+// until the process is interrupted. A zero interval uses agent_interval from
+// the configuration.
+func (a *App) RunAgent(interval time.Duration, once bool) error {
+	if interval <= 0 {
+		interval = a.Config.AgentInterval
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return agent.Run(ctx, agent.Options{
+		Directories: a.agentDirectories,
+		Interval:    interval,
+		StatusFile:  a.Config.AgentStatusFile,
+		Once:        once,
+		Log:         os.Stderr,
+	})
+}
+
+// agentDirectories discovers the repositories again for every run so that new
+// checkouts are fetched as well. Pinned repositories are left alone.
+func (a *App) agentDirectories() []string {
+	dirs := a.workspaceDirectories()
+	unpinned := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if !command.Pinned(&git.Repository{AbsPath: dir}) {
+			unpinned = append(unpinned, dir)
+		}
+	}
+	return unpinned
+}
+
+// workspaceDirectories discovers the repositories of the workspace without
+// loading them. Missing manifest entries are not cloned.
+func (a *App) workspaceDirectories() []string {
+	dirs, _ := discoverDirectories(append([]string(nil), a.workspaces...), a.Config.Depth, a.Config.Nested)
+	if len(a.Config.Imports) > 0 {
+		imported, err := importManifests(a.Config.Imports, a.Config.ImportFormat, false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not import manifests: %s\n", err)
+		} else {
+			dirs = mergeDirectories(dirs, imported)
+		}
+	}
+	dirs, _ = collapseRepositories(dirs, a.Config.Nested)
+	return filterDirectoriesByRemote(dirs, a.Config.IncludeRemotes, a.Config.ExcludeRemotes)
+}
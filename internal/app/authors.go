@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+)
+
+// authorSummary is the work of an author across the workspace.
+type authorSummary struct {
+	Author  string
+	Commits int
+	// Repositories are the commits per repository, most commits first.
+	Repositories []repositoryCount
+}
+
+// repositoryCount is the number of commits of an author in a repository.
+type repositoryCount struct {
+	Repository string
+	Commits    int
+}
+
+// summarizeAuthors runs git shortlog in dirs and adds the counts up per
+// author, most commits first.
+func summarizeAuthors(dirs []string, since time.Time) ([]authorSummary, error) {
+	byAuthor := make(map[string]*authorSummary)
+	for _, dir := range dirs {
+		counts, err := command.Shortlog(context.Background(), dir, since)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(dir), err)
+		}
+		for _, count := range counts {
+			summary := byAuthor[count.Author]
+			if summary == nil {
+				summary = &authorSummary{Author: count.Author}
+				byAuthor[count.Author] = summary
+			}
+			summary.Commits += count.Commits
+			summary.Repositories = append(summary.Repositories, repositoryCount{Repository: filepath.Base(dir), Commits: count.Commits})
+		}
+	}
+
+	summaries := make([]authorSummary, 0, len(byAuthor))
+	for _, summary := range byAuthor {
+		sort.SliceStable(summary.Repositories, func(i, j int) bool {
+			return summary.Repositories[i].Commits > summary.Repositories[j].Commits
+		})
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Commits != summaries[j].Commits {
+			return summaries[i].Commits > summaries[j].Commits
+		}
+		return summaries[i].Author < summaries[j].Author
+	})
+	return summaries, nil
+}
+
+// PrintAuthors prints the commits per author across the repositories of the
+// workspace since a date or period, e.g. 30d, with the repositories they
+// went to. A csv file, - for stdout, receives one author,repository,commits
+// row per author and repository instead, for spreadsheets.
+func (a *App) PrintAuthors(w io.Writer, since, csvFile string) error {
+	start, err := parseSince(since, time.Now())
+	if err != nil {
+		return err
+	}
+	dirs := a.workspaceDirectories()
+	if len(dirs) == 0 {
+		return fmt.Errorf("no git repositories found in specified directories")
+	}
+	summaries, err := summarizeAuthors(dirs, start)
+	if err != nil {
+		return err
+	}
+	if csvFile != "" {
+		return writeAuthorsCSV(w, csvFile, summaries)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	total := 0
+	for _, summary := range summaries {
+		total += summary.Commits
+		repos := make([]string, 0, len(summary.Repositories))
+		for _, repo := range summary.Repositories {
+			repos = append(repos, fmt.Sprintf("%s %d", repo.Repository, repo.Commits))
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", summary.Author, summary.Commits, strings.Join(repos, ", "))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%d commits by %d authors in %d repositories since %s\n",
+		total, len(summaries), len(dirs), start.Local().Format("2006-01-02 15:04"))
+	return err
+}
+
+// writeAuthorsCSV writes the summaries to path, or to w for "-".
+func writeAuthorsCSV(w io.Writer, path string, summaries []authorSummary) (err error) {
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		w = file
+	}
+	out := csv.NewWriter(w)
+	_ = out.Write([]string{"author", "repository", "commits"})
+	for _, summary := range summaries {
+		for _, repo := range summary.Repositories {
+			_ = out.Write([]string{summary.Author, repo.Repository, strconv.Itoa(repo.Commits)})
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintAuthors(t *testing.T) {
+	workspace := t.TempDir()
+	now := time.Now()
+	for _, name := range []string{"api", "web"} {
+		dir := filepath.Join(workspace, name)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		cmd := exec.Command("git", "init", "--initial-branch=main")
+		cmd.Dir = dir
+		require.NoError(t, cmd.Run())
+		commitAt(t, dir, "carol", now.AddDate(-1, 0, 0))
+	}
+	commitAt(t, filepath.Join(workspace, "api"), "alice", now.Add(-time.Hour))
+	commitAt(t, filepath.Join(workspace, "api"), "alice", now.Add(-time.Minute))
+	commitAt(t, filepath.Join(workspace, "web"), "alice", now.Add(-time.Minute))
+	commitAt(t, filepath.Join(workspace, "web"), "bob", now.Add(-time.Minute))
+	a := &App{Config: &Config{}, workspaces: []string{workspace}}
+
+	var out bytes.Buffer
+	require.NoError(t, a.PrintAuthors(&out, "30d", ""))
+	require.Contains(t, out.String(), "alice  3  api 2, web 1\nbob    1  web 1\n")
+	require.Contains(t, out.String(), "4 commits by 2 authors in 2 repositories since ")
+
+	out.Reset()
+	require.NoError(t, a.PrintAuthors(&out, "30d", "-"))
+	require.Equal(t, "author,repository,commits\nalice,api,2\nalice,web,1\nbob,web,1\n", out.String())
+
+	file := filepath.Join(t.TempDir(), "authors.csv")
+	require.NoError(t, a.PrintAuthors(&bytes.Buffer{}, "60w", file))
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "carol,api,1\n")
+}
@@ -3,8 +3,11 @@ package app
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/thorstenhirsch/gitbatch/internal/command"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
 	"github.com/thorstenhirsch/gitbatch/internal/tui"
 )
 
@@ -12,6 +15,10 @@ import (
 // it has only the gui.Gui pointer for interface entity.
 type App struct {
 	Config *Config
+	// workspaces are the directories given on the command line or, without
+	// any, the paths of the configuration. The agent runs from anywhere, so
+	// unlike the interface it does not default to the working directory.
+	workspaces []string
 }
 
 // Config is an assembler data to initiate a setup
@@ -21,6 +28,130 @@ type Config struct {
 	QuickMode   bool
 	Mode        string
 	Trace       bool
+	// TraceFormat writes the trace log as text or as JSON lines.
+	TraceFormat string
+	// IgnoreUntracked treats untracked files as clean when checking whether a
+	// repository has local changes.
+	IgnoreUntracked bool
+	// OnDirty selects how batch pulls handle repositories whose local changes
+	// overlap with incoming commits: skip, autostash or fail.
+	OnDirty string
+	// PruneAfterFetch removes stale remote-tracking refs whenever a fetch runs.
+	PruneAfterFetch bool
+	// PullPrune and PullTags run every pull with --prune and --tags.
+	PullPrune bool
+	PullTags  bool
+	// Nested loads repositories inside the working tree of another
+	// repository as well; otherwise they are listed as skipped.
+	Nested bool
+	// NoFetch starts the interface without fetching; repositories show
+	// their local state until a job fetches them.
+	NoFetch bool
+	// Chaos injects random delays and network failures into git commands,
+	// to test the application and the configuration against a flaky network.
+	Chaos bool
+	// Sort and Filter select the order and the repositories the overview
+	// starts with.
+	Sort   string
+	Filter string
+	// IncludeRemotes keeps only repositories with a remote matching one of
+	// these host/org patterns, e.g. "github.com/mycompany/*".
+	IncludeRemotes []string
+	// ExcludeRemotes drops repositories with a remote matching one of these
+	// host/org patterns.
+	ExcludeRemotes []string
+	// JobResultFile receives a JSON line for every completed job. A named
+	// pipe works as well.
+	JobResultFile string
+	// JobResultCmd is run through the shell after every completed job with
+	// the JSON result on stdin.
+	JobResultCmd string
+	// CredentialKeyring persists credentials entered in the prompt through
+	// the git credential helper (usually the OS keyring) once they worked.
+	CredentialKeyring bool
+	// RemoveStaleLocks deletes lock files no git process holds anymore and
+	// retries the operation that failed on them, without asking.
+	RemoveStaleLocks bool
+	// RepoEnv adds environment variables to the git commands of matching
+	// repositories or directory groups.
+	RepoEnv []command.RepoEnvRule
+	// NoVerify runs the jobs of all repositories with --no-verify.
+	NoVerify bool
+	// NoVerifyPaths selects repositories or directory groups whose jobs run
+	// with --no-verify.
+	NoVerifyPaths []string
+	// Pinned selects repositories or directory groups that are only touched
+	// manually: tag all, the startup fetch and the agent leave them out.
+	Pinned []string
+	// Confirm selects repositories or directory groups where destructive
+	// operations need the user to type a confirmation.
+	Confirm []string
+	// SparseReapply follows pulls and merges in sparse checkouts with
+	// `git sparse-checkout reapply`.
+	SparseReapply bool
+	// BisectCommand is the test command `git bisect run` executes per step.
+	BisectCommand string
+	// DiffFilter is the command diffs are piped through before they are
+	// shown, e.g. delta or diff-so-fancy.
+	DiffFilter string
+	// Icons shows Nerd Font icons for the repository state, the hosting
+	// provider and the dominant language in the overview.
+	Icons bool
+	// Language selects the locale of the interface, e.g. "de". "auto" picks
+	// it from LC_ALL, LC_MESSAGES or LANG.
+	Language string
+	// Identities lists the user.email each group of repositories has to
+	// commit with.
+	Identities []command.IdentityRule
+	// BranchProtection warns about or blocks batch commits and pushes on
+	// branches that only take changes through pull requests.
+	BranchProtection []command.BranchProtectionRule
+	// Dependencies makes repositories of a batch wait until the repositories
+	// they depend on succeeded.
+	Dependencies []command.DependencyRule
+	// DetectDependencies adds the local dependencies named in go.mod and
+	// package.json to Dependencies and flags the ones behind their upstream.
+	DetectDependencies bool
+	// CompositeJobs defines modes that run several git commands per
+	// repository, by name.
+	CompositeJobs map[string][]string
+	// Imports lists repo, vcstool or gita manifests whose repositories are
+	// loaded in addition to the scanned directories.
+	Imports []string
+	// ImportFormat forces the manifest format instead of detecting it from
+	// the file extension.
+	ImportFormat string
+	// CloneMissing clones manifest repositories that are not checked out yet.
+	CloneMissing bool
+	// Export writes a gitbatch manifest of the loaded repositories to this
+	// file ("-" for stdout) instead of starting the interface.
+	Export string
+	// AgentInterval is the time between two runs of `gitbatch agent`.
+	AgentInterval time.Duration
+	// AgentStatusFile is where the agent writes the state of the
+	// repositories and where the interface looks for it on startup.
+	AgentStatusFile string
+	// AuditLog records the operations gitbatch runs in a log per workspace,
+	// shown per repository in the history panel.
+	AuditLog bool
+	// RepoStats collects the size and object counts of every repository for
+	// the status panel and the size sort order.
+	RepoStats bool
+	// AtomicCheckout rolls back a multi-repo checkout in all repositories
+	// when it fails in one of them.
+	AtomicCheckout bool
+	// MergeStyle selects how merge mode records the upstream commits: ff,
+	// no-ff or squash.
+	MergeStyle string
+	// FailFast drops the rest of a batch when a job fails on credentials or
+	// an unreachable remote instead of repeating the failure everywhere.
+	FailFast bool
+	// PullRequestLinks offers the page to open a pull request after a push
+	// created a branch on the remote.
+	PullRequestLinks bool
+	// ForgeHosts names the forge, github, gitlab or bitbucket, of
+	// self-hosted remotes by host name.
+	ForgeHosts map[string]string
 }
 
 // New will handle pre-required operations. It is designed to be a wrapper for
@@ -28,7 +159,14 @@ type Config struct {
 func New(argConfig *Config) (*App, error) {
 	// initiate the app and give it initial values
 	app := &App{}
-	if len(argConfig.Directories) <= 0 {
+	explicitDirectories := len(argConfig.Directories) > 0
+	if explicitDirectories {
+		dirs, err := expandDirectories(argConfig.Directories)
+		if err != nil {
+			return nil, err
+		}
+		argConfig.Directories = dirs
+	} else {
 		d, _ := os.Getwd()
 		argConfig.Directories = []string{d}
 	}
@@ -36,26 +174,96 @@ func New(argConfig *Config) (*App, error) {
 	if err != nil {
 		return nil, err
 	}
+	app.workspaces = argConfig.Directories
+	if !explicitDirectories {
+		app.workspaces = append([]string(nil), presetConfig.Directories...)
+	}
 	app.Config = overrideConfig(presetConfig, argConfig)
 
+	if err := git.SetTraceFormat(app.Config.TraceFormat); err != nil {
+		return nil, err
+	}
 	if err := git.SetTraceLogging(app.Config.Trace); err != nil {
 		return nil, err
 	}
+	// Detect the git version once so features can fall back on old releases.
+	if _, err := git.InstalledVersion(); err != nil {
+		return nil, err
+	}
+	git.SetIgnoreUntracked(app.Config.IgnoreUntracked)
+	policy, _ := command.ParseDirtyPolicy(app.Config.OnDirty)
+	command.SetDirtyPolicy(policy)
+	mergeStyle, _ := command.ParseMergeStyle(app.Config.MergeStyle)
+	command.SetMergeStyle(mergeStyle)
+	command.SetPruneOnFetch(app.Config.PruneAfterFetch)
+	command.SetPullDefaults(app.Config.PullPrune, app.Config.PullTags)
+	command.SetJobResultHook(app.Config.JobResultFile, app.Config.JobResultCmd)
+	command.SetCredentialKeyring(app.Config.CredentialKeyring)
+	command.SetStaleLockCleanup(app.Config.RemoveStaleLocks)
+	command.SetRepoEnv(app.Config.RepoEnv)
+	command.SetIdentityRules(app.Config.Identities)
+	command.SetBranchProtection(app.Config.BranchProtection)
+	command.SetDependencies(app.Config.Dependencies)
+	git.SetDetectLocalDependencies(app.Config.DetectDependencies)
+	if err := command.SetCompositeJobs(app.Config.CompositeJobs); err != nil {
+		return nil, err
+	}
+	command.SetNoVerify(app.Config.NoVerify, app.Config.NoVerifyPaths)
+	command.SetPinned(app.Config.Pinned)
+	command.SetConfirmPaths(app.Config.Confirm)
+	command.SetSparseReapply(app.Config.SparseReapply)
+	command.SetBisectCommand(app.Config.BisectCommand)
+	command.SetDiffFilter(app.Config.DiffFilter)
+	if app.Config.AuditLog {
+		command.SetAuditLog(command.AuditLogPath(app.Config.Directories))
+	}
+	git.SetDetectLanguage(app.Config.Icons)
+	tui.SetIcons(app.Config.Icons)
+	tui.SetAgentStatusFile(app.Config.AgentStatusFile)
+	tui.SetRepoStats(app.Config.RepoStats)
+	tui.SetAtomicCheckout(app.Config.AtomicCheckout)
+	tui.SetSkipStartupFetch(app.Config.NoFetch)
+	command.SetChaos(app.Config.Chaos)
+	command.SetFailFast(app.Config.FailFast)
+	command.SetPullRequestLinks(app.Config.PullRequestLinks, app.Config.ForgeHosts)
+	if !tui.SetStartupSort(app.Config.Sort) {
+		return nil, fmt.Errorf("unknown sort %q, use name, time, size or behind", app.Config.Sort)
+	}
+	if !tui.SetStartupFilter(app.Config.Filter) {
+		return nil, fmt.Errorf("unknown filter %q, use all, dirty, behind or failed", app.Config.Filter)
+	}
+	if err := i18n.SetLocale(app.Config.Language); err != nil {
+		return nil, err
+	}
 
 	return app, nil
 }
 
 // Run starts the application.
 func (a *App) Run() error {
-	dirs := generateDirectories(a.Config.Directories, a.Config.Depth)
+	dirs, skipped := discoverDirectories(a.Config.Directories, a.Config.Depth, a.Config.Nested)
+	if len(a.Config.Imports) > 0 {
+		imported, err := importManifests(a.Config.Imports, a.Config.ImportFormat, a.Config.CloneMissing)
+		if err != nil {
+			return err
+		}
+		dirs = mergeDirectories(dirs, imported)
+	}
+	dirs, nested := collapseRepositories(dirs, a.Config.Nested)
+	skipped = append(skipped, nested...)
+	dirs = filterDirectoriesByRemote(dirs, a.Config.IncludeRemotes, a.Config.ExcludeRemotes)
 	if len(dirs) == 0 {
 		return fmt.Errorf("no git repositories found in specified directories")
 	}
+	if a.Config.Export != "" {
+		return exportWorkspace(dirs, a.Config.Export)
+	}
+	a.recordRun(time.Now())
 	if a.Config.QuickMode {
 		return a.execQuickMode(dirs)
 	}
 	// create a tui and run it
-	return tui.Run(a.Config.Mode, dirs)
+	return tui.Run(a.Config.Mode, dirs, a.Config.Directories, skipped)
 }
 
 func overrideConfig(appConfig, setupConfig *Config) *Config {
@@ -75,18 +283,55 @@ func overrideConfig(appConfig, setupConfig *Config) *Config {
 	if setupConfig.Trace {
 		appConfig.Trace = setupConfig.Trace
 	}
+	if setupConfig.IgnoreUntracked {
+		appConfig.IgnoreUntracked = setupConfig.IgnoreUntracked
+	}
+	if setupConfig.PruneAfterFetch {
+		appConfig.PruneAfterFetch = setupConfig.PruneAfterFetch
+	}
+	if setupConfig.NoVerify {
+		appConfig.NoVerify = setupConfig.NoVerify
+	}
+	if setupConfig.NoFetch {
+		appConfig.NoFetch = setupConfig.NoFetch
+	}
+	if setupConfig.Chaos {
+		appConfig.Chaos = setupConfig.Chaos
+	}
+	if len(setupConfig.Sort) > 0 {
+		appConfig.Sort = setupConfig.Sort
+	}
+	if len(setupConfig.Filter) > 0 {
+		appConfig.Filter = setupConfig.Filter
+	}
 	if len(setupConfig.Mode) > 0 {
 		appConfig.Mode = setupConfig.Mode
 	}
+	if len(setupConfig.OnDirty) > 0 {
+		appConfig.OnDirty = setupConfig.OnDirty
+	}
+	if len(setupConfig.IncludeRemotes) > 0 {
+		appConfig.IncludeRemotes = setupConfig.IncludeRemotes
+	}
+	if len(setupConfig.ExcludeRemotes) > 0 {
+		appConfig.ExcludeRemotes = setupConfig.ExcludeRemotes
+	}
+	appConfig.Imports = setupConfig.Imports
+	appConfig.ImportFormat = setupConfig.ImportFormat
+	appConfig.CloneMissing = setupConfig.CloneMissing
+	appConfig.Export = setupConfig.Export
 	return appConfig
 }
 
 func (a *App) execQuickMode(directories []string) error {
 	mode := a.Config.Mode
+	if mode == tui.AutoMode {
+		return fmt.Errorf("quick mode cannot pick the mode itself, use --mode pull or push")
+	}
 	if mode == "fetch" {
 		mode = "pull"
 	}
-	if mode != "pull" && mode != "merge" && mode != "rebase" {
+	if _, composite := command.LookupCompositeJob(mode); !composite && mode != "pull" && mode != "merge" && mode != "rebase" {
 		return fmt.Errorf("unrecognized quick mode: %s", a.Config.Mode)
 	}
 
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+)
+
+// lastRunFile is where the start of the last run in the workspace made of
+// roots is recorded.
+func lastRunFile(roots []string) string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "gitbatch", "runs", command.WorkspaceName(roots))
+}
+
+// recordRun notes now as the start of the last run in the workspace.
+func (a *App) recordRun(now time.Time) {
+	path := lastRunFile(a.workspaces)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, []byte(now.UTC().Format(time.RFC3339)+"\n"), 0o644)
+}
+
+// lastRun returns the start of the last run in the workspace.
+func (a *App) lastRun() (time.Time, error) {
+	data, err := os.ReadFile(lastRunFile(a.workspaces))
+	if os.IsNotExist(err) {
+		return time.Time{}, fmt.Errorf("gitbatch has not run in this workspace yet, use --since")
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+}
+
+// relativeSince matches periods like "3d" or "2w" that go does not parse.
+var relativeSince = regexp.MustCompile(`^(\d+)([dw])$`)
+
+// parseSince reads the start of a report: a date like 2026-10-01, a time in
+// RFC 3339, or a period before now like 7d, 2w or 36h.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if match := relativeSince.FindStringSubmatch(value); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		days := n
+		if match[2] == "w" {
+			days = 7 * n
+		}
+		return now.AddDate(0, 0, -days), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("cannot read %q, use a date like 2006-01-02, a time in RFC 3339 or a period like 7d", value)
+}
+
+// PrintChanges prints the commits made in the repositories of the workspace
+// since a time, or since the last run when since is empty: per repository
+// the number of commits and their authors and, with commits, the commits
+// themselves. Repositories without new commits are left out.
+func (a *App) PrintChanges(w io.Writer, since string, commits bool) error {
+	start, err := a.reportStart(since)
+	if err != nil {
+		return err
+	}
+	dirs := a.workspaceDirectories()
+	if len(dirs) == 0 {
+		return fmt.Errorf("no git repositories found in specified directories")
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	total, changed := 0, 0
+	var all []command.CommitSummary
+	for _, dir := range dirs {
+		found, err := command.CommitsSince(context.Background(), dir, start)
+		if err != nil {
+			fmt.Fprintf(tw, "%s\t%s\t\n", filepath.Base(dir), err)
+			continue
+		}
+		if len(found) == 0 {
+			continue
+		}
+		changed++
+		total += len(found)
+		all = append(all, found...)
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", filepath.Base(dir), len(found), formatAuthors(command.CountAuthors(found)))
+		if commits {
+			for _, commit := range found {
+				fmt.Fprintf(tw, "  %s\t%s\t%s\n", shortCommit(commit.Hash), commit.Author, commit.Subject)
+			}
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%d commits in %d of %d repositories by %d authors since %s\n",
+		total, changed, len(dirs), len(command.CountAuthors(all)), start.Local().Format("2006-01-02 15:04"))
+	return err
+}
+
+// reportStart returns the start of a report: since, or the last run.
+func (a *App) reportStart(since string) (time.Time, error) {
+	if since == "" {
+		return a.lastRun()
+	}
+	return parseSince(since, time.Now())
+}
+
+// formatAuthors lists authors with their commit counts, e.g. "alice 8, bob 4".
+func formatAuthors(authors []command.AuthorCount) string {
+	parts := make([]string, 0, len(authors))
+	for _, author := range authors {
+		parts = append(parts, fmt.Sprintf("%s %d", author.Author, author.Commits))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func shortCommit(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
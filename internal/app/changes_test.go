@@ -0,0 +1,77 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// commitAt commits a file in dir as author at date.
+func commitAt(t *testing.T, dir, author string, date time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte(author+" "+date.String()), 0o644))
+	for _, args := range [][]string{{"add", "file.txt"}, {"commit", "-m", "change by " + author}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		stamp := date.Format(time.RFC3339)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME="+author, "GIT_AUTHOR_EMAIL="+author+"@example.com", "GIT_AUTHOR_DATE="+stamp,
+			"GIT_COMMITTER_NAME="+author, "GIT_COMMITTER_EMAIL="+author+"@example.com", "GIT_COMMITTER_DATE="+stamp)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for value, want := range map[string]time.Time{
+		"7d":                   now.AddDate(0, 0, -7),
+		"2w":                   now.AddDate(0, 0, -14),
+		"36h":                  now.Add(-36 * time.Hour),
+		"2026-10-01T08:00:00Z": time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC),
+		"2026-10-01":           time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local),
+	} {
+		got, err := parseSince(value, now)
+		require.NoError(t, err, value)
+		require.True(t, want.Equal(got), "%s: %s", value, got)
+	}
+	_, err := parseSince("last tuesday", now)
+	require.Error(t, err)
+}
+
+func TestPrintChanges(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	workspace := t.TempDir()
+	now := time.Now()
+	for _, name := range []string{"api", "web", "docs"} {
+		dir := filepath.Join(workspace, name)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		cmd := exec.Command("git", "init", "--initial-branch=main")
+		cmd.Dir = dir
+		require.NoError(t, cmd.Run())
+		commitAt(t, dir, "carol", now.AddDate(0, -1, 0))
+	}
+	commitAt(t, filepath.Join(workspace, "api"), "alice", now.Add(-2*time.Hour))
+	commitAt(t, filepath.Join(workspace, "api"), "bob", now.Add(-time.Hour))
+	commitAt(t, filepath.Join(workspace, "api"), "alice", now.Add(-time.Minute))
+	commitAt(t, filepath.Join(workspace, "web"), "bob", now.Add(-time.Minute))
+	a := &App{Config: &Config{}, workspaces: []string{workspace}}
+
+	_, err := a.lastRun()
+	require.ErrorContains(t, err, "use --since")
+	a.recordRun(now.Add(-90 * time.Minute))
+
+	var out bytes.Buffer
+	require.NoError(t, a.PrintChanges(&out, "", false))
+	require.Equal(t, "api  2  alice 1, bob 1\nweb  1  bob 1\n3 commits in 2 of 3 repositories by 2 authors since "+now.Add(-90*time.Minute).Format("2006-01-02 15:04")+"\n", out.String())
+
+	out.Reset()
+	require.NoError(t, a.PrintChanges(&out, "1d", true))
+	require.Regexp(t, `api +3 +alice 2, bob 1\n  [0-9a-f]{7}  alice  change by alice\n`, out.String())
+	require.Contains(t, out.String(), "4 commits in 2 of 3 repositories by 2 authors")
+}
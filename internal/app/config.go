@@ -1,11 +1,16 @@
 package app
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/spf13/viper"
+	"github.com/thorstenhirsch/gitbatch/internal/agent"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/tui"
 )
 
 // config file stuff
@@ -29,15 +34,79 @@ var (
 
 // configuration items
 var (
-	modeKey             = "mode"
-	modeKeyDefault      = "fetch"
-	pathsKey            = "paths"
-	quickKey            = "quick"
-	quickKeyDefault     = false
-	recursionKey        = "recursion"
-	recursionKeyDefault = 1
-	traceKey            = "trace"
-	traceKeyDefault     = false
+	modeKey                   = "mode"
+	modeKeyDefault            = "fetch"
+	pathsKey                  = "paths"
+	quickKey                  = "quick"
+	quickKeyDefault           = false
+	recursionKey              = "recursion"
+	recursionKeyDefault       = 1
+	traceKey                  = "trace"
+	traceKeyDefault           = false
+	traceFormatKey            = "trace_format"
+	traceFormatDefault        = "text"
+	ignoreUntrackedKey        = "ignore_untracked"
+	ignoreUntrackedKeyDefault = false
+	onDirtyKey                = "on_dirty"
+	onDirtyKeyDefault         = "skip"
+	pruneAfterFetchKey        = "prune_after_fetch"
+	pruneAfterFetchKeyDefault = false
+	pullPruneKey              = "pull_prune"
+	pullPruneDefault          = false
+	pullTagsKey               = "pull_tags"
+	pullTagsDefault           = false
+	nestedKey                 = "nested_repositories"
+	nestedDefault             = false
+	noFetchKey                = "no_fetch"
+	noFetchDefault            = false
+	sortKey                   = "sort"
+	sortDefault               = "name"
+	filterKey                 = "filter"
+	filterDefault             = "all"
+	includeRemotesKey         = "include_remotes"
+	excludeRemotesKey         = "exclude_remotes"
+	jobResultFileKey          = "job_result_file"
+	jobResultCommandKey       = "job_result_command"
+	credentialKeyringKey      = "credential_keyring"
+	credentialKeyringDefault  = false
+	removeStaleLocksKey       = "remove_stale_locks"
+	removeStaleLocksDefault   = false
+	noVerifyKey               = "no_verify"
+	noVerifyDefault           = false
+	noVerifyPathsKey          = "no_verify_paths"
+	pinnedKey                 = "pinned"
+	confirmKey                = "confirm"
+	sparseReapplyKey          = "sparse_reapply"
+	sparseReapplyDefault      = false
+	bisectCommandKey          = "bisect_command"
+	diffFilterKey             = "diff_filter"
+	iconsKey                  = "icons"
+	iconsDefault              = false
+	languageKey               = "language"
+	languageDefault           = "auto"
+	repoEnvKey                = "repo_env"
+	identitiesKey             = "identities"
+	branchProtectionKey       = "branch_protection"
+	dependenciesKey           = "dependencies"
+	detectDependenciesKey     = "detect_dependencies"
+	detectDependenciesDefault = false
+	compositeJobsKey          = "composite_jobs"
+	agentIntervalKey          = "agent_interval"
+	agentIntervalDefault      = 15 * time.Minute
+	agentStatusFileKey        = "agent_status_file"
+	auditLogKey               = "audit_log"
+	auditLogDefault           = true
+	repoStatsKey              = "repo_stats"
+	repoStatsDefault          = false
+	atomicCheckoutKey         = "atomic_checkout"
+	atomicCheckoutDefault     = false
+	mergeStyleKey             = "merge_style"
+	mergeStyleDefault         = "ff"
+	failFastKey               = "fail_fast"
+	failFastDefault           = false
+	pullRequestLinksKey       = "pull_request_links"
+	pullRequestLinksDefault   = true
+	forgeHostsKey             = "forge_hosts"
 )
 
 // Configuration cache to avoid repeated loading
@@ -115,11 +184,59 @@ func buildConfig() (*Config, error) {
 	}
 
 	config := &Config{
-		Directories: directories,
-		Depth:       viper.GetInt(recursionKey),
-		QuickMode:   viper.GetBool(quickKey),
-		Mode:        viper.GetString(modeKey),
-		Trace:       viper.GetBool(traceKey),
+		Directories:        directories,
+		Depth:              viper.GetInt(recursionKey),
+		QuickMode:          viper.GetBool(quickKey),
+		Mode:               viper.GetString(modeKey),
+		Trace:              viper.GetBool(traceKey),
+		TraceFormat:        viper.GetString(traceFormatKey),
+		IgnoreUntracked:    viper.GetBool(ignoreUntrackedKey),
+		OnDirty:            viper.GetString(onDirtyKey),
+		PruneAfterFetch:    viper.GetBool(pruneAfterFetchKey),
+		PullPrune:          viper.GetBool(pullPruneKey),
+		PullTags:           viper.GetBool(pullTagsKey),
+		Nested:             viper.GetBool(nestedKey),
+		NoFetch:            viper.GetBool(noFetchKey),
+		Sort:               viper.GetString(sortKey),
+		Filter:             viper.GetString(filterKey),
+		IncludeRemotes:     viper.GetStringSlice(includeRemotesKey),
+		ExcludeRemotes:     viper.GetStringSlice(excludeRemotesKey),
+		JobResultFile:      viper.GetString(jobResultFileKey),
+		JobResultCmd:       viper.GetString(jobResultCommandKey),
+		CredentialKeyring:  viper.GetBool(credentialKeyringKey),
+		RemoveStaleLocks:   viper.GetBool(removeStaleLocksKey),
+		NoVerify:           viper.GetBool(noVerifyKey),
+		NoVerifyPaths:      viper.GetStringSlice(noVerifyPathsKey),
+		Pinned:             viper.GetStringSlice(pinnedKey),
+		Confirm:            viper.GetStringSlice(confirmKey),
+		SparseReapply:      viper.GetBool(sparseReapplyKey),
+		BisectCommand:      viper.GetString(bisectCommandKey),
+		DiffFilter:         viper.GetString(diffFilterKey),
+		Icons:              viper.GetBool(iconsKey),
+		Language:           viper.GetString(languageKey),
+		CompositeJobs:      viper.GetStringMapStringSlice(compositeJobsKey),
+		AgentInterval:      viper.GetDuration(agentIntervalKey),
+		AgentStatusFile:    viper.GetString(agentStatusFileKey),
+		AuditLog:           viper.GetBool(auditLogKey),
+		RepoStats:          viper.GetBool(repoStatsKey),
+		DetectDependencies: viper.GetBool(detectDependenciesKey),
+		AtomicCheckout:     viper.GetBool(atomicCheckoutKey),
+		MergeStyle:         viper.GetString(mergeStyleKey),
+		FailFast:           viper.GetBool(failFastKey),
+		PullRequestLinks:   viper.GetBool(pullRequestLinksKey),
+		ForgeHosts:         viper.GetStringMapString(forgeHostsKey),
+	}
+	if err := viper.UnmarshalKey(repoEnvKey, &config.RepoEnv); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", repoEnvKey, err)
+	}
+	if err := viper.UnmarshalKey(identitiesKey, &config.Identities); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", identitiesKey, err)
+	}
+	if err := viper.UnmarshalKey(branchProtectionKey, &config.BranchProtection); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", branchProtectionKey, err)
+	}
+	if err := viper.UnmarshalKey(dependenciesKey, &config.Dependencies); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", dependenciesKey, err)
 	}
 
 	// Validate configuration
@@ -137,17 +254,41 @@ func validateConfig(config *Config) error {
 		config.Depth = recursionKeyDefault
 	}
 
-	// Validate mode — must be one of the supported operation modes.
-	switch config.Mode {
-	case "fetch", "pull", "merge", "rebase", "push":
-		// valid
-	default:
+	// Validate mode — must be one of the supported operation modes or a
+	// composite job.
+	if !validMode(config.Mode, config.CompositeJobs) {
 		config.Mode = modeKeyDefault
 	}
 
-	// Validate directories exist
+	// Validate dirty policy — unknown values fall back to skipping.
+	if policy, ok := command.ParseDirtyPolicy(config.OnDirty); ok {
+		config.OnDirty = string(policy)
+	} else {
+		config.OnDirty = onDirtyKeyDefault
+	}
+
+	// Validate merge style — unknown values fall back to fast-forwards.
+	if style, ok := command.ParseMergeStyle(config.MergeStyle); ok {
+		config.MergeStyle = string(style)
+	} else {
+		config.MergeStyle = mergeStyleDefault
+	}
+
+	if config.AgentInterval <= 0 {
+		config.AgentInterval = agentIntervalDefault
+	}
+	if config.AgentStatusFile == "" {
+		config.AgentStatusFile = agent.DefaultStatusFile()
+	}
+
+	// Validate directories exist, after expanding ~ and environment
+	// variables. A variable that is not set is an error, as it is for -d.
 	validDirs := make([]string, 0, len(config.Directories))
 	for _, dir := range config.Directories {
+		dir, err := expandPath(dir)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", pathsKey, err)
+		}
 		if _, err := os.Stat(dir); err == nil {
 			validDirs = append(validDirs, dir)
 		}
@@ -157,12 +298,47 @@ func validateConfig(config *Config) error {
 	return nil
 }
 
+// validMode reports whether mode is an operation mode, auto or one of the
+// composite jobs.
+func validMode(mode string, compositeJobs map[string][]string) bool {
+	switch mode {
+	case "fetch", "pull", "merge", "rebase", "push", tui.AutoMode:
+		return true
+	}
+	_, ok := compositeJobs[mode]
+	return ok
+}
+
 // set default configuration parameters
 func setDefaults() error {
 	viper.SetDefault(quickKey, quickKeyDefault)
 	viper.SetDefault(recursionKey, recursionKeyDefault)
 	viper.SetDefault(modeKey, modeKeyDefault)
 	viper.SetDefault(traceKey, traceKeyDefault)
+	viper.SetDefault(traceFormatKey, traceFormatDefault)
+	viper.SetDefault(ignoreUntrackedKey, ignoreUntrackedKeyDefault)
+	viper.SetDefault(onDirtyKey, onDirtyKeyDefault)
+	viper.SetDefault(pruneAfterFetchKey, pruneAfterFetchKeyDefault)
+	viper.SetDefault(pullPruneKey, pullPruneDefault)
+	viper.SetDefault(pullTagsKey, pullTagsDefault)
+	viper.SetDefault(nestedKey, nestedDefault)
+	viper.SetDefault(noFetchKey, noFetchDefault)
+	viper.SetDefault(sortKey, sortDefault)
+	viper.SetDefault(filterKey, filterDefault)
+	viper.SetDefault(credentialKeyringKey, credentialKeyringDefault)
+	viper.SetDefault(removeStaleLocksKey, removeStaleLocksDefault)
+	viper.SetDefault(noVerifyKey, noVerifyDefault)
+	viper.SetDefault(sparseReapplyKey, sparseReapplyDefault)
+	viper.SetDefault(iconsKey, iconsDefault)
+	viper.SetDefault(languageKey, languageDefault)
+	viper.SetDefault(agentIntervalKey, agentIntervalDefault.String())
+	viper.SetDefault(auditLogKey, auditLogDefault)
+	viper.SetDefault(repoStatsKey, repoStatsDefault)
+	viper.SetDefault(detectDependenciesKey, detectDependenciesDefault)
+	viper.SetDefault(atomicCheckoutKey, atomicCheckoutDefault)
+	viper.SetDefault(mergeStyleKey, mergeStyleDefault)
+	viper.SetDefault(failFastKey, failFastDefault)
+	viper.SetDefault(pullRequestLinksKey, pullRequestLinksDefault)
 	// viper.SetDefault(pathsKey, pathsKeyDefault)
 	return nil
 }
@@ -171,6 +347,10 @@ func setDefaults() error {
 func readConfiguration() error {
 	err := viper.ReadInConfig() // Find and read the config file
 	if err != nil {             // Handle errors reading the config file
+		// A file passed with --config has to exist; it is never created.
+		if configFileOverride != "" {
+			return fmt.Errorf("read config %s: %w", configFileOverride, err)
+		}
 		// Check if file exists more efficiently
 		configFile := configFileAbsPath + configFileExt
 		if _, err := os.Stat(configFile); os.IsNotExist(err) {
@@ -200,10 +380,13 @@ func readConfiguration() error {
 // initialize the configuration manager
 func initializeConfigurationManager() error {
 	// config viper
+	viper.SetConfigType(configType)
+	if configFileOverride != "" {
+		viper.SetConfigFile(configFileOverride)
+		return nil
+	}
 	viper.AddConfigPath(configurationDirectory)
 	viper.SetConfigName(configFileName)
-	viper.SetConfigType(configType)
 
 	return nil
 }
-
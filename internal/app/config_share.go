@@ -0,0 +1,149 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"go.yaml.in/yaml/v3"
+)
+
+// configFileOverride replaces the configuration file in the user's
+// configuration directory when set through SetConfigFile.
+var configFileOverride string
+
+// SetConfigFile makes gitbatch read its configuration from path instead of
+// the default location. It has to be called before the configuration is
+// loaded; an empty path restores the default.
+func SetConfigFile(path string) {
+	configFileOverride = strings.TrimSpace(path)
+}
+
+// activeConfigFile returns the configuration file gitbatch reads.
+func activeConfigFile() string {
+	if configFileOverride != "" {
+		return configFileOverride
+	}
+	return configFileAbsPath + configFileExt
+}
+
+// machineKeys are settings that only make sense on the machine they were
+// written on and are therefore left out of exports.
+var machineKeys = []string{pathsKey, agentStatusFileKey}
+
+// ExportConfig writes the effective configuration, the configuration file
+// merged with the defaults, as YAML to target ("-" for stdout).
+func ExportConfig(target string) error {
+	if _, err := loadConfiguration(); err != nil {
+		return err
+	}
+	var out io.Writer = os.Stdout
+	if target != "-" {
+		f, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	return exportConfig(viper.GetViper(), out)
+}
+
+func exportConfig(v *viper.Viper, w io.Writer) error {
+	settings := v.AllSettings()
+	for _, key := range machineKeys {
+		delete(settings, key)
+	}
+	data, err := yaml.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// ImportConfig merges the settings of a shared configuration file into the
+// active configuration file. Settings the shared file does not mention, such
+// as the scanned paths, are kept. The previous file is saved next to it with
+// a .bak suffix.
+func ImportConfig(source string) error {
+	return importConfig(source, activeConfigFile())
+}
+
+func importConfig(source, target string) error {
+	shared := viper.New()
+	shared.SetConfigFile(source)
+	shared.SetConfigType(configType)
+	if err := shared.ReadInConfig(); err != nil {
+		return fmt.Errorf("read %s: %w", source, err)
+	}
+	if err := validateSharedConfig(shared); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	merged := viper.New()
+	merged.SetConfigType(configType)
+	previous, err := os.ReadFile(target)
+	switch {
+	case err == nil:
+		if err := merged.ReadConfig(strings.NewReader(string(previous))); err != nil {
+			return fmt.Errorf("read %s: %w", target, err)
+		}
+		if err := os.WriteFile(target+".bak", previous, 0o644); err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	if err := merged.MergeConfigMap(shared.AllSettings()); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return merged.WriteConfigAs(target)
+}
+
+// validateSharedConfig rejects values gitbatch would silently replace with
+// defaults, so a typo in a team configuration does not go unnoticed.
+func validateSharedConfig(v *viper.Viper) error {
+	if mode := v.GetString(modeKey); v.IsSet(modeKey) && !validMode(mode, v.GetStringMapStringSlice(compositeJobsKey)) {
+		return fmt.Errorf("invalid %s %q", modeKey, mode)
+	}
+	if v.IsSet(onDirtyKey) {
+		if _, ok := command.ParseDirtyPolicy(v.GetString(onDirtyKey)); !ok {
+			return fmt.Errorf("invalid %s %q", onDirtyKey, v.GetString(onDirtyKey))
+		}
+	}
+	if v.IsSet(mergeStyleKey) {
+		if _, ok := command.ParseMergeStyle(v.GetString(mergeStyleKey)); !ok {
+			return fmt.Errorf("invalid %s %q", mergeStyleKey, v.GetString(mergeStyleKey))
+		}
+	}
+	for host, forge := range v.GetStringMapString(forgeHostsKey) {
+		if _, ok := command.ParseForge(forge); !ok {
+			return fmt.Errorf("invalid %s %q for %s", forgeHostsKey, forge, host)
+		}
+	}
+	var rules []command.RepoEnvRule
+	if err := v.UnmarshalKey(repoEnvKey, &rules); err != nil {
+		return fmt.Errorf("invalid %s: %w", repoEnvKey, err)
+	}
+	var identities []command.IdentityRule
+	if err := v.UnmarshalKey(identitiesKey, &identities); err != nil {
+		return fmt.Errorf("invalid %s: %w", identitiesKey, err)
+	}
+	var protection []command.BranchProtectionRule
+	if err := v.UnmarshalKey(branchProtectionKey, &protection); err != nil {
+		return fmt.Errorf("invalid %s: %w", branchProtectionKey, err)
+	}
+	var dependencies []command.DependencyRule
+	if err := v.UnmarshalKey(dependenciesKey, &dependencies); err != nil {
+		return fmt.Errorf("invalid %s: %w", dependenciesKey, err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportConfig_OmitsMachineSettings(t *testing.T) {
+	v := viper.New()
+	v.Set(pathsKey, []string{"/home/me/src"})
+	v.Set(modeKey, "pull")
+
+	var buf bytes.Buffer
+	require.NoError(t, exportConfig(v, &buf))
+	require.Contains(t, buf.String(), "mode: pull")
+	require.NotContains(t, buf.String(), "/home/me/src")
+}
+
+func TestImportConfig_MergesIntoActiveFile(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "config.yml")
+	shared := filepath.Join(dir, "team.yml")
+	require.NoError(t, os.WriteFile(target, []byte("paths:\n  - /home/me/src\nmode: fetch\n"), 0o644))
+	require.NoError(t, os.WriteFile(shared, []byte("mode: pull\non_dirty: autostash\n"), 0o644))
+
+	require.NoError(t, importConfig(shared, target))
+	v := viper.New()
+	v.SetConfigFile(target)
+	require.NoError(t, v.ReadInConfig())
+	require.Equal(t, "pull", v.GetString(modeKey))
+	require.Equal(t, "autostash", v.GetString(onDirtyKey))
+	require.Equal(t, []string{"/home/me/src"}, v.GetStringSlice(pathsKey))
+
+	backup, err := os.ReadFile(target + ".bak")
+	require.NoError(t, err)
+	require.Contains(t, string(backup), "mode: fetch")
+
+	require.NoError(t, os.WriteFile(shared, []byte("on_dirty: stash\n"), 0o644))
+	require.ErrorContains(t, importConfig(shared, target), `invalid on_dirty "stash"`)
+
+	require.NoError(t, os.WriteFile(shared, []byte("mode: auto\n"), 0o644))
+	require.NoError(t, importConfig(shared, target))
+	require.NoError(t, os.WriteFile(shared, []byte("mode: sync\n"), 0o644))
+	require.ErrorContains(t, importConfig(shared, target), `invalid mode "sync"`)
+
+	require.NoError(t, os.WriteFile(shared, []byte("forge_hosts:\n  git.example.com: gitea\n"), 0o644))
+	require.ErrorContains(t, importConfig(shared, target), `invalid forge_hosts "gitea" for git.example.com`)
+}
@@ -1,8 +1,11 @@
 package app
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 )
 
@@ -28,7 +31,7 @@ func TestConfigurationDirectory(t *testing.T) {
 }
 
 func TestValidateConfigMode(t *testing.T) {
-	validModes := []string{"fetch", "pull", "merge", "rebase", "push"}
+	validModes := []string{"fetch", "pull", "merge", "rebase", "push", "auto"}
 	for _, mode := range validModes {
 		cfg := &Config{Mode: mode, Depth: 1}
 		err := validateConfig(cfg)
@@ -44,3 +47,55 @@ func TestValidateConfigMode(t *testing.T) {
 		require.Equal(t, modeKeyDefault, cfg.Mode, "invalid mode %q should fall back to default", mode)
 	}
 }
+
+func TestValidateConfigOnDirty(t *testing.T) {
+	for _, policy := range []string{"skip", "autostash", "fail"} {
+		cfg := &Config{Mode: "pull", OnDirty: policy}
+		require.NoError(t, validateConfig(cfg))
+		require.Equal(t, policy, cfg.OnDirty)
+	}
+
+	cfg := &Config{Mode: "pull", OnDirty: "stash"}
+	require.NoError(t, validateConfig(cfg))
+	require.Equal(t, onDirtyKeyDefault, cfg.OnDirty, "unknown policy should fall back to default")
+}
+
+func TestValidateConfigMergeStyle(t *testing.T) {
+	for _, style := range []string{"ff", "no-ff", "squash"} {
+		cfg := &Config{Mode: "merge", MergeStyle: style}
+		require.NoError(t, validateConfig(cfg))
+		require.Equal(t, style, cfg.MergeStyle)
+	}
+
+	cfg := &Config{Mode: "merge", MergeStyle: "rebase"}
+	require.NoError(t, validateConfig(cfg))
+	require.Equal(t, mergeStyleDefault, cfg.MergeStyle, "unknown style should fall back to default")
+}
+
+func TestBuildConfigRepoEnv(t *testing.T) {
+	viper.Set(repoEnvKey, []map[string]any{
+		{"path": "~/work", "env": []string{"GIT_SSH_COMMAND=ssh -i ~/.ssh/id_work"}},
+	})
+	t.Cleanup(func() { viper.Set(repoEnvKey, nil) })
+
+	config, err := buildConfig()
+	require.NoError(t, err)
+	require.Len(t, config.RepoEnv, 1)
+	require.Equal(t, "~/work", config.RepoEnv[0].Path)
+	require.Equal(t, []string{"GIT_SSH_COMMAND=ssh -i ~/.ssh/id_work"}, config.RepoEnv[0].Env)
+}
+
+func TestValidateConfigExpandsPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.Mkdir(filepath.Join(home, "src"), 0o755))
+
+	config := &Config{Directories: []string{"$HOME/src", "~/missing"}}
+	require.NoError(t, validateConfig(config))
+	require.Equal(t, []string{filepath.Join(home, "src")}, config.Directories)
+
+	config = &Config{Directories: []string{"$HOME/src", "$GITBATCH_UNSET_VARIABLE/src"}}
+	err := validateConfig(config)
+	require.ErrorContains(t, err, "invalid paths: ")
+	require.ErrorContains(t, err, "environment variable GITBATCH_UNSET_VARIABLE is not set")
+}
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// doctorStatus grades a doctor finding.
+type doctorStatus string
+
+const (
+	doctorOK   doctorStatus = "ok"
+	doctorWarn doctorStatus = "warn"
+	doctorFail doctorStatus = "fail"
+)
+
+// doctorFinding is the result of one environment check. Fix tells the user
+// what to do about a warning or failure.
+type doctorFinding struct {
+	Status doctorStatus
+	Check  string
+	Detail string
+	Fix    string
+}
+
+// Doctor checks the environment gitbatch runs in and prints its findings to
+// w. It fails when a check finds something gitbatch cannot work with.
+func Doctor(w io.Writer) error {
+	findings := []doctorFinding{checkGit()}
+	findings = append(findings, checkGitFeatures()...)
+	findings = append(findings,
+		checkLazygit(),
+		checkSSHAgent(),
+		checkCredentialHelper(),
+		checkTerminal(),
+	)
+	return writeDoctorReport(w, findings)
+}
+
+func writeDoctorReport(w io.Writer, findings []doctorFinding) error {
+	failed := 0
+	for _, f := range findings {
+		fmt.Fprintf(w, "%-5s %-18s %s\n", f.Status, f.Check, f.Detail)
+		if f.Fix != "" && f.Status != doctorOK {
+			fmt.Fprintf(w, "%-5s %-18s → %s\n", "", "", f.Fix)
+		}
+		if f.Status == doctorFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+func checkGit() doctorFinding {
+	finding := doctorFinding{Check: "git"}
+	v, err := git.InstalledVersion()
+	if err != nil {
+		finding.Status = doctorFail
+		finding.Detail = err.Error()
+		finding.Fix = "install git and make sure it is in PATH"
+		return finding
+	}
+	finding.Status = doctorOK
+	finding.Detail = v.String()
+	return finding
+}
+
+// checkGitFeatures reports the features the installed git is too old for
+// and the fallback gitbatch uses instead.
+func checkGitFeatures() []doctorFinding {
+	var findings []doctorFinding
+	for _, feature := range git.DegradedFeatures() {
+		findings = append(findings, doctorFinding{
+			Status: doctorWarn,
+			Check:  "git feature",
+			Detail: fmt.Sprintf("%s needs git %d.%d: %s", feature.Name, feature.Major, feature.Minor, feature.Fallback),
+			Fix:    "upgrade git",
+		})
+	}
+	return findings
+}
+
+func checkLazygit() doctorFinding {
+	finding := doctorFinding{Check: "lazygit"}
+	path, err := exec.LookPath("lazygit")
+	if err != nil {
+		finding.Status = doctorWarn
+		finding.Detail = "not found in PATH"
+		finding.Fix = "install lazygit (https://github.com/jesseduffield/lazygit) to open repositories with TAB"
+		return finding
+	}
+	finding.Status = doctorOK
+	finding.Detail = path
+	return finding
+}
+
+func checkSSHAgent() doctorFinding {
+	finding := doctorFinding{Check: "ssh-agent"}
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		finding.Status = doctorWarn
+		finding.Detail = "SSH_AUTH_SOCK is not set"
+		finding.Fix = "start ssh-agent and add your key with ssh-add, or batch operations on ssh remotes ask for passphrases"
+		return finding
+	}
+	if _, err := os.Stat(socket); err != nil {
+		finding.Status = doctorWarn
+		finding.Detail = "agent socket " + socket + " does not exist"
+		finding.Fix = "restart ssh-agent and export its SSH_AUTH_SOCK"
+		return finding
+	}
+	// ssh-add -l exits with 1 when the agent holds no identities.
+	err := exec.Command("ssh-add", "-l").Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		finding.Status = doctorOK
+		finding.Detail = "running with keys loaded"
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		finding.Status = doctorWarn
+		finding.Detail = "running without keys"
+		finding.Fix = "add your key with ssh-add"
+	default:
+		finding.Status = doctorWarn
+		finding.Detail = "agent not reachable: " + err.Error()
+		finding.Fix = "restart ssh-agent and export its SSH_AUTH_SOCK"
+	}
+	return finding
+}
+
+func checkCredentialHelper() doctorFinding {
+	finding := doctorFinding{Check: "credential helper"}
+	out, err := exec.Command("git", "config", "--get-all", "credential.helper").Output()
+	var helpers []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			helpers = append(helpers, line)
+		}
+	}
+	if err != nil || len(helpers) == 0 {
+		finding.Status = doctorWarn
+		finding.Detail = "none configured"
+		finding.Fix = "set credential.helper (e.g. osxkeychain, libsecret or manager) so https remotes do not ask on every fetch"
+		return finding
+	}
+	finding.Status = doctorOK
+	finding.Detail = strings.Join(helpers, ", ")
+	return finding
+}
+
+func checkTerminal() doctorFinding {
+	finding := doctorFinding{Check: "terminal"}
+	term := os.Getenv("TERM")
+	info, err := os.Stdout.Stat()
+	switch {
+	case err != nil || info.Mode()&os.ModeCharDevice == 0:
+		finding.Status = doctorWarn
+		finding.Detail = "stdout is not a terminal"
+		finding.Fix = "run gitbatch in a terminal, or use quick mode (-q) in scripts"
+	case term == "" || term == "dumb":
+		finding.Status = doctorWarn
+		finding.Detail = fmt.Sprintf("TERM=%q cannot draw the interface", term)
+		finding.Fix = "set TERM, e.g. to xterm-256color"
+	default:
+		finding.Status = doctorOK
+		finding.Detail = "TERM=" + term
+		if colorterm := os.Getenv("COLORTERM"); colorterm != "" {
+			finding.Detail += ", COLORTERM=" + colorterm
+		}
+	}
+	return finding
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDoctorReport(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeDoctorReport(&buf, []doctorFinding{
+		{Status: doctorOK, Check: "git", Detail: "2.43.0", Fix: "ignored"},
+		{Status: doctorWarn, Check: "lazygit", Detail: "not found in PATH", Fix: "install lazygit"},
+	})
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "ok    git                2.43.0\n")
+	require.Contains(t, buf.String(), "→ install lazygit")
+	require.NotContains(t, buf.String(), "ignored")
+
+	err = writeDoctorReport(&buf, []doctorFinding{{Status: doctorFail, Check: "git", Detail: "missing"}})
+	require.EqualError(t, err, "1 check(s) failed")
+}
+
+func TestCheckCredentialHelper(t *testing.T) {
+	global := filepath.Join(t.TempDir(), "gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Chdir(t.TempDir())
+	require.Equal(t, doctorWarn, checkCredentialHelper().Status)
+
+	require.NoError(t, os.WriteFile(global, []byte("[credential]\n\thelper = store --file ~/.creds\n"), 0o644))
+	finding := checkCredentialHelper()
+	require.Equal(t, doctorOK, finding.Status)
+	require.Equal(t, "store --file ~/.creds", finding.Detail)
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/manifest"
+)
+
+// exportWorkspace writes a gitbatch manifest listing the repositories in dirs
+// with their remotes and current branches. A target of "-" writes YAML to
+// stdout. Paths are stored relative to the manifest's directory so that
+// --import resolves them the same way on another machine.
+func exportWorkspace(dirs []string, target string) error {
+	base, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	var out io.Writer = os.Stdout
+	encoding := manifest.EncodingYAML
+	if target != "-" {
+		abs, err := filepath.Abs(target)
+		if err != nil {
+			return err
+		}
+		base = filepath.Dir(abs)
+		encoding = manifest.EncodingFor(abs)
+		f, err := os.Create(abs)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	return buildWorkspace(dirs, base).Write(out, encoding)
+}
+
+func buildWorkspace(dirs []string, base string) manifest.Workspace {
+	ws := manifest.Workspace{Repositories: make([]manifest.WorkspaceRepository, 0, len(dirs))}
+	for _, dir := range dirs {
+		r, err := git.InitializeRepo(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not export %s: %s\n", dir, err)
+			continue
+		}
+		repo := manifest.WorkspaceRepository{Path: relativeTo(base, dir)}
+		if r.State != nil && r.State.Branch != nil {
+			repo.Branch = r.State.Branch.Name
+		}
+		for _, remote := range r.Remotes {
+			if len(remote.URL) == 0 {
+				continue
+			}
+			repo.Remotes = append(repo.Remotes, manifest.Remote{Name: remote.Name, URL: remote.URL[0]})
+		}
+		repo.Primary = primaryRemote(repo.Remotes)
+		ws.Repositories = append(ws.Repositories, repo)
+	}
+	return ws
+}
+
+// primaryRemote names the remote an import clones from when it is not
+// "origin", so the clone keeps the remote names of the exported repository.
+func primaryRemote(remotes []manifest.Remote) string {
+	if len(remotes) == 0 {
+		return ""
+	}
+	for _, remote := range remotes {
+		if remote.Name == "origin" {
+			return ""
+		}
+	}
+	return remotes[0].Name
+}
+
+func relativeTo(base, dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	rel, err := filepath.Rel(base, abs)
+	if err != nil {
+		return abs
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/manifest"
+)
+
+func TestExportWorkspace(t *testing.T) {
+	root := t.TempDir()
+	api := initRepoWithRemote(t, filepath.Join(root, "src", "api"), "https://example.com/org/api.git")
+	target := filepath.Join(root, "workspace.json")
+
+	require.NoError(t, exportWorkspace([]string{api}, target))
+
+	entries, err := manifest.Load(target, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, api, entries[0].Path)
+	require.Equal(t, "https://example.com/org/api.git", entries[0].URL)
+
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"path": "src/api"`)
+}
+
+func TestPrimaryRemote(t *testing.T) {
+	require.Empty(t, primaryRemote(nil))
+	require.Empty(t, primaryRemote([]manifest.Remote{{Name: "upstream"}, {Name: "origin"}}), "origin is the default")
+	require.Equal(t, "upstream", primaryRemote([]manifest.Remote{{Name: "upstream"}, {Name: "mine"}}))
+}
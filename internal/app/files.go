@@ -1,18 +1,49 @@
 package app
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+
+	"github.com/thorstenhirsch/gitbatch/internal/load"
 )
 
+// foreignMarkers are the metadata directories of version control systems
+// other than git.
+var foreignMarkers = []struct{ name, vcs string }{
+	{".hg", "Mercurial"},
+	{".svn", "Subversion"},
+	{".bzr", "Bazaar"},
+	{"_darcs", "darcs"},
+	{".fslckout", "Fossil"},
+	{"CVS", "CVS"},
+}
+
 // generateDirectories returns possible git repositories to pipe into git pkg
 // load function
 func generateDirectories(dirs []string, depth int) []string {
+	gitDirs, _ := discoverDirectories(dirs, depth, false)
+	return gitDirs
+}
+
+// discoverDirectories is generateDirectories that also reports the
+// directories it passed over because they are working copies of another
+// version control system. With nested set, the search continues inside the
+// working trees of the repositories it finds.
+func discoverDirectories(dirs []string, depth int, nested bool) ([]string, []load.Skipped) {
 	gitDirs := make([]string, 0)
+	var skipped []load.Skipped
+	reported := make(map[string]bool)
+	// Symbolic links can lead to a directory more than once, or back to one
+	// of its parents; both are searched only once.
+	visited := make(map[string]bool)
+	trees := make(map[string]int)
 
 	// Make a copy of original directories for fallback check
 	originalDirs := make([]string, len(dirs))
 	copy(originalDirs, dirs)
+	dirs = searchableDirectories(dirs, visited)
 
 	// If depth is 0, search in immediate subdirectories (depth 1)
 	// This allows gitbatch to be run from the parent directory of git repos
@@ -22,9 +53,26 @@ func generateDirectories(dirs []string, depth int) []string {
 
 	// Search recursively
 	for i := 0; i < depth; i++ {
+		found := len(gitDirs)
 		directories, repositories := walkRecursive(dirs, gitDirs)
+		directories = searchableDirectories(directories, visited)
 		dirs = directories
-		gitDirs = repositories
+		gitDirs = uniqueRepositories(repositories, found, trees)
+		if nested {
+			dirs = append(dirs, searchableDirectories(gitDirs[found:], visited)...)
+		}
+		for _, dir := range directories {
+			// Older Subversion and CVS keep a marker in every directory of
+			// the working copy; its top is enough.
+			if reported[dir] || reported[filepath.Dir(dir)] {
+				reported[dir] = true
+				continue
+			}
+			if vcs := foreignRepository(dir); vcs != "" {
+				reported[dir] = true
+				skipped = append(skipped, load.Skipped{Path: dir, Reason: fmt.Sprintf("%s working copy, not a git repository", vcs)})
+			}
+		}
 	}
 
 	// If no repos found in subdirectories, check if the original directories themselves are git repos
@@ -42,8 +90,110 @@ func generateDirectories(dirs []string, depth int) []string {
 		}
 	}
 
-	return gitDirs
-} // returns given values, first search directories and second stands for possible
+	return gitDirs, skipped
+}
+
+// searchableDirectories returns the directories of dirs that are worth a
+// search: not the metadata directory of a repository, and not yet in visited
+// by their real path. It adds them to visited.
+func searchableDirectories(dirs []string, visited map[string]bool) []string {
+	searchable := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if filepath.Base(dir) == ".git" {
+			continue
+		}
+		real := realPath(dir)
+		if visited[real] {
+			continue
+		}
+		visited[real] = true
+		searchable = append(searchable, dir)
+	}
+	return searchable
+}
+
+// uniqueRepositories drops the repositories from repositories[found:] whose
+// working tree an earlier one already leads to. trees maps the real paths of
+// the kept repositories to their index. Of two paths, the one without a
+// symbolic link is kept.
+func uniqueRepositories(repositories []string, found int, trees map[string]int) []string {
+	unique := repositories[:found]
+	for _, dir := range repositories[found:] {
+		real := realPath(dir)
+		if i, ok := trees[real]; ok {
+			if filepath.Clean(dir) == real {
+				unique[i] = dir
+			}
+			continue
+		}
+		trees[real] = len(unique)
+		unique = append(unique, dir)
+	}
+	return unique
+}
+
+// realPath returns dir with all symbolic links resolved, or dir itself when
+// they cannot be resolved.
+func realPath(dir string) string {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return filepath.Clean(dir)
+	}
+	return real
+}
+
+// collapseRepositories drops directories that lead to a working tree
+// another directory of dirs, e.g. through a symbolic link, already leads to,
+// so that no job runs twice on it. Unless nested is set, repositories inside
+// the working tree of another one are dropped as well and reported as
+// skipped.
+func collapseRepositories(dirs []string, nested bool) ([]string, []load.Skipped) {
+	collapsed := uniqueRepositories(append([]string(nil), dirs...), 0, make(map[string]int, len(dirs)))
+	if nested {
+		return collapsed, nil
+	}
+
+	trees := make(map[string]string, len(collapsed))
+	for _, dir := range collapsed {
+		trees[realPath(dir)] = dir
+	}
+	var skipped []load.Skipped
+	kept := collapsed[:0]
+	for _, dir := range collapsed {
+		if parent := enclosingRepository(dir, trees); parent != "" {
+			skipped = append(skipped, load.Skipped{Path: dir, Reason: fmt.Sprintf("nested inside %s, set nested_repositories to load it", parent)})
+			continue
+		}
+		kept = append(kept, dir)
+	}
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].Path < skipped[j].Path })
+	return kept, skipped
+}
+
+// enclosingRepository returns the directory of trees whose working tree
+// contains dir, or "" when dir is not nested in any of them.
+func enclosingRepository(dir string, trees map[string]string) string {
+	tree := realPath(dir)
+	for parent := filepath.Dir(tree); parent != tree; tree, parent = parent, filepath.Dir(parent) {
+		if enclosing, ok := trees[parent]; ok {
+			return enclosing
+		}
+	}
+	return ""
+}
+
+// foreignRepository returns the version control system dir is a working copy
+// of, or "" when it has none or is a git repository.
+func foreignRepository(dir string) string {
+	for _, marker := range foreignMarkers {
+		if info, err := os.Stat(filepath.Join(dir, marker.name)); err == nil && (info.IsDir() || marker.name == ".fslckout") {
+			return marker.vcs
+		}
+	}
+	return ""
+}
+
+// returns given values, first search directories and second stands for possible
 // git repositories. Call this func from a "for i := 0; i<depth; i++" loop
 func walkRecursive(search, appendant []string) ([]string, []string) {
 	max := len(search)
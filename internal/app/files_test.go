@@ -1,11 +1,14 @@
 package app
 
 import (
+	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"github.com/thorstenhirsch/gitbatch/internal/gittest"
+	"github.com/thorstenhirsch/gitbatch/internal/load"
 )
 
 func TestGenerateDirectories(t *testing.T) {
@@ -18,7 +21,7 @@ func TestGenerateDirectories(t *testing.T) {
 		expected []string
 	}{
 		{[]string{th.RepoPath}, 1, []string{th.BasicRepoPath(), th.DirtyRepoPath()}},
-		{[]string{th.RepoPath}, 2, []string{th.BasicRepoPath(), th.DirtyRepoPath()}}, // maybe move one repo to a sub folder
+		{[]string{th.RepoPath}, 2, []string{th.BasicRepoPath(), th.DirtyRepoPath(), filepath.Join(th.NonRepoPath(), "basic-repo")}},
 	}
 	for _, test := range tests {
 		output := generateDirectories(test.inp1, test.inp2)
@@ -77,3 +80,60 @@ func TestSeparateDirectories(t *testing.T) {
 		require.ElementsMatch(t, out2, test.exp2)
 	}
 }
+
+func TestDiscoverDirectoriesReportsForeignWorkingCopies(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	require.NoError(t, os.MkdirAll(repo, 0o755))
+	require.NoError(t, exec.Command("git", "init", "-q", repo).Run())
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "legacy", ".hg"), 0o755))
+	// Old Subversion working copies have a .svn directory on every level.
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "old", ".svn"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "old", "trunk", ".svn"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "plain"), 0o755))
+
+	dirs, skipped := discoverDirectories([]string{root}, 2, false)
+	require.Equal(t, []string{repo}, dirs)
+	require.ElementsMatch(t, []load.Skipped{
+		{Path: filepath.Join(root, "legacy"), Reason: "Mercurial working copy, not a git repository"},
+		{Path: filepath.Join(root, "old"), Reason: "Subversion working copy, not a git repository"},
+	}, skipped)
+}
+
+func TestNestedRepositoriesAreLoadedOnce(t *testing.T) {
+	root := t.TempDir()
+	outer := filepath.Join(root, "outer")
+	inner := filepath.Join(outer, "vendor", "inner")
+	for _, dir := range []string{outer, inner} {
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, exec.Command("git", "init", "-q", dir).Run())
+	}
+	alias := filepath.Join(root, "alias")
+	require.NoError(t, os.Symlink(outer, alias))
+
+	dirs, _ := discoverDirectories([]string{root}, 3, true)
+	dirs, skipped := collapseRepositories(dirs, true)
+	require.Empty(t, skipped)
+	require.Len(t, dirs, 2, "the working trees behind the symbolic link are loaded once: %v", dirs)
+
+	dirs, skipped = collapseRepositories([]string{outer, inner, alias}, false)
+	require.Equal(t, []string{outer}, dirs)
+	require.Equal(t, []load.Skipped{
+		{Path: inner, Reason: "nested inside " + outer + ", set nested_repositories to load it"},
+	}, skipped)
+}
+
+func TestDiscoverDirectoriesFollowsSymbolicLinksOnce(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	require.NoError(t, os.MkdirAll(repo, 0o755))
+	require.NoError(t, exec.Command("git", "init", "-q", repo).Run())
+	// "alias" comes first and leads to the same working tree.
+	require.NoError(t, os.Symlink(repo, filepath.Join(root, "alias")))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "links"), 0o755))
+	require.NoError(t, os.Symlink(root, filepath.Join(root, "links", "loop")))
+
+	dirs, skipped := discoverDirectories([]string{root}, 5, false)
+	require.Equal(t, []string{repo}, dirs)
+	require.Empty(t, skipped)
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+)
+
+// PrintFreeze lists per repository the commits that landed on protected
+// branches after a freeze, given as a date, a time or a period like
+// PrintChanges takes. The branches are those of branch_protection for each
+// repository, main and master without a rule, unless branches names them. It
+// returns the number of commits found, so a release check can fail on them.
+func (a *App) PrintFreeze(w io.Writer, freeze string, branches []string) (int, error) {
+	start, err := parseSince(freeze, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	dirs := a.workspaceDirectories()
+	if len(dirs) == 0 {
+		return 0, fmt.Errorf("no git repositories found in specified directories")
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	total, violated := 0, 0
+	for _, dir := range dirs {
+		patterns := branches
+		if len(patterns) == 0 {
+			patterns = command.ProtectedBranchPatterns(dir)
+		}
+		commits, err := command.CommitsAfterFreeze(context.Background(), dir, start, patterns)
+		if err != nil {
+			fmt.Fprintf(tw, "%s\t%s\n", filepath.Base(dir), err)
+			continue
+		}
+		if len(commits) == 0 {
+			continue
+		}
+		violated++
+		total += len(commits)
+		fmt.Fprintf(tw, "%s\t%d commits\n", filepath.Base(dir), len(commits))
+		for _, commit := range commits {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", shortCommit(commit.Hash), commit.Ref, commit.Time.Local().Format("2006-01-02 15:04"), commit.Author, commit.Subject)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return total, err
+	}
+	frozen := start.Local().Format("2006-01-02 15:04")
+	if total == 0 {
+		_, err = fmt.Fprintf(w, "no commits on protected branches in %d repositories since the freeze on %s\n", len(dirs), frozen)
+		return 0, err
+	}
+	_, err = fmt.Fprintf(w, "%d commits on protected branches in %d of %d repositories since the freeze on %s\n", total, violated, len(dirs), frozen)
+	return total, err
+}
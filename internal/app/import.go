@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/thorstenhirsch/gitbatch/internal/manifest"
+)
+
+// importManifests loads the given manifests and returns the checkout paths of
+// the repositories they list. With cloneMissing, repositories that are not
+// checked out yet are cloned first.
+func importManifests(paths []string, format string, cloneMissing bool) ([]string, error) {
+	var forced manifest.Format
+	if format != "" {
+		f, err := manifest.ParseFormat(format)
+		if err != nil {
+			return nil, err
+		}
+		forced = f
+	}
+
+	dirs := make([]string, 0)
+	for _, path := range paths {
+		entries, err := manifest.Load(path, forced)
+		if err != nil {
+			return nil, err
+		}
+		if cloneMissing {
+			for _, entry := range manifest.Missing(entries) {
+				fmt.Printf("cloning %s into %s\n", entry.URL, entry.Path)
+				if err := manifest.Clone(entry); err != nil {
+					fmt.Fprintf(os.Stderr, "could not clone %s: %s\n", entry.Path, err)
+				}
+			}
+		}
+		dirs = append(dirs, manifest.Existing(entries)...)
+	}
+	return dirs, nil
+}
+
+// mergeDirectories appends extra repositories to dirs, skipping duplicates.
+func mergeDirectories(dirs, extra []string) []string {
+	seen := make(map[string]struct{}, len(dirs)+len(extra))
+	merged := make([]string, 0, len(dirs)+len(extra))
+	for _, list := range [][]string{dirs, extra} {
+		for _, dir := range list {
+			if _, ok := seen[dir]; ok {
+				continue
+			}
+			seen[dir] = struct{}{}
+			merged = append(merged, dir)
+		}
+	}
+	return merged
+}
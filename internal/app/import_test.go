@@ -0,0 +1,29 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeDirectories(t *testing.T) {
+	merged := mergeDirectories([]string{"/a", "/b"}, []string{"/b", "/c", "/a"})
+	require.Equal(t, []string{"/a", "/b", "/c"}, merged)
+}
+
+func TestImportManifests(t *testing.T) {
+	root := t.TempDir()
+	existing := initRepoWithRemote(t, filepath.Join(root, "api"), "https://example.com/api.git")
+	manifestPath := filepath.Join(root, "repos.csv")
+	content := existing + ",api,,\n" + filepath.Join(root, "missing") + ",missing,,\n"
+	require.NoError(t, os.WriteFile(manifestPath, []byte(content), 0o644))
+
+	dirs, err := importManifests([]string{manifestPath}, "", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{existing}, dirs)
+
+	_, err = importManifests([]string{manifestPath}, "bogus", false)
+	require.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// expandPath replaces a leading ~ with the home directory and $VAR or ${VAR}
+// with the value of the environment variable, and makes the result absolute.
+// A variable that is not set is an error rather than an empty string, which
+// would silently turn "$WORK/src" into "/src".
+func expandPath(path string) (string, error) {
+	p := strings.TrimSpace(path)
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("path %q: %w", path, err)
+		}
+		p = filepath.Join(home, p[1:])
+	}
+	var missing []string
+	p = os.Expand(p, func(name string) string {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("path %q: environment variable %s is not set", path, strings.Join(missing, ", "))
+	}
+	if p == "" {
+		return "", fmt.Errorf("empty path")
+	}
+	return filepath.Abs(p)
+}
+
+// expandDirectories expands the directories given with -d and checks that
+// they exist before anything is scanned.
+func expandDirectories(dirs []string) ([]string, error) {
+	expanded := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		p, err := expandPath(dir)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("directory %s: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("directory %s: not a directory", dir)
+		}
+		expanded = append(expanded, p)
+	}
+	return expanded, nil
+}
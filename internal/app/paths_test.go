@@ -0,0 +1,43 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GITBATCH_WORK", filepath.Join(home, "work"))
+
+	for input, expected := range map[string]string{
+		"~":                    home,
+		"~/src":                filepath.Join(home, "src"),
+		"$HOME/src":            filepath.Join(home, "src"),
+		"${GITBATCH_WORK}/api": filepath.Join(home, "work", "api"),
+		" $GITBATCH_WORK ":     filepath.Join(home, "work"),
+	} {
+		p, err := expandPath(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, p, input)
+	}
+
+	_, err := expandPath("$GITBATCH_UNSET_VARIABLE/src")
+	require.EqualError(t, err, `path "$GITBATCH_UNSET_VARIABLE/src": environment variable GITBATCH_UNSET_VARIABLE is not set`)
+}
+
+func TestExpandDirectoriesRejectsMissingDirectories(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.Mkdir(filepath.Join(home, "src"), 0o755))
+
+	dirs, err := expandDirectories([]string{"~/src"})
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(home, "src")}, dirs)
+
+	_, err = expandDirectories([]string{"~/src", "~/scr"})
+	require.ErrorContains(t, err, "directory ~/scr:")
+}
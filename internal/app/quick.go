@@ -6,6 +6,7 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/thorstenhirsch/gitbatch/internal/command"
@@ -15,6 +16,9 @@ import (
 func quick(directories []string, mode string) error {
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, runtime.GOMAXPROCS(0)*4)
+	// abortedBy is the repository whose failure ended the batch under
+	// fail_fast; the repositories after it are skipped.
+	var abortedBy atomic.Pointer[string]
 	start := time.Now()
 	for _, dir := range directories {
 		wg.Add(1)
@@ -22,8 +26,20 @@ func quick(directories []string, mode string) error {
 		go func(d string, mode string) {
 			defer wg.Done()
 			defer func() { <-sem }()
-			if err := operate(d, mode); err != nil {
+			if failed := abortedBy.Load(); failed != nil {
+				fmt.Fprintf(os.Stderr, "skipped %s on %s: %s failed (fail_fast)\n", mode, d, *failed)
+				return
+			}
+			r, err := operate(d, mode)
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "could not perform %s on %s: %s\n", mode, d, err)
+				if command.AbortsBatch(err) {
+					abortedBy.CompareAndSwap(nil, &d)
+				}
+				return
+			}
+			if r.State.PullRequestURL != "" {
+				fmt.Printf("%s: successful, open a pull request: %s\n", d, r.State.PullRequestURL)
 				return
 			}
 			fmt.Printf("%s: successful\n", d)
@@ -35,32 +51,43 @@ func quick(directories []string, mode string) error {
 	return nil
 }
 
-func operate(directory, mode string) error {
+// operate runs mode in the repository in directory and returns the
+// repository with the state the operation left it in.
+func operate(directory, mode string) (*git.Repository, error) {
 	r, err := git.InitializeRepo(directory)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if !r.HasRemote() {
+		return nil, fmt.Errorf("no remote configured")
 	}
 	executor := command.NewExecutor(r)
 	ctx := context.Background()
 	switch mode {
 	case "fetch":
-		return executor.RunFetch(ctx, &command.FetchOptions{
+		err = executor.RunFetch(ctx, &command.FetchOptions{
 			Progress: true,
 		})
 	case "pull":
-		return executor.RunPull(ctx, &command.PullOptions{
+		err = executor.RunPull(ctx, &command.PullOptions{
 			Progress: true,
 			FFOnly:   true,
 		}, false)
 	case "merge":
-		return executor.RunMerge(ctx, nil)
+		err = executor.RunMerge(ctx, nil)
 	case "rebase":
-		return executor.RunRebase(ctx, &command.PullOptions{
+		err = executor.RunRebase(ctx, &command.PullOptions{
 			Progress: true,
 			Rebase:   true,
 		})
 	case "push":
-		return executor.RunPush(ctx, nil, false)
+		err = executor.RunPush(ctx, nil, false)
+	default:
+		job, ok := command.LookupCompositeJob(mode)
+		if !ok {
+			return nil, fmt.Errorf("unsupported mode: %s", mode)
+		}
+		err = executor.RunComposite(ctx, &command.CompositeOptions{Job: job})
 	}
-	return fmt.Errorf("unsupported mode: %s", mode)
+	return r, err
 }
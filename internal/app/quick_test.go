@@ -52,6 +52,6 @@ func TestOperatePush(t *testing.T) {
 	_, err = command.Run(th.Repository.AbsPath, "git", []string{"remote", "add", "origin", remotePath})
 	require.NoError(t, err)
 
-	err = operate(th.Repository.AbsPath, "push")
+	_, err = operate(th.Repository.AbsPath, "push")
 	require.NoError(t, err)
 }
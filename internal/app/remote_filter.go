@@ -0,0 +1,145 @@
+package app
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+)
+
+// remoteFilter keeps or drops repositories based on their remote URLs.
+// Patterns are matched against a normalized "host/org/repo" form of each
+// remote URL, e.g. "github.com/mycompany/*".
+type remoteFilter struct {
+	include []string
+	exclude []string
+}
+
+func newRemoteFilter(include, exclude []string) *remoteFilter {
+	f := &remoteFilter{
+		include: normalizePatterns(include),
+		exclude: normalizePatterns(exclude),
+	}
+	if len(f.include) == 0 && len(f.exclude) == 0 {
+		return nil
+	}
+	return f
+}
+
+// filterDirectoriesByRemote drops directories whose remotes do not pass the
+// include/exclude patterns. Without patterns the input is returned unchanged.
+func filterDirectoriesByRemote(dirs []string, include, exclude []string) []string {
+	f := newRemoteFilter(include, exclude)
+	if f == nil {
+		return dirs
+	}
+	filtered := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if f.allows(remoteURLs(dir)) {
+			filtered = append(filtered, dir)
+		}
+	}
+	return filtered
+}
+
+// allows reports whether a repository with the given remote URLs should be
+// kept. Any excluded remote drops the repository; with include patterns at
+// least one remote has to match.
+func (f *remoteFilter) allows(urls []string) bool {
+	if f == nil {
+		return true
+	}
+	normalized := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if n := normalizeRemoteURL(u); n != "" {
+			normalized = append(normalized, n)
+		}
+	}
+	for _, u := range normalized {
+		if matchesAnyPattern(u, f.exclude) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, u := range normalized {
+		if matchesAnyPattern(u, f.include) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyPattern(remote string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, remote); ok {
+			return true
+		}
+		// A pattern without wildcards selects the whole host or org subtree.
+		if strings.HasPrefix(remote, pattern+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizePatterns(patterns []string) []string {
+	out := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		p = strings.ToLower(strings.Trim(strings.TrimSpace(p), "/"))
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// normalizeRemoteURL converts https, ssh and scp-like git URLs into
+// "host/path" without credentials, port or ".git" suffix.
+func normalizeRemoteURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	var host, p string
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return ""
+		}
+		host, p = u.Hostname(), u.Path
+	} else if at := strings.Index(raw, ":"); at > 0 && !strings.HasPrefix(raw, "/") {
+		// scp-like syntax: [user@]host:org/repo.git
+		host, p = raw[:at], raw[at+1:]
+		if i := strings.LastIndex(host, "@"); i >= 0 {
+			host = host[i+1:]
+		}
+	} else {
+		// local path remotes have no host
+		p = raw
+	}
+	p = strings.TrimSuffix(strings.Trim(p, "/"), ".git")
+	if host == "" {
+		return strings.ToLower(p)
+	}
+	return strings.ToLower(host + "/" + p)
+}
+
+// remoteURLs returns all remote URLs configured for the repository at dir.
+func remoteURLs(dir string) []string {
+	repo, err := gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil
+	}
+	urls := make([]string, 0, len(cfg.Remotes))
+	for _, remote := range cfg.Remotes {
+		urls = append(urls, remote.URLs...)
+	}
+	return urls
+}
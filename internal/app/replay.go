@@ -0,0 +1,259 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// replayTimeFormat matches the timestamps of the text trace log.
+const replayTimeFormat = "2006-01-02T15:04:05.000000"
+
+// traceLinePattern parses a line of the text trace log, e.g.
+// "2026-10-17T09:30:00.000000 [S] repo=api event=workstatus.transition data=queued -> working".
+var traceLinePattern = regexp.MustCompile(`^(\S+) (?:\[([GS])\] )?repo=(.*?) event=(\S+) (?:done duration=(\S+)|data=(.*))$`)
+
+// replayRecord is a line of a trace log in either format.
+type replayRecord struct {
+	Timestamp time.Time
+	Repo      string
+	Queue     string
+	Event     string
+	Data      string
+	Done      bool
+	Duration  time.Duration
+}
+
+// parseTraceLine reads a line of the text or, when it starts with a brace,
+// the JSON trace log.
+func parseTraceLine(line string) (replayRecord, error) {
+	if strings.HasPrefix(line, "{") {
+		var record struct {
+			Timestamp time.Time `json:"timestamp"`
+			Repo      string    `json:"repo"`
+			Queue     string    `json:"queue"`
+			Event     string    `json:"event"`
+			Data      string    `json:"data"`
+			Done      bool      `json:"done"`
+			Duration  float64   `json:"duration"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return replayRecord{}, err
+		}
+		return replayRecord{
+			Timestamp: record.Timestamp,
+			Repo:      record.Repo,
+			Queue:     record.Queue,
+			Event:     record.Event,
+			Data:      record.Data,
+			Done:      record.Done,
+			Duration:  time.Duration(record.Duration * float64(time.Second)),
+		}, nil
+	}
+
+	match := traceLinePattern.FindStringSubmatch(line)
+	if match == nil {
+		return replayRecord{}, fmt.Errorf("not a trace line")
+	}
+	timestamp, err := time.Parse(replayTimeFormat, match[1])
+	if err != nil {
+		return replayRecord{}, err
+	}
+	record := replayRecord{Timestamp: timestamp, Repo: match[3], Event: match[4], Data: match[6]}
+	switch match[2] {
+	case "G":
+		record.Queue = "git"
+	case "S":
+		record.Queue = "state"
+	}
+	if match[5] != "" {
+		record.Done = true
+		if record.Duration, err = time.ParseDuration(match[5]); err != nil {
+			return replayRecord{}, err
+		}
+	}
+	return record, nil
+}
+
+// replayRepository is the stand-in for a traced repository: a repository
+// without queues or a working tree, so only its state machine runs.
+type replayRepository struct {
+	repo *git.Repository
+	// transitions counts the transitions the state machine accepted.
+	transitions int
+	// pending counts the queued events that have not finished yet.
+	pending map[string]int
+}
+
+func newReplayRepository(name string) *replayRepository {
+	return &replayRepository{
+		repo:    &git.Repository{Name: name, State: &git.RepositoryState{}},
+		pending: make(map[string]int),
+	}
+}
+
+// parseTransition splits the "from -> to" data of a transition event.
+func parseTransition(data string) (from, to git.WorkStatus, ok bool) {
+	fromName, toName, found := strings.Cut(data, " -> ")
+	if !found {
+		return from, to, false
+	}
+	from, fromOK := git.ParseWorkStatus(fromName)
+	to, toOK := git.ParseWorkStatus(toName)
+	if !toOK {
+		return from, to, false
+	}
+	if !fromOK {
+		// The first status of a repository leaves the zero status.
+		from = git.WorkStatus{}
+	}
+	return from, to, true
+}
+
+// replayTransition applies a recorded transition to the stand-in and returns
+// what differs from the trace: a start status the replay does not have, which
+// means a status changed without a transition, or a transition the state
+// machine decides differently now. The stand-in follows the trace either way.
+func (rr *replayRepository) replayTransition(record replayRecord) []string {
+	from, to, ok := parseTransition(record.Data)
+	if !ok {
+		return []string{fmt.Sprintf("unreadable transition %q", record.Data)}
+	}
+	var notes []string
+	current := rr.repo.WorkStatus()
+	if current != (git.WorkStatus{}) && from != (git.WorkStatus{}) && current != from {
+		notes = append(notes, fmt.Sprintf("the trace starts from %s, the replay is %s", from, current))
+	}
+	if from != (git.WorkStatus{}) && current != from {
+		// Follow the trace from here on so one difference is reported once;
+		// a fresh state may enter any status.
+		rr.repo.State = &git.RepositoryState{}
+		rr.repo.SetWorkStatusSilent(from)
+		current = from
+	}
+
+	rejected := record.Event == git.WorkStatusTransitionRejected
+	allowed := git.CanTransition(current, to)
+	switch {
+	case rejected && allowed:
+		notes = append(notes, fmt.Sprintf("rejected in the trace, %s -> %s is allowed now", current, to))
+	case !rejected && !allowed:
+		notes = append(notes, fmt.Sprintf("illegal transition %s -> %s", current, to))
+	}
+	if !rejected {
+		if !allowed {
+			rr.repo.State = &git.RepositoryState{}
+		}
+		rr.repo.SetWorkStatusSilent(to)
+		rr.transitions++
+	}
+	return notes
+}
+
+// isQueuedEvent reports whether an event goes through a queue, the only
+// events the trace records the end of.
+func isQueuedEvent(event string) bool {
+	return event == git.RepositoryGitCommandRequested || event == git.RepositoryEvaluationRequested
+}
+
+// Replay reads a trace log written by --trace, in either format, and replays
+// its work status transitions against stand-in repositories running the
+// current state machine. It prints the timeline and marks with "!" the
+// transitions the state machine rejects now, or accepts although the trace
+// rejected them, transitions starting from another status than the replayed
+// one, and queued events that never finished. It returns the number of
+// anomalies.
+func Replay(path string, w io.Writer) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	repos := make(map[string]*replayRepository)
+	anomalies := 0
+	note := func(format string, args ...interface{}) {
+		anomalies++
+		fmt.Fprintf(w, "  ! "+format+"\n", args...)
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		record, err := parseTraceLine(line)
+		if err != nil {
+			return anomalies, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+		rr := repos[record.Repo]
+		if rr == nil {
+			rr = newReplayRepository(record.Repo)
+			repos[record.Repo] = rr
+		}
+
+		detail := record.Data
+		if record.Done {
+			detail = "done in " + record.Duration.String()
+		}
+		fmt.Fprintf(w, "%s %s %s %s %s\n", record.Timestamp.UTC().Format("15:04:05.000000"), record.Repo, record.Queue, record.Event, detail)
+
+		key := record.Queue + " " + record.Event
+		switch {
+		case record.Event == git.WorkStatusTransitioned || record.Event == git.WorkStatusTransitionRejected:
+			for _, text := range rr.replayTransition(record) {
+				note("%s", text)
+			}
+		case record.Done:
+			if rr.pending[key] == 0 {
+				note("%s finished without being queued", record.Event)
+			} else {
+				rr.pending[key]--
+			}
+		case isQueuedEvent(record.Event):
+			rr.pending[key]++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return anomalies, err
+	}
+
+	names := make([]string, 0, len(repos))
+	for name := range repos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(w)
+	for _, name := range names {
+		rr := repos[name]
+		status := "-"
+		if rr.transitions > 0 {
+			status = rr.repo.WorkStatus().String()
+		}
+		fmt.Fprintf(w, "%s: %s after %d transitions\n", name, status, rr.transitions)
+		keys := make([]string, 0, len(rr.pending))
+		for key, count := range rr.pending {
+			if count > 0 {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			note("%d %s event(s) never finished", rr.pending[key], key)
+		}
+	}
+	fmt.Fprintf(w, "%d anomalies\n", anomalies)
+	return anomalies, nil
+}
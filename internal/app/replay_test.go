@@ -0,0 +1,63 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayTextTrace(t *testing.T) {
+	trace := strings.Join([]string{
+		"2026-10-17T09:30:00.000000 [S] repo=api event=workstatus.transition data=status(0) -> available",
+		"2026-10-17T09:30:00.100000 [S] repo=api event=workstatus.transition data=available -> queued",
+		"2026-10-17T09:30:00.200000 [G] repo=api event=repository.git.command.requested data=*job.Job,operation=fetch",
+		"2026-10-17T09:30:00.300000 [S] repo=api event=workstatus.transition data=queued -> working",
+		"2026-10-17T09:30:01.300000 [G] repo=api event=repository.git.command.requested done duration=1s",
+		"2026-10-17T09:30:01.400000 [S] repo=api event=workstatus.transition.rejected data=working -> queued",
+		"2026-10-17T09:30:01.500000 [S] repo=api event=workstatus.transition data=working -> success",
+		"2026-10-17T09:30:00.000000 [S] repo=web event=workstatus.transition data=available -> queued",
+		"2026-10-17T09:30:00.100000 [S] repo=web event=workstatus.transition data=paused -> working",
+		"2026-10-17T09:30:00.200000 [S] repo=web event=repository.evaluation.requested data=outcome",
+	}, "\n")
+	path := filepath.Join(t.TempDir(), "gitbatch.log")
+	require.NoError(t, os.WriteFile(path, []byte(trace), 0o644))
+
+	var out bytes.Buffer
+	anomalies, err := Replay(path, &out)
+	require.NoError(t, err)
+	require.Equal(t, 2, anomalies, out.String())
+	require.Contains(t, out.String(), "09:30:01.300000 api git repository.git.command.requested done in 1s\n")
+	require.Contains(t, out.String(), "  ! the trace starts from paused, the replay is queued\n")
+	require.Contains(t, out.String(), "  ! 1 state repository.evaluation.requested event(s) never finished\n")
+	require.Contains(t, out.String(), "api: success after 4 transitions\n")
+	require.Contains(t, out.String(), "web: working after 2 transitions\n")
+}
+
+func TestReplayJSONTrace(t *testing.T) {
+	trace := strings.Join([]string{
+		`{"timestamp":"2026-10-17T09:30:00Z","repo":"api","queue":"state","event":"workstatus.transition","data":"available -> queued"}`,
+		`{"timestamp":"2026-10-17T09:30:01Z","repo":"api","queue":"state","event":"workstatus.transition","data":"queued -> success"}`,
+		`{"timestamp":"2026-10-17T09:30:02Z","repo":"api","queue":"state","event":"workstatus.transition.rejected","data":"success -> queued"}`,
+	}, "\n")
+	path := filepath.Join(t.TempDir(), "gitbatch.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(trace), 0o644))
+
+	var out bytes.Buffer
+	anomalies, err := Replay(path, &out)
+	require.NoError(t, err)
+	require.Equal(t, 2, anomalies, out.String())
+	require.Contains(t, out.String(), "  ! illegal transition queued -> success\n")
+	require.Contains(t, out.String(), "  ! rejected in the trace, success -> queued is allowed now\n")
+	require.Contains(t, out.String(), "api: success after 2 transitions\n")
+}
+
+func TestReplayRejectsGarbage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gitbatch.log")
+	require.NoError(t, os.WriteFile(path, []byte("hello\n"), 0o644))
+	_, err := Replay(path, &bytes.Buffer{})
+	require.ErrorContains(t, err, "gitbatch.log:1")
+}
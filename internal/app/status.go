@@ -0,0 +1,31 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thorstenhirsch/gitbatch/internal/agent"
+)
+
+// PrintStatus prints the state of the workspace repositories, one line per
+// repository or, with short, a one-line summary for shell prompts. The
+// status file of the agent is used while it is fresh, so this does not
+// contact any remote.
+func (a *App) PrintStatus(w io.Writer, short bool) error {
+	status, _ := agent.ReadStatus(a.Config.AgentStatusFile)
+	repos := agent.Workspace(a.agentDirectories(), status, time.Now())
+	if short {
+		_, err := fmt.Fprintln(w, agent.Summarize(repos).Short())
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no git repositories found in specified directories")
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, repo := range repos {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", repo.Name, repo.Branch, repo.Flags())
+	}
+	return tw.Flush()
+}
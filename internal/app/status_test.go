@@ -0,0 +1,36 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/agent"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+)
+
+func TestPrintStatus(t *testing.T) {
+	workspace := t.TempDir()
+	api := filepath.Join(workspace, "api")
+	require.NoError(t, os.MkdirAll(api, 0o755))
+	_, err := command.Run(api, "git", []string{"init", "--initial-branch=main"})
+	require.NoError(t, err)
+	statusFile := filepath.Join(t.TempDir(), "status.json")
+	require.NoError(t, agent.WriteStatus(statusFile, &agent.Status{
+		Updated:      time.Now(),
+		Interval:     "15m0s",
+		Repositories: []agent.RepositoryStatus{{Path: api, Name: "api", Branch: "main", Behind: 3, Dirty: true}},
+	}))
+	a := &App{Config: &Config{AgentStatusFile: statusFile}, workspaces: []string{workspace}}
+
+	var out bytes.Buffer
+	require.NoError(t, a.PrintStatus(&out, true))
+	require.Equal(t, "↓1 ⚠1\n", out.String())
+
+	out.Reset()
+	require.NoError(t, a.PrintStatus(&out, false))
+	require.Equal(t, "api  main  ↓3 ⚠\n", out.String())
+}
@@ -0,0 +1,157 @@
+// Package askpass bridges the questions git and ssh ask through GIT_ASKPASS
+// and SSH_ASKPASS back to the running gitbatch interface. The interface runs
+// a Server on a unix socket; `gitbatch --askpass <prompt>` is the client git
+// starts for every question.
+package askpass
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SocketEnv names the environment variable that carries the server address
+// to the askpass client.
+const SocketEnv = "GITBATCH_ASKPASS_SOCKET"
+
+// ErrCancelled is returned by Ask when the user dismissed the question.
+var ErrCancelled = errors.New("askpass: question cancelled")
+
+// Request is a single question asked by git or ssh.
+type Request struct {
+	Prompt string `json:"prompt"`
+	// Dir is the working directory of the asking process, i.e. the
+	// repository the question belongs to.
+	Dir string `json:"dir,omitempty"`
+}
+
+// Response carries the answer back to the askpass client.
+type Response struct {
+	Answer string `json:"answer"`
+	OK     bool   `json:"ok"`
+}
+
+// Handler answers a request. It blocks until the user replied; ok is false
+// when the question was cancelled.
+type Handler func(Request) (answer string, ok bool)
+
+// Secret reports whether the answer to a prompt must not be echoed.
+func (r Request) Secret() bool {
+	lower := strings.ToLower(r.Prompt)
+	for _, word := range []string{"password", "passphrase", "token", "pin"} {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Server accepts askpass requests on a unix socket.
+type Server struct {
+	listener net.Listener
+	dir      string
+	handler  Handler
+	wg       sync.WaitGroup
+}
+
+// Listen starts a server in a private temporary directory.
+func Listen(handler Handler) (*Server, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("askpass: handler required")
+	}
+	dir, err := os.MkdirTemp("", "gitbatch-askpass-")
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", filepath.Join(dir, "socket"))
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	s := &Server{listener: listener, dir: dir, handler: handler}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the socket path clients connect to.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops accepting requests and removes the socket.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	_ = os.RemoveAll(s.dir)
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	var req Request
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		return
+	}
+	answer, ok := s.handler(req)
+	_ = json.NewEncoder(conn).Encode(Response{Answer: answer, OK: ok})
+}
+
+// Ask sends a question to the server listening on socket and returns the
+// user's answer.
+func Ask(socket string, req Request) (string, error) {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return "", err
+	}
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return "", err
+	}
+	if !resp.OK {
+		return "", ErrCancelled
+	}
+	return resp.Answer, nil
+}
+
+// Main implements `gitbatch --askpass <prompt>`: it forwards the prompt to
+// the interface and prints the answer for git or ssh. The exit code is
+// non-zero when no answer is available, which makes git fail the operation.
+func Main(args []string, stdout, stderr io.Writer) int {
+	prompt := strings.Join(args, " ")
+	socket := os.Getenv(SocketEnv)
+	if socket == "" {
+		fmt.Fprintln(stderr, prompt)
+		return 1
+	}
+	dir, _ := os.Getwd()
+	answer, err := Ask(socket, Request{Prompt: prompt, Dir: dir})
+	if err != nil {
+		fmt.Fprintln(stderr, prompt)
+		return 1
+	}
+	fmt.Fprintln(stdout, answer)
+	return 0
+}
@@ -0,0 +1,58 @@
+package askpass
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAskRoundTrip(t *testing.T) {
+	var got Request
+	server, err := Listen(func(req Request) (string, bool) {
+		got = req
+		return "s3cr3t", true
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	answer, err := Ask(server.Addr(), Request{Prompt: "Password for 'https://alice@example.com': ", Dir: "/src/api"})
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", answer)
+	require.Equal(t, "/src/api", got.Dir)
+	require.True(t, got.Secret())
+}
+
+func TestAskCancelled(t *testing.T) {
+	server, err := Listen(func(Request) (string, bool) { return "", false })
+	require.NoError(t, err)
+	defer server.Close()
+
+	_, err = Ask(server.Addr(), Request{Prompt: "Username for 'https://example.com': "})
+	require.ErrorIs(t, err, ErrCancelled)
+}
+
+func TestMainForwardsPromptToServer(t *testing.T) {
+	server, err := Listen(func(req Request) (string, bool) {
+		require.False(t, req.Secret())
+		return "alice", true
+	})
+	require.NoError(t, err)
+	defer server.Close()
+	t.Setenv(SocketEnv, server.Addr())
+
+	var stdout, stderr bytes.Buffer
+	code := Main([]string{"Username for 'https://example.com': "}, &stdout, &stderr)
+	require.Equal(t, 0, code)
+	require.Equal(t, "alice\n", stdout.String())
+}
+
+func TestMainWithoutServerFails(t *testing.T) {
+	t.Setenv(SocketEnv, "")
+
+	var stdout, stderr bytes.Buffer
+	code := Main([]string{"Password: "}, &stdout, &stderr)
+	require.Equal(t, 1, code)
+	require.Empty(t, stdout.String())
+	require.Contains(t, stderr.String(), "Password:")
+}
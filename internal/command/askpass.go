@@ -0,0 +1,161 @@
+package command
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/thorstenhirsch/gitbatch/internal/askpass"
+)
+
+// askpassScript is installed as GIT_ASKPASS and SSH_ASKPASS. A passphrase
+// supplied up front is answered directly. Next the askpass program the user
+// configured gets the question, and only when it gives no answer is the
+// question forwarded to the interface through `gitbatch --askpass`, if the
+// bridge is running. Without an answer the question is echoed on stderr so
+// the prompt detection in runWithEnv still recognises it.
+const askpassScript = `#!/bin/sh
+case "$1" in
+*assphrase*)
+	if [ -n "$GITBATCH_PASSPHRASE" ]; then
+		printf '%s\n' "$GITBATCH_PASSPHRASE"
+		exit 0
+	fi
+	;;
+esac
+if [ -n "$GITBATCH_USER_ASKPASS" ] && answer=$(sh -c "$GITBATCH_USER_ASKPASS"' "$@"' "$GITBATCH_USER_ASKPASS" "$1" </dev/null) && [ -n "$answer" ]; then
+	printf '%s\n' "$answer"
+	exit 0
+fi
+if [ -n "$GITBATCH_ASKPASS_SOCKET" ] && [ -n "$GITBATCH_EXECUTABLE" ]; then
+	exec "$GITBATCH_EXECUTABLE" --askpass "$1"
+fi
+printf '%s\n' "$1" >&2
+exit 1
+`
+
+// askpassSSHCommand runs ssh without BatchMode so it may ask through
+// SSH_ASKPASS; SSH_ASKPASS_REQUIRE=force keeps it away from the terminal.
+const askpassSSHCommand = "ssh -o ConnectTimeout=5 -o ConnectionAttempts=1 -o NumberOfPasswordPrompts=1"
+
+var (
+	askpassBridgeMu     sync.RWMutex
+	askpassBridgeSocket string
+
+	userAskpassOnce sync.Once
+	userAskpassPath string
+)
+
+// SetAskpassBridge routes credential and passphrase questions of git and ssh
+// to the askpass server listening on socket. An empty socket disables the
+// bridge and restores the kill-on-prompt fallback.
+func SetAskpassBridge(socket string) {
+	askpassBridgeMu.Lock()
+	askpassBridgeSocket = socket
+	askpassBridgeMu.Unlock()
+}
+
+func askpassBridge() string {
+	askpassBridgeMu.RLock()
+	defer askpassBridgeMu.RUnlock()
+	return askpassBridgeSocket
+}
+
+// askpassBridgeEnv returns the environment that points git and ssh at the
+// askpass bridge through script, or nil when the bridge is not running.
+func askpassBridgeEnv(script string) []string {
+	socket := askpassBridge()
+	if socket == "" || script == "" {
+		return nil
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return nil
+	}
+	env := []string{
+		"GIT_ASKPASS=" + script,
+		"SSH_ASKPASS=" + script,
+		"SSH_ASKPASS_REQUIRE=force",
+		"GIT_SSH_COMMAND=" + askpassSSHCommand,
+		askpass.SocketEnv + "=" + socket,
+		"GITBATCH_EXECUTABLE=" + executable,
+	}
+	if program := userAskpass(); program != "" && program != script {
+		env = append(env, "GITBATCH_USER_ASKPASS="+program)
+	}
+	return env
+}
+
+// userAskpass returns the askpass program the user configured. The bridge
+// replaces GIT_ASKPASS, so the script asks it before the interface does.
+func userAskpass() string {
+	userAskpassOnce.Do(func() {
+		userAskpassPath = lookupUserAskpass(os.Getenv)
+	})
+	return userAskpassPath
+}
+
+// lookupUserAskpass follows the order in which git picks an askpass program:
+// GIT_ASKPASS, core.askPass, then SSH_ASKPASS. The result is a shell command
+// the script runs with `sh -c` like git does, so a configured
+// "prog --flag" keeps its arguments. SSH_ASKPASS is a plain path to ssh and
+// is quoted.
+func lookupUserAskpass(getenv func(string) string) string {
+	if program := strings.TrimSpace(getenv("GIT_ASKPASS")); program != "" {
+		return program
+	}
+	if out, err := exec.Command("git", "config", "--get", "core.askPass").Output(); err == nil {
+		if program := strings.TrimSpace(string(out)); program != "" {
+			return program
+		}
+	}
+	if program := strings.TrimSpace(getenv("SSH_ASKPASS")); program != "" {
+		return shellQuote(program)
+	}
+	return ""
+}
+
+// shellQuote quotes s as a single word for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// needsAskpassScript reports whether a command run with extraEnv asks
+// through askpassScript: always while the bridge is running, otherwise when
+// a passphrase is handed to ssh.
+func needsAskpassScript(extraEnv []string) bool {
+	return askpassBridge() != "" || hasPassphrase(extraEnv)
+}
+
+// hasPassphrase reports whether env hands a key passphrase to ssh.
+func hasPassphrase(env []string) bool {
+	for _, entry := range env {
+		if strings.HasPrefix(entry, passphraseKey+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeAskpassScript writes askpassScript to a new temporary file. The caller
+// removes it once the command that uses it has finished.
+func writeAskpassScript() (path string, err error) {
+	f, err := os.CreateTemp("", "gitbatch-askpass-*.sh")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+	if _, err := f.WriteString(askpassScript); err != nil {
+		return "", err
+	}
+	if err := f.Chmod(0o700); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
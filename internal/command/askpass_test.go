@@ -0,0 +1,89 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runAskpassScript(t *testing.T, prompt string, env ...string) (string, error) {
+	t.Helper()
+	script, err := writeAskpassScript()
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(script) })
+	cmd := exec.Command(script, prompt)
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func TestAskpassScriptAsksTheUsersAskpassFirst(t *testing.T) {
+	dir := t.TempDir()
+	answering := filepath.Join(dir, "answering")
+	require.NoError(t, os.WriteFile(answering, []byte("#!/bin/sh\necho \"token for $1\"\n"), 0o700))
+	declining := filepath.Join(dir, "declining")
+	require.NoError(t, os.WriteFile(declining, []byte("#!/bin/sh\nexit 1\n"), 0o700))
+
+	out, err := runAskpassScript(t, "Password for 'https://example.com':", "GITBATCH_USER_ASKPASS="+answering)
+	require.NoError(t, err)
+	require.Equal(t, "token for Password for 'https://example.com':", out)
+
+	out, err = runAskpassScript(t, "Enter passphrase for key 'id':", "GITBATCH_USER_ASKPASS="+answering, "GITBATCH_PASSPHRASE=secret")
+	require.NoError(t, err)
+	require.Equal(t, "secret", out, "a passphrase typed into gitbatch wins")
+
+	// Like git, the configured program is a shell command with arguments.
+	out, err = runAskpassScript(t, "Password for 'https://example.com':", "GITBATCH_USER_ASKPASS="+answering+" --flag")
+	require.NoError(t, err)
+	require.Equal(t, "token for --flag", out)
+	spaced := filepath.Join(dir, "ask pass")
+	require.NoError(t, os.WriteFile(spaced, []byte("#!/bin/sh\necho \"spaced $1\"\n"), 0o700))
+	out, err = runAskpassScript(t, "Password:", "GITBATCH_USER_ASKPASS="+shellQuote(spaced))
+	require.NoError(t, err)
+	require.Equal(t, "spaced Password:", out)
+
+	// Without an answer and without the bridge the question fails as before.
+	_, err = runAskpassScript(t, "Username for 'https://example.com':", "GITBATCH_USER_ASKPASS="+declining, "GITBATCH_ASKPASS_SOCKET=")
+	require.Error(t, err)
+}
+
+func TestLookupUserAskpassFollowsGitsOrder(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	env := map[string]string{"GIT_ASKPASS": "/usr/bin/git-askpass", "SSH_ASKPASS": "/usr/bin/ssh-askpass"}
+	getenv := func(key string) string { return env[key] }
+
+	require.Equal(t, "/usr/bin/git-askpass", lookupUserAskpass(getenv))
+
+	delete(env, "GIT_ASKPASS")
+	require.Equal(t, "'/usr/bin/ssh-askpass'", lookupUserAskpass(getenv), "ssh runs SSH_ASKPASS without a shell")
+
+	_, err := Run("", "git", []string{"config", "--global", "core.askPass", "/usr/bin/ksshaskpass"})
+	require.NoError(t, err)
+	require.Equal(t, "/usr/bin/ksshaskpass", lookupUserAskpass(getenv))
+}
+
+func TestRunRemovesTheAskpassScript(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	SetAskpassBridge(filepath.Join(tmp, "bridge.sock"))
+	t.Cleanup(func() { SetAskpassBridge("") })
+
+	_, err := Run("", "git", []string{"version"})
+	require.NoError(t, err)
+	_, err = Run("", "git", []string{"no-such-command"})
+	require.Error(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = RunWithContext(ctx, "", "git", []string{"version"})
+	require.Error(t, err)
+
+	scripts, err := filepath.Glob(filepath.Join(tmp, "gitbatch-askpass-*"))
+	require.NoError(t, err)
+	require.Empty(t, scripts)
+}
@@ -0,0 +1,220 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// BisectVerdict marks the commit under test.
+type BisectVerdict string
+
+const (
+	// BisectGood marks a commit without the regression.
+	BisectGood BisectVerdict = "good"
+	// BisectBad marks a commit with the regression.
+	BisectBad BisectVerdict = "bad"
+	// BisectSkip marks a commit that cannot be tested.
+	BisectSkip BisectVerdict = "skip"
+)
+
+// bisectRunTimeout is how long `git bisect run` may stay silent. The test
+// command of a step often builds the project without printing anything.
+const bisectRunTimeout = 10 * time.Minute
+
+var (
+	bisectCommandMu sync.Mutex
+	bisectCommand   string
+)
+
+// SetBisectCommand configures the shell command `git bisect run` executes
+// for every step. Its exit code decides: 0 is good, 125 skips, 1-127 is bad.
+func SetBisectCommand(command string) {
+	bisectCommandMu.Lock()
+	bisectCommand = strings.TrimSpace(command)
+	bisectCommandMu.Unlock()
+}
+
+// BisectCommand returns the configured test command, empty if none is set.
+func BisectCommand() string {
+	bisectCommandMu.Lock()
+	defer bisectCommandMu.Unlock()
+	return bisectCommand
+}
+
+// BisectProgress describes the bisect session of a repository.
+type BisectProgress struct {
+	// Active is false when no bisect session is running.
+	Active bool
+	// Current is the commit checked out for testing as "<sha> <subject>".
+	Current string
+	// Remaining is the number of revisions left to test after the current one.
+	Remaining int
+	// Steps is roughly the number of steps left.
+	Steps int
+	// FirstBad is the first bad commit as "<sha> <subject>" once it is found.
+	FirstBad string
+}
+
+// BisectStartWithContext starts a session between a known bad and a known
+// good revision and checks out the first commit to test.
+func BisectStartWithContext(ctx context.Context, r *git.Repository, bad, good string) (*BisectProgress, error) {
+	bad, good = strings.TrimSpace(bad), strings.TrimSpace(good)
+	if bad == "" || good == "" {
+		return nil, fmt.Errorf("bad and good revisions are required")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if BisectInProgress(ctx, r) {
+		return nil, fmt.Errorf("a bisect is already in progress")
+	}
+	for _, ref := range []string{bad, good} {
+		if _, err := RunWithContextTimeout(ctx, r.AbsPath, "git", []string{"rev-parse", "--verify", "--quiet", ref + "^{commit}"}, DefaultGitCommandTimeout); err != nil {
+			return nil, fmt.Errorf("unknown ref %s", ref)
+		}
+	}
+	if out, err := RunWithContextTimeout(ctx, r.AbsPath, "git", []string{"bisect", "start", bad, good}, DefaultGitCommandTimeout); err != nil {
+		_, _ = RunWithContextTimeout(context.Background(), r.AbsPath, "git", []string{"bisect", "reset"}, DefaultGitCommandTimeout)
+		return nil, gerr.ParseGitError(out, err)
+	}
+	return BisectStatus(ctx, r)
+}
+
+// BisectMarkWithContext marks the commit under test and moves on to the next.
+func BisectMarkWithContext(ctx context.Context, r *git.Repository, verdict BisectVerdict) (*BisectProgress, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !BisectInProgress(ctx, r) {
+		return nil, fmt.Errorf("no bisect in progress")
+	}
+	if out, err := RunWithContextTimeout(ctx, r.AbsPath, "git", []string{"bisect", string(verdict)}, DefaultGitCommandTimeout); err != nil {
+		return nil, gerr.ParseGitError(out, err)
+	}
+	return BisectStatus(ctx, r)
+}
+
+// BisectRunWithContext lets `git bisect run` test the remaining commits with
+// the configured command until the first bad commit is found.
+func BisectRunWithContext(ctx context.Context, r *git.Repository) (*BisectProgress, error) {
+	command := BisectCommand()
+	if command == "" {
+		return nil, fmt.Errorf("no bisect_command configured")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !BisectInProgress(ctx, r) {
+		return nil, fmt.Errorf("no bisect in progress")
+	}
+	if out, err := RunWithContextTimeout(ctx, r.AbsPath, "git", []string{"bisect", "run", "sh", "-c", command}, bisectRunTimeout); err != nil {
+		return nil, gerr.ParseGitError(out, err)
+	}
+	return BisectStatus(ctx, r)
+}
+
+// BisectResetWithContext ends the session and returns to the branch it was
+// started on.
+func BisectResetWithContext(ctx context.Context, r *git.Repository) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if out, err := RunWithContextTimeout(ctx, r.AbsPath, "git", []string{"bisect", "reset"}, DefaultGitCommandTimeout); err != nil {
+		return "", gerr.ParseGitError(out, err)
+	}
+	return "bisect reset", nil
+}
+
+// BisectInProgress reports whether a bisect session is running.
+func BisectInProgress(ctx context.Context, r *git.Repository) bool {
+	out, err := RunWithContextTimeout(ctx, r.AbsPath, "git", []string{"rev-parse", "--git-path", "BISECT_START"}, DefaultGitCommandTimeout)
+	if err != nil {
+		return false
+	}
+	path := strings.TrimSpace(out)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.AbsPath, path)
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// BisectStep is one step of a bisect session, e.g. marking the commit under
+// test, and returns the progress afterwards.
+type BisectStep func(ctx context.Context) (*BisectProgress, error)
+
+// BisectSummary describes the progress of a session in the message of the
+// repository.
+func BisectSummary(progress *BisectProgress) string {
+	switch {
+	case progress == nil || !progress.Active:
+		return "bisect reset"
+	case progress.FirstBad != "":
+		return "first bad commit: " + progress.FirstBad
+	default:
+		return fmt.Sprintf("bisecting: %d revision(s) left (roughly %d step(s))", progress.Remaining, progress.Steps)
+	}
+}
+
+// BisectStatus reads the progress of the running session from the
+// refs/bisect refs.
+func BisectStatus(ctx context.Context, r *git.Repository) (*BisectProgress, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	progress := &BisectProgress{}
+	if !BisectInProgress(ctx, r) {
+		return progress, nil
+	}
+	progress.Active = true
+
+	out, err := RunWithContextTimeout(ctx, r.AbsPath, "git", []string{"for-each-ref", "--format=%(refname)", "refs/bisect/good-*"}, DefaultGitCommandTimeout)
+	if err != nil {
+		return nil, gerr.ParseGitError(out, err)
+	}
+	args := []string{"rev-list", "--bisect-vars", "refs/bisect/bad", "--not"}
+	args = append(args, strings.Fields(out)...)
+	out, err = RunWithContextTimeout(ctx, r.AbsPath, "git", args, DefaultGitCommandTimeout)
+	if err != nil {
+		return nil, gerr.ParseGitError(out, err)
+	}
+	vars := parseBisectVars(out)
+	if all, _ := strconv.Atoi(vars["bisect_all"]); all <= 1 {
+		progress.FirstBad = describeCommit(ctx, r, "refs/bisect/bad")
+		return progress, nil
+	}
+	progress.Remaining, _ = strconv.Atoi(vars["bisect_nr"])
+	progress.Steps, _ = strconv.Atoi(vars["bisect_steps"])
+	progress.Current = describeCommit(ctx, r, "HEAD")
+	return progress, nil
+}
+
+// parseBisectVars reads the shell assignments of `git rev-list --bisect-vars`.
+func parseBisectVars(out string) map[string]string {
+	vars := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		name, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		vars[name] = strings.Trim(value, "'")
+	}
+	return vars
+}
+
+func describeCommit(ctx context.Context, r *git.Repository, rev string) string {
+	out, err := RunWithContextTimeout(ctx, r.AbsPath, "git", []string{"log", "-1", "--format=%h %s", rev}, DefaultGitCommandTimeout)
+	if err != nil {
+		return rev
+	}
+	return strings.TrimSpace(out)
+}
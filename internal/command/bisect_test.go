@@ -0,0 +1,71 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestBisect_FindsFirstBadCommit(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	for i := 1; i <= 6; i++ {
+		content := fmt.Sprintf("ok %d", i)
+		if i >= 4 {
+			content = fmt.Sprintf("broken %d", i)
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, "state.txt"), []byte(content), 0o644))
+		for _, args := range [][]string{
+			{"add", "state.txt"},
+			{"-c", "user.email=test@example.com", "-c", "user.name=Test User", "commit", "-m", fmt.Sprintf("step %d", i)},
+		} {
+			out, err := Run(repoPath, "git", args)
+			require.NoError(t, err, out)
+		}
+	}
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	progress, err := BisectStartWithContext(ctx, repo, "HEAD", "HEAD~6")
+	require.NoError(t, err)
+	require.True(t, progress.Active)
+	require.NotEmpty(t, progress.Current)
+	require.Empty(t, progress.FirstBad)
+	_, err = BisectStartWithContext(ctx, repo, "HEAD", "HEAD~6")
+	require.Error(t, err)
+
+	_, err = BisectRunWithContext(ctx, repo)
+	require.Error(t, err)
+	SetBisectCommand("! grep -q broken state.txt")
+	t.Cleanup(func() { SetBisectCommand("") })
+	progress, err = BisectRunWithContext(ctx, repo)
+	require.NoError(t, err)
+	require.Contains(t, progress.FirstBad, "step 4")
+
+	_, err = BisectResetWithContext(ctx, repo)
+	require.NoError(t, err)
+	progress, err = BisectStatus(ctx, repo)
+	require.NoError(t, err)
+	require.False(t, progress.Active)
+
+	// Marking by hand converges on the same commit.
+	progress, err = BisectStartWithContext(ctx, repo, "HEAD", "HEAD~6")
+	require.NoError(t, err)
+	for progress.FirstBad == "" {
+		content, err := os.ReadFile(filepath.Join(repoPath, "state.txt"))
+		require.NoError(t, err)
+		verdict := BisectGood
+		if strings.HasPrefix(string(content), "broken") {
+			verdict = BisectBad
+		}
+		progress, err = BisectMarkWithContext(ctx, repo, verdict)
+		require.NoError(t, err)
+	}
+	require.Contains(t, progress.FirstBad, "step 4")
+}
@@ -0,0 +1,171 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// CheckoutOptions defines the rules of the checkout operation.
+type CheckoutOptions struct {
+	// Branch is the local branch to switch to, or the commit to detach at.
+	Branch string
+	// StartPoint creates Branch from this ref, e.g. a remote branch.
+	StartPoint string
+	// Detach checks out Branch as a detached HEAD.
+	Detach bool
+	// Stash stashes the local changes before switching and re-applies them
+	// afterwards, for changes the checkout would otherwise overwrite.
+	Stash bool
+}
+
+// DeleteBranchOptions defines the rules of the branch delete operation.
+type DeleteBranchOptions struct {
+	// Branch is the name of the branch to delete.
+	Branch string
+	// Remote deletes the branch on this remote instead of the local one.
+	Remote string
+}
+
+// SetUpstreamOptions defines the rules of the set upstream operation.
+type SetUpstreamOptions struct {
+	// Branch is the local branch whose upstream is set.
+	Branch string
+	// Upstream is the remote branch to track, e.g. "origin/main".
+	Upstream string
+}
+
+// CheckoutWithContext switches to a branch, creating it from the start point
+// if one is given.
+func CheckoutWithContext(ctx context.Context, r *git.Repository, options *CheckoutOptions) (string, error) {
+	if options == nil || strings.TrimSpace(options.Branch) == "" {
+		return "", fmt.Errorf("checkout branch is required")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	stashed := false
+	if options.Stash {
+		out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"stash", "push", "--include-untracked", "-m", "gitbatch: checkout " + options.Branch})
+		if err != nil {
+			return "", gerr.ParseGitError(out, err)
+		}
+		stashed = !strings.Contains(out, "No local changes to save")
+	}
+
+	args := []string{"checkout", options.Branch}
+	switch {
+	case options.Detach:
+		args = []string{"checkout", "--detach", options.Branch}
+	case options.StartPoint != "":
+		args = []string{"checkout", "-b", options.Branch, options.StartPoint}
+	}
+	out, err := RunWithContext(ctx, r.AbsPath, "git", args)
+	if err != nil {
+		if stashed {
+			// Nothing was switched, so the changes apply cleanly again.
+			_, _ = RunWithContext(ctx, r.AbsPath, "git", []string{"stash", "pop"})
+		}
+		return "", gerr.ParseGitError(out, err)
+	}
+	msg := fmt.Sprintf("switched to %s", options.Branch)
+	if options.Detach {
+		msg = fmt.Sprintf("detached at %s", options.Branch)
+	}
+	if !stashed {
+		return msg, nil
+	}
+	if out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"stash", "pop"}); err != nil {
+		return "", fmt.Errorf("%s, but the stashed changes conflict; resolve them and drop stash@{0}: %w", msg, gerr.ParseGitError(out, err))
+	}
+	return msg + ", local changes re-applied", nil
+}
+
+// CheckoutConflicts lists the locally changed and untracked files a checkout
+// would overwrite: those that also differ between HEAD and the target.
+func CheckoutConflicts(ctx context.Context, r *git.Repository, options *CheckoutOptions) ([]string, error) {
+	if options == nil || strings.TrimSpace(options.Branch) == "" {
+		return nil, fmt.Errorf("checkout branch is required")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	target := options.Branch
+	if options.StartPoint != "" {
+		target = options.StartPoint
+	}
+
+	local := make(map[string]bool)
+	for _, args := range [][]string{
+		{"diff", "--name-only", "HEAD", "--"},
+		{"ls-files", "--others", "--exclude-standard"},
+	} {
+		out, err := RunWithContext(ctx, r.AbsPath, "git", args)
+		if err != nil {
+			return nil, gerr.ParseGitError(out, err)
+		}
+		for _, path := range strings.Split(out, "\n") {
+			if path != "" {
+				local[path] = true
+			}
+		}
+	}
+	if len(local) == 0 {
+		return nil, nil
+	}
+
+	out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"diff", "--name-only", "HEAD", target, "--"})
+	if err != nil {
+		return nil, gerr.ParseGitError(out, err)
+	}
+	var conflicts []string
+	for _, path := range strings.Split(out, "\n") {
+		if local[path] {
+			conflicts = append(conflicts, path)
+		}
+	}
+	return conflicts, nil
+}
+
+// DeleteBranchWithContext deletes a merged local branch, or a branch on a
+// remote.
+func DeleteBranchWithContext(ctx context.Context, r *git.Repository, options *DeleteBranchOptions) (string, error) {
+	if options == nil || strings.TrimSpace(options.Branch) == "" {
+		return "", fmt.Errorf("branch to delete is required")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	args := []string{"branch", "-d", options.Branch}
+	name := options.Branch
+	if options.Remote != "" {
+		args = append([]string{"push", options.Remote, "--delete", options.Branch}, noVerifyArgs(r)...)
+		name = options.Remote + "/" + options.Branch
+	}
+	out, err := RunWithContext(ctx, r.AbsPath, "git", args)
+	if err != nil {
+		return "", gerr.ParseGitError(out, err)
+	}
+	return fmt.Sprintf("deleted %s", name), nil
+}
+
+// SetUpstreamWithContext makes a remote branch the upstream of a local one.
+func SetUpstreamWithContext(ctx context.Context, r *git.Repository, options *SetUpstreamOptions) (string, error) {
+	if options == nil || strings.TrimSpace(options.Branch) == "" || strings.TrimSpace(options.Upstream) == "" {
+		return "", fmt.Errorf("branch and upstream are required")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"branch", "--set-upstream-to=" + options.Upstream, options.Branch})
+	if err != nil {
+		return "", gerr.ParseGitError(out, err)
+	}
+	return fmt.Sprintf("%s now tracks %s", options.Branch, options.Upstream), nil
+}
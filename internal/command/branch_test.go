@@ -0,0 +1,113 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func currentBranch(t *testing.T, dir string) string {
+	t.Helper()
+	out, err := Run(dir, "git", []string{"rev-parse", "--abbrev-ref", "HEAD"})
+	require.NoError(t, err)
+	return strings.TrimSpace(out)
+}
+
+func TestBranchOperationsWithContext(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	_, err := Run(repoPath, "git", []string{"push", "origin", "main:release"})
+	require.NoError(t, err)
+	_, err = Run(repoPath, "git", []string{"fetch", "origin"})
+	require.NoError(t, err)
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	msg, err := CheckoutWithContext(ctx, repo, &CheckoutOptions{Branch: "release", StartPoint: "origin/release"})
+	require.NoError(t, err)
+	require.Equal(t, "switched to release", msg)
+	require.Equal(t, "release", currentBranch(t, repoPath))
+
+	msg, err = SetUpstreamWithContext(ctx, repo, &SetUpstreamOptions{Branch: "release", Upstream: "origin/main"})
+	require.NoError(t, err)
+	require.Equal(t, "release now tracks origin/main", msg)
+
+	_, err = CheckoutWithContext(ctx, repo, &CheckoutOptions{Branch: "main"})
+	require.NoError(t, err)
+	msg, err = DeleteBranchWithContext(ctx, repo, &DeleteBranchOptions{Branch: "release"})
+	require.NoError(t, err)
+	require.Equal(t, "deleted release", msg)
+
+	msg, err = DeleteBranchWithContext(ctx, repo, &DeleteBranchOptions{Branch: "release", Remote: "origin"})
+	require.NoError(t, err)
+	require.Equal(t, "deleted origin/release", msg)
+	out, err := Run(repoPath, "git", []string{"ls-remote", "--heads", "origin", "release"})
+	require.NoError(t, err)
+	require.Empty(t, strings.TrimSpace(out))
+
+	_, err = CheckoutWithContext(ctx, repo, &CheckoutOptions{Branch: "missing"})
+	require.Error(t, err)
+}
+
+func TestCheckoutStashesChangesTheCheckoutWouldOverwrite(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	lines := filepath.Join(repoPath, "lines.txt")
+	require.NoError(t, os.WriteFile(lines, []byte("a\nb\nc\nd\ne\n"), 0o644))
+	_, err := Run(repoPath, "git", []string{"add", "lines.txt"})
+	require.NoError(t, err)
+	_, err = Run(repoPath, "git", []string{"commit", "-m", "lines"})
+	require.NoError(t, err)
+	_, err = Run(repoPath, "git", []string{"checkout", "-b", "feature"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(lines, []byte("A\nb\nc\nd\ne\n"), 0o644))
+	_, err = Run(repoPath, "git", []string{"commit", "-am", "feature lines"})
+	require.NoError(t, err)
+	_, err = Run(repoPath, "git", []string{"checkout", "main"})
+	require.NoError(t, err)
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "notes.txt"), []byte("untouched"), 0o644))
+	conflicts, err := CheckoutConflicts(ctx, repo, &CheckoutOptions{Branch: "feature"})
+	require.NoError(t, err)
+	require.Empty(t, conflicts, "files the branches do not change are carried along")
+
+	require.NoError(t, os.WriteFile(lines, []byte("a\nb\nc\nd\nE\n"), 0o644))
+	conflicts, err = CheckoutConflicts(ctx, repo, &CheckoutOptions{Branch: "feature"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"lines.txt"}, conflicts)
+	_, err = CheckoutWithContext(ctx, repo, &CheckoutOptions{Branch: "feature"})
+	require.Error(t, err)
+	require.Equal(t, "main", currentBranch(t, repoPath))
+
+	msg, err := CheckoutWithContext(ctx, repo, &CheckoutOptions{Branch: "feature", Stash: true})
+	require.NoError(t, err)
+	require.Equal(t, "switched to feature, local changes re-applied", msg)
+	require.Equal(t, "feature", currentBranch(t, repoPath))
+	for name, content := range map[string]string{"lines.txt": "A\nb\nc\nd\nE\n", "notes.txt": "untouched"} {
+		data, err := os.ReadFile(filepath.Join(repoPath, name))
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	}
+	out, err := Run(repoPath, "git", []string{"stash", "list"})
+	require.NoError(t, err)
+	require.Empty(t, out)
+}
+
+func TestPrepareDeleteBranchRefusesTheCurrentBranch(t *testing.T) {
+	repo := &git.Repository{State: &git.RepositoryState{Branch: &git.Branch{Name: "main"}}}
+
+	plan := NewExecutor(repo).prepareDeleteBranch(&DeleteBranchOptions{Branch: "main"})
+	require.NotNil(t, plan.immediate)
+	require.EqualError(t, plan.immediate.Err, "cannot delete current branch main")
+
+	plan = NewExecutor(repo).prepareDeleteBranch(&DeleteBranchOptions{Branch: "main", Remote: "origin"})
+	require.Nil(t, plan.immediate)
+	require.Equal(t, DefaultFetchTimeout, plan.request.Timeout)
+}
@@ -0,0 +1,106 @@
+package command
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommitSummary is a commit in a report over the workspace.
+type CommitSummary struct {
+	Hash    string
+	Author  string
+	Time    time.Time
+	Subject string
+	// Ref is the branch the commit was found on, for reports that ask.
+	Ref string
+}
+
+// commitLogFormat is the --format logCommits reads. %S is empty without
+// --source.
+const commitLogFormat = "--format=%H%x1f%an%x1f%ct%x1f%S%x1f%s"
+
+// CommitsSince returns the commits on the local and remote-tracking branches
+// of the repository in dir that were committed after since, newest first.
+// Merge commits are left out, they carry no work of their own.
+func CommitsSince(ctx context.Context, dir string, since time.Time) ([]CommitSummary, error) {
+	return logCommits(ctx, dir, []string{"--branches", "--remotes", "--no-merges", sinceArgument(since)})
+}
+
+// sinceArgument limits git log to commits after t.
+func sinceArgument(t time.Time) string {
+	return "--since=@" + strconv.FormatInt(t.Unix(), 10)
+}
+
+// logCommits runs git log with args in dir.
+func logCommits(ctx context.Context, dir string, args []string) ([]CommitSummary, error) {
+	out, err := RunWithContext(ctx, dir, "git", append([]string{"log", commitLogFormat}, args...))
+	if err != nil {
+		return nil, err
+	}
+	var commits []CommitSummary
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\x1f", 5)
+		if len(fields) != 5 {
+			continue
+		}
+		seconds, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		commits = append(commits, CommitSummary{Hash: fields[0], Author: fields[1], Time: time.Unix(seconds, 0), Ref: fields[3], Subject: fields[4]})
+	}
+	return commits, nil
+}
+
+// Shortlog counts the commits per author on the local and remote-tracking
+// branches of the repository in dir since since with git shortlog, most
+// commits first. Merge commits are left out.
+func Shortlog(ctx context.Context, dir string, since time.Time) ([]AuthorCount, error) {
+	out, err := RunWithContext(ctx, dir, "git", []string{
+		"shortlog", "-sn", "--no-merges", "--branches", "--remotes", sinceArgument(since),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var authors []AuthorCount
+	for _, line := range strings.Split(out, "\n") {
+		count, author, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		commits, err := strconv.Atoi(count)
+		if err != nil {
+			continue
+		}
+		authors = append(authors, AuthorCount{Author: author, Commits: commits})
+	}
+	return authors, nil
+}
+
+// AuthorCount is the number of commits of an author.
+type AuthorCount struct {
+	Author  string
+	Commits int
+}
+
+// CountAuthors returns the authors of commits, most commits first.
+func CountAuthors(commits []CommitSummary) []AuthorCount {
+	counts := make(map[string]int)
+	for _, commit := range commits {
+		counts[commit.Author]++
+	}
+	authors := make([]AuthorCount, 0, len(counts))
+	for author, count := range counts {
+		authors = append(authors, AuthorCount{Author: author, Commits: count})
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Commits != authors[j].Commits {
+			return authors[i].Commits > authors[j].Commits
+		}
+		return authors[i].Author < authors[j].Author
+	})
+	return authors
+}
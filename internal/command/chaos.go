@@ -0,0 +1,110 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// chaosMaxDelay is the longest delay --chaos adds before a git command.
+	chaosMaxDelay = 3 * time.Second
+	// chaosFailureRate is the share of network commands --chaos fails.
+	chaosFailureRate = 0.2
+	// chaosHangRate is the share of network commands --chaos keeps silent
+	// until their timeout fires.
+	chaosHangRate = 0.05
+)
+
+// chaosEnabled injects delays and failures into git commands.
+var chaosEnabled atomic.Bool
+
+// chaosRandom draws the dice of --chaos; tests replace it.
+var chaosRandom = rand.Float64
+
+// chaosNetworkCommands are the git commands that talk to a remote, the only
+// ones --chaos fails.
+var chaosNetworkCommands = map[string]bool{
+	"clone":     true,
+	"fetch":     true,
+	"ls-remote": true,
+	"pull":      true,
+	"push":      true,
+}
+
+// chaosFailures are the errors --chaos injects, as git reports a flaky
+// network.
+var chaosFailures = []string{
+	"fatal: unable to access 'https://chaos.invalid/': Could not resolve host: chaos.invalid",
+	"ssh: connect to host chaos.invalid port 22: Connection timed out\nfatal: Could not read from remote repository.",
+	"error: RPC failed; curl 56 Recv failure: Connection reset by peer\nfatal: the remote end hung up unexpectedly",
+}
+
+// SetChaos makes git commands start after a random delay of up to three
+// seconds and lets a fifth of the commands that contact a remote fail with a
+// network error, and a few of them hang until their timeout. It exercises the
+// timeout, retry and state evaluation paths, and shows how a configuration
+// copes with a flaky network.
+func SetChaos(enabled bool) {
+	chaosEnabled.Store(enabled)
+}
+
+// gitSubcommand returns the git command in args, after the global options.
+func gitSubcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "-c" || arg == "-C":
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			return arg
+		}
+	}
+	return ""
+}
+
+// injectChaos runs before a command when --chaos is on. It delays the git
+// commands of queued operations and reports whether the command must not
+// run, with the output and error it fails with instead. Commands outside the
+// git queue, e.g. the ones the interface runs to show a diff, are left alone.
+func injectChaos(ctx context.Context, c string, args []string, timeout time.Duration) (string, bool, error) {
+	if !chaosEnabled.Load() || c != "git" {
+		return "", false, nil
+	}
+	queueDone, queued := queuedDone(ctx)
+	if !queued {
+		return "", false, nil
+	}
+	network := chaosNetworkCommands[gitSubcommand(args)]
+	if network && chaosRandom() < chaosHangRate {
+		// A hanging command stays silent until its own timeout, or until
+		// the git queue gives up on the operation.
+		var expired <-chan time.Time
+		if timeout > 0 {
+			expired = time.After(timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return "", true, ctx.Err()
+		case <-queueDone:
+			return "", true, context.DeadlineExceeded
+		case <-expired:
+			return "", true, context.DeadlineExceeded
+		}
+	}
+
+	delay := time.Duration(chaosRandom() * float64(chaosMaxDelay))
+	select {
+	case <-ctx.Done():
+		return "", true, ctx.Err()
+	case <-time.After(delay):
+	}
+	if network && chaosRandom() < chaosFailureRate {
+		out := chaosFailures[int(chaosRandom()*float64(len(chaosFailures)))%len(chaosFailures)]
+		return out, true, errors.New("exit status 128")
+	}
+	return "", false, nil
+}
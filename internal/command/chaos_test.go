@@ -0,0 +1,67 @@
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// withChaos enables --chaos for the test with dice that return draws in
+// order.
+func withChaos(t *testing.T, draws ...float64) {
+	t.Helper()
+	SetChaos(true)
+	random := chaosRandom
+	chaosRandom = func() float64 {
+		draw := draws[0]
+		draws = draws[1:]
+		return draw
+	}
+	t.Cleanup(func() {
+		SetChaos(false)
+		chaosRandom = random
+	})
+}
+
+func TestGitSubcommand(t *testing.T) {
+	require.Equal(t, "fetch", gitSubcommand([]string{"-c", "credential.helper=", "--no-pager", "fetch", "origin"}))
+	require.Equal(t, "status", gitSubcommand([]string{"-C", "/src/api", "status"}))
+	require.Equal(t, "", gitSubcommand([]string{"--version"}))
+}
+
+func TestChaosFailsNetworkCommands(t *testing.T) {
+	queued := withQueued(context.Background(), make(chan struct{}))
+	withChaos(t, 0.9, 0, 0.1, 0)
+	out, err := RunWithContextTimeout(queued, t.TempDir(), "git", []string{"fetch", "origin"}, time.Minute)
+	require.EqualError(t, err, "exit status 128")
+	require.Equal(t, chaosFailures[0], out)
+
+	// Local commands are only delayed.
+	withChaos(t, 0)
+	out, err = RunWithContext(queued, t.TempDir(), "git", []string{"--version"})
+	require.NoError(t, err)
+	require.Contains(t, out, "git version")
+
+	// Commands outside the git queue are left alone: no dice are drawn.
+	withChaos(t)
+	out, err = Run(t.TempDir(), "git", []string{"--version"})
+	require.NoError(t, err)
+	require.Contains(t, out, "git version")
+}
+
+func TestChaosLetsNetworkCommandsTimeOut(t *testing.T) {
+	withChaos(t, 0)
+	queued := withQueued(context.Background(), make(chan struct{}))
+	_, err := RunWithContextTimeout(queued, t.TempDir(), "git", []string{"push"}, 10*time.Millisecond)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Without a timeout of its own the command hangs until the git queue
+	// gives up on it.
+	withChaos(t, 0)
+	done := make(chan struct{})
+	time.AfterFunc(10*time.Millisecond, func() { close(done) })
+	_, err = RunWithContext(withQueued(context.Background(), done), t.TempDir(), "git", []string{"push"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,167 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// CheckoutTransaction switches several repositories as one. Each repository
+// runs its checkout as a CheckoutStep on its own git queue. Once every step
+// ran or was dropped and one of them did not switch its repository, the
+// repositories that did switch go back to the branch or commit they were on
+// and branches the checkouts created are deleted again, so either all
+// repositories are switched or none.
+type CheckoutTransaction struct {
+	mu       sync.Mutex
+	pending  int
+	failed   string
+	switched []checkoutRecord
+}
+
+// CheckoutStep is the checkout of one repository in a CheckoutTransaction.
+type CheckoutStep struct {
+	Transaction *CheckoutTransaction
+	Options     *CheckoutOptions
+}
+
+// checkoutRecord is what a CheckoutTransaction needs to undo a checkout it
+// ran.
+type checkoutRecord struct {
+	repo     *git.Repository
+	options  CheckoutOptions
+	head     string
+	detached bool
+}
+
+// NewCheckoutTransaction returns a transaction over the given number of
+// checkouts.
+func NewCheckoutTransaction(steps int) *CheckoutTransaction {
+	return &CheckoutTransaction{pending: steps}
+}
+
+// failure returns the name of the first repository whose checkout failed.
+func (t *CheckoutTransaction) failure() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failed
+}
+
+// finish records the result of a step. The last step to finish keeps the
+// transaction or rolls it back. The other repositories that switched get a
+// rollback on their git queue and stay pending until it ran; the rollback of
+// r itself is returned for the step to run right away.
+func (t *CheckoutTransaction) finish(r *git.Repository, record *checkoutRecord) *checkoutRecord {
+	t.mu.Lock()
+	if record != nil {
+		t.switched = append(t.switched, *record)
+	} else if t.failed == "" {
+		t.failed = r.Name
+	}
+	t.pending--
+	if t.pending > 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	failed, switched := t.failed, t.switched
+	t.mu.Unlock()
+	if failed == "" {
+		for _, record := range switched {
+			pushCheckoutUndo(record)
+		}
+		return nil
+	}
+	var own *checkoutRecord
+	for _, record := range switched {
+		if record.head == record.options.Branch {
+			continue
+		}
+		if record.repo == r {
+			own = &record
+			continue
+		}
+		setRepositoryStatus(record.repo, git.Pending, i18n.T("rolling back checkout"))
+		if err := ScheduleGitCommand(record.repo, rollbackCheckoutRequest(record)); err != nil {
+			record.repo.MarkFailure(gerr.KindFatal, i18n.T("rollback failed, stays on %s: %s", record.options.Branch, err))
+		}
+	}
+	return own
+}
+
+// checkoutStepRequest runs the checkout of step in r unless another
+// repository of the transaction failed already.
+func checkoutStepRequest(r *git.Repository, step CheckoutStep) *GitCommandRequest {
+	options := *step.Options
+	return &GitCommandRequest{
+		Key:       fmt.Sprintf("checkout:%s", r.RepoID),
+		Timeout:   DefaultGitCommandTimeout,
+		Operation: OperationCheckout,
+		Execute: func(ctx context.Context) OperationOutcome {
+			if failed := step.Transaction.failure(); failed != "" {
+				step.Transaction.finish(r, nil)
+				return OperationOutcome{Operation: OperationCheckout, Message: i18n.T("skipped: checkout failed in %s", failed)}
+			}
+			head, detached, err := currentHead(ctx, r)
+			var msg string
+			if err == nil {
+				msg, err = CheckoutWithContext(ctx, r, &options)
+			}
+			if err != nil {
+				step.Transaction.finish(r, nil)
+				return OperationOutcome{Operation: OperationCheckout, Message: msg, Err: err}
+			}
+			if own := step.Transaction.finish(r, &checkoutRecord{repo: r, options: options, head: head, detached: detached}); own != nil {
+				return rollbackCheckout(ctx, *own)
+			}
+			return OperationOutcome{Operation: OperationCheckout, Message: msg}
+		},
+		Dropped: func(error) { step.Transaction.finish(r, nil) },
+	}
+}
+
+// rollbackCheckoutRequest queues the rollback of record.
+func rollbackCheckoutRequest(record checkoutRecord) *GitCommandRequest {
+	return &GitCommandRequest{
+		Key:       fmt.Sprintf("checkout-rollback:%s", record.repo.RepoID),
+		Timeout:   DefaultGitCommandTimeout,
+		Operation: OperationCheckout,
+		Execute: func(ctx context.Context) OperationOutcome {
+			return rollbackCheckout(ctx, record)
+		},
+	}
+}
+
+// rollbackCheckout switches the repository of record back and deletes the
+// branch its checkout created.
+func rollbackCheckout(ctx context.Context, record checkoutRecord) OperationOutcome {
+	r := record.repo
+	options := &CheckoutOptions{Branch: record.head, Detach: record.detached, Stash: record.options.Stash}
+	_, err := CheckoutWithContext(ctx, r, options)
+	if err == nil && record.options.StartPoint != "" {
+		var out string
+		if out, err = RunWithContext(ctx, r.AbsPath, "git", []string{"branch", "-D", record.options.Branch}); err != nil {
+			err = gerr.ParseGitError(out, err)
+		}
+	}
+	if err != nil {
+		return OperationOutcome{Operation: OperationCheckout, Err: fmt.Errorf("rollback failed, stays on %s: %w", record.options.Branch, err)}
+	}
+	return OperationOutcome{Operation: OperationCheckout, Message: i18n.T("checkout rolled back")}
+}
+
+// pushCheckoutUndo makes a kept checkout undoable.
+func pushCheckoutUndo(record checkoutRecord) {
+	if record.head == record.options.Branch {
+		return
+	}
+	pushUndo(record.repo, UndoEntry{
+		Kind:        UndoCheckout,
+		Description: "checkout " + record.options.Branch,
+		Ref:         record.head,
+		Detached:    record.detached,
+	})
+}
@@ -0,0 +1,86 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestCheckoutAllRollsBackWhenOneRepositoryFails(t *testing.T) {
+	alphaPath := initLocalWorktreeRepoForStateTest(t)
+	betaPath := initLocalWorktreeRepoForStateTest(t)
+	_, err := Run(alphaPath, "git", []string{"branch", "feature"})
+	require.NoError(t, err)
+	_, err = Run(alphaPath, "git", []string{"push", "origin", "main:release"})
+	require.NoError(t, err)
+	_, err = Run(alphaPath, "git", []string{"fetch", "origin"})
+	require.NoError(t, err)
+	alpha, err := git.InitializeRepo(alphaPath)
+	require.NoError(t, err)
+	beta, err := git.InitializeRepo(betaPath)
+	require.NoError(t, err)
+
+	checkoutAll(t, alpha, beta, CheckoutOptions{Branch: "feature"})
+	waitForCheckout(t, beta, func() bool { return beta.WorkStatus() == git.Fail })
+	waitForCheckout(t, alpha, func() bool { return alpha.State.Message() == "checkout rolled back" })
+	require.Equal(t, "main", currentBranch(t, alphaPath))
+	_, ok := PeekUndo(alpha)
+	require.False(t, ok, "a rolled back checkout leaves nothing to undo")
+
+	alpha.State.SetMessage("")
+	checkoutAll(t, alpha, beta, CheckoutOptions{Branch: "release", StartPoint: "origin/release"})
+	waitForCheckout(t, alpha, func() bool { return alpha.State.Message() == "checkout rolled back" })
+	require.Equal(t, "main", currentBranch(t, alphaPath))
+	_, err = Run(alphaPath, "git", []string{"rev-parse", "--verify", "refs/heads/release"})
+	require.Error(t, err, "the branch the checkout created is deleted again")
+
+	_, err = Run(betaPath, "git", []string{"branch", "feature"})
+	require.NoError(t, err)
+	checkoutAll(t, alpha, beta, CheckoutOptions{Branch: "feature"})
+	waitForCheckout(t, beta, func() bool {
+		_, ok := PeekUndo(beta)
+		return ok
+	})
+	require.Equal(t, "feature", currentBranch(t, alphaPath))
+	require.Equal(t, "feature", currentBranch(t, betaPath))
+	entry, ok := PeekUndo(beta)
+	require.True(t, ok)
+	require.Equal(t, "main", entry.Ref)
+}
+
+func TestCheckoutTransactionLeavesRepositoriesThatDidNotSwitch(t *testing.T) {
+	alpha, err := git.InitializeRepo(initLocalWorktreeRepoForStateTest(t))
+	require.NoError(t, err)
+	beta, err := git.InitializeRepo(initLocalWorktreeRepoForStateTest(t))
+	require.NoError(t, err)
+	_, err = Run(alpha.AbsPath, "git", []string{"checkout", "-b", "feature"})
+	require.NoError(t, err)
+
+	checkoutAll(t, alpha, beta, CheckoutOptions{Branch: "feature"})
+	waitForCheckout(t, beta, func() bool { return beta.WorkStatus() == git.Fail })
+	waitForCheckout(t, alpha, func() bool { return alpha.WorkStatus() == git.Available })
+	require.NotEqual(t, "checkout rolled back", alpha.State.Message(), "alpha was on feature already")
+	require.Equal(t, "feature", currentBranch(t, alpha.AbsPath))
+}
+
+// checkoutAll runs the same checkout in both repositories as one
+// transaction, alpha first.
+func checkoutAll(t *testing.T, alpha, beta *git.Repository, options CheckoutOptions) {
+	t.Helper()
+	transaction := NewCheckoutTransaction(2)
+	for _, r := range []*git.Repository{alpha, beta} {
+		r.SetWorkStatus(git.Pending)
+		opts := options
+		require.NoError(t, NewExecutor(r).ScheduleCheckoutStep(CheckoutStep{Transaction: transaction, Options: &opts}))
+		waitForCheckout(t, r, func() bool { return true })
+	}
+}
+
+func waitForCheckout(t *testing.T, r *git.Repository, done func() bool) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return !r.WorkStatus().InFlight() && done()
+	}, 10*time.Second, 20*time.Millisecond)
+}
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// cleanArgs removes untracked files and directories, ignored ones included,
+// e.g. build artifacts. Nested repositories are left alone.
+var cleanArgs = []string{"clean", "-d", "-x"}
+
+// CleanPreview lists what git clean would remove in a repository.
+type CleanPreview struct {
+	Repository *git.Repository
+	Paths      []string
+	Err        error
+}
+
+// PreviewClean runs git clean as a dry run and collects the paths it would
+// remove; directories end with a slash.
+func PreviewClean(ctx context.Context, r *git.Repository) *CleanPreview {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	preview := &CleanPreview{Repository: r}
+	out, err := RunWithContext(ctx, r.AbsPath, "git", append(append([]string(nil), cleanArgs...), "-n"))
+	if err != nil {
+		preview.Err = gerr.ParseGitError(out, err)
+		return preview
+	}
+	preview.Paths = cleanedPaths(out, "Would remove ")
+	return preview
+}
+
+// Clean removes the untracked and ignored files of r and records the command
+// in the audit log.
+func Clean(r *git.Repository) (string, error) {
+	out, err := RunRecorded(r, OperationClean, append(append([]string(nil), cleanArgs...), "-f"))
+	if err != nil {
+		return "", gerr.ParseGitError(out, err)
+	}
+	return fmt.Sprintf("removed %d untracked path(s)", len(cleanedPaths(out, "Removing "))), nil
+}
+
+func cleanedPaths(out, prefix string) []string {
+	var paths []string
+	for _, line := range strings.Split(out, "\n") {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(line), prefix); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,39 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestPreviewCleanListsUntrackedAndIgnoredFiles(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, ".gitignore"), []byte("build/\n"), 0o644))
+	_, err := Run(repoPath, "git", []string{"add", ".gitignore"})
+	require.NoError(t, err)
+	_, err = Run(repoPath, "git", []string{"commit", "-m", "ignore build"})
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "build"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "build", "app"), []byte("binary"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "notes.txt"), []byte("scratch"), 0o644))
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+
+	preview := PreviewClean(context.Background(), repo)
+	require.NoError(t, preview.Err)
+	require.Equal(t, []string{"build/", "notes.txt"}, preview.Paths)
+	require.FileExists(t, filepath.Join(repoPath, "notes.txt"), "the preview removes nothing")
+
+	msg, err := Clean(repo)
+	require.NoError(t, err)
+	require.Equal(t, "removed 2 untracked path(s)", msg)
+	require.NoDirExists(t, filepath.Join(repoPath, "build"))
+	require.NoFileExists(t, filepath.Join(repoPath, "notes.txt"))
+	require.FileExists(t, filepath.Join(repoPath, ".gitignore"))
+
+	require.Empty(t, PreviewClean(context.Background(), repo).Paths)
+}
@@ -23,6 +23,22 @@ var credentialPrompts = []*regexp.Regexp{
 	regexp.MustCompile(`.*2FA Token.*`),
 }
 
+// progressLine matches the progress meters git prints with --progress, e.g.
+// "Receiving objects:  42% (420/1000)" or "remote: Counting objects: 12".
+var progressLine = regexp.MustCompile(`^(remote: )?[A-Z][a-z]+( [a-z]+)*: +[0-9]`)
+
+// stripProgress removes the progress meters from the output of a command run
+// with --progress, so that error messages only keep what git reported.
+func stripProgress(out string) string {
+	var kept []string
+	for _, line := range strings.FieldsFunc(out, func(r rune) bool { return r == '\r' || r == '\n' }) {
+		if !progressLine.MatchString(line) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
 type scanningWriter struct {
 	buf      bytes.Buffer
 	callback func([]byte)
@@ -53,19 +69,63 @@ func RunWithContext(ctx context.Context, d string, c string, args []string) (str
 }
 
 // RunWithContextTimeout executes a command with the supplied context and optional timeout.
-
+// The timeout restarts whenever the command writes output, so it only fires
+// after the command has been silent for that long. Variables configured for
+// the directory through SetRepoEnv are added to the command's environment.
 func RunWithContextTimeout(ctx context.Context, d string, c string, args []string, timeout time.Duration) (string, error) {
+	return runWithEnv(ctx, d, c, args, nil, timeout)
+}
+
+// runWithEnv is RunWithContextTimeout with additional environment variables
+// for the child process, e.g. credentials handed to the git credential helper.
+func runWithEnv(ctx context.Context, d string, c string, args []string, extraEnv []string, timeout time.Duration) (string, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	recordCommand(ctx, c, args)
+	if out, injected, err := injectChaos(ctx, c, args, timeout); injected {
+		return out, err
+	}
 	cmd := exec.CommandContext(ctx, c, args...)
 	if d != "" {
 		cmd.Dir = d
 	}
-	cmd.Env = enrichGitEnv(os.Environ())
+	var script string
+	if needsAskpassScript(extraEnv) {
+		if path, err := writeAskpassScript(); err == nil {
+			script = path
+			defer os.Remove(script)
+		}
+	}
+	// Later entries win: the repo_env of the repository overrides gitbatch's
+	// defaults, including the ssh command that asks for a passphrase.
+	cmd.Env = enrichGitEnv(os.Environ(), script)
+	if script != "" && hasPassphrase(extraEnv) {
+		cmd.Env = append(cmd.Env, sshAskpassEnv(script)...)
+	}
+	cmd.Env = append(append(cmd.Env, repoEnv(d)...), extraEnv...)
 	var buf scanningWriter
 	credentialDetected := false
+	bridged := askpassBridge() != ""
+	active := make(chan struct{}, 1)
+	var progress *progressScanner
+	if report := progressReporter(ctx); report != nil {
+		progress = &progressScanner{report: report}
+	}
 	buf.callback = func(p []byte) {
+		reportActivity(ctx)
+		if progress != nil {
+			progress.write(p)
+		}
+		select {
+		case active <- struct{}{}:
+		default:
+		}
+		// With the askpass bridge running, questions reach the interface
+		// instead of the output and the process must not be killed.
+		if bridged {
+			return
+		}
 		s := string(p)
 		for _, re := range credentialPrompts {
 			if re.MatchString(s) {
@@ -88,62 +148,75 @@ func RunWithContextTimeout(ctx context.Context, d string, c string, args []strin
 	}()
 	var timer *time.Timer
 	var timeoutC <-chan time.Time
+	var activeC <-chan struct{}
 	if timeout > 0 {
 		timer = time.NewTimer(timeout)
 		timeoutC = timer.C
+		activeC = active
 	}
-	select {
-	case err := <-done:
-		if timer != nil {
-			timer.Stop()
-		}
-		if credentialDetected {
-			return trimTrailingNewline(buf.buf.String()), gerr.ErrCredentialPromptDetected
-		}
-		return trimTrailingNewline(buf.buf.String()), err
-	case <-ctx.Done():
-		if timer != nil {
-			timer.Stop()
-		}
-		err := <-done
-		if credentialDetected {
-			return trimTrailingNewline(buf.buf.String()), gerr.ErrCredentialPromptDetected
-		}
-		if ctx.Err() != nil {
-			return trimTrailingNewline(buf.buf.String()), ctx.Err()
-		}
-		return trimTrailingNewline(buf.buf.String()), err
-	case <-timeoutC:
-		if timer != nil {
-			timer.Stop()
-		}
-		go func() {
-			// If we timed out, it might be because it was waiting for a prompt we missed
-			// or just slow.
-			if cmd.Process != nil {
-				_ = cmd.Process.Kill()
+	for {
+		select {
+		case <-activeC:
+			timer.Reset(timeout)
+		case err := <-done:
+			if timer != nil {
+				timer.Stop()
+			}
+			if credentialDetected {
+				return trimTrailingNewline(buf.buf.String()), gerr.ErrCredentialPromptDetected
+			}
+			return trimTrailingNewline(buf.buf.String()), err
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
 			}
-			<-done
-		}()
-		if credentialDetected {
-			return trimTrailingNewline(buf.buf.String()), gerr.ErrCredentialPromptDetected
+			err := <-done
+			if credentialDetected {
+				return trimTrailingNewline(buf.buf.String()), gerr.ErrCredentialPromptDetected
+			}
+			if ctx.Err() != nil {
+				return trimTrailingNewline(buf.buf.String()), ctx.Err()
+			}
+			return trimTrailingNewline(buf.buf.String()), err
+		case <-timeoutC:
+			if timer != nil {
+				timer.Stop()
+			}
+			go func() {
+				// If we timed out, it might be because it was waiting for a prompt we missed
+				// or just slow.
+				if cmd.Process != nil {
+					_ = cmd.Process.Kill()
+				}
+				<-done
+			}()
+			if credentialDetected {
+				return trimTrailingNewline(buf.buf.String()), gerr.ErrCredentialPromptDetected
+			}
+			return trimTrailingNewline(buf.buf.String()), context.DeadlineExceeded
 		}
-		return trimTrailingNewline(buf.buf.String()), context.DeadlineExceeded
 	}
 }
 
-func enrichGitEnv(base []string) []string {
+// enrichGitEnv keeps git and ssh from prompting on the terminal. While the
+// askpass bridge is running they ask through script instead.
+func enrichGitEnv(base []string, script string) []string {
 	env := make([]string, len(base))
 	copy(env, base)
 	// Disable interactive terminal prompts so git fails fast instead of blocking.
 	// Credential prompts from SSH (which ignores GIT_TERMINAL_PROMPT) are caught
-	// by the scanningWriter above, which kills the process and returns ErrCredentialPromptDetected.
+	// by the scanningWriter above, which kills the process and returns ErrCredentialPromptDetected,
+	// unless the askpass bridge forwards them to the interface.
 	env = ensureEnv(env, "GIT_TERMINAL_PROMPT", "0")
 	env = ensureEnv(env, "GIT_SSH_COMMAND", "ssh -o BatchMode=yes -o ConnectTimeout=5 -o ConnectionAttempts=1")
 	env = ensureEnv(env, "GIT_HTTP_LOW_SPEED_LIMIT", "1")
 	env = ensureEnv(env, "GIT_HTTP_LOW_SPEED_TIME", "10")
 	env = ensureEnv(env, "LANG", "C")
 	env = ensureEnv(env, "LC_ALL", "C")
+	for _, entry := range askpassBridgeEnv(script) {
+		key, value, _ := strings.Cut(entry, "=")
+		env = ensureEnv(env, key, value)
+	}
 	return env
 }
 
@@ -1,9 +1,12 @@
 package command
 
 import (
+	"context"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/require"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
 )
 
@@ -42,6 +45,22 @@ func TestTrimTrailingNewline(t *testing.T) {
 	}
 }
 
+func TestRunWithContextTimeoutOnlyCountsSilence(t *testing.T) {
+	out, err := RunWithContextTimeout(context.Background(), "", "sh", []string{"-c", "for i in 1 2 3 4 5 6; do echo $i; sleep 0.1; done"}, 300*time.Millisecond)
+	require.NoError(t, err, "output keeps the command alive past the timeout")
+	require.Equal(t, "1\n2\n3\n4\n5\n6", out)
+
+	_, err = RunWithContextTimeout(context.Background(), "", "sh", []string{"-c", "echo start; sleep 2"}, 300*time.Millisecond)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestStripProgress(t *testing.T) {
+	out := "remote: Enumerating objects: 5, done.\nremote: Counting objects:  20% (1/5)\rremote: Counting objects: 100% (5/5), done.\n" +
+		"Receiving objects:  40% (2/5)\rReceiving objects: 100% (5/5), done.\n" +
+		"error: cannot lock ref 'refs/remotes/origin/main'\nFrom example.com:repo\n"
+	require.Equal(t, "error: cannot lock ref 'refs/remotes/origin/main'\nFrom example.com:repo", stripProgress(out))
+}
+
 func testFile(testRepoDir, name string) (*git.File, error) {
 	_, err := os.Create(testRepoDir + string(os.PathSeparator) + name)
 	if err != nil {
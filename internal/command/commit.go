@@ -39,7 +39,7 @@ func CommitWithContext(ctx context.Context, r *git.Repository, options *CommitOp
 	}
 
 	// Commit
-	out, err = RunWithContext(ctx, r.AbsPath, "git", []string{"commit", "-m", msg})
+	out, err = RunWithContext(ctx, r.AbsPath, "git", append([]string{"commit", "-m", msg}, noVerifyArgs(r)...))
 	if err != nil {
 		return "", gerr.ParseGitError(out, err)
 	}
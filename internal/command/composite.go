@@ -0,0 +1,112 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// CompositeJob is a named sequence of git commands run in one repository,
+// e.g. "sync": fetch --prune, pull --ff-only, submodule update.
+type CompositeJob struct {
+	Name string
+	// Steps are the git arguments of each command, without "git".
+	Steps [][]string
+}
+
+// CompositeOptions defines the rules of the composite operation.
+type CompositeOptions struct {
+	Job *CompositeJob
+	// Progress is called before each step with its index.
+	Progress func(step int)
+}
+
+var (
+	compositeJobsMu sync.RWMutex
+	compositeJobs   []*CompositeJob
+)
+
+// builtinModes cannot be redefined by a composite job.
+var builtinModes = []string{"fetch", "pull", "merge", "rebase", "push", "auto"}
+
+// SetCompositeJobs configures the composite jobs from their name and steps,
+// each step being the arguments of one git command like "fetch --prune".
+// Arguments are split at white space; quoting is not supported.
+func SetCompositeJobs(definitions map[string][]string) error {
+	jobs := make([]*CompositeJob, 0, len(definitions))
+	for name, lines := range definitions {
+		name = strings.TrimSpace(name)
+		for _, builtin := range builtinModes {
+			if name == builtin {
+				return fmt.Errorf("composite job %q shadows the %s mode", name, builtin)
+			}
+		}
+		job := &CompositeJob{Name: name}
+		for _, line := range lines {
+			args := strings.Fields(line)
+			if len(args) > 0 && args[0] == "git" {
+				args = args[1:]
+			}
+			if len(args) > 0 {
+				job.Steps = append(job.Steps, args)
+			}
+		}
+		if name == "" || len(job.Steps) == 0 {
+			return fmt.Errorf("composite job %q has no steps", name)
+		}
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	compositeJobsMu.Lock()
+	compositeJobs = jobs
+	compositeJobsMu.Unlock()
+	return nil
+}
+
+// CompositeJobs returns the configured composite jobs sorted by name.
+func CompositeJobs() []*CompositeJob {
+	compositeJobsMu.RLock()
+	defer compositeJobsMu.RUnlock()
+	return compositeJobs
+}
+
+// LookupCompositeJob returns the composite job with the given name.
+func LookupCompositeJob(name string) (*CompositeJob, bool) {
+	for _, job := range CompositeJobs() {
+		if job.Name == name {
+			return job, true
+		}
+	}
+	return nil, false
+}
+
+// CompositeWithContext runs the steps of a composite job one after another
+// and stops at the first one that fails. The error is the one of the failed
+// step, so credential prompts and conflicts are detected as usual, and the
+// message names the step.
+func CompositeWithContext(ctx context.Context, r *git.Repository, options *CompositeOptions) (string, error) {
+	if options == nil || options.Job == nil || len(options.Job.Steps) == 0 {
+		return "", fmt.Errorf("composite job is required")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	job := options.Job
+	for i, args := range job.Steps {
+		if options.Progress != nil {
+			options.Progress(i)
+		}
+		out, err := RunWithContext(ctx, r.AbsPath, "git", args)
+		if err != nil {
+			err = gerr.ParseGitError(out, err)
+			return fmt.Sprintf("%s stopped at git %s: %s", job.Name, strings.Join(args, " "), git.NormalizeGitErrorMessage(err.Error())), err
+		}
+	}
+	return fmt.Sprintf("%s completed", job.Name), nil
+}
@@ -0,0 +1,53 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestSetCompositeJobs(t *testing.T) {
+	t.Cleanup(func() { _ = SetCompositeJobs(nil) })
+
+	require.NoError(t, SetCompositeJobs(map[string][]string{
+		"sync":  {"fetch --prune", "git pull --ff-only", " "},
+		"clean": {"gc --auto"},
+	}))
+	jobs := CompositeJobs()
+	require.Len(t, jobs, 2)
+	require.Equal(t, "clean", jobs[0].Name)
+	require.Equal(t, [][]string{{"fetch", "--prune"}, {"pull", "--ff-only"}}, jobs[1].Steps)
+	job, ok := LookupCompositeJob("sync")
+	require.True(t, ok)
+	require.Same(t, jobs[1], job)
+
+	require.EqualError(t, SetCompositeJobs(map[string][]string{"pull": {"fetch"}}), `composite job "pull" shadows the pull mode`)
+	require.EqualError(t, SetCompositeJobs(map[string][]string{"auto": {"fetch"}}), `composite job "auto" shadows the auto mode`)
+	require.EqualError(t, SetCompositeJobs(map[string][]string{"empty": {}}), `composite job "empty" has no steps`)
+}
+
+func TestCompositeWithContextStopsAtTheFirstFailingStep(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var steps []int
+	msg, err := CompositeWithContext(ctx, repo, &CompositeOptions{
+		Job:      &CompositeJob{Name: "sync", Steps: [][]string{{"fetch", "--prune"}, {"tag", "synced"}}},
+		Progress: func(step int) { steps = append(steps, step) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, "sync completed", msg)
+	require.Equal(t, []int{0, 1}, steps)
+
+	msg, err = CompositeWithContext(ctx, repo, &CompositeOptions{
+		Job: &CompositeJob{Name: "broken", Steps: [][]string{{"rev-parse", "--verify", "missing"}, {"tag", "unreached"}}},
+	})
+	require.Error(t, err)
+	require.Contains(t, msg, "broken stopped at git rev-parse --verify missing")
+	_, err = Run(repoPath, "git", []string{"rev-parse", "--verify", "unreached"})
+	require.Error(t, err, "steps after a failure do not run")
+}
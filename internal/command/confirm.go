@@ -0,0 +1,48 @@
+package command
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+var (
+	confirmMu    sync.RWMutex
+	confirmPaths []string
+)
+
+// SetConfirmPaths configures the repositories where destructive operations,
+// like a force push or deleting a branch, need a typed confirmation: the ones
+// below the given paths or globs.
+func SetConfirmPaths(paths []string) {
+	normalized := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if p = expandRulePath(p); p != "" {
+			normalized = append(normalized, p)
+		}
+	}
+	confirmMu.Lock()
+	confirmPaths = normalized
+	confirmMu.Unlock()
+}
+
+// RequiresConfirmation reports whether destructive operations in the
+// repository need a typed confirmation.
+func RequiresConfirmation(r *git.Repository) bool {
+	if r == nil || r.AbsPath == "" {
+		return false
+	}
+	confirmMu.RLock()
+	defer confirmMu.RUnlock()
+	dir := r.AbsPath
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	for _, pattern := range confirmPaths {
+		if repoPathMatches(pattern, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package command
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestRequiresConfirmation(t *testing.T) {
+	root := t.TempDir()
+	SetConfirmPaths([]string{filepath.Join(root, "prod", "**")})
+	t.Cleanup(func() { SetConfirmPaths(nil) })
+
+	require.True(t, RequiresConfirmation(&git.Repository{AbsPath: filepath.Join(root, "prod", "config")}))
+	require.True(t, RequiresConfirmation(&git.Repository{AbsPath: filepath.Join(root, "prod", "eu", "config")}))
+	require.False(t, RequiresConfirmation(&git.Repository{AbsPath: filepath.Join(root, "staging", "config")}))
+	require.False(t, RequiresConfirmation(nil))
+}
@@ -0,0 +1,167 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// credentialHelper answers git's credential requests from the environment so
+// the secret never shows up on the command line or in the process list. Git
+// asks it to store the credentials once the remote accepted them, which it
+// reports with credentialsApproved.
+const credentialHelper = `!f() { case "$1" in get) printf 'username=%s\npassword=%s\n' "$GITBATCH_USERNAME" "$GITBATCH_PASSWORD" ;; store) echo "` + credentialsApproved + `" >&2 ;; esac; }; f`
+
+// credentialsApproved is the line credentialHelper writes once git approved
+// the credentials it handed out.
+const credentialsApproved = "gitbatch: credentials approved"
+
+var (
+	sessionCredentialsMu sync.RWMutex
+	sessionCredentials   = make(map[string]git.Credentials)
+
+	// credentialKeyring additionally hands remembered credentials to the
+	// user's git credential helper (osxkeychain, libsecret, manager, ...).
+	credentialKeyring atomic.Bool
+)
+
+// SetCredentialKeyring configures whether credentials that worked are also
+// persisted through `git credential approve` so they survive the session.
+func SetCredentialKeyring(enabled bool) {
+	credentialKeyring.Store(enabled)
+}
+
+// RememberCredentials stores credentials for a remote host for the rest of
+// the session. Subsequent fetches and pulls against the same host use them
+// without prompting again.
+func RememberCredentials(host string, creds *git.Credentials) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" || creds == nil || strings.TrimSpace(creds.User) == "" {
+		return
+	}
+	sessionCredentialsMu.Lock()
+	sessionCredentials[host] = *creds
+	sessionCredentialsMu.Unlock()
+}
+
+// RememberedCredentials returns the session credentials for a remote host.
+func RememberedCredentials(host string) (*git.Credentials, bool) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	sessionCredentialsMu.RLock()
+	defer sessionCredentialsMu.RUnlock()
+	creds, ok := sessionCredentials[host]
+	if !ok {
+		return nil, false
+	}
+	return &creds, true
+}
+
+// remoteURL returns the first URL of the named remote, falling back to the
+// repository's selected remote when the name is empty or unknown.
+func remoteURL(r *git.Repository, remoteName string) string {
+	if r == nil {
+		return ""
+	}
+	for _, remote := range r.Remotes {
+		if remote != nil && remote.Name == remoteName && len(remote.URL) > 0 {
+			return remote.URL[0]
+		}
+	}
+	if r.State != nil && r.State.Remote != nil && len(r.State.Remote.URL) > 0 {
+		return r.State.Remote.URL[0]
+	}
+	return ""
+}
+
+// resolveCredentials picks the credentials for an operation: explicitly
+// supplied ones win, otherwise the session memory for the remote host is used.
+func resolveCredentials(r *git.Repository, remoteName string, explicit *git.Credentials) (*git.Credentials, string) {
+	url := remoteURL(r, remoteName)
+	host := git.RemoteHost(url)
+	if explicit != nil {
+		return explicit, url
+	}
+	if creds, ok := RememberedCredentials(host); ok {
+		return creds, url
+	}
+	return nil, url
+}
+
+// credentialArgs returns the git arguments and environment that feed the
+// given credentials to git. Configured helpers are bypassed so stale entries
+// cannot shadow the credentials the user just entered.
+func credentialArgs(creds *git.Credentials) ([]string, []string) {
+	if creds == nil {
+		return nil, nil
+	}
+	var args, env []string
+	if creds.User != "" || creds.Password != "" {
+		args = []string{"-c", "credential.helper=", "-c", "credential.helper=" + credentialHelper}
+		env = []string{"GITBATCH_USERNAME=" + creds.User, "GITBATCH_PASSWORD=" + creds.Password}
+	}
+	if creds.Passphrase != "" {
+		env = append(env, passphraseEnv(creds.Passphrase)...)
+	}
+	return args, env
+}
+
+// passphraseKey carries a key passphrase to askpassScript.
+const passphraseKey = "GITBATCH_PASSPHRASE"
+
+// passphraseEnv hands the key passphrase to the command; runWithEnv lets ssh
+// ask for it through SSH_ASKPASS, see sshAskpassEnv.
+func passphraseEnv(passphrase string) []string {
+	return []string{passphraseKey + "=" + passphrase}
+}
+
+// sshAskpassEnv lets ssh ask for the key passphrase through script instead of
+// the terminal. BatchMode has to be lifted for that, which is safe because
+// SSH_ASKPASS_REQUIRE keeps ssh away from the TUI's tty.
+func sshAskpassEnv(script string) []string {
+	return []string{
+		"GIT_SSH_COMMAND=" + askpassSSHCommand,
+		"SSH_ASKPASS=" + script,
+		"SSH_ASKPASS_REQUIRE=force",
+	}
+}
+
+// credentialsSucceeded remembers credentials that were typed into the
+// credential prompt once the remote accepted them, as the output of the
+// command that used them tells. An operation that succeeded without asking
+// for them proves nothing about them.
+func credentialsSucceeded(url string, explicit *git.Credentials, out string) {
+	if explicit == nil || !strings.Contains(out, credentialsApproved) {
+		return
+	}
+	RememberCredentials(git.RemoteHost(url), explicit)
+	if credentialKeyring.Load() {
+		_ = approveCredentials(url, explicit)
+	}
+}
+
+// approveCredentials stores credentials through the user's git credential
+// helper, which on most systems is backed by the OS keyring.
+func approveCredentials(url string, creds *git.Credentials) error {
+	protocol := "https"
+	switch {
+	case strings.HasPrefix(url, "https://"):
+	case strings.HasPrefix(url, "http://"):
+		protocol = "http"
+	default:
+		return fmt.Errorf("credentials for %s cannot be stored in the keyring", url)
+	}
+	input := fmt.Sprintf("protocol=%s\nhost=%s\nusername=%s\npassword=%s\n\n",
+		protocol, git.RemoteHost(url), creds.User, creds.Password)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultGitCommandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "credential", "approve")
+	cmd.Env = enrichGitEnv(os.Environ(), "")
+	cmd.Stdin = strings.NewReader(input)
+	return cmd.Run()
+}
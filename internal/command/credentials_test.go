@@ -0,0 +1,100 @@
+package command
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestCredentialArgsFeedGitCredentialFill(t *testing.T) {
+	args, env := credentialArgs(&git.Credentials{User: "alice", Password: "s3cr3t"})
+	require.NotContains(t, args, "s3cr3t")
+
+	cmd := exec.Command("git", append(args, "credential", "fill")...)
+	cmd.Env = append(enrichGitEnv(os.Environ(), ""), env...)
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=gitlab.example.com\n\n")
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	require.Contains(t, string(out), "username=alice")
+	require.Contains(t, string(out), "password=s3cr3t")
+}
+
+func TestCredentialHelperReportsApprovedCredentials(t *testing.T) {
+	args, env := credentialArgs(&git.Credentials{User: "alice", Password: "s3cr3t"})
+	cmd := exec.Command("git", append(args, "credential", "approve")...)
+	cmd.Env = append(enrichGitEnv(os.Environ(), ""), env...)
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=gitlab.example.com\nusername=alice\npassword=s3cr3t\n\n")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err)
+	require.Contains(t, string(out), credentialsApproved)
+}
+
+func TestResolveCredentialsUsesSessionMemoryPerHost(t *testing.T) {
+	t.Cleanup(func() {
+		sessionCredentialsMu.Lock()
+		sessionCredentials = make(map[string]git.Credentials)
+		sessionCredentialsMu.Unlock()
+	})
+	repo := &git.Repository{State: &git.RepositoryState{}}
+	repo.Remotes = []*git.Remote{{Name: "origin", URL: []string{"https://gitlab.example.com/team/api.git"}}}
+
+	creds, url := resolveCredentials(repo, "origin", nil)
+	require.Nil(t, creds)
+
+	credentialsSucceeded(url, &git.Credentials{User: "alice", Password: "s3cr3t"}, "From https://gitlab.example.com/team/api\n")
+	creds, _ = resolveCredentials(repo, "origin", nil)
+	require.Nil(t, creds, "a fetch that did not ask for the credentials proves nothing")
+
+	credentialsSucceeded(url, &git.Credentials{User: "alice", Password: "s3cr3t"}, credentialsApproved+"\n")
+	creds, _ = resolveCredentials(repo, "origin", nil)
+	require.NotNil(t, creds)
+	require.Equal(t, "alice", creds.User)
+
+	other := &git.Repository{State: &git.RepositoryState{}}
+	other.Remotes = []*git.Remote{{Name: "origin", URL: []string{"git@GITLAB.example.com:team/web.git"}}}
+	creds, _ = resolveCredentials(other, "origin", nil)
+	require.NotNil(t, creds)
+
+	explicit := &git.Credentials{User: "bob"}
+	creds, _ = resolveCredentials(other, "origin", explicit)
+	require.Same(t, explicit, creds)
+}
+
+func TestAskpassScriptAnswersOnlyPassphraseQuestions(t *testing.T) {
+	env := passphraseEnv("open sesame")
+	require.True(t, hasPassphrase(env))
+	script, err := writeAskpassScript()
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(script) })
+
+	cmd := exec.Command(script, "Enter passphrase for key '/home/me/.ssh/id_ed25519': ")
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	require.Equal(t, "open sesame\n", string(out))
+
+	cmd = exec.Command(script, "Are you sure you want to continue connecting (yes/no)? ")
+	cmd.Env = append(os.Environ(), env...)
+	_, err = cmd.Output()
+	require.Error(t, err)
+}
+
+func TestEnrichGitEnvRoutesPromptsThroughAskpassBridge(t *testing.T) {
+	SetAskpassBridge("/tmp/gitbatch-test.sock")
+	t.Cleanup(func() { SetAskpassBridge("") })
+
+	env := strings.Join(enrichGitEnv(nil, "/tmp/gitbatch-askpass.sh"), "\n")
+	require.Contains(t, env, "GIT_ASKPASS=")
+	require.Contains(t, env, "SSH_ASKPASS_REQUIRE=force")
+	require.Contains(t, env, "GITBATCH_ASKPASS_SOCKET=/tmp/gitbatch-test.sock")
+	require.NotContains(t, env, "BatchMode=yes")
+
+	SetAskpassBridge("")
+	env = strings.Join(enrichGitEnv(nil, "/tmp/gitbatch-askpass.sh"), "\n")
+	require.NotContains(t, env, "GIT_ASKPASS=")
+	require.Contains(t, env, "BatchMode=yes")
+}
@@ -0,0 +1,195 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// DependencyRule makes repositories wait for others in a batch, e.g. an
+// application that vendors a library pulls only after the library did.
+type DependencyRule struct {
+	// Path selects the dependent repositories the same way as
+	// RepoEnvRule.Path.
+	Path string `mapstructure:"path"`
+	// After selects the repositories that have to succeed first.
+	After []string `mapstructure:"after"`
+}
+
+var (
+	dependencyRulesMu sync.RWMutex
+	dependencyRules   []DependencyRule
+)
+
+// SetDependencies configures the dependencies between repositories. Rules
+// add up: a repository waits for everything any matching rule names.
+func SetDependencies(rules []DependencyRule) {
+	normalized := make([]DependencyRule, 0, len(rules))
+	for _, rule := range rules {
+		path := expandRulePath(rule.Path)
+		var after []string
+		for _, p := range rule.After {
+			if p = expandRulePath(p); p != "" {
+				after = append(after, p)
+			}
+		}
+		if path == "" || len(after) == 0 {
+			continue
+		}
+		normalized = append(normalized, DependencyRule{Path: path, After: after})
+	}
+	dependencyRulesMu.Lock()
+	dependencyRules = normalized
+	dependencyRulesMu.Unlock()
+}
+
+// Dependencies returns, for each repository in repos, the other repositories
+// of repos it has to wait for by the configured rules. Dependencies outside
+// repos are ignored: there is nothing in the batch to wait for.
+func Dependencies(repos []*git.Repository) map[*git.Repository][]*git.Repository {
+	dependencyRulesMu.RLock()
+	rules := dependencyRules
+	dependencyRulesMu.RUnlock()
+
+	deps := make(map[*git.Repository][]*git.Repository)
+	for _, r := range repos {
+		dir := repoDir(r)
+		for _, rule := range rules {
+			if !repoPathMatches(rule.Path, dir) {
+				continue
+			}
+			for _, other := range repos {
+				if other == r || containsRepo(deps[r], other) {
+					continue
+				}
+				for _, after := range rule.After {
+					if repoPathMatches(after, repoDir(other)) {
+						deps[r] = append(deps[r], other)
+						break
+					}
+				}
+			}
+		}
+	}
+	if len(deps) == 0 {
+		return nil
+	}
+	return deps
+}
+
+// LocalDependencyGraph returns, for each repository in repos, the other
+// repositories of repos its manifests point at. Unlike Dependencies they
+// only suggest an order: a batch does not wait for them.
+func LocalDependencyGraph(repos []*git.Repository) map[*git.Repository][]*git.Repository {
+	deps := make(map[*git.Repository][]*git.Repository)
+	for _, r := range repos {
+		if local := LocalDependencies(r, repos); len(local) > 0 {
+			deps[r] = local
+		}
+	}
+	if len(deps) == 0 {
+		return nil
+	}
+	return deps
+}
+
+// LocalDependencies returns the repositories of repos that the manifests of
+// r point at, e.g. with a go.mod replace directive. A directory belongs to
+// the innermost repository that contains it.
+func LocalDependencies(r *git.Repository, repos []*git.Repository) []*git.Repository {
+	var deps []*git.Repository
+	for _, path := range r.LocalDependencies {
+		var owner *git.Repository
+		ownerDir := ""
+		for _, other := range repos {
+			dir := repoDir(other)
+			if path != dir && !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+				continue
+			}
+			if len(dir) > len(ownerDir) {
+				owner, ownerDir = other, dir
+			}
+		}
+		if owner != nil && owner != r && !containsRepo(deps, owner) {
+			deps = append(deps, owner)
+		}
+	}
+	return deps
+}
+
+// OutdatedDependencies returns the local dependencies of r that are behind
+// their upstream, so r would be built against code that is about to change.
+// They should be pulled before r.
+func OutdatedDependencies(r *git.Repository, repos []*git.Repository) []*git.Repository {
+	var outdated []*git.Repository
+	for _, dep := range LocalDependencies(r, repos) {
+		if dep.State == nil || dep.State.Branch == nil || dep.State.Branch.Upstream == nil {
+			continue
+		}
+		if behind, err := strconv.Atoi(dep.State.Branch.Pullables); err == nil && behind > 0 {
+			outdated = append(outdated, dep)
+		}
+	}
+	return outdated
+}
+
+// DependencyOrder sorts repos so that every repository comes after the ones
+// it depends on. Otherwise the order of repos is kept, so the result is the
+// same for every run. A cycle is reported as an error.
+func DependencyOrder(repos []*git.Repository, deps map[*git.Repository][]*git.Repository) ([]*git.Repository, error) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[*git.Repository]int, len(repos))
+	order := make([]*git.Repository, 0, len(repos))
+	var path []string
+
+	var visit func(r *git.Repository) error
+	visit = func(r *git.Repository) error {
+		switch state[r] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, r.Name), " -> "))
+		}
+		state[r] = visiting
+		path = append(path, r.Name)
+		for _, dep := range deps[r] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[r] = done
+		order = append(order, r)
+		return nil
+	}
+	for _, r := range repos {
+		if err := visit(r); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+func repoDir(r *git.Repository) string {
+	if abs, err := filepath.Abs(r.AbsPath); err == nil {
+		return abs
+	}
+	return r.AbsPath
+}
+
+func containsRepo(repos []*git.Repository, r *git.Repository) bool {
+	for _, candidate := range repos {
+		if candidate == r {
+			return true
+		}
+	}
+	return false
+}
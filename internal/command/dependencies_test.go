@@ -0,0 +1,77 @@
+package command
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestDependencyOrderRunsDependenciesFirst(t *testing.T) {
+	root := t.TempDir()
+	repo := func(name string) *git.Repository {
+		return &git.Repository{Name: name, AbsPath: filepath.Join(root, name)}
+	}
+	app, lib, tools, docs := repo("app"), repo("lib"), repo("tools"), repo("docs")
+	SetDependencies([]DependencyRule{
+		{Path: filepath.Join(root, "app"), After: []string{filepath.Join(root, "lib"), filepath.Join(root, "tools")}},
+		{Path: filepath.Join(root, "lib"), After: []string{filepath.Join(root, "tools"), filepath.Join(root, "missing")}},
+		{Path: filepath.Join(root, "docs")},
+	})
+	t.Cleanup(func() { SetDependencies(nil) })
+
+	repos := []*git.Repository{app, docs, lib, tools}
+	deps := Dependencies(repos)
+	require.Equal(t, []*git.Repository{lib, tools}, deps[app])
+	require.Equal(t, []*git.Repository{tools}, deps[lib])
+	require.Empty(t, deps[docs])
+
+	order, err := DependencyOrder(repos, deps)
+	require.NoError(t, err)
+	require.Equal(t, []*git.Repository{tools, lib, app, docs}, order)
+
+	order, err = DependencyOrder([]*git.Repository{app, docs}, Dependencies([]*git.Repository{app, docs}))
+	require.NoError(t, err)
+	require.Equal(t, []*git.Repository{app, docs}, order, "dependencies outside the batch are ignored")
+}
+
+func TestDependencyOrderReportsCycles(t *testing.T) {
+	root := t.TempDir()
+	a := &git.Repository{Name: "a", AbsPath: filepath.Join(root, "a")}
+	b := &git.Repository{Name: "b", AbsPath: filepath.Join(root, "b")}
+	SetDependencies([]DependencyRule{
+		{Path: a.AbsPath, After: []string{b.AbsPath}},
+		{Path: b.AbsPath, After: []string{a.AbsPath}},
+	})
+	t.Cleanup(func() { SetDependencies(nil) })
+
+	repos := []*git.Repository{a, b}
+	_, err := DependencyOrder(repos, Dependencies(repos))
+	require.EqualError(t, err, "dependency cycle: a -> b -> a")
+}
+
+func TestLocalDependenciesOnlySuggestAnOrder(t *testing.T) {
+	root := t.TempDir()
+	repo := func(name string, deps ...string) *git.Repository {
+		r := &git.Repository{Name: name, AbsPath: filepath.Join(root, name)}
+		for _, dep := range deps {
+			r.LocalDependencies = append(r.LocalDependencies, filepath.Join(root, dep))
+		}
+		return r
+	}
+	lib, proto := repo("lib"), repo("proto")
+	app := repo("app", "lib", "proto/go", "vendor/missing")
+	lib.State = &git.RepositoryState{Branch: &git.Branch{Upstream: &git.RemoteBranch{}, Pullables: "2"}}
+	proto.State = &git.RepositoryState{Branch: &git.Branch{Upstream: &git.RemoteBranch{}, Pullables: "0"}}
+
+	repos := []*git.Repository{app, lib, proto}
+	require.Nil(t, Dependencies(repos), "a batch does not wait for local dependencies")
+	require.Equal(t, []*git.Repository{lib, proto}, LocalDependencyGraph(repos)[app])
+	require.Equal(t, []*git.Repository{lib}, OutdatedDependencies(app, repos))
+
+	order, err := DependencyOrder(repos, LocalDependencyGraph(repos))
+	require.NoError(t, err)
+	require.Equal(t, []*git.Repository{lib, proto, app}, order)
+	require.Nil(t, LocalDependencyGraph([]*git.Repository{lib, proto}))
+}
@@ -1,7 +1,16 @@
 package command
 
 import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/thorstenhirsch/gitbatch/internal/git"
 )
@@ -22,3 +31,117 @@ func DiffStatRefs(r *git.Repository, ref1, ref2 string) (string, error) {
 	output = crRegex.ReplaceAllString(output, "\n")
 	return output, err
 }
+
+var (
+	diffFilterMu sync.Mutex
+	diffFilter   string
+)
+
+// SetDiffFilter configures the command diffs are piped through before they
+// are shown, e.g. "delta" or "diff-so-fancy". Empty shows them as git prints
+// them.
+func SetDiffFilter(command string) {
+	diffFilterMu.Lock()
+	diffFilter = strings.TrimSpace(command)
+	diffFilterMu.Unlock()
+}
+
+// DiffFilter returns the configured diff filter, empty if none is set.
+func DiffFilter() string {
+	diffFilterMu.Lock()
+	defer diffFilterMu.Unlock()
+	return diffFilter
+}
+
+// Diff is a diff ready to be shown.
+type Diff struct {
+	Text string
+	// Filtered is true when the diff filter rendered Text, which then
+	// carries its colors.
+	Filtered bool
+}
+
+// FileDiff returns the changes of a file in the working tree against HEAD,
+// staged or not. Untracked files show their whole content.
+func FileDiff(ctx context.Context, r *git.Repository, file *git.File, width int) (*Diff, error) {
+	args := []string{"HEAD", "--", file.Name}
+	if file.X == git.StatusUntracked {
+		args = []string{"--no-index", "--", os.DevNull, file.Name}
+	}
+	return renderDiff(ctx, r, []string{"diff"}, args, width)
+}
+
+// CommitDiff returns a commit and its changes as git show prints them.
+func CommitDiff(ctx context.Context, r *git.Repository, rev string, width int) (*Diff, error) {
+	return renderDiff(ctx, r, []string{"show"}, []string{rev, "--"}, width)
+}
+
+// StashDiff returns the changes of a stash, e.g. stash@{1}.
+func StashDiff(ctx context.Context, r *git.Repository, stashRef string, width int) (*Diff, error) {
+	return renderDiff(ctx, r, []string{"stash", "show"}, []string{"-p", stashRef}, width)
+}
+
+// renderDiff runs the git subcommand with args and pipes its colored output
+// through the diff filter. Without a filter, or when it is not installed or
+// fails, the plain output is returned.
+func renderDiff(ctx context.Context, r *git.Repository, subcommand, args []string, width int) (*Diff, error) {
+	if filter := DiffFilter(); filter != "" {
+		if out, err := runDiff(ctx, r, subcommand, args, "--color=always"); err == nil {
+			if filtered, err := filterDiff(ctx, r.AbsPath, filter, out, width); err == nil {
+				return &Diff{Text: filtered, Filtered: true}, nil
+			}
+		}
+	}
+	out, err := runDiff(ctx, r, subcommand, args, "--no-color")
+	if err != nil {
+		return nil, err
+	}
+	return &Diff{Text: out}, nil
+}
+
+func runDiff(ctx context.Context, r *git.Repository, subcommand, args []string, color string) (string, error) {
+	args = slices.Concat(subcommand, []string{color}, args)
+	out, err := RunWithContext(ctx, r.AbsPath, "git", args)
+	var exitErr *exec.ExitError
+	if err != nil && slices.Contains(args, "--no-index") && errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		// git diff --no-index exits with 1 when the files differ.
+		err = nil
+	}
+	if err != nil {
+		if out != "" {
+			return "", errors.New(out)
+		}
+		return "", err
+	}
+	return out, nil
+}
+
+// filterDiff pipes diff through the filter command. delta needs to be told
+// the width and that it must not start a pager; other filters get the width
+// through COLUMNS.
+func filterDiff(ctx context.Context, dir, filter, diff string, width int) (string, error) {
+	args := strings.Fields(filter)
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return "", err
+	}
+	if filepath.Base(args[0]) == "delta" {
+		args = append(args, "--paging=never")
+		if width > 0 && !slices.ContainsFunc(args, func(arg string) bool {
+			return arg == "-w" || strings.HasPrefix(arg, "--width")
+		}) {
+			args = append(args, "--width", strconv.Itoa(width))
+		}
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(diff)
+	cmd.Env = os.Environ()
+	if width > 0 {
+		cmd.Env = append(cmd.Env, "COLUMNS="+strconv.Itoa(width))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
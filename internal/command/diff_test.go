@@ -0,0 +1,58 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestDiffGoesThroughTheFilterAndFallsBackWithoutIt(t *testing.T) {
+	t.Cleanup(func() { SetDiffFilter("") })
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("changed"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "notes.txt"), []byte("new"), 0o644))
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	ctx := context.Background()
+	readme := &git.File{Name: "README.md", X: git.StatusNotupdated, Y: git.StatusModified}
+
+	diff, err := FileDiff(ctx, repo, readme, 80)
+	require.NoError(t, err)
+	require.False(t, diff.Filtered)
+	require.Contains(t, diff.Text, "-hello")
+	require.Contains(t, diff.Text, "+changed")
+	require.NotContains(t, diff.Text, "\x1b[", "the plain diff carries no colors")
+
+	diff, err = FileDiff(ctx, repo, &git.File{Name: "notes.txt", X: git.StatusUntracked, Y: git.StatusUntracked}, 80)
+	require.NoError(t, err)
+	require.Contains(t, diff.Text, "+new")
+
+	// The filter gets the colored diff on stdin.
+	SetDiffFilter("sed -e s/^/>/")
+	diff, err = FileDiff(ctx, repo, readme, 80)
+	require.NoError(t, err)
+	require.True(t, diff.Filtered)
+	for _, line := range strings.Split(diff.Text, "\n") {
+		require.True(t, strings.HasPrefix(line, ">"), line)
+	}
+	require.Contains(t, diff.Text, "\x1b[")
+
+	diff, err = CommitDiff(ctx, repo, "HEAD", 80)
+	require.NoError(t, err)
+	require.True(t, diff.Filtered)
+	require.Contains(t, diff.Text, "initial commit")
+
+	SetDiffFilter("gitbatch-no-such-filter --side-by-side")
+	diff, err = FileDiff(ctx, repo, readme, 80)
+	require.NoError(t, err)
+	require.False(t, diff.Filtered, "a filter that is not installed shows the plain diff")
+	require.Contains(t, diff.Text, "+changed")
+
+	_, err = CommitDiff(ctx, repo, "no-such-revision", 80)
+	require.Error(t, err)
+}
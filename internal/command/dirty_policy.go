@@ -0,0 +1,68 @@
+package command
+
+import (
+	"strings"
+	"sync"
+)
+
+// DirtyPolicy decides what a batch pull does with a repository whose local
+// changes overlap with the incoming commits.
+type DirtyPolicy string
+
+const (
+	// DirtyPolicySkip leaves the repository untouched and excludes it from the batch.
+	DirtyPolicySkip DirtyPolicy = "skip"
+	// DirtyPolicyAutostash stashes local changes before pulling and re-applies them afterwards.
+	DirtyPolicyAutostash DirtyPolicy = "autostash"
+	// DirtyPolicyFail marks the repository as failed so it stands out in the overview.
+	DirtyPolicyFail DirtyPolicy = "fail"
+)
+
+var (
+	dirtyPolicyMu sync.RWMutex
+	dirtyPolicy   = DirtyPolicySkip
+)
+
+// ParseDirtyPolicy converts a configuration value into a DirtyPolicy. The
+// second return value is false when the value is not a known policy.
+func ParseDirtyPolicy(value string) (DirtyPolicy, bool) {
+	switch DirtyPolicy(strings.ToLower(strings.TrimSpace(value))) {
+	case DirtyPolicySkip:
+		return DirtyPolicySkip, true
+	case DirtyPolicyAutostash:
+		return DirtyPolicyAutostash, true
+	case DirtyPolicyFail:
+		return DirtyPolicyFail, true
+	default:
+		return DirtyPolicySkip, false
+	}
+}
+
+// SetDirtyPolicy configures how dirty repositories are handled during batch pulls.
+func SetDirtyPolicy(policy DirtyPolicy) {
+	if _, ok := ParseDirtyPolicy(string(policy)); !ok {
+		policy = DirtyPolicySkip
+	}
+	dirtyPolicyMu.Lock()
+	dirtyPolicy = policy
+	dirtyPolicyMu.Unlock()
+}
+
+// CurrentDirtyPolicy returns the active dirty-repository policy.
+func CurrentDirtyPolicy() DirtyPolicy {
+	dirtyPolicyMu.RLock()
+	defer dirtyPolicyMu.RUnlock()
+	return dirtyPolicy
+}
+
+// Description returns a short human readable explanation of the policy.
+func (p DirtyPolicy) Description() string {
+	switch p {
+	case DirtyPolicyAutostash:
+		return "local changes are stashed and re-applied"
+	case DirtyPolicyFail:
+		return "repository is marked as failed"
+	default:
+		return "repository is skipped"
+	}
+}
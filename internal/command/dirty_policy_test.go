@@ -0,0 +1,125 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestParseDirtyPolicy(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected DirtyPolicy
+		ok       bool
+	}{
+		{value: "skip", expected: DirtyPolicySkip, ok: true},
+		{value: "autostash", expected: DirtyPolicyAutostash, ok: true},
+		{value: " Fail ", expected: DirtyPolicyFail, ok: true},
+		{value: "", expected: DirtyPolicySkip, ok: false},
+		{value: "stash", expected: DirtyPolicySkip, ok: false},
+	}
+	for _, tt := range tests {
+		policy, ok := ParseDirtyPolicy(tt.value)
+		require.Equal(t, tt.ok, ok, "value %q", tt.value)
+		require.Equal(t, tt.expected, policy, "value %q", tt.value)
+	}
+}
+
+func TestNormalizePullOptionsAutostashFollowsPolicy(t *testing.T) {
+	withDirtyPolicy(t, DirtyPolicyAutostash)
+	repo := &git.Repository{State: &git.RepositoryState{}}
+
+	opts := normalizePullOptions(&PullOptions{RemoteName: "origin"}, repo, true, false)
+	require.True(t, opts.Autostash)
+	require.True(t, opts.FFOnly)
+
+	SetDirtyPolicy(DirtyPolicySkip)
+	opts = normalizePullOptions(&PullOptions{RemoteName: "origin"}, repo, true, false)
+	require.False(t, opts.Autostash)
+}
+
+func TestApplyCleanliness_OverlappingChanges_SkipPolicy(t *testing.T) {
+	withDirtyPolicy(t, DirtyPolicySkip)
+	repo := initOverlappingDirtyRepo(t)
+
+	applyCleanlinessAsync(repo)
+
+	require.False(t, repo.State.Branch.Clean)
+	require.Equal(t, git.Available, repo.WorkStatus())
+}
+
+func TestApplyCleanliness_OverlappingChanges_AutostashPolicy(t *testing.T) {
+	withDirtyPolicy(t, DirtyPolicyAutostash)
+	repo := initOverlappingDirtyRepo(t)
+
+	applyCleanlinessAsync(repo)
+
+	require.True(t, repo.State.Branch.Clean)
+	require.True(t, repo.State.Branch.HasLocalChanges)
+	require.Equal(t, git.Queued, repo.WorkStatus())
+
+	require.NoError(t, NewExecutor(repo).RunPull(t.Context(), nil, false))
+	content, err := os.ReadFile(filepath.Join(repo.AbsPath, "README.md"))
+	require.NoError(t, err)
+	require.Equal(t, "local\nhello\nremote\n", string(content), "local change should be re-applied on top of the pull")
+}
+
+func TestApplyCleanliness_OverlappingChanges_FailPolicy(t *testing.T) {
+	withDirtyPolicy(t, DirtyPolicyFail)
+	repo := initOverlappingDirtyRepo(t)
+
+	applyCleanlinessAsync(repo)
+
+	require.Equal(t, git.Fail, repo.WorkStatus())
+	require.Contains(t, repo.State.Message(), "on_dirty: fail")
+}
+
+func withDirtyPolicy(t *testing.T, policy DirtyPolicy) {
+	t.Helper()
+	prev := CurrentDirtyPolicy()
+	SetDirtyPolicy(policy)
+	t.Cleanup(func() { SetDirtyPolicy(prev) })
+}
+
+// initOverlappingDirtyRepo returns a repository whose upstream has a new commit
+// touching README.md while the working tree holds a non-conflicting edit to
+// the same file.
+func initOverlappingDirtyRepo(t *testing.T) *git.Repository {
+	t.Helper()
+
+	basePath := initLocalWorktreeRepoForStateTest(t)
+	readme := filepath.Join(basePath, "README.md")
+	require.NoError(t, os.WriteFile(readme, []byte("hello\n"), 0o644))
+	_, err := Run(basePath, "git", []string{"commit", "-am", "newline"})
+	require.NoError(t, err)
+	_, err = Run(basePath, "git", []string{"push"})
+	require.NoError(t, err)
+
+	remoteOut, err := Run(basePath, "git", []string{"remote", "get-url", "origin"})
+	require.NoError(t, err)
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	_, err = Run(basePath, "git", []string{"clone", "--branch", "main", strings.TrimSpace(remoteOut), clonePath})
+	require.NoError(t, err)
+	_, err = Run(clonePath, "git", []string{"config", "user.email", "test@example.com"})
+	require.NoError(t, err)
+	_, err = Run(clonePath, "git", []string{"config", "user.name", "Test User"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(clonePath, "README.md"), []byte("hello\nremote\n"), 0o644))
+	_, err = Run(clonePath, "git", []string{"commit", "-am", "remote change"})
+	require.NoError(t, err)
+	_, err = Run(clonePath, "git", []string{"push"})
+	require.NoError(t, err)
+
+	_, err = Run(basePath, "git", []string{"fetch"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(readme, []byte("local\nhello\n"), 0o644))
+
+	repo, err := git.InitializeRepo(basePath)
+	require.NoError(t, err)
+	require.True(t, repo.State.Branch.HasIncomingCommits())
+	return repo
+}
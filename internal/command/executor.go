@@ -8,13 +8,15 @@ import (
 	"time"
 
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
 )
 
 // Executor centralizes operation preparation so synchronous quick-mode execution
 // and queued job execution share the same defaults, validation, and state
 // transitions.
 type Executor struct {
-	repo *git.Repository
+	repo  *git.Repository
+	batch BatchID
 }
 
 // NewExecutor returns an Executor for the given repository.
@@ -22,6 +24,12 @@ func NewExecutor(repo *git.Repository) *Executor {
 	return &Executor{repo: repo}
 }
 
+// InBatch makes the commands e schedules part of batch.
+func (e *Executor) InBatch(batch BatchID) *Executor {
+	e.batch = batch
+	return e
+}
+
 // RunFetch executes fetch synchronously and evaluates repository state.
 func (e *Executor) RunFetch(ctx context.Context, options *FetchOptions) error {
 	return e.run(ctx, e.prepareFetch(options))
@@ -62,6 +70,16 @@ func (e *Executor) ScheduleRebase(options *PullOptions) error {
 	return e.schedule(e.preparePull(OperationRebase, options, false, true, false))
 }
 
+// RunRebaseOnto executes rebase --onto synchronously and evaluates repository state.
+func (e *Executor) RunRebaseOnto(ctx context.Context, options *RebaseOntoOptions) error {
+	return e.run(ctx, e.prepareRebaseOnto(options))
+}
+
+// ScheduleRebaseOnto queues rebase --onto execution on the repository git queue.
+func (e *Executor) ScheduleRebaseOnto(options *RebaseOntoOptions) error {
+	return e.schedule(e.prepareRebaseOnto(options))
+}
+
 // RunPush executes push synchronously and evaluates repository state.
 func (e *Executor) RunPush(ctx context.Context, options *PushOptions, suppressSuccess bool) error {
 	return e.run(ctx, e.preparePush(options, suppressSuccess))
@@ -112,6 +130,131 @@ func (e *Executor) ScheduleStashDrop(options *StashDropOptions) error {
 	return e.schedule(e.prepareStashDrop(options))
 }
 
+// RunCheckout executes checkout synchronously and evaluates repository state.
+func (e *Executor) RunCheckout(ctx context.Context, options *CheckoutOptions) error {
+	return e.run(ctx, e.prepareCheckout(options))
+}
+
+// ScheduleCheckout queues checkout execution on the repository git queue.
+func (e *Executor) ScheduleCheckout(options *CheckoutOptions) error {
+	return e.schedule(e.prepareCheckout(options))
+}
+
+// ScheduleCheckoutStep queues the checkout of the repository in a
+// CheckoutTransaction on the repository git queue.
+func (e *Executor) ScheduleCheckoutStep(step CheckoutStep) error {
+	plan := e.prepareCheckoutStep(step)
+	err := e.schedule(plan)
+	if (plan.request == nil || err != nil) && step.Transaction != nil {
+		// A step that never runs cannot switch its repository.
+		step.Transaction.finish(e.repo, nil)
+	}
+	return err
+}
+
+// RunDeleteBranch executes branch deletion synchronously and evaluates repository state.
+func (e *Executor) RunDeleteBranch(ctx context.Context, options *DeleteBranchOptions) error {
+	return e.run(ctx, e.prepareDeleteBranch(options))
+}
+
+// ScheduleDeleteBranch queues branch deletion on the repository git queue.
+func (e *Executor) ScheduleDeleteBranch(options *DeleteBranchOptions) error {
+	return e.schedule(e.prepareDeleteBranch(options))
+}
+
+// RunUndo reverts the most recent reversible action synchronously and
+// evaluates repository state.
+func (e *Executor) RunUndo(ctx context.Context) error {
+	return e.run(ctx, e.prepareUndo())
+}
+
+// ScheduleUndo queues reverting the most recent reversible action on the
+// repository git queue.
+func (e *Executor) ScheduleUndo() error {
+	return e.schedule(e.prepareUndo())
+}
+
+// RunSetUpstream executes set upstream synchronously and evaluates repository state.
+func (e *Executor) RunSetUpstream(ctx context.Context, options *SetUpstreamOptions) error {
+	return e.run(ctx, e.prepareSetUpstream(options))
+}
+
+// ScheduleSetUpstream queues set upstream execution on the repository git queue.
+func (e *Executor) ScheduleSetUpstream(options *SetUpstreamOptions) error {
+	return e.schedule(e.prepareSetUpstream(options))
+}
+
+// RunComposite executes a composite job synchronously and evaluates repository state.
+func (e *Executor) RunComposite(ctx context.Context, options *CompositeOptions) error {
+	return e.run(ctx, e.prepareComposite(options))
+}
+
+// ScheduleComposite queues composite job execution on the repository git queue.
+func (e *Executor) ScheduleComposite(options *CompositeOptions) error {
+	return e.schedule(e.prepareComposite(options))
+}
+
+// RunSyncFork syncs a fork with its upstream synchronously and evaluates
+// repository state.
+func (e *Executor) RunSyncFork(ctx context.Context) error {
+	return e.run(ctx, e.prepareSyncFork())
+}
+
+// ScheduleSyncFork queues syncing a fork with its upstream on the repository
+// git queue.
+func (e *Executor) ScheduleSyncFork() error {
+	return e.schedule(e.prepareSyncFork())
+}
+
+// RunSparseReapply re-applies the sparse-checkout patterns synchronously and
+// evaluates repository state.
+func (e *Executor) RunSparseReapply(ctx context.Context) error {
+	return e.run(ctx, e.prepareSparseReapply())
+}
+
+// ScheduleSparseReapply queues re-applying the sparse-checkout patterns on the
+// repository git queue.
+func (e *Executor) ScheduleSparseReapply() error {
+	return e.schedule(e.prepareSparseReapply())
+}
+
+// RunPruneRemote removes the stale remote-tracking refs of a remote
+// synchronously and evaluates repository state.
+func (e *Executor) RunPruneRemote(ctx context.Context, remoteName string) error {
+	return e.run(ctx, e.preparePruneRemote(remoteName))
+}
+
+// SchedulePruneRemote queues removing the stale remote-tracking refs of a
+// remote on the repository git queue.
+func (e *Executor) SchedulePruneRemote(remoteName string) error {
+	return e.schedule(e.preparePruneRemote(remoteName))
+}
+
+// ScheduleSetRemoteURLs queues pointing the remotes of the repository at
+// the new URLs of rewrites on the repository git queue.
+func (e *Executor) ScheduleSetRemoteURLs(rewrites []RemoteURLRewrite) error {
+	return e.schedule(e.prepareSetRemoteURLs(rewrites))
+}
+
+// ScheduleBisect queues a bisect step on the repository git queue. done
+// receives the progress once the step ran, or the cause when the step was
+// cancelled before it could run.
+func (e *Executor) ScheduleBisect(step BisectStep, done func(*BisectProgress, error)) error {
+	return e.schedule(e.prepareBisect(step, done))
+}
+
+// RunRebaseOnDefault rebases the current branch onto the updated default
+// branch synchronously and evaluates repository state.
+func (e *Executor) RunRebaseOnDefault(ctx context.Context) error {
+	return e.run(ctx, e.prepareRebaseOnDefault())
+}
+
+// ScheduleRebaseOnDefault queues rebasing the current branch onto the
+// updated default branch on the repository git queue.
+func (e *Executor) ScheduleRebaseOnDefault() error {
+	return e.schedule(e.prepareRebaseOnDefault())
+}
+
 type executionPlan struct {
 	request   *GitCommandRequest
 	immediate *OperationOutcome
@@ -124,8 +267,15 @@ func (e *Executor) run(ctx context.Context, plan executionPlan) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	var audit *auditRecorder
+	if plan.request != nil {
+		startGitOperation(e.repo, plan.request.Operation)
+		defer e.repo.EndWatchSuppress()
+		ctx, audit = startAudit(ctx, plan.request.Operation)
+	}
 
 	outcome := plan.outcome(ctx)
+	audit.finish(e.repo, outcome)
 	ScheduleStateEvaluation(e.repo, outcome)
 	return outcome.Err
 }
@@ -138,6 +288,7 @@ func (e *Executor) schedule(plan executionPlan) error {
 		ScheduleStateEvaluation(e.repo, *plan.immediate)
 		return nil
 	}
+	plan.request.Batch = e.batch
 	return ScheduleGitCommand(e.repo, plan.request)
 }
 
@@ -176,9 +327,11 @@ func (e *Executor) prepareFetch(options *FetchOptions) executionPlan {
 
 	optsCopy := *opts
 	return queuedPlan(&GitCommandRequest{
-		Key:       fmt.Sprintf("fetch:%s:%s", e.repo.RepoID, optsCopy.RemoteName),
-		Timeout:   optsCopy.Timeout,
-		Operation: OperationFetch,
+		Key:             fmt.Sprintf("fetch:%s:%s", e.repo.RepoID, optsCopy.RemoteName),
+		Timeout:         optsCopy.Timeout,
+		Operation:       OperationFetch,
+		HostCredentials: optsCopy.Credentials == nil,
+		CredentialRetry: optsCopy.Credentials != nil,
 		Execute: func(ctx context.Context) OperationOutcome {
 			msg, err := FetchWithContext(ctx, e.repo, &optsCopy)
 			return OperationOutcome{
@@ -201,17 +354,22 @@ func (e *Executor) preparePull(operation OperationType, options *PullOptions, ff
 	opts := normalizePullOptions(options, e.repo, ffOnly, rebase)
 	optsCopy := *opts
 	return queuedPlan(&GitCommandRequest{
-		Key:       fmt.Sprintf("%s:%s:%s", operation, e.repo.RepoID, optsCopy.RemoteName),
-		Timeout:   operationTimeout(e.repo.State.Branch.PullableCount),
-		Operation: operation,
+		Key:             fmt.Sprintf("%s:%s:%s", operation, e.repo.RepoID, optsCopy.RemoteName),
+		Timeout:         operationTimeout(e.repo.State.Branch.PullableCount),
+		Operation:       operation,
+		HostCredentials: optsCopy.Credentials == nil,
+		CredentialRetry: optsCopy.Credentials != nil,
 		Execute: func(ctx context.Context) OperationOutcome {
+			ref := headHash(e.repo)
 			msg, err := PullWithContext(ctx, e.repo, &optsCopy)
-			return OperationOutcome{
+			outcome := OperationOutcome{
 				Operation:       operation,
 				Message:         msg,
 				Err:             err,
 				SuppressSuccess: suppressSuccess,
 			}
+			outcome.FollowUp = sparseReapplyFollowUp(e.repo, ref, headHash(e.repo), outcome)
+			return outcome
 		},
 	})
 }
@@ -228,12 +386,36 @@ func (e *Executor) prepareMerge(options *MergeOptions) executionPlan {
 		Timeout:   operationTimeout(e.repo.State.Branch.PullableCount),
 		Operation: OperationMerge,
 		Execute: func(ctx context.Context) OperationOutcome {
+			ref := headHash(e.repo)
 			msg, err := MergeWithContext(ctx, e.repo, &optsCopy)
-			return OperationOutcome{
+			outcome := OperationOutcome{
 				Operation: OperationMerge,
 				Message:   msg,
 				Err:       err,
 			}
+			outcome.FollowUp = sparseReapplyFollowUp(e.repo, ref, headHash(e.repo), outcome)
+			return outcome
+		},
+	})
+}
+
+func (e *Executor) prepareRebaseOnto(options *RebaseOntoOptions) executionPlan {
+	if options == nil || strings.TrimSpace(options.Onto) == "" || strings.TrimSpace(options.Upstream) == "" {
+		return immediatePlan(OperationRebase, "rebase onto options not provided")
+	}
+
+	optsCopy := *options
+	return queuedPlan(&GitCommandRequest{
+		Key:       fmt.Sprintf("rebase-onto:%s", e.repo.RepoID),
+		Timeout:   DefaultGitCommandTimeout,
+		Operation: OperationRebase,
+		Execute: func(ctx context.Context) OperationOutcome {
+			msg, err := RebaseOntoWithContext(ctx, e.repo, &optsCopy)
+			return OperationOutcome{
+				Operation: OperationRebase,
+				Message:   msg,
+				Err:       err,
+			}
 		},
 	})
 }
@@ -249,16 +431,19 @@ func (e *Executor) preparePush(options *PushOptions, suppressSuccess bool) execu
 	opts := normalizePushOptions(options, e.repo)
 	optsCopy := *opts
 	return queuedPlan(&GitCommandRequest{
-		Key:       fmt.Sprintf("push:%s:%s", e.repo.RepoID, optsCopy.RemoteName),
-		Timeout:   operationTimeout(e.repo.State.Branch.PushableCount),
-		Operation: OperationPush,
+		Key:             fmt.Sprintf("push:%s:%s", e.repo.RepoID, optsCopy.RemoteName),
+		Timeout:         operationTimeout(e.repo.State.Branch.PushableCount),
+		Operation:       OperationPush,
+		HostCredentials: optsCopy.Credentials == nil,
+		CredentialRetry: optsCopy.Credentials != nil,
 		Execute: func(ctx context.Context) OperationOutcome {
-			msg, err := PushWithContext(ctx, e.repo, &optsCopy)
+			msg, pullRequest, err := push(ctx, e.repo, &optsCopy)
 			return OperationOutcome{
 				Operation:       OperationPush,
 				Message:         msg,
 				Err:             err,
 				SuppressSuccess: suppressSuccess,
+				PullRequestURL:  pullRequest,
 			}
 		},
 	})
@@ -349,6 +534,295 @@ func (e *Executor) prepareStashDrop(options *StashDropOptions) executionPlan {
 	})
 }
 
+func (e *Executor) prepareCheckout(options *CheckoutOptions) executionPlan {
+	if options == nil || strings.TrimSpace(options.Branch) == "" {
+		return immediatePlan(OperationCheckout, "checkout options not provided")
+	}
+
+	optsCopy := *options
+	return queuedPlan(&GitCommandRequest{
+		Key:       fmt.Sprintf("checkout:%s", e.repo.RepoID),
+		Timeout:   DefaultGitCommandTimeout,
+		Operation: OperationCheckout,
+		Execute: func(ctx context.Context) OperationOutcome {
+			head, detached, headErr := currentHead(ctx, e.repo)
+			msg, err := CheckoutWithContext(ctx, e.repo, &optsCopy)
+			if err == nil && headErr == nil && head != optsCopy.Branch {
+				pushUndo(e.repo, UndoEntry{
+					Kind:        UndoCheckout,
+					Description: "checkout " + optsCopy.Branch,
+					Ref:         head,
+					Detached:    detached,
+				})
+			}
+			return OperationOutcome{
+				Operation: OperationCheckout,
+				Message:   msg,
+				Err:       err,
+			}
+		},
+	})
+}
+
+func (e *Executor) prepareCheckoutStep(step CheckoutStep) executionPlan {
+	if step.Transaction == nil || step.Options == nil || strings.TrimSpace(step.Options.Branch) == "" {
+		return immediatePlan(OperationCheckout, "checkout options not provided")
+	}
+	return queuedPlan(checkoutStepRequest(e.repo, step))
+}
+
+func (e *Executor) prepareDeleteBranch(options *DeleteBranchOptions) executionPlan {
+	if options == nil || strings.TrimSpace(options.Branch) == "" {
+		return immediatePlan(OperationBranch, "delete branch options not provided")
+	}
+	if options.Remote == "" && e.repo.State.Branch != nil && e.repo.State.Branch.Name == options.Branch {
+		return immediatePlan(OperationBranch, fmt.Sprintf("cannot delete current branch %s", options.Branch))
+	}
+
+	optsCopy := *options
+	timeout := DefaultGitCommandTimeout
+	if optsCopy.Remote != "" {
+		// Deleting a remote branch talks to the server like a push.
+		timeout = DefaultFetchTimeout
+	}
+	return queuedPlan(&GitCommandRequest{
+		Key:       fmt.Sprintf("delete-branch:%s:%s:%s", e.repo.RepoID, optsCopy.Remote, optsCopy.Branch),
+		Timeout:   timeout,
+		Operation: OperationBranch,
+		Execute: func(ctx context.Context) OperationOutcome {
+			// Remote branches cannot be brought back from here; only local
+			// deletions are undoable.
+			var commit string
+			if optsCopy.Remote == "" {
+				commit, _ = branchCommit(ctx, e.repo, optsCopy.Branch)
+			}
+			msg, err := DeleteBranchWithContext(ctx, e.repo, &optsCopy)
+			if err == nil && commit != "" {
+				pushUndo(e.repo, UndoEntry{
+					Kind:        UndoDeleteBranch,
+					Description: "delete " + optsCopy.Branch,
+					Ref:         optsCopy.Branch,
+					Commit:      commit,
+				})
+			}
+			return OperationOutcome{
+				Operation: OperationBranch,
+				Message:   msg,
+				Err:       err,
+			}
+		},
+	})
+}
+
+func (e *Executor) prepareUndo() executionPlan {
+	if _, ok := PeekUndo(e.repo); !ok {
+		return immediatePlan(OperationUndo, "nothing to undo")
+	}
+
+	return queuedPlan(&GitCommandRequest{
+		Key:       fmt.Sprintf("undo:%s", e.repo.RepoID),
+		Timeout:   DefaultGitCommandTimeout,
+		Operation: OperationUndo,
+		Execute: func(ctx context.Context) OperationOutcome {
+			// The entry is taken when the request runs, so undos queued
+			// back to back revert one action each.
+			entry, ok := popUndo(e.repo)
+			if !ok {
+				return OperationOutcome{Operation: OperationUndo, Err: errors.New("nothing to undo"), Message: "nothing to undo"}
+			}
+			msg, err := UndoWithContext(ctx, e.repo, entry)
+			if err != nil {
+				// Keep the entry so the action can be undone once the
+				// obstacle, e.g. local changes, is gone.
+				pushUndo(e.repo, entry)
+			}
+			return OperationOutcome{
+				Operation: OperationUndo,
+				Message:   msg,
+				Err:       err,
+			}
+		},
+	})
+}
+
+func (e *Executor) prepareSparseReapply() executionPlan {
+	if !e.repo.IsSparse() {
+		return immediatePlan(OperationSparseReapply, "not a sparse checkout")
+	}
+
+	return queuedPlan(&GitCommandRequest{
+		Key:       fmt.Sprintf("sparse-reapply:%s", e.repo.RepoID),
+		Timeout:   DefaultGitCommandTimeout,
+		Operation: OperationSparseReapply,
+		Execute: func(ctx context.Context) OperationOutcome {
+			msg, err := SparseReapplyWithContext(ctx, e.repo)
+			return OperationOutcome{
+				Operation: OperationSparseReapply,
+				Message:   msg,
+				Err:       err,
+			}
+		},
+	})
+}
+
+func (e *Executor) preparePruneRemote(remoteName string) executionPlan {
+	if e.repo.State.Remote == nil {
+		return immediatePlan(OperationPrune, "remote not set")
+	}
+
+	return queuedPlan(&GitCommandRequest{
+		Key:       fmt.Sprintf("prune:%s:%s", e.repo.RepoID, remoteName),
+		Timeout:   DefaultFetchTimeout,
+		Operation: OperationPrune,
+		Execute: func(ctx context.Context) OperationOutcome {
+			msg, err := PruneRemoteWithContext(ctx, e.repo, remoteName)
+			return OperationOutcome{
+				Operation: OperationPrune,
+				Message:   msg,
+				Err:       err,
+			}
+		},
+	})
+}
+
+func (e *Executor) prepareSetRemoteURLs(rewrites []RemoteURLRewrite) executionPlan {
+	if len(rewrites) == 0 {
+		return immediatePlan(OperationSetURL, "no remote URL to rewrite")
+	}
+
+	rewritesCopy := append([]RemoteURLRewrite(nil), rewrites...)
+	return queuedPlan(&GitCommandRequest{
+		Key:       fmt.Sprintf("set-url:%s", e.repo.RepoID),
+		Timeout:   DefaultGitCommandTimeout,
+		Operation: OperationSetURL,
+		Execute: func(ctx context.Context) OperationOutcome {
+			var messages []string
+			for _, rewrite := range rewritesCopy {
+				if err := SetRemoteURLWithContext(ctx, e.repo, rewrite.Remote, rewrite.New); err != nil {
+					return OperationOutcome{
+						Operation: OperationSetURL,
+						Message:   strings.Join(messages, ", "),
+						Err:       fmt.Errorf("set-url %s: %w", rewrite.Remote, err),
+					}
+				}
+				messages = append(messages, i18n.T("%s now points at %s", rewrite.Remote, rewrite.New))
+			}
+			return OperationOutcome{
+				Operation: OperationSetURL,
+				Message:   strings.Join(messages, ", "),
+			}
+		},
+	})
+}
+
+func (e *Executor) prepareBisect(step BisectStep, done func(*BisectProgress, error)) executionPlan {
+	return queuedPlan(&GitCommandRequest{
+		Key:       fmt.Sprintf("bisect:%s", e.repo.RepoID),
+		Timeout:   bisectRunTimeout,
+		Operation: OperationBisect,
+		Execute: func(ctx context.Context) OperationOutcome {
+			progress, err := step(ctx)
+			done(progress, err)
+			if err != nil {
+				return OperationOutcome{Operation: OperationBisect, Err: err}
+			}
+			return OperationOutcome{Operation: OperationBisect, Message: BisectSummary(progress)}
+		},
+		Dropped: func(err error) { done(nil, err) },
+	})
+}
+
+func (e *Executor) prepareSetUpstream(options *SetUpstreamOptions) executionPlan {
+	if options == nil || strings.TrimSpace(options.Branch) == "" || strings.TrimSpace(options.Upstream) == "" {
+		return immediatePlan(OperationBranch, "set upstream options not provided")
+	}
+
+	optsCopy := *options
+	return queuedPlan(&GitCommandRequest{
+		Key:       fmt.Sprintf("set-upstream:%s", e.repo.RepoID),
+		Timeout:   DefaultGitCommandTimeout,
+		Operation: OperationBranch,
+		Execute: func(ctx context.Context) OperationOutcome {
+			msg, err := SetUpstreamWithContext(ctx, e.repo, &optsCopy)
+			return OperationOutcome{
+				Operation: OperationBranch,
+				Message:   msg,
+				Err:       err,
+			}
+		},
+	})
+}
+
+func (e *Executor) prepareComposite(options *CompositeOptions) executionPlan {
+	if options == nil || options.Job == nil || len(options.Job.Steps) == 0 {
+		return immediatePlan(OperationComposite, "composite job not provided")
+	}
+
+	optsCopy := *options
+	job := options.Job
+	if optsCopy.Progress == nil {
+		optsCopy.Progress = func(step int) {
+			setRepositoryStatus(e.repo, git.Working, fmt.Sprintf("%s %d/%d: git %s...", job.Name, step+1, len(job.Steps), strings.Join(job.Steps[step], " ")))
+		}
+	}
+	return queuedPlan(&GitCommandRequest{
+		Key: fmt.Sprintf("composite:%s:%s", job.Name, e.repo.RepoID),
+		// Every step may talk to a remote.
+		Timeout:   DefaultFetchTimeout * time.Duration(len(job.Steps)),
+		Operation: OperationComposite,
+		Execute: func(ctx context.Context) OperationOutcome {
+			msg, err := CompositeWithContext(ctx, e.repo, &optsCopy)
+			return OperationOutcome{
+				Operation: OperationComposite,
+				Message:   msg,
+				Err:       err,
+			}
+		},
+	})
+}
+
+func (e *Executor) prepareSyncFork() executionPlan {
+	if _, _, ok := e.repo.ForkRemotes(); !ok {
+		return immediatePlan(OperationSyncFork, "not a fork: origin or upstream remote missing")
+	}
+
+	return queuedPlan(&GitCommandRequest{
+		Key: fmt.Sprintf("sync-fork:%s", e.repo.RepoID),
+		// The fetch and the push both talk to a remote.
+		Timeout:   2 * DefaultFetchTimeout,
+		Operation: OperationSyncFork,
+		Execute: func(ctx context.Context) OperationOutcome {
+			msg, err := SyncForkWithContext(ctx, e.repo)
+			return OperationOutcome{
+				Operation: OperationSyncFork,
+				Message:   msg,
+				Err:       err,
+			}
+		},
+	})
+}
+
+func (e *Executor) prepareRebaseOnDefault() executionPlan {
+	if e.repo.State.Remote == nil {
+		return immediatePlan(OperationRebase, "remote not set")
+	}
+
+	return queuedPlan(&GitCommandRequest{
+		Key: fmt.Sprintf("rebase-default:%s", e.repo.RepoID),
+		// The fetch talks to the remote before the rebase runs.
+		Timeout:   2 * DefaultFetchTimeout,
+		Operation: OperationRebase,
+		Execute: func(ctx context.Context) OperationOutcome {
+			msg, err := RebaseOnDefaultWithContext(ctx, e.repo)
+			return OperationOutcome{
+				Operation: OperationRebase,
+				Message:   msg,
+				Err:       err,
+			}
+		},
+	})
+}
+
 func queuedPlan(request *GitCommandRequest) executionPlan {
 	return executionPlan{request: request}
 }
@@ -372,7 +846,7 @@ func normalizeFetchOptions(options *FetchOptions, repo *git.Repository) *FetchOp
 		opts.RemoteName = repositoryRemoteName(repo)
 	}
 	if opts.Timeout <= 0 {
-		opts.Timeout = DefaultFetchTimeout
+		opts.Timeout = defaultFetchTimeout(&opts)
 	}
 	return &opts
 }
@@ -394,12 +868,16 @@ func normalizePullOptions(options *PullOptions, repo *git.Repository, ffOnly, re
 	if rebase {
 		opts.Rebase = true
 	}
+	if CurrentDirtyPolicy() == DirtyPolicyAutostash {
+		opts.Autostash = true
+	}
 	return &opts
 }
 
 func normalizeMergeOptions(options *MergeOptions, repo *git.Repository) *MergeOptions {
 	if options == nil {
-		options = &MergeOptions{}
+		style := CurrentMergeStyle()
+		options = &MergeOptions{NoFF: style == MergeStyleNoFF, Squash: style == MergeStyleSquash}
 	}
 	opts := *options
 	if opts.BranchName == "" && repo.State.Branch != nil && repo.State.Branch.Upstream != nil {
@@ -0,0 +1,108 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+var (
+	failFast  atomic.Bool
+	lastBatch atomic.Uint64
+
+	batchAbortMu   sync.Mutex
+	abortedBatches = make(map[BatchID]*BatchAbortedError)
+	batchAborts    []*BatchAbortedError
+)
+
+// BatchID tells the batches of jobs apart, so fail_fast only drops the jobs
+// of the batch a failure happened in. Zero is no batch.
+type BatchID uint64
+
+// NewBatchID returns the ID of a new batch.
+func NewBatchID() BatchID {
+	return BatchID(lastBatch.Add(1))
+}
+
+// SetFailFast configures whether a critical failure of a job drops the jobs
+// that still wait on the git queue.
+func SetFailFast(enabled bool) {
+	failFast.Store(enabled)
+}
+
+// BatchAbortedError is why the jobs of a batch were dropped: a job failed in
+// a way that would most likely repeat in every other repository, e.g. the
+// credentials of a host were rejected.
+type BatchAbortedError struct {
+	Batch      BatchID
+	Repository string
+	Kind       gerr.Kind
+}
+
+func (e *BatchAbortedError) Error() string {
+	return fmt.Sprintf("%s failure in %s (fail_fast)", e.Kind, e.Repository)
+}
+
+// Unwrap makes a dropped job count as cancelled.
+func (e *BatchAbortedError) Unwrap() error {
+	return context.Canceled
+}
+
+// criticalFailure reports whether a failure of kind is likely to hit the
+// other repositories as well: a rejected login or an unreachable host.
+func criticalFailure(kind gerr.Kind) bool {
+	return kind == gerr.KindAuth || kind == gerr.KindNetwork
+}
+
+// AbortsBatch reports whether err ends the batch under fail_fast.
+func AbortsBatch(err error) bool {
+	if !failFast.Load() || err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	return criticalFailure(gerr.KindOf(err))
+}
+
+// abortBatchOnFailure drops the jobs of the batch of outcome that wait on
+// the git queue when a job of r failed critically under fail_fast. The jobs
+// that already run finish, and other batches go on.
+func abortBatchOnFailure(r *git.Repository, outcome OperationOutcome) {
+	if outcome.Batch == 0 || !isJobOperation(outcome.Operation) || !AbortsBatch(outcome.Err) {
+		return
+	}
+	aborted := &BatchAbortedError{Batch: outcome.Batch, Repository: r.Name, Kind: gerr.KindOf(outcome.Err)}
+	batchAbortMu.Lock()
+	if _, ok := abortedBatches[outcome.Batch]; ok {
+		batchAbortMu.Unlock()
+		return
+	}
+	abortedBatches[outcome.Batch] = aborted
+	batchAborts = append(batchAborts, aborted)
+	batchAbortMu.Unlock()
+	dropHeldCommands(takeHeldCommandsOfBatch(outcome.Batch), aborted)
+}
+
+// batchAbort returns why the batch of request was aborted, or nil when it
+// was not.
+func batchAbort(request *GitCommandRequest) *BatchAbortedError {
+	if request.Batch == 0 {
+		return nil
+	}
+	batchAbortMu.Lock()
+	defer batchAbortMu.Unlock()
+	return abortedBatches[request.Batch]
+}
+
+// TakeBatchAborts returns why fail_fast dropped the waiting jobs of
+// batches, each abort once.
+func TakeBatchAborts() []*BatchAbortedError {
+	batchAbortMu.Lock()
+	defer batchAbortMu.Unlock()
+	aborts := batchAborts
+	batchAborts = nil
+	return aborts
+}
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/gittest/testkit"
+)
+
+func TestFailFastDropsTheWaitingJobsOnACriticalFailure(t *testing.T) {
+	SetFailFast(true)
+	t.Cleanup(func() {
+		SetFailFast(false)
+		TakeBatchAborts()
+	})
+	api := &git.Repository{Name: "api", State: &git.RepositoryState{}}
+	web := &git.Repository{Name: "web", State: &git.RepositoryState{}}
+	batch, other := NewBatchID(), NewBatchID()
+	authFailure := gerr.ParseGitError("fatal: Authentication failed for 'https://example.com/api.git/'", errors.New("exit status 128"))
+
+	EvaluateRepositoryState(api, OperationOutcome{
+		Operation: OperationPull,
+		Err:       gerr.ParseGitError("fatal: Unable to create 'index.lock': File exists.", errors.New("exit status 128")),
+		Batch:     batch,
+	})
+	require.Nil(t, batchAbort(&GitCommandRequest{Batch: batch}), "a failure of one repository keeps the batch going")
+	EvaluateRepositoryState(api, OperationOutcome{Operation: OperationPull, Err: authFailure})
+	require.Empty(t, TakeBatchAborts(), "a job outside of a batch aborts nothing")
+
+	EvaluateRepositoryState(api, OperationOutcome{Operation: OperationPull, Err: authFailure, Batch: batch})
+	aborted := &BatchAbortedError{Batch: batch, Repository: "api", Kind: gerr.KindAuth}
+	require.Equal(t, aborted, batchAbort(&GitCommandRequest{Batch: batch}))
+	require.Nil(t, batchAbort(&GitCommandRequest{Batch: other}), "other batches go on")
+	require.Nil(t, batchAbort(&GitCommandRequest{}))
+	require.Equal(t, []*BatchAbortedError{aborted}, TakeBatchAborts())
+	require.Empty(t, TakeBatchAborts(), "the abort is reported once")
+
+	EvaluateRepositoryState(web, OperationOutcome{Operation: OperationPull, Err: aborted, Batch: batch})
+	require.Equal(t, git.Available, web.WorkStatus())
+	require.Equal(t, "cancelled: auth failed in api (fail_fast)", web.State.Message())
+	require.Empty(t, TakeBatchAborts(), "a dropped job does not abort again")
+}
+
+func TestFailFastLeavesTheJobsOfOtherBatchesQueued(t *testing.T) {
+	SetFailFast(true)
+	t.Cleanup(func() {
+		SetFailFast(false)
+		TakeBatchAborts()
+	})
+	remote := testkit.NewRemote(t)
+	r, err := git.InitializeRepo(remote.Clone(t, "web"))
+	require.NoError(t, err)
+	api := &git.Repository{Name: "api", State: &git.RepositoryState{}}
+	failing, other := NewBatchID(), NewBatchID()
+	EvaluateRepositoryState(api, OperationOutcome{
+		Operation: OperationPush,
+		Err:       gerr.ParseGitError("fatal: Authentication failed for 'https://example.com/api.git/'", errors.New("exit status 128")),
+		Batch:     failing,
+	})
+
+	var ran atomic.Bool
+	dropped := make(chan error, 1)
+	require.NoError(t, ScheduleGitCommand(r, &GitCommandRequest{
+		Key:       "fetch:failing",
+		Operation: OperationFetch,
+		Batch:     failing,
+		Execute:   func(context.Context) OperationOutcome { return OperationOutcome{} },
+		Dropped:   func(err error) { dropped <- err },
+	}))
+	require.NoError(t, ScheduleGitCommand(r, &GitCommandRequest{
+		Key:       "fetch:other",
+		Operation: OperationFetch,
+		Batch:     other,
+		Execute: func(context.Context) OperationOutcome {
+			ran.Store(true)
+			return OperationOutcome{}
+		},
+	}))
+
+	var aborted *BatchAbortedError
+	require.ErrorAs(t, <-dropped, &aborted)
+	require.Equal(t, "api", aborted.Repository)
+	require.Eventually(t, ran.Load, 5*time.Second, 10*time.Millisecond, "the job of the other batch runs")
+}
+
+func TestAbortsBatchOnlyWithFailFast(t *testing.T) {
+	err := gerr.ParseGitError("ssh: Could not resolve hostname example.com: Name or service not known", errors.New("exit status 128"))
+	require.False(t, AbortsBatch(err))
+
+	SetFailFast(true)
+	t.Cleanup(func() { SetFailFast(false) })
+	require.True(t, AbortsBatch(err))
+	require.False(t, AbortsBatch(context.Canceled))
+	require.False(t, AbortsBatch(nil))
+}
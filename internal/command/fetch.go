@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-git/go-git/v5/plumbing"
@@ -12,8 +13,23 @@ import (
 	"github.com/thorstenhirsch/gitbatch/internal/git"
 )
 
+// DefaultFetchTimeout is how long a fetch may go without progress before it
+// is given up.
 const DefaultFetchTimeout = 60 * time.Second
 
+// DefaultUnshallowTimeout is the fetch inactivity timeout when the complete history of
+// a shallow clone is downloaded.
+const DefaultUnshallowTimeout = 10 * time.Minute
+
+// pruneOnFetch makes every fetch remove stale remote-tracking references.
+var pruneOnFetch atomic.Bool
+
+// SetPruneOnFetch configures whether fetches prune remote-tracking references
+// that no longer exist on the remote.
+func SetPruneOnFetch(enabled bool) {
+	pruneOnFetch.Store(enabled)
+}
+
 // FetchOptions defines the rules for fetch operation
 type FetchOptions struct {
 	// Name of the remote to fetch from. Defaults to origin.
@@ -30,6 +46,8 @@ type FetchOptions struct {
 	// Force allows the fetch to update a local branch even when the remote
 	// branch does not descend from it.
 	Force bool
+	// Unshallow fetches the complete history of a shallow clone.
+	Unshallow bool
 	// Timeout is the maximum duration allowed for the fetch command when
 	// executed via the legacy git CLI. If zero, a sensible default is used.
 	Timeout time.Duration
@@ -48,7 +66,7 @@ func FetchWithContext(ctx context.Context, r *git.Repository, o *FetchOptions) (
 		ctx = context.Background()
 	}
 	if o.Timeout <= 0 {
-		o.Timeout = DefaultFetchTimeout
+		o.Timeout = defaultFetchTimeout(o)
 	}
 	return fetchWithGit(ctx, r, o)
 }
@@ -58,12 +76,12 @@ func FetchWithContext(ctx context.Context, r *git.Repository, o *FetchOptions) (
 // tool. To avoid that, using native implementation is preferred.
 func fetchWithGit(ctx context.Context, r *git.Repository, options *FetchOptions) (string, error) {
 	args := make([]string, 0)
-	args = append(args, "fetch")
+	args = append(args, "fetch", "--progress")
 	// parse options to command line arguments
 	if len(options.RemoteName) > 0 {
 		args = append(args, options.RemoteName)
 	}
-	if options.Prune {
+	if options.Prune || pruneOnFetch.Load() {
 		args = append(args, "-p")
 	}
 	if options.Force {
@@ -72,24 +90,23 @@ func fetchWithGit(ctx context.Context, r *git.Repository, options *FetchOptions)
 	if options.DryRun {
 		args = append(args, "--dry-run")
 	}
+	if options.Unshallow {
+		args = append(args, "--unshallow")
+	}
+	creds, url := resolveCredentials(r, options.RemoteName, options.Credentials)
+	credArgs, credEnv := credentialArgs(creds)
+	args = append(credArgs, args...)
 	ref, _ := r.Repo.Head()
 	initialRef := shortHash(ref)
 
-	var (
-		out    string
-		errRun error
-	)
-	if options.Timeout > 0 {
-		out, errRun = RunWithContextTimeout(ctx, r.AbsPath, "git", args, options.Timeout)
-	} else {
-		out, errRun = RunWithContext(ctx, r.AbsPath, "git", args)
-	}
+	out, errRun := runWithEnv(ctx, r.AbsPath, "git", args, credEnv, options.Timeout)
 	if errRun != nil {
 		if errors.Is(errRun, context.DeadlineExceeded) {
-			return "", fmt.Errorf("fetch timed out after %s: %w", options.Timeout, errRun)
+			return "", fmt.Errorf("fetch timed out after %s without progress: %w", options.Timeout, errRun)
 		}
-		return "", gerr.ParseGitError(out, errRun)
+		return "", gerr.ParseGitError(stripProgress(out), errRun)
 	}
+	credentialsSucceeded(url, options.Credentials, out)
 	uRef := "origin/HEAD"
 	if r.State.Branch != nil && r.State.Branch.Upstream != nil {
 		up := r.State.Branch.Upstream
@@ -108,6 +125,13 @@ func fetchWithGit(ctx context.Context, r *git.Repository, options *FetchOptions)
 	return msg, nil
 }
 
+func defaultFetchTimeout(options *FetchOptions) time.Duration {
+	if options.Unshallow {
+		return DefaultUnshallowTimeout
+	}
+	return DefaultFetchTimeout
+}
+
 func shortHash(ref *plumbing.Reference) string {
 	if ref == nil {
 		return ""
@@ -2,6 +2,9 @@ package command
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -42,3 +45,29 @@ func TestFetchWithGit(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+func TestFetchUnshallowsAShallowClone(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	root := filepath.Dir(repoPath)
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("second"), 0o644))
+	_, err := Run(repoPath, "git", []string{"commit", "-am", "second commit"})
+	require.NoError(t, err)
+	_, err = Run(repoPath, "git", []string{"push", "origin", "main"})
+	require.NoError(t, err)
+	clonePath := filepath.Join(root, "clone")
+	_, err = Run(root, "git", []string{"clone", "--depth", "1", "--branch", "main", "file://" + filepath.Join(root, "remote.git"), clonePath})
+	require.NoError(t, err)
+	repo, err := git.InitializeRepo(clonePath)
+	require.NoError(t, err)
+	require.True(t, repo.IsShallow())
+
+	opts := normalizeFetchOptions(&FetchOptions{Unshallow: true}, repo)
+	require.Equal(t, DefaultUnshallowTimeout, opts.Timeout)
+	_, err = FetchWithContext(context.Background(), repo, opts)
+	require.NoError(t, err)
+	require.NoError(t, repo.Refresh())
+	require.False(t, repo.IsShallow())
+	count, err := Run(clonePath, "git", []string{"rev-list", "--count", "HEAD"})
+	require.NoError(t, err)
+	require.Equal(t, "2", strings.TrimSpace(count))
+}
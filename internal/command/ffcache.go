@@ -0,0 +1,101 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// ffDryRunKey identifies the inputs of a fast-forward dry run. The result
+// stays valid as long as neither HEAD, the upstream tip nor the index change.
+type ffDryRunKey struct {
+	head          plumbing.Hash
+	upstream      plumbing.Hash
+	indexModTime  time.Time
+	workTreeClean bool
+}
+
+type ffDryRunEntry struct {
+	key      ffDryRunKey
+	succeeds bool
+	mergeArg string
+}
+
+// ffDryRunCache memoizes fastForwardDryRunSucceeds per repository so repeated
+// cleanliness evaluations don't spawn merge-tree/diff/status each time.
+var ffDryRunCache = struct {
+	sync.Mutex
+	entries map[string]ffDryRunEntry
+}{entries: make(map[string]ffDryRunEntry)}
+
+// cachedFastForwardDryRunSucceeds returns the cached dry-run result when the
+// repository has not changed since the last evaluation and runs the dry run
+// otherwise.
+func cachedFastForwardDryRunSucceeds(r *git.Repository, mergeArg string, workingTreeClean bool) (bool, error) {
+	key, ok := fastForwardDryRunKey(r, mergeArg, workingTreeClean)
+	if ok {
+		ffDryRunCache.Lock()
+		entry, found := ffDryRunCache.entries[r.RepoID]
+		ffDryRunCache.Unlock()
+		if found && entry.key == key && entry.mergeArg == mergeArg {
+			return entry.succeeds, nil
+		}
+	}
+
+	succeeds, err := fastForwardDryRunSucceeds(r, mergeArg, workingTreeClean)
+	if err != nil {
+		return succeeds, err
+	}
+	// git status inside the dry run may rewrite the index stat cache, so the
+	// key is taken again once the dry run has finished.
+	if key, ok = fastForwardDryRunKey(r, mergeArg, workingTreeClean); !ok {
+		return succeeds, nil
+	}
+
+	ffDryRunCache.Lock()
+	ffDryRunCache.entries[r.RepoID] = ffDryRunEntry{key: key, succeeds: succeeds, mergeArg: mergeArg}
+	ffDryRunCache.Unlock()
+	return succeeds, nil
+}
+
+func fastForwardDryRunKey(r *git.Repository, mergeArg string, workingTreeClean bool) (ffDryRunKey, bool) {
+	if r == nil || r.Repo.Storer == nil || r.RepoID == "" {
+		return ffDryRunKey{}, false
+	}
+	head, err := r.Repo.Head()
+	if err != nil {
+		return ffDryRunKey{}, false
+	}
+	upstream, err := r.Repo.ResolveRevision(plumbing.Revision(mergeArg))
+	if err != nil || upstream == nil {
+		return ffDryRunKey{}, false
+	}
+
+	gitDir, _ := r.GitDirs()
+	info, err := os.Stat(filepath.Join(gitDir, "index"))
+	if err != nil {
+		return ffDryRunKey{}, false
+	}
+
+	return ffDryRunKey{
+		head:          head.Hash(),
+		upstream:      *upstream,
+		indexModTime:  info.ModTime(),
+		workTreeClean: workingTreeClean,
+	}, true
+}
+
+// forgetFastForwardDryRun drops the cached dry-run result of a repository so
+// the next evaluation runs it again.
+func forgetFastForwardDryRun(r *git.Repository) {
+	if r == nil {
+		return
+	}
+	ffDryRunCache.Lock()
+	delete(ffDryRunCache.entries, r.RepoID)
+	ffDryRunCache.Unlock()
+}
@@ -0,0 +1,42 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedFastForwardDryRunSucceeds(t *testing.T) {
+	repo := initOverlappingDirtyRepo(t)
+	mergeArg := upstreamMergeArgument(repo.State.Branch.Upstream)
+	require.NotEmpty(t, mergeArg)
+
+	succeeds, err := cachedFastForwardDryRunSucceeds(repo, mergeArg, false)
+	require.NoError(t, err)
+	require.False(t, succeeds, "local edit overlaps with the incoming commit")
+
+	ffDryRunCache.Lock()
+	entry, ok := ffDryRunCache.entries[repo.RepoID]
+	ffDryRunCache.Unlock()
+	require.True(t, ok, "result should be cached")
+
+	// Poison the cached result: an unchanged repository must return it as-is.
+	ffDryRunCache.Lock()
+	entry.succeeds = true
+	ffDryRunCache.entries[repo.RepoID] = entry
+	ffDryRunCache.Unlock()
+	succeeds, err = cachedFastForwardDryRunSucceeds(repo, mergeArg, false)
+	require.NoError(t, err)
+	require.True(t, succeeds, "cache hit expected while HEAD, upstream and index are unchanged")
+
+	// Touching the index invalidates the entry.
+	index := filepath.Join(repo.AbsPath, ".git", "index")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(index, later, later))
+	succeeds, err = cachedFastForwardDryRunSucceeds(repo, mergeArg, false)
+	require.NoError(t, err)
+	require.False(t, succeeds, "index change should force a fresh dry run")
+}
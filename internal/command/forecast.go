@@ -0,0 +1,153 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// ForecastOutcome predicts what integrating the upstream branch will do.
+type ForecastOutcome string
+
+const (
+	// ForecastUpToDate means there is nothing to integrate.
+	ForecastUpToDate ForecastOutcome = "up to date"
+	// ForecastFastForward means the branch can simply move forward.
+	ForecastFastForward ForecastOutcome = "fast-forward"
+	// ForecastClean means a merge commit without conflicts.
+	ForecastClean ForecastOutcome = "clean merge"
+	// ForecastDiverged means a fast-forward only pull is refused.
+	ForecastDiverged ForecastOutcome = "diverged"
+	// ForecastConflict means the merge stops with conflicts.
+	ForecastConflict ForecastOutcome = "conflict"
+	// ForecastBlocked means local changes to incoming files stop the job.
+	ForecastBlocked ForecastOutcome = "local changes in the way"
+	// ForecastUnknown means the forecast could not be made.
+	ForecastUnknown ForecastOutcome = "unknown"
+)
+
+// MergeForecast is the dry-run result of integrating a repository's upstream.
+type MergeForecast struct {
+	Repository *git.Repository
+	Outcome    ForecastOutcome
+	// Files lists the conflicting files, or the locally changed files the
+	// incoming commits would overwrite.
+	Files []string
+	Err   error
+}
+
+// WillFail reports whether a pull or merge is expected to stop.
+func (f *MergeForecast) WillFail() bool {
+	if f == nil {
+		return false
+	}
+	switch f.Outcome {
+	case ForecastDiverged, ForecastConflict, ForecastBlocked:
+		return true
+	}
+	return false
+}
+
+// ForecastMerge predicts the outcome of merging the upstream branch into the
+// current branch without touching the working tree. With ffOnly, as in a
+// fast-forward only pull, diverged branches are a failure of their own. The
+// forecast relies on the remote-tracking branch as of the last fetch.
+func ForecastMerge(ctx context.Context, r *git.Repository, ffOnly bool) *MergeForecast {
+	forecast := &MergeForecast{Repository: r, Outcome: ForecastUnknown}
+	if r == nil || r.State == nil || r.State.Branch == nil {
+		forecast.Err = fmt.Errorf("no branch checked out")
+		return forecast
+	}
+	mergeArg := upstreamMergeArgument(r.State.Branch.Upstream)
+	if mergeArg == "" {
+		forecast.Err = fmt.Errorf("upstream reference not set")
+		return forecast
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if _, err := RunWithContext(ctx, r.AbsPath, "git", []string{"merge-base", "--is-ancestor", mergeArg, "HEAD"}); err == nil {
+		forecast.Outcome = ForecastUpToDate
+		return forecast
+	}
+	if _, err := RunWithContext(ctx, r.AbsPath, "git", []string{"merge-base", "--is-ancestor", "HEAD", mergeArg}); err == nil {
+		forecast.Outcome = ForecastFastForward
+	} else if ffOnly {
+		forecast.Outcome = ForecastDiverged
+		return forecast
+	} else {
+		files, conflicted, err := mergeConflicts(ctx, r, mergeArg)
+		switch {
+		case err != nil:
+			forecast.Err = err
+			return forecast
+		case conflicted:
+			forecast.Outcome = ForecastConflict
+			forecast.Files = files
+			return forecast
+		}
+		forecast.Outcome = ForecastClean
+	}
+
+	incoming, err := incomingFiles(r, mergeArg)
+	if err != nil {
+		forecast.Outcome = ForecastUnknown
+		forecast.Err = err
+		return forecast
+	}
+	overlap, err := locallyChangedFiles(r, incoming)
+	if err != nil {
+		forecast.Outcome = ForecastUnknown
+		forecast.Err = err
+		return forecast
+	}
+	if len(overlap) > 0 {
+		forecast.Outcome = ForecastBlocked
+		forecast.Files = overlap
+	}
+	return forecast
+}
+
+// errNoMergeTree is returned by mergeConflicts on git releases without
+// `merge-tree --write-tree`. Conflicts are not predicted there, because the
+// only other way is a merge in the worktree of the user.
+var errNoMergeTree = errors.New("git before 2.38 cannot predict conflicts")
+
+// mergeConflicts predicts whether merging mergeArg into HEAD conflicts and
+// which files do, without touching the worktree.
+func mergeConflicts(ctx context.Context, r *git.Repository, mergeArg string) ([]string, bool, error) {
+	if !git.Supports(git.FeatureMergeTreeWriteTree) {
+		return nil, false, errNoMergeTree
+	}
+	out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"merge-tree", "--write-tree", "--name-only", "--no-messages", "HEAD", mergeArg})
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return nil, false, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		// The first line is the tree object, the conflicted files follow.
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		return uniqueNonEmpty(lines[1:]), true, nil
+	default:
+		return nil, false, fmt.Errorf("merge-tree: %w", err)
+	}
+}
+
+func uniqueNonEmpty(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
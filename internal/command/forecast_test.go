@@ -0,0 +1,86 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// pushUpstreamChange commits content to README.md in a second clone of the
+// repository's remote and fetches it into the repository.
+func pushUpstreamChange(t *testing.T, repoPath, content string) {
+	t.Helper()
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	_, err := Run(filepath.Dir(clonePath), "git", []string{"clone", "--branch", "main", filepath.Join(filepath.Dir(repoPath), "remote.git"), clonePath})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(clonePath, "README.md"), []byte(content), 0o644))
+	for _, args := range [][]string{
+		{"-c", "user.email=test@example.com", "-c", "user.name=Test User", "commit", "-am", "upstream change"},
+		{"push", "origin", "main"},
+	} {
+		out, err := Run(clonePath, "git", args)
+		require.NoError(t, err, out)
+	}
+	out, err := Run(repoPath, "git", []string{"fetch", "origin"})
+	require.NoError(t, err, out)
+}
+
+func TestForecastMerge(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	require.Equal(t, ForecastUpToDate, ForecastMerge(context.Background(), repo, false).Outcome)
+
+	pushUpstreamChange(t, repoPath, "upstream")
+	repo, err = git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	require.Equal(t, ForecastFastForward, ForecastMerge(context.Background(), repo, false).Outcome)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("local"), 0o644))
+	forecast := ForecastMerge(context.Background(), repo, false)
+	require.Equal(t, ForecastBlocked, forecast.Outcome)
+	require.Equal(t, []string{"README.md"}, forecast.Files)
+	require.True(t, forecast.WillFail())
+
+	_, err = Run(repoPath, "git", []string{"commit", "-am", "local change"})
+	require.NoError(t, err)
+	repo, err = git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	forecast = ForecastMerge(context.Background(), repo, false)
+	require.Equal(t, ForecastConflict, forecast.Outcome)
+	require.Equal(t, []string{"README.md"}, forecast.Files)
+	require.Equal(t, ForecastDiverged, ForecastMerge(context.Background(), repo, true).Outcome)
+}
+
+func TestForecastMerge_UnknownOnOldGit(t *testing.T) {
+	installed, err := git.InstalledVersion()
+	require.NoError(t, err)
+	git.SetInstalledVersion(git.Version{Major: 2, Minor: 30})
+	t.Cleanup(func() { git.SetInstalledVersion(installed) })
+
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	pushUpstreamChange(t, repoPath, "upstream")
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("local"), 0o644))
+	_, err = Run(repoPath, "git", []string{"-c", "user.email=test@example.com", "-c", "user.name=Test User", "commit", "-am", "local change"})
+	require.NoError(t, err)
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	head, err := Run(repoPath, "git", []string{"rev-parse", "HEAD"})
+	require.NoError(t, err)
+
+	forecast := ForecastMerge(context.Background(), repo, false)
+	require.Equal(t, ForecastUnknown, forecast.Outcome)
+	require.ErrorIs(t, forecast.Err, errNoMergeTree)
+	after, err := Run(repoPath, "git", []string{"rev-parse", "HEAD"})
+	require.NoError(t, err)
+	require.Equal(t, head, after)
+	status, err := Run(repoPath, "git", []string{"status", "--porcelain"})
+	require.NoError(t, err)
+	require.Empty(t, status, "the worktree is not touched")
+	_, err = Run(repoPath, "git", []string{"rev-parse", "-q", "--verify", "MERGE_HEAD"})
+	require.Error(t, err, "no merge was started")
+}
@@ -0,0 +1,90 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// SyncForkWithContext brings a fork up to date with the repository it was
+// forked from: it fetches upstream, fast-forwards the local default branch
+// to upstream's and pushes it to origin. Other branches are left alone, and
+// a default branch that has diverged from upstream stops the sync.
+func SyncForkWithContext(ctx context.Context, r *git.Repository) (string, error) {
+	upstream, origin, ok := r.ForkRemotes()
+	if !ok {
+		return "", fmt.Errorf("a fork needs an origin and an upstream remote")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if err := fetchRemote(ctx, r, upstream.Name); err != nil {
+		return "", err
+	}
+	branch, err := remoteDefaultBranch(ctx, r, upstream.Name)
+	if err != nil {
+		return "", err
+	}
+	before, _ := branchCommit(ctx, r, branch)
+	if err := fastForwardBranch(ctx, r, branch, "refs/remotes/"+upstream.Name+"/"+branch); err != nil {
+		return "", err
+	}
+
+	args := append([]string{"push", "--progress"}, noVerifyArgs(r)...)
+	args = append(args, origin.Name, "refs/heads/"+branch+":refs/heads/"+branch)
+	if out, err := RunWithContextTimeout(ctx, r.AbsPath, "git", args, DefaultFetchTimeout); err != nil {
+		return "", gerr.ParseGitError(stripProgress(out), err)
+	}
+
+	after, _ := branchCommit(ctx, r, branch)
+	if before == after {
+		return fmt.Sprintf("%s already in sync with %s", branch, upstream.Name), nil
+	}
+	return fmt.Sprintf("synced %s with %s and pushed it to %s", branch, upstream.Name, origin.Name), nil
+}
+
+// fetchRemote fetches a remote with the credentials remembered for it.
+func fetchRemote(ctx context.Context, r *git.Repository, remote string) error {
+	creds, _ := resolveCredentials(r, remote, nil)
+	credArgs, credEnv := credentialArgs(creds)
+	out, err := runWithEnv(ctx, r.AbsPath, "git", append(credArgs, "fetch", "--progress", remote), credEnv, DefaultFetchTimeout)
+	if err != nil {
+		return gerr.ParseGitError(stripProgress(out), err)
+	}
+	return nil
+}
+
+// fastForwardBranch moves a local branch to source, creating it if needed.
+// A branch that has commits source lacks is left alone and reported.
+func fastForwardBranch(ctx context.Context, r *git.Repository, branch, source string) error {
+	// fetch refuses to update the checked out branch, which merge does.
+	args := []string{"fetch", ".", source + ":refs/heads/" + branch}
+	if head, detached, err := currentHead(ctx, r); err == nil && !detached && head == branch {
+		args = []string{"merge", "--ff-only", source}
+	}
+	if out, err := RunWithContext(ctx, r.AbsPath, "git", args); err != nil {
+		return gerr.ParseGitError(out, err)
+	}
+	return nil
+}
+
+// remoteDefaultBranch returns the default branch of a remote, read from its
+// HEAD, or main or master when the remote HEAD is not known.
+func remoteDefaultBranch(ctx context.Context, r *git.Repository, remote string) (string, error) {
+	out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"symbolic-ref", "-q", "--short", "refs/remotes/" + remote + "/HEAD"})
+	if err == nil {
+		if branch, ok := strings.CutPrefix(strings.TrimSpace(out), remote+"/"); ok && branch != "" {
+			return branch, nil
+		}
+	}
+	for _, branch := range []string{"main", "master"} {
+		if _, err := RunWithContext(ctx, r.AbsPath, "git", []string{"rev-parse", "--verify", "-q", "refs/remotes/" + remote + "/" + branch}); err == nil {
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("cannot tell the default branch of %s", remote)
+}
@@ -0,0 +1,90 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestSyncFork_FastForwardsTheDefaultBranchAndPushesIt(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	root := filepath.Dir(repoPath)
+	upstreamPath := filepath.Join(root, "upstream.git")
+	_, err := Run(root, "git", []string{"clone", "--bare", filepath.Join(root, "remote.git"), upstreamPath})
+	require.NoError(t, err)
+
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	_, _, ok := repo.ForkRemotes()
+	require.False(t, ok, "a clone without upstream is no fork")
+	_, err = SyncForkWithContext(context.Background(), repo)
+	require.Error(t, err)
+
+	_, err = Run(repoPath, "git", []string{"remote", "add", "upstream", upstreamPath})
+	require.NoError(t, err)
+	repo, err = git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	_, _, ok = repo.ForkRemotes()
+	require.True(t, ok)
+
+	msg, err := SyncForkWithContext(context.Background(), repo)
+	require.NoError(t, err)
+	require.Equal(t, "main already in sync with upstream", msg)
+
+	upstreamChange := func(content string) string {
+		t.Helper()
+		clonePath := filepath.Join(t.TempDir(), "clone")
+		_, err := Run(filepath.Dir(clonePath), "git", []string{"clone", "--branch", "main", upstreamPath, clonePath})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(clonePath, "README.md"), []byte(content), 0o644))
+		for _, args := range [][]string{
+			{"-c", "user.email=test@example.com", "-c", "user.name=Test User", "commit", "-am", content},
+			{"push", "origin", "main"},
+		} {
+			out, err := Run(clonePath, "git", args)
+			require.NoError(t, err, out)
+		}
+		out, err := Run(clonePath, "git", []string{"rev-parse", "HEAD"})
+		require.NoError(t, err)
+		return strings.TrimSpace(out)
+	}
+	originMain := func() string {
+		t.Helper()
+		out, err := Run(filepath.Join(root, "remote.git"), "git", []string{"rev-parse", "main"})
+		require.NoError(t, err)
+		return strings.TrimSpace(out)
+	}
+
+	// On the default branch it is merged.
+	head := upstreamChange("first")
+	msg, err = SyncForkWithContext(context.Background(), repo)
+	require.NoError(t, err)
+	require.Equal(t, "synced main with upstream and pushed it to origin", msg)
+	require.Equal(t, head, originMain())
+	content, err := os.ReadFile(filepath.Join(repoPath, "README.md"))
+	require.NoError(t, err)
+	require.Equal(t, "first", string(content))
+
+	// On another branch it is updated without a checkout.
+	_, err = Run(repoPath, "git", []string{"checkout", "-b", "feature"})
+	require.NoError(t, err)
+	head = upstreamChange("second")
+	_, err = SyncForkWithContext(context.Background(), repo)
+	require.NoError(t, err)
+	require.Equal(t, head, originMain())
+	require.Equal(t, "feature", currentBranch(t, repoPath))
+
+	// A default branch with commits of its own is not touched.
+	_, err = Run(repoPath, "git", []string{"checkout", "main"})
+	require.NoError(t, err)
+	commitFile(t, repoPath, "local.txt", "local")
+	upstreamChange("third")
+	_, err = SyncForkWithContext(context.Background(), repo)
+	require.Error(t, err)
+	require.Equal(t, head, originMain())
+}
@@ -0,0 +1,139 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProtectedBranchPatterns returns the branches branch_protection protects in
+// the repository in dir: those of the last rule that selects it, or main and
+// master.
+func ProtectedBranchPatterns(dir string) []string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	branchProtectionMu.RLock()
+	defer branchProtectionMu.RUnlock()
+	patterns := defaultProtectedBranches
+	for _, rule := range branchProtection {
+		if repoPathMatches(rule.Path, dir) {
+			patterns = rule.Branches
+		}
+	}
+	return patterns
+}
+
+// CommitsAfterFreeze returns the commits that reached a branch matching
+// patterns after freeze, on the local branches and on every remote, merges
+// included, newest first. The reflog of each branch tells where it was at
+// the freeze, so a commit counts by when it reached the branch, not by its
+// committer date. A branch without a usable reflog falls back to the
+// commits committed after the freeze. Ref names the branch each was found on.
+func CommitsAfterFreeze(ctx context.Context, dir string, freeze time.Time, patterns []string) ([]CommitSummary, error) {
+	out, err := RunWithContext(ctx, dir, "git", []string{"for-each-ref", "--format=%(refname)", "refs/heads", "refs/remotes"})
+	if err != nil {
+		return nil, err
+	}
+	var refs []string
+	for _, ref := range strings.Split(out, "\n") {
+		if branch, ok := protectedRefBranch(ref); ok && branchMatches(patterns, branch) {
+			refs = append(refs, ref)
+		}
+	}
+	var commits []CommitSummary
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		args := []string{"--source", sinceArgument(freeze), ref}
+		if base, ok := refAtFreeze(ctx, dir, ref, freeze); ok {
+			args = []string{"--source", ref, "^" + base}
+		}
+		found, err := logCommits(ctx, dir, args)
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range found {
+			if seen[commit.Hash] {
+				continue
+			}
+			seen[commit.Hash] = true
+			commit.Ref = shortRefName(commit.Ref)
+			commits = append(commits, commit)
+		}
+	}
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].Time.After(commits[j].Time) })
+	return commits, nil
+}
+
+// refAtFreeze returns the commit ref pointed at when freeze began, from the
+// reflog of ref. When the reflog starts after the freeze, the ref is taken
+// to be where its first logged update moved it from. It reports false when
+// there is no reflog, or when the ref was created after the freeze.
+func refAtFreeze(ctx context.Context, dir, ref string, freeze time.Time) (string, bool) {
+	path, err := RunWithContext(ctx, dir, "git", []string{"rev-parse", "--git-path", "logs/" + ref})
+	if err != nil {
+		return "", false
+	}
+	path = strings.TrimSpace(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	// Each line is "<old> <new> <name> <email> <unix time> <zone>\t<message>",
+	// the oldest first.
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		entry, _, _ := strings.Cut(lines[i], "\t")
+		fields := strings.Fields(entry)
+		if len(fields) < 4 {
+			continue
+		}
+		seconds, err := strconv.ParseInt(fields[len(fields)-2], 10, 64)
+		if err != nil {
+			continue
+		}
+		if seconds <= freeze.Unix() {
+			return fields[1], true
+		}
+		if i == 0 && strings.Trim(fields[0], "0") != "" {
+			return fields[0], true
+		}
+	}
+	return "", false
+}
+
+// protectedRefBranch returns the branch name of a local or remote-tracking
+// ref, e.g. main for refs/remotes/origin/main. The symbolic HEAD of a remote
+// is not a branch.
+func protectedRefBranch(ref string) (string, bool) {
+	if branch, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+		return branch, true
+	}
+	rest, ok := strings.CutPrefix(ref, "refs/remotes/")
+	if !ok {
+		return "", false
+	}
+	_, branch, ok := strings.Cut(rest, "/")
+	if !ok || branch == "HEAD" {
+		return "", false
+	}
+	return branch, true
+}
+
+// shortRefName drops refs/heads/ and refs/remotes/ from a ref.
+func shortRefName(ref string) string {
+	if short, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+		return short
+	}
+	if short, ok := strings.CutPrefix(ref, "refs/remotes/"); ok {
+		return short
+	}
+	return ref
+}
@@ -0,0 +1,72 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/gittest/testkit"
+)
+
+// commitOn commits a file on the checked out branch of dir at date.
+func commitOn(t *testing.T, dir, file, date string) {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_DATE", date)
+	t.Setenv("GIT_COMMITTER_DATE", date)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(date), 0o644))
+	testkit.Git(t, dir, "add", file)
+	testkit.Git(t, dir, "commit", "-m", file)
+}
+
+func TestCommitsAfterFreeze(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_DATE", "2026-09-01T10:00:00Z")
+	t.Setenv("GIT_COMMITTER_DATE", "2026-09-01T10:00:00Z")
+	remote := testkit.NewRemote(t)
+	clone := remote.Clone(t, "api")
+
+	upstream := remote.Clone(t, "upstream")
+	commitOn(t, upstream, "hotfix.txt", "2026-10-04T10:00:00Z")
+	testkit.Git(t, upstream, "push", "origin", "main")
+	testkit.Git(t, clone, "fetch", "origin")
+
+	commitOn(t, clone, "local.txt", "2026-10-03T10:00:00Z")
+	testkit.Git(t, clone, "checkout", "-b", "feature")
+	commitOn(t, clone, "feature.txt", "2026-10-05T10:00:00Z")
+
+	freeze := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	commits, err := CommitsAfterFreeze(context.Background(), clone, freeze, []string{"main"})
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	require.Equal(t, "hotfix.txt", commits[0].Subject)
+	require.Equal(t, "origin/main", commits[0].Ref)
+	require.Equal(t, "local.txt", commits[1].Subject)
+	require.Equal(t, "main", commits[1].Ref)
+
+	// A commit dated before the freeze that is pushed after it counts: the
+	// reflog tells when it reached origin/main.
+	commitOn(t, upstream, "backdated.txt", "2026-09-15T10:00:00Z")
+	testkit.Git(t, upstream, "push", "origin", "main")
+	t.Setenv("GIT_COMMITTER_DATE", "2026-10-06T10:00:00Z")
+	testkit.Git(t, clone, "fetch", "origin")
+	commits, err = CommitsAfterFreeze(context.Background(), clone, freeze, []string{"main"})
+	require.NoError(t, err)
+	require.Len(t, commits, 3)
+	require.Equal(t, "backdated.txt", commits[2].Subject)
+	require.Equal(t, "origin/main", commits[2].Ref)
+
+	commits, err = CommitsAfterFreeze(context.Background(), clone, freeze, []string{"release/*"})
+	require.NoError(t, err)
+	require.Empty(t, commits)
+}
+
+func TestProtectedBranchPatterns(t *testing.T) {
+	root := t.TempDir()
+	require.Equal(t, []string{"main", "master"}, ProtectedBranchPatterns(filepath.Join(root, "api")))
+
+	SetBranchProtection([]BranchProtectionRule{{Path: root, Branches: []string{"release/*"}}})
+	t.Cleanup(func() { SetBranchProtection(nil) })
+	require.Equal(t, []string{"release/*"}, ProtectedBranchPatterns(filepath.Join(root, "api")))
+}
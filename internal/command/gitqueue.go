@@ -4,17 +4,60 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
 )
 
 // GitCommandFunc describes the work executed on the git queue. The returned OperationOutcome
 // will be forwarded to the state queue regardless of success or failure.
 type GitCommandFunc func(ctx context.Context) OperationOutcome
 
+// DefaultGitCommandTimeout is how long a queued git command may run without
+// producing output before it is given up. As long as progress keeps arriving
+// the command is kept alive, so large transfers are not cut off.
 const DefaultGitCommandTimeout = 10 * time.Second
 
+// activityKey carries the channel on which the commands of a queued operation
+// report their output.
+type activityKey struct{}
+
+// withActivity returns a context whose commands report output on the returned
+// channel.
+func withActivity(ctx context.Context) (context.Context, <-chan struct{}) {
+	ch := make(chan struct{}, 1)
+	return context.WithValue(ctx, activityKey{}, ch), ch
+}
+
+// queuedKey carries the channel that is closed once the git queue stopped
+// waiting for the commands of a queued operation, e.g. after its timeout.
+type queuedKey struct{}
+
+// withQueued marks ctx as the context of a queued operation.
+func withQueued(ctx context.Context, done <-chan struct{}) context.Context {
+	return context.WithValue(ctx, queuedKey{}, done)
+}
+
+// queuedDone returns the channel of withQueued, or nil when ctx does not
+// belong to a queued operation.
+func queuedDone(ctx context.Context) (<-chan struct{}, bool) {
+	done, ok := ctx.Value(queuedKey{}).(<-chan struct{})
+	return done, ok
+}
+
+// reportActivity notes that a command running under ctx produced output.
+func reportActivity(ctx context.Context) {
+	if ch, ok := ctx.Value(activityKey{}).(chan struct{}); ok {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
 // DynamicTimeout calculates a timeout that scales with the number of changes.
 // For every 100 changes the base timeout is added once more, so the result is
 // base * (1 + changes/100). The returned value is never less than base.
@@ -27,12 +70,58 @@ func DynamicTimeout(base time.Duration, changes int) time.Duration {
 }
 
 // GitCommandRequest encapsulates metadata required by the git queue listener.
-// It declares a timeout enforced by the queue infrastructure.
+// It declares an inactivity timeout enforced by the queue infrastructure.
 type GitCommandRequest struct {
 	Key       string
 	Timeout   time.Duration
 	Operation OperationType
 	Execute   GitCommandFunc
+	// HostCredentials is set when the command authenticates with the
+	// credentials remembered for its host; it waits while the host is
+	// paused for credentials.
+	HostCredentials bool
+	// CredentialRetry is set when the command brings the credentials typed
+	// into the credential prompt. It runs even while other commands of its
+	// repository are held, as they wait for it. Once it succeeds, the
+	// commands held back for its host run again.
+	CredentialRetry bool
+	// Dropped, if set, is called with the cause when the command is
+	// cancelled before it ran.
+	Dropped func(error)
+	// Batch is the batch the command belongs to, zero for none.
+	Batch BatchID
+}
+
+var (
+	scheduleMu     sync.Mutex
+	scheduleCtx    context.Context
+	scheduleCancel context.CancelFunc
+	waitingCount   atomic.Int64
+)
+
+// scheduleContext returns the context new git commands are scheduled with.
+func scheduleContext() context.Context {
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+	if scheduleCtx == nil {
+		scheduleCtx, scheduleCancel = context.WithCancel(context.Background())
+	}
+	return scheduleCtx
+}
+
+// CancelScheduledCommands cancels the git commands that wait on the git queue
+// or for the credentials of their host and returns how many there were.
+// Commands that already run are not interrupted, and commands scheduled
+// afterwards run as usual.
+func CancelScheduledCommands() int {
+	scheduleMu.Lock()
+	if scheduleCancel != nil {
+		scheduleCancel()
+	}
+	scheduleCtx, scheduleCancel = context.WithCancel(context.Background())
+	waiting := int(waitingCount.Load())
+	scheduleMu.Unlock()
+	return waiting + dropHeldCommands(takeAllHeldCommands(), context.Canceled)
 }
 
 // ScheduleGitCommand publishes a request to the repository git queue.
@@ -46,7 +135,12 @@ func ScheduleGitCommand(repo *git.Repository, request *GitCommandRequest) error
 	if request.Key == "" {
 		return fmt.Errorf("git command request key required")
 	}
-	return repo.Publish(git.RepositoryGitCommandRequested, request)
+	waitingCount.Add(1)
+	if err := repo.PublishContext(scheduleContext(), git.RepositoryGitCommandRequested, request); err != nil {
+		waitingCount.Add(-1)
+		return err
+	}
+	return nil
 }
 
 // AttachGitCommandWorker registers the git queue listener responsible for executing
@@ -60,22 +154,50 @@ func AttachGitCommandWorker(r *git.Repository) {
 		if !ok || req == nil {
 			return fmt.Errorf("unexpected git command payload: %T", event.Data)
 		}
+		waitingCount.Add(-1)
 		ctx := event.Context
 		if ctx == nil {
 			ctx = context.Background()
 		}
+		var dropped error
+		if ctx.Err() != nil {
+			dropped = context.Cause(ctx)
+		} else if aborted := batchAbort(req); aborted != nil {
+			dropped = aborted
+		}
+		if dropped != nil {
+			if req.Dropped != nil {
+				req.Dropped(dropped)
+			}
+			ScheduleStateEvaluation(r, OperationOutcome{Operation: req.Operation, Err: dropped, Batch: req.Batch})
+			return nil
+		}
+		if holdForCredentials(r, req) {
+			return nil
+		}
+		// Cancelling only drops commands that have not started. A running
+		// command finishes so it cannot leave the repository half updated.
+		ctx = context.WithoutCancel(ctx)
 		timeout := req.Timeout
 		if timeout <= 0 {
 			timeout = DefaultGitCommandTimeout
 		}
 		startGitOperation(r, req.Operation)
 		defer r.EndWatchSuppress()
+		ctx, activity := withActivity(ctx)
 		resultCh := make(chan OperationOutcome, 1)
 		done := make(chan struct{})
+		ctx = withProgress(ctx, repositoryProgress(r, req.Operation, done))
+		ctx = withQueued(ctx, done)
 		go func() {
 			outcome := OperationOutcome{}
 			if req.Execute != nil {
-				outcome = req.Execute(ctx)
+				auditCtx, audit := startAudit(ctx, req.Operation)
+				outcome = executeWithLockCleanup(auditCtx, r, req)
+				if outcome.Operation == "" {
+					outcome.Operation = req.Operation
+				}
+				audit.finish(r, outcome)
 			}
 			if ctx.Err() != nil && outcome.Err == nil {
 				outcome.Err = ctx.Err()
@@ -85,31 +207,46 @@ func AttachGitCommandWorker(r *git.Repository) {
 			case <-done:
 			}
 		}()
-		select {
-		case outcome := <-resultCh:
-			close(done)
-			if outcome.Operation == "" {
-				outcome.Operation = req.Operation
-			}
-			ScheduleStateEvaluation(r, outcome)
-		case <-time.After(timeout):
-			close(done)
-			op := req.Operation
-			if op == "" {
-				op = OperationGit
-			}
-			err := fmt.Errorf("%s command timed out after %s", op, timeout)
-			ScheduleStateEvaluation(r, OperationOutcome{
-				Operation: op,
-				Err:       err,
-				Message:   "git command timed out",
-			})
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		for {
 			select {
-			case <-resultCh:
-			default:
+			case outcome := <-resultCh:
+				close(done)
+				if outcome.Operation == "" {
+					outcome.Operation = req.Operation
+				}
+				outcome.Batch = req.Batch
+				rememberLockedRequest(r, req, outcome.Err)
+				ScheduleStateEvaluation(r, outcome)
+				// Whatever the retry needed, the host answers again, so the
+				// jobs held back for it can run.
+				if req.CredentialRetry && outcome.Err == nil {
+					resumeHost(credentialHost(r))
+				}
+				return nil
+			case <-activity:
+				timer.Reset(timeout)
+			case <-timer.C:
+				close(done)
+				op := req.Operation
+				if op == "" {
+					op = OperationGit
+				}
+				err := fmt.Errorf("%s command timed out after %s without output", op, timeout)
+				ScheduleStateEvaluation(r, OperationOutcome{
+					Operation: op,
+					Err:       err,
+					Message:   "git command timed out",
+					Batch:     req.Batch,
+				})
+				select {
+				case <-resultCh:
+				default:
+				}
+				return nil
 			}
 		}
-		return nil
 	})
 }
 
@@ -118,24 +255,37 @@ func startGitOperation(r *git.Repository, operation OperationType) {
 		return
 	}
 	r.BeginWatchSuppress()
-	message := "running git command..."
+	setRepositoryStatus(r, git.Working, operationMessage(operation))
+}
+
+// operationMessage is the message of a repository while operation runs.
+func operationMessage(operation OperationType) string {
 	switch operation {
 	case OperationFetch:
-		message = "fetching..."
+		return i18n.T("fetching...")
 	case OperationPull:
-		message = "pulling..."
+		return i18n.T("pulling...")
 	case OperationMerge:
-		message = "merging..."
+		return i18n.T("merging...")
 	case OperationRebase:
-		message = "rebasing..."
+		return i18n.T("rebasing...")
 	case OperationPush:
-		message = "pushing..."
-	default:
-		if op := strings.TrimSpace(string(operation)); op != "" && operation != OperationGit {
-			message = fmt.Sprintf("%s...", strings.ToLower(op))
-		}
+		return i18n.T("pushing...")
+	case OperationSyncFork:
+		return i18n.T("syncing fork...")
+	case OperationSparseReapply:
+		return i18n.T("reapplying sparse-checkout...")
+	case OperationBisect:
+		return i18n.T("bisecting...")
+	case OperationPrune:
+		return i18n.T("pruning...")
+	case OperationSetURL:
+		return i18n.T("updating remote URLs...")
+	}
+	if op := strings.TrimSpace(string(operation)); op != "" && operation != OperationGit {
+		return i18n.T("%s...", strings.ToLower(op))
 	}
-	setRepositoryStatus(r, git.Working, message)
+	return i18n.T("running git command...")
 }
 
 func init() {
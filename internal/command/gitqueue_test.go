@@ -1,10 +1,14 @@
 package command
 
 import (
+	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
 )
 
 func TestDynamicTimeout(t *testing.T) {
@@ -32,3 +36,61 @@ func TestDynamicTimeout(t *testing.T) {
 		})
 	}
 }
+
+func TestCancelScheduledCommandsReplacesTheScheduleContext(t *testing.T) {
+	ctx := scheduleContext()
+	CancelScheduledCommands()
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+	require.NoError(t, scheduleContext().Err(), "commands scheduled after a cancel run as usual")
+}
+
+func TestCancelledGitCommandIsSkipped(t *testing.T) {
+	repo, err := git.InitializeRepo(initLocalWorktreeRepoForStateTest(t))
+	require.NoError(t, err)
+	repo.SetWorkStatus(git.Working)
+
+	var ran atomic.Bool
+	dropped := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, repo.PublishContext(ctx, git.RepositoryGitCommandRequested, &GitCommandRequest{
+		Key:       "fetch",
+		Operation: OperationFetch,
+		Execute: func(context.Context) OperationOutcome {
+			ran.Store(true)
+			return OperationOutcome{Operation: OperationFetch}
+		},
+		Dropped: func(err error) { dropped <- err },
+	}))
+
+	require.Eventually(t, func() bool {
+		return repo.WorkStatus() == git.Available
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "cancelled", repo.State.Message())
+	assert.False(t, ran.Load())
+	assert.ErrorIs(t, <-dropped, context.Canceled)
+}
+
+func TestGitCommandTimeoutRestartsOnActivity(t *testing.T) {
+	repo, err := git.InitializeRepo(initLocalWorktreeRepoForStateTest(t))
+	require.NoError(t, err)
+	repo.SetWorkStatus(git.Working)
+
+	require.NoError(t, repo.Publish(git.RepositoryGitCommandRequested, &GitCommandRequest{
+		Key:       "fetch",
+		Timeout:   150 * time.Millisecond,
+		Operation: OperationFetch,
+		Execute: func(ctx context.Context) OperationOutcome {
+			for range 6 {
+				time.Sleep(50 * time.Millisecond)
+				reportActivity(ctx)
+			}
+			return OperationOutcome{Operation: OperationFetch, Message: "fetched after a while"}
+		},
+	}))
+
+	require.Eventually(t, func() bool {
+		return repo.WorkStatus() == git.Available
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "fetched after a while", repo.State.Message())
+}
@@ -0,0 +1,200 @@
+package command
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// HostPause is a remote host whose jobs wait for credentials after one of
+// them failed to authenticate, so the user is asked once instead of once per
+// repository.
+type HostPause struct {
+	Host string
+	// Repository is the repository whose job failed to authenticate.
+	Repository *git.Repository
+	// Waiting is the number of jobs held back until the credentials work.
+	Waiting int
+}
+
+// heldCommand is a git command held back while its host is paused.
+type heldCommand struct {
+	repo    *git.Repository
+	request *GitCommandRequest
+}
+
+type hostPause struct {
+	repo *git.Repository
+	held []heldCommand
+}
+
+var (
+	hostPausesMu sync.Mutex
+	hostPauses   = make(map[string]*hostPause)
+)
+
+// credentialHost returns the host whose remembered credentials the jobs of r
+// use, or "" for ssh remotes, which ask for the passphrase of a key instead.
+func credentialHost(r *git.Repository) string {
+	url := remoteURL(r, "")
+	if url == "" || git.IsSSHURL(url) {
+		return ""
+	}
+	return strings.ToLower(git.RemoteHost(url))
+}
+
+// pauseHost holds back the jobs for the host of r that need credentials
+// after a job of r failed to authenticate.
+func pauseHost(r *git.Repository, outcome OperationOutcome) {
+	if !isJobOperation(outcome.Operation) {
+		return
+	}
+	host := credentialHost(r)
+	if host == "" {
+		return
+	}
+	hostPausesMu.Lock()
+	defer hostPausesMu.Unlock()
+	if _, ok := hostPauses[host]; !ok {
+		hostPauses[host] = &hostPause{repo: r}
+	}
+}
+
+// holdForCredentials keeps request for r back while the host of r is paused
+// and reports whether it did. Once a request of r is held, the requests of r
+// that follow are held behind it, so none of them overtakes it, except for
+// the credential retry they wait for.
+func holdForCredentials(r *git.Repository, request *GitCommandRequest) bool {
+	if request.CredentialRetry {
+		return false
+	}
+	hostPausesMu.Lock()
+	host, pause := heldPause(r)
+	if pause == nil && request.HostCredentials {
+		host = credentialHost(r)
+		pause = hostPauses[host]
+	}
+	if pause != nil {
+		pause.held = append(pause.held, heldCommand{repo: r, request: request})
+	}
+	hostPausesMu.Unlock()
+	if pause != nil {
+		setRepositoryStatus(r, git.Pending, i18n.T("waiting for credentials for %s", host))
+	}
+	return pause != nil
+}
+
+// heldPause returns the paused host that holds a request of r back. The
+// caller holds hostPausesMu.
+func heldPause(r *git.Repository) (string, *hostPause) {
+	for host, pause := range hostPauses {
+		for _, h := range pause.held {
+			if h.repo == r {
+				return host, pause
+			}
+		}
+	}
+	return "", nil
+}
+
+// PausedHosts returns the hosts that wait for credentials, sorted by name.
+func PausedHosts() []HostPause {
+	hostPausesMu.Lock()
+	defer hostPausesMu.Unlock()
+	pauses := make([]HostPause, 0, len(hostPauses))
+	for host, pause := range hostPauses {
+		pauses = append(pauses, HostPause{Host: host, Repository: pause.repo, Waiting: len(pause.held)})
+	}
+	sort.Slice(pauses, func(i, j int) bool { return pauses[i].Host < pauses[j].Host })
+	return pauses
+}
+
+// resumeHost schedules the jobs held back for host again, in the order they
+// were held. They run with the credentials remembered for it by now.
+func resumeHost(host string) {
+	rescheduleHeldCommands(takeHeldCommands(host))
+}
+
+// rescheduleHeldCommands puts held jobs back on the git queue.
+func rescheduleHeldCommands(held []heldCommand) {
+	for _, h := range held {
+		if err := ScheduleGitCommand(h.repo, h.request); err != nil {
+			ScheduleStateEvaluation(h.repo, OperationOutcome{Operation: h.request.Operation, Err: err})
+		}
+	}
+}
+
+// DropHeldCommands ends the pause of host, e.g. when the credential prompt
+// is dismissed, and cancels the jobs held back for its credentials. The jobs
+// that were only held behind one of them are scheduled again.
+func DropHeldCommands(host string) int {
+	var dropped, followers []heldCommand
+	for _, h := range takeHeldCommands(strings.ToLower(host)) {
+		if h.request.HostCredentials {
+			dropped = append(dropped, h)
+		} else {
+			followers = append(followers, h)
+		}
+	}
+	rescheduleHeldCommands(followers)
+	return dropHeldCommands(dropped, context.Canceled)
+}
+
+// dropHeldCommands cancels held jobs with err and returns how many there were.
+func dropHeldCommands(held []heldCommand, err error) int {
+	for _, h := range held {
+		if h.request.Dropped != nil {
+			h.request.Dropped(err)
+		}
+		ScheduleStateEvaluation(h.repo, OperationOutcome{Operation: h.request.Operation, Err: err})
+	}
+	return len(held)
+}
+
+// takeHeldCommands ends the pause of host and returns its held jobs.
+func takeHeldCommands(host string) []heldCommand {
+	hostPausesMu.Lock()
+	defer hostPausesMu.Unlock()
+	pause, ok := hostPauses[host]
+	if !ok {
+		return nil
+	}
+	delete(hostPauses, host)
+	return pause.held
+}
+
+// takeAllHeldCommands returns the jobs held back for every host. The hosts
+// stay paused until their credentials work.
+func takeAllHeldCommands() []heldCommand {
+	hostPausesMu.Lock()
+	defer hostPausesMu.Unlock()
+	var held []heldCommand
+	for _, pause := range hostPauses {
+		held = append(held, pause.held...)
+		pause.held = nil
+	}
+	return held
+}
+
+// takeHeldCommandsOfBatch returns the jobs of batch held back for any host.
+func takeHeldCommandsOfBatch(batch BatchID) []heldCommand {
+	hostPausesMu.Lock()
+	defer hostPausesMu.Unlock()
+	var taken []heldCommand
+	for _, pause := range hostPauses {
+		kept := pause.held[:0]
+		for _, h := range pause.held {
+			if h.request.Batch == batch {
+				taken = append(taken, h)
+			} else {
+				kept = append(kept, h)
+			}
+		}
+		pause.held = kept
+	}
+	return taken
+}
@@ -0,0 +1,107 @@
+package command
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/gittest/testkit"
+)
+
+func repoWithRemote(name, url string) *git.Repository {
+	return &git.Repository{Name: name, State: &git.RepositoryState{
+		Remote: &git.Remote{Name: "origin", URL: []string{url}},
+	}}
+}
+
+func TestAuthFailurePausesTheJobsOfTheHost(t *testing.T) {
+	t.Cleanup(func() { DropHeldCommands("git.example.com") })
+	api := repoWithRemote("api", "https://git.example.com/org/api.git")
+	web := repoWithRemote("web", "https://GIT.example.com/org/web.git")
+	docs := repoWithRemote("docs", "https://docs.example.com/org/docs.git")
+	infra := repoWithRemote("infra", "git@git.example.com:org/infra.git")
+
+	fetch := &GitCommandRequest{Operation: OperationFetch, HostCredentials: true}
+	require.False(t, holdForCredentials(web, fetch), "nothing is paused yet")
+
+	pauseHost(api, OperationOutcome{Operation: OperationFetch})
+	require.True(t, holdForCredentials(web, fetch))
+	require.Equal(t, git.Pending, web.WorkStatus())
+	require.Equal(t, "waiting for credentials for git.example.com", web.State.Message())
+	require.False(t, holdForCredentials(docs, fetch), "other hosts keep running")
+	require.False(t, holdForCredentials(infra, fetch), "ssh remotes ask for a passphrase instead")
+	require.False(t, holdForCredentials(web, &GitCommandRequest{Operation: OperationFetch, CredentialRetry: true}), "a credential retry is not held")
+	require.Equal(t, []HostPause{{Host: "git.example.com", Repository: api, Waiting: 1}}, PausedHosts())
+
+	pauseHost(web, OperationOutcome{Operation: OperationFetch})
+	require.Equal(t, api, PausedHosts()[0].Repository, "the host is asked for once")
+
+	require.Equal(t, 1, DropHeldCommands("Git.Example.com"))
+	require.Empty(t, PausedHosts())
+	require.False(t, holdForCredentials(web, fetch))
+}
+
+func TestCancelScheduledCommandsDropsTheHeldJobs(t *testing.T) {
+	t.Cleanup(func() { DropHeldCommands("git.example.com") })
+	api := repoWithRemote("api", "https://git.example.com/org/api.git")
+	web := repoWithRemote("web", "https://git.example.com/org/web.git")
+
+	pauseHost(api, OperationOutcome{Operation: OperationPull})
+	require.True(t, holdForCredentials(web, &GitCommandRequest{Operation: OperationPull, HostCredentials: true}))
+	CancelScheduledCommands()
+	require.Equal(t, []HostPause{{Host: "git.example.com", Repository: api}}, PausedHosts(), "the host stays paused")
+}
+
+func TestHeldRequestKeepsTheLaterRequestsOfItsRepositoryBack(t *testing.T) {
+	t.Cleanup(func() { DropHeldCommands("git.example.com") })
+	remote := testkit.NewRemote(t)
+	clone := remote.Clone(t, "web")
+	testkit.Git(t, clone, "remote", "set-url", "origin", "https://git.example.com/org/web.git")
+	web, err := git.InitializeRepo(clone)
+	require.NoError(t, err)
+	api := repoWithRemote("api", "https://git.example.com/org/api.git")
+	pauseHost(api, OperationOutcome{Operation: OperationFetch})
+
+	var mu sync.Mutex
+	var ran []string
+	request := func(name string, hostCredentials, retry bool) *GitCommandRequest {
+		return &GitCommandRequest{
+			Key:             name,
+			Operation:       OperationFetch,
+			HostCredentials: hostCredentials,
+			CredentialRetry: retry,
+			Execute: func(context.Context) OperationOutcome {
+				mu.Lock()
+				ran = append(ran, name)
+				mu.Unlock()
+				return OperationOutcome{}
+			},
+		}
+	}
+	executed := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), ran...)
+	}
+	waiting := func() int {
+		for _, pause := range PausedHosts() {
+			return pause.Waiting
+		}
+		return 0
+	}
+
+	require.NoError(t, ScheduleGitCommand(web, request("fetch", true, false)))
+	require.Eventually(t, func() bool { return waiting() == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, ScheduleGitCommand(web, request("stash", false, false)))
+	require.Eventually(t, func() bool { return waiting() == 2 }, 5*time.Second, 10*time.Millisecond, "the stash waits behind the held fetch")
+
+	// The credential retry runs, and as it succeeds the host resumes.
+	require.NoError(t, ScheduleGitCommand(web, request("retry", false, true)))
+	require.Eventually(t, func() bool { return len(executed()) == 3 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "retry", executed()[0])
+	require.ElementsMatch(t, []string{"retry", "fetch", "stash"}, executed())
+	require.Empty(t, PausedHosts())
+}
@@ -0,0 +1,131 @@
+package command
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// IdentityRule requires repositories below Path to commit with a certain
+// identity, e.g. the work email address for everything in ~/work.
+type IdentityRule struct {
+	// Path selects the repositories the same way as RepoEnvRule.Path.
+	Path string `mapstructure:"path"`
+	// Email is the expected user.email. A glob such as *@corp.example only
+	// checks the address; an exact address can also be applied by FixIdentity.
+	Email string `mapstructure:"email"`
+	// Name is the user.name FixIdentity sets along with the email. Optional.
+	Name string `mapstructure:"name"`
+}
+
+// IdentityProblem describes a repository whose user.email does not satisfy
+// its identity rule.
+type IdentityProblem struct {
+	Repository *git.Repository
+	// Email is the user.email git currently uses in the repository, empty
+	// when none is configured.
+	Email string
+	Rule  IdentityRule
+}
+
+// Fixable reports whether FixIdentity can resolve the problem.
+func (p *IdentityProblem) Fixable() bool {
+	return p != nil && !strings.ContainsAny(p.Rule.Email, "*?[")
+}
+
+var (
+	identityRulesMu sync.RWMutex
+	identityRules   []IdentityRule
+)
+
+// SetIdentityRules configures the identity checks. When several rules match
+// a repository, the last one applies.
+func SetIdentityRules(rules []IdentityRule) {
+	normalized := make([]IdentityRule, 0, len(rules))
+	for _, rule := range rules {
+		path := expandRulePath(rule.Path)
+		email := strings.TrimSpace(rule.Email)
+		if path == "" || email == "" {
+			continue
+		}
+		normalized = append(normalized, IdentityRule{Path: path, Email: email, Name: strings.TrimSpace(rule.Name)})
+	}
+	identityRulesMu.Lock()
+	identityRules = normalized
+	identityRulesMu.Unlock()
+}
+
+// HasIdentityRules reports whether identity checks are configured.
+func HasIdentityRules() bool {
+	identityRulesMu.RLock()
+	defer identityRulesMu.RUnlock()
+	return len(identityRules) > 0
+}
+
+func identityRuleFor(dir string) (IdentityRule, bool) {
+	identityRulesMu.RLock()
+	defer identityRulesMu.RUnlock()
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	var found IdentityRule
+	ok := false
+	for _, rule := range identityRules {
+		if repoPathMatches(rule.Path, dir) {
+			found, ok = rule, true
+		}
+	}
+	return found, ok
+}
+
+// CheckIdentity compares the user.email git uses in the repository with its
+// identity rule. It returns nil when no rule applies or the email matches.
+func CheckIdentity(ctx context.Context, r *git.Repository) (*IdentityProblem, error) {
+	if r == nil {
+		return nil, nil
+	}
+	rule, ok := identityRuleFor(r.AbsPath)
+	if !ok {
+		return nil, nil
+	}
+	// A missing user.email makes git config exit with status 1.
+	out, _ := RunWithContextTimeout(ctx, r.AbsPath, "git", []string{"config", "--get", "user.email"}, DefaultGitCommandTimeout)
+	email := strings.TrimSpace(out)
+	if identityMatches(rule.Email, email) {
+		return nil, nil
+	}
+	return &IdentityProblem{Repository: r, Email: email, Rule: rule}, nil
+}
+
+func identityMatches(pattern, email string) bool {
+	if email == "" {
+		return false
+	}
+	pattern = strings.ToLower(pattern)
+	email = strings.ToLower(email)
+	if ok, _ := filepath.Match(pattern, email); ok {
+		return true
+	}
+	return pattern == email
+}
+
+// FixIdentity sets the repository's local user.email, and user.name when the
+// rule has one, to the identity the rule expects.
+func FixIdentity(ctx context.Context, p *IdentityProblem) error {
+	if !p.Fixable() || p.Repository == nil {
+		return nil
+	}
+	dir := p.Repository.AbsPath
+	if _, err := RunWithContextTimeout(ctx, dir, "git", []string{"config", "--local", "user.email", p.Rule.Email}, DefaultGitCommandTimeout); err != nil {
+		return err
+	}
+	if p.Rule.Name != "" {
+		if _, err := RunWithContextTimeout(ctx, dir, "git", []string{"config", "--local", "user.name", p.Rule.Name}, DefaultGitCommandTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
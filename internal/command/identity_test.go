@@ -0,0 +1,51 @@
+package command
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestCheckIdentity_FlagsAndFixesMismatchedEmail(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	basePath := initLocalWorktreeRepoForStateTest(t)
+	_, err := Run(basePath, "git", []string{"config", "user.email", "me@private.example"})
+	require.NoError(t, err)
+	repo := &git.Repository{Name: "work", AbsPath: basePath}
+
+	require.False(t, HasIdentityRules())
+	problem, err := CheckIdentity(context.Background(), repo)
+	require.NoError(t, err)
+	require.Nil(t, problem, "repositories without a rule are not checked")
+
+	SetIdentityRules([]IdentityRule{
+		{Path: filepath.Dir(basePath), Email: "*@corp.example"},
+		{Path: basePath, Email: "me@corp.example", Name: "Me At Work"},
+	})
+	t.Cleanup(func() { SetIdentityRules(nil) })
+
+	problem, err = CheckIdentity(context.Background(), repo)
+	require.NoError(t, err)
+	require.NotNil(t, problem)
+	require.Equal(t, "me@private.example", problem.Email)
+	require.True(t, problem.Fixable())
+
+	require.NoError(t, FixIdentity(context.Background(), problem))
+	out, err := Run(basePath, "git", []string{"config", "user.name"})
+	require.NoError(t, err)
+	require.Equal(t, "Me At Work", strings.TrimSpace(out))
+	problem, err = CheckIdentity(context.Background(), repo)
+	require.NoError(t, err)
+	require.Nil(t, problem)
+}
+
+func TestIdentityProblem_GlobIsNotFixable(t *testing.T) {
+	problem := &IdentityProblem{Rule: IdentityRule{Email: "*@corp.example"}}
+	require.False(t, problem.Fixable())
+	require.True(t, identityMatches("*@corp.example", "Me@Corp.Example"))
+	require.False(t, identityMatches("*@corp.example", ""))
+}
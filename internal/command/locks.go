@@ -0,0 +1,82 @@
+package command
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+var (
+	// staleLockCleanup removes stale lock files without asking and retries
+	// the operation that ran into them.
+	staleLockCleanup atomic.Bool
+
+	// lockedRequests keeps the last request of every repository that failed
+	// on a lock file so it can be retried once the lock is gone.
+	lockedRequestsMu sync.Mutex
+	lockedRequests   = make(map[string]*GitCommandRequest)
+)
+
+// SetStaleLockCleanup configures whether lock files left behind by crashed
+// git processes are removed automatically.
+func SetStaleLockCleanup(enabled bool) {
+	staleLockCleanup.Store(enabled)
+}
+
+// blockedByLock reports whether an operation failed because of a lock file.
+func blockedByLock(err error) bool {
+	return gerr.Classify(err) == gerr.RecoveryRemoveLock
+}
+
+// executeWithLockCleanup runs a queued request. When it fails on a lock file
+// that no git process holds and automatic cleanup is enabled, the lock files
+// are removed and the request runs a second time.
+func executeWithLockCleanup(ctx context.Context, r *git.Repository, req *GitCommandRequest) OperationOutcome {
+	outcome := req.Execute(ctx)
+	if !blockedByLock(outcome.Err) || !staleLockCleanup.Load() || ctx.Err() != nil {
+		return outcome
+	}
+	stale := r.StaleLockFiles()
+	if len(stale) == 0 {
+		return outcome
+	}
+	if err := git.RemoveLockFiles(stale); err != nil {
+		return outcome
+	}
+	return req.Execute(ctx)
+}
+
+// rememberLockedRequest records or forgets the request of a repository
+// depending on whether it ended on a lock file.
+func rememberLockedRequest(r *git.Repository, req *GitCommandRequest, err error) {
+	lockedRequestsMu.Lock()
+	defer lockedRequestsMu.Unlock()
+	if blockedByLock(err) {
+		lockedRequests[r.RepoID] = req
+		return
+	}
+	delete(lockedRequests, r.RepoID)
+}
+
+// RetryLockedOperation schedules the operation that last failed on a lock
+// file in the repository again. It returns false when there is nothing to
+// retry.
+func RetryLockedOperation(r *git.Repository) (bool, error) {
+	if r == nil {
+		return false, nil
+	}
+	lockedRequestsMu.Lock()
+	req, ok := lockedRequests[r.RepoID]
+	delete(lockedRequests, r.RepoID)
+	lockedRequestsMu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	if err := ScheduleGitCommand(r, req); err != nil {
+		return false, err
+	}
+	return true, nil
+}
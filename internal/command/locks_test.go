@@ -0,0 +1,65 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestExecuteWithLockCleanup_RemovesStaleLockAndRetries(t *testing.T) {
+	dir := t.TempDir()
+	lockPath := filepath.Join(dir, ".git", "index.lock")
+	require.NoError(t, os.MkdirAll(filepath.Dir(lockPath), 0o755))
+	require.NoError(t, os.WriteFile(lockPath, nil, 0o644))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(lockPath, old, old))
+
+	repo := &git.Repository{RepoID: "locked", AbsPath: dir}
+	calls := 0
+	req := &GitCommandRequest{Key: "commit", Execute: func(context.Context) OperationOutcome {
+		calls++
+		if _, err := os.Stat(lockPath); err == nil {
+			return OperationOutcome{Err: gerr.ParseGitError("fatal: Unable to create '"+lockPath+"': File exists.", nil)}
+		}
+		return OperationOutcome{}
+	}}
+
+	SetStaleLockCleanup(false)
+	outcome := executeWithLockCleanup(context.Background(), repo, req)
+	require.True(t, blockedByLock(outcome.Err))
+	require.Equal(t, 1, calls)
+	require.FileExists(t, lockPath)
+
+	SetStaleLockCleanup(true)
+	t.Cleanup(func() { SetStaleLockCleanup(false) })
+	calls = 0
+	outcome = executeWithLockCleanup(context.Background(), repo, req)
+	require.NoError(t, outcome.Err)
+	require.Equal(t, 2, calls)
+	require.NoFileExists(t, lockPath)
+}
+
+func TestRetryLockedOperation_OnlyRetriesLockFailures(t *testing.T) {
+	repo := &git.Repository{RepoID: "retry-locked"}
+	req := &GitCommandRequest{Key: "fetch"}
+
+	rememberLockedRequest(repo, req, gerr.ErrDiskFull)
+	retried, err := RetryLockedOperation(repo)
+	require.NoError(t, err)
+	require.False(t, retried)
+
+	rememberLockedRequest(repo, req, gerr.ErrLockFileExists)
+	lockedRequestsMu.Lock()
+	require.Same(t, req, lockedRequests[repo.RepoID])
+	lockedRequestsMu.Unlock()
+	rememberLockedRequest(repo, req, nil)
+	retried, err = RetryLockedOperation(repo)
+	require.NoError(t, err)
+	require.False(t, retried)
+}
@@ -2,6 +2,8 @@ package command
 
 import (
 	"context"
+	"strings"
+	"sync/atomic"
 
 	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
@@ -15,6 +17,55 @@ type MergeOptions struct {
 	Verbose bool
 	// With true do not show a diffstat at the end of the merge.
 	NoStat bool
+	// Create a merge commit even when the merge resolves as a fast-forward.
+	NoFF bool
+	// Squash the merged commits into a single new commit on the current
+	// branch instead of recording a merge.
+	Squash bool
+}
+
+// MergeStyle selects how batch merges record the commits they merge.
+type MergeStyle string
+
+const (
+	// MergeStyleFF fast-forwards when possible, like git merge does.
+	MergeStyleFF MergeStyle = "ff"
+	// MergeStyleNoFF always creates a merge commit.
+	MergeStyleNoFF MergeStyle = "no-ff"
+	// MergeStyleSquash squashes the merged commits into one commit.
+	MergeStyleSquash MergeStyle = "squash"
+)
+
+var mergeStyle atomic.Value
+
+// ParseMergeStyle converts a configuration value into a MergeStyle. The
+// second return value is false when the value is not a known style.
+func ParseMergeStyle(value string) (MergeStyle, bool) {
+	switch MergeStyle(strings.ToLower(strings.TrimSpace(value))) {
+	case MergeStyleFF:
+		return MergeStyleFF, true
+	case MergeStyleNoFF:
+		return MergeStyleNoFF, true
+	case MergeStyleSquash:
+		return MergeStyleSquash, true
+	default:
+		return MergeStyleFF, false
+	}
+}
+
+// SetMergeStyle configures how merges started without options record the
+// merged commits.
+func SetMergeStyle(style MergeStyle) {
+	style, _ = ParseMergeStyle(string(style))
+	mergeStyle.Store(style)
+}
+
+// CurrentMergeStyle returns the configured merge style.
+func CurrentMergeStyle() MergeStyle {
+	if style, ok := mergeStyle.Load().(MergeStyle); ok {
+		return style
+	}
+	return MergeStyleFF
 }
 
 // Merge incorporates changes from the named commits or branches into the
@@ -40,14 +91,25 @@ func MergeWithContext(ctx context.Context, r *git.Repository, options *MergeOpti
 	if options.NoStat {
 		args = append(args, "-n")
 	}
+	if options.NoFF {
+		args = append(args, "--no-ff")
+	}
+	if options.Squash {
+		args = append(args, "--squash")
+	}
+	args = append(args, noVerifyArgs(r)...)
 
 	ref, _ := r.Repo.Head()
 	if out, err := RunWithContext(ctx, r.AbsPath, "git", args); err != nil {
 		return "", gerr.ParseGitError(out, err)
 	}
+	if options.Squash {
+		if err := commitSquash(ctx, r); err != nil {
+			return "", err
+		}
+	}
 
 	newref, _ := r.Repo.Head()
-
 	msg, err := getMergeMessage(r, referenceHash(ref), referenceHash(newref))
 	if err != nil {
 		msg = "couldn't get stat"
@@ -55,6 +117,19 @@ func MergeWithContext(ctx context.Context, r *git.Repository, options *MergeOpti
 	return msg, nil
 }
 
+// commitSquash commits what git merge --squash staged, with the message git
+// prepared. Nothing is staged when the branch was up to date already.
+func commitSquash(ctx context.Context, r *git.Repository) error {
+	if _, err := RunWithContext(ctx, r.AbsPath, "git", []string{"diff", "--cached", "--quiet"}); err == nil {
+		return nil
+	}
+	args := append([]string{"commit", "--no-edit"}, noVerifyArgs(r)...)
+	if out, err := RunWithContext(ctx, r.AbsPath, "git", args); err != nil {
+		return gerr.ParseGitError(out, err)
+	}
+	return nil
+}
+
 func getMergeMessage(r *git.Repository, ref1, ref2 string) (string, error) {
 	var msg string
 	if ref1 == ref2 {
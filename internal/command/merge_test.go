@@ -1,6 +1,8 @@
 package command
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -26,3 +28,31 @@ func TestMerge(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+func TestMergeStyles(t *testing.T) {
+	for _, style := range []MergeStyle{MergeStyleNoFF, MergeStyleSquash} {
+		t.Run(string(style), func(t *testing.T) {
+			th := gittest.InitTestRepositoryFromLocal(t)
+			defer th.CleanUp(t)
+			dir := th.Repository.AbsPath
+			upstream := th.Repository.State.Branch.Upstream.Name
+			_, err := Run(dir, "git", []string{"reset", "--hard", upstream + "~1"})
+			require.NoError(t, err)
+
+			SetMergeStyle(style)
+			t.Cleanup(func() { SetMergeStyle(MergeStyleFF) })
+			require.NoError(t, NewExecutor(th.Repository).RunMerge(context.Background(), nil))
+
+			parents, err := Run(dir, "git", []string{"rev-list", "--parents", "-n", "1", "HEAD"})
+			require.NoError(t, err)
+			subject, err := Run(dir, "git", []string{"log", "-1", "--format=%s"})
+			require.NoError(t, err)
+			if style == MergeStyleNoFF {
+				require.Len(t, strings.Fields(parents), 3, "--no-ff records a merge commit")
+			} else {
+				require.Len(t, strings.Fields(parents), 2)
+				require.True(t, strings.HasPrefix(subject, "Squashed commit"), subject)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package command
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+var (
+	noVerifyMu    sync.RWMutex
+	noVerifyAll   bool
+	noVerifyPaths []string
+	// noVerifyRepos holds the per-repository choices made during the
+	// session; they take precedence over the configuration.
+	noVerifyRepos = make(map[string]bool)
+)
+
+// SetNoVerify configures which repositories skip the pre-commit, commit-msg,
+// pre-merge-commit and pre-push hooks: all of them, or the ones below the
+// given paths or globs.
+func SetNoVerify(all bool, paths []string) {
+	normalized := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if p = expandRulePath(p); p != "" {
+			normalized = append(normalized, p)
+		}
+	}
+	noVerifyMu.Lock()
+	noVerifyAll = all
+	noVerifyPaths = normalized
+	noVerifyMu.Unlock()
+}
+
+// SetRepoNoVerify overrides the configured hook behaviour of a repository
+// for the rest of the session.
+func SetRepoNoVerify(r *git.Repository, enabled bool) {
+	if r == nil || r.AbsPath == "" {
+		return
+	}
+	noVerifyMu.Lock()
+	noVerifyRepos[r.AbsPath] = enabled
+	noVerifyMu.Unlock()
+}
+
+// NoVerify reports whether jobs of the repository run with --no-verify.
+func NoVerify(r *git.Repository) bool {
+	if r == nil || r.AbsPath == "" {
+		return false
+	}
+	noVerifyMu.RLock()
+	defer noVerifyMu.RUnlock()
+	if enabled, ok := noVerifyRepos[r.AbsPath]; ok {
+		return enabled
+	}
+	if noVerifyAll {
+		return true
+	}
+	dir := r.AbsPath
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	for _, pattern := range noVerifyPaths {
+		if repoPathMatches(pattern, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// noVerifyArgs returns the flag that skips the verification hooks when the
+// repository is configured to bypass them.
+func noVerifyArgs(r *git.Repository) []string {
+	if NoVerify(r) {
+		return []string{"--no-verify"}
+	}
+	return nil
+}
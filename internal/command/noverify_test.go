@@ -0,0 +1,48 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestNoVerify_ConfigAndSessionOverride(t *testing.T) {
+	root := t.TempDir()
+	SetNoVerify(false, []string{filepath.Join(root, "work")})
+	t.Cleanup(func() { SetNoVerify(false, nil) })
+
+	work := &git.Repository{AbsPath: filepath.Join(root, "work", "api")}
+	private := &git.Repository{AbsPath: filepath.Join(root, "private")}
+	require.True(t, NoVerify(work))
+	require.False(t, NoVerify(private))
+
+	SetRepoNoVerify(work, false)
+	SetRepoNoVerify(private, true)
+	require.False(t, NoVerify(work))
+	require.True(t, NoVerify(private))
+
+	SetNoVerify(true, nil)
+	require.True(t, NoVerify(&git.Repository{AbsPath: filepath.Join(root, "other")}))
+	require.False(t, NoVerify(work))
+}
+
+func TestCommitWithContext_SkipsHooksWithNoVerify(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	hook := filepath.Join(repoPath, ".git", "hooks", "pre-commit")
+	require.NoError(t, os.MkdirAll(filepath.Dir(hook), 0o755))
+	require.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\nexit 1\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("changed"), 0o644))
+
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	_, err = CommitWithContext(context.Background(), repo, &CommitOptions{Message: "blocked"})
+	require.Error(t, err)
+
+	SetRepoNoVerify(repo, true)
+	_, err = CommitWithContext(context.Background(), repo, &CommitOptions{Message: "bypassed"})
+	require.NoError(t, err)
+}
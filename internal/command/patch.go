@@ -0,0 +1,133 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// clipboardCommands read the clipboard on macOS, Wayland, X11 and Windows;
+// the first one installed is used.
+var clipboardCommands = [][]string{
+	{"pbpaste"},
+	{"wl-paste", "--no-newline"},
+	{"xclip", "-out", "-selection", "clipboard"},
+	{"xsel", "--output", "--clipboard"},
+	{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"},
+}
+
+// ReadClipboard returns the text on the clipboard.
+func ReadClipboard(ctx context.Context) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for _, args := range clipboardCommands {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		out, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", args[0], err)
+		}
+		return string(out), nil
+	}
+	return "", errors.New("no clipboard tool found (pbpaste, wl-paste, xclip or xsel)")
+}
+
+// PatchFile returns the absolute path of the patch in path, "~" expanded.
+// An empty path takes the patch from the clipboard, written to a temporary
+// file the returned cleanup removes.
+func PatchFile(ctx context.Context, path string) (string, func(), error) {
+	path = strings.TrimSpace(path)
+	if path != "" {
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, rest)
+			}
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return "", nil, err
+		}
+		return abs, func() {}, nil
+	}
+
+	text, err := ReadClipboard(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", nil, errors.New("the clipboard is empty")
+	}
+	if !strings.HasSuffix(text, "\n") {
+		// git apply rejects a patch whose last line is cut off.
+		text += "\n"
+	}
+	file, err := os.CreateTemp("", "gitbatch-*.patch")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { _ = os.Remove(file.Name()) }
+	_, err = file.WriteString(text)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return file.Name(), cleanup, nil
+}
+
+// PatchResult is the outcome of applying a patch in a repository.
+type PatchResult struct {
+	Repository *git.Repository
+	// Files is the number of files the patch changed.
+	Files int
+	Err   error
+}
+
+// ApplyPatch applies the patch in file to the working tree of r with git
+// apply, which changes nothing when a single hunk does not apply, and
+// records it in the audit log.
+func ApplyPatch(ctx context.Context, r *git.Repository, file string) *PatchResult {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	result := &PatchResult{Repository: r}
+	out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"apply", "--numstat", file})
+	if err != nil {
+		result.Err = patchError(out, err)
+		return result
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) != "" {
+			result.Files++
+		}
+	}
+	if out, err := RunRecorded(r, OperationApply, []string{"apply", file}); err != nil {
+		result.Err = patchError(out, err)
+	}
+	return result
+}
+
+// patchError keeps the first line git apply complained with, e.g. "error:
+// patch failed: main.go:12".
+func patchError(out string, err error) error {
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "error: ") {
+			return errors.New(strings.TrimPrefix(line, "error: "))
+		}
+	}
+	return gerr.ParseGitError(out, err)
+}
@@ -0,0 +1,41 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestApplyPatch_ReportsWhetherItApplied(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("patched"), 0o644))
+	patch, err := Run(repoPath, "git", []string{"diff"})
+	require.NoError(t, err)
+	_, err = Run(repoPath, "git", []string{"checkout", "--", "README.md"})
+	require.NoError(t, err)
+	patchPath := filepath.Join(t.TempDir(), "change.patch")
+	require.NoError(t, os.WriteFile(patchPath, []byte(patch+"\n"), 0o644))
+
+	_, _, err = PatchFile(context.Background(), filepath.Join(t.TempDir(), "missing.patch"))
+	require.Error(t, err)
+	file, cleanup, err := PatchFile(context.Background(), patchPath)
+	require.NoError(t, err)
+	defer cleanup()
+	require.Equal(t, patchPath, file)
+
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	result := ApplyPatch(context.Background(), repo, file)
+	require.NoError(t, result.Err)
+	require.Equal(t, 1, result.Files)
+	content, err := os.ReadFile(filepath.Join(repoPath, "README.md"))
+	require.NoError(t, err)
+	require.Equal(t, "patched", string(content))
+
+	result = ApplyPatch(context.Background(), repo, file)
+	require.EqualError(t, result.Err, "patch failed: README.md:1")
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+var (
+	pinnedMu    sync.RWMutex
+	pinnedPaths []string
+	// pinnedRepos holds the repositories pinned or unpinned during the
+	// session; they take precedence over the configuration.
+	pinnedRepos = make(map[string]bool)
+)
+
+// SetPinned configures the pinned repositories: the ones below the given
+// paths or globs. Pinned repositories are only touched manually; tag all,
+// the startup fetch and the agent leave them out.
+func SetPinned(paths []string) {
+	normalized := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if p = expandRulePath(p); p != "" {
+			normalized = append(normalized, p)
+		}
+	}
+	pinnedMu.Lock()
+	pinnedPaths = normalized
+	pinnedMu.Unlock()
+}
+
+// SetRepoPinned pins or unpins a repository for the rest of the session.
+func SetRepoPinned(r *git.Repository, pinned bool) {
+	if r == nil || r.AbsPath == "" {
+		return
+	}
+	pinnedMu.Lock()
+	pinnedRepos[r.AbsPath] = pinned
+	pinnedMu.Unlock()
+}
+
+// Pinned reports whether the repository is pinned.
+func Pinned(r *git.Repository) bool {
+	if r == nil || r.AbsPath == "" {
+		return false
+	}
+	pinnedMu.RLock()
+	defer pinnedMu.RUnlock()
+	if pinned, ok := pinnedRepos[r.AbsPath]; ok {
+		return pinned
+	}
+	dir := r.AbsPath
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	for _, pattern := range pinnedPaths {
+		if repoPathMatches(pattern, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestPinned_ConfigAndSessionOverride(t *testing.T) {
+	root := t.TempDir()
+	SetPinned([]string{filepath.Join(root, "prod", "*")})
+	t.Cleanup(func() { SetPinned(nil) })
+
+	config := &git.Repository{AbsPath: filepath.Join(root, "prod", "config")}
+	app := &git.Repository{AbsPath: filepath.Join(root, "app")}
+	require.True(t, Pinned(config))
+	require.False(t, Pinned(app))
+
+	SetRepoPinned(config, false)
+	SetRepoPinned(app, true)
+	t.Cleanup(func() {
+		pinnedMu.Lock()
+		pinnedRepos = make(map[string]bool)
+		pinnedMu.Unlock()
+	})
+	require.False(t, Pinned(config))
+	require.True(t, Pinned(app))
+}
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// A failed initial fetch waits probeRetryDelay plus up to probeRetryJitter
+// before it runs again, so repositories on the same host do not all retry
+// at the same moment.
+var (
+	probeRetryDelay  = 2 * time.Second
+	probeRetryJitter = 3 * time.Second
+)
+
+// probeSettled holds the ids of the repositories whose initial fetch is
+// done: it succeeded, or it was retried already. Later state probes, e.g.
+// after a refresh, are not retried.
+var probeSettled sync.Map
+
+// operationProbeRetry asks the state queue to run the initial fetch of a
+// repository again. Its message is the one the repository shows while it
+// waits for the retry.
+const operationProbeRetry OperationType = "probe-retry"
+
+// retryStateProbe schedules the initial fetch of r once more when it failed
+// with a transient error. DNS and network errors are common at startup while
+// a VPN is still connecting; authentication failures are not retried. It
+// reports whether a retry is scheduled.
+func retryStateProbe(r *git.Repository, outcome OperationOutcome) bool {
+	if !transientProbeError(outcome.Err) {
+		return false
+	}
+	if _, settled := probeSettled.LoadOrStore(r.RepoID, struct{}{}); settled {
+		return false
+	}
+	delay := probeRetryDelay
+	if probeRetryJitter > 0 {
+		delay += rand.N(probeRetryJitter)
+	}
+	message := fmt.Sprintf("fetch failed, retrying in %ds", int(delay.Round(time.Second)/time.Second))
+	setRepositoryStatus(r, git.Pending, message)
+	ctx := scheduleContext()
+	time.AfterFunc(delay, func() {
+		if ctx.Err() != nil {
+			ScheduleStateEvaluation(r, outcome)
+			return
+		}
+		ScheduleStateEvaluation(r, OperationOutcome{Operation: operationProbeRetry, Message: message})
+	})
+	return true
+}
+
+// handleProbeRetry runs the initial fetch of r again unless another
+// operation took over the repository while it waited.
+func handleProbeRetry(r *git.Repository, outcome OperationOutcome) {
+	if r.WorkStatus() != git.Pending || r.State.Message() != outcome.Message {
+		return
+	}
+	handleStateProbe(r)
+}
+
+// settleStateProbe records that the initial fetch of r succeeded, so a later
+// failure is not retried.
+func settleStateProbe(r *git.Repository) {
+	probeSettled.Store(r.RepoID, struct{}{})
+}
+
+func transientProbeError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return gerr.Classify(err) == gerr.RecoveryRetry || errors.Is(err, context.DeadlineExceeded)
+}
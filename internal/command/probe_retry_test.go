@@ -0,0 +1,52 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestRetryStateProbeRetriesTransientFailuresOnce(t *testing.T) {
+	delay, jitter := probeRetryDelay, probeRetryJitter
+	probeRetryDelay, probeRetryJitter = 10*time.Millisecond, 10*time.Millisecond
+	t.Cleanup(func() { probeRetryDelay, probeRetryJitter = delay, jitter })
+
+	basePath := initLocalWorktreeRepoForStateTest(t)
+	_, err := Run(basePath, "git", []string{"remote", "remove", "origin"})
+	require.NoError(t, err)
+	repo, err := git.InitializeRepo(basePath)
+	require.NoError(t, err)
+	t.Cleanup(func() { probeSettled.Delete(repo.RepoID) })
+
+	outcome := OperationOutcome{Operation: OperationStateProbe, Err: gerr.ErrDNSError}
+	require.True(t, retryStateProbe(repo, outcome))
+	require.Equal(t, git.Pending, repo.WorkStatus())
+	require.True(t, strings.HasPrefix(repo.State.Message(), "fetch failed, retrying in "))
+
+	// The retry probes the repository again, which has no remote by now.
+	require.Eventually(t, func() bool {
+		return repo.State.Message() == "no remote configured"
+	}, 2*time.Second, 10*time.Millisecond)
+	require.False(t, retryStateProbe(repo, outcome), "a failed retry is final")
+
+	probeSettled.Delete(repo.RepoID)
+	require.False(t, retryStateProbe(repo, OperationOutcome{Operation: OperationStateProbe, Err: gerr.ErrAuthenticationRequired}))
+
+	settleStateProbe(repo)
+	require.False(t, retryStateProbe(repo, outcome), "only the initial fetch is retried")
+}
+
+func TestProbeRetryLeavesRepositoriesAnotherOperationTookOver(t *testing.T) {
+	repo, err := git.InitializeRepo(initLocalWorktreeRepoForStateTest(t))
+	require.NoError(t, err)
+	repo.SetWorkStatus(git.Working)
+	repo.State.SetMessage("pulling")
+
+	handleProbeRetry(repo, OperationOutcome{Operation: operationProbeRetry, Message: "fetch failed, retrying in 3s"})
+	require.Equal(t, git.Working, repo.WorkStatus())
+	require.Equal(t, "pulling", repo.State.Message())
+}
@@ -0,0 +1,98 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// progressInterval limits how often the progress of an operation updates its
+// repository, so fast transfers do not redraw the interface for every line.
+const progressInterval = 200 * time.Millisecond
+
+// progressPhase splits a progress meter like "Receiving objects:  42% (420/1000)"
+// into its phase and, if there is one, its percentage.
+var progressPhase = regexp.MustCompile(`^(?:remote: )?([A-Z][a-z]+(?: [a-z]+)*): +(?:([0-9]+%))?`)
+
+// progressKey carries the function the commands of a queued operation report
+// their progress meters to.
+type progressKey struct{}
+
+// withProgress returns a context whose commands pass every progress meter
+// they print to report.
+func withProgress(ctx context.Context, report func(line string)) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// progressReporter returns the progress function of ctx, or nil.
+func progressReporter(ctx context.Context) func(string) {
+	report, _ := ctx.Value(progressKey{}).(func(string))
+	return report
+}
+
+// progressScanner picks the progress meters out of command output. Git
+// redraws them with a carriage return, so output is split at both line
+// endings and the unfinished rest is kept for the next write.
+type progressScanner struct {
+	report  func(string)
+	partial []byte
+}
+
+func (s *progressScanner) write(p []byte) {
+	s.partial = append(s.partial, p...)
+	for {
+		i := bytes.IndexAny(s.partial, "\r\n")
+		if i < 0 {
+			return
+		}
+		line := string(s.partial[:i])
+		s.partial = s.partial[i+1:]
+		if progressLine.MatchString(line) {
+			s.report(line)
+		}
+	}
+}
+
+// progressMessage turns a progress meter into the message of a running
+// operation, e.g. "fetching... 42% receiving objects".
+func progressMessage(base, line string) string {
+	match := progressPhase.FindStringSubmatch(line)
+	if match == nil {
+		return base
+	}
+	phase := strings.ToLower(match[1])
+	if match[2] == "" {
+		return base + " " + phase
+	}
+	return base + " " + match[2] + " " + phase
+}
+
+// repositoryProgress returns the progress function of an operation in r. It
+// shows the latest meter as the message of r, at most every
+// progressInterval, until stop is closed.
+func repositoryProgress(r *git.Repository, operation OperationType, stop <-chan struct{}) func(string) {
+	var (
+		mu   sync.Mutex
+		last time.Time
+	)
+	base := operationMessage(operation)
+	return func(line string) {
+		mu.Lock()
+		defer mu.Unlock()
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		if time.Since(last) < progressInterval {
+			return
+		}
+		last = time.Now()
+		setRepositoryStatus(r, git.Working, progressMessage(base, line))
+	}
+}
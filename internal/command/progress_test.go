@@ -0,0 +1,33 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressScannerSplitsRedrawnMeters(t *testing.T) {
+	var lines []string
+	s := &progressScanner{report: func(line string) { lines = append(lines, line) }}
+	s.write([]byte("From example.com:repo\nReceiving obj"))
+	s.write([]byte("ects:  42% (42/100)\rReceiving objects: 100% (100/100), done.\n"))
+	s.write([]byte("remote: Counting objects: 12"))
+	require.Equal(t, []string{"Receiving objects:  42% (42/100)", "Receiving objects: 100% (100/100), done."}, lines)
+}
+
+func TestProgressMessage(t *testing.T) {
+	require.Equal(t, "fetching... 42% receiving objects", progressMessage("fetching...", "Receiving objects:  42% (42/100), 1.20 MiB | 2.00 MiB/s"))
+	require.Equal(t, "pushing... 7% writing objects", progressMessage("pushing...", "Writing objects:   7% (1/14)"))
+	require.Equal(t, "fetching... enumerating objects", progressMessage("fetching...", "remote: Enumerating objects: 5, done."))
+	require.Equal(t, "fetching...", progressMessage("fetching...", "From example.com:repo"))
+}
+
+func TestRunReportsProgressMetersOfTheContext(t *testing.T) {
+	var lines []string
+	ctx := withProgress(context.Background(), func(line string) { lines = append(lines, line) })
+	out, err := RunWithContext(ctx, "", "sh", []string{"-c", `printf 'Resolving deltas:  50%% (1/2)\rResolving deltas: 100%% (2/2), done.\nok\n' >&2`})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Resolving deltas:  50% (1/2)", "Resolving deltas: 100% (2/2), done."}, lines)
+	require.Equal(t, "ok", stripProgress(out))
+}
@@ -0,0 +1,93 @@
+package command
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// defaultProtectedBranches are protected when a rule names no branches.
+var defaultProtectedBranches = []string{"main", "master"}
+
+// BranchProtectionRule protects branches of a group of repositories that only
+// take changes through pull requests from local commits and pushes.
+type BranchProtectionRule struct {
+	// Path selects the repositories the same way as RepoEnvRule.Path.
+	Path string `mapstructure:"path"`
+	// Branches are the protected branch names; globs such as release/* are
+	// allowed. Without any, main and master are protected.
+	Branches []string `mapstructure:"branches"`
+	// Block refuses batch commits and pushes on a protected branch instead
+	// of warning about them.
+	Block bool `mapstructure:"block"`
+}
+
+var (
+	branchProtectionMu sync.RWMutex
+	branchProtection   []BranchProtectionRule
+)
+
+// SetBranchProtection configures the protected branches. When several rules
+// match a repository, the last one applies.
+func SetBranchProtection(rules []BranchProtectionRule) {
+	normalized := make([]BranchProtectionRule, 0, len(rules))
+	for _, rule := range rules {
+		dir := expandRulePath(rule.Path)
+		if dir == "" {
+			continue
+		}
+		var branches []string
+		for _, branch := range rule.Branches {
+			if branch = strings.TrimSpace(branch); branch != "" {
+				branches = append(branches, branch)
+			}
+		}
+		if len(branches) == 0 {
+			branches = defaultProtectedBranches
+		}
+		normalized = append(normalized, BranchProtectionRule{Path: dir, Branches: branches, Block: rule.Block})
+	}
+	branchProtectionMu.Lock()
+	branchProtection = normalized
+	branchProtectionMu.Unlock()
+}
+
+// ProtectedBranch returns the rule that protects the branch checked out in r,
+// if any.
+func ProtectedBranch(r *git.Repository) (BranchProtectionRule, bool) {
+	if r == nil || r.State == nil || r.State.Branch == nil {
+		return BranchProtectionRule{}, false
+	}
+	dir := r.AbsPath
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	branchProtectionMu.RLock()
+	defer branchProtectionMu.RUnlock()
+	var found BranchProtectionRule
+	ok := false
+	for _, rule := range branchProtection {
+		if !repoPathMatches(rule.Path, dir) {
+			continue
+		}
+		// A later rule for the repository decides, even if it protects
+		// other branches.
+		found, ok = rule, branchMatches(rule.Branches, r.State.Branch.Name)
+	}
+	return found, ok
+}
+
+func branchMatches(patterns []string, branch string) bool {
+	for _, pattern := range patterns {
+		if pattern == branch {
+			return true
+		}
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package command
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestProtectedBranch_LastMatchingRuleDecides(t *testing.T) {
+	root := t.TempDir()
+	work := filepath.Join(root, "work")
+	repo := func(dir, branch string) *git.Repository {
+		return &git.Repository{AbsPath: dir, State: &git.RepositoryState{Branch: &git.Branch{Name: branch}}}
+	}
+
+	_, ok := ProtectedBranch(repo(filepath.Join(work, "api"), "main"))
+	require.False(t, ok, "nothing is protected without rules")
+
+	SetBranchProtection([]BranchProtectionRule{
+		{Path: root},
+		{Path: work, Branches: []string{"main", "release/*"}, Block: true},
+		{Path: filepath.Join(work, "sandbox"), Branches: []string{"stable"}},
+	})
+	t.Cleanup(func() { SetBranchProtection(nil) })
+
+	rule, ok := ProtectedBranch(repo(filepath.Join(root, "tool"), "master"))
+	require.True(t, ok, "main and master are protected by default")
+	require.False(t, rule.Block)
+
+	rule, ok = ProtectedBranch(repo(filepath.Join(work, "api"), "release/1.2"))
+	require.True(t, ok)
+	require.True(t, rule.Block)
+	_, ok = ProtectedBranch(repo(filepath.Join(work, "api"), "master"))
+	require.False(t, ok, "the rule for work names its branches")
+	_, ok = ProtectedBranch(repo(filepath.Join(work, "api"), "feature/x"))
+	require.False(t, ok)
+
+	_, ok = ProtectedBranch(repo(filepath.Join(work, "sandbox"), "main"))
+	require.False(t, ok, "a later rule for the repository decides")
+	_, ok = ProtectedBranch(&git.Repository{AbsPath: work, State: &git.RepositoryState{}})
+	require.False(t, ok, "a detached HEAD is not protected")
+}
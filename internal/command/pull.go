@@ -2,12 +2,23 @@ package command
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/go-git/go-git/v5/plumbing"
 	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
 )
 
+// pruneOnPull and tagsOnPull add --prune and --tags to every pull.
+var pruneOnPull, tagsOnPull atomic.Bool
+
+// SetPullDefaults configures whether pulls prune remote-tracking references
+// that no longer exist on the remote and fetch all tags.
+func SetPullDefaults(prune, tags bool) {
+	pruneOnPull.Store(prune)
+	tagsOnPull.Store(tags)
+}
+
 // PullOptions defines the rules for pull operation
 type PullOptions struct {
 	// Name of the remote to fetch from. Defaults to origin.
@@ -27,6 +38,14 @@ type PullOptions struct {
 	FFOnly bool
 	// Rebase performs the pull using rebase instead of merge.
 	Rebase bool
+	// Autostash stashes local changes before the pull and re-applies them
+	// once it has finished.
+	Autostash bool
+	// Prune removes remote-tracking references that no longer exist on the
+	// remote before the merge.
+	Prune bool
+	// Tags fetches all tags from the remote as well.
+	Tags bool
 }
 
 // Pull incorporates changes from a remote repository into the current branch.
@@ -47,7 +66,7 @@ func PullWithContext(ctx context.Context, r *git.Repository, o *PullOptions) (st
 
 func pullWithGit(ctx context.Context, r *git.Repository, options *PullOptions) (string, error) {
 	args := make([]string, 0)
-	args = append(args, "pull")
+	args = append(args, "pull", "--progress")
 	// parse options to command line arguments
 	if options.FFOnly {
 		args = append(args, "--ff-only")
@@ -55,21 +74,35 @@ func pullWithGit(ctx context.Context, r *git.Repository, options *PullOptions) (
 	if options.Rebase {
 		args = append(args, "--rebase")
 	}
+	if options.Autostash {
+		args = append(args, "--autostash")
+	}
+	if options.Prune || pruneOnPull.Load() {
+		args = append(args, "--prune")
+	}
+	if options.Tags || tagsOnPull.Load() {
+		args = append(args, "--tags")
+	}
 	if options.Force {
 		args = append(args, "-f")
 	}
+	args = append(args, noVerifyArgs(r)...)
 	if len(options.RemoteName) > 0 {
 		args = append(args, options.RemoteName)
 	}
 	if len(options.ReferenceName) > 0 {
 		args = append(args, options.ReferenceName)
 	}
+	creds, url := resolveCredentials(r, options.RemoteName, options.Credentials)
+	credArgs, credEnv := credentialArgs(creds)
+	args = append(credArgs, args...)
 	ref, _ := r.Repo.Head()
-	if out, err := RunWithContext(ctx, r.AbsPath, "git", args); err != nil {
-		return "", gerr.ParseGitError(out, err)
+	out, err := runWithEnv(ctx, r.AbsPath, "git", args, credEnv, 0)
+	if err != nil {
+		return "", gerr.ParseGitError(stripProgress(out), err)
 	}
+	credentialsSucceeded(url, options.Credentials, out)
 	newref, _ := r.Repo.Head()
-
 	msg, err := getMergeMessage(r, referenceHash(ref), referenceHash(newref))
 	if err != nil {
 		msg = "couldn't get stat"
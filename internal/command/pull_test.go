@@ -2,6 +2,7 @@ package command
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -36,3 +37,37 @@ func TestPullWithGit(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+func TestPullPrunesAndFetchesTags(t *testing.T) {
+	th := gittest.InitTestRepositoryFromLocal(t)
+	defer th.CleanUp(t)
+	dir := th.Repository.AbsPath
+	remote := t.TempDir()
+	_, err := Run(dir, "git", []string{"clone", "--bare", "-q", dir, remote})
+	require.NoError(t, err)
+	_, err = Run(dir, "git", []string{"remote", "set-url", "origin", remote})
+	require.NoError(t, err)
+	_, err = Run(remote, "git", []string{"branch", "stale"})
+	require.NoError(t, err)
+	_, err = Run(dir, "git", []string{"fetch", "-q", "origin"})
+	require.NoError(t, err)
+	_, err = Run(remote, "git", []string{"branch", "-D", "stale"})
+	require.NoError(t, err)
+	// A tag on a commit no branch reaches is not followed automatically.
+	tree, err := Run(remote, "git", []string{"rev-parse", "HEAD^{tree}"})
+	require.NoError(t, err)
+	orphan, err := Run(remote, "git", []string{"commit-tree", "-m", "orphan", strings.TrimSpace(tree)})
+	require.NoError(t, err)
+	_, err = Run(remote, "git", []string{"tag", "pulled-tag", strings.TrimSpace(orphan)})
+	require.NoError(t, err)
+
+	SetPullDefaults(true, false)
+	t.Cleanup(func() { SetPullDefaults(false, false) })
+	_, err = pullWithGit(context.Background(), th.Repository, &PullOptions{RemoteName: "origin", Tags: true})
+	require.NoError(t, err)
+
+	refs, err := Run(dir, "git", []string{"for-each-ref", "--format=%(refname)"})
+	require.NoError(t, err)
+	require.NotContains(t, refs, "refs/remotes/origin/stale", "pull_prune removes the stale remote-tracking branch")
+	require.Contains(t, refs, "refs/tags/pulled-tag")
+}
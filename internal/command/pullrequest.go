@@ -0,0 +1,163 @@
+package command
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// Forge is a code hosting service gitbatch knows the pull request pages of.
+type Forge string
+
+const (
+	// ForgeGitHub opens pull requests on GitHub.
+	ForgeGitHub Forge = "github"
+	// ForgeGitLab opens merge requests on GitLab.
+	ForgeGitLab Forge = "gitlab"
+	// ForgeBitbucket opens pull requests on Bitbucket.
+	ForgeBitbucket Forge = "bitbucket"
+)
+
+// ParseForge returns the forge named s.
+func ParseForge(s string) (Forge, bool) {
+	switch forge := Forge(strings.ToLower(strings.TrimSpace(s))); forge {
+	case ForgeGitHub, ForgeGitLab, ForgeBitbucket:
+		return forge, true
+	}
+	return "", false
+}
+
+// publicForges are the hosts every installation knows.
+var publicForges = map[string]Forge{
+	"github.com":    ForgeGitHub,
+	"gitlab.com":    ForgeGitLab,
+	"bitbucket.org": ForgeBitbucket,
+}
+
+var (
+	pullRequestMu    sync.RWMutex
+	pullRequestLinks = true
+	forgeHosts       map[string]Forge
+)
+
+// SetPullRequestLinks configures whether pushes of new branches offer the
+// page to open a pull request, and the self-hosted forges besides GitHub,
+// GitLab and Bitbucket by host name. Hosts with an unknown forge are left
+// out.
+func SetPullRequestLinks(enabled bool, hosts map[string]string) {
+	forges := make(map[string]Forge, len(hosts))
+	for host, name := range hosts {
+		if forge, ok := ParseForge(name); ok {
+			forges[strings.ToLower(strings.TrimSpace(host))] = forge
+		}
+	}
+	pullRequestMu.Lock()
+	pullRequestLinks = enabled
+	forgeHosts = forges
+	pullRequestMu.Unlock()
+}
+
+// forgeOf returns the forge host runs, configured hosts first.
+func forgeOf(host string) (Forge, bool) {
+	host = strings.ToLower(host)
+	pullRequestMu.RLock()
+	forge, ok := forgeHosts[host]
+	pullRequestMu.RUnlock()
+	if ok {
+		return forge, true
+	}
+	forge, ok = publicForges[host]
+	return forge, ok
+}
+
+// PullRequestURL returns the page of the host behind remoteURL that opens a
+// pull request, a merge request on GitLab, for branch. It is empty for hosts
+// that are neither GitHub, GitLab or Bitbucket nor configured as a forge,
+// and for local remotes.
+func PullRequestURL(remoteURL, branch string) string {
+	return pullRequestURL(git.RemoteHost(remoteURL), remoteRepositoryPath(remoteURL), branch)
+}
+
+func pullRequestURL(host, path, branch string) string {
+	if host == "" || path == "" || branch == "" {
+		return ""
+	}
+	forge, ok := forgeOf(host)
+	if !ok {
+		return ""
+	}
+	base := "https://" + host + "/" + path
+	switch forge {
+	case ForgeGitHub:
+		return base + "/pull/new/" + branch
+	case ForgeGitLab:
+		return base + "/-/merge_requests/new?merge_request%5Bsource_branch%5D=" + url.QueryEscape(branch)
+	case ForgeBitbucket:
+		return base + "/pull-requests/new?source=" + url.QueryEscape(branch)
+	}
+	return ""
+}
+
+// remoteRepositoryPath returns the path of the repository in a remote URL,
+// e.g. org/repo for git@github.com:org/repo.git.
+func remoteRepositoryPath(remoteURL string) string {
+	raw := strings.TrimSpace(remoteURL)
+	var p string
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return ""
+		}
+		p = u.Path
+	} else if colon := strings.Index(raw, ":"); colon > 0 && !strings.HasPrefix(raw, "/") {
+		p = raw[colon+1:]
+	} else {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Trim(p, "/"), ".git")
+}
+
+// newBranchPullRequestURL returns the pull request page for ref when pushing
+// it creates the branch on remote: there is no remote-tracking ref for it yet
+// and it is no protected branch, which takes no pull requests of its own.
+// The remote URL is taken as git uses it, with url.<base>.insteadOf applied
+// and ssh host aliases resolved.
+func newBranchPullRequestURL(ctx context.Context, r *git.Repository, remote, ref string) string {
+	pullRequestMu.RLock()
+	enabled := pullRequestLinks
+	pullRequestMu.RUnlock()
+	if !enabled || remote == "" || ref == "" || branchMatches(ProtectedBranchPatterns(r.AbsPath), ref) {
+		return ""
+	}
+	if _, err := RunWithContext(ctx, r.AbsPath, "git", []string{"rev-parse", "--verify", "--quiet", "refs/remotes/" + remote + "/" + ref}); err == nil {
+		return ""
+	}
+	u := remoteURL(r, remote)
+	if out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"ls-remote", "--get-url", remote}); err == nil && strings.TrimSpace(out) != "" {
+		u = strings.TrimSpace(out)
+	}
+	host := git.RemoteHost(u)
+	if git.IsSSHURL(u) {
+		host = sshHostName(ctx, r, host)
+	}
+	return pullRequestURL(host, remoteRepositoryPath(u), ref)
+}
+
+// sshHostName resolves host with the ssh command git runs for r, so that
+// an alias from the ssh configuration names the real host. It returns host
+// when ssh cannot tell.
+func sshHostName(ctx context.Context, r *git.Repository, host string) string {
+	out, err := RunWithContext(ctx, r.AbsPath, "sh", []string{"-c", `eval "$GIT_SSH_COMMAND" '-G "$1"'`, "sh", host})
+	if err != nil {
+		return host
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(line), "hostname "); ok && name != "" {
+			return name
+		}
+	}
+	return host
+}
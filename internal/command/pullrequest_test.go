@@ -0,0 +1,83 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/gittest/testkit"
+)
+
+func TestPullRequestURL(t *testing.T) {
+	tests := []struct {
+		remote, branch, want string
+	}{
+		{"git@github.com:org/api.git", "feature/login", "https://github.com/org/api/pull/new/feature/login"},
+		{"https://user@github.example.com/org/api", "fix", ""},
+		{"https://user@git.example.com/org/api", "fix", "https://git.example.com/org/api/pull/new/fix"},
+		{"ssh://git@gitlab.com:22/group/sub/api.git", "feature/login", "https://gitlab.com/group/sub/api/-/merge_requests/new?merge_request%5Bsource_branch%5D=feature%2Flogin"},
+		{"https://bitbucket.org/team/api.git", "fix", "https://bitbucket.org/team/api/pull-requests/new?source=fix"},
+		{"https://code.example.com/org/api.git", "fix", "https://code.example.com/org/api/-/merge_requests/new?merge_request%5Bsource_branch%5D=fix"},
+		{"https://other.example.com/org/api.git", "fix", ""},
+		{"/srv/git/api.git", "fix", ""},
+		{"git@github.com:org/api.git", "", ""},
+	}
+	SetPullRequestLinks(true, map[string]string{"git.example.com": "github", "Code.example.com": "GitLab", "other.example.com": "gitea"})
+	t.Cleanup(func() { SetPullRequestLinks(true, nil) })
+	for _, test := range tests {
+		require.Equal(t, test.want, PullRequestURL(test.remote, test.branch), test.remote)
+	}
+}
+
+func TestPushOfANewBranchOffersAPullRequest(t *testing.T) {
+	remote := testkit.NewRemote(t)
+	clone := remote.Clone(t, "api")
+	testkit.Git(t, clone, "remote", "set-url", "origin", "git@github.com:org/api.git")
+	testkit.Git(t, clone, "remote", "set-url", "--push", "origin", remote.Path)
+	r, err := git.InitializeRepo(clone)
+	require.NoError(t, err)
+
+	_, pullRequest, err := push(context.Background(), r, nil)
+	require.NoError(t, err)
+	require.Empty(t, pullRequest, "main is protected")
+
+	testkit.Git(t, clone, "checkout", "-b", "feature")
+	_, pullRequest, err = push(context.Background(), r, &PushOptions{ReferenceName: "feature"})
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/org/api/pull/new/feature", pullRequest)
+
+	testkit.Git(t, clone, "fetch", remote.Path, "feature:refs/remotes/origin/feature")
+	testkit.Git(t, clone, "commit", "--allow-empty", "-m", "more")
+	_, pullRequest, err = push(context.Background(), r, &PushOptions{ReferenceName: "feature"})
+	require.NoError(t, err)
+	require.Empty(t, pullRequest, "the branch exists on the remote already")
+}
+
+func TestPullRequestLinksResolveTheRemoteAsGitDoes(t *testing.T) {
+	remote := testkit.NewRemote(t)
+	clone := remote.Clone(t, "api")
+	testkit.Git(t, clone, "remote", "set-url", "origin", "work:org/api.git")
+	testkit.Git(t, clone, "remote", "set-url", "--push", "origin", remote.Path)
+	testkit.Git(t, clone, "config", "url.gh-work:.insteadOf", "work:")
+	sshConfig := filepath.Join(t.TempDir(), "ssh_config")
+	require.NoError(t, os.WriteFile(sshConfig, []byte("Host gh-work\n  HostName github.com\n"), 0o644))
+	SetRepoEnv([]RepoEnvRule{{Path: clone, Env: []string{"GIT_SSH_COMMAND=ssh -F " + sshConfig}}})
+	t.Cleanup(func() { SetRepoEnv(nil) })
+	r, err := git.InitializeRepo(clone)
+	require.NoError(t, err)
+
+	testkit.Git(t, clone, "checkout", "-b", "feature")
+	_, pullRequest, err := push(context.Background(), r, &PushOptions{ReferenceName: "feature"})
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/org/api/pull/new/feature", pullRequest)
+
+	SetPullRequestLinks(false, nil)
+	t.Cleanup(func() { SetPullRequestLinks(true, nil) })
+	testkit.Git(t, clone, "checkout", "-b", "other")
+	_, pullRequest, err = push(context.Background(), r, &PushOptions{ReferenceName: "other"})
+	require.NoError(t, err)
+	require.Empty(t, pullRequest, "pull request links are turned off")
+}
@@ -15,6 +15,9 @@ type PushOptions struct {
 	ReferenceName string
 	// Force toggles --force pushes when required.
 	Force bool
+	// Credentials authenticates against https remotes. Without them the
+	// credentials remembered for the remote host are used.
+	Credentials *git.Credentials
 }
 
 func Push(r *git.Repository, options *PushOptions) (string, error) {
@@ -23,6 +26,13 @@ func Push(r *git.Repository, options *PushOptions) (string, error) {
 
 // PushWithContext runs git push and respects context cancellation and deadlines.
 func PushWithContext(ctx context.Context, r *git.Repository, options *PushOptions) (string, error) {
+	msg, _, err := push(ctx, r, options)
+	return msg, err
+}
+
+// push runs git push and, when it created the branch on the remote, returns
+// the page to open a pull request for it as well.
+func push(ctx context.Context, r *git.Repository, options *PushOptions) (string, string, error) {
 	if options == nil {
 		options = &PushOptions{}
 	}
@@ -41,19 +51,25 @@ func PushWithContext(ctx context.Context, r *git.Repository, options *PushOption
 		ref = r.State.Branch.Name
 	}
 
-	args := []string{"push"}
+	args := []string{"push", "--progress"}
 	if options.Force {
 		args = append(args, "--force")
 	}
+	args = append(args, noVerifyArgs(r)...)
 	if remote != "" {
 		args = append(args, remote)
 	}
 	if ref != "" {
 		args = append(args, ref)
 	}
-	out, err := RunWithContext(ctx, r.AbsPath, "git", args)
+	creds, url := resolveCredentials(r, remote, options.Credentials)
+	credArgs, credEnv := credentialArgs(creds)
+	args = append(credArgs, args...)
+	pullRequest := newBranchPullRequestURL(ctx, r, remote, ref)
+	out, err := runWithEnv(ctx, r.AbsPath, "git", args, credEnv, 0)
 	if err != nil {
-		return "", gerr.ParseGitError(out, err)
+		return "", "", gerr.ParseGitError(stripProgress(out), err)
 	}
-	return "push completed", nil
+	credentialsSucceeded(url, options.Credentials, out)
+	return "push completed", pullRequest, nil
 }
@@ -6,7 +6,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
 	"github.com/thorstenhirsch/gitbatch/internal/gittest"
+	"github.com/thorstenhirsch/gitbatch/internal/gittest/testkit"
 )
 
 func TestPush(t *testing.T) {
@@ -43,3 +45,28 @@ func TestPush(t *testing.T) {
 	_, err = Push(th.Repository, opts)
 	require.NoError(t, err)
 }
+
+func TestPushAuthenticatesWithTheCredentials(t *testing.T) {
+	t.Cleanup(func() {
+		sessionCredentialsMu.Lock()
+		sessionCredentials = make(map[string]git.Credentials)
+		sessionCredentialsMu.Unlock()
+	})
+	remote := testkit.NewRemote(t)
+	clone := remote.Clone(t, "api")
+	remote.RequireAuth(t, "alice", "s3cret")
+	// The stub answers no prompt, so only the credentials can log in.
+	askpass := testkit.NewAskpass(t, nil)
+	testkit.Git(t, clone, "remote", "set-url", "origin", remote.URL)
+	repo, err := git.InitializeRepo(clone)
+	require.NoError(t, err)
+
+	testkit.Git(t, clone, "commit", "--allow-empty", "-m", "first")
+	_, err = Push(repo, &PushOptions{Credentials: &git.Credentials{User: "alice", Password: "s3cret"}})
+	require.NoError(t, err)
+
+	testkit.Git(t, clone, "commit", "--allow-empty", "-m", "second")
+	_, err = Push(repo, nil)
+	require.NoError(t, err, "the credentials the server accepted are remembered for the host")
+	require.Empty(t, askpass.Prompts(t))
+}
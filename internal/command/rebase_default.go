@@ -0,0 +1,58 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// RebaseOnDefaultWithContext fetches the remote, fast-forwards the local
+// default branch to the remote's and rebases the current branch onto it. A
+// fork takes its default branch from upstream. A rebase that stops with
+// conflicts is left for the user to resolve and reported as a conflict.
+func RebaseOnDefaultWithContext(ctx context.Context, r *git.Repository) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	remote := repositoryRemoteName(r)
+	if upstream, _, ok := r.ForkRemotes(); ok {
+		remote = upstream.Name
+	}
+	head, detached, err := currentHead(ctx, r)
+	if err != nil {
+		return "", err
+	}
+	if detached {
+		return "", fmt.Errorf("HEAD is detached")
+	}
+
+	if err := fetchRemote(ctx, r, remote); err != nil {
+		return "", err
+	}
+	branch, err := remoteDefaultBranch(ctx, r, remote)
+	if err != nil {
+		return "", err
+	}
+	if err := fastForwardBranch(ctx, r, branch, "refs/remotes/"+remote+"/"+branch); err != nil {
+		return "", err
+	}
+	if head == branch {
+		return fmt.Sprintf("%s updated from %s", branch, remote), nil
+	}
+	if _, err := RunWithContext(ctx, r.AbsPath, "git", []string{"merge-base", "--is-ancestor", "refs/heads/" + branch, "HEAD"}); err == nil {
+		return fmt.Sprintf("%s already based on %s", head, branch), nil
+	}
+
+	args := append([]string{"rebase"}, noVerifyArgs(r)...)
+	args = append(args, "refs/heads/"+branch)
+	out, err := RunWithContext(ctx, r.AbsPath, "git", args)
+	if err != nil {
+		if rebaseInProgress(ctx, r) {
+			return fmt.Sprintf("rebase of %s onto %s stopped with conflicts", head, branch), gerr.ErrConflictAfterMerge
+		}
+		return "", gerr.ParseGitError(out, err)
+	}
+	return fmt.Sprintf("rebased %s onto %s", head, branch), nil
+}
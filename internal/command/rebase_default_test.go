@@ -0,0 +1,42 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestRebaseOnDefaultWithContext(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	_, err := Run(repoPath, "git", []string{"checkout", "-b", "feature"})
+	require.NoError(t, err)
+	commitFile(t, repoPath, "feature.txt", "feature")
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+
+	msg, err := RebaseOnDefaultWithContext(context.Background(), repo)
+	require.NoError(t, err)
+	require.Equal(t, "feature already based on main", msg)
+
+	pushUpstreamChange(t, repoPath, "upstream")
+	msg, err = RebaseOnDefaultWithContext(context.Background(), repo)
+	require.NoError(t, err)
+	require.Equal(t, "rebased feature onto main", msg)
+	require.Equal(t, "feature", currentBranch(t, repoPath))
+	_, err = Run(repoPath, "git", []string{"merge-base", "--is-ancestor", "origin/main", "main"})
+	require.NoError(t, err, "the default branch is updated")
+	content, err := os.ReadFile(filepath.Join(repoPath, "README.md"))
+	require.NoError(t, err)
+	require.Equal(t, "upstream", string(content))
+
+	commitFile(t, repoPath, "README.md", "feature")
+	pushUpstreamChange(t, repoPath, "conflicting")
+	msg, err = RebaseOnDefaultWithContext(context.Background(), repo)
+	require.True(t, isUnmergedOrConflictError(err), "conflicts reach the state evaluator as such")
+	require.Equal(t, "rebase of feature onto main stopped with conflicts", msg)
+	require.True(t, rebaseInProgress(context.Background(), repo), "the rebase waits to be resolved")
+}
@@ -0,0 +1,78 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// RebaseOntoOptions defines the rules of a `git rebase --onto` operation.
+type RebaseOntoOptions struct {
+	// Onto is the new base the commits are replayed on.
+	Onto string
+	// Upstream is the old base. Commits reachable from it are not replayed.
+	Upstream string
+	// Branch is checked out and rebased instead of the current branch.
+	Branch string
+}
+
+// RebaseOntoWithContext replays the commits between Upstream and the branch
+// onto Onto. A rebase that stops with conflicts is aborted so the repository
+// is left as it was.
+func RebaseOntoWithContext(ctx context.Context, r *git.Repository, options *RebaseOntoOptions) (string, error) {
+	if options == nil || strings.TrimSpace(options.Onto) == "" || strings.TrimSpace(options.Upstream) == "" {
+		return "", fmt.Errorf("onto and upstream refs are required")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	onto := strings.TrimSpace(options.Onto)
+	upstream := strings.TrimSpace(options.Upstream)
+	branch := strings.TrimSpace(options.Branch)
+	for _, ref := range []string{onto, upstream, branch} {
+		if ref == "" {
+			continue
+		}
+		if _, err := RunWithContext(ctx, r.AbsPath, "git", []string{"rev-parse", "--verify", "--quiet", ref + "^{commit}"}); err != nil {
+			return "", fmt.Errorf("unknown ref %s", ref)
+		}
+	}
+
+	args := append([]string{"rebase"}, noVerifyArgs(r)...)
+	args = append(args, "--onto", onto, upstream)
+	if branch != "" {
+		args = append(args, branch)
+	}
+	out, err := RunWithContext(ctx, r.AbsPath, "git", args)
+	if err != nil {
+		if rebaseInProgress(ctx, r) {
+			_, _ = RunWithContext(context.Background(), r.AbsPath, "git", []string{"rebase", "--abort"})
+			return "", fmt.Errorf("rebase onto %s stopped with conflicts and was aborted", onto)
+		}
+		return "", gerr.ParseGitError(out, err)
+	}
+	return fmt.Sprintf("rebased onto %s", onto), nil
+}
+
+// rebaseInProgress reports whether a stopped rebase waits for the user.
+func rebaseInProgress(ctx context.Context, r *git.Repository) bool {
+	for _, name := range []string{"rebase-merge", "rebase-apply"} {
+		out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"rev-parse", "--git-path", name})
+		if err != nil {
+			continue
+		}
+		path := strings.TrimSpace(out)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.AbsPath, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
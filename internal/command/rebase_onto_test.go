@@ -0,0 +1,51 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	for _, args := range [][]string{{"add", name}, {"commit", "-m", "change " + name}} {
+		out, err := Run(dir, "git", args)
+		require.NoError(t, err, out)
+	}
+}
+
+func TestRebaseOntoWithContext(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	for _, args := range [][]string{{"branch", "old-base"}, {"checkout", "-b", "release"}} {
+		_, err := Run(repoPath, "git", args)
+		require.NoError(t, err)
+	}
+	commitFile(t, repoPath, "release.txt", "release")
+	_, err := Run(repoPath, "git", []string{"checkout", "-b", "feature", "old-base"})
+	require.NoError(t, err)
+	commitFile(t, repoPath, "feature.txt", "feature")
+
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	_, err = RebaseOntoWithContext(context.Background(), repo, &RebaseOntoOptions{Onto: "missing", Upstream: "old-base"})
+	require.EqualError(t, err, "unknown ref missing")
+
+	msg, err := RebaseOntoWithContext(context.Background(), repo, &RebaseOntoOptions{Onto: "release", Upstream: "old-base"})
+	require.NoError(t, err)
+	require.Equal(t, "rebased onto release", msg)
+	_, err = Run(repoPath, "git", []string{"merge-base", "--is-ancestor", "release", "feature"})
+	require.NoError(t, err)
+
+	commitFile(t, repoPath, "release.txt", "conflicting")
+	_, err = Run(repoPath, "git", []string{"checkout", "release"})
+	require.NoError(t, err)
+	commitFile(t, repoPath, "release.txt", "release 2")
+	_, err = RebaseOntoWithContext(context.Background(), repo, &RebaseOntoOptions{Onto: "release", Upstream: "release~1", Branch: "feature"})
+	require.ErrorContains(t, err, "aborted")
+	require.False(t, rebaseInProgress(context.Background(), repo))
+}
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Reclone replaces the damaged repository at path with a fresh clone of url.
+// The damaged copy is kept next to it as <name>.corrupt-<time>, so that
+// uncommitted work can still be salvaged, and is put back when the clone
+// fails. It returns where the damaged copy was moved to.
+func Reclone(path, url string) (string, error) {
+	if url == "" {
+		return "", fmt.Errorf("no origin URL recorded for %s", path)
+	}
+	backup := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(path, backup); err != nil {
+		return "", err
+	}
+	if out, err := Run(filepath.Dir(path), "git", []string{"clone", url, path}); err != nil {
+		_ = os.RemoveAll(path)
+		if restoreErr := os.Rename(backup, path); restoreErr != nil {
+			return backup, fmt.Errorf("clone %s: %s (the damaged copy is at %s)", url, out, backup)
+		}
+		return "", fmt.Errorf("clone %s: %s", url, out)
+	}
+	return backup, nil
+}
@@ -0,0 +1,34 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecloneKeepsTheDamagedCopy(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	remote := filepath.Join(filepath.Dir(repoPath), "remote.git")
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "notes.txt"), []byte("unsaved"), 0o644))
+
+	backup, err := Reclone(repoPath, remote)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(backup, repoPath+".corrupt-"))
+	require.FileExists(t, filepath.Join(backup, "notes.txt"))
+	require.NoFileExists(t, filepath.Join(repoPath, "notes.txt"))
+	require.FileExists(t, filepath.Join(repoPath, ".git", "HEAD"))
+
+	_, err = Reclone(repoPath, "")
+	require.Error(t, err)
+}
+
+func TestRecloneRestoresTheRepositoryWhenTheCloneFails(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+
+	_, err := Reclone(repoPath, filepath.Join(t.TempDir(), "missing.git"))
+	require.Error(t, err)
+	require.FileExists(t, filepath.Join(repoPath, "README.md"))
+}
@@ -56,8 +56,8 @@ func AttachRefreshExecutor(r *git.Repository) {
 				// OperationStateProbe completion calls applyCleanliness without
 				// triggering a new remote probe, eliminating the 2–3 s delay.
 				message := " " // non-empty: tells EvaluateRepositoryState this is a completion, not an initial probe
-				if r.State != nil && strings.TrimSpace(r.State.Message) != "" {
-					message = r.State.Message
+				if r.State != nil && strings.TrimSpace(r.State.Message()) != "" {
+					message = r.State.Message()
 				}
 				ScheduleStateEvaluation(r, OperationOutcome{
 					Operation: OperationStateProbe,
@@ -0,0 +1,106 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// PruneRemote deletes remote-tracking references of the given remote that no
+// longer exist on the remote and reports how many were removed.
+func PruneRemote(r *git.Repository, remoteName string) (string, error) {
+	return PruneRemoteWithContext(context.Background(), r, remoteName)
+}
+
+// PruneRemoteWithContext runs git remote prune honouring the supplied context.
+func PruneRemoteWithContext(ctx context.Context, r *git.Repository, remoteName string) (string, error) {
+	if r == nil {
+		return "", fmt.Errorf("repository not set")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if remoteName == "" {
+		remoteName = repositoryRemoteName(r)
+	}
+	out, err := RunWithContextTimeout(ctx, r.AbsPath, "git", []string{"remote", "prune", remoteName}, DefaultFetchTimeout)
+	if err != nil {
+		return "", gerr.ParseGitError(out, err)
+	}
+	pruned := countPrunedRefs(out)
+	switch pruned {
+	case 0:
+		return i18n.T("%s: nothing to prune", remoteName), nil
+	case 1:
+		return i18n.T("%s: pruned 1 stale ref", remoteName), nil
+	default:
+		return i18n.T("%s: pruned %d stale refs", remoteName, pruned), nil
+	}
+}
+
+// countPrunedRefs counts the " * [pruned] origin/x" lines git prints.
+func countPrunedRefs(out string) int {
+	count := 0
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "[pruned]") {
+			count++
+		}
+	}
+	return count
+}
+
+// RemoteURLRewrite is the new URL of a remote after a search and replace.
+type RemoteURLRewrite struct {
+	Repository *git.Repository
+	Remote     string
+	Old        string
+	New        string
+}
+
+// PlanRemoteURLRewrites replaces find with replace, which may refer to
+// submatches as $1, in the URLs of the remotes of repos and returns the
+// remotes whose URL changes. Only the first URL of a remote is rewritten.
+func PlanRemoteURLRewrites(repos []*git.Repository, find *regexp.Regexp, replace string) []RemoteURLRewrite {
+	var rewrites []RemoteURLRewrite
+	for _, r := range repos {
+		if r == nil {
+			continue
+		}
+		for _, remote := range r.Remotes {
+			if len(remote.URL) == 0 {
+				continue
+			}
+			old := remote.URL[0]
+			if url := find.ReplaceAllString(old, replace); url != old {
+				rewrites = append(rewrites, RemoteURLRewrite{Repository: r, Remote: remote.Name, Old: old, New: url})
+			}
+		}
+	}
+	return rewrites
+}
+
+// SetRemoteURL points a remote at a new URL with git remote set-url.
+func SetRemoteURL(r *git.Repository, remote, url string) error {
+	return SetRemoteURLWithContext(context.Background(), r, remote, url)
+}
+
+// SetRemoteURLWithContext runs git remote set-url honouring the supplied
+// context.
+func SetRemoteURLWithContext(ctx context.Context, r *git.Repository, remote, url string) error {
+	if r == nil {
+		return fmt.Errorf("repository not set")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"remote", "set-url", remote, url})
+	if err != nil {
+		return gerr.ParseGitError(out, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package command
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestPruneRemote_RemovesStaleTrackingRefs(t *testing.T) {
+	basePath := initLocalWorktreeRepoForStateTest(t)
+	remotePath := filepath.Join(filepath.Dir(basePath), "remote.git")
+
+	_, err := Run(basePath, "git", []string{"push", "origin", "main:feature"})
+	require.NoError(t, err)
+	_, err = Run(basePath, "git", []string{"fetch", "origin"})
+	require.NoError(t, err)
+	_, err = Run(remotePath, "git", []string{"branch", "-D", "feature"})
+	require.NoError(t, err)
+
+	repo, err := git.InitializeRepo(basePath)
+	require.NoError(t, err)
+
+	msg, err := PruneRemote(repo, "origin")
+	require.NoError(t, err)
+	require.Equal(t, "origin: pruned 1 stale ref", msg)
+
+	_, err = Run(basePath, "git", []string{"rev-parse", "--verify", "refs/remotes/origin/feature"})
+	require.Error(t, err, "stale tracking ref should be gone")
+
+	msg, err = PruneRemote(repo, "origin")
+	require.NoError(t, err)
+	require.Equal(t, "origin: nothing to prune", msg)
+}
+
+func TestPlanRemoteURLRewrites_OnlyListsChangedURLs(t *testing.T) {
+	api := &git.Repository{Name: "api", Remotes: []*git.Remote{
+		{Name: "origin", URL: []string{"git@github.com:old-org/api.git"}},
+		{Name: "upstream", URL: []string{"https://github.com/vendor/api.git"}},
+	}}
+	web := &git.Repository{Name: "web", Remotes: []*git.Remote{
+		{Name: "origin", URL: []string{"https://github.com/old-org/web.git"}},
+	}}
+	find := regexp.MustCompile(`github\.com([:/])old-org/`)
+	rewrites := PlanRemoteURLRewrites([]*git.Repository{api, web, nil}, find, "gitlab.com${1}new-org/")
+	require.Equal(t, []RemoteURLRewrite{
+		{Repository: api, Remote: "origin", Old: "git@github.com:old-org/api.git", New: "git@gitlab.com:new-org/api.git"},
+		{Repository: web, Remote: "origin", Old: "https://github.com/old-org/web.git", New: "https://gitlab.com/new-org/web.git"},
+	}, rewrites)
+}
+
+func TestSetRemoteURL(t *testing.T) {
+	basePath := initLocalWorktreeRepoForStateTest(t)
+	repo, err := git.InitializeRepo(basePath)
+	require.NoError(t, err)
+
+	require.NoError(t, SetRemoteURL(repo, "origin", "https://example.com/new.git"))
+	out, err := Run(basePath, "git", []string{"remote", "get-url", "origin"})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/new.git", strings.TrimSpace(out))
+	require.Error(t, SetRemoteURL(repo, "missing", "https://example.com/new.git"))
+}
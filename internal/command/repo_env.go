@@ -0,0 +1,115 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RepoEnvRule adds environment variables to the git commands run in matching
+// repositories, e.g. a GIT_SSH_COMMAND with a dedicated key or a proxy.
+type RepoEnvRule struct {
+	// Path selects the repositories: a repository directory, a parent
+	// directory covering a group of repositories, or a glob such as
+	// ~/src/work-*.
+	Path string `mapstructure:"path"`
+	// Env lists KEY=value entries. A list rather than a map keeps the case
+	// of variable names, which the configuration loader would lower.
+	Env []string `mapstructure:"env"`
+}
+
+var (
+	repoEnvMu    sync.RWMutex
+	repoEnvRules []RepoEnvRule
+)
+
+// SetRepoEnv configures the per-repository environment. When several rules
+// match a repository, later rules override variables of earlier ones.
+func SetRepoEnv(rules []RepoEnvRule) {
+	normalized := make([]RepoEnvRule, 0, len(rules))
+	for _, rule := range rules {
+		path := expandRulePath(rule.Path)
+		if path == "" || len(rule.Env) == 0 {
+			continue
+		}
+		normalized = append(normalized, RepoEnvRule{Path: path, Env: rule.Env})
+	}
+	repoEnvMu.Lock()
+	repoEnvRules = normalized
+	repoEnvMu.Unlock()
+}
+
+// repoEnv returns the configured variables for a repository directory as
+// KEY=value entries, sorted by key.
+func repoEnv(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	repoEnvMu.RLock()
+	rules := repoEnvRules
+	repoEnvMu.RUnlock()
+	if len(rules) == 0 {
+		return nil
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	merged := make(map[string]string)
+	for _, rule := range rules {
+		if !repoPathMatches(rule.Path, dir) {
+			continue
+		}
+		for _, entry := range rule.Env {
+			key, value, ok := strings.Cut(entry, "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				continue
+			}
+			merged[strings.TrimSpace(key)] = value
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	env := make([]string, 0, len(merged))
+	for key, value := range merged {
+		env = append(env, key+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// repoPathMatches reports whether dir is selected by pattern, either directly
+// or because one of its parent directories is.
+func repoPathMatches(pattern, dir string) bool {
+	for candidate := dir; ; {
+		if candidate == pattern {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, candidate); ok {
+			return true
+		}
+		parent := filepath.Dir(candidate)
+		if parent == candidate {
+			return false
+		}
+		candidate = parent
+	}
+}
+
+func expandRulePath(p string) string {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return ""
+	}
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, p[1:])
+		}
+	}
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	return filepath.Clean(p)
+}
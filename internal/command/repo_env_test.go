@@ -0,0 +1,57 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoEnv_MatchesRepositoriesAndGroups(t *testing.T) {
+	root := t.TempDir()
+	SetRepoEnv([]RepoEnvRule{
+		{Path: filepath.Join(root, "work"), Env: []string{"GIT_SSH_COMMAND=ssh -i work", "https_proxy=http://proxy:3128"}},
+		{Path: filepath.Join(root, "work", "oss-*"), Env: []string{"GIT_SSH_COMMAND=ssh -i oss", "invalid"}},
+		{Path: filepath.Join(root, "empty")},
+	})
+	t.Cleanup(func() { SetRepoEnv(nil) })
+
+	require.Equal(t, []string{"GIT_SSH_COMMAND=ssh -i work", "https_proxy=http://proxy:3128"}, repoEnv(filepath.Join(root, "work", "api")))
+	require.Equal(t, []string{"GIT_SSH_COMMAND=ssh -i oss", "https_proxy=http://proxy:3128"}, repoEnv(filepath.Join(root, "work", "oss-lib", "sub")))
+	require.Empty(t, repoEnv(filepath.Join(root, "workshop")))
+	require.Empty(t, repoEnv(filepath.Join(root, "empty")))
+}
+
+func TestRunWithContextTimeout_InjectsRepoEnv(t *testing.T) {
+	dir := t.TempDir()
+	SetRepoEnv([]RepoEnvRule{{Path: dir, Env: []string{"GIT_SSH_COMMAND=ssh -i ~/.ssh/id_test"}}})
+	t.Cleanup(func() { SetRepoEnv(nil) })
+
+	out, err := Run(dir, "sh", []string{"-c", "echo $GIT_SSH_COMMAND"})
+	require.NoError(t, err)
+	require.Equal(t, "ssh -i ~/.ssh/id_test", strings.TrimSpace(out))
+
+	other, err := os.MkdirTemp("", "gitbatch-env-")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(other) })
+	out, err = Run(other, "sh", []string{"-c", "echo $GIT_SSH_COMMAND"})
+	require.NoError(t, err)
+	require.Contains(t, out, "BatchMode=yes")
+}
+
+func TestRunWithPassphrase_KeepsTheRepoEnvSSHCommand(t *testing.T) {
+	dir := t.TempDir()
+	SetRepoEnv([]RepoEnvRule{{Path: dir, Env: []string{"GIT_SSH_COMMAND=ssh -i ~/.ssh/id_work"}}})
+	t.Cleanup(func() { SetRepoEnv(nil) })
+
+	out, err := runWithEnv(context.Background(), dir, "sh", []string{"-c", "echo $GIT_SSH_COMMAND; echo $SSH_ASKPASS_REQUIRE"}, passphraseEnv("secret"), 0)
+	require.NoError(t, err)
+	require.Equal(t, "ssh -i ~/.ssh/id_work\nforce", strings.TrimSpace(out))
+
+	out, err = runWithEnv(context.Background(), t.TempDir(), "sh", []string{"-c", "echo $GIT_SSH_COMMAND"}, passphraseEnv("secret"), 0)
+	require.NoError(t, err)
+	require.Equal(t, askpassSSHCommand, strings.TrimSpace(out))
+}
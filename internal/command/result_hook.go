@@ -0,0 +1,148 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// JobResult is the record emitted for every completed job. It is written as a
+// single JSON line so external tools can follow the stream.
+type JobResult struct {
+	Time       time.Time `json:"time"`
+	Repository string    `json:"repository"`
+	Path       string    `json:"path"`
+	Branch     string    `json:"branch,omitempty"`
+	Operation  string    `json:"operation"`
+	Status     string    `json:"status"`
+	Message    string    `json:"message,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Job result statuses.
+const (
+	JobResultSuccess = "success"
+	JobResultFailed  = "failed"
+)
+
+// resultHookTimeout bounds how long the result command may run. A hung
+// command is killed so its goroutine does not linger for the whole session.
+var resultHookTimeout = 30 * time.Second
+
+var (
+	resultHookMu      sync.Mutex
+	resultHookFile    string
+	resultHookCommand string
+)
+
+// SetJobResultHook configures where job results are reported. file receives
+// one JSON line per job (a FIFO works as well); command is run through the
+// shell with the JSON line on stdin. Empty values disable the respective hook.
+func SetJobResultHook(file, command string) {
+	resultHookMu.Lock()
+	resultHookFile = strings.TrimSpace(file)
+	resultHookCommand = strings.TrimSpace(command)
+	resultHookMu.Unlock()
+}
+
+// isJobOperation reports whether an operation is a user job whose result is
+// reported to the result hooks. Background probes and refreshes are not.
+func isJobOperation(op OperationType) bool {
+	switch op {
+	case OperationFetch, OperationPull, OperationMerge, OperationRebase, OperationPush,
+		OperationCommit, OperationStash, OperationStashPop, OperationStashDrop, OperationSyncFork,
+		OperationComposite:
+		return true
+	default:
+		return false
+	}
+}
+
+func newJobResult(r *git.Repository, outcome OperationOutcome) JobResult {
+	result := JobResult{
+		Time:       time.Now(),
+		Repository: r.Name,
+		Path:       r.AbsPath,
+		Operation:  string(outcome.Operation),
+		Status:     JobResultSuccess,
+		Message:    strings.TrimSpace(outcome.Message),
+	}
+	if r.State != nil && r.State.Branch != nil {
+		result.Branch = r.State.Branch.Name
+	}
+	if outcome.Err != nil {
+		result.Status = JobResultFailed
+		result.Error = git.NormalizeGitErrorMessage(outcome.Err.Error())
+	}
+	return result
+}
+
+// reportJobResult hands a finished job to the configured hooks. It runs in the
+// background so slow consumers never hold up the state queue.
+func reportJobResult(r *git.Repository, outcome OperationOutcome) {
+	if r == nil || !isJobOperation(outcome.Operation) {
+		return
+	}
+	resultHookMu.Lock()
+	file, command := resultHookFile, resultHookCommand
+	resultHookMu.Unlock()
+	if file == "" && command == "" {
+		return
+	}
+	line, err := json.Marshal(newJobResult(r, outcome))
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	go func() {
+		if file != "" {
+			_ = appendJobResult(file, line)
+		}
+		if command != "" {
+			_ = runJobResultCommand(command, line)
+		}
+	}()
+}
+
+// appendJobResult writes one line to the result file. Writes are serialised so
+// lines from concurrent jobs never interleave. A FIFO without a reader is
+// skipped instead of blocking.
+func appendJobResult(path string, line []byte) error {
+	resultHookMu.Lock()
+	defer resultHookMu.Unlock()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE|syscall.O_NONBLOCK, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(line)
+	return err
+}
+
+// runJobResultCommand runs the result command with the JSON line on stdin.
+// It is killed once resultHookTimeout has passed.
+func runJobResultCommand(command string, line []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), resultHookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(line)
+	// Children of the shell may keep running after it is killed; do not wait
+	// for them.
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		log.Printf("result hook: %q timed out after %s", command, resultHookTimeout)
+		return ctx.Err()
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestReportJobResultWritesJSONLines(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "results.jsonl")
+	SetJobResultHook(file, "")
+	t.Cleanup(func() { SetJobResultHook("", "") })
+
+	repo := &git.Repository{Name: "alpha", AbsPath: "/src/alpha", State: &git.RepositoryState{
+		Branch: &git.Branch{Name: "main"},
+	}}
+	reportJobResult(repo, OperationOutcome{Operation: OperationPull, Message: "pull completed"})
+	reportJobResult(repo, OperationOutcome{Operation: OperationStateProbe})
+	reportJobResult(repo, OperationOutcome{Operation: OperationComposite, Message: "release done"})
+
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(file)
+		return err == nil && strings.Count(string(data), "\n") == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	// The lines are written in the background, in any order.
+	results := make(map[string]JobResult)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var result JobResult
+		require.NoError(t, json.Unmarshal([]byte(line), &result))
+		results[result.Operation] = result
+	}
+	require.Equal(t, "release done", results["composite"].Message)
+	result := results["pull"]
+	require.Equal(t, "alpha", result.Repository)
+	require.Equal(t, "main", result.Branch)
+	require.Equal(t, "pull", result.Operation)
+	require.Equal(t, JobResultSuccess, result.Status)
+	require.Equal(t, "pull completed", result.Message)
+}
+
+func TestReportJobResultRunsCommandWithFailure(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "hook.out")
+	SetJobResultHook("", "cat > "+out)
+	t.Cleanup(func() { SetJobResultHook("", "") })
+
+	repo := &git.Repository{Name: "beta", AbsPath: "/src/beta", State: &git.RepositoryState{}}
+	reportJobResult(repo, OperationOutcome{Operation: OperationFetch, Err: errors.New("network unreachable")})
+
+	var result JobResult
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(out)
+		return err == nil && json.Unmarshal(data, &result) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "fetch", result.Operation)
+	require.Equal(t, JobResultFailed, result.Status)
+	require.Contains(t, result.Error, "network unreachable")
+}
+
+func TestRunJobResultCommandStopsAHungCommand(t *testing.T) {
+	old := resultHookTimeout
+	resultHookTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { resultHookTimeout = old })
+
+	start := time.Now()
+	err := runJobResultCommand("sleep 30", []byte("{}\n"))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
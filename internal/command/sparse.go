@@ -0,0 +1,73 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// sparseReapplyAfterPull re-applies the sparse-checkout patterns of sparse
+// repositories whenever a pull or merge moved their branch.
+var sparseReapplyAfterPull atomic.Bool
+
+// SetSparseReapply configures whether pulls and merges in sparse checkouts
+// are followed by `git sparse-checkout reapply`.
+func SetSparseReapply(enabled bool) {
+	sparseReapplyAfterPull.Store(enabled)
+}
+
+// SparseReapplyWithContext runs `git sparse-checkout reapply`, which brings
+// the working tree back in line with the patterns, e.g. after a merge left
+// files outside of them behind.
+func SparseReapplyWithContext(ctx context.Context, r *git.Repository) (string, error) {
+	if !r.IsSparse() {
+		return "", fmt.Errorf("%s is not a sparse checkout", r.Name)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"sparse-checkout", "reapply"})
+	if err != nil {
+		return "", gerr.ParseGitError(out, err)
+	}
+	return "sparse-checkout reapplied", nil
+}
+
+// sparseReapplyFollowUp returns the request that re-applies the sparse
+// patterns after outcome moved HEAD from ref to newref, or nil when nothing
+// needs to be done. The request runs on the git queue after the pull or merge
+// was evaluated and reports a failed reapply as a warning in the message of
+// the update instead of failing it.
+func sparseReapplyFollowUp(r *git.Repository, ref, newref string, outcome OperationOutcome) *GitCommandRequest {
+	if outcome.Err != nil || ref == newref || !r.IsSparse() || !sparseReapplyAfterPull.Load() {
+		return nil
+	}
+	return &GitCommandRequest{
+		Key:       fmt.Sprintf("sparse-reapply:%s", r.RepoID),
+		Timeout:   DefaultGitCommandTimeout,
+		Operation: OperationSparseReapply,
+		Execute: func(ctx context.Context) OperationOutcome {
+			// The result is the one of the update, which the result hook
+			// reported already.
+			result := OperationOutcome{
+				Operation:       OperationSparseReapply,
+				Message:         outcome.Message,
+				SuppressSuccess: outcome.SuppressSuccess,
+			}
+			if _, err := SparseReapplyWithContext(ctx, r); err != nil {
+				result.Message = i18n.T("%s (warning: sparse-checkout reapply failed: %s)", outcome.Message, git.NormalizeGitErrorMessage(err.Error()))
+			}
+			return result
+		},
+	}
+}
+
+// headHash is the commit HEAD of r points to, or "" when it is unborn.
+func headHash(r *git.Repository) string {
+	ref, _ := r.Repo.Head()
+	return referenceHash(ref)
+}
@@ -0,0 +1,45 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestSparseReapplyWithContext(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	_, err = SparseReapplyWithContext(context.Background(), repo)
+	require.ErrorContains(t, err, "not a sparse checkout")
+
+	_, err = Run(repoPath, "git", []string{"sparse-checkout", "set", "docs"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Refresh())
+	require.True(t, repo.IsSparse())
+
+	msg, err := SparseReapplyWithContext(context.Background(), repo)
+	require.NoError(t, err)
+	require.Equal(t, "sparse-checkout reapplied", msg)
+
+	pull := OperationOutcome{Operation: OperationPull, Message: "1 file changed"}
+	require.Nil(t, sparseReapplyFollowUp(repo, "a", "b", pull), "reapply is off by default")
+	SetSparseReapply(true)
+	t.Cleanup(func() { SetSparseReapply(false) })
+	require.Nil(t, sparseReapplyFollowUp(repo, "a", "a", pull), "HEAD did not move")
+	require.Nil(t, sparseReapplyFollowUp(repo, "a", "b", OperationOutcome{Operation: OperationPull, Err: context.Canceled}))
+
+	followUp := sparseReapplyFollowUp(repo, "a", "b", pull)
+	require.NotNil(t, followUp)
+	require.Equal(t, OperationSparseReapply, followUp.Operation)
+	require.Equal(t, OperationOutcome{Operation: OperationSparseReapply, Message: "1 file changed"}, followUp.Execute(context.Background()))
+
+	_, err = Run(repoPath, "git", []string{"sparse-checkout", "disable"})
+	require.NoError(t, err)
+	outcome := followUp.Execute(context.Background())
+	require.NoError(t, outcome.Err, "a failed reapply does not fail the pull")
+	require.Equal(t, OperationSparseReapply, outcome.Operation, "the pull is reported once")
+	require.Contains(t, outcome.Message, "1 file changed (warning: sparse-checkout reapply failed: ")
+}
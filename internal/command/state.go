@@ -10,6 +10,7 @@ import (
 
 	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
 )
 
 func init() {
@@ -20,19 +21,30 @@ func init() {
 type OperationType string
 
 const (
-	OperationFetch      OperationType = "fetch"
-	OperationPull       OperationType = "pull"
-	OperationMerge      OperationType = "merge"
-	OperationRebase     OperationType = "rebase"
-	OperationPush       OperationType = "push"
-	OperationCommit     OperationType = "commit"
-	OperationStash      OperationType = "stash"
-	OperationStashPop   OperationType = "stash-pop"
-	OperationStashDrop  OperationType = "stash-drop"
-	OperationRefresh    OperationType = "refresh"
-	OperationGit        OperationType = "git"
-	OperationStateProbe OperationType = "state-probe"
-	OperationNoUpstream OperationType = "no-upstream"
+	OperationFetch         OperationType = "fetch"
+	OperationPull          OperationType = "pull"
+	OperationMerge         OperationType = "merge"
+	OperationRebase        OperationType = "rebase"
+	OperationPush          OperationType = "push"
+	OperationCommit        OperationType = "commit"
+	OperationStash         OperationType = "stash"
+	OperationStashPop      OperationType = "stash-pop"
+	OperationStashDrop     OperationType = "stash-drop"
+	OperationCheckout      OperationType = "checkout"
+	OperationBranch        OperationType = "branch"
+	OperationUndo          OperationType = "undo"
+	OperationClean         OperationType = "clean"
+	OperationApply         OperationType = "apply"
+	OperationComposite     OperationType = "composite"
+	OperationSyncFork      OperationType = "sync-fork"
+	OperationSparseReapply OperationType = "sparse-reapply"
+	OperationBisect        OperationType = "bisect"
+	OperationPrune         OperationType = "prune"
+	OperationSetURL        OperationType = "set-url"
+	OperationRefresh       OperationType = "refresh"
+	OperationGit           OperationType = "git"
+	OperationStateProbe    OperationType = "state-probe"
+	OperationNoUpstream    OperationType = "no-upstream"
 )
 
 // OperationOutcome captures the result of an operation for state evaluation.
@@ -41,6 +53,14 @@ type OperationOutcome struct {
 	Err             error
 	Message         string
 	SuppressSuccess bool
+	// PullRequestURL is the page to open a pull request for the branch a
+	// push created on the remote.
+	PullRequestURL string
+	// FollowUp is queued on the repository once the outcome was applied
+	// without an error, e.g. the sparse-checkout reapply after a pull.
+	FollowUp *GitCommandRequest
+	// Batch is the batch of the job the outcome belongs to, zero for none.
+	Batch BatchID
 }
 
 // isGitFatalError checks if an error is a git fatal error (exit code 128).
@@ -79,6 +99,11 @@ func EvaluateRepositoryState(r *git.Repository, outcome OperationOutcome) {
 		return
 	}
 
+	if outcome.Operation == operationProbeRetry {
+		handleProbeRetry(r, outcome)
+		return
+	}
+
 	if outcome.Operation == OperationStateProbe {
 		// Check if this is an initial state probe request (no message/result yet)
 		// vs. a completion (has message or error from the async operation)
@@ -90,14 +115,31 @@ func EvaluateRepositoryState(r *git.Repository, outcome OperationOutcome) {
 		// This is a completion result from the async state probe.
 		// Handle errors normally, or apply success state if no error.
 		if outcome.Err == nil {
+			settleStateProbe(r)
 			applySuccessState(r, outcome)
 			applyCleanliness(r)
 			return
 		}
+		if retryStateProbe(r, outcome) {
+			return
+		}
 		// Fall through to error handling below
 	}
 
+	if errors.Is(outcome.Err, context.Canceled) {
+		// The command was dropped from the git queue before it ran.
+		r.State.SetMessage(i18n.T("cancelled"))
+		var aborted *BatchAbortedError
+		if errors.As(outcome.Err, &aborted) {
+			r.State.SetMessage(i18n.T("cancelled: %s failed in %s (fail_fast)", aborted.Kind, aborted.Repository))
+		}
+		r.SetWorkStatus(git.Available)
+		return
+	}
+
 	if outcome.Err != nil {
+		abortBatchOnFailure(r, outcome)
+
 		// Check for authentication errors first
 		if gerr.RequiresCredentials(outcome.Err) {
 			message := strings.TrimSpace(outcome.Message)
@@ -105,6 +147,7 @@ func EvaluateRepositoryState(r *git.Repository, outcome OperationOutcome) {
 				message = git.NormalizeGitErrorMessage(outcome.Err.Error())
 			}
 			r.MarkRequiresCredentials(message)
+			pauseHost(r, outcome)
 			return
 		}
 
@@ -114,8 +157,9 @@ func EvaluateRepositoryState(r *git.Repository, outcome OperationOutcome) {
 			if message == "" {
 				message = git.NormalizeGitErrorMessage(outcome.Err.Error())
 			}
-			r.State.Message = message
+			r.State.SetMessage(message)
 			r.MarkDisabled()
+			r.State.Failure = gerr.KindConflict
 			r.SetWorkStatus(git.Available)
 			return
 		}
@@ -124,7 +168,7 @@ func EvaluateRepositoryState(r *git.Repository, outcome OperationOutcome) {
 		if message == "" {
 			message = git.NormalizeGitErrorMessage(outcome.Err.Error())
 		}
-		r.MarkCriticalError(message)
+		r.MarkFailure(gerr.KindOf(outcome.Err), message)
 		return
 	}
 
@@ -138,6 +182,11 @@ func EvaluateRepositoryState(r *git.Repository, outcome OperationOutcome) {
 	}
 
 	applyCleanliness(r)
+	if outcome.FollowUp != nil {
+		if err := ScheduleGitCommand(r, outcome.FollowUp); err != nil {
+			r.State.SetMessage(i18n.T("%s (warning: %s)", r.State.Message(), err))
+		}
+	}
 }
 
 // AttachStateEvaluator wires repository events to the state evaluator.
@@ -162,9 +211,10 @@ func AttachStateEvaluator(r *git.Repository) {
 		}
 		prev := snapshotState(r)
 		EvaluateRepositoryState(r, outcome)
+		reportJobResult(r, outcome)
 		// Only schedule a refresh if the operation succeeded.
 		// Refreshing after an error would overwrite the error state.
-		if outcome.Err == nil && outcome.Operation != OperationRefresh && outcome.Operation != OperationStateProbe && stateChanged(prev, r) {
+		if outcome.Err == nil && outcome.Operation != OperationRefresh && outcome.Operation != OperationStateProbe && outcome.Operation != operationProbeRetry && stateChanged(prev, r) {
 			_ = ScheduleRepositoryRefresh(r, nil)
 		}
 		return nil
@@ -172,10 +222,15 @@ func AttachStateEvaluator(r *git.Repository) {
 }
 
 // ScheduleStateEvaluation emits an event-driven request to recompute repository state.
+// A bare state probe carries no result, so it coalesces with one that still waits.
 func ScheduleStateEvaluation(r *git.Repository, outcome OperationOutcome) {
 	if r == nil {
 		return
 	}
+	if outcome.Operation == OperationStateProbe && outcome.Err == nil && outcome.Message == "" {
+		_ = r.PublishCoalesced(git.RepositoryEvaluationRequested, outcome)
+		return
+	}
 	_ = r.Publish(git.RepositoryEvaluationRequested, outcome)
 }
 
@@ -197,12 +252,43 @@ func RequestExternalRefresh(r *git.Repository) {
 	if r == nil || r.State == nil || r.WorkStatus().InFlight() {
 		return
 	}
-	r.State.Message = "waiting"
+	r.State.SetMessage(i18n.T("waiting"))
 	r.SetWorkStatus(git.Pending)
 	r.NotifyRepositoryUpdated()
 	_ = ScheduleRepositoryRefresh(r, nil)
 }
 
+// ForceRefresh synchronously reloads a repository's metadata and then
+// schedules a full state probe, including the remote check and fast-forward
+// dry run that RequestExternalRefresh skips. Respects the global git
+// semaphore. In-flight repos are skipped.
+func ForceRefresh(ctx context.Context, r *git.Repository) error {
+	if r == nil || r.State == nil || r.WorkStatus().InFlight() {
+		return nil
+	}
+	r.State.SetMessage(i18n.T("waiting"))
+	r.SetWorkStatus(git.Pending)
+
+	if err := git.AcquireGitSemaphore(ctx); err != nil {
+		return err
+	}
+	r.BeginWatchSuppress()
+	err := r.Refresh()
+	r.EndWatchSuppress()
+	git.ReleaseGitSemaphore()
+	if err != nil {
+		ScheduleStateEvaluation(r, OperationOutcome{
+			Operation: OperationRefresh,
+			Err:       err,
+		})
+		return err
+	}
+
+	forgetFastForwardDryRun(r)
+	ScheduleStateEvaluation(r, OperationOutcome{Operation: OperationStateProbe})
+	return nil
+}
+
 // RefreshWorkingTreeSync synchronously re-evaluates working-tree cleanliness
 // (HasLocalChanges, HasConflicts) and branch ahead/behind counts. Callers
 // that need fresh state before making a decision — e.g. pre-batch-op
@@ -224,7 +310,7 @@ type stateSnapshot struct {
 func snapshotState(r *git.Repository) stateSnapshot {
 	return stateSnapshot{
 		status:  r.WorkStatus(),
-		message: r.State.Message,
+		message: r.State.Message(),
 	}
 }
 
@@ -232,14 +318,14 @@ func stateChanged(prev stateSnapshot, r *git.Repository) bool {
 	if prev.status != r.WorkStatus() {
 		return true
 	}
-	if prev.message != r.State.Message {
+	if prev.message != r.State.Message() {
 		return true
 	}
 	return false
 }
 
 func handleStateProbe(r *git.Repository) {
-	setRepositoryStatus(r, git.Pending, "waiting")
+	setRepositoryStatus(r, git.Pending, i18n.T("waiting"))
 
 	if r.IsLinkedWorktree() {
 		handleLinkedWorktreeStateProbe(r)
@@ -248,26 +334,31 @@ func handleStateProbe(r *git.Repository) {
 
 	branch := r.State.Branch
 	if branch == nil {
-		r.MarkCriticalError("branch not set")
+		r.MarkCriticalError(i18n.T("branch not set"))
+		return
+	}
+
+	if !r.HasRemote() {
+		r.MarkNoUpstream(i18n.T("no remote configured"))
 		return
 	}
 
 	upstream := branch.Upstream
 	if upstream == nil {
-		r.MarkNoUpstream("upstream not configured")
+		r.MarkNoUpstream(i18n.T("upstream not configured"))
 		return
 	}
 
 	remoteName, remoteBranch := resolveUpstreamParts(r, branch)
 	if remoteName == "" || remoteBranch == "" {
-		r.MarkNoUpstream("upstream not configured")
+		r.MarkNoUpstream(i18n.T("upstream not configured"))
 		return
 	}
 
 	// Schedule the upstream verification and fetch asynchronously via the git queue
 	// to avoid blocking the TUI
 	if err := scheduleUpstreamVerificationAndFetch(r, remoteName, remoteBranch); err != nil {
-		r.MarkCriticalError(fmt.Sprintf("unable to schedule verification: %v", err))
+		r.MarkCriticalError(i18n.T("unable to schedule verification: %v", err))
 		return
 	}
 }
@@ -352,14 +443,14 @@ func setAndTrackStatus(r *git.Repository, status git.WorkStatus) bool {
 
 func applySuccessState(r *git.Repository, outcome OperationOutcome) {
 	message := strings.TrimSpace(outcome.Message)
-	prevMessage := r.State.Message
+	prevMessage := r.State.Message()
 	statusChanged := false
 	notified := false
 
 	switch outcome.Operation {
 	case OperationFetch:
 		statusChanged = setAndTrackStatus(r, git.Available)
-		r.State.Message = message
+		r.State.SetMessage(message)
 	case OperationPull:
 		if outcome.SuppressSuccess {
 			statusChanged = setAndTrackStatus(r, git.Available)
@@ -367,23 +458,23 @@ func applySuccessState(r *git.Repository, outcome OperationOutcome) {
 			statusChanged = setAndTrackStatus(r, git.Success)
 		}
 		if message == "" {
-			r.State.Message = "pull completed"
+			r.State.SetMessage(i18n.T("pull completed"))
 		} else {
-			r.State.Message = message
+			r.State.SetMessage(message)
 		}
 	case OperationMerge:
 		statusChanged = setAndTrackStatus(r, git.Success)
 		if message == "" {
-			r.State.Message = "merge completed"
+			r.State.SetMessage(i18n.T("merge completed"))
 		} else {
-			r.State.Message = message
+			r.State.SetMessage(message)
 		}
 	case OperationRebase:
 		statusChanged = setAndTrackStatus(r, git.Success)
 		if message == "" {
-			r.State.Message = "rebase completed"
+			r.State.SetMessage(i18n.T("rebase completed"))
 		} else {
-			r.State.Message = message
+			r.State.SetMessage(message)
 		}
 	case OperationPush:
 		if outcome.SuppressSuccess {
@@ -392,12 +483,26 @@ func applySuccessState(r *git.Repository, outcome OperationOutcome) {
 			statusChanged = setAndTrackStatus(r, git.Success)
 		}
 		if message == "" {
-			r.State.Message = "push completed"
+			r.State.SetMessage(i18n.T("push completed"))
 		} else {
-			r.State.Message = message
+			r.State.SetMessage(message)
 		}
+		r.State.PullRequestURL = outcome.PullRequestURL
+		if outcome.PullRequestURL != "" {
+			r.State.SetMessage(i18n.T("push completed, open a pull request: %s", outcome.PullRequestURL))
+		}
+	case OperationSparseReapply:
+		if outcome.SuppressSuccess {
+			statusChanged = setAndTrackStatus(r, git.Available)
+		} else {
+			statusChanged = setAndTrackStatus(r, git.Success)
+		}
+		r.State.SetMessage(message)
+	case OperationComposite, OperationSyncFork:
+		statusChanged = setAndTrackStatus(r, git.Success)
+		r.State.SetMessage(message)
 	case OperationRefresh:
-		r.State.Message = message
+		r.State.SetMessage(message)
 		if r.WorkStatus() != git.Available {
 			statusChanged = setAndTrackStatus(r, git.Available)
 		} else if strings.TrimSpace(prevMessage) != strings.TrimSpace(message) {
@@ -405,17 +510,17 @@ func applySuccessState(r *git.Repository, outcome OperationOutcome) {
 			notified = true
 		}
 	case OperationStateProbe:
-		r.State.Message = message
+		r.State.SetMessage(message)
 		if strings.TrimSpace(prevMessage) != strings.TrimSpace(message) {
 			r.NotifyRepositoryUpdated()
 			notified = true
 		}
 	default:
 		statusChanged = setAndTrackStatus(r, git.Available)
-		r.State.Message = message
+		r.State.SetMessage(message)
 	}
 
-	if !statusChanged && !notified && strings.TrimSpace(prevMessage) != strings.TrimSpace(r.State.Message) {
+	if !statusChanged && !notified && strings.TrimSpace(prevMessage) != strings.TrimSpace(r.State.Message()) {
 		r.NotifyRepositoryUpdated()
 	}
 }
@@ -426,6 +531,19 @@ func applyCleanliness(r *git.Repository) {
 }
 
 func applyCleanlinessAsync(r *git.Repository) {
+	// Acquire semaphore to limit concurrent git status operations
+	if err := git.AcquireGitSemaphore(context.Background()); err != nil {
+		// If we can't acquire semaphore, we can't check cleanliness safely
+		return
+	}
+	defer git.ReleaseGitSemaphore()
+
+	// A refresh running alongside would swap the branches we mark below.
+	// Refreshes take the lock while holding the semaphore too, so taking it
+	// second keeps the order the same on both sides.
+	r.LockComponents()
+	defer r.UnlockComponents()
+
 	if r.State.Branch == nil {
 		return
 	}
@@ -434,18 +552,10 @@ func applyCleanlinessAsync(r *git.Repository) {
 	// watcher doesn't treat our own writes as an external change.
 	r.BeginWatchSuppress()
 	defer r.EndWatchSuppress()
-	// Refresh ahead/behind counts before acquiring the semaphore. initBranches()
-	// runs before the initial fetch so Pullables is stale on the first run.
-	// git for-each-ref is read-only and lightweight; no semaphore needed.
+	// Refresh ahead/behind counts first. initBranches() runs before the
+	// initial fetch so Pullables is stale on the first run.
 	r.RefreshBranchCounts()
 
-	// Acquire semaphore to limit concurrent git status operations
-	if err := git.AcquireGitSemaphore(context.Background()); err != nil {
-		// If we can't acquire semaphore, we can't check cleanliness safely
-		return
-	}
-	defer git.ReleaseGitSemaphore()
-
 	branch := r.State.Branch
 
 	// Check if the working tree is clean according to git
@@ -470,19 +580,19 @@ func applyCleanlinessAsync(r *git.Repository) {
 		// HasIncomingCommits() guarantees Upstream != nil via PullableCount().
 		mergeArg := upstreamMergeArgument(branch.Upstream)
 		if mergeArg == "" {
-			r.MarkNoUpstream("upstream not configured")
+			r.MarkNoUpstream(i18n.T("upstream not configured"))
 			return
 		}
 
 		// Set status to Working so spinner shows during the fast-forward check
 		prevStatus := r.WorkStatus()
 		if prevStatus != git.Working {
-			setRepositoryStatus(r, git.Working, "checking for conflicts...")
+			setRepositoryStatus(r, git.Working, i18n.T("checking for conflicts..."))
 		}
 
-		succeeds, err := fastForwardDryRunSucceeds(r, mergeArg, workingTreeClean)
+		succeeds, err := cachedFastForwardDryRunSucceeds(r, mergeArg, workingTreeClean)
 		if err != nil {
-			r.MarkCriticalError(fmt.Sprintf("unable to verify fast-forward: %v", err))
+			r.MarkCriticalError(i18n.T("unable to verify fast-forward: %v", err))
 			return
 		}
 
@@ -490,18 +600,28 @@ func applyCleanlinessAsync(r *git.Repository) {
 			// Working tree is clean — fast-forward will succeed.
 			r.MarkClean()
 			if succeeds {
-				r.SetWorkStatus(git.Queued)
+				autoQueue(r)
 			}
 		} else if succeeds {
 			// Working tree has local changes, but they don't overlap with the incoming
 			// commits so a fast-forward pull will work fine. Mark with the yellow
 			// "local changes" indicator and still auto-queue.
 			r.MarkLocalChanges()
-			r.SetWorkStatus(git.Queued)
+			autoQueue(r)
 		} else {
 			// Working tree is dirty AND the incoming commits touch the same files.
-			// A pull would fail — show the warning triangle.
-			r.MarkDisabled()
+			// A plain pull would fail; the configured policy decides what happens.
+			switch CurrentDirtyPolicy() {
+			case DirtyPolicyAutostash:
+				r.MarkLocalChanges()
+				autoQueue(r)
+			case DirtyPolicyFail:
+				r.MarkDisabled()
+				r.MarkCriticalError(i18n.T("local changes overlap with incoming commits (on_dirty: fail)"))
+				return
+			default:
+				r.MarkDisabled()
+			}
 		}
 		if r.WorkStatus() != git.Available && r.WorkStatus() != git.Queued {
 			r.SetWorkStatus(git.Available)
@@ -522,6 +642,16 @@ func applyCleanlinessAsync(r *git.Repository) {
 	}
 }
 
+// autoQueue tags r for the next pull once the fast-forward check passed. The
+// check runs as Working to show the spinner, and a working repository cannot
+// be queued, so it settles to Available first.
+func autoQueue(r *git.Repository) {
+	if r.WorkStatus() == git.Working {
+		r.SetWorkStatusSilent(git.Available)
+	}
+	r.SetWorkStatus(git.Queued)
+}
+
 func applyLinkedWorktreeStateAsync(r *git.Repository) {
 	if r == nil || r.State == nil || r.State.Branch == nil {
 		return
@@ -595,17 +725,13 @@ func fastForwardDryRunSucceeds(r *git.Repository, mergeArg string, workingTreeCl
 	_, ancestorErr := Run(r.AbsPath, "git", []string{"merge-base", "--is-ancestor", "HEAD", mergeArg})
 	if ancestorErr != nil {
 		// HEAD is not an ancestor of upstream (branches diverged) or the check failed.
-		// Use merge-tree to detect commit-level conflicts.
-		out, err := Run(r.AbsPath, "git", []string{"merge-tree", "--write-tree", "HEAD", mergeArg})
-		if err != nil {
-			// merge-tree exits non-zero on conflicts or when the flag is unsupported.
+		// Predict commit-level conflicts.
+		_, conflicted, err := mergeConflicts(context.Background(), r, mergeArg)
+		if err != nil || conflicted {
 			// Either way we can't safely merge without manual intervention.
 			return false, nil
 		}
-		if strings.Contains(out, "CONFLICT") {
-			return false, nil
-		}
-		// merge-tree succeeded with no conflicts; fall through to file-overlap check.
+		// The merge is clean; fall through to file-overlap check.
 	}
 
 	if workingTreeClean {
@@ -614,46 +740,64 @@ func fastForwardDryRunSucceeds(r *git.Repository, mergeArg string, workingTreeCl
 	}
 
 	// Determine which files the incoming commits would update.
-	diffOut, err := Run(r.AbsPath, "git", []string{"diff", "--name-only", "HEAD", mergeArg})
+	incoming, err := incomingFiles(r, mergeArg)
 	if err != nil {
 		// Can't determine overlap — be conservative.
 		return false, nil
 	}
 
-	// Re-read the working tree status to get per-file detail for overlap checking.
-	statusOut, err := Run(r.AbsPath, "git", []string{"status", "--porcelain"})
+	// If any locally modified file would also be touched by the incoming commits,
+	// a fast-forward checkout would refuse to overwrite it.
+	overlap, err := locallyChangedFiles(r, incoming)
 	if err != nil {
-		return false, fmt.Errorf("failed to get status: %w", err)
+		return false, err
 	}
 
-	incomingFiles := make(map[string]bool)
+	// Local changes don't overlap with incoming changes — fast-forward is safe.
+	return len(overlap) == 0, nil
+}
+
+// incomingFiles returns the files that differ between HEAD and mergeArg.
+func incomingFiles(r *git.Repository, mergeArg string) (map[string]bool, error) {
+	diffOut, err := Run(r.AbsPath, "git", []string{"diff", "--name-only", "HEAD", mergeArg})
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]bool)
 	for _, file := range strings.Split(strings.TrimSpace(diffOut), "\n") {
 		if file != "" {
-			incomingFiles[file] = true
+			files[file] = true
 		}
 	}
+	return files, nil
+}
 
-	// If any locally modified file would also be touched by the incoming commits,
-	// a fast-forward checkout would refuse to overwrite it.
+// locallyChangedFiles returns the files of the working tree status that are
+// part of incoming.
+func locallyChangedFiles(r *git.Repository, incoming map[string]bool) ([]string, error) {
+	// Re-read the working tree status to get per-file detail for overlap checking.
+	statusOut, err := Run(r.AbsPath, "git", git.WorkTreeStatusArgs())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+	var overlap []string
 	for _, line := range strings.Split(statusOut, "\n") {
 		if len(line) < 4 {
 			continue
 		}
 		file := strings.TrimSpace(line[3:])
-		if incomingFiles[file] {
-			return false, nil
+		if incoming[file] {
+			overlap = append(overlap, file)
 		}
 	}
-
-	// Local changes don't overlap with incoming changes — fast-forward is safe.
-	return true, nil
+	return overlap, nil
 }
 
 func setRepositoryStatus(r *git.Repository, status git.WorkStatus, message string) {
 	prevStatus := r.WorkStatus()
-	prevMessage := strings.TrimSpace(r.State.Message)
+	prevMessage := strings.TrimSpace(r.State.Message())
 	trimmed := strings.TrimSpace(message)
-	r.State.Message = message
+	r.State.SetMessage(message)
 	if prevStatus != status {
 		r.SetWorkStatus(status)
 		return
@@ -1,6 +1,7 @@
 package command
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -112,16 +113,15 @@ func TestApplySuccessState(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &git.Repository{
 				RepoID: "test",
-				State: &git.RepositoryState{
-					Message: "initial",
-				},
+				State:  &git.RepositoryState{},
 			}
+			repo.State.SetMessage("initial")
 			repo.SetWorkStatusSilent(tt.initialStatus)
 
 			applySuccessState(repo, tt.outcome)
 
 			require.Equal(t, tt.expectedStatus, repo.WorkStatus(), "WorkStatus mismatch")
-			require.Equal(t, tt.expectedMsg, repo.State.Message, "Message mismatch")
+			require.Equal(t, tt.expectedMsg, repo.State.Message(), "Message mismatch")
 		})
 	}
 }
@@ -148,6 +148,23 @@ func TestHandleStateProbe_LinkedWorktreeSkipsNoUpstreamState(t *testing.T) {
 	require.True(t, repo.State.Branch.Clean)
 }
 
+func TestHandleStateProbe_NoRemoteMarksNoUpstream(t *testing.T) {
+	basePath := initLocalWorktreeRepoForStateTest(t)
+	_, err := Run(basePath, "git", []string{"remote", "remove", "origin"})
+	require.NoError(t, err)
+
+	repo, err := git.InitializeRepo(basePath)
+	require.NoError(t, err)
+	require.False(t, repo.HasRemote())
+	require.Nil(t, repo.State.Remote)
+
+	handleStateProbe(repo)
+
+	require.True(t, repo.State.NoUpstream)
+	require.Equal(t, "no remote configured", repo.State.Message())
+	require.Equal(t, git.Fail, repo.WorkStatus())
+}
+
 // TestSetAndTrackStatus tests the setAndTrackStatus helper.
 func TestSetAndTrackStatus(t *testing.T) {
 	repo := &git.Repository{
@@ -816,3 +833,29 @@ func TestApplyCleanliness_UncleanWithIncomingFFSucceeds(t *testing.T) {
 	require.True(t, repo.State.Branch.HasLocalChanges, "HasLocalChanges should be true")
 	require.Equal(t, git.Queued, repo.WorkStatus(), "status should be Queued (auto-queued for ff pull)")
 }
+
+func TestForceRefresh_ReloadsMetadataAndProbes(t *testing.T) {
+	basePath := initLocalWorktreeRepoForStateTest(t)
+	repo, err := git.InitializeRepo(basePath)
+	require.NoError(t, err)
+
+	_, err = Run(basePath, "git", []string{"branch", "feature"})
+	require.NoError(t, err)
+
+	repo.SetWorkStatusSilent(git.Queued)
+	require.NoError(t, ForceRefresh(context.Background(), repo))
+	require.Len(t, repo.Branches, 1, "queued repositories are skipped")
+
+	repo.SetWorkStatusSilent(git.Available)
+	require.NoError(t, ForceRefresh(context.Background(), repo))
+	names := make([]string, 0, len(repo.Branches))
+	for _, b := range repo.Branches {
+		names = append(names, b.Name)
+	}
+	require.ElementsMatch(t, []string{"main", "feature"}, names)
+
+	require.Eventually(t, func() bool {
+		return !repo.WorkStatus().InFlight()
+	}, 10*time.Second, 20*time.Millisecond)
+	require.Equal(t, git.Available, repo.WorkStatus())
+}
@@ -0,0 +1,132 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// UndoKind identifies how an undo entry is reverted.
+type UndoKind string
+
+const (
+	// UndoCheckout switches back to the branch or commit checked out before.
+	UndoCheckout UndoKind = "checkout"
+	// UndoDeleteBranch recreates a deleted local branch at its old commit.
+	UndoDeleteBranch UndoKind = "delete-branch"
+)
+
+// UndoEntry records a reversible action and the refs needed to revert it.
+type UndoEntry struct {
+	Kind UndoKind
+	// Description is the action that was taken, e.g. "checkout feature".
+	Description string
+	// Ref is the branch, or with Detached the commit, HEAD pointed at before
+	// a checkout, or the name of a deleted branch.
+	Ref      string
+	Detached bool
+	// Commit is the commit a deleted branch pointed at.
+	Commit string
+}
+
+// undoStackLimit caps the entries kept per repository; the oldest are
+// dropped first.
+const undoStackLimit = 50
+
+var (
+	undoMu     sync.Mutex
+	undoStacks = make(map[string][]UndoEntry)
+)
+
+func pushUndo(r *git.Repository, entry UndoEntry) {
+	undoMu.Lock()
+	defer undoMu.Unlock()
+	stack := append(undoStacks[r.RepoID], entry)
+	if len(stack) > undoStackLimit {
+		stack = stack[len(stack)-undoStackLimit:]
+	}
+	undoStacks[r.RepoID] = stack
+}
+
+func popUndo(r *git.Repository) (UndoEntry, bool) {
+	undoMu.Lock()
+	defer undoMu.Unlock()
+	stack := undoStacks[r.RepoID]
+	if len(stack) == 0 {
+		return UndoEntry{}, false
+	}
+	entry := stack[len(stack)-1]
+	undoStacks[r.RepoID] = stack[:len(stack)-1]
+	return entry, true
+}
+
+// PeekUndo returns the most recent reversible action in r without removing
+// it.
+func PeekUndo(r *git.Repository) (UndoEntry, bool) {
+	if r == nil {
+		return UndoEntry{}, false
+	}
+	undoMu.Lock()
+	defer undoMu.Unlock()
+	stack := undoStacks[r.RepoID]
+	if len(stack) == 0 {
+		return UndoEntry{}, false
+	}
+	return stack[len(stack)-1], true
+}
+
+// currentHead returns the branch HEAD points at, or the commit when HEAD is
+// detached.
+func currentHead(ctx context.Context, r *git.Repository) (string, bool, error) {
+	if out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"symbolic-ref", "-q", "--short", "HEAD"}); err == nil {
+		return strings.TrimSpace(out), false, nil
+	}
+	out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"rev-parse", "HEAD"})
+	if err != nil {
+		return "", false, gerr.ParseGitError(out, err)
+	}
+	return strings.TrimSpace(out), true, nil
+}
+
+// branchCommit returns the commit a local branch points at.
+func branchCommit(ctx context.Context, r *git.Repository, branch string) (string, error) {
+	out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"rev-parse", "--verify", "refs/heads/" + branch})
+	if err != nil {
+		return "", gerr.ParseGitError(out, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// UndoWithContext reverts an undo entry.
+func UndoWithContext(ctx context.Context, r *git.Repository, entry UndoEntry) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	switch entry.Kind {
+	case UndoCheckout:
+		if _, err := CheckoutWithContext(ctx, r, &CheckoutOptions{Branch: entry.Ref, Detach: entry.Detached}); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("undid %s: back on %s", entry.Description, shortRef(entry.Ref, entry.Detached)), nil
+	case UndoDeleteBranch:
+		out, err := RunWithContext(ctx, r.AbsPath, "git", []string{"branch", entry.Ref, entry.Commit})
+		if err != nil {
+			return "", gerr.ParseGitError(out, err)
+		}
+		return fmt.Sprintf("undid %s: restored at %s", entry.Description, shortRef(entry.Commit, true)), nil
+	default:
+		return "", fmt.Errorf("cannot undo %s", entry.Description)
+	}
+}
+
+func shortRef(ref string, commit bool) string {
+	if commit && len(ref) > 7 {
+		return ref[:7]
+	}
+	return ref
+}
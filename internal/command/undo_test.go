@@ -0,0 +1,90 @@
+package command
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// runPlan runs a plan without the state evaluation of Executor.run, whose
+// background git status would race the next command for the index lock.
+func runPlan(t *testing.T, plan executionPlan) error {
+	t.Helper()
+	return plan.outcome(context.Background()).Err
+}
+
+func TestUndoRevertsCheckoutsAndBranchDeletions(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	_, err := Run(repoPath, "git", []string{"branch", "feature"})
+	require.NoError(t, err)
+	featureCommit, err := Run(repoPath, "git", []string{"rev-parse", "feature"})
+	require.NoError(t, err)
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	executor := NewExecutor(repo)
+
+	_, ok := PeekUndo(repo)
+	require.False(t, ok)
+
+	require.NoError(t, runPlan(t, executor.prepareCheckout(&CheckoutOptions{Branch: "feature"})))
+	require.Equal(t, "feature", currentBranch(t, repoPath))
+	require.NoError(t, runPlan(t, executor.prepareCheckout(&CheckoutOptions{Branch: "main"})))
+	require.NoError(t, repo.Refresh())
+	require.NoError(t, runPlan(t, executor.prepareDeleteBranch(&DeleteBranchOptions{Branch: "feature"})))
+
+	entry, ok := PeekUndo(repo)
+	require.True(t, ok)
+	require.Equal(t, UndoDeleteBranch, entry.Kind)
+	require.Equal(t, "delete feature", entry.Description)
+
+	require.NoError(t, runPlan(t, executor.prepareUndo()))
+	restored, err := Run(repoPath, "git", []string{"rev-parse", "feature"})
+	require.NoError(t, err)
+	require.Equal(t, strings.TrimSpace(featureCommit), strings.TrimSpace(restored))
+
+	require.NoError(t, runPlan(t, executor.prepareUndo()))
+	require.Equal(t, "feature", currentBranch(t, repoPath))
+	require.NoError(t, runPlan(t, executor.prepareUndo()))
+	require.Equal(t, "main", currentBranch(t, repoPath))
+
+	_, ok = PeekUndo(repo)
+	require.False(t, ok)
+	require.Error(t, runPlan(t, executor.prepareUndo()))
+}
+
+func TestUndoOfADetachedCheckoutReturnsToTheCommit(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	head, err := Run(repoPath, "git", []string{"rev-parse", "HEAD"})
+	require.NoError(t, err)
+	_, err = Run(repoPath, "git", []string{"checkout", "--detach", "HEAD"})
+	require.NoError(t, err)
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, runPlan(t, NewExecutor(repo).prepareCheckout(&CheckoutOptions{Branch: "main"})))
+	entry, ok := PeekUndo(repo)
+	require.True(t, ok)
+	require.True(t, entry.Detached)
+	require.Equal(t, strings.TrimSpace(head), entry.Ref)
+
+	msg, err := UndoWithContext(ctx, repo, entry)
+	require.NoError(t, err)
+	require.Equal(t, "undid checkout main: back on "+strings.TrimSpace(head)[:7], msg)
+	require.Equal(t, "HEAD", currentBranch(t, repoPath))
+}
+
+func TestFailedUndoKeepsTheEntry(t *testing.T) {
+	repoPath := initLocalWorktreeRepoForStateTest(t)
+	repo, err := git.InitializeRepo(repoPath)
+	require.NoError(t, err)
+	pushUndo(repo, UndoEntry{Kind: UndoCheckout, Description: "checkout main", Ref: "missing"})
+
+	require.Error(t, runPlan(t, NewExecutor(repo).prepareUndo()))
+	entry, ok := PeekUndo(repo)
+	require.True(t, ok)
+	require.Equal(t, "missing", entry.Ref)
+}
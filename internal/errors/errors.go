@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"strings"
 )
@@ -49,6 +50,9 @@ const (
 	ErrUserEmailNotSet GitError = "user email not set"
 	// ErrCredentialPromptDetected is thrown when a credential prompt is detected in the output
 	ErrCredentialPromptDetected GitError = "credential prompt detected"
+	// ErrPassphraseRequired is thrown when ssh asks for the passphrase of a
+	// private key that is not loaded into an agent
+	ErrPassphraseRequired GitError = "ssh key passphrase required"
 	// ErrNetworkTimeout is thrown when network operations timeout
 	ErrNetworkTimeout GitError = ("network timeout")
 	// ErrNetworkUnreachable is thrown when network is unreachable
@@ -57,6 +61,14 @@ const (
 	ErrDNSError GitError = ("dns resolution failed")
 	// ErrSSLError is thrown when SSL/TLS validation fails
 	ErrSSLError GitError = ("ssl certificate problem")
+	// ErrLockFileExists is thrown when git finds a lock file such as
+	// .git/index.lock left by another (possibly crashed) git process
+	ErrLockFileExists GitError = ("lock file exists, another git process may be running")
+	// ErrDiskFull is thrown when the filesystem has no space left
+	ErrDiskFull GitError = ("no space left on device")
+	// ErrFilesystemPermission is thrown when git cannot read or write files of
+	// the repository, e.g. after running git with sudo
+	ErrFilesystemPermission GitError = ("filesystem permission denied")
 	// ErrUnclassified is unconsidered error type
 	ErrUnclassified GitError = ("unclassified error")
 )
@@ -88,10 +100,13 @@ func RequiresCredentials(err error) bool {
 	if err == nil {
 		return false
 	}
+	if ge, ok := asGitError(err); ok && ge == ErrFilesystemPermission {
+		return false
+	}
 
 	// Check for specific error types
 	switch err {
-	case ErrAuthenticationRequired, ErrPermissionDenied, ErrAuthorizationFailed, ErrCredentialPromptDetected:
+	case ErrAuthenticationRequired, ErrPermissionDenied, ErrAuthorizationFailed, ErrCredentialPromptDetected, ErrPassphraseRequired:
 		return true
 	}
 
@@ -147,6 +162,34 @@ func ParseGitError(out string, err error) error {
 		return ErrAuthenticationRequired
 	}
 
+	if strings.Contains(lowerOut, "enter passphrase for key") {
+		if exitCode > 0 {
+			return gitErrorWithExitCode{GitError: ErrPassphraseRequired, exitCode: exitCode}
+		}
+		return ErrPassphraseRequired
+	}
+
+	if strings.Contains(lowerOut, ".lock': file exists") ||
+		strings.Contains(lowerOut, "another git process seems to be running") {
+		if exitCode > 0 {
+			return gitErrorWithExitCode{GitError: ErrLockFileExists, exitCode: exitCode}
+		}
+		return ErrLockFileExists
+	}
+	if strings.Contains(lowerOut, "no space left on device") ||
+		strings.Contains(lowerOut, "disk quota exceeded") {
+		if exitCode > 0 {
+			return gitErrorWithExitCode{GitError: ErrDiskFull, exitCode: exitCode}
+		}
+		return ErrDiskFull
+	}
+	if isFilesystemPermissionError(lowerOut) {
+		if exitCode > 0 {
+			return gitErrorWithExitCode{GitError: ErrFilesystemPermission, exitCode: exitCode}
+		}
+		return ErrFilesystemPermission
+	}
+
 	if strings.Contains(out, "error: Your local changes to the following files would be overwritten by merge") {
 		return ErrMergeAbortedTryCommit
 	} else if strings.Contains(out, "ERROR: Repository not found") {
@@ -206,3 +249,149 @@ func ParseGitError(out string, err error) error {
 
 	return errors.New(trimmed)
 }
+
+// isFilesystemPermissionError recognises permission problems on local files,
+// as opposed to ssh's "Permission denied (publickey)".
+func isFilesystemPermissionError(lowerOut string) bool {
+	if strings.Contains(lowerOut, "insufficient permission for adding an object") ||
+		strings.Contains(lowerOut, "operation not permitted") {
+		return true
+	}
+	if !strings.Contains(lowerOut, "permission denied") {
+		return false
+	}
+	return strings.Contains(lowerOut, "unable to create") ||
+		strings.Contains(lowerOut, "unable to unlink") ||
+		strings.Contains(lowerOut, "unable to write") ||
+		strings.Contains(lowerOut, "could not open") ||
+		strings.Contains(lowerOut, "cannot open") ||
+		strings.Contains(lowerOut, "unable to append") ||
+		strings.Contains(lowerOut, "failed to create")
+}
+
+// corruptionMarkers are messages of git and go-git about a damaged object
+// database or damaged refs.
+var corruptionMarkers = []string{
+	"corrupt",
+	"bad object",
+	"missing object",
+	"object not found",
+	"unable to unpack",
+	"loose object",
+	"packfile",
+	"invalid sha1 pointer",
+	"does not point to a valid object",
+	"zlib",
+	"inflate:",
+	"malformed",
+}
+
+// IsCorruption reports whether err says that the objects or refs of a
+// repository are damaged. Fetching again does not help; the repository has
+// to be repaired or cloned again.
+func IsCorruption(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range corruptionMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// asGitError unwraps the GitError behind err, including errors that carry an
+// exit code.
+func asGitError(err error) (GitError, bool) {
+	var withCode gitErrorWithExitCode
+	if errors.As(err, &withCode) {
+		return withCode.GitError, true
+	}
+	var ge GitError
+	if errors.As(err, &ge) {
+		return ge, true
+	}
+	return "", false
+}
+
+// Recovery describes what it takes to get past a failed operation.
+type Recovery string
+
+const (
+	// RecoveryNone means there is no known way around the error.
+	RecoveryNone Recovery = "none"
+	// RecoveryRetry means the error is transient and retrying may succeed.
+	RecoveryRetry Recovery = "retry"
+	// RecoveryRemoveLock means a stale lock file blocks git and removing it
+	// lets the operation succeed.
+	RecoveryRemoveLock Recovery = "remove-lock"
+	// RecoveryUserAction means the user has to fix something outside of
+	// gitbatch first: credentials, disk space or file permissions.
+	RecoveryUserAction Recovery = "user-action"
+)
+
+// Classify reports how an error returned by ParseGitError can be recovered.
+func Classify(err error) Recovery {
+	if err == nil {
+		return RecoveryNone
+	}
+	ge, ok := asGitError(err)
+	if !ok {
+		if RequiresCredentials(err) {
+			return RecoveryUserAction
+		}
+		return RecoveryNone
+	}
+	switch ge {
+	case ErrLockFileExists:
+		return RecoveryRemoveLock
+	case ErrNetworkTimeout, ErrNetworkUnreachable, ErrDNSError:
+		return RecoveryRetry
+	case ErrDiskFull, ErrFilesystemPermission, ErrAuthenticationRequired, ErrAuthorizationFailed,
+		ErrPermissionDenied, ErrCredentialPromptDetected, ErrPassphraseRequired, ErrUserEmailNotSet:
+		return RecoveryUserAction
+	default:
+		return RecoveryNone
+	}
+}
+
+// Kind groups errors by what went wrong, so the overview can tell a
+// repository that needs credentials from one with a merge conflict or one
+// whose remote cannot be reached.
+type Kind string
+
+const (
+	// KindFatal is any error without a more specific kind.
+	KindFatal Kind = "fatal"
+	// KindAuth means git needs credentials, a passphrase or other rights.
+	KindAuth Kind = "auth"
+	// KindNetwork means the remote could not be reached.
+	KindNetwork Kind = "network"
+	// KindConflict means the changes could not be merged.
+	KindConflict Kind = "conflict"
+)
+
+// KindOf returns the kind of an error returned by ParseGitError, or an empty
+// kind for nil.
+func KindOf(err error) Kind {
+	if err == nil {
+		return ""
+	}
+	if RequiresCredentials(err) {
+		return KindAuth
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindNetwork
+	}
+	ge, _ := asGitError(err)
+	switch ge {
+	case ErrNetworkTimeout, ErrNetworkUnreachable, ErrDNSError, ErrRemoteNotFound:
+		return KindNetwork
+	case ErrConflictAfterMerge, ErrUnmergedFiles, ErrOverwrittenByMerge:
+		return KindConflict
+	default:
+		return KindFatal
+	}
+}
@@ -1,6 +1,9 @@
 package errors
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -23,3 +26,86 @@ func TestParseGitErrorReturnsTrimmedOutput(t *testing.T) {
 		t.Fatalf("expected trimmed message, got %q", output.Error())
 	}
 }
+
+func TestParseGitErrorDetectsPassphrasePrompt(t *testing.T) {
+	output := ParseGitError("Enter passphrase for key '/home/me/.ssh/id_ed25519': ", ErrCredentialPromptDetected)
+	if output != ErrPassphraseRequired {
+		t.Fatalf("expected passphrase error, got %v", output)
+	}
+	if !RequiresCredentials(output) {
+		t.Fatalf("expected passphrase error to require credentials")
+	}
+}
+
+func TestParseGitErrorClassifiesFilesystemErrors(t *testing.T) {
+	cases := []struct {
+		output   string
+		want     GitError
+		recovery Recovery
+	}{
+		{"fatal: Unable to create '/repo/.git/index.lock': File exists.\n\nAnother git process seems to be running in this repository", ErrLockFileExists, RecoveryRemoveLock},
+		{"error: unable to write file .git/objects/ab/cdef: No space left on device", ErrDiskFull, RecoveryUserAction},
+		{"error: insufficient permission for adding an object to repository database .git/objects", ErrFilesystemPermission, RecoveryUserAction},
+		{"error: cannot open .git/FETCH_HEAD: Permission denied", ErrFilesystemPermission, RecoveryUserAction},
+	}
+	for _, tc := range cases {
+		output := ParseGitError(tc.output, nil)
+		if output != tc.want {
+			t.Fatalf("expected %v for %q, got %v", tc.want, tc.output, output)
+		}
+		if got := Classify(output); got != tc.recovery {
+			t.Fatalf("expected recovery %q for %v, got %q", tc.recovery, output, got)
+		}
+		if RequiresCredentials(output) {
+			t.Fatalf("expected %v not to require credentials", output)
+		}
+	}
+}
+
+func TestParseGitErrorKeepsSSHPermissionDenied(t *testing.T) {
+	output := ParseGitError("git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository.", nil)
+	if output == ErrFilesystemPermission {
+		t.Fatalf("expected ssh error not to be classified as filesystem permission")
+	}
+	if !RequiresCredentials(output) {
+		t.Fatalf("expected ssh permission error to require credentials")
+	}
+}
+
+func TestIsCorruption(t *testing.T) {
+	corrupt := []string{
+		"HEAD commit f90f8db: zlib reading error: zlib: invalid header",
+		"HEAD commit f90f8db: object not found",
+		"for-each-ref: error: unable to unpack f90f8db header\nfatal: missing object f90f8db for refs/heads/main",
+		"fatal: bad object HEAD",
+	}
+	for _, msg := range corrupt {
+		if !IsCorruption(errors.New(msg)) {
+			t.Errorf("expected %q to be classified as corruption", msg)
+		}
+	}
+	for _, err := range []error{nil, ErrAuthenticationRequired, ErrLockFileExists, errors.New("repository does not exist")} {
+		if IsCorruption(err) {
+			t.Errorf("expected %v not to be classified as corruption", err)
+		}
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		kind Kind
+	}{
+		"auth":         {ParseGitError("fatal: Authentication failed for 'https://example.com/repo.git/'", errors.New("exit status 128")), KindAuth},
+		"dns":          {ParseGitError("ssh: Could not resolve hostname example.com: Name or service not known", errors.New("exit status 128")), KindNetwork},
+		"timeout":      {fmt.Errorf("fetch timed out: %w", context.DeadlineExceeded), KindNetwork},
+		"conflict":     {ErrConflictAfterMerge, KindConflict},
+		"unclassified": {errors.New("fatal: bad revision"), KindFatal},
+		"none":         {nil, ""},
+	}
+	for name, tc := range cases {
+		if got := KindOf(tc.err); got != tc.kind {
+			t.Errorf("%s: expected kind %q for %v, got %q", name, tc.kind, tc.err, got)
+		}
+	}
+}
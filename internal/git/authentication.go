@@ -7,4 +7,6 @@ type Credentials struct {
 	User string
 	// Password is the secret information required for authentication
 	Password string
+	// Passphrase unlocks the ssh private key used for ssh remotes
+	Passphrase string
 }
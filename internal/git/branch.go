@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	git "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -82,6 +83,10 @@ func (r *Repository) initBranches() error {
 	cmd.Dir = r.AbsPath
 	out, err := cmd.CombinedOutput()
 	if err != nil {
+		// The output says why, e.g. a missing object behind a branch.
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("for-each-ref: %s", msg)
+		}
 		return err
 	}
 
@@ -349,10 +354,37 @@ type WorkTreeStatus struct {
 	HasConflicts bool
 }
 
+// ignoreUntracked controls whether untracked files are ignored when deciding
+// if a working tree is dirty. Set once from the app configuration.
+var ignoreUntracked atomic.Bool
+
+// SetIgnoreUntracked configures whether untracked files count as local changes.
+// When enabled, scratch files lying around in a repository no longer block
+// batch pulls.
+func SetIgnoreUntracked(enabled bool) {
+	ignoreUntracked.Store(enabled)
+}
+
+// IgnoreUntracked reports whether untracked files are treated as clean.
+func IgnoreUntracked() bool {
+	return ignoreUntracked.Load()
+}
+
+// WorkTreeStatusArgs returns the git status arguments used for cleanliness
+// checks, honouring the untracked-files setting. The checks run next to the
+// git queue, so they skip the optional index refresh that would hold
+// index.lock while a queued command needs it.
+func WorkTreeStatusArgs() []string {
+	if ignoreUntracked.Load() {
+		return []string{"--no-optional-locks", "status", "--porcelain", "-uno"}
+	}
+	return []string{"--no-optional-locks", "status", "--porcelain"}
+}
+
 // GetWorkTreeStatus checks the working tree status using git status --porcelain.
 // It returns whether the tree is clean and if there are any conflicts.
 func (r *Repository) GetWorkTreeStatus() (WorkTreeStatus, error) {
-	args := []string{"status", "--porcelain"}
+	args := WorkTreeStatusArgs()
 	cmd := exec.Command("git", args...)
 	cmd.Dir = r.AbsPath
 	out, err := cmd.CombinedOutput()
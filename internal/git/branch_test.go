@@ -1,6 +1,8 @@
 package git
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -100,3 +102,27 @@ func TestRevlistNew(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, pushables)
 }
+
+func TestGetWorkTreeStatus_IgnoreUntracked(t *testing.T) {
+	basePath := initLocalWorktreeRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "scratch.txt"), []byte("notes"), 0o644))
+
+	repo, err := InitializeRepo(basePath)
+	require.NoError(t, err)
+
+	status, err := repo.GetWorkTreeStatus()
+	require.NoError(t, err)
+	require.False(t, status.Clean, "untracked files count as dirty by default")
+
+	SetIgnoreUntracked(true)
+	t.Cleanup(func() { SetIgnoreUntracked(false) })
+
+	status, err = repo.GetWorkTreeStatus()
+	require.NoError(t, err)
+	require.True(t, status.Clean, "untracked files should be ignored when enabled")
+
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "README.md"), []byte("changed"), 0o644))
+	status, err = repo.GetWorkTreeStatus()
+	require.NoError(t, err)
+	require.False(t, status.Clean, "tracked modifications are still dirty")
+}
@@ -0,0 +1,31 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// CompareWithRef counts the commits HEAD has that ref lacks (ahead) and the
+// commits ref has that HEAD lacks (behind). ref may be any revision git
+// understands, e.g. "origin/main" or "v1.2".
+func (r *Repository) CompareWithRef(ref string) (ahead, behind int, err error) {
+	cmd := exec.Command("git", "rev-list", "--left-right", "--count", "HEAD..."+ref, "--")
+	cmd.Dir = r.AbsPath
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return 0, 0, fmt.Errorf("compare with %s: %s", ref, NormalizeGitErrorMessage(string(out)))
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("compare with %s: unexpected output %q", ref, strings.TrimSpace(string(out)))
+	}
+	if ahead, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, err
+	}
+	if behind, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
@@ -0,0 +1,36 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareWithRef(t *testing.T) {
+	basePath := initLocalWorktreeRepo(t)
+	repo, err := InitializeRepo(basePath)
+	require.NoError(t, err)
+
+	runGitCommand(t, basePath, "branch", "release")
+	runGitCommand(t, basePath, "checkout", "-b", "feature")
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "feature.txt"), []byte("f"), 0o644))
+	runGitCommand(t, basePath, "add", "feature.txt")
+	runGitCommand(t, basePath, "commit", "-m", "feature")
+	runGitCommand(t, basePath, "checkout", "release")
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "release.txt"), []byte("r"), 0o644))
+	runGitCommand(t, basePath, "add", "release.txt")
+	runGitCommand(t, basePath, "commit", "-m", "release 1")
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "release.txt"), []byte("r2"), 0o644))
+	runGitCommand(t, basePath, "commit", "-am", "release 2")
+	runGitCommand(t, basePath, "checkout", "feature")
+
+	ahead, behind, err := repo.CompareWithRef("release")
+	require.NoError(t, err)
+	require.Equal(t, 1, ahead)
+	require.Equal(t, 2, behind)
+
+	_, _, err = repo.CompareWithRef("does-not-exist")
+	require.Error(t, err)
+}
@@ -1,5 +1,11 @@
 package git
 
+import (
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
 
 // File represents the status of a file in an index or work tree
 type File struct {
@@ -49,3 +55,36 @@ func (s FilesAlphabetical) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 func (s FilesAlphabetical) Less(i, j int) bool {
 	return CompareNamesInsensitive(s[i].Name, s[j].Name) < 0
 }
+
+// LoadFiles lists the changed files of the working tree, sorted by name. It
+// honours the untracked-files setting; renamed files carry their new name.
+func (r *Repository) LoadFiles() ([]*File, error) {
+	args := append(WorkTreeStatusArgs(), "-z")
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.AbsPath
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var files []*File
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		file := &File{
+			Name:    entry[3:],
+			AbsPath: filepath.Join(r.AbsPath, entry[3:]),
+			X:       FileStatus(entry[0]),
+			Y:       FileStatus(entry[1]),
+		}
+		// Renames and copies are followed by their source path.
+		if file.X == StatusRenamed || file.X == StatusCopied {
+			i++
+		}
+		files = append(files, file)
+	}
+	sort.Sort(FilesAlphabetical(files))
+	return files, nil
+}
@@ -0,0 +1,30 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFiles(t *testing.T) {
+	repoPath := initLocalWorktreeRepo(t)
+	repo, err := InitializeRepo(repoPath)
+	require.NoError(t, err)
+
+	files, err := repo.LoadFiles()
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("changed"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "notes.txt"), []byte("new"), 0o644))
+	files, err = repo.LoadFiles()
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, "notes.txt", files[0].Name)
+	require.Equal(t, StatusUntracked, files[0].X)
+	require.Equal(t, "README.md", files[1].Name)
+	require.Equal(t, StatusModified, files[1].Y)
+	require.Equal(t, filepath.Join(repoPath, "README.md"), files[1].AbsPath)
+}
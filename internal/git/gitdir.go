@@ -0,0 +1,64 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResolveGitDir returns the git directory of the working tree at workTree and
+// its common git directory without running git. Linked worktrees, submodules
+// and `git init --separate-git-dir` leave a .git file naming the git
+// directory instead of a .git directory; a commondir file in a linked
+// worktree's git directory points at the directory it shares with the main
+// worktree.
+func ResolveGitDir(workTree string) (gitDir, commonGitDir string, err error) {
+	dotGit := filepath.Join(workTree, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", "", err
+	}
+	gitDir = dotGit
+	if !info.IsDir() {
+		content, err := os.ReadFile(dotGit)
+		if err != nil {
+			return "", "", err
+		}
+		line := strings.TrimSpace(string(content))
+		target, ok := strings.CutPrefix(line, "gitdir:")
+		if !ok {
+			return "", "", fmt.Errorf("%s: no gitdir line", dotGit)
+		}
+		gitDir = resolveRelative(workTree, strings.TrimSpace(target))
+	}
+	commonGitDir = gitDir
+	if content, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonGitDir = resolveRelative(gitDir, strings.TrimSpace(string(content)))
+	}
+	return gitDir, commonGitDir, nil
+}
+
+// GitDirs returns the git directory and the common git directory of the
+// repository. Until its worktrees are loaded they are resolved from the .git
+// entry of the working tree.
+func (r *Repository) GitDirs() (gitDir, commonGitDir string) {
+	gitDir, commonGitDir = r.GitDir, r.CommonGitDir
+	if gitDir == "" {
+		var err error
+		if gitDir, commonGitDir, err = ResolveGitDir(r.AbsPath); err != nil {
+			gitDir = filepath.Join(r.AbsPath, ".git")
+		}
+	}
+	if commonGitDir == "" {
+		commonGitDir = gitDir
+	}
+	return gitDir, commonGitDir
+}
+
+func resolveRelative(base, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(base, path)
+	}
+	return filepath.Clean(path)
+}
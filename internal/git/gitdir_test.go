@@ -0,0 +1,53 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveGitDir_FollowsGitFiles(t *testing.T) {
+	basePath := initLocalWorktreeRepo(t)
+	gitDir, commonGitDir, err := ResolveGitDir(basePath)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(basePath, ".git"), gitDir)
+	require.Equal(t, gitDir, commonGitDir)
+
+	linkedPath := filepath.Join(filepath.Dir(basePath), "linked")
+	runGitCommand(t, basePath, "worktree", "add", "-b", "linked", linkedPath)
+	gitDir, commonGitDir, err = ResolveGitDir(linkedPath)
+	require.NoError(t, err)
+	require.Equal(t, normalizeRepositoryPath(filepath.Join(basePath, ".git", "worktrees", "linked")), normalizeRepositoryPath(gitDir))
+	require.Equal(t, normalizeRepositoryPath(filepath.Join(basePath, ".git")), normalizeRepositoryPath(commonGitDir))
+
+	separatePath := filepath.Join(filepath.Dir(basePath), "separate")
+	separateGitDir := filepath.Join(filepath.Dir(basePath), "separate.git")
+	require.NoError(t, os.MkdirAll(separatePath, 0o755))
+	runGitCommand(t, separatePath, "init", "--separate-git-dir", separateGitDir)
+	gitDir, commonGitDir, err = ResolveGitDir(separatePath)
+	require.NoError(t, err)
+	require.Equal(t, normalizeRepositoryPath(separateGitDir), normalizeRepositoryPath(gitDir))
+	require.Equal(t, gitDir, commonGitDir)
+
+	_, _, err = ResolveGitDir(t.TempDir())
+	require.Error(t, err)
+}
+
+func TestRefreshModTime_LinkedWorktreeSeesItsOwnFiles(t *testing.T) {
+	basePath := initLocalWorktreeRepo(t)
+	linkedPath := filepath.Join(filepath.Dir(basePath), "linked")
+	runGitCommand(t, basePath, "worktree", "add", "-b", "linked", linkedPath)
+
+	r := &Repository{AbsPath: linkedPath}
+	gitDir, commonGitDir := r.GitDirs()
+	require.NotEqual(t, gitDir, commonGitDir)
+
+	before := r.RefreshModTime()
+	later := before.Add(time.Hour)
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "FETCH_HEAD"), nil, 0o644))
+	require.NoError(t, os.Chtimes(filepath.Join(gitDir, "FETCH_HEAD"), later, later))
+	require.True(t, r.RefreshModTime().Equal(later))
+}
@@ -0,0 +1,55 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GrepMatch is a line of a tracked file that matched a git grep pattern.
+type GrepMatch struct {
+	// File is relative to the repository root.
+	File string
+	Line int
+	Text string
+}
+
+// Grep runs `git grep` for pattern in the tracked files of the working tree
+// and returns at most limit matches; limit <= 0 returns all of them. Binary
+// files are skipped. A pattern without matches is not an error.
+func (r *Repository) Grep(pattern string, limit int) ([]GrepMatch, error) {
+	cmd := exec.Command("git", "grep", "-z", "-n", "-I", "--no-color", "--full-name", "-e", pattern)
+	cmd.Dir = r.AbsPath
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		msg := err.Error()
+		if exitErr != nil && len(exitErr.Stderr) > 0 {
+			msg = NormalizeGitErrorMessage(string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("grep %s: %s", pattern, msg)
+	}
+
+	var matches []GrepMatch
+	for _, line := range strings.Split(string(out), "\n") {
+		// With -z every match reads file NUL line NUL text.
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		matches = append(matches, GrepMatch{File: parts[0], Line: n, Text: parts[2]})
+		if limit > 0 && len(matches) >= limit {
+			break
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,37 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrep(t *testing.T) {
+	repoPath := initLocalWorktreeRepo(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "docs", "a:b.txt"), []byte("one\nTODO: two\nthree TODO\n"), 0o644))
+	runGitCommand(t, repoPath, "add", ".")
+	runGitCommand(t, repoPath, "commit", "-m", "docs")
+	repo, err := InitializeRepo(repoPath)
+	require.NoError(t, err)
+
+	matches, err := repo.Grep("TODO", 0)
+	require.NoError(t, err)
+	require.Equal(t, []GrepMatch{
+		{File: "docs/a:b.txt", Line: 2, Text: "TODO: two"},
+		{File: "docs/a:b.txt", Line: 3, Text: "three TODO"},
+	}, matches)
+
+	matches, err = repo.Grep("TODO", 1)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+
+	matches, err = repo.Grep("nothing-matches-this", 0)
+	require.NoError(t, err)
+	require.Empty(t, matches)
+
+	_, err = repo.Grep(`\(`, 0)
+	require.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// clientHookNames lists the client-side hooks that can run while gitbatch
+// commits, pulls, merges, rebases or pushes.
+var clientHookNames = []string{
+	"pre-commit",
+	"prepare-commit-msg",
+	"commit-msg",
+	"post-commit",
+	"pre-merge-commit",
+	"post-merge",
+	"pre-rebase",
+	"post-rewrite",
+	"post-checkout",
+	"pre-push",
+	"reference-transaction",
+}
+
+// Hooks describes the client-side hooks git runs for a repository.
+type Hooks struct {
+	// Dir is the directory git looks for hooks in.
+	Dir string
+	// CustomPath is set when core.hooksPath points git to Dir.
+	CustomPath bool
+	// Active lists the names of the executable hooks found in Dir.
+	Active []string
+}
+
+// Enabled reports whether any hook will run during gitbatch operations.
+func (h *Hooks) Enabled() bool {
+	return h != nil && len(h.Active) > 0
+}
+
+// loadHooks resolves the hooks directory of the repository, honouring
+// core.hooksPath from every config scope, and collects the active hooks.
+func (r *Repository) loadHooks() error {
+	hooks := &Hooks{}
+	cmd := exec.Command("git", "rev-parse", "--git-path", "hooks")
+	cmd.Dir = r.AbsPath
+	if out, err := cmd.Output(); err == nil {
+		hooks.Dir = strings.TrimSpace(string(out))
+	}
+	if hooks.Dir == "" {
+		_, commonGitDir := r.GitDirs()
+		hooks.Dir = filepath.Join(commonGitDir, "hooks")
+	} else if !filepath.IsAbs(hooks.Dir) {
+		hooks.Dir = filepath.Join(r.AbsPath, hooks.Dir)
+	}
+	cmd = exec.Command("git", "config", "--get", "core.hooksPath")
+	cmd.Dir = r.AbsPath
+	if out, err := cmd.Output(); err == nil && strings.TrimSpace(string(out)) != "" {
+		hooks.CustomPath = true
+	}
+	for _, name := range clientHookNames {
+		info, err := os.Stat(filepath.Join(hooks.Dir, name))
+		if err != nil || info.IsDir() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		hooks.Active = append(hooks.Active, name)
+	}
+	r.Hooks = hooks
+	return nil
+}
@@ -0,0 +1,37 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadHooks_DetectsExecutableHooks(t *testing.T) {
+	repoPath := initLocalWorktreeRepo(t)
+	hooksDir := filepath.Join(repoPath, ".git", "hooks")
+	require.NoError(t, os.MkdirAll(hooksDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "pre-push"), []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "pre-commit"), []byte("#!/bin/sh\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "pre-commit.sample"), []byte("#!/bin/sh\n"), 0o755))
+
+	repo, err := InitializeRepo(repoPath)
+	require.NoError(t, err)
+	require.True(t, repo.Hooks.Enabled())
+	require.False(t, repo.Hooks.CustomPath)
+	require.Equal(t, []string{"pre-push"}, repo.Hooks.Active)
+}
+
+func TestLoadHooks_HonoursHooksPath(t *testing.T) {
+	repoPath := initLocalWorktreeRepo(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoPath, ".githooks"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, ".githooks", "commit-msg"), []byte("#!/bin/sh\n"), 0o755))
+	runGitCommand(t, repoPath, "config", "core.hooksPath", ".githooks")
+
+	repo, err := InitializeRepo(repoPath)
+	require.NoError(t, err)
+	require.True(t, repo.Hooks.CustomPath)
+	require.Equal(t, filepath.Join(repoPath, ".githooks"), repo.Hooks.Dir)
+	require.Equal(t, []string{"commit-msg"}, repo.Hooks.Active)
+}
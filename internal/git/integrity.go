@@ -0,0 +1,25 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// checkHeadCommit reads the commit HEAD points at, so that a repository with
+// a damaged object database fails to load instead of showing up half broken.
+func (r *Repository) checkHeadCommit() error {
+	head, err := r.Repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		// An unborn branch has no commit yet.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("HEAD: %w", err)
+	}
+	if _, err := r.Repo.CommitObject(head.Hash()); err != nil {
+		return fmt.Errorf("HEAD commit %s: %w", head.Hash(), err)
+	}
+	return nil
+}
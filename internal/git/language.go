@@ -0,0 +1,86 @@
+package git
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// detectLanguage enables the language detection on load. It costs a
+// `git ls-files` per repository, so it is off unless icons are shown.
+var detectLanguage atomic.Bool
+
+// SetDetectLanguage configures whether loading a repository detects its
+// dominant language.
+func SetDetectLanguage(enabled bool) {
+	detectLanguage.Store(enabled)
+}
+
+// languageExtensions maps file extensions to the language they are written
+// in. Extensions shared by many languages, like .h, are left out.
+var languageExtensions = map[string]string{
+	".go":    "Go",
+	".py":    "Python",
+	".rs":    "Rust",
+	".js":    "JavaScript",
+	".jsx":   "JavaScript",
+	".mjs":   "JavaScript",
+	".ts":    "TypeScript",
+	".tsx":   "TypeScript",
+	".java":  "Java",
+	".kt":    "Kotlin",
+	".kts":   "Kotlin",
+	".scala": "Scala",
+	".c":     "C",
+	".cc":    "C++",
+	".cpp":   "C++",
+	".cxx":   "C++",
+	".hpp":   "C++",
+	".cs":    "C#",
+	".rb":    "Ruby",
+	".php":   "PHP",
+	".swift": "Swift",
+	".sh":    "Shell",
+	".bash":  "Shell",
+	".lua":   "Lua",
+	".hs":    "Haskell",
+	".ex":    "Elixir",
+	".exs":   "Elixir",
+	".dart":  "Dart",
+}
+
+// loadLanguage detects the language most tracked files are written in. The
+// language of a repository rarely changes, so it is only detected once.
+func (r *Repository) loadLanguage() error {
+	if !detectLanguage.Load() || r.Language != "" {
+		return nil
+	}
+	cmd := exec.Command("git", "ls-files", "-z")
+	cmd.Dir = r.AbsPath
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	r.Language = dominantLanguage(strings.Split(string(out), "\x00"))
+	return nil
+}
+
+// dominantLanguage returns the language with the most files among paths, or
+// an empty string when none of them is source code.
+func dominantLanguage(paths []string) string {
+	counts := make(map[string]int)
+	for _, path := range paths {
+		if language, ok := languageExtensions[strings.ToLower(filepath.Ext(path))]; ok {
+			counts[language]++
+		}
+	}
+	dominant := ""
+	for language, count := range counts {
+		// Ties go to the alphabetically first language to keep the result stable.
+		if count > counts[dominant] || count == counts[dominant] && language < dominant {
+			dominant = language
+		}
+	}
+	return dominant
+}
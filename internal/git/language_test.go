@@ -0,0 +1,32 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDominantLanguage(t *testing.T) {
+	require.Equal(t, "Go", dominantLanguage([]string{"main.go", "util.go", "build.sh", "README.md"}))
+	require.Equal(t, "TypeScript", dominantLanguage([]string{"src/app.TSX", "src/index.ts", "vite.config.js"}))
+	require.Equal(t, "Python", dominantLanguage([]string{"setup.py", "run.sh"}), "ties are stable")
+	require.Empty(t, dominantLanguage([]string{"README.md", "LICENSE", ""}))
+}
+
+func TestLoadLanguage(t *testing.T) {
+	repoPath := initLocalWorktreeRepo(t)
+	repo, err := InitializeRepo(repoPath)
+	require.NoError(t, err)
+	require.Empty(t, repo.Language, "detection is off by default")
+
+	SetDetectLanguage(true)
+	t.Cleanup(func() { SetDetectLanguage(false) })
+	for _, name := range []string{"main.rs", "lib.rs"} {
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte("fn main() {}\n"), 0o644))
+	}
+	runGitCommand(t, repoPath, "add", ".")
+	require.NoError(t, repo.loadLanguage())
+	require.Equal(t, "Rust", repo.Language)
+}
@@ -0,0 +1,147 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// detectLocalDependencies enables reading the dependency manifests on load.
+var detectLocalDependencies atomic.Bool
+
+// SetDetectLocalDependencies configures whether loading a repository reads
+// its go.mod and package.json for dependencies on local directories.
+func SetDetectLocalDependencies(enabled bool) {
+	detectLocalDependencies.Store(enabled)
+}
+
+// loadLocalDependencies collects the directories outside the repository its
+// manifests point at: go.mod replace directives with a local path and
+// package.json workspaces and file: or link: dependencies. Manifests change
+// with pulls, so they are read on every refresh.
+func (r *Repository) loadLocalDependencies() error {
+	if !detectLocalDependencies.Load() {
+		r.LocalDependencies = nil
+		return nil
+	}
+	var paths []string
+	if data, err := os.ReadFile(filepath.Join(r.AbsPath, "go.mod")); err == nil {
+		paths = append(paths, goModLocalReplaces(data)...)
+	}
+	if data, err := os.ReadFile(filepath.Join(r.AbsPath, "package.json")); err == nil {
+		paths = append(paths, packageJSONLocalPaths(data)...)
+	}
+
+	root := filepath.Clean(r.AbsPath)
+	seen := make(map[string]bool)
+	var deps []string
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		path = filepath.Clean(path)
+		if path == root || strings.HasPrefix(path, root+string(filepath.Separator)) || seen[path] {
+			continue
+		}
+		seen[path] = true
+		deps = append(deps, path)
+	}
+	sort.Strings(deps)
+	r.LocalDependencies = deps
+	return nil
+}
+
+// goModLocalReplaces returns the local paths of the replace directives in a
+// go.mod, in both the single line and the block form.
+func goModLocalReplaces(data []byte) []string {
+	var paths []string
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case inBlock:
+		case line == "replace (":
+			inBlock = true
+			continue
+		case strings.HasPrefix(line, "replace "):
+			line = strings.TrimPrefix(line, "replace ")
+		default:
+			continue
+		}
+		_, target, ok := strings.Cut(line, "=>")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(target)
+		if len(fields) == 1 && isLocalModulePath(fields[0]) {
+			paths = append(paths, fields[0])
+		}
+	}
+	return paths
+}
+
+// isLocalModulePath reports whether a replacement is a directory, which go
+// recognizes by a leading ./ or ../ or an absolute path.
+func isLocalModulePath(path string) bool {
+	return strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") || filepath.IsAbs(path)
+}
+
+// packageJSON holds the parts of a package.json that name local directories.
+type packageJSON struct {
+	Workspaces           json.RawMessage   `json:"workspaces"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// packageJSONLocalPaths returns the workspaces of a package.json and the
+// directories of its file: and link: dependencies. Workspace globs are kept
+// up to their first wildcard, which is the directory they search.
+func packageJSONLocalPaths(data []byte) []string {
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+
+	var workspaces []string
+	if err := json.Unmarshal(pkg.Workspaces, &workspaces); err != nil {
+		var yarn struct {
+			Packages []string `json:"packages"`
+		}
+		if json.Unmarshal(pkg.Workspaces, &yarn) == nil {
+			workspaces = yarn.Packages
+		}
+	}
+	var paths []string
+	for _, workspace := range workspaces {
+		if i := strings.IndexAny(workspace, "*?["); i >= 0 {
+			workspace = filepath.Dir(workspace[:i] + "x")
+		}
+		paths = append(paths, workspace)
+	}
+
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.PeerDependencies, pkg.OptionalDependencies} {
+		for _, spec := range deps {
+			for _, prefix := range []string{"file:", "link:"} {
+				if path, ok := strings.CutPrefix(spec, prefix); ok && !strings.HasSuffix(path, ".tgz") {
+					paths = append(paths, path)
+				}
+			}
+		}
+	}
+	return paths
+}
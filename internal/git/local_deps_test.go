@@ -0,0 +1,50 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoModLocalReplaces(t *testing.T) {
+	gomod := `module example.com/app
+
+require example.com/lib v1.2.0
+
+replace example.com/lib => ../lib // local checkout
+
+replace (
+	example.com/proto v0.3.0 => ./../proto
+	example.com/fork => github.com/me/fork v1.0.0
+)
+`
+	require.Equal(t, []string{"../lib", "./../proto"}, goModLocalReplaces([]byte(gomod)))
+}
+
+func TestPackageJSONLocalPaths(t *testing.T) {
+	pkg := `{
+		"workspaces": ["packages/*", "../shared"],
+		"dependencies": {"lib": "file:../lib", "left-pad": "^1.3.0", "dist": "file:../dist/dist.tgz"},
+		"devDependencies": {"tools": "link:../tools"}
+	}`
+	require.ElementsMatch(t, []string{"packages", "../shared", "../lib", "../tools"}, packageJSONLocalPaths([]byte(pkg)))
+	require.Equal(t, []string{"../lib"}, packageJSONLocalPaths([]byte(`{"workspaces": {"packages": ["../lib"]}}`)))
+	require.Empty(t, packageJSONLocalPaths([]byte(`not json`)))
+}
+
+func TestLoadLocalDependencies(t *testing.T) {
+	repoPath := initLocalWorktreeRepo(t)
+	gomod := "module example.com/app\n\nreplace example.com/lib => ../lib\nreplace example.com/internal => ./internal\n"
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "go.mod"), []byte(gomod), 0o644))
+	repo, err := InitializeRepo(repoPath)
+	require.NoError(t, err)
+	require.Empty(t, repo.LocalDependencies, "detection is off by default")
+
+	SetDetectLocalDependencies(true)
+	t.Cleanup(func() { SetDetectLocalDependencies(false) })
+	require.NoError(t, repo.loadLocalDependencies())
+	require.Equal(t, []string{filepath.Join(filepath.Dir(repoPath), "lib")}, repo.LocalDependencies,
+		"directories inside the repository are left out")
+}
@@ -0,0 +1,146 @@
+package git
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LockFile is a lock file git leaves in the git directory while it updates
+// the index, a ref or the config. A crashed git process leaves it behind.
+type LockFile struct {
+	Path    string
+	ModTime time.Time
+}
+
+// Age returns how long the lock file has existed.
+func (l LockFile) Age() time.Duration {
+	return time.Since(l.ModTime)
+}
+
+// lockFileNames are the lock files git creates at the top of a git directory.
+var lockFileNames = []string{
+	"index.lock",
+	"HEAD.lock",
+	"ORIG_HEAD.lock",
+	"FETCH_HEAD.lock",
+	"config.lock",
+	"packed-refs.lock",
+	"shallow.lock",
+}
+
+// LockFiles returns the lock files currently present in the repository's
+// git directory, its common git directory and below refs/.
+func (r *Repository) LockFiles() []LockFile {
+	gitDir, commonGitDir := r.GitDirs()
+
+	var locks []LockFile
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if seen[path] {
+			return
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			return
+		}
+		seen[path] = true
+		locks = append(locks, LockFile{Path: path, ModTime: info.ModTime()})
+	}
+
+	for _, dir := range []string{gitDir, commonGitDir} {
+		for _, name := range lockFileNames {
+			add(filepath.Join(dir, name))
+		}
+	}
+	_ = filepath.WalkDir(filepath.Join(commonGitDir, "refs"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".lock") {
+			add(path)
+		}
+		return nil
+	})
+	return locks
+}
+
+// StaleLockFiles returns the lock files of the repository that no running git
+// process holds. Without a way to inspect processes no lock counts as stale,
+// however old it is: a long checkout or gc holds its lock for minutes.
+func (r *Repository) StaleLockFiles() []LockFile {
+	locks := r.LockFiles()
+	if len(locks) == 0 {
+		return nil
+	}
+	if running, known := r.gitProcessRunning(); running || !known {
+		return nil
+	}
+	return locks
+}
+
+// LockHoldersKnown reports whether StaleLockFiles can tell the locks of
+// running git processes from stale ones on this system.
+func LockHoldersKnown() bool {
+	_, err := os.Stat("/proc/self/cwd")
+	return err == nil
+}
+
+// gitProcessRunning reports whether a git process works in the repository by
+// looking at the working directories in /proc. known is false where /proc is
+// not available. Git processes whose working directory cannot be read count
+// as running.
+func (r *Repository) gitProcessRunning() (running, known bool) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return false, false
+	}
+	roots := []string{r.AbsPath, r.GitDir, r.CommonGitDir}
+	for i, root := range roots {
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			roots[i] = resolved
+		}
+	}
+	self := os.Getpid()
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == self {
+			continue
+		}
+		comm, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "comm"))
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSpace(string(comm))
+		if name != "git" && !strings.HasPrefix(name, "git-") {
+			continue
+		}
+		cwd, err := os.Readlink(filepath.Join("/proc", entry.Name(), "cwd"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return true, true
+		}
+		for _, root := range roots {
+			if root != "" && (cwd == root || strings.HasPrefix(cwd, root+string(filepath.Separator))) {
+				return true, true
+			}
+		}
+	}
+	return false, true
+}
+
+// RemoveLockFiles deletes the given lock files. It must only be called when no
+// git process is working on the repository anymore.
+func RemoveLockFiles(locks []LockFile) error {
+	for _, lock := range locks {
+		if err := os.Remove(lock.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFiles_FindsAndRemovesStaleLocks(t *testing.T) {
+	repoPath := initLocalWorktreeRepo(t)
+	repo, err := InitializeRepo(repoPath)
+	require.NoError(t, err)
+	require.Empty(t, repo.LockFiles())
+
+	indexLock := filepath.Join(repoPath, ".git", "index.lock")
+	refLock := filepath.Join(repoPath, ".git", "refs", "heads", "main.lock")
+	require.NoError(t, os.WriteFile(indexLock, nil, 0o644))
+	require.NoError(t, os.WriteFile(refLock, nil, 0o644))
+
+	locks := repo.LockFiles()
+	require.Len(t, locks, 2)
+
+	require.NoError(t, RemoveLockFiles(locks))
+	require.Empty(t, repo.LockFiles())
+	require.NoFileExists(t, indexLock)
+}
+
+func TestStaleLockFiles_IgnoresLocksOfRunningGit(t *testing.T) {
+	if _, err := os.Stat("/proc/self/cwd"); err != nil {
+		t.Skip("process inspection requires /proc")
+	}
+	repoPath := initLocalWorktreeRepo(t)
+	repo, err := InitializeRepo(repoPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, ".git", "index.lock"), nil, 0o644))
+	require.Len(t, repo.StaleLockFiles(), 1)
+
+	holder := exec.Command("git", "cat-file", "--batch")
+	holder.Dir = repoPath
+	stdin, err := holder.StdinPipe()
+	require.NoError(t, err)
+	require.NoError(t, holder.Start())
+	t.Cleanup(func() {
+		_ = stdin.Close()
+		_ = holder.Wait()
+	})
+
+	require.Empty(t, repo.StaleLockFiles())
+}
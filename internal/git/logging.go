@@ -13,20 +13,24 @@ import (
 )
 
 const (
-	traceLogFileName  = "gitbatch.log"
-	traceEventMaxData = 512
-	traceTimeFormat   = "2006-01-02T15:04:05.000000"
+	traceLogFileName     = "gitbatch.log"
+	traceJSONLogFileName = "gitbatch.jsonl"
+	traceEventMaxData    = 512
+	traceTimeFormat      = "2006-01-02T15:04:05.000000"
 )
 
 type traceSettings struct {
 	enabled bool
 	path    string
 	logger  *eventTraceLogger
+	json    bool
 }
 
 var (
 	traceSettingsMu sync.RWMutex
 	currentTrace    traceSettings
+	// traceJSON selects JSON lines for the next SetTraceLogging.
+	traceJSON bool
 )
 
 type eventTraceLogger struct {
@@ -55,8 +59,25 @@ func (l *eventTraceLogger) close() error {
 	return err
 }
 
+// SetTraceFormat selects how SetTraceLogging writes the trace log: "text"
+// lines in gitbatch.log, or "json" lines in gitbatch.jsonl for tools like jq.
+// An empty format is text.
+func SetTraceFormat(format string) error {
+	switch format {
+	case "", "text":
+	case "json":
+	default:
+		return fmt.Errorf("unknown trace format %q, use text or json", format)
+	}
+	traceSettingsMu.Lock()
+	traceJSON = format == "json"
+	traceSettingsMu.Unlock()
+	return nil
+}
+
 // SetTraceLogging enables or disables trace logging across repositories.
-// When enabled a gitbatch.log file is created in the current working directory.
+// When enabled a gitbatch.log file, or gitbatch.jsonl with the json format,
+// is created in the current working directory.
 func SetTraceLogging(enabled bool) error {
 	traceSettingsMu.Lock()
 	defer traceSettingsMu.Unlock()
@@ -74,7 +95,11 @@ func SetTraceLogging(enabled bool) error {
 		return err
 	}
 
-	path := filepath.Join(wd, traceLogFileName)
+	name := traceLogFileName
+	if traceJSON {
+		name = traceJSONLogFileName
+	}
+	path := filepath.Join(wd, name)
 	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
@@ -88,6 +113,7 @@ func SetTraceLogging(enabled bool) error {
 		enabled: true,
 		path:    path,
 		logger:  &eventTraceLogger{file: file},
+		json:    traceJSON,
 	}
 
 	return nil
@@ -104,8 +130,12 @@ type tracedEventPayload struct {
 	Repository string
 	Event      string
 	Summary    string
+	Operation  string
 	Source     eventQueueType
 	Timestamp  time.Time
+	// Done marks the end of a queued event, which took Duration to handle.
+	Done     bool
+	Duration time.Duration
 }
 
 func (p tracedEventPayload) format() string {
@@ -118,9 +148,43 @@ func (p tracedEventPayload) format() string {
 	if indicator != "" {
 		indicator += " "
 	}
+	if p.Done {
+		return fmt.Sprintf("%s %srepo=%s event=%s done duration=%s", timestamp, indicator, p.Repository, p.Event, p.Duration)
+	}
 	return fmt.Sprintf("%s %srepo=%s event=%s data=%s", timestamp, indicator, p.Repository, p.Event, summary)
 }
 
+// tracedEventRecord is a line of the JSON trace log. Duration, in seconds,
+// is only set on the record that ends a queued event.
+type tracedEventRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Repo      string    `json:"repo"`
+	Queue     string    `json:"queue"`
+	Event     string    `json:"event"`
+	Operation string    `json:"operation,omitempty"`
+	Data      string    `json:"data,omitempty"`
+	Done      bool      `json:"done,omitempty"`
+	Duration  float64   `json:"duration,omitempty"`
+}
+
+func (p tracedEventPayload) formatJSON() string {
+	record := tracedEventRecord{
+		Timestamp: p.Timestamp.UTC(),
+		Repo:      p.Repository,
+		Queue:     p.Source.String(),
+		Event:     p.Event,
+		Operation: p.Operation,
+		Data:      p.Summary,
+		Done:      p.Done,
+		Duration:  p.Duration.Seconds(),
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Sprintf(`{"event":%q,"error":%q}`, p.Event, err.Error())
+	}
+	return string(line)
+}
+
 func queueIndicator(source eventQueueType) string {
 	switch source {
 	case queueGit:
@@ -141,15 +205,38 @@ func (r *Repository) traceEvent(eventName string, source eventQueueType, data in
 		return
 	}
 
-	summary := sanitizeTraceValue(renderTraceData(data))
-	payload := tracedEventPayload{
+	r.enqueueTrace(logQueue, tracedEventPayload{
 		Repository: r.Name,
 		Event:      eventName,
-		Summary:    summary,
+		Summary:    sanitizeTraceValue(renderTraceData(data)),
+		Operation:  operationName(data),
 		Source:     source,
 		Timestamp:  time.Now().UTC(),
+	})
+}
+
+// traceEventDone records that a queued event was handled and how long its
+// listeners took.
+func (r *Repository) traceEventDone(event *RepositoryEvent, source eventQueueType, duration time.Duration) {
+	if r == nil || !isTraceEnabled() || event.Name == RepositoryEventTraced {
+		return
 	}
+	logQueue := r.queues[queueLog]
+	if logQueue == nil {
+		return
+	}
+	r.enqueueTrace(logQueue, tracedEventPayload{
+		Repository: r.Name,
+		Event:      event.Name,
+		Operation:  operationName(event.Data),
+		Source:     source,
+		Timestamp:  time.Now().UTC(),
+		Done:       true,
+		Duration:   duration,
+	})
+}
 
+func (r *Repository) enqueueTrace(logQueue *eventQueue, payload tracedEventPayload) {
 	logEvent := &RepositoryEvent{Name: RepositoryEventTraced, Data: payload}
 	if err := logQueue.enqueue(logEvent); err != nil {
 		log.Printf("log queue enqueue failed: %v", err)
@@ -224,11 +311,16 @@ func sanitizeTraceValue(value string) string {
 func writeTraceLine(payload tracedEventPayload) {
 	traceSettingsMu.RLock()
 	logger := currentTrace.logger
+	asJSON := currentTrace.json
 	traceSettingsMu.RUnlock()
 	if logger == nil {
 		return
 	}
-	if err := logger.write(payload.format()); err != nil {
+	line := payload.format()
+	if asJSON {
+		line = payload.formatJSON()
+	}
+	if err := logger.write(line); err != nil {
 		log.Printf("trace log write failed: %v", err)
 	}
 }
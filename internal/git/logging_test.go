@@ -0,0 +1,43 @@
+package git
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceLogWritesJSONLines(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.Error(t, SetTraceFormat("xml"))
+	require.NoError(t, SetTraceFormat("json"))
+	require.NoError(t, SetTraceLogging(true))
+	t.Cleanup(func() {
+		_ = SetTraceLogging(false)
+		_ = SetTraceFormat("")
+	})
+
+	start := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	writeTraceLine(tracedEventPayload{Repository: "api", Event: RepositoryEvaluationRequested, Summary: "outcome", Operation: "pull", Source: queueState, Timestamp: start})
+	writeTraceLine(tracedEventPayload{Repository: "api", Event: RepositoryEvaluationRequested, Operation: "pull", Source: queueState, Timestamp: start.Add(time.Second), Done: true, Duration: 1500 * time.Millisecond})
+
+	data, err := os.ReadFile(filepath.Join(dir, "gitbatch.jsonl"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var records []tracedEventRecord
+	for _, line := range lines {
+		var record tracedEventRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	require.Equal(t, tracedEventRecord{Timestamp: start, Repo: "api", Queue: "state", Event: RepositoryEvaluationRequested, Operation: "pull", Data: "outcome"}, records[0])
+	require.True(t, records[1].Done)
+	require.Equal(t, 1.5, records[1].Duration)
+}
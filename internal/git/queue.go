@@ -0,0 +1,74 @@
+package git
+
+// QueueStats describes the backlog of one event queue of a repository.
+type QueueStats struct {
+	Name     string
+	Depth    int
+	Capacity int
+	// Blocked counts the events whose publisher had to wait for a free slot.
+	Blocked uint64
+	// Dropped counts the events that were coalesced with a waiting one or, for
+	// the trace log, discarded because the queue was full.
+	Dropped uint64
+}
+
+// QueueStats returns the depth and overflow counters of the git, state and
+// (with trace logging) log queues of the repository.
+func (r *Repository) QueueStats() []QueueStats {
+	if r == nil {
+		return nil
+	}
+	var stats []QueueStats
+	for _, kind := range []eventQueueType{queueGit, queueState, queueLog} {
+		q := r.queues[kind]
+		if q == nil || q.events == nil {
+			continue
+		}
+		stats = append(stats, QueueStats{
+			Name:     q.kind.String(),
+			Depth:    len(q.events),
+			Capacity: cap(q.events),
+			Blocked:  q.blocked.Load(),
+			Dropped:  q.dropped.Load(),
+		})
+	}
+	return stats
+}
+
+func (k eventQueueType) String() string {
+	switch k {
+	case queueGit:
+		return "git"
+	case queueState:
+		return "state"
+	case queueLog:
+		return "log"
+	}
+	return "unknown"
+}
+
+// reserve claims the single waiting slot of a coalescing event. It reports
+// false when an event of the same name already waits.
+func (q *eventQueue) reserve(name string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.waiting[name] > 0 {
+		return false
+	}
+	if q.waiting == nil {
+		q.waiting = make(map[string]int)
+	}
+	q.waiting[name]++
+	return true
+}
+
+// release frees the waiting slot of a coalescing event once it leaves the
+// queue.
+func (q *eventQueue) release(event *RepositoryEvent) {
+	if !event.coalesce {
+		return
+	}
+	q.mu.Lock()
+	q.waiting[event.Name]--
+	q.mu.Unlock()
+}
@@ -0,0 +1,48 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventQueueCoalescesWaitingEvents(t *testing.T) {
+	q := &eventQueue{kind: queueState, events: make(chan *RepositoryEvent, 4)}
+
+	require.NoError(t, q.enqueue(&RepositoryEvent{Name: "probe", coalesce: true}))
+	require.NoError(t, q.enqueue(&RepositoryEvent{Name: "probe", coalesce: true}))
+	require.NoError(t, q.enqueue(&RepositoryEvent{Name: "result"}))
+	assert.Len(t, q.events, 2)
+	assert.Equal(t, uint64(1), q.dropped.Load())
+
+	q.release(<-q.events)
+	require.NoError(t, q.enqueue(&RepositoryEvent{Name: "probe", coalesce: true}))
+	assert.Len(t, q.events, 2, "a probe that left the queue no longer coalesces")
+}
+
+func TestEventQueueOverflowPolicies(t *testing.T) {
+	logQueue := &eventQueue{kind: queueLog, events: make(chan *RepositoryEvent, 1)}
+	require.NoError(t, logQueue.enqueue(&RepositoryEvent{Name: "trace"}))
+	require.NoError(t, logQueue.enqueue(&RepositoryEvent{Name: "trace"}))
+	assert.Equal(t, uint64(1), logQueue.dropped.Load(), "a full log queue drops trace lines")
+
+	gitQueue := &eventQueue{kind: queueGit, events: make(chan *RepositoryEvent, 1)}
+	require.NoError(t, gitQueue.enqueue(&RepositoryEvent{Name: "fetch"}))
+	done := make(chan struct{})
+	go func() {
+		_ = gitQueue.enqueue(&RepositoryEvent{Name: "pull"})
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return gitQueue.blocked.Load() == 1 }, time.Second, time.Millisecond)
+	<-gitQueue.events
+	<-done
+	assert.Len(t, gitQueue.events, 1, "a full git queue makes the publisher wait")
+
+	r := &Repository{queues: map[eventQueueType]*eventQueue{queueGit: gitQueue, queueLog: logQueue}}
+	assert.Equal(t, []QueueStats{
+		{Name: "git", Depth: 1, Capacity: 1, Blocked: 1},
+		{Name: "log", Depth: 1, Capacity: 1, Dropped: 1},
+	}, r.QueueStats())
+}
@@ -0,0 +1,54 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ReflogEntry is a position HEAD pointed to, newest first in the reflog.
+type ReflogEntry struct {
+	Hash string
+	// Selector addresses the entry, e.g. HEAD@{2}.
+	Selector string
+	// Action describes how HEAD got there, e.g. "reset: moving to HEAD~3".
+	Action string
+	// When is the relative time of the move.
+	When    string
+	Subject string
+}
+
+// LoadReflog returns at most limit entries of the HEAD reflog; limit <= 0
+// returns all of them. A repository without reflog has no entries.
+func (r *Repository) LoadReflog(limit int) ([]*ReflogEntry, error) {
+	args := []string{"reflog", "show", "--format=%h%x00%gd%x00%gs%x00%cr%x00%s"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("-n%d", limit))
+	}
+	cmd := exec.Command("git", append(args, "HEAD")...)
+	cmd.Dir = r.AbsPath
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("reflog: %s", NormalizeGitErrorMessage(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("reflog: %w", err)
+	}
+	var entries []*ReflogEntry
+	for _, line := range strings.Split(string(out), "\n") {
+		parts := strings.SplitN(line, "\x00", 5)
+		if len(parts) != 5 {
+			continue
+		}
+		entries = append(entries, &ReflogEntry{
+			Hash:     parts[0],
+			Selector: parts[1],
+			Action:   parts[2],
+			When:     parts[3],
+			Subject:  parts[4],
+		})
+	}
+	return entries, nil
+}
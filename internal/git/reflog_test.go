@@ -0,0 +1,26 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadReflog(t *testing.T) {
+	repoPath := initLocalWorktreeRepo(t)
+	runGitCommand(t, repoPath, "commit", "--allow-empty", "-m", "second")
+	runGitCommand(t, repoPath, "reset", "--hard", "HEAD~1")
+	repo, err := InitializeRepo(repoPath)
+	require.NoError(t, err)
+
+	entries, err := repo.LoadReflog(0)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(entries), 3)
+	require.Equal(t, "HEAD@{0}", entries[0].Selector)
+	require.Equal(t, "reset: moving to HEAD~1", entries[0].Action)
+	require.Equal(t, "second", entries[1].Subject)
+
+	entries, err = repo.LoadReflog(1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -1,6 +1,6 @@
 package git
 
-import "fmt"
+import "strings"
 
 // Remote struct is simply a collection of remote branches and wraps it with the
 // name of the remote and fetch/push urls. It also holds the *selected* remote
@@ -51,8 +51,69 @@ func (r *Repository) initRemotes() error {
 	}
 
 	if len(r.Remotes) <= 0 {
-		return fmt.Errorf("no remote for repository: %s", r.Name)
+		// Local-only repositories are still loaded; network operations check
+		// HasRemote and skip them.
+		r.State.Remote = nil
+		return nil
 	}
 	r.State.Remote = r.Remotes[0]
 	return nil
 }
+
+// HasRemote reports whether the repository has at least one remote configured.
+func (r *Repository) HasRemote() bool {
+	return r != nil && len(r.Remotes) > 0
+}
+
+// ForkRemotes returns the remotes of a fork: upstream, the repository it was
+// forked from, and origin, the fork itself. ok is false unless both exist.
+func (r *Repository) ForkRemotes() (upstream, origin *Remote, ok bool) {
+	if r == nil {
+		return nil, nil, false
+	}
+	for _, remote := range r.Remotes {
+		switch remote.Name {
+		case "upstream":
+			upstream = remote
+		case "origin":
+			origin = remote
+		}
+	}
+	return upstream, origin, upstream != nil && origin != nil
+}
+
+// RemoteHost extracts the host name from a remote URL in any of the forms git
+// accepts: https://host/org/repo, ssh://git@host:22/repo or git@host:org/repo.
+func RemoteHost(url string) string {
+	host := strings.TrimSpace(url)
+	host = strings.TrimPrefix(host, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	host = strings.TrimPrefix(host, "ssh://")
+	if at := strings.Index(host, "@"); at != -1 {
+		if slash := strings.Index(host, "/"); slash == -1 || at < slash {
+			host = host[at+1:]
+		}
+	}
+	if idx := strings.Index(host, "/"); idx != -1 {
+		host = host[:idx]
+	}
+	if idx := strings.Index(host, ":"); idx != -1 {
+		host = host[:idx]
+	}
+	return host
+}
+
+// IsSSHURL reports whether a remote URL is reached over ssh, either as
+// ssh://host/repo or in the scp-like form user@host:repo.
+func IsSSHURL(url string) bool {
+	url = strings.TrimSpace(url)
+	if strings.HasPrefix(url, "ssh://") || strings.HasPrefix(url, "git+ssh://") {
+		return true
+	}
+	if strings.Contains(url, "://") {
+		return false
+	}
+	colon := strings.Index(url, ":")
+	slash := strings.Index(url, "/")
+	return colon > 1 && (slash == -1 || colon < slash)
+}
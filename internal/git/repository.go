@@ -8,9 +8,11 @@ import (
 	"path/filepath"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
 )
@@ -30,12 +32,28 @@ type Repository struct {
 	Remotes      []*Remote
 	Stasheds     []*StashedItem
 	Worktrees    []*Worktree
-	State        *RepositoryState
+	Hooks        *Hooks
+	Sparse       *SparseCheckout
+	Shallow      bool
+	Language     string
+	// LocalDependencies lists the directories outside the repository its
+	// manifests depend on, see SetDetectLocalDependencies.
+	LocalDependencies []string
+	State             *RepositoryState
+	// Enclosing is the loaded repository whose working tree contains this
+	// one, nil unless the repository is nested in another one.
+	Enclosing *Repository
+
+	// componentsMu keeps a reload of the branches and remotes apart from
+	// the cleanliness pass that marks them.
+	componentsMu sync.Mutex
 
 	mutex     sync.RWMutex
 	listeners map[string][]RepositoryListener
 	queues    map[eventQueueType]*eventQueue
 
+	transitionHooks []TransitionHook
+
 	watchSuppressCount      int
 	watchSuppressGraceUntil time.Time
 }
@@ -47,12 +65,21 @@ const watchSuppressGrace = 1 * time.Second
 
 // RepositoryState is the current pointers of a repository
 type RepositoryState struct {
+	// mu guards workStatus and message, which the git and state queues
+	// write while the interface reads them.
+	mu                  sync.RWMutex
 	workStatus          WorkStatus
+	message             string
 	Branch              *Branch
 	Remote              *Remote
-	Message             string
 	RequiresCredentials bool
 	NoUpstream          bool
+	// Failure is the kind of the error the last operation failed with. A
+	// conflict outlives the failure until the working tree is clean again.
+	Failure gerr.Kind
+	// PullRequestURL is the page to open a pull request for the branch the
+	// last push created on the remote.
+	PullRequestURL string
 }
 
 // RepositoryListener is a type for listeners
@@ -64,6 +91,8 @@ type RepositoryEvent struct {
 	Name    string
 	Data    any
 	Context context.Context
+
+	coalesce bool
 }
 
 // WorkStatus is the state of the repository for an operation
@@ -126,6 +155,11 @@ type eventQueue struct {
 	events  chan *RepositoryEvent
 	started bool
 	handler func(*RepositoryEvent)
+
+	// waiting counts the coalescing events per name that are still queued.
+	waiting map[string]int
+	blocked atomic.Uint64
+	dropped atomic.Uint64
 }
 
 var (
@@ -208,7 +242,7 @@ func FastInitializeRepo(dir string) (r *Repository, err error) {
 		Repo:    *rp,
 		State: &RepositoryState{
 			workStatus: Pending,
-			Message:    "waiting",
+			message:    "waiting",
 		},
 		listeners: make(map[string][]RepositoryListener),
 	}
@@ -227,7 +261,17 @@ func InitializeRepo(dir string) (r *Repository, err error) {
 	return r, r.loadComponents()
 }
 
-// loadComponents initializes branches, remotes, and stashed items for a repository.
+// LoadComponents loads the branches, remotes, stashes and worktrees of a
+// repository created by FastInitializeRepo.
+func (r *Repository) LoadComponents() error {
+	r.componentsMu.Lock()
+	defer r.componentsMu.Unlock()
+	return r.loadComponents()
+}
+
+// loadComponents initializes branches, remotes, stashed items, worktrees,
+// hooks, the sparse-checkout, the shallow flag, the language and the local
+// dependencies for a repository.
 func (r *Repository) loadComponents() error {
 	// initRemotes must complete before initBranches: branch upstream lookup
 	// reads r.Remotes, so running them concurrently causes a race where
@@ -235,16 +279,26 @@ func (r *Repository) loadComponents() error {
 	if err := r.initRemotes(); err != nil {
 		return err
 	}
+	if err := r.checkHeadCommit(); err != nil {
+		return err
+	}
 	var eg errgroup.Group
 	eg.Go(r.initBranches)
 	eg.Go(r.loadStashedItems)
 	eg.Go(r.loadWorktrees)
+	eg.Go(r.loadHooks)
+	eg.Go(r.loadSparseCheckout)
+	eg.Go(r.loadShallow)
+	eg.Go(r.loadLanguage)
+	eg.Go(r.loadLocalDependencies)
 	return eg.Wait()
 }
 
 // Refresh the belongings of a repository, this function is called right after
 // fetch/pull/merge operations
 func (r *Repository) Refresh() error {
+	r.componentsMu.Lock()
+	defer r.componentsMu.Unlock()
 	// if the Repository is only fast initialized, no need to refresh because
 	// it won't contain its belongings
 	if r.State.Branch == nil {
@@ -271,6 +325,17 @@ func (r *Repository) Refresh() error {
 	return nil
 }
 
+// LockComponents holds off reloading the branches and remotes until
+// UnlockComponents is called.
+func (r *Repository) LockComponents() {
+	r.componentsMu.Lock()
+}
+
+// UnlockComponents releases the lock taken by LockComponents.
+func (r *Repository) UnlockComponents() {
+	r.componentsMu.Unlock()
+}
+
 // RequestRefresh schedules a metadata refresh via the repository's event queue.
 func (r *Repository) RequestRefresh() error {
 	return r.Publish(RepositoryRefreshRequested, nil)
@@ -325,13 +390,35 @@ func (r *Repository) On(event string, listener RepositoryListener) {
 // Publish publishes the data to a certain event by its name.
 // Events are either queued for async dispatch or handled synchronously.
 func (r *Repository) Publish(eventName string, data any) error {
+	return r.PublishContext(context.Background(), eventName, data)
+}
+
+// PublishCoalesced publishes an event that is dropped while an event of the
+// same name, published this way, still waits in the queue. Use it for
+// requests whose payload carries no result, like re-evaluating the state.
+func (r *Repository) PublishCoalesced(eventName string, data any) error {
+	return r.publish(&RepositoryEvent{Name: eventName, Data: data, Context: context.Background(), coalesce: true})
+}
+
+// PublishContext publishes an event that carries ctx to its listeners. A
+// queued git event whose context is cancelled before it gets a slot on the
+// git queue is still dispatched, so the listener can settle the repository
+// without running git.
+func (r *Repository) PublishContext(ctx context.Context, eventName string, data any) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return r.publish(&RepositoryEvent{Name: eventName, Data: data, Context: ctx})
+}
+
+func (r *Repository) publish(event *RepositoryEvent) error {
 	if r == nil {
 		return fmt.Errorf("repository not initialized")
 	}
-	if eventName == "" {
+	if event.Name == "" {
 		return fmt.Errorf("event name required")
 	}
-	event := &RepositoryEvent{Name: eventName, Data: data, Context: context.Background()}
+	eventName, data := event.Name, event.Data
 
 	queue := r.queueForEvent(eventName)
 	if queue == nil {
@@ -395,6 +482,23 @@ func (q *eventQueue) enqueue(event *RepositoryEvent) error {
 		if event.Context == nil {
 			event.Context = context.Background()
 		}
+		if event.coalesce && !q.reserve(event.Name) {
+			q.dropped.Add(1)
+			return nil
+		}
+		select {
+		case q.events <- event:
+			return nil
+		default:
+		}
+		// The queue is full. Trace lines are not worth stalling the
+		// publisher for; everything else waits for a free slot.
+		if q.kind == queueLog {
+			q.release(event)
+			q.dropped.Add(1)
+			return nil
+		}
+		q.blocked.Add(1)
 		q.events <- event
 		return nil
 	}
@@ -407,6 +511,7 @@ func (q *eventQueue) enqueue(event *RepositoryEvent) error {
 
 func (q *eventQueue) run() {
 	for event := range q.events {
+		q.release(event)
 		if q.handler != nil {
 			q.handler(event)
 		}
@@ -415,16 +520,21 @@ func (q *eventQueue) run() {
 
 func (q *eventQueue) handleGitEvent(event *RepositoryEvent) {
 	go func() {
+		if event.Context == nil {
+			event.Context = context.Background()
+		}
 		sem := gitSemaphore()
-		if err := sem.Acquire(context.Background(), 1); err != nil {
+		if err := sem.Acquire(event.Context, 1); err == nil {
+			defer sem.Release(1)
+		} else if event.Context.Err() == nil {
 			log.Printf("git queue acquire failed: %v", err)
 			return
 		}
-		defer sem.Release(1)
-		event.Context = context.Background()
+		start := time.Now()
 		if err := q.dispatch(event); err != nil && err != context.Canceled && err != context.DeadlineExceeded {
 			log.Printf("git queue event %s failed: %v", event.Name, err)
 		}
+		q.repo.traceEventDone(event, q.kind, time.Since(start))
 	}()
 }
 
@@ -441,9 +551,11 @@ func (q *eventQueue) handleStateEvent(event *RepositoryEvent) {
 	if event.Context == nil {
 		event.Context = context.Background()
 	}
+	start := time.Now()
 	if err := q.dispatch(event); err != nil && err != context.Canceled && err != context.DeadlineExceeded {
 		log.Printf("state queue event %s failed: %v", event.Name, err)
 	}
+	q.repo.traceEventDone(event, q.kind, time.Since(start))
 }
 
 func (q *eventQueue) dispatch(event *RepositoryEvent) error {
@@ -462,12 +574,60 @@ func (q *eventQueue) dispatch(event *RepositoryEvent) error {
 	return nil
 }
 
+// Message returns the status message of the repository.
+func (s *RepositoryState) Message() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.message
+}
+
+// SetMessage sets the status message of the repository.
+func (s *RepositoryState) SetMessage(message string) {
+	s.mu.Lock()
+	s.message = message
+	s.mu.Unlock()
+}
+
+// Copy returns a copy of the state, e.g. to put it back with Restore.
+func (s *RepositoryState) Copy() *RepositoryState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &RepositoryState{
+		workStatus:          s.workStatus,
+		message:             s.message,
+		Branch:              s.Branch,
+		Remote:              s.Remote,
+		RequiresCredentials: s.RequiresCredentials,
+		NoUpstream:          s.NoUpstream,
+		Failure:             s.Failure,
+		PullRequestURL:      s.PullRequestURL,
+	}
+}
+
+// Restore puts the state back to saved, a copy taken with Copy.
+func (s *RepositoryState) Restore(saved *RepositoryState) {
+	saved = saved.Copy()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.workStatus = saved.workStatus
+	s.message = saved.message
+	s.Branch = saved.Branch
+	s.Remote = saved.Remote
+	s.RequiresCredentials = saved.RequiresCredentials
+	s.NoUpstream = saved.NoUpstream
+	s.Failure = saved.Failure
+	s.PullRequestURL = saved.PullRequestURL
+}
+
 // WorkStatus returns the state of the repository such as queued, failed etc.
 func (r *Repository) WorkStatus() WorkStatus {
+	r.State.mu.RLock()
+	defer r.State.mu.RUnlock()
 	return r.State.workStatus
 }
 
-// SetWorkStatus sets the state of repository and sends repository updated event
+// SetWorkStatus sets the state of repository and sends repository updated event.
+// Transitions the state machine does not allow are logged and ignored.
 func (r *Repository) SetWorkStatus(ws WorkStatus) {
 	r.setWorkStatus(ws, true)
 }
@@ -483,11 +643,21 @@ func (r *Repository) setWorkStatus(ws WorkStatus, notify bool) {
 	if r.State == nil {
 		return
 	}
+	r.State.mu.Lock()
 	prev := r.State.workStatus
-	r.State.workStatus = ws
 	if prev == ws {
+		r.State.mu.Unlock()
 		return
 	}
+	if !CanTransition(prev, ws) {
+		r.State.mu.Unlock()
+		r.rejectTransition(prev, ws)
+		return
+	}
+	r.State.workStatus = ws
+	r.State.mu.Unlock()
+	r.traceEvent(WorkStatusTransitioned, queueState, fmt.Sprintf("%s -> %s", prev, ws))
+	r.runTransitionHooks(prev, ws)
 	if notify {
 		r.NotifyRepositoryUpdated()
 	}
@@ -551,14 +721,7 @@ func (r *Repository) String() string {
 // It returns the latest modification time found.
 func (r *Repository) RefreshModTime() time.Time {
 	latest := r.ModTime
-	gitDir := r.GitDir
-	if gitDir == "" {
-		gitDir = filepath.Join(r.AbsPath, ".git")
-	}
-	commonGitDir := r.CommonGitDir
-	if commonGitDir == "" {
-		commonGitDir = gitDir
-	}
+	gitDir, commonGitDir := r.GitDirs()
 
 	checkPath := func(root, path string) {
 		info, err := os.Stat(filepath.Join(root, path))
@@ -570,7 +733,8 @@ func (r *Repository) RefreshModTime() time.Time {
 	// Check critical git files that indicate state changes
 	checkPath(gitDir, "HEAD")
 	checkPath(gitDir, "index")
-	checkPath(commonGitDir, "FETCH_HEAD")
+	// FETCH_HEAD belongs to the worktree, branches are shared.
+	checkPath(gitDir, "FETCH_HEAD")
 	if r.State != nil && r.State.Branch != nil {
 		checkPath(commonGitDir, "refs/heads/"+r.State.Branch.Name)
 	}
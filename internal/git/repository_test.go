@@ -66,3 +66,17 @@ func TestRefreshModTime_BranchSwitch(t *testing.T) {
 
 	assert.True(t, modTime2.After(modTime1), "ModTime should increase when HEAD symbolic ref changes")
 }
+
+func TestInitializeRepo_WithoutRemote(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "local")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	runGitCommand(t, dir, "init", "--initial-branch=main")
+	runGitCommand(t, dir, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "init")
+
+	repo, err := InitializeRepo(dir)
+	require.NoError(t, err)
+	require.False(t, repo.HasRemote())
+	require.Nil(t, repo.State.Remote)
+	require.Empty(t, repo.Remotes)
+	require.NotNil(t, repo.State.Branch)
+}
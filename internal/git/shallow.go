@@ -0,0 +1,18 @@
+package git
+
+import "strings"
+
+// IsShallow reports whether the repository is a shallow clone. Its history
+// ends at the grafted commits, so ahead/behind counts and merge bases can be
+// wrong until it is unshallowed.
+func (r *Repository) IsShallow() bool {
+	return r != nil && r.Shallow
+}
+
+// loadShallow asks git whether the repository is a shallow clone. Linked
+// worktrees share the answer of the main worktree.
+func (r *Repository) loadShallow() error {
+	out, err := r.runGit("rev-parse", "--is-shallow-repository")
+	r.Shallow = err == nil && strings.TrimSpace(out) == "true"
+	return nil
+}
@@ -0,0 +1,26 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadShallow(t *testing.T) {
+	repoPath := initLocalWorktreeRepo(t)
+	repo, err := InitializeRepo(repoPath)
+	require.NoError(t, err)
+	require.False(t, repo.IsShallow())
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("second"), 0o644))
+	runGitCommand(t, repoPath, "commit", "-am", "second commit")
+	runGitCommand(t, repoPath, "push", "origin", "main")
+	clonePath := filepath.Join(filepath.Dir(repoPath), "clone")
+	runGitCommand(t, filepath.Dir(repoPath), "clone", "--depth", "1", "--branch", "main", "file://"+filepath.Join(filepath.Dir(repoPath), "remote.git"), clonePath)
+
+	clone, err := InitializeRepo(clonePath)
+	require.NoError(t, err)
+	require.True(t, clone.IsShallow())
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Snapshot records the parts of a repository an external tool such as
+// lazygit typically changes: the checked out branch, the HEAD commit, the
+// index and the refs tracked by RefreshModTime. Comparing two snapshots is
+// far cheaper than a full Refresh.
+type Snapshot struct {
+	Branch       string
+	Head         plumbing.Hash
+	IndexModTime time.Time
+	ModTime      time.Time
+}
+
+// TakeSnapshot reads the current HEAD and index state from disk.
+func (r *Repository) TakeSnapshot() Snapshot {
+	s := Snapshot{ModTime: r.RefreshModTime()}
+	gitDir, _ := r.GitDirs()
+	if info, err := os.Stat(filepath.Join(gitDir, "index")); err == nil {
+		s.IndexModTime = info.ModTime()
+	}
+	if r.Repo.Storer == nil {
+		return s
+	}
+	if head, err := r.Repo.Head(); err == nil {
+		s.Head = head.Hash()
+		if head.Name().IsBranch() {
+			s.Branch = head.Name().Short()
+		}
+	}
+	return s
+}
+
+// Changed reports whether anything recorded in the snapshots differs.
+func (s Snapshot) Changed(other Snapshot) bool {
+	return s.Branch != other.Branch ||
+		s.Head != other.Head ||
+		!s.IndexModTime.Equal(other.IndexModTime) ||
+		!s.ModTime.Equal(other.ModTime)
+}
+
+// DescribeChange summarizes how other differs from s, e.g.
+// "branch changed from main to feature".
+func (s Snapshot) DescribeChange(other Snapshot) string {
+	switch {
+	case s.Branch != other.Branch:
+		return "branch changed from " + s.headLabel() + " to " + other.headLabel()
+	case s.Head != other.Head:
+		return "HEAD moved from " + shortHash(s.Head) + " to " + shortHash(other.Head)
+	case !s.IndexModTime.Equal(other.IndexModTime):
+		return "index changed"
+	case !s.ModTime.Equal(other.ModTime):
+		return "refs changed"
+	default:
+		return "no changes"
+	}
+}
+
+func (s Snapshot) headLabel() string {
+	if s.Branch != "" {
+		return s.Branch
+	}
+	return "detached " + shortHash(s.Head)
+}
+
+func shortHash(h plumbing.Hash) string {
+	if h.IsZero() {
+		return "(none)"
+	}
+	return h.String()[:7]
+}
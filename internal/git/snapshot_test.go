@@ -0,0 +1,39 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotDetectsBranchSwitch(t *testing.T) {
+	basePath := initLocalWorktreeRepo(t)
+	repo, err := InitializeRepo(basePath)
+	require.NoError(t, err)
+
+	before := repo.TakeSnapshot()
+	require.Equal(t, "main", before.Branch)
+	require.False(t, before.Changed(repo.TakeSnapshot()))
+
+	runGitCommand(t, basePath, "checkout", "-b", "feature")
+	after := repo.TakeSnapshot()
+	require.True(t, before.Changed(after))
+	require.Equal(t, "branch changed from main to feature", before.DescribeChange(after))
+}
+
+func TestSnapshotDetectsNewCommit(t *testing.T) {
+	basePath := initLocalWorktreeRepo(t)
+	repo, err := InitializeRepo(basePath)
+	require.NoError(t, err)
+
+	before := repo.TakeSnapshot()
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "new.txt"), []byte("new"), 0o644))
+	runGitCommand(t, basePath, "add", "new.txt")
+	runGitCommand(t, basePath, "commit", "-m", "second")
+
+	after := repo.TakeSnapshot()
+	require.True(t, before.Changed(after))
+	require.Contains(t, before.DescribeChange(after), "HEAD moved from "+before.Head.String()[:7])
+}
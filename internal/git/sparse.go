@@ -0,0 +1,49 @@
+package git
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// SparseCheckout describes the sparse-checkout of a worktree. Repositories
+// that check out all files have none.
+type SparseCheckout struct {
+	// Cone is set when the patterns select whole directories (cone mode).
+	Cone bool
+	// Patterns lists the directories or patterns that are checked out.
+	Patterns []string
+}
+
+// IsSparse reports whether the worktree is a sparse checkout.
+func (r *Repository) IsSparse() bool {
+	return r != nil && r.Sparse != nil
+}
+
+// loadSparseCheckout reads the sparse-checkout patterns of the worktree. Git
+// refuses to list them when the worktree is not sparse.
+func (r *Repository) loadSparseCheckout() error {
+	if !Supports(FeatureSparseCheckout) {
+		r.Sparse = nil
+		return nil
+	}
+	cmd := exec.Command("git", "sparse-checkout", "list")
+	cmd.Dir = r.AbsPath
+	out, err := cmd.Output()
+	if err != nil {
+		r.Sparse = nil
+		return nil
+	}
+	sparse := &SparseCheckout{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			sparse.Patterns = append(sparse.Patterns, line)
+		}
+	}
+	cmd = exec.Command("git", "config", "--get", "--bool", "core.sparseCheckoutCone")
+	cmd.Dir = r.AbsPath
+	if out, err := cmd.Output(); err == nil {
+		sparse.Cone = strings.TrimSpace(string(out)) == "true"
+	}
+	r.Sparse = sparse
+	return nil
+}
@@ -0,0 +1,20 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSparseCheckout(t *testing.T) {
+	repoPath := initLocalWorktreeRepo(t)
+	repo, err := InitializeRepo(repoPath)
+	require.NoError(t, err)
+	require.False(t, repo.IsSparse())
+
+	runGitCommand(t, repoPath, "sparse-checkout", "set", "docs", "src")
+	require.NoError(t, repo.loadSparseCheckout())
+	require.True(t, repo.IsSparse())
+	require.True(t, repo.Sparse.Cone)
+	require.Equal(t, []string{"docs", "src"}, repo.Sparse.Patterns)
+}
@@ -36,6 +36,7 @@ func (r *Repository) MarkClean() {
 		return
 	}
 	r.State.NoUpstream = false
+	r.State.Failure = ""
 	r.State.Branch.Clean = true
 	r.State.Branch.HasLocalChanges = false
 	r.syncBranchCleanState(true, false)
@@ -49,6 +50,7 @@ func (r *Repository) MarkLocalChanges() {
 		return
 	}
 	r.State.NoUpstream = false
+	r.State.Failure = ""
 	r.State.Branch.Clean = true
 	r.State.Branch.HasLocalChanges = true
 	r.syncBranchCleanState(true, true)
@@ -56,7 +58,13 @@ func (r *Repository) MarkLocalChanges() {
 
 // MarkCriticalError transitions the repository into a critical error state.
 func (r *Repository) MarkCriticalError(message string) {
-	r.markErrorState(message)
+	r.markErrorState(gerr.KindFatal, message)
+}
+
+// MarkFailure transitions the repository into an error state of the given
+// kind, e.g. when its remote cannot be reached.
+func (r *Repository) MarkFailure(kind gerr.Kind, message string) {
+	r.markErrorState(kind, message)
 }
 
 // MarkNoUpstream transitions the repository into a state where the upstream
@@ -68,11 +76,12 @@ func (r *Repository) MarkNoUpstream(message string) {
 	}
 	r.State.NoUpstream = true
 	r.State.RequiresCredentials = false
+	r.State.Failure = ""
 	trimmed := strings.TrimSpace(message)
 	if trimmed != "" {
-		r.State.Message = trimmed
+		r.State.SetMessage(trimmed)
 	} else {
-		r.State.Message = "upstream not configured"
+		r.State.SetMessage("upstream not configured")
 	}
 	r.SetWorkStatus(Fail)
 }
@@ -84,6 +93,9 @@ func (r *Repository) MarkRequiresCredentials(message string) {
 	}
 
 	r.MarkDisabled()
+	if r.State != nil {
+		r.State.Failure = gerr.KindAuth
+	}
 	r.SetWorkStatus(Fail)
 	if r.State == nil {
 		return
@@ -92,17 +104,20 @@ func (r *Repository) MarkRequiresCredentials(message string) {
 	r.State.NoUpstream = false
 	trimmed := strings.TrimSpace(message)
 	if trimmed != "" {
-		r.State.Message = trimmed
+		r.State.SetMessage(trimmed)
 	} else {
-		r.State.Message = "authentication required"
+		r.State.SetMessage("authentication required")
 	}
 }
 
-func (r *Repository) markErrorState(message string) {
+func (r *Repository) markErrorState(kind gerr.Kind, message string) {
 	if r == nil {
 		return
 	}
 
+	if r.State != nil {
+		r.State.Failure = kind
+	}
 	r.SetWorkStatus(Fail)
 	if r.State == nil {
 		return
@@ -111,7 +126,7 @@ func (r *Repository) markErrorState(message string) {
 	r.State.NoUpstream = false
 	trimmed := strings.TrimSpace(message)
 	if trimmed != "" {
-		r.State.Message = trimmed
+		r.State.SetMessage(trimmed)
 	}
 }
 
@@ -129,7 +144,7 @@ func (r *Repository) ApplyOperationError(err error) error {
 	if gerr.RequiresCredentials(err) {
 		r.MarkRequiresCredentials(message)
 	} else {
-		r.MarkCriticalError(message)
+		r.MarkFailure(gerr.KindOf(err), message)
 	}
 	return err
 }
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Thresholds at which git gc --auto repacks, see gc.auto and gc.autoPackLimit.
+const (
+	gcAutoLooseObjects = 6700
+	gcAutoPackLimit    = 50
+)
+
+// ObjectStats is the disk usage of a repository: the size of its git
+// directory and the object counts of git count-objects.
+type ObjectStats struct {
+	// Size is the size of the git directory in bytes.
+	Size int64
+	// Loose is the number of loose objects and LooseSize their size in bytes.
+	Loose     int
+	LooseSize int64
+	// Packed is the number of objects in Packs pack files of PackSize bytes.
+	Packed   int
+	Packs    int
+	PackSize int64
+	// Prunable is the number of loose objects that are also in a pack.
+	Prunable int
+	// Garbage is the number of files in the object database that are
+	// neither objects nor packs.
+	Garbage int
+}
+
+// NeedsGC reports whether enough loose objects or packs piled up that git gc
+// --auto would repack, or there is something to prune.
+func (s ObjectStats) NeedsGC() bool {
+	return s.Loose >= gcAutoLooseObjects || s.Packs >= gcAutoPackLimit || s.Prunable > 0 || s.Garbage > 0
+}
+
+// ObjectStats collects the disk usage of the repository. Linked worktrees
+// report the shared git directory of the main worktree.
+func (r *Repository) ObjectStats() (ObjectStats, error) {
+	var stats ObjectStats
+	out, err := r.runGit("count-objects", "-v")
+	if err != nil {
+		return stats, err
+	}
+	if err := parseCountObjects(out, &stats); err != nil {
+		return stats, err
+	}
+	_, dir := r.GitDirs()
+	stats.Size, err = directorySize(dir)
+	return stats, err
+}
+
+// parseCountObjects reads the "key: value" lines of git count-objects -v,
+// where sizes are in KiB.
+func parseCountObjects(out string, stats *ObjectStats) error {
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return fmt.Errorf("count-objects: %s", line)
+		}
+		switch strings.TrimSpace(key) {
+		case "count":
+			stats.Loose = int(n)
+		case "size":
+			stats.LooseSize = n * 1024
+		case "in-pack":
+			stats.Packed = int(n)
+		case "packs":
+			stats.Packs = int(n)
+		case "size-pack":
+			stats.PackSize = n * 1024
+		case "prune-packable":
+			stats.Prunable = int(n)
+		case "garbage":
+			stats.Garbage = int(n)
+		}
+	}
+	return nil
+}
+
+// directorySize sums the files below dir. Files git removes while it walks,
+// e.g. lock files, are skipped.
+func directorySize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			var info fs.FileInfo
+			if info, err = d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	})
+	return size, err
+}
@@ -0,0 +1,41 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectStats(t *testing.T) {
+	repoPath := initLocalWorktreeRepo(t)
+	repo, err := InitializeRepo(repoPath)
+	require.NoError(t, err)
+
+	stats, err := repo.ObjectStats()
+	require.NoError(t, err)
+	require.Positive(t, stats.Loose)
+	require.Positive(t, stats.Size)
+	require.False(t, stats.NeedsGC())
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("second"), 0o644))
+	runGitCommand(t, repoPath, "commit", "-am", "second commit")
+	runGitCommand(t, repoPath, "repack", "-a", "-d")
+
+	packed, err := repo.ObjectStats()
+	require.NoError(t, err)
+	require.Equal(t, 1, packed.Packs)
+	require.Positive(t, packed.Packed)
+	require.Positive(t, packed.PackSize)
+}
+
+func TestParseCountObjects(t *testing.T) {
+	var stats ObjectStats
+	out := "count: 7000\nsize: 28\nin-pack: 120\npacks: 2\nsize-pack: 64\nprune-packable: 0\ngarbage: 0\nsize-garbage: 0"
+	require.NoError(t, parseCountObjects(out, &stats))
+	require.Equal(t, ObjectStats{Loose: 7000, LooseSize: 28 * 1024, Packed: 120, Packs: 2, PackSize: 64 * 1024}, stats)
+	require.True(t, stats.NeedsGC())
+
+	require.Error(t, parseCountObjects("count: many", &stats))
+}
@@ -0,0 +1,12 @@
+package git
+
+// VerifyTag reports whether the tag carries a signature that git verifies,
+// with gpg or, for tags signed with gpg.format=ssh, against the allowed
+// signers. Unsigned and lightweight tags do not verify.
+func (r *Repository) VerifyTag(name string) bool {
+	if r == nil || name == "" {
+		return false
+	}
+	_, err := r.runGit("tag", "-v", name)
+	return err == nil
+}
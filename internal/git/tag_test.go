@@ -0,0 +1,38 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyTag(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is needed to sign tags")
+	}
+	repoPath := initLocalWorktreeRepo(t)
+	keyDir := t.TempDir()
+	key := filepath.Join(keyDir, "id_ed25519")
+	out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "test@example.com", "-f", key).CombinedOutput()
+	require.NoError(t, err, string(out))
+	pub, err := os.ReadFile(key + ".pub")
+	require.NoError(t, err)
+	allowed := filepath.Join(keyDir, "allowed_signers")
+	require.NoError(t, os.WriteFile(allowed, []byte("test@example.com "+strings.TrimSpace(string(pub))+"\n"), 0o644))
+	runGitCommand(t, repoPath, "config", "gpg.format", "ssh")
+	runGitCommand(t, repoPath, "config", "user.signingkey", key)
+	runGitCommand(t, repoPath, "config", "gpg.ssh.allowedSignersFile", allowed)
+
+	runGitCommand(t, repoPath, "tag", "-s", "v1.0.0", "-m", "signed release")
+	runGitCommand(t, repoPath, "tag", "-a", "v0.9.0", "-m", "unsigned release")
+
+	repo, err := InitializeRepo(repoPath)
+	require.NoError(t, err)
+	require.True(t, repo.VerifyTag("v1.0.0"))
+	require.False(t, repo.VerifyTag("v0.9.0"))
+	require.False(t, repo.VerifyTag("missing"))
+}
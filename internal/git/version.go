@@ -0,0 +1,122 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is a git release number.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast reports whether v is major.minor or newer.
+func (v Version) AtLeast(major, minor int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	return v.Minor >= minor
+}
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// ParseVersion reads the output of `git --version`, e.g.
+// "git version 2.39.3 (Apple Git-146)".
+func ParseVersion(out string) (Version, error) {
+	m := versionPattern.FindStringSubmatch(out)
+	if m == nil {
+		return Version{}, fmt.Errorf("unrecognized git version %q", strings.TrimSpace(out))
+	}
+	var v Version
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+	}
+	return v, nil
+}
+
+var (
+	installedVersionMu       sync.Mutex
+	installedVersionDetected bool
+	installedVersion         Version
+	installedVersionErr      error
+)
+
+// InstalledVersion returns the version of the git binary in PATH. It is
+// detected once per process.
+func InstalledVersion() (Version, error) {
+	installedVersionMu.Lock()
+	defer installedVersionMu.Unlock()
+	if !installedVersionDetected {
+		installedVersionDetected = true
+		out, err := exec.Command("git", "--version").Output()
+		if err != nil {
+			installedVersionErr = fmt.Errorf("git --version: %w", err)
+		} else {
+			installedVersion, installedVersionErr = ParseVersion(string(out))
+		}
+	}
+	return installedVersion, installedVersionErr
+}
+
+// SetInstalledVersion replaces the detected git version, e.g. to exercise the
+// fallbacks for older releases.
+func SetInstalledVersion(v Version) {
+	installedVersionMu.Lock()
+	defer installedVersionMu.Unlock()
+	installedVersionDetected = true
+	installedVersion, installedVersionErr = v, nil
+}
+
+// Feature is a git capability that needs a minimum git release. Without it
+// gitbatch takes the Fallback path.
+type Feature struct {
+	Name         string
+	Major, Minor int
+	Fallback     string
+}
+
+var (
+	// FeatureMergeTreeWriteTree merges in memory, without a worktree.
+	FeatureMergeTreeWriteTree = Feature{
+		Name: "merge-tree --write-tree", Major: 2, Minor: 38,
+		Fallback: "conflicts are not predicted, the forecast reports diverged branches as unknown",
+	}
+	// FeatureSparseCheckout lists and reapplies sparse-checkout patterns.
+	FeatureSparseCheckout = Feature{
+		Name: "sparse-checkout", Major: 2, Minor: 25,
+		Fallback: "sparse checkouts are not detected",
+	}
+
+	features = []Feature{FeatureMergeTreeWriteTree, FeatureSparseCheckout}
+)
+
+// Supports reports whether the installed git has feature. A version that
+// cannot be detected is assumed to be recent.
+func Supports(feature Feature) bool {
+	v, err := InstalledVersion()
+	if err != nil {
+		return true
+	}
+	return v.AtLeast(feature.Major, feature.Minor)
+}
+
+// DegradedFeatures lists the features the installed git lacks.
+func DegradedFeatures() []Feature {
+	var degraded []Feature
+	for _, feature := range features {
+		if !Supports(feature) {
+			degraded = append(degraded, feature)
+		}
+	}
+	return degraded
+}
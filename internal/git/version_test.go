@@ -0,0 +1,36 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	v, err := ParseVersion("git version 2.39.3 (Apple Git-146)\n")
+	require.NoError(t, err)
+	require.Equal(t, Version{Major: 2, Minor: 39, Patch: 3}, v)
+	require.True(t, v.AtLeast(2, 38))
+	require.False(t, v.AtLeast(2, 40))
+	require.True(t, v.AtLeast(1, 99))
+
+	v, err = ParseVersion("git version 2.45.windows.1")
+	require.NoError(t, err)
+	require.Equal(t, "2.45.0", v.String())
+
+	_, err = ParseVersion("not git")
+	require.Error(t, err)
+}
+
+func TestDegradedFeatures(t *testing.T) {
+	installed, err := InstalledVersion()
+	require.NoError(t, err)
+	t.Cleanup(func() { SetInstalledVersion(installed) })
+
+	SetInstalledVersion(Version{Major: 2, Minor: 30})
+	require.Equal(t, []Feature{FeatureMergeTreeWriteTree}, DegradedFeatures())
+	require.True(t, Supports(FeatureSparseCheckout))
+
+	SetInstalledVersion(Version{Major: 2, Minor: 45})
+	require.Empty(t, DegradedFeatures())
+}
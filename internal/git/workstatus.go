@@ -0,0 +1,105 @@
+package git
+
+import (
+	"fmt"
+	"log"
+)
+
+const (
+	// WorkStatusTransitioned is the trace event recorded for an accepted work
+	// status transition, with "from -> to" as its data.
+	WorkStatusTransitioned = "workstatus.transition"
+	// WorkStatusTransitionRejected is the trace event recorded for an illegal
+	// work status transition.
+	WorkStatusTransitionRejected = "workstatus.transition.rejected"
+)
+
+// TransitionHook is called after the work status of a repository changed.
+type TransitionHook func(r *Repository, from, to WorkStatus)
+
+// workStatusTransitions lists the statuses each status may move to. Anything
+// else is a bug in the caller: e.g. a repository that is already Working
+// cannot go back to Queued, or the batch loop would start it a second time.
+var workStatusTransitions = map[WorkStatus][]WorkStatus{
+	Available: {Pending, Queued, Working, Success, Fail},
+	Pending:   {Available, Queued, Working, Paused, Success, Fail},
+	Queued:    {Available, Pending, Working, Paused, Fail},
+	Working:   {Available, Pending, Paused, Success, Fail},
+	Paused:    {Available, Pending, Queued, Working, Fail},
+	Success:   {Available, Pending, Queued, Working, Fail},
+	Fail:      {Available, Pending, Queued, Working},
+}
+
+// String returns the name of the status.
+func (ws WorkStatus) String() string {
+	switch ws {
+	case Available:
+		return "available"
+	case Pending:
+		return "pending"
+	case Queued:
+		return "queued"
+	case Working:
+		return "working"
+	case Paused:
+		return "paused"
+	case Success:
+		return "success"
+	case Fail:
+		return "fail"
+	}
+	return fmt.Sprintf("status(%d)", ws.Status)
+}
+
+// ParseWorkStatus returns the status String names.
+func ParseWorkStatus(name string) (WorkStatus, bool) {
+	for _, ws := range []WorkStatus{Available, Pending, Queued, Working, Paused, Success, Fail} {
+		if ws.String() == name {
+			return ws, true
+		}
+	}
+	return WorkStatus{}, false
+}
+
+// CanTransition reports whether a repository may move from one work status to
+// another. Staying in the same status is always allowed, and so is leaving the
+// zero status of a state that was never set.
+func CanTransition(from, to WorkStatus) bool {
+	if from == to || from == (WorkStatus{}) {
+		return true
+	}
+	for _, next := range workStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// OnTransition registers a hook that runs after every accepted work status
+// change of the repository.
+func (r *Repository) OnTransition(hook TransitionHook) {
+	if r == nil || hook == nil {
+		return
+	}
+	r.mutex.Lock()
+	r.transitionHooks = append(r.transitionHooks, hook)
+	r.mutex.Unlock()
+}
+
+func (r *Repository) runTransitionHooks(from, to WorkStatus) {
+	r.mutex.RLock()
+	hooks := append([]TransitionHook(nil), r.transitionHooks...)
+	r.mutex.RUnlock()
+	for _, hook := range hooks {
+		hook(r, from, to)
+	}
+}
+
+// rejectTransition logs an illegal transition. The status is left unchanged so
+// the UI keeps showing what the repository really does.
+func (r *Repository) rejectTransition(from, to WorkStatus) {
+	transition := fmt.Sprintf("%s -> %s", from, to)
+	log.Printf("%s: illegal work status transition %s", r.Name, transition)
+	r.traceEvent(WorkStatusTransitionRejected, queueState, transition)
+}
@@ -0,0 +1,51 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to WorkStatus
+		allowed  bool
+	}{
+		{Available, Queued, true},
+		{Queued, Working, true},
+		{Working, Success, true},
+		{Working, Fail, true},
+		{Fail, Working, true},
+		{Working, Working, true},
+		{WorkStatus{}, Working, true},
+		{Working, Queued, false},
+		{Queued, Success, false},
+		{Fail, Success, false},
+		{Available, Paused, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from.String()+"->"+tt.to.String(), func(t *testing.T) {
+			assert.Equal(t, tt.allowed, CanTransition(tt.from, tt.to))
+		})
+	}
+}
+
+func TestSetWorkStatusRejectsIllegalTransitionsAndRunsHooks(t *testing.T) {
+	r := &Repository{Name: "repo", State: &RepositoryState{}}
+	r.SetWorkStatusSilent(Available)
+
+	var seen []string
+	r.OnTransition(func(_ *Repository, from, to WorkStatus) {
+		seen = append(seen, from.String()+"->"+to.String())
+	})
+
+	r.SetWorkStatusSilent(Queued)
+	r.SetWorkStatusSilent(Working)
+	r.SetWorkStatusSilent(Queued)
+	require.Equal(t, Working, r.WorkStatus(), "a working repository cannot be queued again")
+
+	r.SetWorkStatusSilent(Success)
+	r.SetWorkStatusSilent(Success)
+	assert.Equal(t, []string{"available->queued", "queued->working", "working->success"}, seen)
+}
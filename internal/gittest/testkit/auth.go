@@ -0,0 +1,104 @@
+package testkit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// RequireAuth serves the remote over http through git http-backend and asks
+// for user and password with basic authentication, for fetches and pushes
+// alike. URL becomes the http URL, so clones made afterwards need
+// credentials, e.g. from an Askpass stub; clones made before keep fetching
+// from the path. The server stops with the test.
+func (r *Remote) RequireAuth(t *testing.T, user, password string) {
+	t.Helper()
+	gitPath, err := exec.LookPath("git")
+	require.NoError(t, err)
+	Git(t, r.Path, "config", "http.receivepack", "true")
+
+	backend := &cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env: []string{
+			"GIT_PROJECT_ROOT=" + filepath.Dir(r.Path),
+			"GIT_HTTP_EXPORT_ALL=1",
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if gotUser, gotPassword, ok := req.BasicAuth(); !ok || gotUser != user || gotPassword != password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="testkit"`)
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, req)
+	}))
+	t.Cleanup(server.Close)
+	r.URL = server.URL + "/" + filepath.Base(r.Path)
+}
+
+// Askpass is a GIT_ASKPASS program that answers the prompts of git from a
+// script and records them.
+type Askpass struct {
+	// Path is the program.
+	Path string
+	log  string
+}
+
+// NewAskpass installs an askpass stub as GIT_ASKPASS for the test. A prompt
+// is answered with the value of a key it contains, e.g. "Username" or
+// "Password", the first in sorted order when several match. Prompts without
+// an answer fail like a cancelled dialog. The git configuration of the user
+// is replaced by one with only an identity, so its credential helpers cannot
+// answer first.
+func NewAskpass(t *testing.T, answers map[string]string) *Askpass {
+	t.Helper()
+	dir := t.TempDir()
+	stub := &Askpass{Path: filepath.Join(dir, "askpass"), log: filepath.Join(dir, "prompts")}
+
+	var script strings.Builder
+	fmt.Fprintf(&script, "#!/bin/sh\nprintf '%%s\\n' \"$1\" >> %s\ncase \"$1\" in\n", shellQuote(stub.log))
+	keys := make([]string, 0, len(answers))
+	for key := range answers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&script, "*%s*)\n\tprintf '%%s\\n' %s\n\texit 0\n\t;;\n", shellQuote(key), shellQuote(answers[key]))
+	}
+	script.WriteString("esac\nexit 1\n")
+	require.NoError(t, os.WriteFile(stub.Path, []byte(script.String()), 0o700))
+
+	t.Setenv("GIT_ASKPASS", stub.Path)
+	t.Setenv("SSH_ASKPASS", stub.Path)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	gitconfig := filepath.Join(dir, "gitconfig")
+	require.NoError(t, os.WriteFile(gitconfig, []byte("[user]\n\tname = Test User\n\temail = test@example.com\n"), 0o600))
+	t.Setenv("GIT_CONFIG_GLOBAL", gitconfig)
+	return stub
+}
+
+// Prompts returns the prompts the stub was asked, in order.
+func (a *Askpass) Prompts(t *testing.T) []string {
+	t.Helper()
+	data, err := os.ReadFile(a.log)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// shellQuote quotes s as a single shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,25 @@
+package testkit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Delay slows down every fetch from and push to remote in clone by delay, as
+// a slow network would. It wraps git upload-pack and receive-pack, which git
+// runs for remotes reached by path; remotes over http are not slowed down.
+func Delay(t *testing.T, clone, remote string, delay time.Duration) {
+	t.Helper()
+	dir := t.TempDir()
+	for key, program := range map[string]string{"uploadpack": "upload-pack", "receivepack": "receive-pack"} {
+		wrapper := filepath.Join(dir, "git-"+program)
+		script := fmt.Sprintf("#!/bin/sh\nsleep %.3f\nexec git %s \"$@\"\n", delay.Seconds(), program)
+		require.NoError(t, os.WriteFile(wrapper, []byte(script), 0o700))
+		Git(t, clone, "config", "remote."+remote+"."+key, wrapper)
+	}
+}
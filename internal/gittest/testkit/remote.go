@@ -0,0 +1,64 @@
+package testkit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Remote is a bare repository on disk with a main branch.
+type Remote struct {
+	// Path is the bare repository.
+	Path string
+	// URL is what clones fetch from: Path, or the http URL once the remote
+	// requires authentication.
+	URL string
+}
+
+// NewRemote creates a bare repository with a single commit of README.md on
+// main.
+func NewRemote(t *testing.T) *Remote {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "remote.git")
+	Git(t, filepath.Dir(path), "init", "--bare", "--initial-branch=main", path)
+	remote := &Remote{Path: path, URL: path}
+	remote.Commit(t, "README.md", "initial\n", "initial commit")
+	return remote
+}
+
+// Clone clones the remote into a new directory called name and returns its
+// path. The remote is origin there and main tracks origin/main.
+func (r *Remote) Clone(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	Git(t, filepath.Dir(path), "clone", "--branch", "main", r.URL, path)
+	return path
+}
+
+// Commit writes content to file on main of the remote, as if someone else
+// pushed it, and returns the new commit.
+func (r *Remote) Commit(t *testing.T, file, content, message string) string {
+	t.Helper()
+	scratch := filepath.Join(t.TempDir(), "scratch")
+	Git(t, filepath.Dir(scratch), "clone", r.Path, scratch)
+	Git(t, scratch, "checkout", "-B", "main")
+	require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(scratch, file)), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(scratch, file), []byte(content), 0o644))
+	Git(t, scratch, "add", file)
+	Git(t, scratch, "commit", "-m", message)
+	Git(t, scratch, "push", "origin", "main")
+	return Git(t, scratch, "rev-parse", "HEAD")
+}
+
+// Diverge commits to the remote and to clone, in different files so merging
+// succeeds, and fetches: clone is one commit ahead and one behind.
+func (r *Remote) Diverge(t *testing.T, clone string) {
+	t.Helper()
+	r.Commit(t, "upstream.txt", "upstream\n", "upstream change")
+	require.NoError(t, os.WriteFile(filepath.Join(clone, "local.txt"), []byte("local\n"), 0o644))
+	Git(t, clone, "add", "local.txt")
+	Git(t, clone, "commit", "-m", "local change")
+	Git(t, clone, "fetch", "origin")
+}
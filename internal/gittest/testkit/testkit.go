@@ -0,0 +1,46 @@
+// Package testkit builds the remotes end-to-end tests of jobs run against:
+// a bare repository a test pushes commits to, clones that diverged from it,
+// a remote over http that asks for credentials, answered by an askpass stub,
+// and a remote behind a slow network.
+//
+// A test of a new job type typically scripts the remote, clones it, loads the
+// clone with git.InitializeRepo and starts the job:
+//
+//	remote := testkit.NewRemote(t)
+//	clone := remote.Clone(t, "api")
+//	remote.Diverge(t, clone)
+//	repo, err := git.InitializeRepo(clone)
+//
+// The package runs git directly, so tests of the command package can use it
+// too.
+package testkit
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/gittest"
+)
+
+// TestHelper is the extracted test-data repository of gittest.
+type TestHelper = gittest.TestHelper
+
+// InitTestRepositoryFromLocal extracts the embedded test-data repository and
+// loads it, see gittest.InitTestRepositoryFromLocal.
+func InitTestRepositoryFromLocal(t *testing.T) *TestHelper {
+	return gittest.InitTestRepositoryFromLocal(t)
+}
+
+// Git runs git in dir with a fixed identity and returns its trimmed output.
+// It fails the test when git does.
+func Git(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+	args = append([]string{"-c", "user.name=Test User", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	return strings.TrimSpace(string(out))
+}
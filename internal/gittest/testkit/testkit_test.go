@@ -0,0 +1,46 @@
+package testkit
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDivergedClone(t *testing.T) {
+	remote := NewRemote(t)
+	clone := remote.Clone(t, "api")
+	remote.Diverge(t, clone)
+	require.Equal(t, "1\t1", Git(t, clone, "rev-list", "--left-right", "--count", "HEAD...origin/main"))
+}
+
+func TestRequireAuthAsksTheAskpassStub(t *testing.T) {
+	remote := NewRemote(t)
+	remote.RequireAuth(t, "alice", "s3cret")
+
+	wrong := NewAskpass(t, map[string]string{"Username": "alice", "Password": "wrong"})
+	cmd := exec.Command("git", "clone", remote.URL, t.TempDir()+"/denied")
+	cmd.Env = append(cmd.Environ(), "GIT_TERMINAL_PROMPT=0")
+	require.Error(t, cmd.Run())
+	require.NotEmpty(t, wrong.Prompts(t))
+
+	askpass := NewAskpass(t, map[string]string{"Username": "alice", "Password": "s3cret"})
+	clone := remote.Clone(t, "api")
+	require.Len(t, askpass.Prompts(t), 2)
+	require.Contains(t, askpass.Prompts(t)[0], "Username")
+
+	head := remote.Commit(t, "README.md", "changed\n", "change")
+	Git(t, clone, "pull")
+	require.Equal(t, head, Git(t, clone, "rev-parse", "HEAD"))
+}
+
+func TestDelaySlowsDownFetches(t *testing.T) {
+	remote := NewRemote(t)
+	clone := remote.Clone(t, "api")
+	Delay(t, clone, "origin", 300*time.Millisecond)
+
+	start := time.Now()
+	Git(t, clone, "fetch", "origin")
+	require.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+}
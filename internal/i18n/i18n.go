@@ -0,0 +1,130 @@
+// Package i18n translates the messages gitbatch shows in the interface. The
+// messages are written in English in the code and double as keys into the
+// catalog of the active locale; a message without a translation is shown in
+// English.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// DefaultLocale is the locale the messages are written in.
+const DefaultLocale = "en"
+
+//go:embed locales/*.yaml
+var localeFiles embed.FS
+
+var (
+	mu           sync.RWMutex
+	activeLocale = DefaultLocale
+	catalog      map[string]string
+)
+
+// Locales returns the available locales.
+func Locales() []string {
+	locales := []string{DefaultLocale}
+	entries, _ := localeFiles.ReadDir("locales")
+	for _, entry := range entries {
+		locales = append(locales, strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// SetLocale activates a locale such as "de" or "de_DE.UTF-8". An empty
+// locale or "auto" picks the locale from the environment.
+func SetLocale(locale string) error {
+	locale = strings.TrimSpace(locale)
+	if locale == "" || locale == "auto" {
+		locale = FromEnvironment()
+	}
+	lang := language(locale)
+	if lang == DefaultLocale {
+		mu.Lock()
+		activeLocale, catalog = DefaultLocale, nil
+		mu.Unlock()
+		return nil
+	}
+	messages, err := loadCatalog(lang)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	activeLocale, catalog = lang, messages
+	mu.Unlock()
+	return nil
+}
+
+// Locale returns the active locale.
+func Locale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return activeLocale
+}
+
+// FromEnvironment returns the locale of the messages the user asked for via
+// LC_ALL, LC_MESSAGES or LANG, or the default when it is not available.
+func FromEnvironment() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		lang := language(value)
+		for _, locale := range Locales() {
+			if locale == lang {
+				return locale
+			}
+		}
+		// The first variable that is set decides, like in libc.
+		break
+	}
+	return DefaultLocale
+}
+
+// T translates message into the active locale and formats it with args
+// like fmt.Sprintf.
+func T(message string, args ...any) string {
+	mu.RLock()
+	if translated, ok := catalog[message]; ok {
+		message = translated
+	}
+	mu.RUnlock()
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// language reduces a locale like "de_DE.UTF-8" to its language "de". The C
+// and POSIX locales are English.
+func language(locale string) string {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "_.@-"); i != -1 {
+		lang = lang[:i]
+	}
+	if lang == "c" || lang == "posix" {
+		return DefaultLocale
+	}
+	return lang
+}
+
+func loadCatalog(lang string) (map[string]string, error) {
+	data, err := localeFiles.ReadFile("locales/" + lang + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("unsupported language %q (available: %s)", lang, strings.Join(Locales(), ", "))
+	}
+	messages := make(map[string]string)
+	if err := yaml.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("read %s catalog: %w", lang, err)
+	}
+	return messages, nil
+}
@@ -0,0 +1,56 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLocale(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetLocale(DefaultLocale)) })
+
+	require.NoError(t, SetLocale("de_DE.UTF-8"))
+	require.Equal(t, "de", Locale())
+	require.Equal(t, "Pull abgeschlossen", T("pull completed"))
+	require.Equal(t, "markiert: 3", T("tagged: %d", 3))
+	require.Equal(t, "not translated 1", T("not translated %d", 1), "untranslated messages stay English")
+
+	require.NoError(t, SetLocale("C"))
+	require.Equal(t, DefaultLocale, Locale())
+	require.Equal(t, "pull completed", T("pull completed"))
+
+	require.EqualError(t, SetLocale("xx"), `unsupported language "xx" (available: de, en)`)
+	require.Equal(t, DefaultLocale, Locale())
+}
+
+func TestFromEnvironment(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "de_AT.UTF-8")
+	require.Equal(t, "de", FromEnvironment())
+
+	t.Setenv("LC_MESSAGES", "fr_FR.UTF-8")
+	require.Equal(t, DefaultLocale, FromEnvironment(), "the first variable set decides")
+
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "")
+	require.Equal(t, DefaultLocale, FromEnvironment())
+}
+
+var verbRE = regexp.MustCompile(`%[a-zA-Z%]`)
+
+func TestCatalogsKeepPlaceholders(t *testing.T) {
+	for _, locale := range Locales() {
+		if locale == DefaultLocale {
+			continue
+		}
+		messages, err := loadCatalog(locale)
+		require.NoError(t, err)
+		require.NotEmpty(t, messages)
+		for message, translated := range messages {
+			require.Equal(t, verbRE.FindAllString(message, -1), verbRE.FindAllString(translated, -1), "%s: %q", locale, message)
+		}
+	}
+}
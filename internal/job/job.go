@@ -15,6 +15,8 @@ type Job struct {
 	Repository *git.Repository
 	// Options is a placeholder for operation options
 	Options any
+	// Batch is the batch the job belongs to, zero for none
+	Batch command.BatchID
 }
 
 // Type is the a git operation supported
@@ -33,6 +35,13 @@ const (
 	// RebaseJob is wrapper of git pull --rebase command
 	RebaseJob Type = "rebase"
 
+	// RebaseOntoJob is wrapper of git rebase --onto
+	RebaseOntoJob Type = "rebase-onto"
+
+	// RebaseDefaultJob rebases the current branch onto the updated default
+	// branch
+	RebaseDefaultJob Type = "rebase-default"
+
 	// PushJob is wrapper of git push command
 	PushJob Type = "push"
 
@@ -47,6 +56,34 @@ const (
 
 	// StashDropJob is wrapper of git stash drop
 	StashDropJob Type = "stash-drop"
+
+	// CheckoutJob is wrapper of git checkout
+	CheckoutJob Type = "checkout"
+
+	// DeleteBranchJob is wrapper of git branch -d and git push --delete
+	DeleteBranchJob Type = "delete-branch"
+
+	// UndoJob reverts the most recent checkout or branch deletion
+	UndoJob Type = "undo"
+
+	// SetUpstreamJob is wrapper of git branch --set-upstream-to
+	SetUpstreamJob Type = "set-upstream"
+
+	// SetRemoteURLJob is wrapper of git remote set-url
+	SetRemoteURLJob Type = "set-url"
+
+	// SyncForkJob fetches upstream, fast-forwards the default branch and
+	// pushes it to origin
+	SyncForkJob Type = "sync-fork"
+
+	// PruneRemoteJob is wrapper of git remote prune
+	PruneRemoteJob Type = "prune-remote"
+
+	// SparseReapplyJob is wrapper of git sparse-checkout reapply
+	SparseReapplyJob Type = "sparse-reapply"
+
+	// CompositeJob runs the git commands of a configured composite job
+	CompositeJob Type = "composite"
 )
 
 // PullJobConfig wraps pull options with queue behaviour flags.
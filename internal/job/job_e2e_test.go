@@ -0,0 +1,63 @@
+package job
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/gittest/testkit"
+)
+
+func TestFetchJobAuthenticatesThroughAskpass(t *testing.T) {
+	remote := testkit.NewRemote(t)
+	remote.RequireAuth(t, "alice", "s3cret")
+	askpass := testkit.NewAskpass(t, map[string]string{"Username": "alice", "Password": "s3cret"})
+	clone := remote.Clone(t, "api")
+	head := remote.Commit(t, "README.md", "changed\n", "change")
+
+	repo, err := git.InitializeRepo(clone)
+	require.NoError(t, err)
+	prompts := len(askpass.Prompts(t))
+	require.Equal(t, git.Available, runJob(t, &Job{JobType: FetchJob, Repository: repo}), repo.State.Message())
+	require.Equal(t, head, testkit.Git(t, clone, "rev-parse", "origin/main"))
+	require.Greater(t, len(askpass.Prompts(t)), prompts)
+	// A clean repository behind its upstream is tagged for the next pull.
+	require.Eventually(t, func() bool { return repo.WorkStatus() == git.Queued }, 10*time.Second, 25*time.Millisecond)
+}
+
+func TestPullJobOnADivergedSlowRemote(t *testing.T) {
+	remote := testkit.NewRemote(t)
+	clone := remote.Clone(t, "api")
+	remote.Diverge(t, clone)
+	testkit.Delay(t, clone, "origin", 200*time.Millisecond)
+
+	repo, err := git.InitializeRepo(clone)
+	require.NoError(t, err)
+	require.Equal(t, git.Fail, runJob(t, &Job{JobType: PullJob, Repository: repo}), repo.State.Message())
+
+	require.Equal(t, git.Success, runJob(t, &Job{JobType: MergeJob, Repository: repo}), repo.State.Message())
+	require.Equal(t, "0\t0", testkit.Git(t, clone, "rev-list", "--left-right", "--count", "origin/main...HEAD^2"))
+}
+
+// runJob starts job and waits until it left the working state, returning
+// the status the job moved its repository to.
+func runJob(t *testing.T, job *Job) git.WorkStatus {
+	t.Helper()
+	done := make(chan git.WorkStatus, 1)
+	var once sync.Once
+	job.Repository.OnTransition(func(_ *git.Repository, from, to git.WorkStatus) {
+		if from == git.Working {
+			once.Do(func() { done <- to })
+		}
+	})
+	require.NoError(t, job.Start())
+	select {
+	case status := <-done:
+		return status
+	case <-time.After(10 * time.Second):
+		t.Fatalf("%s job on %s did not finish", job.JobType, job.Repository.Name)
+		return git.Available
+	}
+}
@@ -91,7 +91,7 @@ func TestFetchJobPreservesErrorState(t *testing.T) {
 	require.Eventually(t, func() bool {
 		return repo.WorkStatus() == git.Fail
 	}, 2*time.Second, 50*time.Millisecond)
-	require.Contains(t, strings.ToLower(repo.State.Message), "upstream")
+	require.Contains(t, strings.ToLower(repo.State.Message()), "upstream")
 }
 
 func TestStartRequiresInitializedJob(t *testing.T) {
@@ -7,58 +7,123 @@ import (
 type jobStarter func(*Job) error
 
 var jobStarters = map[Type]jobStarter{
-	FetchJob:     startFetchJob,
-	PullJob:      startPullJob,
-	MergeJob:     startMergeJob,
-	RebaseJob:    startRebaseJob,
-	PushJob:      startPushJob,
-	CommitJob:    startCommitJob,
-	StashJob:     startStashJob,
-	StashPopJob:  startStashPopJob,
-	StashDropJob: startStashDropJob,
+	FetchJob:         startFetchJob,
+	PullJob:          startPullJob,
+	MergeJob:         startMergeJob,
+	RebaseJob:        startRebaseJob,
+	RebaseOntoJob:    startRebaseOntoJob,
+	RebaseDefaultJob: startRebaseDefaultJob,
+	PushJob:          startPushJob,
+	CommitJob:        startCommitJob,
+	StashJob:         startStashJob,
+	StashPopJob:      startStashPopJob,
+	StashDropJob:     startStashDropJob,
+	CheckoutJob:      startCheckoutJob,
+	DeleteBranchJob:  startDeleteBranchJob,
+	SetUpstreamJob:   startSetUpstreamJob,
+	SetRemoteURLJob:  startSetRemoteURLJob,
+	UndoJob:          startUndoJob,
+	SyncForkJob:      startSyncForkJob,
+	SparseReapplyJob: startSparseReapplyJob,
+	PruneRemoteJob:   startPruneRemoteJob,
+	CompositeJob:     startCompositeJob,
 }
 
 // The per-operation "running..." status message is set by command.startGitOperation
 // when the git queue worker picks up the request; these starters only translate
 // the Options payload into the typed command options.
 
+// executor returns the executor that schedules the commands of j.
+func (j *Job) executor() *command.Executor {
+	return command.NewExecutor(j.Repository).InBatch(j.Batch)
+}
+
 func startFetchJob(j *Job) error {
-	return command.NewExecutor(j.Repository).ScheduleFetch(resolveFetchOptions(j.Options))
+	return j.executor().ScheduleFetch(resolveFetchOptions(j.Options))
 }
 
 func startPullJob(j *Job) error {
 	opts, suppress := resolvePullJobConfig(j.Options)
-	return command.NewExecutor(j.Repository).SchedulePull(opts, suppress)
+	return j.executor().SchedulePull(opts, suppress)
 }
 
 func startMergeJob(j *Job) error {
-	return command.NewExecutor(j.Repository).ScheduleMerge(nil)
+	return j.executor().ScheduleMerge(nil)
 }
 
 func startRebaseJob(j *Job) error {
 	opts, _ := resolvePullJobConfig(j.Options)
-	return command.NewExecutor(j.Repository).ScheduleRebase(opts)
+	return j.executor().ScheduleRebase(opts)
 }
 
 func startPushJob(j *Job) error {
 	opts, suppress := resolvePushJobConfig(j.Options)
-	return command.NewExecutor(j.Repository).SchedulePush(opts, suppress)
+	return j.executor().SchedulePush(opts, suppress)
+}
+
+func startRebaseOntoJob(j *Job) error {
+	return j.executor().ScheduleRebaseOnto(resolveRebaseOntoOptions(j.Options))
+}
+
+func startRebaseDefaultJob(j *Job) error {
+	return j.executor().ScheduleRebaseOnDefault()
 }
 
 func startCommitJob(j *Job) error {
-	return command.NewExecutor(j.Repository).ScheduleCommit(resolveCommitOptions(j.Options))
+	return j.executor().ScheduleCommit(resolveCommitOptions(j.Options))
 }
 
 func startStashJob(j *Job) error {
-	return command.NewExecutor(j.Repository).ScheduleStash(resolveStashOptions(j.Options))
+	return j.executor().ScheduleStash(resolveStashOptions(j.Options))
 }
 
 func startStashPopJob(j *Job) error {
-	return command.NewExecutor(j.Repository).ScheduleStashPop(resolveStashPopOptions(j.Options))
+	return j.executor().ScheduleStashPop(resolveStashPopOptions(j.Options))
 }
 
 func startStashDropJob(j *Job) error {
-	return command.NewExecutor(j.Repository).ScheduleStashDrop(resolveStashDropOptions(j.Options))
+	return j.executor().ScheduleStashDrop(resolveStashDropOptions(j.Options))
+}
+
+func startCheckoutJob(j *Job) error {
+	if step, ok := j.Options.(command.CheckoutStep); ok {
+		return j.executor().ScheduleCheckoutStep(step)
+	}
+	return j.executor().ScheduleCheckout(resolveCheckoutOptions(j.Options))
+}
+
+func startDeleteBranchJob(j *Job) error {
+	return j.executor().ScheduleDeleteBranch(resolveDeleteBranchOptions(j.Options))
+}
+
+func startSetUpstreamJob(j *Job) error {
+	return j.executor().ScheduleSetUpstream(resolveSetUpstreamOptions(j.Options))
+}
+
+func startSetRemoteURLJob(j *Job) error {
+	rewrites, _ := j.Options.([]command.RemoteURLRewrite)
+	return j.executor().ScheduleSetRemoteURLs(rewrites)
+}
+
+func startUndoJob(j *Job) error {
+	return j.executor().ScheduleUndo()
+}
+
+func startSyncForkJob(j *Job) error {
+	return j.executor().ScheduleSyncFork()
+}
+
+func startSparseReapplyJob(j *Job) error {
+	return j.executor().ScheduleSparseReapply()
+}
+
+func startPruneRemoteJob(j *Job) error {
+	remoteName, _ := j.Options.(string)
+	return j.executor().SchedulePruneRemote(remoteName)
+}
+
+func startCompositeJob(j *Job) error {
+	return j.executor().ScheduleComposite(resolveCompositeOptions(j.Options))
 }
 
 func resolveFetchOptions(options any) *command.FetchOptions {
@@ -108,6 +173,17 @@ func resolvePushJobConfig(options any) (*command.PushOptions, bool) {
 	}
 }
 
+func resolveRebaseOntoOptions(options any) *command.RebaseOntoOptions {
+	switch cfg := options.(type) {
+	case *command.RebaseOntoOptions:
+		return cfg
+	case command.RebaseOntoOptions:
+		return &cfg
+	default:
+		return nil
+	}
+}
+
 func resolveCommitOptions(options any) *command.CommitOptions {
 	switch cfg := options.(type) {
 	case *command.CommitOptions:
@@ -151,3 +227,49 @@ func resolveStashDropOptions(options any) *command.StashDropOptions {
 		return nil
 	}
 }
+
+func resolveCheckoutOptions(options any) *command.CheckoutOptions {
+	switch cfg := options.(type) {
+	case *command.CheckoutOptions:
+		return cfg
+	case command.CheckoutOptions:
+		return &cfg
+	default:
+		return nil
+	}
+}
+
+func resolveDeleteBranchOptions(options any) *command.DeleteBranchOptions {
+	switch cfg := options.(type) {
+	case *command.DeleteBranchOptions:
+		return cfg
+	case command.DeleteBranchOptions:
+		return &cfg
+	default:
+		return nil
+	}
+}
+
+func resolveSetUpstreamOptions(options any) *command.SetUpstreamOptions {
+	switch cfg := options.(type) {
+	case *command.SetUpstreamOptions:
+		return cfg
+	case command.SetUpstreamOptions:
+		return &cfg
+	default:
+		return nil
+	}
+}
+
+func resolveCompositeOptions(options any) *command.CompositeOptions {
+	switch cfg := options.(type) {
+	case *command.CompositeOptions:
+		return cfg
+	case command.CompositeOptions:
+		return &cfg
+	case *command.CompositeJob:
+		return &command.CompositeOptions{Job: cfg}
+	default:
+		return nil
+	}
+}
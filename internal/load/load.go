@@ -2,12 +2,30 @@ package load
 
 import (
 	"fmt"
+	"os/exec"
+	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
 
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
 )
 
+// Skipped is a directory that looked like a repository but was not loaded,
+// with the reason why.
+type Skipped struct {
+	Path   string
+	Reason string
+	// Corrupt is set when git reported damaged objects or refs. Such
+	// repositories are quarantined until they are repaired or cloned again.
+	Corrupt bool
+	// Origin is the URL of the origin remote of a corrupt repository, if
+	// its configuration could still be read.
+	Origin string
+}
+
 // SyncLoad initializes the go-git's repository objects with given
 // slice of paths. since this job is done parallel, the order of the directories
 // is not kept
@@ -19,8 +37,20 @@ func SyncLoad(directories []string) (entities []*git.Repository, err error) {
 // repositories (1, 2, 3, …) to progress after each repo is initialized.
 // progress may be nil, in which case no progress is reported.
 func SyncLoadWithProgress(directories []string, progress chan<- int) (entities []*git.Repository, err error) {
+	entities, _, err = StreamLoad(directories, nil, progress)
+	return entities, err
+}
+
+// StreamLoad is like SyncLoadWithProgress but additionally sends every
+// repository to fast as soon as FastInitializeRepo returns, before its
+// branches and remotes are loaded. Once they are, the repository publishes
+// a repository-updated event so the receiver can fill its row in.
+// Repositories whose components fail to load are sent to fast but left out
+// of the returned slice. Directories that fail to open or load, e.g. because
+// the repository is corrupt, are returned as skipped. fast may be nil.
+func StreamLoad(directories []string, fast chan<- *git.Repository, progress chan<- int) (entities []*git.Repository, skipped []Skipped, err error) {
 	if len(directories) == 0 {
-		return nil, fmt.Errorf("no directories provided")
+		return nil, nil, fmt.Errorf("no directories provided")
 	}
 
 	// Use a worker pool pattern instead of unlimited goroutines
@@ -35,7 +65,7 @@ func SyncLoadWithProgress(directories []string, progress chan<- int) (entities [
 	// Channels for work distribution and result collection
 	jobs := make(chan string, len(directories))
 	results := make(chan *git.Repository, len(directories))
-	errors := make(chan error, len(directories))
+	failures := make(chan Skipped, len(directories))
 
 	// Start workers
 	var (
@@ -48,13 +78,21 @@ func SyncLoadWithProgress(directories []string, progress chan<- int) (entities [
 		go func() {
 			defer wg.Done()
 			for dir := range jobs {
-				entity, err := git.InitializeRepo(dir)
+				entity, err := git.FastInitializeRepo(dir)
 				if err != nil {
-					errors <- err
+					failures <- skippedRepository(dir, "cannot be opened: ", err)
+					continue
+				}
+				if fast != nil {
+					fast <- entity
+				}
+				if err := entity.LoadComponents(); err != nil {
+					failures <- skippedRepository(dir, "cannot be loaded: ", err)
 					continue
 				}
 				// Initialize modtime
 				entity.RefreshModTime()
+				entity.NotifyRepositoryUpdated()
 
 				if progress != nil {
 					mu.Lock()
@@ -82,12 +120,11 @@ func SyncLoadWithProgress(directories []string, progress chan<- int) (entities [
 	go func() {
 		wg.Wait()
 		close(results)
-		close(errors)
+		close(failures)
 	}()
 
 	// Collect results
 	entities = make([]*git.Repository, 0, len(directories))
-	var errCount int
 
 	for {
 		select {
@@ -97,22 +134,47 @@ func SyncLoadWithProgress(directories []string, progress chan<- int) (entities [
 			} else {
 				entities = append(entities, entity)
 			}
-		case err, ok := <-errors:
+		case failure, ok := <-failures:
 			if !ok {
-				errors = nil
-			} else if err != nil {
-				errCount++
-				// Log error but continue processing other repositories
+				failures = nil
+			} else {
+				// Keep going with the other repositories; the caller
+				// decides how to report the skipped ones.
+				skipped = append(skipped, failure)
 			}
 		}
 
-		if results == nil && errors == nil {
+		if results == nil && failures == nil {
 			break
 		}
 	}
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].Path < skipped[j].Path })
 
 	if len(entities) == 0 {
-		return entities, fmt.Errorf("there are no git repositories at given path(s)")
+		return entities, skipped, fmt.Errorf("there are no git repositories at given path(s)")
+	}
+	return entities, skipped, nil
+}
+
+func skippedRepository(dir, prefix string, err error) Skipped {
+	skipped := Skipped{Path: dir, Reason: prefix + err.Error()}
+	if gerr.IsCorruption(err) {
+		skipped.Corrupt = true
+		skipped.Origin = originURL(dir)
+	}
+	return skipped
+}
+
+// originURL reads the origin URL from the configuration file, which git can
+// still parse when the objects of the repository are damaged.
+func originURL(dir string) string {
+	args := []string{"-C", dir, "config", "--get", "remote.origin.url"}
+	if _, commonGitDir, err := git.ResolveGitDir(dir); err == nil {
+		args = []string{"config", "--file", filepath.Join(commonGitDir, "config"), "--get", "remote.origin.url"}
+	}
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return ""
 	}
-	return entities, nil
+	return strings.TrimSpace(string(out))
 }
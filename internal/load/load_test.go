@@ -1,9 +1,15 @@
 package load
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
 	"github.com/thorstenhirsch/gitbatch/internal/gittest"
 )
 
@@ -22,3 +28,78 @@ func TestSyncLoad(t *testing.T) {
 		require.NotEmpty(t, output)
 	}
 }
+
+func TestStreamLoad(t *testing.T) {
+	th := gittest.InitTestRepositoryFromLocal(t)
+	defer th.CleanUp(t)
+
+	dirs := []string{th.BasicRepoPath(), th.DirtyRepoPath()}
+	fast := make(chan *git.Repository)
+	streamed := make(chan []*git.Repository)
+	var updated atomic.Int32
+	go func() {
+		var repos []*git.Repository
+		for r := range fast {
+			// the branches and remotes are still loading; the repository
+			// tells once they are in
+			r.On(git.RepositoryUpdated, func(*git.RepositoryEvent) error {
+				updated.Add(1)
+				return nil
+			})
+			repos = append(repos, r)
+		}
+		streamed <- repos
+	}()
+	output, skipped, err := StreamLoad(dirs, fast, nil)
+	close(fast)
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+	require.Len(t, output, len(dirs))
+	require.ElementsMatch(t, output, <-streamed)
+	for _, r := range output {
+		require.NotNil(t, r.State.Branch)
+	}
+	require.Equal(t, int32(len(dirs)), updated.Load())
+}
+
+func TestStreamLoadReportsRepositoriesThatCannotBeOpened(t *testing.T) {
+	th := gittest.InitTestRepositoryFromLocal(t)
+	defer th.CleanUp(t)
+
+	broken := filepath.Join(t.TempDir(), "broken")
+	require.NoError(t, os.MkdirAll(broken, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(broken, ".git"), []byte("gitdir: "+filepath.Join(broken, "missing")+"\n"), 0o644))
+
+	output, skipped, err := StreamLoad([]string{th.BasicRepoPath(), broken}, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+	require.Len(t, skipped, 1)
+	require.Equal(t, broken, skipped[0].Path)
+	require.Contains(t, skipped[0].Reason, "cannot be opened")
+}
+
+func TestStreamLoadQuarantinesCorruptRepositories(t *testing.T) {
+	th := gittest.InitTestRepositoryFromLocal(t)
+	defer th.CleanUp(t)
+
+	dir := filepath.Join(t.TempDir(), "corrupt")
+	for _, args := range [][]string{
+		{"init", "-q", "--initial-branch=main", dir},
+		{"-C", dir, "remote", "add", "origin", "https://example.com/corrupt.git"},
+		{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	head, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	require.NoError(t, err)
+	hash := strings.TrimSpace(string(head))
+	require.NoError(t, os.Remove(filepath.Join(dir, ".git", "objects", hash[:2], hash[2:])))
+
+	output, skipped, err := StreamLoad([]string{th.BasicRepoPath(), dir}, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+	require.Len(t, skipped, 1)
+	require.True(t, skipped[0].Corrupt)
+	require.Equal(t, "https://example.com/corrupt.git", skipped[0].Origin)
+}
@@ -0,0 +1,87 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+)
+
+// Clone checks out a manifest entry at its path and configures its extra
+// remotes. Entries without URL cannot be cloned.
+func Clone(e Entry) error {
+	if e.URL == "" {
+		return fmt.Errorf("no clone URL for %s", e.Path)
+	}
+	parent := filepath.Dir(e.Path)
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return err
+	}
+	branch, detached := cloneRevision(e.Revision)
+	args := []string{"clone"}
+	if e.RemoteName != "" && e.RemoteName != "origin" {
+		args = append(args, "--origin", e.RemoteName)
+	}
+	if branch != "" {
+		args = append(args, "--branch", branch)
+	}
+	args = append(args, e.URL, e.Path)
+	if out, err := command.Run(parent, "git", args); err != nil {
+		return fmt.Errorf("clone %s: %s", e.URL, out)
+	}
+	if detached != "" {
+		remote := e.RemoteName
+		if remote == "" {
+			remote = "origin"
+		}
+		if err := checkoutRevision(e.Path, remote, detached); err != nil {
+			return err
+		}
+	}
+	for _, remote := range e.Remotes {
+		if out, err := command.Run(e.Path, "git", []string{"remote", "add", remote.Name, remote.URL}); err != nil {
+			return fmt.Errorf("add remote %s: %s", remote.Name, out)
+		}
+	}
+	return nil
+}
+
+var commitID = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// cloneRevision splits a manifest revision into the branch or tag name that
+// git clone --branch accepts and the revision to check out after the clone
+// otherwise: a commit or a ref outside refs/heads and refs/tags, e.g. the
+// refs/changes of a review.
+func cloneRevision(revision string) (branch, detached string) {
+	switch {
+	case revision == "":
+		return "", ""
+	case strings.HasPrefix(revision, "refs/heads/"):
+		return strings.TrimPrefix(revision, "refs/heads/"), ""
+	case strings.HasPrefix(revision, "refs/tags/"):
+		return strings.TrimPrefix(revision, "refs/tags/"), ""
+	case strings.HasPrefix(revision, "refs/"), commitID.MatchString(revision):
+		return "", revision
+	}
+	return revision, ""
+}
+
+// checkoutRevision detaches the clone at dir at revision, fetching it first
+// when the clone did not bring it along.
+func checkoutRevision(dir, remote, revision string) error {
+	if !strings.HasPrefix(revision, "refs/") {
+		if _, err := command.Run(dir, "git", []string{"checkout", "--quiet", revision}); err == nil {
+			return nil
+		}
+	}
+	if out, err := command.Run(dir, "git", []string{"fetch", remote, revision}); err != nil {
+		return fmt.Errorf("fetch %s: %s", revision, out)
+	}
+	if out, err := command.Run(dir, "git", []string{"checkout", "--quiet", "FETCH_HEAD"}); err != nil {
+		return fmt.Errorf("checkout %s: %s", revision, out)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+)
+
+// parseGita reads gita's repos.csv (path,name,type,flags). Older gita
+// versions stored one path per line, which parses the same way.
+func parseGita(data []byte) ([]Entry, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(records))
+	for _, record := range records {
+		if len(record) == 0 {
+			continue
+		}
+		path := strings.TrimSpace(record[0])
+		if path == "" || strings.HasPrefix(path, "#") {
+			continue
+		}
+		entries = append(entries, Entry{Path: path})
+	}
+	return entries, nil
+}
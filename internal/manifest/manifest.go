@@ -0,0 +1,160 @@
+// Package manifest reads multi-repository manifests written by other tools
+// (Google repo, vcstool, gita) into a flat list of repositories gitbatch can
+// load or clone.
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Format identifies a manifest file format.
+type Format string
+
+const (
+	// FormatRepo is the XML manifest used by Google's repo tool.
+	FormatRepo Format = "repo"
+	// FormatVcstool is the YAML .repos file used by vcstool.
+	FormatVcstool Format = "vcstool"
+	// FormatGita is the repos.csv file maintained by gita.
+	FormatGita Format = "gita"
+	// FormatGitbatch is the YAML or JSON workspace file written by gitbatch.
+	FormatGitbatch Format = "gitbatch"
+)
+
+// Entry describes one repository listed in a manifest.
+type Entry struct {
+	// Path is the absolute checkout location of the repository.
+	Path string
+	// URL is the clone URL. It may be empty when the manifest only lists paths.
+	URL string
+	// Revision is the branch, tag or commit the manifest pins, if any.
+	Revision string
+	// RemoteName names the remote cloned from URL; empty means "origin".
+	RemoteName string
+	// Remotes lists additional remotes to configure after cloning.
+	Remotes []Remote
+}
+
+// Remote is a named remote URL of a manifest entry.
+type Remote struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+// ParseFormat converts a user supplied format name into a Format.
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case FormatRepo:
+		return FormatRepo, nil
+	case FormatVcstool, "vcs", "repos":
+		return FormatVcstool, nil
+	case FormatGita:
+		return FormatGita, nil
+	case FormatGitbatch:
+		return FormatGitbatch, nil
+	default:
+		return "", fmt.Errorf("unknown manifest format: %s", value)
+	}
+}
+
+// DetectFormat guesses the manifest format from the file name.
+func DetectFormat(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		return FormatRepo, nil
+	case ".repos", ".yaml", ".yml":
+		return FormatVcstool, nil
+	case ".csv":
+		return FormatGita, nil
+	case ".json":
+		return FormatGitbatch, nil
+	default:
+		return "", fmt.Errorf("cannot detect manifest format of %s", path)
+	}
+}
+
+// Load reads the manifest at path. An empty format is detected from the file
+// name. Relative repository paths are resolved against the manifest's
+// directory.
+func Load(path string, format Format) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		detected, err := DetectFormat(path)
+		if err != nil {
+			return nil, err
+		}
+		// vcstool and gitbatch both use YAML; gitbatch lists repositories
+		// as a sequence rather than a mapping.
+		if detected == FormatVcstool && isWorkspaceYAML(data) {
+			detected = FormatGitbatch
+		}
+		format = detected
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	baseDir := filepath.Dir(absPath)
+
+	var entries []Entry
+	switch format {
+	case FormatRepo:
+		entries, err = parseRepoManifest(data)
+	case FormatVcstool:
+		entries, err = parseVcstool(data)
+	case FormatGita:
+		entries, err = parseGita(data)
+	case FormatGitbatch:
+		entries, err = parseWorkspace(data)
+	default:
+		return nil, fmt.Errorf("unknown manifest format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s manifest %s: %w", format, path, err)
+	}
+
+	for i := range entries {
+		entries[i].Path = resolvePath(baseDir, entries[i].Path)
+	}
+	return entries, nil
+}
+
+// Missing returns the entries whose checkout path does not exist yet.
+func Missing(entries []Entry) []Entry {
+	missing := make([]Entry, 0)
+	for _, e := range entries {
+		if _, err := os.Stat(e.Path); os.IsNotExist(err) {
+			missing = append(missing, e)
+		}
+	}
+	return missing
+}
+
+// Existing returns the checkout paths of entries that are git repositories.
+func Existing(entries []Entry) []string {
+	dirs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if _, err := os.Stat(filepath.Join(e.Path, ".git")); err == nil {
+			dirs = append(dirs, e.Path)
+		}
+	}
+	return dirs
+}
+
+func resolvePath(baseDir, p string) string {
+	if strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, p[2:])
+		}
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(baseDir, p)
+	}
+	return filepath.Clean(p)
+}
@@ -0,0 +1,171 @@
+package manifest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const repoXML = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest>
+  <remote name="origin" fetch="https://example.com/" />
+  <remote name="mirror" fetch="ssh://git@mirror.example.com/base" revision="stable" />
+  <default remote="origin" revision="main" />
+  <project name="platform/build" path="build" />
+  <project name="tools/lint" remote="mirror" />
+  <project name="docs" revision="v1.0" />
+</manifest>`
+
+const vcstoolYAML = `repositories:
+  src/core:
+    type: git
+    url: https://github.com/org/core.git
+    version: main
+  src/legacy:
+    type: svn
+    url: https://svn.example.com/legacy
+  src/tools:
+    type: git
+    url: git@github.com:org/tools.git
+`
+
+const gitaCSV = `/work/api,api,,
+/work/web,web,,--no-pager
+`
+
+func TestParseRepoManifest(t *testing.T) {
+	entries, err := parseRepoManifest([]byte(repoXML))
+	require.NoError(t, err)
+	require.Equal(t, []Entry{
+		{Path: "build", URL: "https://example.com/platform/build", Revision: "main"},
+		{Path: "tools/lint", URL: "ssh://git@mirror.example.com/base/tools/lint", Revision: "stable"},
+		{Path: "docs", URL: "https://example.com/docs", Revision: "v1.0"},
+	}, entries)
+}
+
+func TestParseVcstool(t *testing.T) {
+	entries, err := parseVcstool([]byte(vcstoolYAML))
+	require.NoError(t, err)
+	require.Equal(t, []Entry{
+		{Path: "src/core", URL: "https://github.com/org/core.git", Revision: "main"},
+		{Path: "src/tools", URL: "git@github.com:org/tools.git"},
+	}, entries)
+
+	_, err = parseVcstool([]byte("foo: bar\n"))
+	require.Error(t, err)
+}
+
+func TestParseGita(t *testing.T) {
+	entries, err := parseGita([]byte(gitaCSV))
+	require.NoError(t, err)
+	require.Equal(t, []Entry{{Path: "/work/api"}, {Path: "/work/web"}}, entries)
+
+	entries, err = parseGita([]byte("/legacy/one\n/legacy/two\n"))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
+
+func TestDetectAndParseFormat(t *testing.T) {
+	for path, expected := range map[string]Format{
+		"default.xml": FormatRepo,
+		"deps.repos":  FormatVcstool,
+		"ws.yaml":     FormatVcstool,
+		"repos.csv":   FormatGita,
+	} {
+		format, err := DetectFormat(path)
+		require.NoError(t, err)
+		require.Equal(t, expected, format, path)
+	}
+	_, err := DetectFormat("manifest.txt")
+	require.Error(t, err)
+
+	format, err := ParseFormat("VCS")
+	require.NoError(t, err)
+	require.Equal(t, FormatVcstool, format)
+	_, err = ParseFormat("mr")
+	require.Error(t, err)
+}
+
+func TestLoadResolvesRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ws.repos")
+	require.NoError(t, os.WriteFile(path, []byte(vcstoolYAML), 0o644))
+
+	entries, err := Load(path, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, filepath.Join(dir, "src", "core"), entries[0].Path)
+	require.Len(t, Missing(entries), 2)
+	require.Empty(t, Existing(entries))
+}
+
+func TestClone(t *testing.T) {
+	root := t.TempDir()
+	origin := filepath.Join(root, "origin")
+	require.NoError(t, os.MkdirAll(origin, 0o755))
+	for _, args := range [][]string{
+		{"init", "--initial-branch=main"},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = origin
+		out, err := cmd.CombinedOutput()
+		require.NoErrorf(t, err, "git %v failed: %s", args, string(out))
+	}
+
+	entry := Entry{Path: filepath.Join(root, "ws", "checkout"), URL: origin, Revision: "main"}
+	require.NoError(t, Clone(entry))
+	require.Equal(t, []string{entry.Path}, Existing([]Entry{entry}))
+
+	require.Error(t, Clone(Entry{Path: filepath.Join(root, "nourl")}))
+}
+
+func TestCloneAtACommit(t *testing.T) {
+	root := t.TempDir()
+	origin := filepath.Join(root, "origin")
+	require.NoError(t, os.MkdirAll(origin, 0o755))
+	git := func(dir string, args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoErrorf(t, err, "git %v failed: %s", args, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	git(origin, "init", "--initial-branch=main")
+	git(origin, "commit", "--allow-empty", "-m", "first")
+	first := git(origin, "rev-parse", "HEAD")
+	git(origin, "commit", "--allow-empty", "-m", "second")
+
+	entry := Entry{Path: filepath.Join(root, "ws", "pinned"), URL: origin, Revision: first}
+	require.NoError(t, Clone(entry))
+	require.Equal(t, first, git(entry.Path, "rev-parse", "HEAD"))
+
+	git(origin, "update-ref", "refs/changes/01/1/1", first)
+	entry = Entry{Path: filepath.Join(root, "ws", "change"), URL: origin, Revision: "refs/changes/01/1/1"}
+	require.NoError(t, Clone(entry))
+	require.Equal(t, first, git(entry.Path, "rev-parse", "HEAD"))
+}
+
+func TestCloneRevision(t *testing.T) {
+	tests := []struct {
+		revision, branch, detached string
+	}{
+		{"", "", ""},
+		{"main", "main", ""},
+		{"v1.0", "v1.0", ""},
+		{"refs/heads/stable", "stable", ""},
+		{"refs/tags/v1.0", "v1.0", ""},
+		{"refs/changes/01/1/1", "", "refs/changes/01/1/1"},
+		{"0123abc", "", "0123abc"},
+		{"3f5c2a9e0b1d4c6e8f7a9b0c1d2e3f4a5b6c7d8e", "", "3f5c2a9e0b1d4c6e8f7a9b0c1d2e3f4a5b6c7d8e"},
+	}
+	for _, test := range tests {
+		branch, detached := cloneRevision(test.revision)
+		require.Equal(t, test.branch, branch, test.revision)
+		require.Equal(t, test.detached, detached, test.revision)
+	}
+}
@@ -0,0 +1,74 @@
+package manifest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+type repoManifest struct {
+	Remotes  []repoRemote  `xml:"remote"`
+	Default  repoDefault   `xml:"default"`
+	Projects []repoProject `xml:"project"`
+}
+
+type repoRemote struct {
+	Name     string `xml:"name,attr"`
+	Fetch    string `xml:"fetch,attr"`
+	Revision string `xml:"revision,attr"`
+}
+
+type repoDefault struct {
+	Remote   string `xml:"remote,attr"`
+	Revision string `xml:"revision,attr"`
+}
+
+type repoProject struct {
+	Name     string `xml:"name,attr"`
+	Path     string `xml:"path,attr"`
+	Remote   string `xml:"remote,attr"`
+	Revision string `xml:"revision,attr"`
+}
+
+// parseRepoManifest reads a Google repo manifest. Project URLs are the
+// remote's fetch base joined with the project name; revisions fall back to
+// the remote and then the <default> element.
+func parseRepoManifest(data []byte) ([]Entry, error) {
+	var m repoManifest
+	if err := xml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	remotes := make(map[string]repoRemote, len(m.Remotes))
+	for _, r := range m.Remotes {
+		remotes[r.Name] = r
+	}
+
+	entries := make([]Entry, 0, len(m.Projects))
+	for _, p := range m.Projects {
+		if p.Name == "" {
+			return nil, fmt.Errorf("project without name")
+		}
+		path := p.Path
+		if path == "" {
+			path = p.Name
+		}
+		remoteName := p.Remote
+		if remoteName == "" {
+			remoteName = m.Default.Remote
+		}
+		remote := remotes[remoteName]
+		revision := p.Revision
+		if revision == "" {
+			revision = remote.Revision
+		}
+		if revision == "" {
+			revision = m.Default.Revision
+		}
+		url := ""
+		if remote.Fetch != "" {
+			url = strings.TrimSuffix(remote.Fetch, "/") + "/" + p.Name
+		}
+		entries = append(entries, Entry{Path: path, URL: url, Revision: revision})
+	}
+	return entries, nil
+}
@@ -0,0 +1,45 @@
+package manifest
+
+import (
+	"fmt"
+	"sort"
+
+	"go.yaml.in/yaml/v3"
+)
+
+type vcstoolFile struct {
+	Repositories map[string]vcstoolRepository `yaml:"repositories"`
+}
+
+type vcstoolRepository struct {
+	Type    string `yaml:"type"`
+	URL     string `yaml:"url"`
+	Version string `yaml:"version"`
+}
+
+// parseVcstool reads a vcstool .repos file. Non-git entries are skipped.
+func parseVcstool(data []byte) ([]Entry, error) {
+	var f vcstoolFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Repositories == nil {
+		return nil, fmt.Errorf("missing repositories section")
+	}
+
+	paths := make([]string, 0, len(f.Repositories))
+	for path := range f.Repositories {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	entries := make([]Entry, 0, len(paths))
+	for _, path := range paths {
+		repo := f.Repositories[path]
+		if repo.Type != "" && repo.Type != "git" {
+			continue
+		}
+		entries = append(entries, Entry{Path: path, URL: repo.URL, Revision: repo.Version})
+	}
+	return entries, nil
+}
@@ -0,0 +1,108 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Workspace is gitbatch's own manifest format. It records every repository
+// with its checked out branch and all remotes so the workspace can be
+// reproduced with --import and --clone-missing.
+type Workspace struct {
+	Repositories []WorkspaceRepository `json:"repositories" yaml:"repositories"`
+}
+
+// WorkspaceRepository is a single repository of a Workspace. Primary names
+// the remote to clone from; it defaults to "origin".
+type WorkspaceRepository struct {
+	Path    string   `json:"path" yaml:"path"`
+	Branch  string   `json:"branch,omitempty" yaml:"branch,omitempty"`
+	Primary string   `json:"primary,omitempty" yaml:"primary,omitempty"`
+	Remotes []Remote `json:"remotes,omitempty" yaml:"remotes,omitempty"`
+}
+
+// Encoding selects how a Workspace is serialized.
+type Encoding string
+
+const (
+	// EncodingYAML writes the workspace as YAML.
+	EncodingYAML Encoding = "yaml"
+	// EncodingJSON writes the workspace as indented JSON.
+	EncodingJSON Encoding = "json"
+)
+
+// EncodingFor picks the encoding matching the file extension, defaulting to YAML.
+func EncodingFor(path string) Encoding {
+	if filepath.Ext(path) == ".json" {
+		return EncodingJSON
+	}
+	return EncodingYAML
+}
+
+// Write serializes the workspace to w.
+func (ws Workspace) Write(w io.Writer, encoding Encoding) error {
+	switch encoding {
+	case EncodingJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(ws)
+	case EncodingYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(ws); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unknown encoding: %s", encoding)
+	}
+}
+
+// parseWorkspace reads a gitbatch workspace. The primary remote, "origin"
+// unless the repository names another one, becomes the clone URL and keeps
+// its name; the others are added after cloning.
+func parseWorkspace(data []byte) ([]Entry, error) {
+	var ws Workspace
+	// JSON is valid YAML, so a single decoder handles both encodings.
+	if err := yaml.Unmarshal(data, &ws); err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(ws.Repositories))
+	for _, repo := range ws.Repositories {
+		if repo.Path == "" {
+			return nil, fmt.Errorf("repository without path")
+		}
+		entry := Entry{Path: repo.Path, Revision: repo.Branch}
+		primary := repo.Primary
+		if primary == "" {
+			primary = "origin"
+		}
+		for _, remote := range repo.Remotes {
+			if remote.Name == primary {
+				entry.URL = remote.URL
+				entry.RemoteName = remote.Name
+				continue
+			}
+			entry.Remotes = append(entry.Remotes, remote)
+		}
+		if entry.URL == "" && repo.Primary != "" {
+			return nil, fmt.Errorf("%s: primary remote %s is not listed", repo.Path, repo.Primary)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func isWorkspaceYAML(data []byte) bool {
+	var probe struct {
+		Repositories yaml.Node `yaml:"repositories"`
+	}
+	if err := yaml.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return probe.Repositories.Kind == yaml.SequenceNode
+}
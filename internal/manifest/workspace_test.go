@@ -0,0 +1,69 @@
+package manifest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceRoundTrip(t *testing.T) {
+	ws := Workspace{Repositories: []WorkspaceRepository{
+		{Path: "api", Branch: "main", Remotes: []Remote{
+			{Name: "upstream", URL: "https://example.com/org/api.git"},
+			{Name: "origin", URL: "git@example.com:me/api.git"},
+		}},
+		{Path: "fork", Primary: "upstream", Remotes: []Remote{
+			{Name: "upstream", URL: "https://example.com/org/fork.git"},
+			{Name: "mine", URL: "git@example.com:me/fork.git"},
+		}},
+		{Path: "local"},
+	}}
+
+	for _, encoding := range []Encoding{EncodingYAML, EncodingJSON} {
+		var buf bytes.Buffer
+		require.NoError(t, ws.Write(&buf, encoding))
+
+		entries, err := parseWorkspace(buf.Bytes())
+		require.NoError(t, err, string(encoding))
+		require.Equal(t, []Entry{
+			{Path: "api", URL: "git@example.com:me/api.git", RemoteName: "origin", Revision: "main", Remotes: []Remote{
+				{Name: "upstream", URL: "https://example.com/org/api.git"},
+			}},
+			{Path: "fork", URL: "https://example.com/org/fork.git", RemoteName: "upstream", Remotes: []Remote{
+				{Name: "mine", URL: "git@example.com:me/fork.git"},
+			}},
+			{Path: "local"},
+		}, entries, string(encoding))
+	}
+}
+
+func TestLoadDetectsWorkspaceYAML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "workspace.yaml")
+	content := "repositories:\n  - path: api\n    remotes:\n      - name: origin\n        url: https://example.com/api.git\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	entries, err := Load(path, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, filepath.Join(dir, "api"), entries[0].Path)
+	require.Equal(t, "https://example.com/api.git", entries[0].URL)
+}
+
+func TestWorkspaceWithoutOriginNeedsAPrimaryRemote(t *testing.T) {
+	entries, err := parseWorkspace([]byte("repositories:\n  - path: api\n    remotes:\n      - name: upstream\n        url: https://example.com/api.git\n"))
+	require.NoError(t, err)
+	require.Equal(t, []Entry{{Path: "api", Remotes: []Remote{{Name: "upstream", URL: "https://example.com/api.git"}}}}, entries, "no remote is picked implicitly")
+
+	_, err = parseWorkspace([]byte("repositories:\n  - path: api\n    primary: mirror\n"))
+	require.Error(t, err)
+}
+
+func TestEncodingFor(t *testing.T) {
+	require.Equal(t, EncodingJSON, EncodingFor("ws.json"))
+	require.Equal(t, EncodingYAML, EncodingFor("ws.yaml"))
+	require.Equal(t, EncodingYAML, EncodingFor("-"))
+}
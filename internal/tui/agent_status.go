@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/thorstenhirsch/gitbatch/internal/agent"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// agentStatusFile is the status file `gitbatch agent` writes. While it is
+// fresh, the initial state probe trusts it instead of fetching again.
+var agentStatusFile atomic.Value
+
+// SetAgentStatusFile configures where the interface looks for the status
+// written by the agent.
+func SetAgentStatusFile(path string) {
+	agentStatusFile.Store(path)
+}
+
+// loadAgentStatus returns the agent status, or nil if there is none or it is
+// too old to be trusted.
+func loadAgentStatus(now time.Time) *agent.Status {
+	path, _ := agentStatusFile.Load().(string)
+	if path == "" {
+		return nil
+	}
+	status, err := agent.ReadStatus(path)
+	if err != nil || !status.Fresh(now) {
+		return nil
+	}
+	return status
+}
+
+// agentProbeOutcome completes the initial state probe of repo from the agent
+// status. Only repositories the agent fetched on the branch that is checked
+// out now qualify; the ahead and behind counts and the working tree are still
+// evaluated locally.
+func agentProbeOutcome(status *agent.Status, repo *git.Repository, now time.Time) (command.OperationOutcome, bool) {
+	if status == nil || repo == nil || repo.State == nil || repo.State.Branch == nil || repo.State.Branch.Upstream == nil {
+		return command.OperationOutcome{}, false
+	}
+	entry, ok := status.Repository(repo.AbsPath)
+	if !ok || entry.Error != "" || entry.Fetched.IsZero() || entry.Branch != repo.State.Branch.Name {
+		return command.OperationOutcome{}, false
+	}
+	minutes := int(now.Sub(entry.Fetched).Minutes())
+	return command.OperationOutcome{
+		Operation: command.OperationStateProbe,
+		Message:   i18n.T("fetched by the agent %d min ago", minutes),
+	}, true
+}
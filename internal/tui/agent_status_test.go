@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/agent"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+)
+
+func TestAgentStatusReplacesTheInitialFetchWhileFresh(t *testing.T) {
+	now := time.Now()
+	repo := queueTestRepo("api", true, true, true)
+	repo.AbsPath = filepath.Join(t.TempDir(), "api")
+	statusFile := filepath.Join(t.TempDir(), "status.json")
+	require.NoError(t, agent.WriteStatus(statusFile, &agent.Status{
+		Updated:  now.Add(-5 * time.Minute),
+		Interval: "15m0s",
+		Repositories: []agent.RepositoryStatus{
+			{Path: repo.AbsPath, Name: "api", Branch: "main", Fetched: now.Add(-5 * time.Minute)},
+		},
+	}))
+	SetAgentStatusFile(statusFile)
+	t.Cleanup(func() { SetAgentStatusFile("") })
+
+	status := loadAgentStatus(now)
+	require.NotNil(t, status)
+	outcome, ok := agentProbeOutcome(status, repo, now)
+	require.True(t, ok)
+	assert.Equal(t, command.OperationStateProbe, outcome.Operation)
+	assert.Equal(t, "fetched by the agent 5 min ago", outcome.Message)
+
+	repo.State.Branch.Name = "feature"
+	_, ok = agentProbeOutcome(status, repo, now)
+	assert.False(t, ok, "the agent fetched another branch")
+
+	assert.Nil(t, loadAgentStatus(now.Add(time.Hour)), "stale status is ignored")
+}
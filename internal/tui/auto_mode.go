@@ -0,0 +1,100 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// AutoMode is the --mode that picks pull or push from the state of the
+// workspace once the startup fetch is done.
+const AutoMode = "auto"
+
+// autoModeChoice is the mode auto mode picked and why. The model keeps it
+// until the first batch confirms it or the mode is switched; autoModePending
+// is set until the startup fetch has settled.
+type autoModeChoice struct {
+	mode   Mode
+	reason string
+}
+
+// chooseAutoMode picks push when more repositories are ahead of their
+// upstream than behind it, pull otherwise. Only repositories a batch can run
+// in count; diverged ones count for neither, the reason mentions them.
+func chooseAutoMode(repos []*git.Repository) autoModeChoice {
+	total, ahead, behind, diverged := 0, 0, 0, 0
+	for _, r := range repos {
+		if !repoIsActionable(r) || r.State == nil || r.State.Branch == nil {
+			continue
+		}
+		pushable, okPush := r.State.Branch.PushableCount()
+		pullable, okPull := r.State.Branch.PullableCount()
+		if !okPush || !okPull {
+			continue
+		}
+		total++
+		switch {
+		case pushable > 0 && pullable > 0:
+			diverged++
+		case pushable > 0:
+			ahead++
+		case pullable > 0:
+			behind++
+		}
+	}
+	if ahead > behind {
+		return autoModeChoice{mode: pushMode, reason: i18n.T("%d of %d repositories are ahead, %d behind, %d diverged", ahead, total, behind, diverged)}
+	}
+	if behind > 0 {
+		return autoModeChoice{mode: pullMode, reason: i18n.T("%d of %d repositories are behind, %d ahead, %d diverged", behind, total, ahead, diverged)}
+	}
+	return autoModeChoice{mode: pullMode, reason: i18n.T("none of %d repositories is behind or ahead, %d diverged", total, diverged)}
+}
+
+// maybeChooseAutoMode switches to the mode auto mode picks once the startup
+// fetch has settled. The choice has to be confirmed before the first batch
+// runs in it.
+func (m *Model) maybeChooseAutoMode() {
+	if !m.autoModePending || m.loading || !m.initialStateProbeStarted || m.jobsRunning {
+		return
+	}
+	m.autoModePending = false
+	choice := chooseAutoMode(m.repositories)
+	m.mode = choice.mode
+	m.autoModeChoice = &choice
+	m.notice = i18n.T("auto mode chose %s: %s", string(choice.mode.ID), choice.reason)
+}
+
+// confirmAutoMode asks to confirm the mode auto mode picked before the first
+// batch starts in it, and reports whether it did.
+func (m *Model) confirmAutoMode(limit int) bool {
+	if m.autoModeChoice == nil || m.autoModeChoice.mode.ID != m.mode.ID || len(m.queuedModes()) == 0 {
+		return false
+	}
+	m.autoModePromptLimit = limit
+	m.autoModePromptActive = true
+	return true
+}
+
+// handleAutoModePromptKey starts the batch on return and leaves the mode
+// for the user to change on esc.
+func (m *Model) handleAutoModePromptKey(key string) tea.Cmd {
+	switch key {
+	case "y", "Y", "enter":
+		m.autoModePromptActive = false
+		m.autoModeChoice = nil
+		return m.startQueueLimit(m.autoModePromptLimit)
+	case "n", "N", "esc":
+		m.autoModePromptActive = false
+		m.notice = i18n.T("m: switch the mode")
+	}
+	return nil
+}
+
+// autoModePromptText describes the choice for the status bar.
+func (m *Model) autoModePromptText() string {
+	if m.autoModeChoice == nil {
+		return ""
+	}
+	return i18n.T("Start the batch in %s mode? %s", string(m.autoModeChoice.mode.ID), m.autoModeChoice.reason)
+}
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// repoWithCounts returns an available repository that is ahead and behind
+// its upstream by the given number of commits.
+func repoWithCounts(name string, ahead, behind int) *git.Repository {
+	repo := &git.Repository{RepoID: name, Name: name, State: &git.RepositoryState{
+		Branch: &git.Branch{
+			Name:      "main",
+			Upstream:  &git.RemoteBranch{Name: "origin/main"},
+			Pushables: fmt.Sprint(ahead),
+			Pullables: fmt.Sprint(behind),
+			Clean:     true,
+		},
+	}}
+	repo.SetWorkStatusSilent(git.Available)
+	return repo
+}
+
+func TestChooseAutoMode(t *testing.T) {
+	choice := chooseAutoMode([]*git.Repository{
+		repoWithCounts("api", 2, 0),
+		repoWithCounts("web", 1, 0),
+		repoWithCounts("docs", 0, 3),
+		repoWithCounts("infra", 1, 1),
+	})
+	require.Equal(t, PushMode, choice.mode.ID)
+	require.Equal(t, "2 of 4 repositories are ahead, 1 behind, 1 diverged", choice.reason)
+
+	choice = chooseAutoMode([]*git.Repository{repoWithCounts("api", 1, 0), repoWithCounts("web", 0, 1)})
+	require.Equal(t, PullMode, choice.mode.ID, "a tie pulls")
+
+	choice = chooseAutoMode([]*git.Repository{repoWithCounts("api", 0, 0)})
+	require.Equal(t, PullMode, choice.mode.ID)
+	require.Equal(t, "none of 1 repositories is behind or ahead, 0 diverged", choice.reason)
+}
+
+func TestAutoModeIsConfirmedBeforeTheFirstBatch(t *testing.T) {
+	api, web := repoWithCounts("api", 1, 0), repoWithCounts("web", 2, 0)
+	m := New(AutoMode, nil)
+	m.repositories = []*git.Repository{api, web}
+	m.width, m.height = 120, 30
+	m.loading = false
+	m.initialStateProbeStarted = true
+
+	m.maybeChooseAutoMode()
+	require.Equal(t, PushMode, m.mode.ID)
+	require.Contains(t, m.notice, "auto mode chose push: 2 of 2 repositories are ahead")
+
+	api.SetWorkStatusSilent(git.Queued)
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.True(t, m.autoModePromptActive)
+	require.Contains(t, m.autoModePromptText(), "Start the batch in push mode?")
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	require.False(t, m.autoModePromptActive)
+	require.Equal(t, git.Queued, api.WorkStatus(), "esc starts nothing")
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.False(t, m.autoModePromptActive, "a mode switched by hand needs no confirmation")
+}
@@ -6,32 +6,42 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
 	"github.com/thorstenhirsch/gitbatch/internal/load"
 )
 
 // loadProgressCh carries incremental load counts from the worker pool to the TUI.
 var loadProgressCh = make(chan int, 256)
 
-// loadRepositoriesCmd returns a command that loads repositories.
-// When len(directories) > loadingScreenThreshold it also reports progress via
-// loadProgressCh so the loading screen can show a progress bar.
+// repositoryLoadedCh carries repositories from the worker pool to the TUI as
+// soon as they are fast-initialized, so rows appear before their branches and
+// remotes are loaded. A nil value ends the stream.
+var repositoryLoadedCh = make(chan *git.Repository, 256)
+
+// loadRepositoriesCmd returns a command that loads repositories. Each one is
+// streamed via repositoryLoadedCh right after FastInitializeRepo; load counts
+// go to loadProgressCh so the loading screen can show a progress bar and rows
+// are redrawn as their details fill in.
 func loadRepositoriesCmd(directories []string) tea.Cmd {
 	return func() tea.Msg {
 		if len(directories) == 0 {
 			return errMsg{err: fmt.Errorf("no directories provided")}
 		}
 
-		var progressCh chan<- int
-		if len(directories) > loadingScreenThreshold {
-			progressCh = loadProgressCh
-		}
-
-		repos, err := load.SyncLoadWithProgress(directories, progressCh)
+		repos, skipped, err := load.StreamLoad(directories, repositoryLoadedCh, loadProgressCh)
 		if err != nil {
 			return errMsg{err: err}
 		}
 
-		return repositoriesLoadedMsg{repos: repos}
+		return repositoriesLoadedMsg{repos: repos, skipped: skipped}
+	}
+}
+
+// listenRepositoryLoadedCmd returns a command that waits for the next
+// streamed repository and returns it as a repositoryLoadedMsg.
+func listenRepositoryLoadedCmd() tea.Cmd {
+	return func() tea.Msg {
+		return repositoryLoadedMsg{repo: <-repositoryLoadedCh}
 	}
 }
 
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// credentialGuidanceThreshold is the number of failed retries with the same
+// credentials after which the prompt explains the host's token requirements.
+const credentialGuidanceThreshold = 2
+
+// credentialFailure counts consecutive failed retries against one host.
+type credentialFailure struct {
+	user     string
+	password string
+	count    int
+}
+
+// credentialGuidance explains how a host expects git clients to authenticate.
+type credentialGuidance struct {
+	text     string
+	tokenURL string
+}
+
+// guidanceForHost returns tailored advice for well-known hosting services and
+// a generic hint for everything else.
+func guidanceForHost(host string) credentialGuidance {
+	host = strings.ToLower(host)
+	switch {
+	case host == "github.com":
+		return credentialGuidance{
+			text:     "GitHub requires a personal access token instead of your account password.",
+			tokenURL: "https://github.com/settings/tokens",
+		}
+	case host == "bitbucket.org":
+		return credentialGuidance{
+			text:     "Bitbucket requires an app password or API token instead of your account password.",
+			tokenURL: "https://bitbucket.org/account/settings/app-passwords/",
+		}
+	case host == "dev.azure.com" || strings.HasSuffix(host, ".visualstudio.com"):
+		return credentialGuidance{
+			text:     "Azure DevOps requires a personal access token instead of your account password.",
+			tokenURL: "https://dev.azure.com/_usersSettings/tokens",
+		}
+	case host == "gitlab.com" || strings.Contains(host, "gitlab"):
+		return credentialGuidance{
+			text:     "GitLab with two-factor authentication requires a personal access token as password.",
+			tokenURL: "https://" + host + "/-/user_settings/personal_access_tokens",
+		}
+	default:
+		return credentialGuidance{
+			text: "If the server uses two-factor authentication, use a personal access token as password.",
+		}
+	}
+}
+
+// noteFailedCredentialRetry records that the last credential retry of repo
+// did not succeed. Failures with identical credentials accumulate per host.
+func (m *Model) noteFailedCredentialRetry(repo *git.Repository) {
+	if repo == nil {
+		return
+	}
+	creds, ok := m.credentialRetries[repo.RepoID]
+	if !ok {
+		return
+	}
+	delete(m.credentialRetries, repo.RepoID)
+	host := repositoryHost(repo)
+	if m.credentialFailures == nil {
+		m.credentialFailures = make(map[string]credentialFailure)
+	}
+	failure := m.credentialFailures[host]
+	if failure.user == creds.User && failure.password == creds.Password {
+		failure.count++
+	} else {
+		failure = credentialFailure{user: creds.User, password: creds.Password, count: 1}
+	}
+	m.credentialFailures[host] = failure
+}
+
+// activeCredentialGuidance returns the guidance to show in the active prompt,
+// if its host has failed often enough with the same credentials.
+func (m *Model) activeCredentialGuidance() (credentialGuidance, bool) {
+	if m.activeCredentialPrompt == nil || m.activeCredentialPrompt.passphrase {
+		return credentialGuidance{}, false
+	}
+	host := repositoryHost(m.activeCredentialPrompt.repo)
+	if m.credentialFailures[host].count < credentialGuidanceThreshold {
+		return credentialGuidance{}, false
+	}
+	return guidanceForHost(host), true
+}
+
+// openURLCmd opens a URL in the user's browser.
+func openURLCmd(url string) tea.Cmd {
+	return func() tea.Msg {
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "darwin":
+			cmd = exec.Command("open", url)
+		case "windows":
+			cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+		default:
+			cmd = exec.Command("xdg-open", url)
+		}
+		if err := cmd.Start(); err != nil {
+			return errMsg{err: err}
+		}
+		go func() { _ = cmd.Wait() }()
+		return nil
+	}
+}
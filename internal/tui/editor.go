@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editorCommand builds the command that opens path at line in the user's
+// editor: $VISUAL, then $EDITOR, falling back to vi. Editors differ in how
+// they take a line number, so the well-known exceptions to `+line path` are
+// handled here.
+func editorCommand(path string, line int) *exec.Cmd {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor)
+	if line > 0 {
+		switch filepath.Base(args[0]) {
+		case "code", "code-insiders", "codium":
+			args = append(args, "-g", path+":"+strconv.Itoa(line))
+		case "subl", "zed", "hx", "helix":
+			args = append(args, path+":"+strconv.Itoa(line))
+		default:
+			args = append(args, "+"+strconv.Itoa(line), path)
+		}
+	} else {
+		args = append(args, path)
+	}
+	return exec.Command(args[0], args[1:]...)
+}
+
+// openInEditorCmd suspends the interface while the editor runs.
+func openInEditorCmd(path string, line int) tea.Cmd {
+	return tea.ExecProcess(editorCommand(path, line), func(err error) tea.Msg {
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return nil
+	})
+}
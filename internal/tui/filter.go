@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// repositoryFilter hides the repositories that need no attention from the
+// overview.
+type repositoryFilter uint8
+
+const (
+	filterNone repositoryFilter = iota
+	filterDirty
+	filterBehind
+	filterFailed
+)
+
+var filterNames = []string{"all", "dirty", "behind", "failed"}
+
+var sortNames = map[string]repositorySortMode{
+	"name":   repositorySortByName,
+	"time":   repositorySortByTime,
+	"size":   repositorySortBySize,
+	"behind": repositorySortByBehind,
+}
+
+// startupSort and startupFilter hold what the overview starts with.
+var startupSort, startupFilter atomic.Uint32
+
+// SetStartupSort selects the order the overview starts in: name, time, size
+// or behind. It reports false for other names.
+func SetStartupSort(name string) bool {
+	if name == "" {
+		name = "name"
+	}
+	mode, ok := sortNames[name]
+	if ok {
+		startupSort.Store(uint32(mode))
+	}
+	return ok
+}
+
+// SetStartupFilter selects the repositories the overview starts with:
+// dirty, behind or failed ones, or all of them. It reports false for other
+// names.
+func SetStartupFilter(name string) bool {
+	if name == "" {
+		name = "all"
+	}
+	for i, filterName := range filterNames {
+		if filterName == name {
+			startupFilter.Store(uint32(i))
+			return true
+		}
+	}
+	return false
+}
+
+func (f repositoryFilter) String() string {
+	return filterNames[f]
+}
+
+// matches reports whether the filter shows r.
+func (f repositoryFilter) matches(r *git.Repository) bool {
+	switch f {
+	case filterDirty:
+		return repoIsDirty(r) || repoHasLocalChanges(r)
+	case filterBehind:
+		return behindCount(r) > 0
+	case filterFailed:
+		return r.WorkStatus() == git.Fail
+	default:
+		return true
+	}
+}
+
+// behindCount returns the number of commits r is behind its upstream, or -1
+// when it is not known.
+func behindCount(r *git.Repository) int {
+	if r == nil || r.State == nil || r.State.Branch == nil {
+		return -1
+	}
+	behind, err := strconv.Atoi(r.State.Branch.Pullables)
+	if err != nil {
+		return -1
+	}
+	return behind
+}
+
+// cycleFilter switches to the next filter and keeps the cursor on a row.
+func (m *Model) cycleFilter() {
+	m.statusFilter = (m.statusFilter + 1) % repositoryFilter(len(filterNames))
+	m.cursor = m.closestSelectableIndex(m.cursor, 1)
+	m.resetCommitScrollForSelected()
+}
+
+// sortRepositoriesByBehind puts the repositories that are furthest behind
+// their upstream first.
+func (m *Model) sortRepositoriesByBehind() {
+	sort.Sort(git.Alphabetical(m.repositories))
+	sort.SliceStable(m.repositories, func(i, j int) bool {
+		return behindCount(m.repositories[i]) > behindCount(m.repositories[j])
+	})
+}
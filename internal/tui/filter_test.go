@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestStartupSortAndFilter(t *testing.T) {
+	api := queueTestRepo("api", true, true, true)
+	api.State.Branch.Pullables = "2"
+	web := queueTestRepo("web", true, true, true)
+	web.State.Branch.Pullables = "7"
+	docs := queueTestRepo("docs", false, true, true)
+	docs.State.Branch.Pullables = "0"
+	broken := queueTestRepo("broken", true, true, true)
+	broken.State.Branch.Pullables = "?"
+	broken.SetWorkStatusSilent(git.Fail)
+
+	require.False(t, SetStartupSort("stars"))
+	require.False(t, SetStartupFilter("stale"))
+	require.True(t, SetStartupSort("behind"))
+	require.True(t, SetStartupFilter("behind"))
+	t.Cleanup(func() {
+		SetStartupSort("")
+		SetStartupFilter("")
+	})
+
+	model := New("pull", nil)
+	model.repositories = []*git.Repository{api, broken, docs, web}
+	model.applyRepositorySort()
+	require.Equal(t, []*git.Repository{web, api, docs, broken}, model.repositories)
+	require.Equal(t, []*git.Repository{web, api}, model.visibleRepositories())
+
+	model.cursor = 1
+	model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	require.Equal(t, filterFailed, model.statusFilter)
+	require.Equal(t, []*git.Repository{broken}, model.visibleRepositories())
+	require.Equal(t, 0, model.cursor, "the cursor stays on a row")
+
+	model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	require.Equal(t, filterNone, model.statusFilter)
+	require.Len(t, model.visibleRepositories(), 4)
+
+	model.statusFilter = filterDirty
+	require.Equal(t, []*git.Repository{docs}, model.visibleRepositories())
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"strconv"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// hintRule suggests the actions that fit a state of the selected repository.
+type hintRule struct {
+	matches func(r *git.Repository, ahead, behind int) bool
+	hints   []string
+}
+
+// stateHintRules drive the hints of the status bar for a repository without
+// local changes. The first rule that matches wins, so the more specific
+// states come first.
+var stateHintRules = []hintRule{
+	{
+		matches: func(r *git.Repository, _, _ int) bool { return !r.HasRemote() },
+		hints:   []string{"no remote: r add remote"},
+	},
+	{
+		matches: func(r *git.Repository, _, _ int) bool {
+			return r.State == nil || r.State.Branch == nil || r.State.Branch.Upstream == nil
+		},
+		hints: []string{"no upstream: r then u set upstream", "f fetch"},
+	},
+	{
+		matches: func(_ *git.Repository, ahead, behind int) bool { return ahead > 0 && behind > 0 },
+		hints:   []string{"diverged: m rebase/merge mode", "F forecast", "p pull", "v compare"},
+	},
+	{
+		matches: func(_ *git.Repository, _, behind int) bool { return behind > 0 },
+		hints:   []string{"behind: p pull", "F forecast", "f fetch"},
+	},
+	{
+		matches: func(_ *git.Repository, ahead, _ int) bool { return ahead > 0 },
+		hints:   []string{"ahead: P push", "f fetch"},
+	},
+	{
+		matches: func(*git.Repository, int, int) bool { return true },
+		hints:   []string{"f fetch", "p pull", "P push"},
+	},
+}
+
+// stateHints returns the translated hints of the first rule that matches r.
+func stateHints(r *git.Repository) []string {
+	if r == nil {
+		return translateHints(stateHintRules[len(stateHintRules)-1].hints)
+	}
+	ahead, behind := 0, 0
+	if r.State != nil && r.State.Branch != nil {
+		ahead, _ = strconv.Atoi(r.State.Branch.Pushables)
+		behind, _ = strconv.Atoi(r.State.Branch.Pullables)
+	}
+	for _, rule := range stateHintRules {
+		if rule.matches(r, ahead, behind) {
+			return translateHints(rule.hints)
+		}
+	}
+	return nil
+}
+
+func translateHints(hints []string) []string {
+	translated := make([]string, len(hints))
+	for i, hint := range hints {
+		translated[i] = i18n.T(hint)
+	}
+	return translated
+}
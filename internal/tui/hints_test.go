@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestStateHintsFollowTheRepositoryState(t *testing.T) {
+	tracked := func(ahead, behind string) *git.Repository {
+		repo := testRepoWithBranch("repo", "main")
+		repo.Remotes = []*git.Remote{{Name: "origin"}}
+		repo.State.Branch.Upstream = &git.RemoteBranch{Name: "origin/main"}
+		repo.State.Branch.Pushables = ahead
+		repo.State.Branch.Pullables = behind
+		return repo
+	}
+	noUpstream := testRepoWithBranch("repo", "main")
+	noUpstream.Remotes = []*git.Remote{{Name: "origin"}}
+
+	tests := []struct {
+		name  string
+		repo  *git.Repository
+		first string
+	}{
+		{"no remote", testRepoWithBranch("repo", "main"), "no remote: r add remote"},
+		{"no upstream", noUpstream, "no upstream: r then u set upstream"},
+		{"diverged", tracked("2", "3"), "diverged: m rebase/merge mode"},
+		{"behind", tracked("0", "3"), "behind: p pull"},
+		{"ahead", tracked("2", "0"), "ahead: P push"},
+		{"in sync", tracked("0", "0"), "f fetch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hints := stateHints(tt.repo)
+			require.NotEmpty(t, hints)
+			assert.Equal(t, tt.first, hints[0])
+			assert.LessOrEqual(t, len(hints), 4)
+		})
+	}
+}
+
+func TestRenderStatusBarShowsHintsForTheSelectedState(t *testing.T) {
+	repo := testRepoWithBranch("repo", "main")
+	repo.Remotes = []*git.Remote{{Name: "origin"}}
+	repo.State.Branch.Upstream = &git.RemoteBranch{Name: "origin/main"}
+	repo.State.Branch.Pullables = "4"
+	repo.State.Branch.Clean = true
+
+	model := Model{width: 160, height: 20, styles: DefaultStyles(), ready: true, mode: modes[0], repositories: []*git.Repository{repo}}
+	statusBar := ansi.Strip(model.renderStatusBar())
+	assert.Contains(t, statusBar, "behind: p pull")
+	assert.NotContains(t, statusBar, "P push")
+}
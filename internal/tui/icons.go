@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// iconsEnabled switches the overview to Nerd Font icons. Without a patched
+// font the glyphs render as boxes, so the plain symbols are the default.
+var iconsEnabled atomic.Bool
+
+// SetIcons configures whether the overview uses Nerd Font icons for the
+// repository state, the hosting provider and the language.
+func SetIcons(enabled bool) {
+	iconsEnabled.Store(enabled)
+}
+
+// stateIcons replaces the status symbols when icons are enabled. The
+// spinner of running jobs keeps its frames.
+var stateIcons = map[string]string{
+	queuedSymbol:       "\uF111", // nf-fa-circle
+	successSymbol:      "\uF00C", // nf-fa-check
+	failSymbol:         "\uF00D", // nf-fa-times
+	dirtySymbol:        "\uF071", // nf-fa-warning
+	localChangesSymbol: "\uF040", // nf-fa-pencil
+	noRemoteSymbol:     "\uF05E", // nf-fa-ban
+	credentialsSymbol:  "\uF084", // nf-fa-key
+	networkSymbol:      "\uF127", // nf-fa-chain_broken
+	conflictSymbol:     "\uE727", // nf-dev-git_merge
+	waitingSymbol:      "\uF252", // nf-fa-hourglass_half
+}
+
+// languageIcons maps the languages git detects to their icons.
+var languageIcons = map[string]string{
+	"C":          "\uE61E", // nf-custom-c
+	"C#":         "\uE648", // nf-seti-c_sharp
+	"C++":        "\uE61D", // nf-custom-cpp
+	"Dart":       "\uE798", // nf-dev-dart
+	"Elixir":     "\uE62D", // nf-custom-elixir
+	"Go":         "\uE627", // nf-seti-go
+	"Haskell":    "\uE777", // nf-dev-haskell
+	"Java":       "\uE738", // nf-dev-java
+	"JavaScript": "\uE74E", // nf-dev-javascript
+	"Kotlin":     "\uE634", // nf-seti-kotlin
+	"Lua":        "\uE620", // nf-seti-lua
+	"PHP":        "\uE73D", // nf-dev-php
+	"Python":     "\uE73C", // nf-dev-python
+	"Ruby":       "\uE739", // nf-dev-ruby
+	"Rust":       "\uE7A8", // nf-dev-rust
+	"Scala":      "\uE737", // nf-dev-scala
+	"Shell":      "\uE795", // nf-dev-terminal
+	"Swift":      "\uE755", // nf-dev-swift
+	"TypeScript": "\uE628", // nf-seti-typescript
+}
+
+const (
+	gitIcon       = "\uE702" // nf-dev-git
+	localRepoIcon = "\uF401" // nf-oct-repo
+)
+
+// statusIcon returns the icon for a status symbol, or the symbol itself when
+// icons are disabled or the symbol has none.
+func statusIcon(symbol string) string {
+	if !iconsEnabled.Load() {
+		return symbol
+	}
+	if icon, ok := stateIcons[symbol]; ok {
+		return icon
+	}
+	return symbol
+}
+
+// providerIcon returns the icon of the service hosting the first remote.
+func providerIcon(r *git.Repository) string {
+	if !r.HasRemote() || len(r.Remotes[0].URL) == 0 {
+		return localRepoIcon
+	}
+	host := strings.ToLower(git.RemoteHost(r.Remotes[0].URL[0]))
+	switch {
+	case strings.Contains(host, "github"):
+		return "\uF09B" // nf-fa-github
+	case strings.Contains(host, "gitlab"):
+		return "\uF296" // nf-fa-gitlab
+	case strings.Contains(host, "bitbucket"):
+		return "\uF171" // nf-fa-bitbucket
+	case host == "dev.azure.com" || strings.HasSuffix(host, ".visualstudio.com"):
+		return "\uEBD8" // nf-cod-azure
+	default:
+		return gitIcon
+	}
+}
+
+// repoIcons returns the provider and language icons shown in front of the
+// repository name, or an empty string when icons are disabled. The language
+// slot stays blank until it is detected, so the name does not shift.
+func repoIcons(r *git.Repository) string {
+	if r == nil || !iconsEnabled.Load() {
+		return ""
+	}
+	language, ok := languageIcons[r.Language]
+	if !ok {
+		language = " "
+	}
+	return providerIcon(r) + " " + language
+}
@@ -0,0 +1,33 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestRepoDisplayNameWithIcons(t *testing.T) {
+	repo := testRepoWithBranch("api", "main")
+	repo.Remotes = []*git.Remote{{Name: "origin", URL: []string{"git@gitlab.example.com:team/api.git"}}}
+	require.Equal(t, "api", repoDisplayName(repo), "icons are off by default")
+
+	SetIcons(true)
+	t.Cleanup(func() { SetIcons(false) })
+	require.Equal(t, "\uF296   api", repoDisplayName(repo), "the language slot is reserved")
+
+	repo.Language = "Go"
+	require.Equal(t, "\uF296 \uE627 api", repoDisplayName(repo))
+
+	repo.Remotes = nil
+	require.Equal(t, localRepoIcon+" \uE627 api", repoDisplayName(repo))
+}
+
+func TestStatusIconFallsBackToSymbols(t *testing.T) {
+	require.Equal(t, successSymbol, statusIcon(successSymbol))
+
+	SetIcons(true)
+	t.Cleanup(func() { SetIcons(false) })
+	require.Equal(t, "\uF00C", statusIcon(successSymbol))
+	require.Equal(t, "⠋", statusIcon("⠋"), "spinner frames stay")
+}
@@ -0,0 +1,417 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// keyBinding ties keys to what they do. Bindings with an action are
+// dispatched by handleOverviewKeys; the others document keys that a panel,
+// prompt or overlay handles itself. The help overlay is generated from the
+// bindings, so it lists exactly the keys that work.
+type keyBinding struct {
+	// keys are the names tea.KeyMsg.String reports.
+	keys []string
+	// label replaces the key names in the help, e.g. "N Enter".
+	label string
+	help  string
+	// action runs the binding; count is the numeric prefix, 0 if none.
+	action func(m *Model, count int) tea.Cmd
+}
+
+// keyGroup is a section of the help overlay.
+type keyGroup struct {
+	title    string
+	bindings []keyBinding
+}
+
+// keyLabel returns how the help shows the keys of a binding.
+func (b keyBinding) keyLabel() string {
+	if b.label != "" {
+		return b.label
+	}
+	return strings.Join(b.keys, "/")
+}
+
+// overviewKeymap lists the keys of the repository overview grouped for the
+// help overlay.
+var overviewKeymap = []keyGroup{
+	{title: "Navigation", bindings: []keyBinding{
+		{keys: []string{"up", "k"}, help: "move up", action: func(m *Model, _ int) tea.Cmd {
+			if m.overviewRowCount() > 0 {
+				m.moveOverviewCursor(-1)
+				m.resetCommitScrollForSelected()
+			}
+			return nil
+		}},
+		{keys: []string{"down", "j"}, help: "move down", action: func(m *Model, _ int) tea.Cmd {
+			if m.overviewRowCount() > 0 {
+				m.moveOverviewCursor(1)
+				m.resetCommitScrollForSelected()
+			}
+			return nil
+		}},
+		{keys: []string{"g", "home"}, label: "g/Home", help: "jump to the top", action: func(m *Model, _ int) tea.Cmd {
+			m.cursor = m.firstSelectableIndex()
+			m.resetCommitScrollForSelected()
+			return nil
+		}},
+		{keys: []string{"G", "end"}, label: "G/End", help: "jump to the bottom", action: func(m *Model, _ int) tea.Cmd {
+			m.cursor = m.findLastNavigableIndex()
+			m.resetCommitScrollForSelected()
+			return nil
+		}},
+		{keys: []string{"pgdown", "ctrl+f"}, label: "PgDn/Ctrl+F", help: "page down", action: func(m *Model, _ int) tea.Cmd {
+			m.moveOverviewPage(m.height-5, 1)
+			return nil
+		}},
+		{keys: []string{"pgup", "ctrl+b"}, label: "PgUp/Ctrl+B", help: "page up", action: func(m *Model, _ int) tea.Cmd {
+			m.moveOverviewPage(m.height-5, -1)
+			return nil
+		}},
+		{keys: []string{"ctrl+d"}, label: "Ctrl+D", help: "half page down", action: func(m *Model, _ int) tea.Cmd {
+			m.moveOverviewPage((m.height-5)/2, 1)
+			return nil
+		}},
+		{keys: []string{"ctrl+u"}, label: "Ctrl+U", help: "half page up", action: func(m *Model, _ int) tea.Cmd {
+			m.moveOverviewPage((m.height-5)/2, -1)
+			return nil
+		}},
+		{keys: []string{"right", "l"}, help: "scroll the commit message right", action: func(m *Model, _ int) tea.Cmd {
+			m.adjustCommitScroll(12)
+			return nil
+		}},
+		{keys: []string{"left", "h"}, help: "scroll the commit message left", action: func(m *Model, _ int) tea.Cmd {
+			m.adjustCommitScroll(-12)
+			return nil
+		}},
+		{keys: []string{"["}, help: "previous tab", action: func(m *Model, _ int) tea.Cmd {
+			m.switchTab(-1)
+			return nil
+		}},
+		{keys: []string{"]"}, help: "next tab", action: func(m *Model, _ int) tea.Cmd {
+			m.switchTab(1)
+			return nil
+		}},
+	}},
+	{title: "Actions", bindings: []keyBinding{
+		{keys: []string{" ", "space"}, label: "Space", help: "tag/untag the repository", action: func(m *Model, _ int) tea.Cmd {
+			return m.toggleQueue()
+		}},
+		{keys: []string{"a"}, help: "tag all", action: func(m *Model, _ int) tea.Cmd {
+			return m.queueAll()
+		}},
+		{keys: []string{"A"}, help: "untag all", action: func(m *Model, _ int) tea.Cmd {
+			return m.unqueueAll()
+		}},
+		{keys: []string{"enter"}, label: "Enter", help: "start the tagged jobs (N Enter: only the first N)", action: func(m *Model, count int) tea.Cmd {
+			repo := m.currentRepository()
+			if repo != nil && repo.State != nil && repo.State.RequiresCredentials {
+				ws := repo.WorkStatus()
+				if ws != git.Working && ws != git.Queued && ws != git.Pending {
+					m.noteFailedCredentialRetry(repo)
+					if cmd, ok := m.retryWithRememberedCredentials(repo); ok {
+						return cmd
+					}
+					m.openCredentialDialog(repo)
+					return nil
+				}
+			}
+			if m.confirmAutoMode(count) {
+				return nil
+			}
+			return m.startQueueLimit(count)
+		}},
+		{keys: []string{"ctrl+x"}, label: "Ctrl+X", help: "cancel the jobs that have not started yet", action: func(m *Model, _ int) tea.Cmd {
+			return m.cancelWaitingJobs()
+		}},
+		{keys: []string{"m"}, help: "cycle the mode", action: func(m *Model, _ int) tea.Cmd {
+			m.cycleMode()
+			return nil
+		}},
+		{keys: []string{"M"}, help: "cycle the mode of the tagged repository", action: func(m *Model, _ int) tea.Cmd {
+			m.cycleRepositoryMode()
+			return nil
+		}},
+		{keys: []string{"F"}, help: "forecast conflicts of the tagged pull/merge batch", action: func(m *Model, _ int) tea.Cmd {
+			return m.openForecast()
+		}},
+		{keys: []string{"c"}, help: "commit, or clear the error", action: func(m *Model, _ int) tea.Cmd {
+			if m.err != nil {
+				m.err = nil
+				return nil
+			}
+			if repo := m.currentRepository(); repo != nil && repo.State != nil && repo.WorkStatus() == git.Fail {
+				repo.State.SetMessage("")
+				return nil
+			}
+			m.openCommitPrompt()
+			return nil
+		}},
+		{keys: []string{"K"}, help: "remove the stale lock file of a failed repository", action: func(m *Model, _ int) tea.Cmd {
+			if repo := m.currentRepository(); repo != nil && repoBlockedByLock(repo) {
+				return m.openLockPrompt(repo)
+			}
+			return nil
+		}},
+		{keys: []string{"V"}, help: "toggle --no-verify (skip hooks)", action: func(m *Model, _ int) tea.Cmd {
+			m.toggleNoVerify(m.panelRepositories())
+			return nil
+		}},
+		{keys: []string{"T"}, help: "pin/unpin: keep at the top and out of tag all and the startup fetch", action: func(m *Model, _ int) tea.Cmd {
+			m.togglePinned(m.panelRepositories())
+			return nil
+		}},
+		{keys: []string{"Z"}, help: "reapply the sparse-checkout patterns", action: func(m *Model, _ int) tea.Cmd {
+			return m.sparseReapplyCmd(m.panelRepositories())
+		}},
+		{keys: []string{"U"}, help: "fetch the complete history of a shallow clone", action: func(m *Model, _ int) tea.Cmd {
+			return m.unshallowCmd(m.panelRepositories())
+		}},
+		{keys: []string{"I"}, help: "apply a patch file or the clipboard (git apply)", action: func(m *Model, _ int) tea.Cmd {
+			m.openPatchPrompt(m.panelRepositories())
+			return nil
+		}},
+		{keys: []string{"C"}, help: "preview and remove untracked and ignored files (git clean)", action: func(m *Model, _ int) tea.Cmd {
+			return m.openCleanPreview(m.panelRepositories())
+		}},
+	}},
+	{title: "Git", bindings: []keyBinding{
+		{keys: []string{"f"}, help: "fetch the repository", action: func(m *Model, _ int) tea.Cmd {
+			repo := m.currentRepository()
+			if repo == nil || !repoIsActionable(repo) {
+				return nil
+			}
+			return m.runFetchForRepo(repo)
+		}},
+		{keys: []string{"p"}, help: "pull the repository", action: func(m *Model, _ int) tea.Cmd {
+			repo := m.currentRepository()
+			if repo == nil || !repoIsActionable(repo) {
+				return nil
+			}
+			return m.runPullForRepo(repo, true)
+		}},
+		{keys: []string{"P"}, help: "push the repository", action: func(m *Model, _ int) tea.Cmd {
+			repo := m.currentRepository()
+			if repo == nil || !repoIsActionable(repo) {
+				return nil
+			}
+			return m.runPushForRepo(repo, false, true, "push queued")
+		}},
+		{keys: []string{"ctrl+o"}, label: "Ctrl+O", help: "open the pull request page of a pushed new branch", action: func(m *Model, _ int) tea.Cmd {
+			repo := m.currentRepository()
+			if repo == nil || repo.State == nil || repo.State.PullRequestURL == "" {
+				m.notice = i18n.T("no new branch pushed that a pull request could be opened for")
+				return nil
+			}
+			return openURLCmd(repo.State.PullRequestURL)
+		}},
+		{keys: []string{"n"}, help: "new branch (worktree in worktree mode)", action: func(m *Model, _ int) tea.Cmd {
+			if m.worktreeMode {
+				m.openWorktreePrompt()
+				return nil
+			}
+			m.openBranchPrompt()
+			return nil
+		}},
+		{keys: []string{"ctrl+z"}, label: "Ctrl+Z", help: "undo the last checkout or branch delete", action: func(m *Model, _ int) tea.Cmd {
+			return m.undoCmd(m.currentRepository(), NonePanel)
+		}},
+		{keys: []string{"S"}, help: "stash", action: func(m *Model, _ int) tea.Cmd {
+			m.openStashPrompt()
+			return nil
+		}},
+		{keys: []string{"O"}, help: "pop a stash", action: func(m *Model, _ int) tea.Cmd {
+			m.openStashAction(stashActionPop)
+			return nil
+		}},
+		{keys: []string{"D"}, help: "drop a stash", action: func(m *Model, _ int) tea.Cmd {
+			m.openStashAction(stashActionDrop)
+			return nil
+		}},
+		{keys: []string{"x"}, help: "prune remote-tracking refs", action: func(m *Model, _ int) tea.Cmd {
+			return m.pruneRemotesCmd(m.panelRepositories())
+		}},
+		{keys: []string{"Y"}, help: "sync the fork: fast-forward the default branch to upstream and push it to origin", action: func(m *Model, _ int) tea.Cmd {
+			return m.syncForksCmd(m.panelRepositories())
+		}},
+		{keys: []string{"o"}, help: "rebase --onto", action: func(m *Model, _ int) tea.Cmd {
+			m.openRebaseOntoPrompt()
+			return nil
+		}},
+		{keys: []string{"E"}, help: "fetch, update the default branch and rebase the current branch onto it", action: func(m *Model, _ int) tea.Cmd {
+			return m.rebaseOnDefaultCmd(m.panelRepositories())
+		}},
+		{keys: []string{"i"}, help: "bisect the repository", action: func(m *Model, _ int) tea.Cmd {
+			m.openBisect()
+			return nil
+		}},
+		{keys: []string{"d"}, help: "delete the worktree (worktree mode)", action: func(m *Model, _ int) tea.Cmd {
+			if m.worktreeMode {
+				return m.deleteSelectedWorktreeCmd()
+			}
+			return nil
+		}},
+		{keys: []string{"L"}, help: "lock/unlock the worktree (worktree mode)", action: func(m *Model, _ int) tea.Cmd {
+			if m.worktreeMode {
+				return m.toggleWorktreeLockCmd()
+			}
+			return nil
+		}},
+		{keys: []string{"X"}, help: "prune stale worktrees (worktree mode)", action: func(m *Model, _ int) tea.Cmd {
+			if m.worktreeMode {
+				return m.pruneWorktreesCmd()
+			}
+			return nil
+		}},
+	}},
+	{title: "Views", bindings: []keyBinding{
+		{keys: []string{"b"}, help: "branches", action: func(m *Model, _ int) tea.Cmd {
+			m.activatePanel(BranchPanel)
+			return nil
+		}},
+		{keys: []string{"ctrl+g"}, label: "Ctrl+G", help: "inline branch switcher", action: func(m *Model, _ int) tea.Cmd {
+			m.openBranchSwitcher()
+			return nil
+		}},
+		{keys: []string{"B"}, help: "expand the branches of all repositories", action: func(m *Model, _ int) tea.Cmd {
+			m.expandBranches = !m.expandBranches
+			return nil
+		}},
+		{keys: []string{"r"}, help: "remotes (add one if there is none)", action: func(m *Model, _ int) tea.Cmd {
+			if repo := m.currentRepository(); repo != nil && !repo.HasRemote() && !m.hasMultipleTagged() {
+				m.openRemotePrompt(repo)
+				return nil
+			}
+			m.activatePanel(RemotePanel)
+			return nil
+		}},
+		{keys: []string{"s"}, help: "status and changed files", action: func(m *Model, _ int) tea.Cmd {
+			if m.requiresSingleSelection("Status view unavailable for tagged selection") {
+				m.activatePanel(StatusPanel)
+				return m.loadStatusFilesCmd(m.currentRepository())
+			}
+			return nil
+		}},
+		{keys: []string{"u"}, help: "reflog", action: func(m *Model, _ int) tea.Cmd {
+			if m.requiresSingleSelection("Reflog view unavailable for tagged selection") {
+				m.activatePanel(ReflogPanel)
+			}
+			return nil
+		}},
+		{keys: []string{"H"}, help: "what gitbatch did in the repository", action: func(m *Model, _ int) tea.Cmd {
+			if m.requiresSingleSelection("History view unavailable for tagged selection") {
+				m.activatePanel(HistoryPanel)
+			}
+			return nil
+		}},
+		{keys: []string{"W"}, help: "toggle worktree mode", action: func(m *Model, _ int) tea.Cmd {
+			m.toggleWorktreeMode()
+			return nil
+		}},
+		{keys: []string{"t"}, help: "sort by name/time/size/behind", action: func(m *Model, _ int) tea.Cmd {
+			return m.toggleRepositorySort()
+		}},
+		{keys: []string{"w"}, help: "show all/dirty/behind/failed repositories", action: func(m *Model, _ int) tea.Cmd {
+			m.cycleFilter()
+			return nil
+		}},
+		{keys: []string{"v"}, help: "compare ahead/behind against a ref", action: func(m *Model, _ int) tea.Cmd {
+			m.openComparePrompt()
+			return nil
+		}},
+		{keys: []string{"!"}, help: "problems (identity check, skipped directories, failures)", action: func(m *Model, _ int) tea.Cmd {
+			return m.openProblems()
+		}},
+		{keys: []string{"/"}, help: "git grep in all or the tagged repositories", action: func(m *Model, _ int) tea.Cmd {
+			m.openGrepPrompt()
+			return nil
+		}},
+		{keys: []string{"R"}, help: "refresh", action: func(m *Model, _ int) tea.Cmd {
+			return m.focusRefreshCmd(true)
+		}},
+		{keys: []string{"ctrl+r"}, label: "Ctrl+R", help: "refresh metadata and re-probe remotes", action: func(m *Model, _ int) tea.Cmd {
+			return m.forceRefreshAllCmd()
+		}},
+	}},
+	{title: "Other", bindings: []keyBinding{
+		{keys: []string{"tab"}, label: "Tab", help: "open lazygit"},
+		{keys: []string{"esc"}, label: "Esc", help: "close the panel or clear results"},
+		{keys: []string{"?"}, help: "help"},
+		{keys: []string{"q", "ctrl+c"}, label: "q/Ctrl+C", help: "quit"},
+	}},
+}
+
+// contextKeymap documents the keys of the panels, overlays and prompts.
+var contextKeymap = []keyGroup{
+	{title: "Branch and remote panels", bindings: []keyBinding{
+		{keys: []string{" ", "c"}, label: "Space/c", help: "check out"},
+		{keys: []string{"d"}, help: "delete"},
+		{keys: []string{"u"}, help: "set as upstream of the current branch (remotes)"},
+		{keys: []string{"a"}, help: "add a remote (remotes)"},
+		{keys: []string{"Y"}, help: "sync the fork with upstream (remotes)"},
+		{keys: []string{"e"}, help: "search and replace in the remote URLs (remotes)"},
+		{keys: []string{"ctrl+z"}, label: "Ctrl+Z", help: "undo the last checkout or branch delete"},
+		{keys: []string{"enter"}, label: "Enter", help: "start the tagged jobs"},
+	}},
+	{title: "Status and reflog panels", bindings: []keyBinding{
+		{keys: []string{"e"}, help: "open the changed file in $EDITOR (status)"},
+		{keys: []string{"enter", "c"}, label: "Enter/c", help: "check out the entry detached (reflog)"},
+		{keys: []string{"n"}, help: "new branch at the entry (reflog)"},
+		{keys: []string{"d"}, help: "show the diff of the file or entry, through diff_filter if set"},
+	}},
+	{title: "Stash panel", bindings: []keyBinding{
+		{keys: []string{"enter"}, label: "Enter", help: "show the diff of the stash, Enter again pops/drops it"},
+		{keys: []string{" "}, label: "Space", help: "pop/drop the stash without the diff"},
+	}},
+	{title: "Forecast, clean, problems and grep", bindings: []keyBinding{
+		{keys: []string{" "}, label: "Space", help: "untag the repository (forecast)"},
+		{keys: []string{"u"}, help: "untag all that would fail (forecast)"},
+		{keys: []string{" "}, label: "Space", help: "skip the repository (clean)"},
+		{keys: []string{"f", "F"}, label: "f/F", help: "fix the selected/all identities (problems)"},
+		{keys: []string{"c"}, help: "clone the quarantined repository again (problems)"},
+		{keys: []string{"enter"}, label: "Enter", help: "start the rest (forecast), remove the files (clean), open the match (grep)"},
+	}},
+	{title: "Bisect", bindings: []keyBinding{
+		{keys: []string{"g", "b", "s"}, help: "mark good/bad/skip"},
+		{keys: []string{"r"}, help: "run bisect_command on the rest"},
+		{keys: []string{"x"}, help: "reset"},
+	}},
+	{title: "Prompts", bindings: []keyBinding{
+		{keys: []string{"enter"}, label: "Enter", help: "next field or confirm"},
+		{keys: []string{"tab"}, label: "Tab", help: "switch field"},
+		{keys: []string{"esc"}, label: "Esc", help: "cancel"},
+		{keys: []string{"ctrl+r"}, label: "Ctrl+R", help: "show/hide the password (credentials)"},
+		{keys: []string{"ctrl+o"}, label: "Ctrl+O", help: "open the token page (credentials)"},
+	}},
+}
+
+// overviewActions indexes the overview bindings by key.
+var overviewActions = func() map[string]func(m *Model, count int) tea.Cmd {
+	actions := make(map[string]func(m *Model, count int) tea.Cmd)
+	for _, group := range overviewKeymap {
+		for _, binding := range group.bindings {
+			if binding.action == nil {
+				continue
+			}
+			for _, key := range binding.keys {
+				actions[key] = binding.action
+			}
+		}
+	}
+	return actions
+}()
+
+// moveOverviewPage moves the cursor by rows in direction, landing on the
+// closest selectable row.
+func (m *Model) moveOverviewPage(rows, direction int) {
+	count := m.overviewRowCount()
+	if count == 0 {
+		return
+	}
+	m.cursor = m.closestSelectableIndex(clampIndex(m.cursor+rows*direction, count), direction)
+	m.resetCommitScrollForSelected()
+}
@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverviewKeymapBindsEachKeyOnceAndDocumentsIt(t *testing.T) {
+	seen := make(map[string]string)
+	for _, group := range overviewKeymap {
+		for _, binding := range group.bindings {
+			if binding.action == nil {
+				continue
+			}
+			assert.NotEmpty(t, binding.help, "binding %v has no help", binding.keys)
+			for _, key := range binding.keys {
+				assert.NotContains(t, seen, key, "key %q is bound twice", key)
+				seen[key] = binding.help
+			}
+		}
+	}
+	assert.Len(t, overviewActions, len(seen))
+}
+
+func TestHelpSearchFiltersBindingsAndKeepsTheirGroup(t *testing.T) {
+	model := Model{width: 120, height: 50, styles: DefaultStyles(), showHelp: true}
+
+	for _, r := range "/stash" {
+		handled, _ := model.handleHelpKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		require.True(t, handled)
+	}
+	require.True(t, model.helpSearching)
+	require.Equal(t, "stash", model.helpQuery)
+
+	help := ansi.Strip(model.renderHelp())
+	assert.Contains(t, help, "Git:")
+	assert.Contains(t, help, "pop a stash")
+	assert.NotContains(t, help, "Navigation:")
+	assert.NotContains(t, help, "fetch the repository")
+
+	_, _ = model.handleHelpKey(tea.KeyMsg{Type: tea.KeyEnter})
+	_, _ = model.handleHelpKey(tea.KeyMsg{Type: tea.KeyEsc})
+	require.True(t, model.showHelp, "esc clears the search first")
+	require.Empty(t, model.helpQuery)
+
+	_, _ = model.handleHelpKey(tea.KeyMsg{Type: tea.KeyEsc})
+	require.False(t, model.showHelp)
+}
+
+func TestHelpScrollsWithinTheViewport(t *testing.T) {
+	model := Model{width: 120, height: 20, styles: DefaultStyles(), showHelp: true}
+	total := len(model.helpLines())
+	viewport := model.helpViewportSize()
+	require.Greater(t, total, viewport)
+
+	_, _ = model.handleHelpKey(tea.KeyMsg{Type: tea.KeyUp})
+	require.Zero(t, model.helpOffset)
+
+	_, _ = model.handleHelpKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	require.Equal(t, total-viewport, model.helpOffset)
+	assert.Contains(t, ansi.Strip(model.renderHelp()), "open the token page", "the last group is reachable")
+
+	_, _ = model.handleHelpKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	require.False(t, model.showHelp, "q closes the help instead of quitting")
+}
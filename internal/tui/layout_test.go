@@ -8,7 +8,9 @@ import (
 	"github.com/charmbracelet/x/ansi"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
 )
 
 func TestCalculateColumnWidthsDistributesExtraSpace(t *testing.T) {
@@ -172,6 +174,76 @@ func TestRenderOverviewFillsEmptyRowsWithAgeColumnBorder(t *testing.T) {
 	assert.Equal(t, strings.Repeat(" ", 4), parts[4])
 }
 
+func TestRenderOverviewUsesCompactLayoutInNarrowTerminals(t *testing.T) {
+	repo := testRepoWithBranch("a-repository-with-a-long-name", "main")
+	repo.State.Branch.Upstream = &git.RemoteBranch{Name: "origin/main"}
+	repo.State.Branch.Pushables = "2"
+	repo.State.Branch.Pullables = "1"
+
+	model := Model{
+		repositories: []*git.Repository{repo, testRepoWithBranch("beta", "main")},
+		width:        32,
+		height:       8,
+		styles:       DefaultStyles(),
+	}
+	require.True(t, model.compactLayout())
+	require.False(t, model.terminalTooSmall())
+
+	lines := strings.Split(ansi.Strip(model.renderOverview()), "\n")
+	require.GreaterOrEqual(t, len(lines), 6)
+	for _, line := range lines {
+		assert.Equal(t, model.width, ansi.StringWidth(line), "line %q", line)
+	}
+	assert.Equal(t, "┌"+strings.Repeat("─", 30)+"┐", lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], "│→   a-repository"), lines[2])
+	assert.True(t, strings.HasSuffix(lines[2], "↖2 ↘1│"), lines[2])
+	assert.Equal(t, "│    beta"+strings.Repeat(" ", 22)+"│", lines[3])
+	assert.NotContains(t, model.renderStatusBar(), "\n", "status bar wraps")
+}
+
+func TestRenderHelpUsesActiveLocale(t *testing.T) {
+	require.NoError(t, i18n.SetLocale("de"))
+	t.Cleanup(func() { require.NoError(t, i18n.SetLocale(i18n.DefaultLocale)) })
+
+	model := Model{width: 120, height: 50, styles: DefaultStyles()}
+	help := ansi.Strip(model.renderHelp())
+	assert.Contains(t, help, "Hilfe")
+	assert.Contains(t, help, "Aktionen:", "the group titles are translated")
+	assert.Contains(t, help, "alle markieren", "the bindings are translated")
+	assert.NotContains(t, help, "Actions:")
+}
+
+func TestRepositoryRowsTellFailureKindsApart(t *testing.T) {
+	model := Model{styles: DefaultStyles()}
+	network := testRepoWithBranch("network", "main")
+	network.MarkFailure(gerr.KindNetwork, "network unreachable")
+	fatal := testRepoWithBranch("fatal", "main")
+	fatal.MarkCriticalError("bad object HEAD")
+	auth := testRepoWithBranch("auth", "main")
+	auth.MarkRequiresCredentials("")
+	conflict := testRepoWithBranch("conflict", "main")
+	conflict.SetWorkStatus(git.Available)
+	conflict.State.Failure = gerr.KindConflict
+
+	assert.Equal(t, networkSymbol, model.repoVisualStateFor(network).statusIcon)
+	assert.Equal(t, failSymbol, model.repoVisualStateFor(fatal).statusIcon)
+	assert.Equal(t, credentialsSymbol, model.repoVisualStateFor(auth).statusIcon)
+	visual := model.repoVisualStateFor(conflict)
+	assert.Equal(t, conflictSymbol, visual.statusIcon)
+	assert.Equal(t, model.styles.ConflictSelectedItem, model.selectedHighlightForVisual(visual))
+
+	conflict.MarkClean()
+	assert.Equal(t, " ", model.repoVisualStateFor(conflict).statusIcon, "the conflict ends with a clean tree")
+}
+
+func TestHelpExplainsTheStatusSymbols(t *testing.T) {
+	model := Model{width: 120, height: 50, styles: DefaultStyles(), helpQuery: "conflict"}
+	help := ansi.Strip(model.renderHelp())
+	assert.Contains(t, help, "Status symbols:")
+	assert.Contains(t, help, conflictSymbol+"  merge conflict")
+	assert.NotContains(t, help, "needs credentials")
+}
+
 func testRepoWithBranch(name, branch string) *git.Repository {
 	repo := &git.Repository{
 		Name:  name,
@@ -182,3 +254,12 @@ func testRepoWithBranch(name, branch string) *git.Repository {
 	}
 	return repo
 }
+
+func TestQueueSummaryShowsDepthAndOverflow(t *testing.T) {
+	summary := queueSummary([]git.QueueStats{
+		{Name: "git", Depth: 2, Capacity: 64, Blocked: 3},
+		{Name: "state", Depth: 0, Capacity: 512, Dropped: 1},
+	})
+	assert.Equal(t, "git 2/64 (3 blocked), state 0/512 (1 dropped)", summary)
+	assert.Empty(t, queueSummary(nil))
+}
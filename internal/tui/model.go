@@ -1,13 +1,16 @@
 package tui
 
 import (
+	"strings"
 	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
 	"github.com/thorstenhirsch/gitbatch/internal/job"
+	"github.com/thorstenhirsch/gitbatch/internal/load"
 	"github.com/thorstenhirsch/gitbatch/internal/watch"
 )
 
@@ -30,13 +33,28 @@ type Model struct {
 	loadedCount              int
 	jobsRunning              bool
 	err                      error
+	notice                   string
+	countPrefix              string
 
 	// View state
 	expandBranches         bool
 	worktreeMode           bool
 	sortMode               repositorySortMode
+	statusFilter           repositoryFilter
+	objectStats            map[string]git.ObjectStats
+	tabs                   []repositoryTab
+	activeTab              int
 	sidePanel              SidePanelType
 	showHelp               bool
+	helpQuery              string
+	helpSearching          bool
+	helpOffset             int
+	diffActive             bool
+	diffTitle              string
+	diffLines              []string
+	diffOffset             int
+	diffConfirm            func() tea.Cmd
+	diffConfirmLabel       string
 	branchCursor           int
 	remoteBranchCursor     int
 	commitCursor           int
@@ -46,24 +64,113 @@ type Model struct {
 	remoteOffset           int
 	forcePromptQueue       []*forcePushPrompt
 	activeForcePrompt      *forcePushPrompt
+	activeLockPrompt       *lockPrompt
+	activeConfirmPrompt    *confirmPrompt
+	autoModePending        bool
+	autoModeChoice         *autoModeChoice
+	autoModePromptActive   bool
+	autoModePromptLimit    int
+	activeCheckoutPrompt   *checkoutPrompt
+	panelBatch             *panelBatch
 	credentialPromptQueue  []*credentialPrompt
 	activeCredentialPrompt *credentialPrompt
 	credentialInputField   credentialField
 	credentialInputBuffer  string
+	credentialReveal       bool
+	credentialError        string
+	credentialAutoRetried  map[string]bool
+	credentialRetries      map[string]*git.Credentials
+	credentialFailures     map[string]credentialFailure
+	credentialPausedHosts  map[string]bool
+	askpassQuestions       []*askpassQuestion
+	askpassBuffer          string
 	commitPromptActive     bool
 	commitPromptRepos      []*git.Repository
 	commitPromptField      commitField
 	commitMessageBuffer    string
 	commitDescBuffer       string
+	branchSwitcherActive   bool
+	branchSwitcherRepo     *git.Repository
+	branchSwitcherCursor   int
+	branchSwitcherOffset   int
 	branchPromptActive     bool
 	branchPromptRepos      []*git.Repository
 	branchNameBuffer       string
+	branchStartPoint       string
 	worktreePromptActive   bool
 	worktreePromptRepo     *git.Repository
 	worktreePromptField    worktreeField
 	worktreeBranchBuffer   string
 	worktreePathBuffer     string
 	worktreePathEdited     bool
+	comparePromptActive    bool
+	compareRefBuffer       string
+	compareRef             string
+	compareResults         map[string]compareResult
+	problemsActive         bool
+	problemsChecking       bool
+	problemsCursor         int
+	identityProblems       []*command.IdentityProblem
+	skippedDirectories     []load.Skipped
+	// failureDetail is the error the status panel shows after jumping to a
+	// failed repository from the problems view.
+	failureDetail          *failureDetail
+	modeOverrides          modeOverrides
+	forecastActive         bool
+	forecastRunning        bool
+	forecastCursor         int
+	forecasts              []*command.MergeForecast
+	cleanActive            bool
+	cleanRunning           bool
+	cleanCursor            int
+	cleanPreviews          []*command.CleanPreview
+	cleanSkipped           map[string]bool
+	urlRewritePromptActive bool
+	urlRewriteRepos        []*git.Repository
+	urlRewriteField        urlRewriteField
+	urlFindBuffer          string
+	urlReplaceBuffer       string
+	patchPromptActive      bool
+	patchPromptRepos       []*git.Repository
+	patchPathBuffer        string
+	patchActive            bool
+	patchRunning           bool
+	patchSource            string
+	patchResults           []*command.PatchResult
+	patchErr               error
+	patchOffset            int
+	grepPromptActive       bool
+	grepBuffer             string
+	grepActive             bool
+	grepRunning            bool
+	grepPattern            string
+	grepCursor             int
+	grepResults            []grepResult
+	statusFiles            []*git.File
+	statusFileCursor       int
+	reflogEntries          []*git.ReflogEntry
+	reflogCursor           int
+	reflogOffset           int
+	historyEntries         []command.AuditEntry
+	historyCursor          int
+	historyOffset          int
+	bisectActive           bool
+	bisectRepo             *git.Repository
+	bisectRunning          bool
+	bisectProgress         *command.BisectProgress
+	bisectField            bisectField
+	bisectBadBuffer        string
+	bisectGoodBuffer       string
+	rebaseOntoPromptActive bool
+	rebaseOntoRepos        []*git.Repository
+	rebaseOntoField        rebaseOntoField
+	rebaseOntoBuffer       string
+	rebaseUpstreamBuffer   string
+	remotePromptActive     bool
+	remotePromptRepo       *git.Repository
+	remotePromptField      remoteField
+	remoteNameBuffer       string
+	remoteURLBuffer        string
 	stashPromptActive      bool
 	stashPromptRepos       []*git.Repository
 	stashMessageBuffer     string
@@ -74,6 +181,9 @@ type Model struct {
 	// Tick management — ensures only one spinner/job-check tick chain is active.
 	tickRunning bool
 
+	// Repositories of the running batches that wait for the ones they depend on.
+	dependencyWaits map[*git.Repository]dependencyWait
+
 	// Performance caching
 	cachedColWidths columnWidths
 	cachedWidth     int
@@ -89,6 +199,7 @@ type Model struct {
 	lastUpdateCheck    time.Time
 	lastJobCheck       time.Time
 	lastFocusRefresh   time.Time
+	lastForceRefresh   time.Time
 
 	// External-change watcher; nil if construction failed.
 	watcher *watch.Service
@@ -99,7 +210,8 @@ type columnWidths struct {
 	repo      int
 	branch    int
 	commitMsg int
-	age       int // 0 = hidden (terminal width ≤ ageColumnThreshold)
+	age       int  // 0 = hidden (terminal width ≤ ageColumnThreshold)
+	compact   bool // single column below compactLayoutWidth; repo spans it
 }
 
 type repositorySortMode uint8
@@ -107,6 +219,8 @@ type repositorySortMode uint8
 const (
 	repositorySortByName repositorySortMode = iota
 	repositorySortByTime
+	repositorySortBySize
+	repositorySortByBehind
 )
 
 // SidePanelType represents which side panel is active
@@ -119,23 +233,66 @@ const (
 	CommitPanel
 	StashActionPanel
 	StatusPanel
+	ReflogPanel
+	HistoryPanel
 )
 
 // Mode represents the operation mode
 type Mode struct {
 	ID            ModeID
 	DisplayString string
+	// composite is the configured job a composite mode runs.
+	composite *command.CompositeJob
 }
 
 type forcePushPrompt struct {
 	repo *git.Repository
 }
 
+// lockPrompt asks whether stale git lock files of a repository may be removed.
+type lockPrompt struct {
+	repo  *git.Repository
+	locks []git.LockFile
+	// stale is false when a running git process may still hold the locks.
+	stale bool
+	// unknown is set when running git processes cannot be inspected here.
+	unknown bool
+}
+
+// confirmPrompt holds a destructive operation back until the user typed the
+// expected text, see command.RequiresConfirmation.
+type confirmPrompt struct {
+	action   string
+	repos    []*git.Repository
+	expected string
+	buffer   string
+	run      func() tea.Cmd
+}
+
+// failureDetail keeps the error of a failed repository, which a refresh may
+// clear while the status panel shows it.
+type failureDetail struct {
+	repo    *git.Repository
+	message string
+}
+
+// checkoutPrompt asks whether local changes a checkout would overwrite may be
+// stashed around it.
+type checkoutPrompt struct {
+	jobs       []panelJob
+	panel      SidePanelType
+	closePanel bool
+	// conflicts holds the overwritten files per blocked repository.
+	conflicts map[*git.Repository][]string
+}
+
 type credentialPrompt struct {
 	repo     *git.Repository
 	job      *job.Job
 	username string
 	password string
+	// passphrase switches the prompt to a single ssh key passphrase field.
+	passphrase bool
 }
 
 type credentialField int
@@ -159,6 +316,34 @@ const (
 	worktreeFieldPath
 )
 
+type remoteField int
+
+const (
+	remoteFieldName remoteField = iota
+	remoteFieldURL
+)
+
+type urlRewriteField int
+
+const (
+	urlRewriteFieldFind urlRewriteField = iota
+	urlRewriteFieldReplace
+)
+
+type rebaseOntoField int
+
+const (
+	rebaseOntoFieldOnto rebaseOntoField = iota
+	rebaseOntoFieldUpstream
+)
+
+type bisectField int
+
+const (
+	bisectFieldBad bisectField = iota
+	bisectFieldGood
+)
+
 type stashActionType int
 
 const (
@@ -186,6 +371,20 @@ var (
 	modes = []Mode{pullMode, mergeMode, rebaseMode, pushMode}
 )
 
+// availableModes returns the built-in modes followed by one mode per
+// configured composite job.
+func availableModes() []Mode {
+	available := append([]Mode(nil), modes...)
+	for _, job := range command.CompositeJobs() {
+		available = append(available, Mode{
+			ID:            ModeID(job.Name),
+			DisplayString: strings.ToUpper(job.Name[:1]) + job.Name[1:] + " | m: switch",
+			composite:     job,
+		})
+	}
+	return available
+}
+
 const (
 	// loadingScreenThreshold is the minimum number of directories needed
 	// to trigger the progress-bar loading screen.
@@ -218,12 +417,16 @@ type Styles struct {
 	WorktreeSelectedItem     lipgloss.Style
 	CommonSelectedItem       lipgloss.Style
 	FailedSelectedItem       lipgloss.Style
+	NetworkSelectedItem      lipgloss.Style
+	ConflictSelectedItem     lipgloss.Style
 	CredentialsItem          lipgloss.Style
 	QueuedItem               lipgloss.Style
 	PendingItem              lipgloss.Style
 	WorkingItem              lipgloss.Style
 	SuccessItem              lipgloss.Style
 	FailedItem               lipgloss.Style
+	NetworkItem              lipgloss.Style
+	ConflictItem             lipgloss.Style
 	DisabledItem             lipgloss.Style
 	LocalChangesItem         lipgloss.Style
 	BranchInfo               lipgloss.Style
@@ -302,6 +505,14 @@ func DefaultStyles() *Styles {
 			Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#FFFFFF"}).
 			Background(lipgloss.AdaptiveColor{Light: "#5E35B1", Dark: "#7E57C2"}).
 			Bold(true),
+		NetworkSelectedItem: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#FFFFFF"}).
+			Background(lipgloss.AdaptiveColor{Light: "#455A64", Dark: "#546E7A"}).
+			Bold(true),
+		ConflictSelectedItem: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#FFFFFF"}).
+			Background(lipgloss.AdaptiveColor{Light: "#C2185B", Dark: "#AD1457"}).
+			Bold(true),
 		QueuedItem: lipgloss.NewStyle().
 			Foreground(tagHighlightColor),
 		PendingItem: lipgloss.NewStyle().
@@ -314,6 +525,10 @@ func DefaultStyles() *Styles {
 			Foreground(lipgloss.AdaptiveColor{Light: "#D32F2F", Dark: "#E57373"}),
 		CredentialsItem: lipgloss.NewStyle().
 			Foreground(lipgloss.AdaptiveColor{Light: "#5E35B1", Dark: "#9575CD"}),
+		NetworkItem: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#546E7A", Dark: "#90A4AE"}),
+		ConflictItem: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#C2185B", Dark: "#F06292"}),
 		DisabledItem: lipgloss.NewStyle().
 			Foreground(lipgloss.AdaptiveColor{Light: "#4A3728", Dark: "#9A7B4F"}),
 		LocalChangesItem: lipgloss.NewStyle().
@@ -349,7 +564,7 @@ func DefaultStyles() *Styles {
 // New creates a new Model with the given configuration
 func New(mode string, directories []string) *Model {
 	initialMode := pullMode
-	for _, m := range modes {
+	for _, m := range availableModes() {
 		if string(m.ID) == mode {
 			initialMode = m
 			break
@@ -359,6 +574,7 @@ func New(mode string, directories []string) *Model {
 	return &Model{
 		directories:         directories,
 		mode:                initialMode,
+		autoModePending:     mode == AutoMode,
 		repositories:        make([]*git.Repository, 0),
 		sidePanel:           NonePanel,
 		styles:              DefaultStyles(),
@@ -367,17 +583,21 @@ func New(mode string, directories []string) *Model {
 		commitScrollOffsets: make(map[string]int),
 		repositoryUpdateCh:  make(chan struct{}, 256),
 		displayCache:        make(map[string]*repoDisplayEntry),
+		sortMode:            repositorySortMode(startupSort.Load()),
+		statusFilter:        repositoryFilter(startupFilter.Load()),
 	}
 }
 
 // Init initializes the model
 func (m *Model) Init() tea.Cmd {
 	m.tickRunning = true
-	cmds := []tea.Cmd{loadRepositoriesCmd(m.directories), m.listenRepositoryUpdatesCmd(), tickCmd()}
-	if len(m.directories) > loadingScreenThreshold {
-		cmds = append(cmds, listenLoadProgressCmd())
-	}
-	return tea.Batch(cmds...)
+	return tea.Batch(
+		loadRepositoriesCmd(m.directories),
+		listenRepositoryLoadedCmd(),
+		listenLoadProgressCmd(),
+		m.listenRepositoryUpdatesCmd(),
+		tickCmd(),
+	)
 }
 
 func (m *Model) terminalTooSmall() bool {
@@ -390,9 +610,16 @@ type repoLoadProgressMsg struct {
 	count int
 }
 
+// repositoryLoadedMsg is sent for each repository as soon as it is
+// fast-initialized; its branches and remotes are still loading.
+type repositoryLoadedMsg struct {
+	repo *git.Repository
+}
+
 // repositoriesLoadedMsg is sent when all repositories are loaded
 type repositoriesLoadedMsg struct {
-	repos []*git.Repository
+	repos   []*git.Repository
+	skipped []load.Skipped
 }
 
 // repositoryStateChangedMsg notifies the TUI that a repository triggered a RepositoryUpdated event.
@@ -410,14 +637,44 @@ func (e errMsg) Error() string { return e.err.Error() }
 
 // lazygitClosedMsg is sent when lazygit exits
 type lazygitClosedMsg struct {
-	repo            *git.Repository
-	originalModTime time.Time
-	originalState   git.RepositoryState
+	repo          *git.Repository
+	before        git.Snapshot
+	originalState *git.RepositoryState
 }
 
 // jobCompletedMsg is sent when a job completes (success or failure)
 type jobCompletedMsg struct{}
 
+// queueResultMsg reports how many repositories queueAll tagged and why it
+// skipped the others.
+type queueResultMsg struct {
+	tagged  int
+	skipped []queueSkip
+}
+
+// dependencyWait is a repository of a batch held back until the
+// repositories it depends on succeeded.
+type dependencyWait struct {
+	deps  []*git.Repository
+	batch command.BatchID
+}
+
+// batchStartedMsg is sent once a batch started; waits lists the repositories
+// held back until their dependencies succeeded.
+type batchStartedMsg struct {
+	batch command.BatchID
+	waits map[*git.Repository][]*git.Repository
+	// advice is the order the local dependencies of the batch suggest, or
+	// the warning that they form a cycle.
+	advice string
+}
+
+// queueSkip counts the repositories skipped for one reason.
+type queueSkip struct {
+	reason string
+	count  int
+}
+
 // repoActionResultMsg is sent when a focus view action updates repository state
 type repoActionResultMsg struct {
 	panel      SidePanelType
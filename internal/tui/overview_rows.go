@@ -117,8 +117,9 @@ func (f *worktreeFamily) repositoryForWorktree(worktree *git.Worktree) *git.Repo
 
 func (m *Model) overviewRows() []overviewRow {
 	if !m.worktreeMode {
-		rows := make([]overviewRow, 0, len(m.repositories))
-		for _, repo := range m.repositories {
+		repos := m.visibleRepositories()
+		rows := make([]overviewRow, 0, len(repos))
+		for _, repo := range repos {
 			if repo == nil {
 				continue
 			}
@@ -165,7 +166,7 @@ func (m *Model) worktreeFamilies() []worktreeFamily {
 	familyOrder := make([]string, 0)
 	familyByKey := make(map[string]*worktreeFamily)
 
-	for _, repo := range m.repositories {
+	for _, repo := range m.visibleRepositories() {
 		if repo == nil {
 			continue
 		}
@@ -268,6 +269,18 @@ func (m *Model) moveOverviewCursor(delta int) {
 	m.cursor = idx
 }
 
+// focusRepository moves the cursor to the row of r. It reports false when r
+// has no row, e.g. because a filter hides it.
+func (m *Model) focusRepository(r *git.Repository) bool {
+	for i, row := range m.overviewRows() {
+		if row.selectable() && row.repository() == r {
+			m.cursor = i
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Model) closestSelectableIndex(start, direction int) int {
 	rows := m.overviewRows()
 	if len(rows) == 0 {
@@ -316,15 +329,16 @@ func (m *Model) toggleWorktreeMode() {
 
 	m.worktreeMode = !m.worktreeMode
 	if !m.worktreeMode {
+		repos := m.visibleRepositories()
 		if selectedRepo != nil {
-			for i, repo := range m.repositories {
+			for i, repo := range repos {
 				if repo == selectedRepo {
 					m.cursor = i
 					return
 				}
 			}
 		}
-		m.cursor = clampIndex(m.cursor, len(m.repositories))
+		m.cursor = clampIndex(m.cursor, len(repos))
 		return
 	}
 
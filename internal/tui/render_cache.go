@@ -58,19 +58,28 @@ func (m *Model) displayEntry(repoID string) *repoDisplayEntry {
 }
 
 // commitContentForRepo returns the pre-rendered "[tags] commit-message" line
-// for r's HEAD branch. On a fail state it returns the sticky error message.
-// Cache miss triggers a single-shot disk read via go-git; hits are O(1).
+// for r's HEAD branch. On a fail state it returns the sticky error message,
+// and while an operation runs its live progress, e.g. "fetching... 42%
+// receiving objects". Cache miss triggers a single-shot disk read via go-git;
+// hits are O(1).
 func (m *Model) commitContentForRepo(r *git.Repository) string {
 	if r == nil {
 		return ""
 	}
-	if r.WorkStatus() == git.Fail && r.State != nil && r.State.Message != "" {
-		return singleLineMessage(r.State.Message)
+	if status := r.WorkStatus(); (status == git.Fail || status == git.Working) && r.State != nil && r.State.Message() != "" {
+		return singleLineMessage(r.State.Message())
 	}
 	if r.State == nil || r.State.Branch == nil || r.State.Branch.Reference == nil {
 		return ""
 	}
+	if mode := m.jobMode(r); r.WorkStatus() == git.Queued && mode.ID != m.mode.ID {
+		return fmt.Sprintf("[%s] %s", mode.ID, m.headCommitContent(r))
+	}
+	return m.headCommitContent(r)
+}
 
+// headCommitContent returns the cached commit message of the HEAD of r.
+func (m *Model) headCommitContent(r *git.Repository) string {
 	entry := m.displayEntry(r.RepoID)
 	hash := r.State.Branch.Reference.Hash()
 	if entry.headHash == hash && entry.headContent != "" {
@@ -158,7 +167,7 @@ func (m *Model) worktreeBranchContent(row overviewRow) string {
 	if repo.State == nil {
 		return content + markers
 	}
-	return content + syncSuffix(repo.State.Branch) + markers
+	return content + m.branchSyncSuffix(repo) + markers
 }
 
 func (m *Model) linkedWorktreeSyncSuffix(r *git.Repository) string {
@@ -215,7 +224,7 @@ func (m *Model) worktreeDiffContent(r *git.Repository, selected bool) (string, i
 		insertions, deletions, ok = worktreeDiffStats(r)
 	}
 	if !ok {
-		insertions, deletions, ok = parseWorktreeDiffMessage(r.State.Message)
+		insertions, deletions, ok = parseWorktreeDiffMessage(r.State.Message())
 	}
 	content := ""
 	plain := ""
@@ -245,7 +254,7 @@ func worktreeDiffCacheKey(r *git.Repository) string {
 		head = r.State.Branch.Reference.Hash().String()
 	}
 	if r != nil && r.State != nil {
-		message = strings.TrimSpace(r.State.Message)
+		message = strings.TrimSpace(r.State.Message())
 	}
 	return fmt.Sprintf("%s|%t|%t|%s", head, repoIsDirty(r), repoHasLocalChanges(r), message)
 }
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestCommitContentForRepo_ShowsTheProgressOfARunningOperation(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	m := Model{}
+	require.Equal(t, "initial commit", m.commitContentForRepo(repo))
+
+	repo.SetWorkStatus(git.Working)
+	repo.State.SetMessage("fetching... 42% receiving objects")
+	require.Equal(t, "fetching... 42% receiving objects", m.commitContentForRepo(repo))
+
+	repo.SetWorkStatus(git.Success)
+	require.Equal(t, "initial commit", m.commitContentForRepo(repo))
+}
+
+func TestComputeCommitContent_MarksVerifiedTags(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is needed to sign tags")
+	}
+	repo := initBranchCreationRepo(t, "alpha")
+	keyDir := t.TempDir()
+	key := filepath.Join(keyDir, "id_ed25519")
+	out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "test@example.com", "-f", key).CombinedOutput()
+	require.NoError(t, err, string(out))
+	pub, err := os.ReadFile(key + ".pub")
+	require.NoError(t, err)
+	allowed := filepath.Join(keyDir, "allowed_signers")
+	require.NoError(t, os.WriteFile(allowed, []byte("test@example.com "+strings.TrimSpace(string(pub))+"\n"), 0o644))
+	runBranchTestGit(t, repo.AbsPath, "config", "gpg.format", "ssh")
+	runBranchTestGit(t, repo.AbsPath, "config", "user.signingkey", key)
+	runBranchTestGit(t, repo.AbsPath, "config", "gpg.ssh.allowedSignersFile", allowed)
+
+	runBranchTestGit(t, repo.AbsPath, "tag", "-s", "v1.0.0", "-m", "signed release")
+	runBranchTestGit(t, repo.AbsPath, "tag", "-a", "v0.9.0", "-m", "unsigned release")
+	require.Equal(t, "[v0.9.0, v1.0.0 (verified)] initial commit", computeCommitContent(repo))
+}
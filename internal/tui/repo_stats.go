@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// repoStatsEnabled collects the size and object counts of every repository
+// after loading. Walking large git directories takes a while, so without it
+// they are only collected when sorting by size.
+var repoStatsEnabled atomic.Bool
+
+// SetRepoStats configures whether the size and object counts of all
+// repositories are collected on startup.
+func SetRepoStats(enabled bool) {
+	repoStatsEnabled.Store(enabled)
+}
+
+// objectStatsMsg delivers the object statistics collected per RepoID.
+type objectStatsMsg struct {
+	stats map[string]git.ObjectStats
+}
+
+// collectObjectStatsCmd collects the object statistics of repos in the
+// background. Repositories that fail are left out.
+func collectObjectStatsCmd(repos []*git.Repository) tea.Cmd {
+	repos = append([]*git.Repository(nil), repos...)
+	if len(repos) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+		stats := make(map[string]git.ObjectStats, len(repos))
+		sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+		for _, repo := range repos {
+			if repo == nil {
+				continue
+			}
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(repo *git.Repository) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				s, err := repo.ObjectStats()
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				stats[repo.RepoID] = s
+				mu.Unlock()
+			}(repo)
+		}
+		wg.Wait()
+		return objectStatsMsg{stats: stats}
+	}
+}
+
+// applyObjectStats stores collected statistics and re-sorts the list when it
+// is sorted by size.
+func (m *Model) applyObjectStats(msg objectStatsMsg) {
+	if m.objectStats == nil {
+		m.objectStats = make(map[string]git.ObjectStats, len(msg.stats))
+	}
+	for id, s := range msg.stats {
+		m.objectStats[id] = s
+	}
+	if m.sortMode == repositorySortBySize {
+		m.applyRepositorySort()
+		// The age column shows the size now.
+		m.cachedWidth = 0
+	}
+}
+
+// startupObjectStatsCmd collects the statistics of all repositories when
+// repo_stats is enabled.
+func (m *Model) startupObjectStatsCmd() tea.Cmd {
+	if !repoStatsEnabled.Load() && m.sortMode != repositorySortBySize {
+		return nil
+	}
+	return collectObjectStatsCmd(m.repositories)
+}
+
+// missingObjectStatsCmd collects the statistics the size sort order still
+// lacks.
+func (m *Model) missingObjectStatsCmd() tea.Cmd {
+	var missing []*git.Repository
+	for _, repo := range m.repositories {
+		if repo == nil {
+			continue
+		}
+		if _, ok := m.objectStats[repo.RepoID]; !ok {
+			missing = append(missing, repo)
+		}
+	}
+	return collectObjectStatsCmd(missing)
+}
+
+// loadObjectStats refreshes the statistics of r for the status panel when
+// repo_stats is enabled or they were collected for the size order.
+func (m *Model) loadObjectStats(r *git.Repository) {
+	if r == nil {
+		return
+	}
+	if _, ok := m.objectStats[r.RepoID]; !ok && !repoStatsEnabled.Load() {
+		return
+	}
+	if s, err := r.ObjectStats(); err == nil {
+		m.applyObjectStats(objectStatsMsg{stats: map[string]git.ObjectStats{r.RepoID: s}})
+	}
+}
+
+// sortRepositoriesBySize orders repositories by the size of their git
+// directory, the largest first. Repositories without statistics yet come
+// last, by name.
+func (m *Model) sortRepositoriesBySize() {
+	sort.Sort(git.Alphabetical(m.repositories))
+	sort.SliceStable(m.repositories, func(i, j int) bool {
+		a, aok := m.objectStats[m.repositories[i].RepoID]
+		b, bok := m.objectStats[m.repositories[j].RepoID]
+		if aok != bok {
+			return aok
+		}
+		return a.Size > b.Size
+	})
+}
+
+// sizeColumnForRepo is the size the age column shows while sorting by size.
+func (m *Model) sizeColumnForRepo(r *git.Repository) string {
+	if r == nil {
+		return ""
+	}
+	s, ok := m.objectStats[r.RepoID]
+	if !ok {
+		return ""
+	}
+	return formatSize(s.Size)
+}
+
+// formatSize renders a byte count like du -h, e.g. "4.0K" or "130M".
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	value := float64(bytes)
+	suffixes := "KMGTP"
+	i := -1
+	for value >= unit && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	if value < 9.95 {
+		return fmt.Sprintf("%.1f%c", value, suffixes[i])
+	}
+	return fmt.Sprintf("%.0f%c", value, suffixes[i])
+}
+
+// objectStatsLines describes a repository's disk usage for the status panel.
+func objectStatsLines(s git.ObjectStats) []string {
+	lines := []string{
+		fmt.Sprintf("Repo size      %s", formatSize(s.Size)),
+		fmt.Sprintf("Loose objects  %d (%s)", s.Loose, formatSize(s.LooseSize)),
+		fmt.Sprintf("Packed objects %d in %d packs (%s)", s.Packed, s.Packs, formatSize(s.PackSize)),
+	}
+	if s.Prunable > 0 {
+		lines = append(lines, fmt.Sprintf("Prunable       %d loose objects are packed too", s.Prunable))
+	}
+	if s.Garbage > 0 {
+		lines = append(lines, fmt.Sprintf("Garbage        %d files", s.Garbage))
+	}
+	if s.NeedsGC() {
+		lines = append(lines, "Maintenance    git gc would repack and prune")
+	}
+	return lines
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestSortBySizeShowsTheLargestRepositoryFirst(t *testing.T) {
+	alpha := initBranchCreationRepo(t, "alpha")
+	beta := initBranchCreationRepo(t, "beta")
+	require.NoError(t, os.WriteFile(filepath.Join(beta.AbsPath, "data.txt"), []byte(strings.Repeat("incompressible? no, but large\n", 20000)), 0o644))
+	runBranchTestGit(t, beta.AbsPath, "add", "data.txt")
+	runBranchTestGit(t, beta.AbsPath, "commit", "-m", "add data")
+	m := &Model{repositories: []*git.Repository{alpha, beta}, styles: DefaultStyles(), width: 130, height: 30}
+
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	require.Nil(t, cmd)
+	require.Equal(t, repositorySortByTime, m.sortMode)
+
+	_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	require.NotNil(t, cmd, "the size order collects the statistics")
+	require.Equal(t, repositorySortBySize, m.sortMode)
+	m.Update(cmd())
+
+	require.Equal(t, []*git.Repository{beta, alpha}, m.repositories)
+	require.NotEmpty(t, m.ageColumnForRepo(beta))
+	require.Equal(t, formatSize(m.objectStats[beta.RepoID].Size), m.ageColumnForRepo(beta))
+
+	_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	require.Nil(t, cmd)
+	require.Equal(t, repositorySortByBehind, m.sortMode)
+	_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	require.Nil(t, cmd)
+	require.Equal(t, repositorySortByName, m.sortMode)
+	require.Equal(t, []*git.Repository{alpha, beta}, m.repositories)
+}
+
+func TestObjectStatsLinesSuggestGC(t *testing.T) {
+	lines := objectStatsLines(git.ObjectStats{Size: 3 << 20, Loose: 12, LooseSize: 48 << 10, Packed: 900, Packs: 1, PackSize: 2 << 20})
+	require.Equal(t, []string{
+		"Repo size      3.0M",
+		"Loose objects  12 (48K)",
+		"Packed objects 900 in 1 packs (2.0M)",
+	}, lines)
+
+	lines = objectStatsLines(git.ObjectStats{Loose: 7000})
+	require.Contains(t, lines, "Maintenance    git gc would repack and prune")
+}
+
+func TestFormatSize(t *testing.T) {
+	require.Equal(t, "512B", formatSize(512))
+	require.Equal(t, "4.0K", formatSize(4096))
+	require.Equal(t, "130M", formatSize(130<<20))
+	require.Equal(t, "1.5G", formatSize(3<<29))
+}
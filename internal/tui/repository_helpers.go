@@ -24,7 +24,7 @@ func scheduleRefresh(repo *git.Repository) error {
 	}
 	message := ""
 	if repo.State != nil {
-		message = repo.State.Message
+		message = repo.State.Message()
 	}
 	if err := command.ScheduleRepositoryRefresh(repo, &command.OperationOutcome{
 		Operation: command.OperationRefresh,
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// repositoryTab is one overview tab. Each tab remembers its own sort mode and
+// cursor; the "all" tab has an empty root and shows every repository.
+type repositoryTab struct {
+	label    string
+	root     string
+	sortMode repositorySortMode
+	cursor   int
+}
+
+// newRepositoryTabs builds the "all" tab followed by one tab per root. Tabs
+// are only worth showing for two or more distinct roots, otherwise nil is
+// returned and the overview behaves as before.
+func newRepositoryTabs(roots []string) []repositoryTab {
+	seen := make(map[string]struct{}, len(roots))
+	tabs := []repositoryTab{{label: "all"}}
+	for _, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		abs = filepath.Clean(abs)
+		if _, ok := seen[abs]; ok {
+			continue
+		}
+		seen[abs] = struct{}{}
+		tabs = append(tabs, repositoryTab{label: filepath.Base(abs), root: abs})
+	}
+	if len(tabs) < 3 {
+		return nil
+	}
+	return tabs
+}
+
+// contains reports whether repo lives below the tab's root.
+func (t repositoryTab) contains(repo *git.Repository) bool {
+	if t.root == "" {
+		return true
+	}
+	if repo == nil {
+		return false
+	}
+	path := filepath.Clean(repo.AbsPath)
+	return path == t.root || strings.HasPrefix(path, t.root+string(filepath.Separator))
+}
+
+// setRoots enables tabbed views for the given -d roots.
+func (m *Model) setRoots(roots []string) {
+	m.tabs = newRepositoryTabs(roots)
+	m.activeTab = 0
+	for i := range m.tabs {
+		m.tabs[i].sortMode = m.sortMode
+	}
+}
+
+// visibleRepositories returns the repositories shown in the active tab that
+// pass the filter, in the current sort order. Pinned repositories are always
+// shown.
+func (m *Model) visibleRepositories() []*git.Repository {
+	if (len(m.tabs) == 0 || m.tabs[m.activeTab].root == "") && m.statusFilter == filterNone {
+		return m.repositories
+	}
+	tab := repositoryTab{}
+	if len(m.tabs) > 0 {
+		tab = m.tabs[m.activeTab]
+	}
+	visible := make([]*git.Repository, 0, len(m.repositories))
+	for _, repo := range m.repositories {
+		if tab.contains(repo) && (m.statusFilter.matches(repo) || command.Pinned(repo)) {
+			visible = append(visible, repo)
+		}
+	}
+	return visible
+}
+
+// switchTab moves delta tabs to the left or right, wrapping around. The
+// sort mode and cursor of the tab being left are kept for when it returns.
+func (m *Model) switchTab(delta int) {
+	if len(m.tabs) == 0 {
+		return
+	}
+	m.tabs[m.activeTab].sortMode = m.sortMode
+	m.tabs[m.activeTab].cursor = m.cursor
+
+	m.activeTab = (m.activeTab + delta + len(m.tabs)) % len(m.tabs)
+	tab := m.tabs[m.activeTab]
+	m.sortMode = tab.sortMode
+	m.applyRepositorySort()
+	m.cursor = m.closestSelectableIndex(tab.cursor, 1)
+	m.resetCommitScrollForSelected()
+}
+
+// renderTabs renders the tab labels for the title bar, marking the active one.
+func (m *Model) renderTabs() string {
+	if len(m.tabs) == 0 {
+		return ""
+	}
+	labels := make([]string, len(m.tabs))
+	for i, tab := range m.tabs {
+		if i == m.activeTab {
+			labels[i] = "[" + tab.label + "]"
+			continue
+		}
+		labels[i] = " " + tab.label + " "
+	}
+	return strings.Join(labels, "")
+}
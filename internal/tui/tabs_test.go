@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestNewRepositoryTabs(t *testing.T) {
+	require.Nil(t, newRepositoryTabs([]string{"/src/work"}))
+	require.Nil(t, newRepositoryTabs([]string{"/src/work", "/src/work/"}))
+
+	tabs := newRepositoryTabs([]string{"/src/work", "/src/oss"})
+	require.Len(t, tabs, 3)
+	require.Equal(t, "all", tabs[0].label)
+	require.Equal(t, "work", tabs[1].label)
+	require.Equal(t, "/src/oss", tabs[2].root)
+}
+
+func TestSwitchTabKeepsSortAndCursorPerTab(t *testing.T) {
+	api := &git.Repository{Name: "api", AbsPath: "/src/work/api", ModTime: time.Unix(10, 0)}
+	web := &git.Repository{Name: "web", AbsPath: "/src/work/web", ModTime: time.Unix(30, 0)}
+	lib := &git.Repository{Name: "lib", AbsPath: "/src/oss/lib", ModTime: time.Unix(20, 0)}
+
+	model := Model{repositories: []*git.Repository{api, lib, web}}
+	model.setRoots([]string{"/src/work", "/src/oss"})
+	require.Equal(t, []*git.Repository{api, lib, web}, model.visibleRepositories())
+
+	model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{']'}})
+	require.Equal(t, 1, model.activeTab)
+	require.Equal(t, []*git.Repository{api, web}, model.visibleRepositories())
+
+	model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	model.cursor = 1
+	require.Equal(t, []*git.Repository{web, api}, model.visibleRepositories())
+
+	model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{']'}})
+	require.Equal(t, 2, model.activeTab)
+	require.Equal(t, repositorySortByName, model.sortMode)
+	require.Equal(t, []*git.Repository{lib}, model.visibleRepositories())
+	require.Equal(t, 0, model.cursor)
+
+	model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'['}})
+	require.Equal(t, repositorySortByTime, model.sortMode)
+	require.Equal(t, 1, model.cursor)
+	require.Same(t, api, model.currentRepository())
+
+	model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'['}})
+	model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'['}})
+	require.Equal(t, 2, model.activeTab)
+}
@@ -5,14 +5,19 @@ import (
 	"log"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/askpass"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/load"
 	"github.com/thorstenhirsch/gitbatch/internal/watch"
 )
 
 // Version exposes the application version for use across the TUI.
 var Version string
 
-// Run starts the TUI application
-func Run(mode string, directories []string) error {
+// Run starts the TUI application. With more than one root directory the
+// overview gets one tab per root plus an "all" tab. Directories skipped while
+// discovering the repositories are listed in the problems view.
+func Run(mode string, directories, roots []string, skipped []load.Skipped) error {
 	// The standard logger writes to stderr, which shares the terminal with the
 	// alt-screen TUI and corrupts the display on every Printf. Route it to
 	// /dev/null for the lifetime of the TUI. Trace logging via --trace has its
@@ -20,11 +25,25 @@ func Run(mode string, directories []string) error {
 	log.SetOutput(io.Discard)
 
 	m := New(mode, directories)
+	m.setRoots(roots)
+	m.skippedDirectories = skipped
 	svc := watch.New()
 	m.watcher = svc
 	defer svc.Close()
 
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithReportFocus())
+	// Git commands still waiting on the queue must not start after quitting.
+	defer command.CancelScheduledCommands()
+
+	// Route credential and passphrase questions of git and ssh into the
+	// interface. Without the bridge, command falls back to failing fast.
+	quit := make(chan struct{})
+	defer close(quit)
+	if server, err := askpass.Listen(askpassHandler(p, quit)); err == nil {
+		command.SetAskpassBridge(server.Addr())
+		defer server.Close()
+		defer command.SetAskpassBridge("")
+	}
 
 	if _, err := p.Run(); err != nil {
 		return err
@@ -1,12 +1,18 @@
 package tui
 
 import (
+	"context"
+	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/thorstenhirsch/gitbatch/internal/command"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
 )
 
 // focusRefreshDebounce caps how often a terminal focus-gain triggers a
@@ -14,6 +20,15 @@ import (
 // repeated fan-outs.
 const focusRefreshDebounce = 5 * time.Second
 
+const (
+	// forceRefreshDebounce ignores repeated ctrl+r presses while the previous
+	// refresh-all is still fanning out.
+	forceRefreshDebounce = 2 * time.Second
+	// forceRefreshWorkers caps how many repositories reload their metadata
+	// at once during a refresh-all, so probes don't flood the git queue.
+	forceRefreshWorkers = 4
+)
+
 const (
 	// TargetFPS is the maximum frames per second for the UI.
 	TargetFPS = 60
@@ -85,35 +100,63 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.ready = true
 		return m, m.maybeStartInitialStateEvaluation(nil)
 
+	case repositoryLoadedMsg:
+		if msg.repo == nil || !m.loading {
+			return m, nil
+		}
+		if !slices.Contains(m.repositories, msg.repo) {
+			m.addRepository(msg.repo)
+		}
+		return m, listenRepositoryLoadedCmd()
+
 	case repositoriesLoadedMsg:
+		// Streamed rows whose branches or remotes failed to load are dropped.
+		m.repositories = slices.DeleteFunc(m.repositories, func(repo *git.Repository) bool {
+			return !slices.Contains(msg.repos, repo)
+		})
 		for _, repo := range msg.repos {
 			if repo != nil {
-				m.addRepository(repo)
+				if !slices.Contains(m.repositories, repo) {
+					m.addRepository(repo)
+				}
 				if repo.State != nil {
-					repo.State.Message = "waiting"
+					repo.State.SetMessage(i18n.T("waiting"))
 				}
 				repo.SetWorkStatus(git.Pending)
 			}
 		}
 		m.applyRepositorySort()
+		// Column widths were computed while branches were still loading.
+		m.cachedWidth = 0
 		if m.cursor >= m.overviewRowCount() {
 			m.cursor = m.findLastNavigableIndex()
 		} else {
 			m.cursor = m.findNextReadyIndex(m.cursor)
 		}
 		m.loading = false
+		m.applySkippedDirectories(msg.skipped)
 		select {
 		case loadProgressCh <- 0:
 		default:
 		}
-		return m, m.maybeStartInitialStateEvaluation(nil)
+		select {
+		case repositoryLoadedCh <- nil:
+		default:
+		}
+		return m, tea.Batch(m.maybeStartInitialStateEvaluation(nil), m.startupObjectStatsCmd())
+
+	case objectStatsMsg:
+		m.applyObjectStats(msg)
+		return m, nil
 
 	case repositoryStateChangedMsg:
 		// Throttle O(n) job check to avoid starvation on large repo lists.
 		if m.shouldThrottleCheck(&m.lastJobCheck, 100*time.Millisecond) {
 			m.updateJobsRunningFlag()
 		}
-		if m.sortMode == repositorySortByTime {
+		m.updatePanelBatch()
+		// Ages and incoming commits change while jobs run.
+		if m.sortMode == repositorySortByTime || m.sortMode == repositorySortByBehind {
 			m.applyRepositorySort()
 		}
 		if m.worktreeMode {
@@ -124,21 +167,58 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case repositoriesWaitingMsg:
 		return m, m.ensureTicking()
 
+	case askpassRequestMsg:
+		m.enqueueAskpassQuestion(msg.question)
+		return m, nil
+
 	case lazygitClosedMsg:
 		return m.handleLazygitClosed(msg)
 
+	case statusFileEditedMsg:
+		return m.handleStatusFileEdited(msg)
+
+	case statusFilesLoadedMsg:
+		return m.handleStatusFilesLoaded(msg)
+
+	case checkoutConflictsMsg:
+		return m.handleCheckoutConflicts(msg)
+
+	case diffLoadedMsg:
+		return m.handleDiffLoaded(msg)
+
+	case queueResultMsg:
+		m.notice = msg.summary()
+		return m, nil
+
+	case batchStartedMsg:
+		if len(msg.waits) > 0 && m.dependencyWaits == nil {
+			m.dependencyWaits = make(map[*git.Repository]dependencyWait)
+		}
+		for r, deps := range msg.waits {
+			m.dependencyWaits[r] = dependencyWait{deps: deps, batch: msg.batch}
+		}
+		if msg.advice != "" {
+			m.notice = msg.advice
+		}
+		return m, m.ensureTicking()
+
 	case jobCompletedMsg:
+		m.applyBatchAbort()
+		pauses := m.applyHostPauses()
+		m.releaseDependents()
+		m.updatePanelBatch()
 		if m.jobsRunning || m.loading {
 			m.advanceSpinner()
 		}
 		if m.shouldThrottleCheck(&m.lastJobCheck, JobCheckInterval) {
 			m.updateJobsRunningFlag()
 		}
+		m.maybeChooseAutoMode()
 		if m.jobsRunning || m.loading {
-			return m, tickCmd()
+			return m, tea.Batch(pauses, tickCmd())
 		}
 		m.tickRunning = false
-		return m, nil
+		return m, pauses
 
 	case repoActionResultMsg:
 		if msg.closePanel {
@@ -149,6 +229,41 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.ensureSelectionWithinBounds(msg.panel)
 		return m, nil
 
+	case compareResultsMsg:
+		m.applyCompareResults(msg)
+		return m, nil
+
+	case identityProblemsMsg:
+		m.applyIdentityProblems(msg)
+		return m, nil
+
+	case recloneResultMsg:
+		m.applyRecloneResult(msg)
+		return m, nil
+
+	case forecastResultsMsg:
+		m.applyForecastResults(msg)
+		return m, nil
+
+	case cleanPreviewsMsg:
+		m.applyCleanPreviews(msg)
+		return m, nil
+
+	case patchResultsMsg:
+		m.applyPatchResults(msg)
+		return m, nil
+
+	case grepResultsMsg:
+		m.applyGrepResults(msg)
+		return m, nil
+
+	case bisectResultMsg:
+		refresh := m.applyBisectResult(msg)
+		if m.updateJobsRunningFlag() {
+			return m, tea.Batch(refresh, m.ensureTicking())
+		}
+		return m, refresh
+
 	case repoLoadProgressMsg:
 		m.loadedCount = msg.count
 		if m.loading {
@@ -202,17 +317,71 @@ func (m *Model) focusRefreshCmd(force bool) tea.Cmd {
 	}
 }
 
+// forceRefreshAllCmd reloads the metadata of every repository and re-runs the
+// full state probe, remote check included. Repositories are processed by a
+// small worker pool; presses within forceRefreshDebounce are ignored.
+func (m *Model) forceRefreshAllCmd() tea.Cmd {
+	m.updateMu.Lock()
+	if !m.lastForceRefresh.IsZero() && time.Since(m.lastForceRefresh) < forceRefreshDebounce {
+		m.updateMu.Unlock()
+		return nil
+	}
+	m.lastForceRefresh = time.Now()
+	m.updateMu.Unlock()
+
+	repos := filterRepositories(m.repositories)
+	if len(repos) == 0 {
+		return nil
+	}
+	m.jobsRunning = true
+
+	refresh := func() tea.Msg {
+		work := make(chan *git.Repository)
+		var wg sync.WaitGroup
+		for range min(forceRefreshWorkers, len(repos)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for r := range work {
+					_ = command.ForceRefresh(context.Background(), r)
+				}
+			}()
+		}
+		for _, r := range repos {
+			work <- r
+		}
+		close(work)
+		wg.Wait()
+		return nil
+	}
+	return tea.Batch(refresh, m.ensureTicking(), m.identityCheckCmd())
+}
+
 func (m *Model) handleLazygitClosed(msg lazygitClosedMsg) (tea.Model, tea.Cmd) {
 	repo := msg.repo
-	if repo.RefreshModTime().After(msg.originalModTime) {
+	after := repo.TakeSnapshot()
+	if msg.before.Changed(after) {
+		m.notice = "refreshed " + repo.Name + ": " + msg.before.DescribeChange(after)
 		// The TAB handler set Working as a lock while lazygit ran. Clear it so
-		// RequestExternalRefresh (which skips InFlight repos) can actually run.
+		// the refresh (which skips InFlight repos) can actually run.
 		repo.SetWorkStatusSilent(git.Available)
-		command.RequestExternalRefresh(repo)
 		m.jobsRunning = true
+		if msg.before.Branch != after.Branch {
+			// A different branch has a different upstream; re-probe the remote.
+			probe := func() tea.Msg {
+				if err := command.ForceRefresh(context.Background(), repo); err != nil {
+					return errMsg{err: err}
+				}
+				return nil
+			}
+			return m, tea.Batch(probe, m.ensureTicking())
+		}
+		command.RequestExternalRefresh(repo)
 		return m, m.ensureTicking()
 	}
-	*repo.State = msg.originalState
+	if msg.originalState != nil {
+		repo.State.Restore(msg.originalState)
+	}
 	repo.NotifyRepositoryUpdated()
 	if m.updateJobsRunningFlag() {
 		return m, m.ensureTicking()
@@ -223,7 +392,11 @@ func (m *Model) handleLazygitClosed(msg lazygitClosedMsg) (tea.Model, tea.Cmd) {
 // addRepository inserts r into m.repositories and registers its event listeners.
 func (m *Model) addRepository(r *git.Repository) {
 	rs := m.repositories
+	pinned := command.Pinned(r)
 	index := sort.Search(len(rs), func(i int) bool {
+		if other := command.Pinned(rs[i]); pinned != other {
+			return pinned
+		}
 		return git.CompareNamesInsensitive(r.Name, rs[i].Name) < 0
 	})
 	rs = append(rs, &git.Repository{})
@@ -244,11 +417,33 @@ func (m *Model) addRepository(r *git.Repository) {
 	}
 
 	m.repositories = rs
+	linkEnclosingRepository(r, rs)
 	if m.sortMode == repositorySortByTime {
 		m.applyRepositorySort()
 	}
 }
 
+// linkEnclosingRepository points r at the closest repository of rs whose
+// working tree contains it, and the repositories of rs nested in r at r.
+func linkEnclosingRepository(r *git.Repository, rs []*git.Repository) {
+	for _, other := range rs {
+		if other == r || other == nil {
+			continue
+		}
+		if nestedIn(r, other) && (r.Enclosing == nil || nestedIn(other, r.Enclosing)) {
+			r.Enclosing = other
+		}
+		if nestedIn(other, r) && (other.Enclosing == nil || nestedIn(r, other.Enclosing)) {
+			other.Enclosing = r
+		}
+	}
+}
+
+// nestedIn reports whether the working tree of r lies inside that of parent.
+func nestedIn(r, parent *git.Repository) bool {
+	return strings.HasPrefix(r.AbsPath, parent.AbsPath+string(filepath.Separator))
+}
+
 func (m *Model) currentRepository() *git.Repository {
 	row, ok := m.currentOverviewRow()
 	if !ok {
@@ -269,6 +464,10 @@ func (m *Model) currentWorktreeCommandRepository() *git.Repository {
 }
 
 func (m *Model) updateJobsRunningFlag() bool {
+	if len(m.dependencyWaits) > 0 {
+		m.jobsRunning = true
+		return true
+	}
 	for _, r := range m.repositories {
 		s := r.WorkStatus()
 		if s == git.Working || s == git.Pending {
@@ -338,7 +537,7 @@ func repoIsActionable(repo *git.Repository) bool {
 	status := repo.WorkStatus()
 	if status == git.Fail {
 		// Allow retry on a clean-message fail (preserves fail visualization).
-		if repo.State == nil || repo.State.Message != "" {
+		if repo.State == nil || repo.State.Message() != "" {
 			return false
 		}
 	} else if !status.Ready {
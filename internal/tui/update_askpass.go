@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/askpass"
+)
+
+// askpassQuestion is a question git or ssh asked through the askpass bridge.
+// The asking process blocks until an answer is sent on reply.
+type askpassQuestion struct {
+	request askpass.Request
+	reply   chan askpassAnswer
+}
+
+type askpassAnswer struct {
+	answer string
+	ok     bool
+}
+
+// askpassRequestMsg delivers a new askpass question to the update loop.
+type askpassRequestMsg struct {
+	question *askpassQuestion
+}
+
+// askpassHandler returns the askpass server handler that forwards questions
+// to the program and waits for the user's answer. Pending questions are
+// cancelled once quit is closed.
+func askpassHandler(p *tea.Program, quit <-chan struct{}) askpass.Handler {
+	return func(req askpass.Request) (string, bool) {
+		q := &askpassQuestion{request: req, reply: make(chan askpassAnswer, 1)}
+		p.Send(askpassRequestMsg{question: q})
+		select {
+		case answer := <-q.reply:
+			return answer.answer, answer.ok
+		case <-quit:
+			return "", false
+		}
+	}
+}
+
+func (m *Model) enqueueAskpassQuestion(q *askpassQuestion) {
+	if q == nil {
+		return
+	}
+	m.askpassQuestions = append(m.askpassQuestions, q)
+}
+
+func (m *Model) activeAskpassQuestion() *askpassQuestion {
+	if len(m.askpassQuestions) == 0 {
+		return nil
+	}
+	return m.askpassQuestions[0]
+}
+
+func (m *Model) handleAskpassKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	q := m.activeAskpassQuestion()
+	if q == nil {
+		return false, nil
+	}
+	if msg.Paste {
+		m.askpassBuffer += sanitizeCredentialPaste(string(msg.Runes))
+		return true, nil
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		m.answerAskpass(false)
+		return true, tea.Quit
+	case "esc":
+		m.answerAskpass(false)
+	case "enter":
+		m.answerAskpass(true)
+	case "ctrl+u":
+		m.askpassBuffer = ""
+	case "backspace", "ctrl+h":
+		runes := []rune(m.askpassBuffer)
+		if len(runes) > 0 {
+			m.askpassBuffer = string(runes[:len(runes)-1])
+		}
+	case " ":
+		m.askpassBuffer += " "
+	default:
+		if len(msg.Runes) > 0 {
+			m.askpassBuffer += string(msg.Runes)
+		}
+	}
+	return true, nil
+}
+
+// answerAskpass replies to the active question and moves on to the next one.
+func (m *Model) answerAskpass(ok bool) {
+	q := m.activeAskpassQuestion()
+	if q == nil {
+		return
+	}
+	answer := askpassAnswer{ok: ok}
+	if ok {
+		answer.answer = m.askpassBuffer
+	}
+	q.reply <- answer
+	m.askpassQuestions = m.askpassQuestions[1:]
+	m.askpassBuffer = ""
+}
+
+// askpassRepositoryName resolves the repository a question belongs to from
+// the working directory of the asking process.
+func (m *Model) askpassRepositoryName(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	best := ""
+	bestLen := 0
+	for _, r := range m.repositories {
+		if r == nil || r.AbsPath == "" {
+			continue
+		}
+		if dir != r.AbsPath && !strings.HasPrefix(dir, r.AbsPath+string(filepath.Separator)) {
+			continue
+		}
+		if len(r.AbsPath) > bestLen {
+			best, bestLen = r.Name, len(r.AbsPath)
+		}
+	}
+	if best == "" {
+		return filepath.Base(dir)
+	}
+	return best
+}
+
+func (m *Model) renderAskpassPrompt() string {
+	q := m.activeAskpassQuestion()
+	if q == nil {
+		return ""
+	}
+
+	panelWidth := 60
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+	if contentWidth < 10 {
+		contentWidth = 10
+	}
+
+	title := "git asks"
+	if name := m.askpassRepositoryName(q.request.Dir); name != "" {
+		title = fmt.Sprintf("git asks for %s", name)
+	}
+	answer := m.askpassBuffer
+	if q.request.Secret() {
+		answer = strings.Repeat("*", len([]rune(answer)))
+	}
+	lines := []string{
+		m.styles.PanelTitle.Render(truncateString(title, contentWidth)),
+		"",
+		strings.TrimSpace(q.request.Prompt),
+		fmt.Sprintf("> %s", truncateString(answer, contentWidth-2)),
+		"",
+	}
+	if pending := len(m.askpassQuestions) - 1; pending > 0 {
+		lines = append(lines, fmt.Sprintf("%d more question(s) waiting", pending))
+	}
+	lines = append(lines, "enter: answer | esc: cancel")
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
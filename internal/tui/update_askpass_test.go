@@ -0,0 +1,34 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/askpass"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestAskpassQuestion_AnswersInOrder(t *testing.T) {
+	repo := &git.Repository{Name: "api", AbsPath: "/src/api"}
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 80}
+	first := &askpassQuestion{request: askpass.Request{Prompt: "Password for 'https://example.com': ", Dir: "/src/api"}, reply: make(chan askpassAnswer, 1)}
+	second := &askpassQuestion{request: askpass.Request{Prompt: "Username for 'https://example.com': "}, reply: make(chan askpassAnswer, 1)}
+
+	m.Update(askpassRequestMsg{question: first})
+	m.Update(askpassRequestMsg{question: second})
+
+	view := m.renderAskpassPrompt()
+	require.Contains(t, view, "git asks for api")
+	require.Contains(t, view, "1 more question(s) waiting")
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("hunter2")})
+	require.NotContains(t, m.renderAskpassPrompt(), "hunter2")
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, askpassAnswer{answer: "hunter2", ok: true}, <-first.reply)
+
+	require.Same(t, second, m.activeAskpassQuestion())
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	require.Equal(t, askpassAnswer{}, <-second.reply)
+	require.Nil(t, m.activeAskpassQuestion())
+}
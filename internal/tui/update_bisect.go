@@ -0,0 +1,242 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// bisectResultMsg delivers the outcome of a bisect step.
+type bisectResultMsg struct {
+	repo     *git.Repository
+	progress *command.BisectProgress
+	err      error
+}
+
+// openBisect shows the bisect session of the selected repository, or asks
+// for the bad and good revisions to start one.
+func (m *Model) openBisect() {
+	r := m.currentRepository()
+	if r == nil || r.State == nil {
+		return
+	}
+	progress, err := command.BisectStatus(context.Background(), r)
+	if err != nil {
+		m.notice = "bisect: " + err.Error()
+		return
+	}
+	m.bisectActive = true
+	m.bisectRepo = r
+	m.bisectRunning = false
+	m.bisectProgress = progress
+	m.bisectField = bisectFieldGood
+	m.bisectBadBuffer = "HEAD"
+	m.bisectGoodBuffer = ""
+}
+
+func (m *Model) dismissBisect() {
+	m.bisectActive = false
+	m.bisectRepo = nil
+	m.bisectRunning = false
+	m.bisectProgress = nil
+	m.bisectBadBuffer = ""
+	m.bisectGoodBuffer = ""
+}
+
+func (m *Model) handleBisectKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.bisectActive {
+		return false, nil
+	}
+	if msg.String() == "ctrl+c" {
+		return true, tea.Quit
+	}
+	if m.bisectRunning {
+		// Steps cannot be interrupted; the view stays until git is done.
+		return true, nil
+	}
+	if m.bisectProgress == nil || !m.bisectProgress.Active {
+		return true, m.handleBisectStartKey(msg)
+	}
+
+	r := m.bisectRepo
+	switch msg.String() {
+	case "esc", "q":
+		m.dismissBisect()
+	case "g":
+		return true, m.bisectStepCmd(r, func(ctx context.Context) (*command.BisectProgress, error) {
+			return command.BisectMarkWithContext(ctx, r, command.BisectGood)
+		})
+	case "b":
+		return true, m.bisectStepCmd(r, func(ctx context.Context) (*command.BisectProgress, error) {
+			return command.BisectMarkWithContext(ctx, r, command.BisectBad)
+		})
+	case "s":
+		return true, m.bisectStepCmd(r, func(ctx context.Context) (*command.BisectProgress, error) {
+			return command.BisectMarkWithContext(ctx, r, command.BisectSkip)
+		})
+	case "r":
+		if command.BisectCommand() == "" {
+			m.notice = "set bisect_command in the config to run tests"
+			return true, nil
+		}
+		return true, m.bisectStepCmd(r, func(ctx context.Context) (*command.BisectProgress, error) {
+			return command.BisectRunWithContext(ctx, r)
+		})
+	case "x":
+		return true, m.bisectStepCmd(r, func(ctx context.Context) (*command.BisectProgress, error) {
+			if _, err := command.BisectResetWithContext(ctx, r); err != nil {
+				return nil, err
+			}
+			return command.BisectStatus(ctx, r)
+		})
+	}
+	return true, nil
+}
+
+func (m *Model) handleBisectStartKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.dismissBisect()
+	case "tab":
+		if m.bisectField == bisectFieldBad {
+			m.bisectField = bisectFieldGood
+		} else {
+			m.bisectField = bisectFieldBad
+		}
+	case "enter":
+		bad := strings.TrimSpace(m.bisectBadBuffer)
+		good := strings.TrimSpace(m.bisectGoodBuffer)
+		if bad == "" || good == "" {
+			return nil
+		}
+		r := m.bisectRepo
+		return m.bisectStepCmd(r, func(ctx context.Context) (*command.BisectProgress, error) {
+			return command.BisectStartWithContext(ctx, r, bad, good)
+		})
+	case "backspace", "ctrl+h":
+		buffer := m.bisectInputBuffer()
+		runes := []rune(*buffer)
+		if len(runes) > 0 {
+			*buffer = string(runes[:len(runes)-1])
+		}
+	case " ":
+		// Revisions cannot contain spaces.
+	default:
+		if len(msg.Runes) > 0 {
+			*m.bisectInputBuffer() += string(msg.Runes)
+		}
+	}
+	return nil
+}
+
+func (m *Model) bisectInputBuffer() *string {
+	if m.bisectField == bisectFieldBad {
+		return &m.bisectBadBuffer
+	}
+	return &m.bisectGoodBuffer
+}
+
+// bisectStepCmd runs a bisect step on the git queue of the repository and
+// waits for its result. Every step checks out another commit, so the
+// repository is refreshed once the result arrives.
+func (m *Model) bisectStepCmd(r *git.Repository, step command.BisectStep) tea.Cmd {
+	if r == nil || r.State == nil {
+		return nil
+	}
+	m.bisectRunning = true
+	r.State.SetMessage(i18n.T("bisecting"))
+	r.SetWorkStatus(git.Pending)
+	return func() tea.Msg {
+		result := make(chan bisectResultMsg, 1)
+		err := command.NewExecutor(r).ScheduleBisect(step, func(progress *command.BisectProgress, err error) {
+			result <- bisectResultMsg{repo: r, progress: progress, err: err}
+		})
+		if err != nil {
+			r.SetWorkStatus(git.Available)
+			return bisectResultMsg{repo: r, err: err}
+		}
+		return <-result
+	}
+}
+
+func (m *Model) applyBisectResult(msg bisectResultMsg) tea.Cmd {
+	if msg.err != nil {
+		msg.repo.State.SetMessage(msg.err.Error())
+	} else {
+		msg.repo.State.SetMessage(command.BisectSummary(msg.progress))
+	}
+	refresh := func() tea.Msg {
+		if err := scheduleRefresh(msg.repo); err != nil {
+			return errMsg{err: fmt.Errorf("refresh repository %s: %w", msg.repo.Name, err)}
+		}
+		return nil
+	}
+	if !m.bisectActive || msg.repo != m.bisectRepo {
+		return refresh
+	}
+	m.bisectRunning = false
+	if msg.err != nil {
+		m.notice = "bisect: " + msg.err.Error()
+		return refresh
+	}
+	m.bisectProgress = msg.progress
+	if !msg.progress.Active {
+		m.dismissBisect()
+		m.notice = "bisect reset in " + msg.repo.Name
+	}
+	return refresh
+}
+
+func (m *Model) renderBisect() string {
+	if !m.bisectActive || m.bisectRepo == nil {
+		return ""
+	}
+	panelWidth := 72
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+
+	lines := []string{m.styles.PanelTitle.Render(truncateString("Bisect "+m.bisectRepo.Name, contentWidth)), ""}
+	progress := m.bisectProgress
+	var hints string
+	switch {
+	case progress == nil || !progress.Active:
+		badIndicator, goodIndicator := " ", " "
+		if m.bisectField == bisectFieldBad {
+			badIndicator = ">"
+		} else {
+			goodIndicator = ">"
+		}
+		lines = append(lines,
+			fmt.Sprintf("%s Bad (has the bug):  %s", badIndicator, truncateString(m.bisectBadBuffer, contentWidth-22)),
+			fmt.Sprintf("%s Good (without it):  %s", goodIndicator, truncateString(m.bisectGoodBuffer, contentWidth-22)),
+		)
+		hints = "enter: start | tab: switch field | esc: cancel"
+	case progress.FirstBad != "":
+		lines = append(lines, "First bad commit:", "  "+m.styles.BranchInfo.Render(truncateString(progress.FirstBad, contentWidth-2)))
+		hints = "x: reset and return to the branch | esc: close"
+	default:
+		lines = append(lines,
+			"Testing   "+truncateString(progress.Current, contentWidth-10),
+			fmt.Sprintf("Progress  %d revision(s) left (roughly %d step(s))", progress.Remaining, progress.Steps),
+		)
+		if test := command.BisectCommand(); test != "" {
+			lines = append(lines, "Test      "+truncateString(test, contentWidth-10))
+		}
+		hints = "g: good | b: bad | s: skip | r: run test | x: reset | esc: close"
+	}
+	if m.bisectRunning {
+		lines = append(lines, "", "Running git bisect...")
+	}
+	lines = append(lines, "", m.styles.Help.Render(truncateString(hints, contentWidth)))
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
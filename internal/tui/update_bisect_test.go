@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestBisect_MarksCommitsUntilFirstBad(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(repo.AbsPath, "README.md"), []byte(fmt.Sprintf("step %d", i)), 0o644))
+		runBranchTestGit(t, repo.AbsPath, "commit", "-am", fmt.Sprintf("step %d", i))
+	}
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 100}
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	require.True(t, m.bisectActive)
+	require.Contains(t, m.renderBisect(), "Bad (has the bug):  HEAD")
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("HEAD~3")})
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	require.True(t, m.bisectRunning)
+	require.Equal(t, "bisecting", repo.State.Message())
+	m.Update(cmd())
+	require.True(t, m.bisectProgress.Active)
+	require.Contains(t, repo.State.Message(), "bisecting: ")
+	require.Contains(t, m.renderBisect(), "g: good | b: bad")
+
+	// Every tested commit is bad, so the first commit after the good one is
+	// the culprit.
+	for m.bisectProgress.FirstBad == "" {
+		_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+		m.Update(cmd())
+	}
+	require.Contains(t, m.bisectProgress.FirstBad, "step 1")
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	require.Equal(t, "set bisect_command in the config to run tests", m.notice)
+
+	_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	m.Update(cmd())
+	require.False(t, m.bisectActive)
+	require.Equal(t, "main", currentBranchName(t, repo.AbsPath))
+}
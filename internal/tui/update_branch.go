@@ -7,6 +7,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/thorstenhirsch/gitbatch/internal/command"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
 )
 
 func (m *Model) handleBranchPromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
@@ -53,17 +54,29 @@ func (m *Model) openBranchPrompt() {
 	m.branchPromptActive = true
 	m.branchPromptRepos = repos
 	m.branchNameBuffer = ""
+	m.branchStartPoint = ""
+}
+
+// openBranchPromptAt asks for the name of a branch created at startPoint in
+// repo instead of at HEAD.
+func (m *Model) openBranchPromptAt(repo *git.Repository, startPoint string) {
+	m.branchPromptActive = true
+	m.branchPromptRepos = []*git.Repository{repo}
+	m.branchNameBuffer = ""
+	m.branchStartPoint = startPoint
 }
 
 func (m *Model) dismissBranchPrompt() {
 	m.branchPromptActive = false
 	m.branchPromptRepos = nil
 	m.branchNameBuffer = ""
+	m.branchStartPoint = ""
 }
 
 func (m *Model) submitBranchPrompt() tea.Cmd {
 	repos := m.branchPromptRepos
 	branchName := strings.TrimSpace(m.branchNameBuffer)
+	startPoint := m.branchStartPoint
 	m.dismissBranchPrompt()
 
 	if len(repos) == 0 {
@@ -72,15 +85,21 @@ func (m *Model) submitBranchPrompt() tea.Cmd {
 	if branchName == "" {
 		for _, repo := range repos {
 			if repo != nil && repo.State != nil {
-				repo.State.Message = "branch name required"
+				repo.State.SetMessage(i18n.T("branch name required"))
 			}
 		}
 		return nil
 	}
-	return m.createBranchCmd(repos, branchName)
+	return m.createBranchAtCmd(repos, branchName, startPoint)
 }
 
 func (m *Model) createBranchCmd(repos []*git.Repository, branchName string) tea.Cmd {
+	return m.createBranchAtCmd(repos, branchName, "")
+}
+
+// createBranchAtCmd creates and checks out branchName at startPoint, or at
+// HEAD when startPoint is empty.
+func (m *Model) createBranchAtCmd(repos []*git.Repository, branchName, startPoint string) tea.Cmd {
 	filtered := filterRepositories(repos)
 	if len(filtered) == 0 || branchName == "" {
 		return nil
@@ -90,7 +109,7 @@ func (m *Model) createBranchCmd(repos []*git.Repository, branchName string) tea.
 		for _, repo := range filtered {
 			if findBranchByName(repo, branchName) != nil {
 				if repo.State != nil {
-					repo.State.Message = fmt.Sprintf("branch %s already exists", branchName)
+					repo.State.SetMessage(fmt.Sprintf("branch %s already exists", branchName))
 				}
 				return repoActionResultMsg{panel: BranchPanel}
 			}
@@ -98,16 +117,20 @@ func (m *Model) createBranchCmd(repos []*git.Repository, branchName string) tea.
 
 		for _, repo := range filtered {
 			if repo.State != nil {
-				repo.State.Message = fmt.Sprintf("creating %s", branchName)
+				repo.State.SetMessage(fmt.Sprintf("creating %s", branchName))
+			}
+			args := []string{"checkout", "-b", branchName}
+			if startPoint != "" {
+				args = append(args, startPoint)
 			}
-			if _, err := command.Run(repo.AbsPath, "git", []string{"checkout", "-b", branchName}); err != nil {
+			if _, err := command.RunRecorded(repo, command.OperationBranch, args); err != nil {
 				if repo.State != nil {
-					repo.State.Message = err.Error()
+					repo.State.SetMessage(err.Error())
 				}
 				return errMsg{err: fmt.Errorf("create branch %s in %s: %w", branchName, repo.Name, err)}
 			}
 			if repo.State != nil {
-				repo.State.Message = fmt.Sprintf("switched to %s", branchName)
+				repo.State.SetMessage(fmt.Sprintf("switched to %s", branchName))
 			}
 			if err := scheduleRefresh(repo); err != nil {
 				return errMsg{err: fmt.Errorf("refresh repository %s: %w", repo.Name, err)}
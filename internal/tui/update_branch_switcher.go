@@ -0,0 +1,113 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// branchSwitcherMaxRows caps the height of the inline branch dropdown.
+const branchSwitcherMaxRows = 8
+
+func (m *Model) handleBranchSwitcherKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.branchSwitcherActive {
+		return false, nil
+	}
+
+	branches := m.branchSwitcherBranches()
+	count := len(branches)
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc", "ctrl+g", "q":
+		m.dismissBranchSwitcher()
+	case "up", "k":
+		wrapCursor(&m.branchSwitcherCursor, count, -1)
+	case "down", "j":
+		wrapCursor(&m.branchSwitcherCursor, count, 1)
+	case "home", "g":
+		m.branchSwitcherCursor = 0
+	case "end", "G":
+		m.branchSwitcherCursor = max(count-1, 0)
+	case "enter":
+		repo := m.branchSwitcherRepo
+		cursor := m.branchSwitcherCursor
+		m.dismissBranchSwitcher()
+		if count == 0 {
+			return true, nil
+		}
+		return true, m.checkoutBranchCmd(repo, branches[clampIndex(cursor, count)])
+	}
+	m.ensureBranchSwitcherCursorVisible(count)
+	return true, nil
+}
+
+// openBranchSwitcher shows the inline branch dropdown below the selected row,
+// with the checked out branch preselected.
+func (m *Model) openBranchSwitcher() {
+	if m.worktreeMode {
+		return
+	}
+	repo := m.currentRepository()
+	if repo == nil || len(repo.Branches) == 0 || repo.WorkStatus() == git.Working {
+		return
+	}
+	m.branchSwitcherActive = true
+	m.branchSwitcherRepo = repo
+	m.branchSwitcherCursor = 0
+	m.branchSwitcherOffset = 0
+	if repo.State != nil && repo.State.Branch != nil {
+		for i, branch := range m.branchSwitcherBranches() {
+			if branch.Name == repo.State.Branch.Name {
+				m.branchSwitcherCursor = i
+				break
+			}
+		}
+	}
+	m.ensureBranchSwitcherCursorVisible(len(m.branchSwitcherBranches()))
+}
+
+func (m *Model) dismissBranchSwitcher() {
+	m.branchSwitcherActive = false
+	m.branchSwitcherRepo = nil
+	m.branchSwitcherCursor = 0
+	m.branchSwitcherOffset = 0
+}
+
+// branchSwitcherBranches returns the local branches offered by the dropdown.
+func (m *Model) branchSwitcherBranches() []*git.Branch {
+	if m.branchSwitcherRepo == nil {
+		return nil
+	}
+	return filterBranches(m.branchSwitcherRepo.Branches)
+}
+
+// branchSwitcherRows returns how many rows the dropdown occupies below r.
+func (m *Model) branchSwitcherRows(r *git.Repository) int {
+	if !m.branchSwitcherActive || r == nil || r != m.branchSwitcherRepo {
+		return 0
+	}
+	return min(len(m.branchSwitcherBranches()), branchSwitcherMaxRows)
+}
+
+func (m *Model) ensureBranchSwitcherCursorVisible(count int) {
+	if count <= branchSwitcherMaxRows {
+		m.branchSwitcherOffset = 0
+		return
+	}
+	if m.branchSwitcherCursor < m.branchSwitcherOffset {
+		m.branchSwitcherOffset = m.branchSwitcherCursor
+	}
+	if m.branchSwitcherCursor >= m.branchSwitcherOffset+branchSwitcherMaxRows {
+		m.branchSwitcherOffset = m.branchSwitcherCursor - branchSwitcherMaxRows + 1
+	}
+}
+
+func filterBranches(branches []*git.Branch) []*git.Branch {
+	filtered := make([]*git.Branch, 0, len(branches))
+	for _, branch := range branches {
+		if branch != nil {
+			filtered = append(filtered, branch)
+		}
+	}
+	return filtered
+}
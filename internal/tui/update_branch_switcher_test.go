@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestBranchSwitcher_OpensOnCurrentBranchAndNavigates(t *testing.T) {
+	repo := testBranchPromptRepo("alpha", "main")
+	repo.Branches = []*git.Branch{{Name: "develop"}, {Name: "main"}, {Name: "release"}}
+
+	model := Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 120, height: 30}
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlB})
+	require.False(t, model.branchSwitcherActive, "Ctrl+B pages up as before")
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlG})
+	require.True(t, model.branchSwitcherActive)
+	require.Same(t, repo, model.branchSwitcherRepo)
+	require.Equal(t, 1, model.branchSwitcherCursor)
+	require.Equal(t, 4, model.repoRowCount(repo))
+
+	view := ansi.Strip(model.renderOverview())
+	require.Contains(t, view, "* main")
+	require.Contains(t, view, "release")
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	require.Equal(t, 2, model.branchSwitcherCursor)
+	require.Equal(t, 0, model.cursor)
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	require.False(t, model.branchSwitcherActive)
+	require.Equal(t, 1, model.repoRowCount(repo))
+}
+
+func TestBranchSwitcher_EnterChecksOutSelectedBranch(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	runBranchTestGit(t, repo.AbsPath, "branch", "feature")
+	require.NoError(t, repo.Refresh())
+
+	model := Model{repositories: []*git.Repository{repo}}
+	model.openBranchSwitcher()
+	require.True(t, model.branchSwitcherActive)
+	for model.branchSwitcherBranches()[model.branchSwitcherCursor].Name != "feature" {
+		model.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+	}
+
+	_, cmd := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.False(t, model.branchSwitcherActive)
+	cmd = resolveCheckoutConflicts(t, &model, cmd)
+	require.NotNil(t, cmd)
+	cmd()
+	require.Eventually(t, func() bool {
+		return !repo.WorkStatus().InFlight() && currentBranchName(t, repo.AbsPath) == "feature"
+	}, 10*time.Second, 20*time.Millisecond)
+}
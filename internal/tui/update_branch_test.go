@@ -1,13 +1,17 @@
 package tui
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
 )
 
@@ -117,3 +121,43 @@ func runBranchTestGit(t *testing.T, dir string, args ...string) string {
 	require.NoErrorf(t, err, "git %v failed: %s", args, string(output))
 	return string(output)
 }
+
+func TestCtrlZUndoesTheLastCheckout(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	runBranchTestGit(t, repo.AbsPath, "branch", "feature")
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 100, height: 30}
+
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	require.Nil(t, cmd)
+	require.Equal(t, "nothing to undo in alpha", m.notice)
+
+	require.NoError(t, command.NewExecutor(repo).RunCheckout(context.Background(), &command.CheckoutOptions{Branch: "feature"}))
+	entry, ok := command.PeekUndo(repo)
+	require.True(t, ok)
+	require.Equal(t, "main", entry.Ref)
+
+	_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	require.NotNil(t, cmd)
+	require.True(t, m.jobsRunning)
+}
+
+func TestMultiRepoDeleteReportsACombinedResult(t *testing.T) {
+	alpha := initBranchCreationRepo(t, "alpha")
+	beta := initBranchCreationRepo(t, "beta")
+	gamma := initBranchCreationRepo(t, "gamma")
+	runBranchTestGit(t, alpha.AbsPath, "branch", "feature")
+	runBranchTestGit(t, gamma.AbsPath, "branch", "feature")
+	m := &Model{repositories: []*git.Repository{alpha, beta, gamma}, styles: DefaultStyles(), width: 120, height: 30}
+
+	cmd := m.deleteBranchMultiCmd([]*git.Repository{alpha, beta, gamma}, "feature")
+	require.NotNil(t, cmd)
+	require.Equal(t, "deleting feature in 3 repos: 0 done", m.notice)
+	require.Equal(t, repoActionResultMsg{panel: BranchPanel}, cmd())
+
+	require.Eventually(t, func() bool {
+		m.updatePanelBatch()
+		return m.panelBatch == nil
+	}, 10*time.Second, 20*time.Millisecond)
+	require.Equal(t, "deleting feature in 3 repos: 2 succeeded, failed in beta, press ! to review", m.notice)
+	require.Empty(t, strings.TrimSpace(runBranchTestGit(t, gamma.AbsPath, "branch", "--list", "feature")))
+}
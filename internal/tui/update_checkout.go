@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// atomicCheckout rolls back the repositories a multi-repo checkout
+// already switched when it fails in one of them.
+var atomicCheckout atomic.Bool
+
+// SetAtomicCheckout configures whether multi-repo checkouts switch
+// either all repositories or none.
+func SetAtomicCheckout(enabled bool) {
+	atomicCheckout.Store(enabled)
+}
+
+// checkoutConflictsMsg carries the files the checkouts of a panel would
+// overwrite, by repository.
+type checkoutConflictsMsg struct {
+	jobs       []panelJob
+	panel      SidePanelType
+	closePanel bool
+	conflicts  map[*git.Repository][]string
+}
+
+// startCheckoutJobs looks for local changes the checkout jobs would overwrite
+// in the background; handleCheckoutConflicts starts them or asks first.
+func (m *Model) startCheckoutJobs(jobs []panelJob, panel SidePanelType, closePanel bool) tea.Cmd {
+	if len(jobs) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		conflicts := make(map[*git.Repository][]string)
+		for _, pj := range jobs {
+			options, ok := pj.options.(*command.CheckoutOptions)
+			if !ok {
+				continue
+			}
+			paths, err := command.CheckoutConflicts(context.Background(), pj.repo, options)
+			if err == nil && len(paths) > 0 {
+				conflicts[pj.repo] = paths
+			}
+		}
+		return checkoutConflictsMsg{jobs: jobs, panel: panel, closePanel: closePanel, conflicts: conflicts}
+	}
+}
+
+// handleCheckoutConflicts starts the checkout jobs unless one of them would
+// overwrite local changes. Then nothing is started and a prompt offers to
+// stash the changes around the checkout instead. Repositories the check
+// fails for are left to git, which refuses the checkout itself.
+func (m *Model) handleCheckoutConflicts(msg checkoutConflictsMsg) (tea.Model, tea.Cmd) {
+	if len(msg.conflicts) == 0 {
+		return m, m.runCheckouts(msg.jobs, msg.panel, msg.closePanel)
+	}
+	m.activeCheckoutPrompt = &checkoutPrompt{jobs: msg.jobs, panel: msg.panel, closePanel: msg.closePanel, conflicts: msg.conflicts}
+	return m, nil
+}
+
+// confirmCheckoutStash starts the checkouts of the active prompt, stashing
+// the local changes of the blocked repositories before switching and popping
+// them afterwards.
+func (m *Model) confirmCheckoutStash() tea.Cmd {
+	prompt := m.activeCheckoutPrompt
+	m.activeCheckoutPrompt = nil
+	if prompt == nil {
+		return nil
+	}
+	jobs := make([]panelJob, len(prompt.jobs))
+	for i, pj := range prompt.jobs {
+		if options, ok := pj.options.(*command.CheckoutOptions); ok && len(prompt.conflicts[pj.repo]) > 0 {
+			stashed := *options
+			stashed.Stash = true
+			pj.options = &stashed
+		}
+		jobs[i] = pj
+	}
+	return m.runCheckouts(jobs, prompt.panel, prompt.closePanel)
+}
+
+// runCheckouts queues the checkout jobs. When atomic_checkout is enabled and
+// they switch several repositories, they run as steps of one transaction.
+func (m *Model) runCheckouts(jobs []panelJob, panel SidePanelType, closePanel bool) tea.Cmd {
+	if len(jobs) < 2 || !atomicCheckout.Load() {
+		return m.startPanelJobs(jobs, panel, closePanel)
+	}
+	transaction := command.NewCheckoutTransaction(len(jobs))
+	steps := make([]panelJob, len(jobs))
+	for i, pj := range jobs {
+		options, ok := pj.options.(*command.CheckoutOptions)
+		if !ok {
+			return m.startPanelJobs(jobs, panel, closePanel)
+		}
+		pj.options = command.CheckoutStep{Transaction: transaction, Options: options}
+		steps[i] = pj
+	}
+	return m.startPanelJobs(steps, panel, closePanel)
+}
+
+// abortCheckout drops the checkouts of the active prompt.
+func (m *Model) abortCheckout() {
+	prompt := m.activeCheckoutPrompt
+	m.activeCheckoutPrompt = nil
+	if prompt == nil {
+		return
+	}
+	var names []string
+	for _, repo := range prompt.blocked() {
+		names = append(names, repo.Name)
+	}
+	m.notice = fmt.Sprintf("checkout aborted, local changes in %s", strings.Join(names, ", "))
+}
+
+// blocked lists the repositories whose local changes are in the way, in the
+// order of the jobs.
+func (p *checkoutPrompt) blocked() []*git.Repository {
+	var repos []*git.Repository
+	for _, pj := range p.jobs {
+		if len(p.conflicts[pj.repo]) > 0 {
+			repos = append(repos, pj.repo)
+		}
+	}
+	return repos
+}
+
+// checkoutPromptText describes the changes of the active prompt for the
+// status bar.
+func (m *Model) checkoutPromptText() string {
+	prompt := m.activeCheckoutPrompt
+	if prompt == nil {
+		return ""
+	}
+	repos := prompt.blocked()
+	if len(repos) == 1 {
+		paths := prompt.conflicts[repos[0]]
+		name := paths[0]
+		if len(paths) > 1 {
+			name = i18n.T("%s and %d more", name, len(paths)-1)
+		}
+		return i18n.T("Checkout would overwrite %s. Stash, check out and pop?", name)
+	}
+	return i18n.T("Checkout would overwrite local changes in %d repositories. Stash, check out and pop?", len(repos))
+}
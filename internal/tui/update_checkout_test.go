@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// resolveCheckoutConflicts runs the conflict check of a checkout and hands
+// its result to the model, returning the command that starts the checkouts.
+func resolveCheckoutConflicts(t *testing.T, m *Model, cmd tea.Cmd) tea.Cmd {
+	t.Helper()
+	require.NotNil(t, cmd)
+	msg, ok := cmd().(checkoutConflictsMsg)
+	require.True(t, ok)
+	_, next := m.Update(msg)
+	return next
+}
+
+func TestCheckoutPromptOffersToStashOverwrittenChanges(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	lines := filepath.Join(repo.AbsPath, "lines.txt")
+	require.NoError(t, os.WriteFile(lines, []byte("a\nb\nc\nd\ne\n"), 0o644))
+	runBranchTestGit(t, repo.AbsPath, "add", "lines.txt")
+	runBranchTestGit(t, repo.AbsPath, "commit", "-m", "lines")
+	runBranchTestGit(t, repo.AbsPath, "checkout", "-b", "feature")
+	require.NoError(t, os.WriteFile(lines, []byte("A\nb\nc\nd\ne\n"), 0o644))
+	runBranchTestGit(t, repo.AbsPath, "commit", "-am", "feature lines")
+	runBranchTestGit(t, repo.AbsPath, "checkout", "main")
+	require.NoError(t, os.WriteFile(lines, []byte("a\nb\nc\nd\nE\n"), 0o644))
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 120, height: 30}
+
+	require.Nil(t, resolveCheckoutConflicts(t, m, m.checkoutBranchCmd(repo, &git.Branch{Name: "feature"})))
+	require.NotNil(t, m.activeCheckoutPrompt)
+	require.Equal(t, "Checkout would overwrite lines.txt. Stash, check out and pop?", m.checkoutPromptText())
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	require.Nil(t, m.activeCheckoutPrompt)
+	require.Equal(t, "checkout aborted, local changes in alpha", m.notice)
+	require.Equal(t, "main", currentBranchName(t, repo.AbsPath))
+
+	require.Nil(t, resolveCheckoutConflicts(t, m, m.checkoutBranchCmd(repo, &git.Branch{Name: "feature"})))
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Nil(t, m.activeCheckoutPrompt)
+	require.NotNil(t, cmd)
+	cmd()
+
+	require.Eventually(t, func() bool {
+		return currentBranchName(t, repo.AbsPath) == "feature" && repo.WorkStatus() != git.Pending && repo.WorkStatus() != git.Working
+	}, 10*time.Second, 20*time.Millisecond)
+	data, err := os.ReadFile(lines)
+	require.NoError(t, err)
+	require.Equal(t, "A\nb\nc\nd\nE\n", string(data))
+	require.Empty(t, strings.TrimSpace(runBranchTestGit(t, repo.AbsPath, "stash", "list")))
+}
+
+func TestCheckoutWithoutOverwrittenChangesStartsRightAway(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	runBranchTestGit(t, repo.AbsPath, "branch", "feature")
+	require.NoError(t, os.WriteFile(filepath.Join(repo.AbsPath, "README.md"), []byte("local"), 0o644))
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 120, height: 30}
+
+	require.NotNil(t, resolveCheckoutConflicts(t, m, m.checkoutBranchCmd(repo, &git.Branch{Name: "feature"})))
+	require.Nil(t, m.activeCheckoutPrompt)
+}
+
+func TestAtomicCheckoutRollsBackTheTaggedRepositories(t *testing.T) {
+	SetAtomicCheckout(true)
+	t.Cleanup(func() { SetAtomicCheckout(false) })
+	alpha := initBranchCreationRepo(t, "alpha")
+	beta := initBranchCreationRepo(t, "beta")
+	runBranchTestGit(t, alpha.AbsPath, "push", "origin", "main:release")
+	runBranchTestGit(t, alpha.AbsPath, "fetch", "origin")
+	m := &Model{repositories: []*git.Repository{alpha, beta}, styles: DefaultStyles(), width: 120, height: 30}
+
+	cmd := resolveCheckoutConflicts(t, m, m.checkoutRemoteBranchMultiCmd([]*git.Repository{alpha, beta}, remotePanelEntry{RemoteName: "origin", BranchName: "release", FullName: "origin/release"}))
+	require.NotNil(t, cmd)
+	_, ok := cmd().(repoActionResultMsg)
+	require.True(t, ok)
+	require.Eventually(t, func() bool {
+		return !alpha.WorkStatus().InFlight() && alpha.State.Message() == "checkout rolled back"
+	}, 10*time.Second, 20*time.Millisecond)
+	require.Equal(t, "main", currentBranchName(t, alpha.AbsPath))
+	require.Eventually(t, func() bool { return beta.WorkStatus() == git.Fail }, 10*time.Second, 20*time.Millisecond)
+}
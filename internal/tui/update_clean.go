@@ -0,0 +1,233 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// cleanPreviewPaths caps the paths listed under the selected repository.
+const cleanPreviewPaths = 8
+
+// cleanPreviewsMsg delivers the git clean dry runs of the selected
+// repositories.
+type cleanPreviewsMsg struct {
+	previews []*command.CleanPreview
+}
+
+// openCleanPreview shows what git clean would remove in repos before anything
+// is deleted.
+func (m *Model) openCleanPreview(repos []*git.Repository) tea.Cmd {
+	repos = filterRepositories(repos)
+	if len(repos) == 0 {
+		m.notice = "no repository selected"
+		return nil
+	}
+	m.cleanActive = true
+	m.cleanRunning = true
+	m.cleanCursor = 0
+	m.cleanPreviews = nil
+	m.cleanSkipped = make(map[string]bool)
+	return cleanPreviewCmd(repos)
+}
+
+func (m *Model) dismissCleanPreview() {
+	m.cleanActive = false
+	m.cleanRunning = false
+	m.cleanCursor = 0
+	m.cleanPreviews = nil
+	m.cleanSkipped = nil
+}
+
+func (m *Model) handleCleanKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.cleanActive {
+		return false, nil
+	}
+	count := len(m.cleanPreviews)
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc", "C", "q":
+		m.dismissCleanPreview()
+	case "up", "k":
+		if count > 0 {
+			wrapCursor(&m.cleanCursor, count, -1)
+		}
+	case "down", "j":
+		if count > 0 {
+			wrapCursor(&m.cleanCursor, count, 1)
+		}
+	case " ", "space":
+		if count > 0 {
+			id := m.cleanPreviews[clampIndex(m.cleanCursor, count)].Repository.RepoID
+			m.cleanSkipped[id] = !m.cleanSkipped[id]
+		}
+	case "enter":
+		if m.cleanRunning {
+			return true, nil
+		}
+		repos := m.cleanTargets()
+		m.dismissCleanPreview()
+		if len(repos) == 0 {
+			m.notice = "nothing to clean"
+			return true, nil
+		}
+		return true, m.confirmDestructive(i18n.T("remove untracked files"), repos, func() tea.Cmd {
+			return cleanCmd(repos)
+		})
+	}
+	return true, nil
+}
+
+// cleanTargets are the previewed repositories that have something to remove
+// and were not skipped.
+func (m *Model) cleanTargets() []*git.Repository {
+	var repos []*git.Repository
+	for _, preview := range m.cleanPreviews {
+		if preview.Err == nil && len(preview.Paths) > 0 && !m.cleanSkipped[preview.Repository.RepoID] {
+			repos = append(repos, preview.Repository)
+		}
+	}
+	return repos
+}
+
+func (m *Model) applyCleanPreviews(msg cleanPreviewsMsg) {
+	if !m.cleanActive {
+		return
+	}
+	m.cleanRunning = false
+	m.cleanPreviews = msg.previews
+	m.cleanCursor = clampIndex(m.cleanCursor, len(msg.previews))
+}
+
+// cleanPreviewCmd runs the dry runs concurrently, bounded by the git
+// semaphore.
+func cleanPreviewCmd(repos []*git.Repository) tea.Cmd {
+	return func() tea.Msg {
+		var (
+			mu       sync.Mutex
+			wg       sync.WaitGroup
+			previews []*command.CleanPreview
+			work     = make(chan *git.Repository)
+		)
+		for range min(forceRefreshWorkers, max(len(repos), 1)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for r := range work {
+					if err := git.AcquireGitSemaphore(context.Background()); err != nil {
+						continue
+					}
+					preview := command.PreviewClean(context.Background(), r)
+					git.ReleaseGitSemaphore()
+					mu.Lock()
+					previews = append(previews, preview)
+					mu.Unlock()
+				}
+			}()
+		}
+		for _, r := range repos {
+			work <- r
+		}
+		close(work)
+		wg.Wait()
+		sort.Slice(previews, func(i, j int) bool {
+			return previews[i].Repository.Name < previews[j].Repository.Name
+		})
+		return cleanPreviewsMsg{previews: previews}
+	}
+}
+
+// cleanCmd removes the untracked and ignored files of repos.
+func cleanCmd(repos []*git.Repository) tea.Cmd {
+	return func() tea.Msg {
+		for _, repo := range repos {
+			if repo.State != nil {
+				repo.State.SetMessage(i18n.T("cleaning untracked files"))
+			}
+			msg, err := command.Clean(repo)
+			if err != nil {
+				if repo.State != nil {
+					repo.State.SetMessage(err.Error())
+				}
+				return errMsg{err: fmt.Errorf("clean %s: %w", repo.Name, err)}
+			}
+			if repo.State != nil {
+				repo.State.SetMessage(msg)
+			}
+			if err := scheduleRefresh(repo); err != nil {
+				return errMsg{err: fmt.Errorf("refresh repository %s: %w", repo.Name, err)}
+			}
+		}
+		return repoActionResultMsg{}
+	}
+}
+
+func cleanPreviewLabel(preview *command.CleanPreview, skipped bool) string {
+	label := preview.Repository.Name + ": "
+	switch {
+	case preview.Err != nil:
+		label += preview.Err.Error()
+	case len(preview.Paths) == 0:
+		label += "nothing to clean"
+	default:
+		label += fmt.Sprintf("%d path(s)", len(preview.Paths))
+	}
+	if skipped {
+		label += " (skipped)"
+	}
+	return label
+}
+
+func (m *Model) renderCleanPreview() string {
+	if !m.cleanActive {
+		return ""
+	}
+	panelWidth := 72
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+
+	lines := []string{m.styles.PanelTitle.Render("Clean untracked and ignored files"), ""}
+	if m.cleanRunning {
+		lines = append(lines, "Running git clean -ndx...")
+	} else {
+		for i, preview := range m.cleanPreviews {
+			label := cleanPreviewLabel(preview, m.cleanSkipped[preview.Repository.RepoID])
+			if i != m.cleanCursor {
+				lines = append(lines, truncateString("  "+label, contentWidth))
+				continue
+			}
+			lines = append(lines, m.styles.SelectedItem.Render(padToWidth(truncateString("> "+label, contentWidth), contentWidth)))
+			for j, path := range preview.Paths {
+				if j == cleanPreviewPaths {
+					lines = append(lines, fmt.Sprintf("    ... and %d more", len(preview.Paths)-j))
+					break
+				}
+				lines = append(lines, truncateString("    "+path, contentWidth))
+			}
+		}
+		paths := 0
+		targets := m.cleanTargets()
+		for _, preview := range m.cleanPreviews {
+			if preview.Err == nil && !m.cleanSkipped[preview.Repository.RepoID] {
+				paths += len(preview.Paths)
+			}
+		}
+		lines = append(lines, "", fmt.Sprintf("%d path(s) in %d repo(s) will be removed", paths, len(targets)))
+	}
+	hints := "space: skip | enter: remove | esc: close"
+	lines = append(lines, "", m.styles.Help.Render(truncateString(hints, contentWidth)))
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestClean_PreviewsBeforeRemovingUntrackedFiles(t *testing.T) {
+	alpha := initBranchCreationRepo(t, "alpha")
+	beta := initBranchCreationRepo(t, "beta")
+	gamma := initBranchCreationRepo(t, "gamma")
+	require.NoError(t, os.WriteFile(filepath.Join(alpha.AbsPath, "out.bin"), []byte("artifact"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(gamma.AbsPath, "keep.txt"), []byte("draft"), 0o644))
+	for _, repo := range []*git.Repository{alpha, beta, gamma} {
+		repo.SetWorkStatusSilent(git.Queued)
+	}
+	m := &Model{repositories: []*git.Repository{alpha, beta, gamma}, styles: DefaultStyles(), width: 100, height: 30}
+
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	require.NotNil(t, cmd)
+	require.True(t, m.cleanActive)
+	m.Update(cmd())
+
+	view := m.renderCleanPreview()
+	require.Contains(t, view, "alpha: 1 path(s)")
+	require.Contains(t, view, "out.bin")
+	require.Contains(t, view, "beta: nothing to clean")
+	require.Contains(t, view, "2 path(s) in 2 repo(s) will be removed")
+	require.FileExists(t, filepath.Join(alpha.AbsPath, "out.bin"))
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	require.Contains(t, m.renderCleanPreview(), "gamma: 1 path(s) (skipped)")
+
+	_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	require.False(t, m.cleanActive)
+	require.IsType(t, repoActionResultMsg{}, cmd())
+	require.NoFileExists(t, filepath.Join(alpha.AbsPath, "out.bin"))
+	require.FileExists(t, filepath.Join(gamma.AbsPath, "keep.txt"))
+}
@@ -6,6 +6,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/thorstenhirsch/gitbatch/internal/command"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
 	"github.com/thorstenhirsch/gitbatch/internal/job"
 )
 
@@ -87,10 +88,19 @@ func (m *Model) openCommitPrompt() {
 	}
 	// Filter to only repos with local changes
 	var eligible []*git.Repository
+	var protected []string
 	for _, repo := range repos {
-		if repoHasLocalChanges(repo) {
-			eligible = append(eligible, repo)
+		if !repoHasLocalChanges(repo) {
+			continue
 		}
+		if rule, ok := command.ProtectedBranch(repo); ok && rule.Block {
+			protected = append(protected, repo.Name)
+			continue
+		}
+		eligible = append(eligible, repo)
+	}
+	if len(protected) > 0 {
+		m.notice = i18n.T("not committing on protected branches in %s", strings.Join(protected, ", "))
 	}
 	if len(eligible) == 0 {
 		return
@@ -102,6 +112,18 @@ func (m *Model) openCommitPrompt() {
 	m.commitDescBuffer = ""
 }
 
+// protectedCommitRepos names the repositories of the commit prompt that are
+// on a protected branch.
+func (m *Model) protectedCommitRepos() []string {
+	var names []string
+	for _, repo := range m.commitPromptRepos {
+		if _, ok := command.ProtectedBranch(repo); ok {
+			names = append(names, repo.Name)
+		}
+	}
+	return names
+}
+
 func (m *Model) dismissCommitPrompt() {
 	m.commitPromptActive = false
 	m.commitPromptRepos = nil
@@ -134,7 +156,7 @@ func (m *Model) submitCommit() tea.Cmd {
 			if repo == nil || repo.State == nil {
 				continue
 			}
-			repo.State.Message = "committing.."
+			repo.State.SetMessage(i18n.T("committing.."))
 			repo.SetWorkStatus(git.Pending)
 			j := &job.Job{
 				Repository: repo,
@@ -143,7 +165,7 @@ func (m *Model) submitCommit() tea.Cmd {
 			}
 			if err := j.Start(); err != nil {
 				repo.SetWorkStatus(git.Available)
-				repo.State.Message = "commit failed: " + err.Error()
+				repo.State.SetMessage(i18n.T("commit failed: %v", err))
 				continue
 			}
 		}
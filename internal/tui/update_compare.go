@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// compareResult holds the divergence of a repository's HEAD from the
+// comparison ref.
+type compareResult struct {
+	ahead  int
+	behind int
+	err    error
+}
+
+// compareResultsMsg delivers the results of a compare run keyed by RepoID.
+type compareResultsMsg struct {
+	ref     string
+	results map[string]compareResult
+}
+
+func (m *Model) handleComparePromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.comparePromptActive {
+		return false, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc":
+		m.dismissComparePrompt()
+		return true, nil
+	case "enter":
+		return true, m.submitComparePrompt()
+	case "backspace", "ctrl+h":
+		runes := []rune(m.compareRefBuffer)
+		if len(runes) > 0 {
+			m.compareRefBuffer = string(runes[:len(runes)-1])
+		}
+		return true, nil
+	case " ":
+		// Refs cannot contain spaces.
+		return true, nil
+	default:
+		if len(msg.Runes) > 0 {
+			m.compareRefBuffer += string(msg.Runes)
+		}
+		return true, nil
+	}
+}
+
+// openComparePrompt asks for the ref all repositories are compared against,
+// prefilled with the current one so enter re-runs the comparison.
+func (m *Model) openComparePrompt() {
+	m.comparePromptActive = true
+	m.compareRefBuffer = m.compareRef
+}
+
+func (m *Model) dismissComparePrompt() {
+	m.comparePromptActive = false
+	m.compareRefBuffer = ""
+}
+
+// submitComparePrompt starts comparing against the entered ref. An empty ref
+// returns to the upstream-based counts.
+func (m *Model) submitComparePrompt() tea.Cmd {
+	ref := strings.TrimSpace(m.compareRefBuffer)
+	m.dismissComparePrompt()
+	if ref == "" {
+		m.compareRef = ""
+		m.compareResults = nil
+		return nil
+	}
+	m.compareRef = ref
+	m.compareResults = make(map[string]compareResult)
+	return compareRepositoriesCmd(ref, filterRepositories(m.repositories))
+}
+
+// compareRepositoriesCmd counts ahead/behind against ref for every repository
+// using a small worker pool that respects the global git semaphore.
+func compareRepositoriesCmd(ref string, repos []*git.Repository) tea.Cmd {
+	if len(repos) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		var (
+			mu      sync.Mutex
+			wg      sync.WaitGroup
+			results = make(map[string]compareResult, len(repos))
+			work    = make(chan *git.Repository)
+		)
+		for range min(forceRefreshWorkers, len(repos)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for r := range work {
+					var res compareResult
+					if err := git.AcquireGitSemaphore(context.Background()); err != nil {
+						res.err = err
+					} else {
+						res.ahead, res.behind, res.err = r.CompareWithRef(ref)
+						git.ReleaseGitSemaphore()
+					}
+					mu.Lock()
+					results[r.RepoID] = res
+					mu.Unlock()
+				}
+			}()
+		}
+		for _, r := range repos {
+			work <- r
+		}
+		close(work)
+		wg.Wait()
+		return compareResultsMsg{ref: ref, results: results}
+	}
+}
+
+func (m *Model) applyCompareResults(msg compareResultsMsg) {
+	// Results of a comparison the user already replaced are dropped.
+	if msg.ref != m.compareRef {
+		return
+	}
+	m.compareResults = msg.results
+}
+
+// branchSyncSuffix returns the ahead/behind suffix shown next to a
+// repository's branch: against the comparison ref while one is set, against
+// the upstream otherwise.
+func (m *Model) branchSyncSuffix(r *git.Repository) string {
+	if m.compareRef == "" {
+		if r == nil || r.State == nil {
+			return ""
+		}
+		return syncSuffix(r.State.Branch)
+	}
+	if r == nil {
+		return ""
+	}
+	res, ok := m.compareResults[r.RepoID]
+	switch {
+	case !ok:
+		return " " + waitingSymbol
+	case res.err != nil:
+		return " ?"
+	}
+	var parts []string
+	if res.ahead > 0 {
+		parts = append(parts, fmt.Sprintf("%s%d", pushable, res.ahead))
+	}
+	if res.behind > 0 {
+		parts = append(parts, fmt.Sprintf("%s%d", pullable, res.behind))
+	}
+	if len(parts) == 0 {
+		return " ="
+	}
+	return " " + strings.Join(parts, " ")
+}
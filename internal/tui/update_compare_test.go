@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestComparePrompt_SubmitAndClear(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	model := Model{repositories: []*git.Repository{repo}}
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	require.True(t, model.comparePromptActive)
+	for _, r := range "origin/main" {
+		model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	_, cmd := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.False(t, model.comparePromptActive)
+	require.Equal(t, "origin/main", model.compareRef)
+	require.Equal(t, " "+waitingSymbol, model.branchSyncSuffix(repo))
+	require.NotNil(t, cmd)
+
+	model.Update(cmd())
+	require.Equal(t, " =", model.branchSyncSuffix(repo))
+	require.Equal(t, "main =", model.branchColumnContent(repo))
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	require.Equal(t, "origin/main", model.compareRefBuffer)
+	for range "origin/main" {
+		model.handleKeyPress(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	_, cmd = model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Nil(t, cmd)
+	require.Empty(t, model.compareRef)
+	require.Equal(t, "main", model.branchColumnContent(repo))
+}
+
+func TestBranchSyncSuffix_CompareResults(t *testing.T) {
+	repo := &git.Repository{RepoID: "a", State: &git.RepositoryState{Branch: &git.Branch{Name: "main"}}}
+	model := Model{compareRef: "release"}
+
+	model.applyCompareResults(compareResultsMsg{ref: "other", results: map[string]compareResult{"a": {ahead: 9}}})
+	require.Equal(t, " "+waitingSymbol, model.branchSyncSuffix(repo))
+
+	model.applyCompareResults(compareResultsMsg{ref: "release", results: map[string]compareResult{"a": {ahead: 2, behind: 3}}})
+	require.Equal(t, " "+pushable+"2 "+pullable+"3", model.branchSyncSuffix(repo))
+
+	model.compareResults["a"] = compareResult{err: errors.New("unknown revision")}
+	require.Equal(t, " ?", model.branchSyncSuffix(repo))
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// confirmDestructive runs a destructive action in repos right away, or asks
+// for a typed confirmation first when any of them is configured to need
+// one: the name of the repository, or the number of repositories when it
+// affects several of them.
+func (m *Model) confirmDestructive(action string, repos []*git.Repository, run func() tea.Cmd) tea.Cmd {
+	var guarded []*git.Repository
+	for _, r := range filterRepositories(repos) {
+		if command.RequiresConfirmation(r) {
+			guarded = append(guarded, r)
+		}
+	}
+	if len(guarded) == 0 {
+		return run()
+	}
+	expected := guarded[0].Name
+	if len(guarded) > 1 {
+		expected = strconv.Itoa(len(guarded))
+	}
+	m.activeConfirmPrompt = &confirmPrompt{action: action, repos: guarded, expected: expected, run: run}
+	return nil
+}
+
+func (m *Model) handleConfirmPromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	prompt := m.activeConfirmPrompt
+	if prompt == nil {
+		return false, nil
+	}
+	if msg.Paste {
+		// The confirmation has to be typed.
+		return true, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc":
+		m.activeConfirmPrompt = nil
+		m.notice = i18n.T("cancelled: %s", prompt.action)
+		return true, nil
+	case "enter":
+		if prompt.buffer != prompt.expected {
+			m.notice = i18n.T("type %s to confirm", prompt.expected)
+			return true, nil
+		}
+		m.activeConfirmPrompt = nil
+		return true, prompt.run()
+	case "backspace", "ctrl+h":
+		runes := []rune(prompt.buffer)
+		if len(runes) > 0 {
+			prompt.buffer = string(runes[:len(runes)-1])
+		}
+		return true, nil
+	default:
+		if len(msg.Runes) > 0 {
+			prompt.buffer += string(msg.Runes)
+		}
+		return true, nil
+	}
+}
+
+func (m *Model) renderConfirmPrompt() string {
+	prompt := m.activeConfirmPrompt
+	if prompt == nil {
+		return ""
+	}
+	panelWidth := 60
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+
+	lines := []string{
+		m.styles.PanelTitle.Render(truncateString(i18n.T("Confirm: %s", prompt.action), contentWidth)),
+		"",
+		truncateString(i18n.T("Repositories: %s", repositoryNames(prompt.repos)), contentWidth),
+		"",
+		truncateString(i18n.T("Type %s to confirm:", prompt.expected), contentWidth),
+		"> " + truncateString(prompt.buffer, contentWidth-2),
+		"",
+		i18n.T("enter: confirm | esc: cancel"),
+	}
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestConfirmDestructive_NeedsTheTypedNameUnderConfirmPaths(t *testing.T) {
+	root := t.TempDir()
+	command.SetConfirmPaths([]string{filepath.Join(root, "prod", "**")})
+	t.Cleanup(func() { command.SetConfirmPaths(nil) })
+
+	config := testRepoWithBranch("config", "main")
+	config.AbsPath = filepath.Join(root, "prod", "config")
+	app := testRepoWithBranch("app", "main")
+	app.AbsPath = filepath.Join(root, "app")
+	m := &Model{repositories: []*git.Repository{app, config}, styles: DefaultStyles(), width: 100, height: 30}
+
+	runs := 0
+	run := func() tea.Cmd {
+		runs++
+		return nil
+	}
+	m.confirmDestructive("delete branch old", []*git.Repository{app}, run)
+	require.Equal(t, 1, runs, "other repositories need no confirmation")
+	require.Nil(t, m.activeConfirmPrompt)
+
+	m.confirmDestructive("delete branch old", []*git.Repository{app, config}, run)
+	require.NotNil(t, m.activeConfirmPrompt)
+	require.Equal(t, 1, runs)
+	view := ansi.Strip(m.renderConfirmPrompt())
+	assert.Contains(t, view, "Confirm: delete branch old")
+	assert.Contains(t, view, "Type config to confirm:")
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("app")})
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, 1, runs, "a wrong name does not confirm")
+	assert.Equal(t, "type config to confirm", m.notice)
+
+	for range 3 {
+		m.handleKeyPress(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("config"), Paste: true})
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, 1, runs, "the confirmation cannot be pasted")
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("config")})
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, 2, runs)
+	require.Nil(t, m.activeConfirmPrompt)
+
+	other := testRepoWithBranch("other", "main")
+	other.AbsPath = filepath.Join(root, "prod", "other")
+	m.confirmDestructive("force-push", []*git.Repository{config, other}, run)
+	require.Equal(t, "2", m.activeConfirmPrompt.expected, "several repositories are confirmed by their number")
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	require.Nil(t, m.activeConfirmPrompt)
+	require.Equal(t, 2, runs)
+}
+
+func TestConfirmDestructive_GuardsForkSyncRebaseAndPatch(t *testing.T) {
+	root := t.TempDir()
+	command.SetConfirmPaths([]string{filepath.Join(root, "prod", "**")})
+	t.Cleanup(func() { command.SetConfirmPaths(nil) })
+
+	repo := testRepoWithBranch("config", "main")
+	repo.AbsPath = filepath.Join(root, "prod", "config")
+	repo.State.Remote = &git.Remote{Name: "origin"}
+	repo.Remotes = []*git.Remote{{Name: "origin"}, {Name: "upstream"}}
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 100, height: 30}
+	repos := []*git.Repository{repo}
+
+	require.Nil(t, m.syncForksCmd(repos))
+	require.NotNil(t, m.activeConfirmPrompt)
+	assert.Equal(t, "sync fork and push to origin", m.activeConfirmPrompt.action)
+	assert.NotEqual(t, git.Pending, repo.WorkStatus(), "nothing runs before the confirmation")
+
+	m.activeConfirmPrompt = nil
+	require.Nil(t, m.rebaseOnDefaultCmd(repos))
+	require.NotNil(t, m.activeConfirmPrompt)
+	assert.Equal(t, "rebase onto the default branch", m.activeConfirmPrompt.action)
+
+	m.activeConfirmPrompt = nil
+	m.openPatchPrompt(repos)
+	require.Nil(t, m.submitPatchPrompt())
+	require.NotNil(t, m.activeConfirmPrompt)
+	assert.Equal(t, "apply a patch", m.activeConfirmPrompt.action)
+	assert.False(t, m.patchActive)
+}
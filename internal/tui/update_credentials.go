@@ -6,6 +6,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/thorstenhirsch/gitbatch/internal/command"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
 	"github.com/thorstenhirsch/gitbatch/internal/job"
 )
 
@@ -14,6 +15,12 @@ func (m *Model) handleCredentialPromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
 		return false, nil
 	}
 
+	if msg.Paste {
+		m.credentialError = ""
+		m.credentialInputBuffer += sanitizeCredentialPaste(string(msg.Runes))
+		return true, nil
+	}
+
 	key := msg.String()
 	switch key {
 	case "ctrl+c":
@@ -26,7 +33,7 @@ func (m *Model) handleCredentialPromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
 		return true, cmd
 	case "tab", "shift+tab":
 		prompt := m.activeCredentialPrompt
-		if prompt == nil {
+		if prompt == nil || prompt.passphrase {
 			return true, nil
 		}
 		switch m.credentialInputField {
@@ -40,6 +47,21 @@ func (m *Model) handleCredentialPromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
 			m.credentialInputBuffer = prompt.username
 		}
 		return true, nil
+	case "ctrl+r":
+		m.credentialReveal = !m.credentialReveal
+		return true, nil
+	case "ctrl+o":
+		if guidance, ok := m.activeCredentialGuidance(); ok && guidance.tokenURL != "" {
+			return true, openURLCmd(guidance.tokenURL)
+		}
+		return true, nil
+	case "ctrl+u":
+		m.credentialInputBuffer = ""
+		return true, nil
+	case "ctrl+v":
+		// Terminals deliver the clipboard as a bracketed paste; the raw
+		// control character itself must not end up in the secret.
+		return true, nil
 	case "backspace", "ctrl+h":
 		m.backspaceCredentialInput()
 		return true, nil
@@ -48,12 +70,24 @@ func (m *Model) handleCredentialPromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
 		return true, nil
 	default:
 		if len(msg.Runes) > 0 {
+			m.credentialError = ""
 			m.credentialInputBuffer += string(msg.Runes)
 		}
 		return true, nil
 	}
 }
 
+// sanitizeCredentialPaste drops line breaks and other control characters that
+// commonly trail a copied token or password.
+func sanitizeCredentialPaste(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, text)
+}
+
 func (m *Model) backspaceCredentialInput() {
 	runes := []rune(m.credentialInputBuffer)
 	if len(runes) == 0 {
@@ -70,11 +104,22 @@ func (m *Model) submitCredentialInput() tea.Cmd {
 	switch m.credentialInputField {
 	case credentialFieldUsername:
 		prompt.username = strings.TrimSpace(m.credentialInputBuffer)
+		if prompt.username == "" {
+			m.credentialError = "username must not be empty"
+			return nil
+		}
+		m.credentialError = ""
 		m.credentialInputField = credentialFieldPassword
 		m.credentialInputBuffer = prompt.password
 		return nil
 	case credentialFieldPassword:
 		prompt.password = m.credentialInputBuffer
+		if !prompt.passphrase && strings.TrimSpace(prompt.username) == "" {
+			m.credentialError = "username must not be empty"
+			m.credentialInputField = credentialFieldUsername
+			m.credentialInputBuffer = ""
+			return nil
+		}
 		cmd := m.retryCredentialPrompt(prompt)
 		m.dismissCredentialPrompt()
 		return cmd
@@ -85,9 +130,15 @@ func (m *Model) submitCredentialInput() tea.Cmd {
 
 func (m *Model) cancelCredentialPrompt() {
 	if m.activeCredentialPrompt != nil && m.activeCredentialPrompt.repo != nil {
-		m.activeCredentialPrompt.repo.SetWorkStatus(git.Fail)
-		if m.activeCredentialPrompt.repo.State != nil {
-			m.activeCredentialPrompt.repo.State.Message = "credentials prompt dismissed"
+		repo := m.activeCredentialPrompt.repo
+		repo.SetWorkStatus(git.Fail)
+		if repo.State != nil {
+			repo.State.SetMessage(i18n.T("credentials prompt dismissed"))
+		}
+		if host := repositoryHost(repo); m.credentialPausedHosts[strings.ToLower(host)] {
+			if n := command.DropHeldCommands(host); n > 0 {
+				m.notice = i18n.T("cancelled %d jobs waiting for credentials for %s", n, host)
+			}
 		}
 	}
 	m.dismissCredentialPrompt()
@@ -97,6 +148,8 @@ func (m *Model) dismissCredentialPrompt() {
 	m.activeCredentialPrompt = nil
 	m.credentialInputBuffer = ""
 	m.credentialInputField = credentialFieldUsername
+	m.credentialReveal = false
+	m.credentialError = ""
 	m.advanceCredentialPrompt()
 }
 
@@ -125,19 +178,141 @@ func (m *Model) openCredentialDialog(repo *git.Repository) {
 	}
 
 	prompt := &credentialPrompt{
-		repo: repo,
-		job:  existingJob,
+		repo:       repo,
+		job:        existingJob,
+		passphrase: git.IsSSHURL(repositoryURL(repo)),
+	}
+	if creds, ok := command.RememberedCredentials(repositoryHost(repo)); ok {
+		prompt.username = creds.User
 	}
 
 	if m.activeCredentialPrompt == nil {
 		m.activeCredentialPrompt = prompt
-		m.credentialInputField = credentialFieldUsername
-		m.credentialInputBuffer = ""
+		m.credentialInputField = prompt.firstField()
+		m.credentialInputBuffer = prompt.username
+		if prompt.passphrase {
+			m.credentialInputBuffer = ""
+		}
 	} else {
 		m.credentialPromptQueue = append(m.credentialPromptQueue, prompt)
 	}
 }
 
+// retryWithRememberedCredentials re-runs the failed job of a repository with
+// the credentials remembered for its host instead of prompting again. Every
+// repository gets a single automatic attempt so wrong credentials still end
+// up in the prompt.
+func (m *Model) retryWithRememberedCredentials(repo *git.Repository) (tea.Cmd, bool) {
+	if repo == nil || m.credentialAutoRetried[repo.RepoID] {
+		return nil, false
+	}
+	creds, ok := command.RememberedCredentials(repositoryHost(repo))
+	if !ok {
+		return nil, false
+	}
+	if m.credentialAutoRetried == nil {
+		m.credentialAutoRetried = make(map[string]bool)
+	}
+	m.credentialAutoRetried[repo.RepoID] = true
+	prompt := &credentialPrompt{
+		repo: repo,
+		job: &job.Job{
+			JobType:    job.FetchJob,
+			Repository: repo,
+			Options: &command.FetchOptions{
+				RemoteName: defaultRemoteName(repo),
+				Timeout:    command.DefaultFetchTimeout,
+			},
+		},
+		username: creds.User,
+		password: creds.Password,
+	}
+	return m.retryCredentialPrompt(prompt), true
+}
+
+// applyHostPauses asks once for the credentials of each host whose jobs wait
+// for them after one failed to authenticate. Once they work and the held jobs
+// run again, the repositories that failed on the host before it was paused
+// are retried with them.
+func (m *Model) applyHostPauses() tea.Cmd {
+	paused := make(map[string]bool)
+	for _, pause := range command.PausedHosts() {
+		paused[pause.Host] = true
+		if m.credentialPausedHosts[pause.Host] {
+			continue
+		}
+		if m.credentialPausedHosts == nil {
+			m.credentialPausedHosts = make(map[string]bool)
+		}
+		m.credentialPausedHosts[pause.Host] = true
+		if !m.hasCredentialPrompt(pause.Repository) {
+			m.openCredentialDialog(pause.Repository)
+		}
+	}
+	var cmds []tea.Cmd
+	for host := range m.credentialPausedHosts {
+		if paused[host] {
+			continue
+		}
+		delete(m.credentialPausedHosts, host)
+		for _, repo := range m.repositories {
+			if repo.State == nil || !repo.State.RequiresCredentials || repo.WorkStatus() != git.Fail || strings.ToLower(repositoryHost(repo)) != host {
+				continue
+			}
+			if cmd, ok := m.retryWithRememberedCredentials(repo); ok {
+				cmds = append(cmds, cmd)
+			}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// hasCredentialPrompt reports whether the credentials of repo are asked for
+// already.
+func (m *Model) hasCredentialPrompt(repo *git.Repository) bool {
+	if m.activeCredentialPrompt != nil && m.activeCredentialPrompt.repo == repo {
+		return true
+	}
+	for _, prompt := range m.credentialPromptQueue {
+		if prompt != nil && prompt.repo == repo {
+			return true
+		}
+	}
+	return false
+}
+
+// heldJobs returns how many jobs wait for the credentials of host.
+func heldJobs(host string) int {
+	for _, pause := range command.PausedHosts() {
+		if pause.Host == strings.ToLower(host) {
+			return pause.Waiting
+		}
+	}
+	return 0
+}
+
+// repositoryURL returns the first URL of the repository's selected remote.
+func repositoryURL(repo *git.Repository) string {
+	if repo == nil || repo.State == nil || repo.State.Remote == nil || len(repo.State.Remote.URL) == 0 {
+		return ""
+	}
+	return repo.State.Remote.URL[0]
+}
+
+// repositoryHost returns the host of the repository's selected remote.
+func repositoryHost(repo *git.Repository) string {
+	return git.RemoteHost(repositoryURL(repo))
+}
+
+// firstField returns the field a prompt starts in. Ssh remotes only need the
+// key passphrase, which lives in the password field.
+func (p *credentialPrompt) firstField() credentialField {
+	if p != nil && p.passphrase {
+		return credentialFieldPassword
+	}
+	return credentialFieldUsername
+}
+
 func (m *Model) advanceCredentialPrompt() {
 	if len(m.credentialPromptQueue) == 0 {
 		m.activeCredentialPrompt = nil
@@ -148,8 +323,8 @@ func (m *Model) advanceCredentialPrompt() {
 	next := m.credentialPromptQueue[0]
 	m.credentialPromptQueue = m.credentialPromptQueue[1:]
 	m.activeCredentialPrompt = next
-	m.credentialInputField = credentialFieldUsername
-	if next != nil {
+	m.credentialInputField = next.firstField()
+	if next != nil && !next.passphrase {
 		m.credentialInputBuffer = next.username
 	} else {
 		m.credentialInputBuffer = ""
@@ -163,29 +338,35 @@ func (m *Model) retryCredentialPrompt(prompt *credentialPrompt) tea.Cmd {
 	repo := prompt.repo
 	repo.SetWorkStatus(git.Pending)
 	if repo.State != nil {
-		repo.State.Message = "retrying with credentials"
+		repo.State.SetMessage(i18n.T("retrying with credentials"))
 	}
 	creds := &git.Credentials{
 		User:     strings.TrimSpace(prompt.username),
 		Password: prompt.password,
 	}
+	if prompt.passphrase {
+		creds = &git.Credentials{Passphrase: prompt.password}
+	}
 	retryJob := cloneJobWithCredentials(prompt.job, creds)
 	if retryJob == nil {
 		repo.SetWorkStatus(git.Fail)
 		if repo.State != nil {
-			repo.State.Message = "unable to retry with credentials"
+			repo.State.SetMessage(i18n.T("unable to retry with credentials"))
 		}
 		return nil
 	}
 	retryJob.Repository = repo
+	if m.credentialRetries == nil {
+		m.credentialRetries = make(map[string]*git.Credentials)
+	}
+	m.credentialRetries[repo.RepoID] = creds
 	if err := retryJob.Start(); err != nil {
 		repo.SetWorkStatus(git.Fail)
 		if repo.State != nil {
-			repo.State.Message = "failed to start credential retry"
+			repo.State.SetMessage(i18n.T("failed to start credential retry"))
 		}
 		return func() tea.Msg { return errMsg{err: err} }
 	}
-	repo.SetWorkStatus(git.Queued)
 	m.jobsRunning = true
 	return m.ensureTicking()
 }
@@ -259,6 +440,39 @@ func cloneJobWithCredentials(original *job.Job, creds *git.Credentials) *job.Job
 		default:
 			clone.Options = &command.PullOptions{Credentials: creds}
 		}
+	case job.PushJob:
+		switch cfg := original.Options.(type) {
+		case *command.PushOptions:
+			copyCfg := *cfg
+			copyCfg.Credentials = creds
+			clone.Options = &copyCfg
+		case command.PushOptions:
+			copyCfg := cfg
+			copyCfg.Credentials = creds
+			clone.Options = &copyCfg
+		case *job.PushJobConfig:
+			copyCfg := *cfg
+			if copyCfg.Options != nil {
+				optsCopy := *copyCfg.Options
+				optsCopy.Credentials = creds
+				copyCfg.Options = &optsCopy
+			} else {
+				copyCfg.Options = &command.PushOptions{Credentials: creds}
+			}
+			clone.Options = &copyCfg
+		case job.PushJobConfig:
+			copyCfg := cfg
+			if copyCfg.Options != nil {
+				optsCopy := *copyCfg.Options
+				optsCopy.Credentials = creds
+				copyCfg.Options = &optsCopy
+			} else {
+				copyCfg.Options = &command.PushOptions{Credentials: creds}
+			}
+			clone.Options = &copyCfg
+		default:
+			clone.Options = &command.PushOptions{Credentials: creds}
+		}
 	default:
 		return nil
 	}
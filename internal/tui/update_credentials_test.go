@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/job"
+)
+
+func newCredentialTestModel() *Model {
+	repo := &git.Repository{Name: "alpha", State: &git.RepositoryState{}}
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles()}
+	m.openCredentialDialog(repo)
+	return m
+}
+
+func TestCredentialPrompt_PasteStripsLineBreaks(t *testing.T) {
+	m := newCredentialTestModel()
+	m.credentialInputField = credentialFieldPassword
+
+	handled, _ := m.handleCredentialPromptKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s3cr3t\r\n"), Paste: true})
+	require.True(t, handled)
+	require.Equal(t, "s3cr3t", m.credentialInputBuffer)
+
+	m.handleCredentialPromptKey(tea.KeyMsg{Type: tea.KeyCtrlV})
+	require.Equal(t, "s3cr3t", m.credentialInputBuffer)
+}
+
+func TestCredentialPrompt_RejectsEmptyUsername(t *testing.T) {
+	m := newCredentialTestModel()
+
+	_, cmd := m.handleCredentialPromptKey(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Nil(t, cmd)
+	require.Equal(t, credentialFieldUsername, m.credentialInputField)
+	require.Equal(t, "username must not be empty", m.credentialError)
+
+	m.handleCredentialPromptKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("bob")})
+	require.Empty(t, m.credentialError)
+	m.handleCredentialPromptKey(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, credentialFieldPassword, m.credentialInputField)
+	require.Equal(t, "bob", m.activeCredentialPrompt.username)
+}
+
+func TestCredentialPrompt_CtrlRRevealsPassword(t *testing.T) {
+	m := newCredentialTestModel()
+	m.width = 80
+	m.credentialInputField = credentialFieldPassword
+	m.credentialInputBuffer = "hunter2"
+
+	require.NotContains(t, m.renderCredentialPrompt(), "hunter2")
+	m.handleCredentialPromptKey(tea.KeyMsg{Type: tea.KeyCtrlR})
+	require.True(t, m.credentialReveal)
+	require.Contains(t, m.renderCredentialPrompt(), "hunter2")
+
+	m.cancelCredentialPrompt()
+	require.False(t, m.credentialReveal)
+}
+
+func TestCredentialPrompt_PrefillsRememberedUsername(t *testing.T) {
+	command.RememberCredentials("prefill.example.invalid", &git.Credentials{User: "carol", Password: "pw"})
+	repo := &git.Repository{Name: "alpha", State: &git.RepositoryState{
+		Remote: &git.Remote{Name: "origin", URL: []string{"https://prefill.example.invalid/team/alpha.git"}},
+	}}
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles()}
+
+	m.openCredentialDialog(repo)
+	require.Equal(t, "carol", m.credentialInputBuffer)
+	require.Equal(t, "carol", m.activeCredentialPrompt.username)
+}
+
+func TestCredentialPrompt_ShowsGuidanceAfterRepeatedFailures(t *testing.T) {
+	repo := &git.Repository{RepoID: "alpha", Name: "alpha", State: &git.RepositoryState{
+		Remote: &git.Remote{Name: "origin", URL: []string{"https://github.com/org/alpha.git"}},
+	}}
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 100}
+	creds := &git.Credentials{User: "dave", Password: "password"}
+
+	for i := 0; i < credentialGuidanceThreshold; i++ {
+		_, ok := m.activeCredentialGuidance()
+		require.False(t, ok)
+		m.credentialRetries = map[string]*git.Credentials{repo.RepoID: creds}
+		m.noteFailedCredentialRetry(repo)
+		m.dismissCredentialPrompt()
+		m.openCredentialDialog(repo)
+	}
+
+	guidance, ok := m.activeCredentialGuidance()
+	require.True(t, ok)
+	require.Equal(t, "https://github.com/settings/tokens", guidance.tokenURL)
+	require.Contains(t, m.renderCredentialPrompt(), "personal access token")
+}
+
+func TestGuidanceForHost(t *testing.T) {
+	require.Contains(t, guidanceForHost("gitlab.example.com").tokenURL, "https://gitlab.example.com/")
+	require.Empty(t, guidanceForHost("git.example.com").tokenURL)
+}
+
+func TestCredentialPrompt_SSHRemoteAsksForPassphrase(t *testing.T) {
+	repo := &git.Repository{Name: "alpha", State: &git.RepositoryState{
+		Remote: &git.Remote{Name: "origin", URL: []string{"git@github.com:org/alpha.git"}},
+	}}
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 80}
+
+	m.openCredentialDialog(repo)
+	require.True(t, m.activeCredentialPrompt.passphrase)
+	require.Equal(t, credentialFieldPassword, m.credentialInputField)
+
+	m.handleCredentialPromptKey(tea.KeyMsg{Type: tea.KeyTab})
+	require.Equal(t, credentialFieldPassword, m.credentialInputField)
+
+	view := m.renderCredentialPrompt()
+	require.Contains(t, view, "Passphrase:")
+	require.NotContains(t, view, "Username:")
+}
+
+func TestHostPauseAsksForTheCredentialsOnce(t *testing.T) {
+	t.Cleanup(func() { command.DropHeldCommands("git.example.com") })
+	remote := func(name string) *git.Repository {
+		return &git.Repository{RepoID: name, Name: name, State: &git.RepositoryState{
+			Remote: &git.Remote{Name: "origin", URL: []string{"https://git.example.com/org/" + name + ".git"}},
+		}}
+	}
+	api, web := remote("api"), remote("web")
+	m := &Model{repositories: []*git.Repository{api, web}, styles: DefaultStyles()}
+
+	command.EvaluateRepositoryState(api, command.OperationOutcome{
+		Operation: command.OperationFetch,
+		Err:       errors.New("fatal: Authentication failed for 'https://git.example.com/org/api.git/'"),
+	})
+	m.applyHostPauses()
+	require.NotNil(t, m.activeCredentialPrompt)
+	require.Equal(t, api, m.activeCredentialPrompt.repo)
+	m.applyHostPauses()
+	require.Empty(t, m.credentialPromptQueue, "the host is asked for once")
+
+	m.cancelCredentialPrompt()
+	require.Empty(t, command.PausedHosts(), "dismissing the prompt ends the pause")
+	m.applyHostPauses()
+	require.Empty(t, m.credentialPausedHosts)
+}
+
+func TestCloneJobWithCredentials_RetriesAPush(t *testing.T) {
+	creds := &git.Credentials{User: "alice", Password: "s3cr3t"}
+	original := &job.Job{JobType: job.PushJob, Options: &job.PushJobConfig{Options: &command.PushOptions{RemoteName: "origin", Force: true}, SuppressSuccess: true}}
+
+	retry := cloneJobWithCredentials(original, creds)
+	require.NotNil(t, retry)
+	cfg, ok := retry.Options.(*job.PushJobConfig)
+	require.True(t, ok)
+	require.True(t, cfg.SuppressSuccess)
+	require.Equal(t, &command.PushOptions{RemoteName: "origin", Force: true, Credentials: creds}, cfg.Options)
+	require.Nil(t, original.Options.(*job.PushJobConfig).Options.Credentials, "the failed job is left alone")
+}
@@ -0,0 +1,208 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// diffLoadedMsg carries the diff the overlay waits for.
+type diffLoadedMsg struct {
+	title string
+	diff  *command.Diff
+	err   error
+}
+
+// openDiff shows the diff overlay and loads the diff in the background.
+func (m *Model) openDiff(title string, load func(ctx context.Context, width int) (*command.Diff, error)) tea.Cmd {
+	m.diffActive = true
+	m.diffTitle = title
+	m.diffLines = nil
+	m.diffOffset = 0
+	width := m.diffContentWidth()
+	return func() tea.Msg {
+		diff, err := load(context.Background(), width)
+		return diffLoadedMsg{title: title, diff: diff, err: err}
+	}
+}
+
+// openFileDiff shows the changes of a file listed in the status panel.
+func (m *Model) openFileDiff(r *git.Repository, file *git.File) tea.Cmd {
+	if r == nil || file == nil {
+		return nil
+	}
+	return m.openDiff(file.Name, func(ctx context.Context, width int) (*command.Diff, error) {
+		return command.FileDiff(ctx, r, file, width)
+	})
+}
+
+// openStashDiff shows the changes of a stash before it is popped or dropped;
+// Enter goes on with the action. Of several tagged repositories the
+// selected one is shown, or the first.
+func (m *Model) openStashDiff(action stashActionType, repos []*git.Repository, item stashPanelItem) tea.Cmd {
+	if len(repos) == 0 {
+		return nil
+	}
+	r := repos[0]
+	if current := m.currentRepository(); slices.Contains(repos, current) {
+		r = current
+	}
+	stashRef := fmt.Sprintf("stash@{%d}", findStashID(r, item.Description))
+	cmd := m.openDiff(stashRef+" "+item.Description, func(ctx context.Context, width int) (*command.Diff, error) {
+		return command.StashDiff(ctx, r, stashRef, width)
+	})
+	m.diffConfirm = func() tea.Cmd {
+		m.sidePanel = NonePanel
+		return m.confirmStashAction(action, repos, item)
+	}
+	m.diffConfirmLabel = i18n.T("pop the stash")
+	if action == stashActionDrop {
+		m.diffConfirmLabel = i18n.T("drop the stash")
+	}
+	return cmd
+}
+
+// openCommitDiff shows the commit of a reflog entry with its changes.
+func (m *Model) openCommitDiff(r *git.Repository, entry *git.ReflogEntry) tea.Cmd {
+	if r == nil || entry == nil {
+		return nil
+	}
+	return m.openDiff(entry.Selector+" "+entry.Subject, func(ctx context.Context, width int) (*command.Diff, error) {
+		return command.CommitDiff(ctx, r, entry.Hash, width)
+	})
+}
+
+func (m *Model) handleDiffLoaded(msg diffLoadedMsg) (tea.Model, tea.Cmd) {
+	if !m.diffActive || m.diffTitle != msg.title {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.closeDiff()
+		m.err = msg.err
+		return m, nil
+	}
+	text := strings.ReplaceAll(msg.diff.Text, "\t", "    ")
+	m.diffLines = strings.Split(text, "\n")
+	if !msg.diff.Filtered {
+		for i, line := range m.diffLines {
+			m.diffLines[i] = m.colorDiffLine(line)
+		}
+	}
+	return m, nil
+}
+
+// colorDiffLine colors a line of a plain diff the way git does.
+func (m *Model) colorDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		return m.styles.PanelTitle.Render(line)
+	case strings.HasPrefix(line, "+"):
+		return worktreeDiffAdditionStyle.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return worktreeDiffDeletionStyle.Render(line)
+	case strings.HasPrefix(line, "@@"):
+		return m.styles.BranchInfo.Render(line)
+	}
+	return line
+}
+
+// handleDiffKey scrolls the diff overlay. While it is shown it takes all
+// keys.
+func (m *Model) handleDiffKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.diffActive {
+		return false, nil
+	}
+	viewport := m.diffViewportSize()
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc", "q", "d":
+		m.closeDiff()
+	case "enter":
+		if confirm := m.diffConfirm; confirm != nil {
+			m.closeDiff()
+			return true, confirm()
+		}
+	case "up", "k":
+		m.diffOffset--
+	case "down", "j":
+		m.diffOffset++
+	case "pgup", "ctrl+u", "b":
+		m.diffOffset -= viewport
+	case "pgdown", "ctrl+f", "ctrl+d", " ":
+		m.diffOffset += viewport
+	case "g", "home":
+		m.diffOffset = 0
+	case "G", "end":
+		m.diffOffset = len(m.diffLines)
+	}
+	m.diffOffset = clampHelpOffset(m.diffOffset, len(m.diffLines), viewport)
+	return true, nil
+}
+
+func (m *Model) closeDiff() {
+	m.diffActive = false
+	m.diffTitle = ""
+	m.diffLines = nil
+	m.diffOffset = 0
+	m.diffConfirm = nil
+	m.diffConfirmLabel = ""
+}
+
+// diffPanelWidth leaves a margin around the diff overlay, which otherwise
+// takes the whole screen.
+func (m *Model) diffPanelWidth() int {
+	if m.width <= 0 {
+		return 80
+	}
+	return max(m.width-2, 20)
+}
+
+func (m *Model) diffContentWidth() int {
+	return m.diffPanelWidth() - 4
+}
+
+// diffViewportSize is the number of diff lines that fit next to the title
+// and the footer.
+func (m *Model) diffViewportSize() int {
+	if m.height <= 0 {
+		return 20
+	}
+	return max(m.height-8, 5)
+}
+
+// renderDiff renders the diff overlay.
+func (m *Model) renderDiff() string {
+	contentWidth := m.diffContentWidth()
+	lines := []string{m.styles.PanelTitle.Render(truncateString(m.diffTitle, contentWidth)), ""}
+
+	viewport := m.diffViewportSize()
+	offset := clampHelpOffset(m.diffOffset, len(m.diffLines), viewport)
+	switch {
+	case m.diffLines == nil:
+		lines = append(lines, i18n.T("loading diff..."))
+	case len(m.diffLines) == 1 && m.diffLines[0] == "":
+		lines = append(lines, i18n.T("No changes."))
+	}
+	for _, line := range m.diffLines[offset:min(offset+viewport, len(m.diffLines))] {
+		lines = append(lines, ansi.Truncate(line, contentWidth, ""))
+	}
+
+	footer := i18n.T("esc: close")
+	if m.diffConfirm != nil {
+		footer = i18n.T("enter: %s | esc: back", m.diffConfirmLabel)
+	}
+	if len(m.diffLines) > viewport {
+		footer = i18n.T("↑/↓: scroll (%d/%d) | %s", min(offset+viewport, len(m.diffLines)), len(m.diffLines), footer)
+	}
+	lines = append(lines, "", m.styles.Help.Render(footer))
+	return m.styles.Panel.Width(m.diffPanelWidth()).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,199 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// forecastResultsMsg delivers the dry-run results of the queued repositories.
+type forecastResultsMsg struct {
+	forecasts []*command.MergeForecast
+}
+
+// openForecast predicts the outcome of the queued pull or merge batch and
+// shows it in the forecast view.
+func (m *Model) openForecast() tea.Cmd {
+	if m.mode.ID != PullMode && m.mode.ID != MergeMode {
+		m.notice = "forecast is available in pull and merge mode"
+		return nil
+	}
+	var queued []*git.Repository
+	for _, r := range m.taggedRepositories() {
+		// Repositories with their own mode are not part of the forecast.
+		if m.jobMode(r).ID == m.mode.ID {
+			queued = append(queued, r)
+		}
+	}
+	if len(queued) == 0 {
+		m.notice = "queue repositories with space to forecast the batch"
+		return nil
+	}
+	m.forecastActive = true
+	m.forecastRunning = true
+	m.forecastCursor = 0
+	m.forecasts = nil
+	return forecastCmd(queued, m.mode.ID == PullMode)
+}
+
+func (m *Model) dismissForecast() {
+	m.forecastActive = false
+	m.forecastRunning = false
+	m.forecastCursor = 0
+	m.forecasts = nil
+}
+
+func (m *Model) handleForecastKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.forecastActive {
+		return false, nil
+	}
+	count := len(m.forecasts)
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc", "F", "q":
+		m.dismissForecast()
+	case "up", "k":
+		if count > 0 {
+			wrapCursor(&m.forecastCursor, count, -1)
+		}
+	case "down", "j":
+		if count > 0 {
+			wrapCursor(&m.forecastCursor, count, 1)
+		}
+	case " ", "space":
+		if count > 0 {
+			m.toggleForecastQueue(m.forecasts[clampIndex(m.forecastCursor, count)].Repository)
+		}
+	case "u":
+		removed := 0
+		for _, forecast := range m.forecasts {
+			if forecast.WillFail() && forecast.Repository.WorkStatus() == git.Queued {
+				m.removeFromQueue(forecast.Repository)
+				removed++
+			}
+		}
+		m.notice = fmt.Sprintf("unqueued %d repo(s) that would fail", removed)
+	case "enter":
+		m.dismissForecast()
+		return true, m.startQueueLimit(0)
+	}
+	return true, nil
+}
+
+func (m *Model) toggleForecastQueue(r *git.Repository) {
+	if r.WorkStatus() == git.Queued {
+		m.removeFromQueue(r)
+		return
+	}
+	m.addToQueue(r)
+}
+
+// applyForecastResults stores the forecasts, failures first.
+func (m *Model) applyForecastResults(msg forecastResultsMsg) {
+	if !m.forecastActive {
+		return
+	}
+	m.forecastRunning = false
+	m.forecasts = msg.forecasts
+	m.forecastCursor = clampIndex(m.forecastCursor, len(msg.forecasts))
+}
+
+// forecastCmd runs the dry runs concurrently, bounded by the git semaphore.
+func forecastCmd(repos []*git.Repository, ffOnly bool) tea.Cmd {
+	return func() tea.Msg {
+		var (
+			mu        sync.Mutex
+			wg        sync.WaitGroup
+			forecasts []*command.MergeForecast
+			work      = make(chan *git.Repository)
+		)
+		for range min(forceRefreshWorkers, max(len(repos), 1)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for r := range work {
+					if err := git.AcquireGitSemaphore(context.Background()); err != nil {
+						continue
+					}
+					forecast := command.ForecastMerge(context.Background(), r, ffOnly)
+					git.ReleaseGitSemaphore()
+					mu.Lock()
+					forecasts = append(forecasts, forecast)
+					mu.Unlock()
+				}
+			}()
+		}
+		for _, r := range repos {
+			if r != nil {
+				work <- r
+			}
+		}
+		close(work)
+		wg.Wait()
+		sort.Slice(forecasts, func(i, j int) bool {
+			if a, b := forecasts[i].WillFail(), forecasts[j].WillFail(); a != b {
+				return a
+			}
+			return forecasts[i].Repository.Name < forecasts[j].Repository.Name
+		})
+		return forecastResultsMsg{forecasts: forecasts}
+	}
+}
+
+func forecastLabel(forecast *command.MergeForecast) string {
+	label := fmt.Sprintf("%s: %s", forecast.Repository.Name, forecast.Outcome)
+	switch {
+	case len(forecast.Files) > 0:
+		label += " (" + strings.Join(forecast.Files, ", ") + ")"
+	case forecast.Err != nil:
+		label += " (" + forecast.Err.Error() + ")"
+	}
+	return label
+}
+
+func (m *Model) renderForecast() string {
+	if !m.forecastActive {
+		return ""
+	}
+	panelWidth := 72
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+
+	lines := []string{m.styles.PanelTitle.Render(fmt.Sprintf("Forecast (%s)", m.mode.ID)), ""}
+	if m.forecastRunning {
+		lines = append(lines, "Running dry runs against the last fetched upstreams...")
+	} else {
+		failing := 0
+		for i, forecast := range m.forecasts {
+			if forecast.WillFail() {
+				failing++
+			}
+			marker := " "
+			if forecast.Repository.WorkStatus() == git.Queued {
+				marker = queuedSymbol
+			}
+			label := marker + " " + forecastLabel(forecast)
+			if i == m.forecastCursor {
+				lines = append(lines, m.styles.SelectedItem.Render(padToWidth(truncateString("> "+label, contentWidth), contentWidth)))
+			} else {
+				lines = append(lines, truncateString("  "+label, contentWidth))
+			}
+		}
+		lines = append(lines, "", fmt.Sprintf("%d of %d repo(s) would fail", failing, len(m.forecasts)))
+	}
+	hints := "space: (un)queue | u: unqueue failing | enter: start | esc: close"
+	lines = append(lines, "", m.styles.Help.Render(truncateString(hints, contentWidth)))
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
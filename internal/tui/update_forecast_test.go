@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestForecast_UnqueuesFailingRepositories(t *testing.T) {
+	alpha := &git.Repository{Name: "alpha", State: &git.RepositoryState{}}
+	beta := &git.Repository{Name: "beta", State: &git.RepositoryState{}}
+	alpha.SetWorkStatusSilent(git.Queued)
+	beta.SetWorkStatusSilent(git.Queued)
+	m := &Model{repositories: []*git.Repository{alpha, beta}, styles: DefaultStyles(), mode: pushMode, width: 100}
+
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")})
+	require.Nil(t, cmd)
+	require.False(t, m.forecastActive)
+
+	m.mode = pullMode
+	m.forecastActive = true
+	m.applyForecastResults(forecastResultsMsg{forecasts: []*command.MergeForecast{
+		{Repository: beta, Outcome: command.ForecastConflict, Files: []string{"go.mod"}},
+		{Repository: alpha, Outcome: command.ForecastFastForward},
+	}})
+	require.Contains(t, m.renderForecast(), "beta: conflict (go.mod)")
+	require.Contains(t, m.renderForecast(), "1 of 2 repo(s) would fail")
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	require.Equal(t, git.Available, beta.WorkStatus())
+	require.Equal(t, git.Queued, alpha.WorkStatus())
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	require.False(t, m.forecastActive)
+}
@@ -0,0 +1,33 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+	"github.com/thorstenhirsch/gitbatch/internal/job"
+)
+
+// syncForksCmd brings the forks among repos up to date with their upstream.
+// Repositories without both an origin and an upstream remote are skipped.
+func (m *Model) syncForksCmd(repos []*git.Repository) tea.Cmd {
+	var jobs []panelJob
+	var forks []*git.Repository
+	for _, repo := range filterRepositories(repos) {
+		if _, _, ok := repo.ForkRemotes(); ok && repo.State != nil {
+			forks = append(forks, repo)
+			jobs = append(jobs, panelJob{
+				repo:    repo,
+				jobType: job.SyncForkJob,
+				message: i18n.T("syncing fork"),
+			})
+		}
+	}
+	if len(jobs) == 0 {
+		m.notice = i18n.T("no fork selected (needs origin and upstream remotes)")
+		return nil
+	}
+	// The sync pushes the default branch to origin.
+	return m.confirmDestructive(i18n.T("sync fork and push to origin"), forks, func() tea.Cmd {
+		return m.startPanelJobs(jobs, NonePanel, false)
+	})
+}
@@ -0,0 +1,315 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// grepMatchLimit caps the matches listed per repository so a pattern that
+// matches everywhere stays browsable.
+const grepMatchLimit = 100
+
+// grepResult holds the matches of one repository.
+type grepResult struct {
+	repo    *git.Repository
+	matches []git.GrepMatch
+	err     error
+}
+
+// grepResultsMsg delivers the results of a workspace grep.
+type grepResultsMsg struct {
+	pattern string
+	results []grepResult
+}
+
+// grepRow is a line of the results view: a repository header or a match.
+type grepRow struct {
+	repo  *git.Repository
+	match *git.GrepMatch
+	text  string
+}
+
+func (m *Model) handleGrepPromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.grepPromptActive {
+		return false, nil
+	}
+	if msg.Paste {
+		m.grepBuffer += sanitizeCredentialPaste(string(msg.Runes))
+		return true, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc":
+		m.grepPromptActive = false
+		m.grepBuffer = ""
+		return true, nil
+	case "enter":
+		return true, m.submitGrepPrompt()
+	case "backspace", "ctrl+h":
+		runes := []rune(m.grepBuffer)
+		if len(runes) > 0 {
+			m.grepBuffer = string(runes[:len(runes)-1])
+		}
+		return true, nil
+	case " ":
+		m.grepBuffer += " "
+		return true, nil
+	default:
+		if len(msg.Runes) > 0 {
+			m.grepBuffer += string(msg.Runes)
+		}
+		return true, nil
+	}
+}
+
+// openGrepPrompt asks for a pattern, prefilled with the previous one.
+func (m *Model) openGrepPrompt() {
+	m.grepPromptActive = true
+	m.grepBuffer = m.grepPattern
+}
+
+// grepRepositories returns the tagged repositories, or all of them.
+func (m *Model) grepRepositories() []*git.Repository {
+	if tagged := m.taggedRepositories(); len(tagged) > 0 {
+		return tagged
+	}
+	return filterRepositories(m.repositories)
+}
+
+func (m *Model) submitGrepPrompt() tea.Cmd {
+	pattern := m.grepBuffer
+	if strings.TrimSpace(pattern) == "" {
+		return nil
+	}
+	m.grepPromptActive = false
+	m.grepBuffer = ""
+	repos := m.grepRepositories()
+	if len(repos) == 0 {
+		return nil
+	}
+	m.grepPattern = pattern
+	m.grepActive = true
+	m.grepRunning = true
+	m.grepResults = nil
+	m.grepCursor = 0
+	return grepRepositoriesCmd(pattern, repos)
+}
+
+func (m *Model) dismissGrep() {
+	m.grepActive = false
+	m.grepRunning = false
+	m.grepResults = nil
+	m.grepCursor = 0
+}
+
+func (m *Model) applyGrepResults(msg grepResultsMsg) {
+	// Results of a search the user already closed or replaced are dropped.
+	if !m.grepActive || msg.pattern != m.grepPattern {
+		return
+	}
+	m.grepRunning = false
+	m.grepResults = msg.results
+	m.grepCursor = 0
+}
+
+// grepRows flattens the results into headers and matches.
+func (m *Model) grepRows() []grepRow {
+	var rows []grepRow
+	for _, result := range m.grepResults {
+		header := fmt.Sprintf("%s (%d)", result.repo.Name, len(result.matches))
+		if result.err != nil {
+			header = fmt.Sprintf("%s: %v", result.repo.Name, result.err)
+		} else if len(result.matches) >= grepMatchLimit {
+			header = fmt.Sprintf("%s (first %d)", result.repo.Name, grepMatchLimit)
+		}
+		rows = append(rows, grepRow{repo: result.repo, text: header})
+		for i := range result.matches {
+			match := &result.matches[i]
+			rows = append(rows, grepRow{
+				repo:  result.repo,
+				match: match,
+				text:  fmt.Sprintf("%s:%d: %s", match.File, match.Line, strings.TrimSpace(match.Text)),
+			})
+		}
+	}
+	return rows
+}
+
+// grepMatchRows returns the indices of the rows the cursor can select.
+func grepMatchRows(rows []grepRow) []int {
+	var indices []int
+	for i, row := range rows {
+		if row.match != nil {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+func (m *Model) handleGrepKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.grepActive {
+		return false, nil
+	}
+	rows := m.grepRows()
+	selectable := grepMatchRows(rows)
+	count := len(selectable)
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc", "q":
+		m.dismissGrep()
+	case "/":
+		m.dismissGrep()
+		m.openGrepPrompt()
+	case "up", "k":
+		if count > 0 {
+			wrapCursor(&m.grepCursor, count, -1)
+		}
+	case "down", "j":
+		if count > 0 {
+			wrapCursor(&m.grepCursor, count, 1)
+		}
+	case "enter":
+		if count > 0 {
+			row := rows[selectable[clampIndex(m.grepCursor, count)]]
+			return true, openInEditorCmd(filepath.Join(row.repo.AbsPath, row.match.File), row.match.Line)
+		}
+	}
+	return true, nil
+}
+
+// grepRepositoriesCmd greps repos in parallel, bounded by the git semaphore.
+func grepRepositoriesCmd(pattern string, repos []*git.Repository) tea.Cmd {
+	return func() tea.Msg {
+		var (
+			mu      sync.Mutex
+			wg      sync.WaitGroup
+			results []grepResult
+			work    = make(chan *git.Repository)
+		)
+		for range min(forceRefreshWorkers, max(len(repos), 1)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for r := range work {
+					res := grepResult{repo: r}
+					if err := git.AcquireGitSemaphore(context.Background()); err != nil {
+						res.err = err
+					} else {
+						res.matches, res.err = r.Grep(pattern, grepMatchLimit)
+						git.ReleaseGitSemaphore()
+					}
+					if len(res.matches) == 0 && res.err == nil {
+						continue
+					}
+					mu.Lock()
+					results = append(results, res)
+					mu.Unlock()
+				}
+			}()
+		}
+		for _, r := range repos {
+			if r != nil {
+				work <- r
+			}
+		}
+		close(work)
+		wg.Wait()
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].repo.Name < results[j].repo.Name
+		})
+		return grepResultsMsg{pattern: pattern, results: results}
+	}
+}
+
+func (m *Model) renderGrepPrompt() string {
+	if !m.grepPromptActive {
+		return ""
+	}
+	panelWidth := 60
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+
+	scope := "all repos"
+	if tagged := len(m.taggedRepositories()); tagged > 0 {
+		scope = fmt.Sprintf("%d tagged repos", tagged)
+	}
+	display := m.grepBuffer
+	if runes := []rune(display); len(runes) > contentWidth-2 {
+		display = string(runes[len(runes)-contentWidth+2:])
+	}
+	lines := []string{
+		m.styles.PanelTitle.Render("Grep " + scope),
+		"",
+		"> " + display,
+		"",
+		"enter: search | esc: cancel",
+	}
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
+
+func (m *Model) renderGrep() string {
+	if !m.grepActive {
+		return ""
+	}
+	panelWidth := 100
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+
+	lines := []string{m.styles.PanelTitle.Render(truncateString("Grep: "+m.grepPattern, contentWidth)), ""}
+	rows := m.grepRows()
+	switch {
+	case m.grepRunning:
+		lines = append(lines, "Searching...")
+	case len(rows) == 0:
+		lines = append(lines, "No matches.")
+	default:
+		selectable := grepMatchRows(rows)
+		selected := -1
+		if len(selectable) > 0 {
+			selected = selectable[clampIndex(m.grepCursor, len(selectable))]
+		}
+		// Keep the selected row in a window that fits the screen.
+		height := 20
+		if m.height > 0 {
+			height = max(m.height-10, 5)
+		}
+		start := 0
+		if selected >= height {
+			start = selected - height + 1
+		}
+		end := min(start+height, len(rows))
+		for i := start; i < end; i++ {
+			row := rows[i]
+			switch {
+			case row.match == nil:
+				lines = append(lines, m.styles.BranchInfo.Render(truncateString(row.text, contentWidth)))
+			case i == selected:
+				lines = append(lines, m.styles.SelectedItem.Render(padToWidth(truncateString("> "+row.text, contentWidth), contentWidth)))
+			default:
+				lines = append(lines, truncateString("  "+row.text, contentWidth))
+			}
+		}
+	}
+	lines = append(lines, "", m.styles.Help.Render("enter: open in $EDITOR | /: new search | esc: close"))
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestGrep_GroupsMatchesByRepository(t *testing.T) {
+	alpha := initBranchCreationRepo(t, "alpha")
+	beta := initBranchCreationRepo(t, "beta")
+	m := &Model{repositories: []*git.Repository{beta, alpha}, styles: DefaultStyles(), width: 120, height: 40}
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	require.True(t, m.grepPromptActive)
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("hel")})
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("lo")})
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	require.True(t, m.grepActive)
+	require.Contains(t, m.renderGrep(), "Searching...")
+
+	m.applyGrepResults(cmd().(grepResultsMsg))
+	require.Len(t, m.grepResults, 2)
+	require.Equal(t, alpha, m.grepResults[0].repo)
+	view := m.renderGrep()
+	require.Contains(t, view, "alpha (1)")
+	require.Contains(t, view, "> README.md:1: hello")
+
+	// The cursor skips the repository headers.
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+	rows := m.grepRows()
+	require.Equal(t, beta, rows[grepMatchRows(rows)[m.grepCursor]].repo)
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	require.False(t, m.grepActive)
+	require.Equal(t, "hello", m.grepPattern)
+}
+
+func TestEditorCommand_LineArguments(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "nvim")
+	require.Equal(t, []string{"nvim", "+12", "/src/main.go"}, editorCommand("/src/main.go", 12).Args)
+
+	t.Setenv("EDITOR", "code --wait")
+	require.Equal(t, []string{"code", "--wait", "-g", "/src/main.go:12"}, editorCommand("/src/main.go", 12).Args)
+
+	t.Setenv("VISUAL", "hx")
+	require.Equal(t, []string{"hx", "/src/main.go"}, editorCommand("/src/main.go", 0).Args)
+}
@@ -0,0 +1,201 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// handleHelpKey scrolls and searches the help overlay. While it is shown it
+// takes all keys, so typing a search does not trigger overview actions.
+func (m *Model) handleHelpKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.showHelp {
+		return false, nil
+	}
+	key := msg.String()
+	if key == "ctrl+c" {
+		return true, tea.Quit
+	}
+
+	if m.helpSearching {
+		switch key {
+		case "esc":
+			m.helpSearching = false
+			m.helpQuery = ""
+		case "enter":
+			m.helpSearching = false
+		case "backspace", "ctrl+h":
+			if runes := []rune(m.helpQuery); len(runes) > 0 {
+				m.helpQuery = string(runes[:len(runes)-1])
+			}
+		case " ":
+			m.helpQuery += " "
+		default:
+			if len(msg.Runes) > 0 {
+				m.helpQuery += string(msg.Runes)
+			}
+		}
+		m.helpOffset = 0
+		return true, nil
+	}
+
+	viewport := m.helpViewportSize()
+	switch key {
+	case "?", "q":
+		m.closeHelp()
+	case "esc":
+		if m.helpQuery != "" {
+			m.helpQuery = ""
+			m.helpOffset = 0
+			return true, nil
+		}
+		m.closeHelp()
+	case "/":
+		m.helpSearching = true
+	case "up", "k":
+		m.helpOffset--
+	case "down", "j":
+		m.helpOffset++
+	case "pgup", "ctrl+u":
+		m.helpOffset -= viewport
+	case "pgdown", "ctrl+f", "ctrl+d":
+		m.helpOffset += viewport
+	case "g", "home":
+		m.helpOffset = 0
+	case "G", "end":
+		m.helpOffset = len(m.helpLines())
+	}
+	m.helpOffset = clampHelpOffset(m.helpOffset, len(m.helpLines()), viewport)
+	return true, nil
+}
+
+func (m *Model) closeHelp() {
+	m.showHelp = false
+	m.helpSearching = false
+	m.helpQuery = ""
+	m.helpOffset = 0
+}
+
+// legendEntry explains a status symbol of the overview in its row color.
+type legendEntry struct {
+	symbol string
+	style  lipgloss.Style
+	help   string
+}
+
+// statusLegend lists the status symbols of the overview, the failures first
+// so the kinds of errors can be told apart at a glance.
+func (m *Model) statusLegend() []legendEntry {
+	return []legendEntry{
+		{failSymbol, m.styles.FailedItem, "failed"},
+		{credentialsSymbol, m.styles.CredentialsItem, "needs credentials"},
+		{networkSymbol, m.styles.NetworkItem, "remote not reachable (network, DNS, timeout)"},
+		{conflictSymbol, m.styles.ConflictItem, "merge conflict"},
+		{dirtySymbol, m.styles.DisabledItem, "local changes conflict with incoming commits"},
+		{localChangesSymbol, m.styles.LocalChangesItem, "local changes"},
+		{noRemoteSymbol, m.styles.DisabledItem, "no remote"},
+		{queuedSymbol, m.styles.QueuedItem, "queued"},
+		{waitingSymbol, m.styles.PendingItem, "waiting"},
+		{successSymbol, m.styles.SuccessItem, "succeeded"},
+	}
+}
+
+// helpLines lists the status symbols and the bindings of the keymaps that
+// match the search, under the title of their group.
+func (m *Model) helpLines() []string {
+	query := strings.ToLower(strings.TrimSpace(m.helpQuery))
+	var lines []string
+	for _, entry := range m.statusLegend() {
+		help := i18n.T(entry.help)
+		if query != "" && !strings.Contains(strings.ToLower(help), query) &&
+			!strings.Contains(strings.ToLower(entry.help), query) {
+			continue
+		}
+		if len(lines) == 0 {
+			lines = append(lines, i18n.T("Status symbols")+":")
+		}
+		lines = append(lines, fmt.Sprintf("  %s  %s", entry.style.Render(statusIcon(entry.symbol)), help))
+	}
+	for _, group := range helpKeymap() {
+		var rows []string
+		for _, binding := range group.bindings {
+			if binding.help == "" {
+				continue
+			}
+			label, help := binding.keyLabel(), i18n.T(binding.help)
+			if query != "" && !strings.Contains(strings.ToLower(label+" "+help), query) &&
+				!strings.Contains(strings.ToLower(binding.help), query) {
+				continue
+			}
+			rows = append(rows, fmt.Sprintf("  %-12s %s", label, help))
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, i18n.T(group.title)+":")
+		lines = append(lines, rows...)
+	}
+	return lines
+}
+
+// helpKeymap returns the groups of the overview followed by those of the
+// panels, overlays and prompts.
+func helpKeymap() []keyGroup {
+	groups := make([]keyGroup, 0, len(overviewKeymap)+len(contextKeymap))
+	return append(append(groups, overviewKeymap...), contextKeymap...)
+}
+
+// helpViewportSize is the number of help lines that fit on the screen next
+// to the title, the search line and the footer.
+func (m *Model) helpViewportSize() int {
+	if m.height <= 0 {
+		return 20
+	}
+	return max(m.height-12, 5)
+}
+
+func clampHelpOffset(offset, total, viewport int) int {
+	return max(min(offset, total-viewport), 0)
+}
+
+// renderHelp renders the help overlay generated from the keymaps.
+func (m *Model) renderHelp() string {
+	panelWidth := 68
+	if m.width > 0 && panelWidth > m.width-4 {
+		panelWidth = m.width - 4
+	}
+	contentWidth := panelWidth - 4
+
+	lines := []string{m.styles.PanelTitle.Render(i18n.T("Help"))}
+	if m.helpSearching || m.helpQuery != "" {
+		search := "/" + m.helpQuery
+		if m.helpSearching {
+			search += "_"
+		}
+		lines = append(lines, truncateString(search, contentWidth))
+	}
+	lines = append(lines, "")
+
+	body := m.helpLines()
+	viewport := m.helpViewportSize()
+	offset := clampHelpOffset(m.helpOffset, len(body), viewport)
+	if len(body) == 0 {
+		lines = append(lines, i18n.T("No key matches %q.", m.helpQuery))
+	}
+	for _, line := range body[offset:min(offset+viewport, len(body))] {
+		lines = append(lines, truncateString(line, contentWidth))
+	}
+
+	footer := i18n.T("/: search | esc: close")
+	if len(body) > viewport {
+		footer = i18n.T("↑/↓: scroll (%d/%d) | %s", min(offset+viewport, len(body)), len(body), footer)
+	}
+	lines = append(lines, "", m.styles.Help.Render(footer), m.styles.Help.Render(gitVersionSummary()))
+	return m.styles.Panel.Width(panelWidth).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// historyLimit caps the audit entries the history panel loads.
+const historyLimit = 500
+
+// loadHistoryEntries reads the operations gitbatch recorded for r, newest
+// first.
+func (m *Model) loadHistoryEntries(r *git.Repository) {
+	m.historyEntries = nil
+	m.historyCursor = 0
+	m.historyOffset = 0
+	if r == nil {
+		return
+	}
+	entries, err := command.RepositoryAuditTrail(r, historyLimit)
+	if err != nil {
+		m.notice = err.Error()
+		return
+	}
+	m.historyEntries = entries
+}
+
+func (m *Model) handleHistoryPanelKey(key string) (tea.Model, tea.Cmd) {
+	count := len(m.historyEntries)
+	if count == 0 {
+		return m, nil
+	}
+
+	switch key {
+	case "up", "k":
+		wrapCursor(&m.historyCursor, count, -1)
+	case "down", "j":
+		wrapCursor(&m.historyCursor, count, 1)
+	case "home", "g":
+		m.historyCursor = 0
+	case "end", "G":
+		m.historyCursor = count - 1
+	}
+
+	ensureCursorVisible(&m.historyCursor, &m.historyOffset, count, m.historyViewportSize())
+	return m, nil
+}
+
+// historyViewportSize is the number of entries that fit next to the expanded
+// commands of the selected entry.
+func (m *Model) historyViewportSize() int {
+	count := len(m.historyEntries)
+	viewport := m.stashViewportSize(count)
+	if count > 0 {
+		viewport -= len(m.historyEntries[clampIndex(m.historyCursor, count)].Commands)
+	}
+	return max(viewport, 1)
+}
+
+// historyLabel summarises an entry on one line: time, operation and result.
+func historyLabel(entry command.AuditEntry) string {
+	result := "✓"
+	detail := entry.Message
+	if entry.Status == command.JobResultFailed {
+		result = "✗"
+		detail = entry.Error
+	}
+	label := fmt.Sprintf("%s  %s %s", entry.Time.Local().Format("2006-01-02 15:04"), entry.Operation, result)
+	if detail != "" {
+		label += " " + detail
+	}
+	return label
+}
+
+// renderHistory renders the audit trail of the selected repository. The
+// selected entry is expanded to the git commands it ran.
+func (m *Model) renderHistory(contentWidth, maxLines int) string {
+	if contentWidth <= 0 || maxLines <= 0 {
+		return ""
+	}
+	if len(m.historyEntries) == 0 {
+		if command.AuditLog() == "" {
+			return padToWidth(i18n.T("The audit log is disabled (audit_log: false)"), contentWidth)
+		}
+		return padToWidth(i18n.T("gitbatch has not run anything in this repository yet"), contentWidth)
+	}
+
+	lines := make([]string, 0, maxLines)
+	for i := m.historyOffset; i < len(m.historyEntries) && len(lines) < maxLines; i++ {
+		entry := m.historyEntries[i]
+		label := truncateString(historyLabel(entry), contentWidth-2)
+		if i != m.historyCursor {
+			lines = append(lines, padToWidth("  "+label, contentWidth))
+			continue
+		}
+		lines = append(lines, m.styles.SelectedItem.Render(padToWidth("> "+label, contentWidth)))
+		for _, cmd := range entry.Commands {
+			if len(lines) >= maxLines {
+				break
+			}
+			lines = append(lines, padToWidth(truncateString("    $ "+cmd, contentWidth), contentWidth))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestHistoryPanelExpandsTheSelectedEntry(t *testing.T) {
+	command.SetAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	t.Cleanup(func() { command.SetAuditLog("") })
+	repo := initBranchCreationRepo(t, "alpha")
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 100, height: 30}
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("H")})
+	require.Equal(t, HistoryPanel, m.sidePanel)
+	require.Contains(t, m.renderHistory(80, 10), "has not run anything")
+
+	m.historyEntries = []command.AuditEntry{
+		{Time: time.Now(), Operation: "pull", Status: command.JobResultSuccess, Message: "pull completed", Commands: []string{"git pull --ff-only origin main"}},
+		{Time: time.Now().Add(-time.Hour), Operation: "push", Status: command.JobResultFailed, Error: "rejected", Commands: []string{"git push origin main"}},
+	}
+	view := m.renderHistory(80, 10)
+	require.Contains(t, view, "pull ✓ pull completed")
+	require.Contains(t, view, "$ git pull --ff-only origin main")
+	require.NotContains(t, view, "$ git push")
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+	view = m.renderHistory(80, 10)
+	require.Contains(t, view, "push ✗ rejected")
+	require.Contains(t, view, "$ git push origin main")
+	require.NotContains(t, view, "$ git pull")
+}
@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// toggleNoVerify switches the given repositories between running and
+// skipping their hooks. A mixed selection is switched to --no-verify first.
+func (m *Model) toggleNoVerify(repos []*git.Repository) {
+	repos = filterRepositories(repos)
+	if len(repos) == 0 {
+		return
+	}
+	enable := false
+	for _, r := range repos {
+		if !command.NoVerify(r) {
+			enable = true
+			break
+		}
+	}
+	for _, r := range repos {
+		command.SetRepoNoVerify(r, enable)
+	}
+	target := repos[0].Name
+	if len(repos) > 1 {
+		target = fmt.Sprintf("%d repos", len(repos))
+	}
+	if enable {
+		m.notice = "jobs in " + target + " run with --no-verify"
+	} else {
+		m.notice = "jobs in " + target + " run hooks again"
+	}
+}
@@ -0,0 +1,31 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestToggleNoVerify_MarksRepositories(t *testing.T) {
+	root := t.TempDir()
+	alpha := &git.Repository{Name: "alpha", AbsPath: filepath.Join(root, "alpha"), State: &git.RepositoryState{},
+		Hooks: &git.Hooks{Active: []string{"pre-push"}}}
+	beta := &git.Repository{Name: "beta", AbsPath: filepath.Join(root, "beta"), State: &git.RepositoryState{}}
+	m := &Model{repositories: []*git.Repository{alpha, beta}, styles: DefaultStyles()}
+
+	require.Equal(t, "alpha "+hooksSymbol, repoDisplayName(alpha))
+	require.Equal(t, "beta", repoDisplayName(beta))
+
+	m.toggleNoVerify([]*git.Repository{alpha, beta})
+	require.True(t, command.NoVerify(alpha))
+	require.True(t, command.NoVerify(beta))
+	require.Equal(t, "alpha "+noVerifySymbol, repoDisplayName(alpha))
+	require.Contains(t, m.notice, "2 repos")
+
+	m.toggleNoVerify([]*git.Repository{alpha})
+	require.False(t, command.NoVerify(alpha))
+	require.Equal(t, "alpha "+hooksSymbol, repoDisplayName(alpha))
+}
@@ -2,12 +2,17 @@ package tui
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/thorstenhirsch/gitbatch/internal/command"
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
 	"github.com/thorstenhirsch/gitbatch/internal/job"
 )
 
@@ -23,7 +28,9 @@ func (m *Model) toggleQueue() tea.Cmd {
 			return jobCompletedMsg{}
 		}
 	}
-	if !repoIsActionable(r) {
+	if reason, ok := m.queueBlocker(r); !ok {
+		noteQueueBlocker(r, reason)
+		m.notice = i18n.T("%s not tagged: %s", r.Name, reason)
 		return nil
 	}
 	return func() tea.Msg {
@@ -32,53 +39,247 @@ func (m *Model) toggleQueue() tea.Cmd {
 	}
 }
 
-// addToQueue marks a repository as queued for later execution.
-func (m *Model) addToQueue(r *git.Repository) error {
-	if !repoIsActionable(r) {
-		return nil
+// queueBlocker reports whether r can be queued in the current mode and, if
+// not, why.
+func (m *Model) queueBlocker(r *git.Repository) (string, bool) {
+	if r == nil {
+		return i18n.T("not loaded"), false
 	}
-	switch m.mode.ID {
-	case PullMode:
-		if r.State == nil || r.State.Branch == nil || r.State.Branch.Upstream == nil || r.State.Remote == nil {
-			return nil
+	if r.IsLinkedWorktree() {
+		return i18n.T("linked worktree"), false
+	}
+	status := r.WorkStatus()
+	if status == git.Fail {
+		// Allow retry on a clean-message fail (preserves fail visualization).
+		if r.State == nil || r.State.Message() != "" {
+			return i18n.T("failed"), false
 		}
-	case MergeMode:
-		if r.State == nil || r.State.Branch == nil || r.State.Branch.Upstream == nil {
-			return nil
+	} else if !status.Ready {
+		return i18n.T("busy"), false
+	}
+	if r.State == nil || r.State.Branch == nil {
+		return i18n.T("no branch checked out"), false
+	}
+	if !r.State.Branch.Clean {
+		return i18n.T("dirty"), false
+	}
+	return modeBlocker(r, m.jobMode(r))
+}
+
+// modeBlocker reports whether r has what a job of mode needs and, if not,
+// what is missing.
+func modeBlocker(r *git.Repository, mode Mode) (string, bool) {
+	if mode.composite != nil {
+		// The steps of a composite job are up to the user.
+		return "", true
+	}
+	switch mode.ID {
+	case PullMode, RebaseMode:
+		if r.State.Remote == nil {
+			return i18n.T("no remote"), false
 		}
-	case RebaseMode:
-		if r.State == nil || r.State.Branch == nil || r.State.Branch.Upstream == nil || r.State.Remote == nil {
-			return nil
+		if r.State.Branch.Upstream == nil {
+			return i18n.T("no upstream"), false
+		}
+	case MergeMode:
+		if r.State.Branch.Upstream == nil {
+			return i18n.T("no upstream"), false
 		}
 	case PushMode:
-		if r.State == nil || r.State.Remote == nil || r.State.Branch == nil {
-			return nil
+		if r.State.Remote == nil {
+			return i18n.T("no remote"), false
+		}
+		if rule, ok := unreviewedCommits(r); ok && rule.Block {
+			return i18n.T("%s is protected", r.State.Branch.Name), false
 		}
 	default:
+		return i18n.T("mode %s cannot be queued", mode.ID), false
+	}
+	return "", true
+}
+
+// unreviewedCommits reports whether r is on a protected branch and has local
+// commits that did not go through a pull request, with the protecting rule.
+func unreviewedCommits(r *git.Repository) (command.BranchProtectionRule, bool) {
+	rule, ok := command.ProtectedBranch(r)
+	if !ok {
+		return rule, false
+	}
+	ahead, err := strconv.Atoi(r.State.Branch.Pushables)
+	return rule, err == nil && ahead > 0
+}
+
+// protectionWarning is the message of a repository whose job in mode would
+// push to a protected branch, empty if it would not.
+func protectionWarning(r *git.Repository, mode Mode) string {
+	if mode.ID != PushMode {
+		return ""
+	}
+	if _, ok := unreviewedCommits(r); !ok {
+		return ""
+	}
+	return i18n.T("pushes to protected branch %s", r.State.Branch.Name)
+}
+
+// modeOverrides keeps the modes tagged repositories run in instead of the
+// mode of the batch. Jobs are started from commands, hence the lock.
+type modeOverrides struct {
+	mu    sync.Mutex
+	modes map[*git.Repository]Mode
+}
+
+func (o *modeOverrides) get(r *git.Repository) (Mode, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	mode, ok := o.modes[r]
+	return mode, ok
+}
+
+func (o *modeOverrides) set(r *git.Repository, mode Mode) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.modes == nil {
+		o.modes = make(map[*git.Repository]Mode)
+	}
+	o.modes[r] = mode
+}
+
+func (o *modeOverrides) clear(r *git.Repository) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.modes, r)
+}
+
+// jobMode returns the mode the job of r runs in: its own if one was picked
+// with M, otherwise the mode of the batch.
+func (m *Model) jobMode(r *git.Repository) Mode {
+	if mode, ok := m.modeOverrides.get(r); ok && mode.ID != m.mode.ID {
+		return mode
+	}
+	return m.mode
+}
+
+// cycleRepositoryMode switches the selected tagged repository to the next
+// mode it can run in, so a batch can rebase a few repositories while the
+// others pull. Cycling back to the mode of the batch drops the override.
+func (m *Model) cycleRepositoryMode() {
+	r := m.currentRepository()
+	if r == nil {
+		return
+	}
+	if r.WorkStatus() != git.Queued {
+		m.notice = i18n.T("tag %s to give it its own mode", r.Name)
+		return
+	}
+	available := availableModes()
+	current := 0
+	for i, mode := range available {
+		if mode.ID == m.jobMode(r).ID {
+			current = i
+			break
+		}
+	}
+	for step := 1; step < len(available); step++ {
+		next := available[(current+step)%len(available)]
+		if next.ID == m.mode.ID {
+			m.modeOverrides.clear(r)
+			r.State.SetMessage(protectionWarning(r, next))
+			m.notice = i18n.T("%s runs in the mode of the batch (%s)", r.Name, next.ID)
+			return
+		}
+		if _, ok := modeBlocker(r, next); ok {
+			m.modeOverrides.set(r, next)
+			r.State.SetMessage(protectionWarning(r, next))
+			m.notice = i18n.T("%s runs %s instead of %s", r.Name, next.ID, m.mode.ID)
+			return
+		}
+	}
+	m.notice = i18n.T("%s cannot run in another mode", r.Name)
+}
+
+// noteQueueBlocker keeps the reason a repository was not queued as its
+// message. Failed and busy repositories keep the message of their job.
+func noteQueueBlocker(r *git.Repository, reason string) {
+	if r != nil && r.State != nil && r.WorkStatus().Ready {
+		r.State.SetMessage(reason)
+	}
+}
+
+// addToQueue marks a repository as queued for later execution.
+func (m *Model) addToQueue(r *git.Repository) error {
+	if _, ok := m.queueBlocker(r); !ok {
 		return nil
 	}
+	if r.State != nil && r.WorkStatus() != git.Fail {
+		r.State.SetMessage(protectionWarning(r, m.jobMode(r)))
+	}
 	r.SetWorkStatusSilent(git.Queued)
 	return nil
 }
 
-// removeFromQueue removes a repository from the queue.
+// removeFromQueue removes a repository from the queue and drops its own
+// mode.
 func (m *Model) removeFromQueue(r *git.Repository) error {
+	m.modeOverrides.clear(r)
 	r.SetWorkStatusSilent(git.Available)
 	return nil
 }
 
-// queueAll adds all actionable repositories to the queue.
+// queueAll adds all actionable repositories of the active tab to the queue
+// and reports how many were tagged and why the others were skipped. The
+// reason is also kept as the message of each skipped repository. Pinned
+// repositories are only tagged one by one.
 func (m *Model) queueAll() tea.Cmd {
+	repos := m.visibleRepositories()
 	return func() tea.Msg {
-		for _, r := range m.repositories {
-			if repoIsActionable(r) {
-				m.addToQueue(r)
+		var result queueResultMsg
+		for _, r := range repos {
+			if r != nil && r.WorkStatus() == git.Queued {
+				result.tagged++
+				continue
 			}
+			if command.Pinned(r) {
+				result.skip(i18n.T("pinned"))
+				continue
+			}
+			if reason, ok := m.queueBlocker(r); !ok {
+				noteQueueBlocker(r, reason)
+				result.skip(reason)
+				continue
+			}
+			m.addToQueue(r)
+			result.tagged++
 		}
-		return jobCompletedMsg{}
+		return result
 	}
 }
 
+// skip counts a repository skipped for reason, keeping the reasons in the
+// order they first occurred.
+func (q *queueResultMsg) skip(reason string) {
+	for i := range q.skipped {
+		if q.skipped[i].reason == reason {
+			q.skipped[i].count++
+			return
+		}
+	}
+	q.skipped = append(q.skipped, queueSkip{reason: reason, count: 1})
+}
+
+// summary renders the result like "tagged 34, skipped 12 (5 dirty, 7 busy)".
+func (q queueResultMsg) summary() string {
+	if len(q.skipped) == 0 {
+		return i18n.T("tagged %d", q.tagged)
+	}
+	total := 0
+	parts := make([]string, len(q.skipped))
+	for i, skip := range q.skipped {
+		parts[i] = fmt.Sprintf("%d %s", skip.count, skip.reason)
+		total += skip.count
+	}
+	return i18n.T("tagged %d, skipped %d (%s)", q.tagged, total, strings.Join(parts, ", "))
+}
+
 // unqueueAll removes all repositories from the queue.
 func (m *Model) unqueueAll() tea.Cmd {
 	return func() tea.Msg {
@@ -98,15 +299,13 @@ func (m *Model) unqueueAll() tea.Cmd {
 // them. For push/fetch, tree cleanliness does not gate the op, so the
 // refresh is skipped to avoid needless work.
 func (m *Model) preBatchRefresh() {
-	switch m.mode.ID {
-	case PullMode, MergeMode, RebaseMode:
-	default:
-		return
-	}
-
 	var queued []*git.Repository
 	for _, r := range m.repositories {
-		if r != nil && r.WorkStatus() == git.Queued {
+		if r == nil || r.WorkStatus() != git.Queued {
+			continue
+		}
+		switch m.jobMode(r).ID {
+		case PullMode, MergeMode, RebaseMode:
 			queued = append(queued, r)
 		}
 	}
@@ -125,52 +324,221 @@ func (m *Model) preBatchRefresh() {
 	wg.Wait()
 }
 
+// cancelWaitingJobs drops the git commands that wait for a slot on the git
+// queue. Their repositories return to Available once the worker skips them;
+// running commands finish.
+func (m *Model) cancelWaitingJobs() tea.Cmd {
+	if n := command.CancelScheduledCommands(); n > 0 {
+		m.notice = i18n.T("cancelled %d waiting jobs", n)
+	} else {
+		m.notice = i18n.T("no waiting jobs")
+	}
+	return nil
+}
+
 // startQueue starts jobs for all queued repositories.
 func (m *Model) startQueue() tea.Cmd {
+	return m.startQueueLimit(0)
+}
+
+// startQueueLimit starts jobs for the first limit queued repositories in
+// overview order; the rest stay queued. A limit of 0 starts all of them.
+// Repositories with configured dependencies come after the ones they depend
+// on and are held back until those succeeded.
+func (m *Model) startQueueLimit(limit int) tea.Cmd {
 	return func() tea.Msg {
 		m.preBatchRefresh()
+		var queued []*git.Repository
 		for _, r := range m.repositories {
-			if r.WorkStatus() != git.Queued {
+			if r.WorkStatus() == git.Queued {
+				queued = append(queued, r)
+			}
+		}
+		deps := command.Dependencies(queued)
+		order, err := command.DependencyOrder(queued, deps)
+		if err != nil {
+			return errMsg{err: err}
+		}
+
+		batch := command.NewBatchID()
+		waits := make(map[*git.Repository][]*git.Repository)
+		started := 0
+		for _, r := range order {
+			if limit > 0 && started >= limit {
+				break
+			}
+			if len(deps[r]) > 0 {
+				// Held repositories count toward the limit: they run as part
+				// of this batch once their dependencies are done.
+				waits[r] = deps[r]
+				r.State.SetMessage(i18n.T("waiting for %s", repositoryNames(deps[r])))
+				started++
 				continue
 			}
-			j := &job.Job{Repository: r}
-
-			switch m.mode.ID {
-			case PullMode:
-				if r.State == nil || r.State.Branch == nil || r.State.Branch.Upstream == nil || r.State.Remote == nil {
-					continue
-				}
-				j.JobType = job.PullJob
-				j.Options = &command.PullOptions{RemoteName: r.State.Remote.Name, FFOnly: true}
-			case MergeMode:
-				if r.State == nil || r.State.Branch == nil || r.State.Branch.Upstream == nil {
-					continue
-				}
-				j.JobType = job.MergeJob
-			case RebaseMode:
-				if r.State == nil || r.State.Branch == nil || r.State.Branch.Upstream == nil || r.State.Remote == nil {
-					continue
-				}
-				j.JobType = job.RebaseJob
-				j.Options = &command.PullOptions{RemoteName: r.State.Remote.Name, Rebase: true}
-			case PushMode:
-				if r.State == nil || r.State.Remote == nil || r.State.Branch == nil {
-					continue
-				}
-				j.JobType = job.PushJob
-				j.Options = &command.PushOptions{RemoteName: r.State.Remote.Name, ReferenceName: r.State.Branch.Name}
-			default:
+			if m.startBatchJob(r, batch) {
+				started++
+			}
+		}
+		m.jobsRunning = true
+		return batchStartedMsg{batch: batch, waits: waits, advice: localDependencyAdvice(queued)}
+	}
+}
+
+// localDependencyAdvice describes the order the manifests of repos suggest
+// for the repositories that depend on each other, or warns when they form a
+// cycle. The batch does not wait for them either way.
+func localDependencyAdvice(repos []*git.Repository) string {
+	deps := command.LocalDependencyGraph(repos)
+	if len(deps) == 0 {
+		return ""
+	}
+	order, err := command.DependencyOrder(repos, deps)
+	if err != nil {
+		return i18n.T("warning: local dependencies: %s", err)
+	}
+	related := make(map[*git.Repository]bool)
+	for r, local := range deps {
+		related[r] = true
+		for _, dep := range local {
+			related[dep] = true
+		}
+	}
+	var suggested []*git.Repository
+	for _, r := range order {
+		if related[r] {
+			suggested = append(suggested, r)
+		}
+	}
+	return i18n.T("local dependencies suggest the order %s", repositoryNames(suggested))
+}
+
+// startBatchJob starts the job of the mode of a queued repository as part of
+// batch. It
+// reports false when the repository lacks what the job needs; such a
+// repository leaves the queue as failed, so the repositories that depend on
+// it are skipped and the batch can end.
+func (m *Model) startBatchJob(r *git.Repository, batch command.BatchID) bool {
+	j := &job.Job{Repository: r, Batch: batch}
+	mode := m.jobMode(r)
+	m.modeOverrides.clear(r)
+
+	switch mode.ID {
+	case PullMode:
+		if reason := batchJobBlocker(r, true, true); reason != "" {
+			return refuseBatchJob(r, mode, reason)
+		}
+		j.JobType = job.PullJob
+		j.Options = &command.PullOptions{RemoteName: r.State.Remote.Name, FFOnly: true}
+	case MergeMode:
+		if reason := batchJobBlocker(r, true, false); reason != "" {
+			return refuseBatchJob(r, mode, reason)
+		}
+		j.JobType = job.MergeJob
+	case RebaseMode:
+		if reason := batchJobBlocker(r, true, true); reason != "" {
+			return refuseBatchJob(r, mode, reason)
+		}
+		j.JobType = job.RebaseJob
+		j.Options = &command.PullOptions{RemoteName: r.State.Remote.Name, Rebase: true}
+	case PushMode:
+		if reason := batchJobBlocker(r, false, true); reason != "" {
+			return refuseBatchJob(r, mode, reason)
+		}
+		j.JobType = job.PushJob
+		j.Options = &command.PushOptions{RemoteName: r.State.Remote.Name, ReferenceName: r.State.Branch.Name}
+	default:
+		if mode.composite == nil {
+			return refuseBatchJob(r, mode, i18n.T("no composite job configured"))
+		}
+		j.JobType = job.CompositeJob
+		j.Options = &command.CompositeOptions{Job: mode.composite}
+	}
+
+	if err := j.Start(); err != nil {
+		r.SetWorkStatus(git.Available)
+		r.State.SetMessage(fmt.Sprintf("failed to start: %v", err))
+		return false
+	}
+	return true
+}
+
+// batchJobBlocker names what r lacks for a batch job that needs a branch and,
+// as requested, an upstream and a remote. It is empty when nothing is missing.
+func batchJobBlocker(r *git.Repository, upstream, remote bool) string {
+	switch {
+	case r.State == nil || r.State.Branch == nil:
+		return i18n.T("no branch checked out")
+	case upstream && r.State.Branch.Upstream == nil:
+		return i18n.T("upstream not set")
+	case remote && r.State.Remote == nil:
+		return i18n.T("remote not set")
+	}
+	return ""
+}
+
+// refuseBatchJob takes a queued repository whose job cannot start out of the
+// queue as failed and reports false.
+func refuseBatchJob(r *git.Repository, mode Mode, reason string) bool {
+	r.MarkFailure(gerr.KindFatal, i18n.T("cannot %s: %s", string(mode.ID), reason))
+	return false
+}
+
+// applyBatchAbort ends the batches fail_fast dropped the waiting jobs of:
+// their held repositories leave the queue as well.
+func (m *Model) applyBatchAbort() {
+	for _, aborted := range command.TakeBatchAborts() {
+		for r, wait := range m.dependencyWaits {
+			if wait.batch != aborted.Batch {
 				continue
 			}
+			r.State.SetMessage(i18n.T("cancelled: %s failed in %s (fail_fast)", aborted.Kind, aborted.Repository))
+			r.SetWorkStatus(git.Available)
+			delete(m.dependencyWaits, r)
+		}
+		m.notice = i18n.T("batch aborted: %s failed in %s (fail_fast)", aborted.Kind, aborted.Repository)
+	}
+}
 
-			if err := j.Start(); err != nil {
-				r.SetWorkStatus(git.Available)
-				r.State.Message = fmt.Sprintf("failed to start: %v", err)
+// releaseDependents starts the held repositories whose dependencies all
+// succeeded. A repository whose dependency failed, or was skipped itself, is
+// skipped and leaves the queue.
+func (m *Model) releaseDependents() {
+	for r, wait := range m.dependencyWaits {
+		if r.WorkStatus() != git.Queued {
+			// Untagged while it waited.
+			delete(m.dependencyWaits, r)
+			continue
+		}
+		failed := ""
+		waiting := false
+		for _, dep := range wait.deps {
+			switch status := dep.WorkStatus(); {
+			case status == git.Success:
+			case status.InFlight():
+				waiting = true
+			default:
+				failed = dep.Name
 			}
 		}
-		m.jobsRunning = true
-		return jobCompletedMsg{}
+		switch {
+		case failed != "":
+			delete(m.dependencyWaits, r)
+			r.State.SetMessage(i18n.T("skipped: %s did not succeed", failed))
+			r.SetWorkStatus(git.Available)
+		case !waiting:
+			delete(m.dependencyWaits, r)
+			r.State.SetMessage("")
+			m.startBatchJob(r, wait.batch)
+		}
+	}
+}
+
+func repositoryNames(repos []*git.Repository) string {
+	names := make([]string, len(repos))
+	for i, r := range repos {
+		names[i] = r.Name
 	}
+	return strings.Join(names, ", ")
 }
 
 func (m *Model) runFetchForRepo(repo *git.Repository) tea.Cmd {
@@ -188,14 +556,14 @@ func (m *Model) runPullForRepo(repo *git.Repository, suppressSuccess bool) tea.C
 		return nil
 	}
 	if repo.State.Branch.Upstream == nil {
-		repo.State.Message = "upstream not set"
+		repo.State.SetMessage(i18n.T("upstream not set"))
 		return nil
 	}
 	if repo.State.Remote == nil {
-		repo.State.Message = "remote not set"
+		repo.State.SetMessage(i18n.T("remote not set"))
 		return nil
 	}
-	repo.State.Message = "pull queued"
+	repo.State.SetMessage(i18n.T("pull queued"))
 	repo.SetWorkStatus(git.Pending)
 	j := &job.Job{
 		Repository: repo,
@@ -209,7 +577,6 @@ func (m *Model) runPullForRepo(repo *git.Repository, suppressSuccess bool) tea.C
 		repo.SetWorkStatus(git.Available)
 		return func() tea.Msg { return errMsg{err: err} }
 	}
-	repo.SetWorkStatus(git.Queued)
 	m.jobsRunning = true
 	return m.ensureTicking()
 }
@@ -222,11 +589,11 @@ func (m *Model) runPushForRepo(repo *git.Repository, force bool, suppressSuccess
 		return nil
 	}
 	if repo.State.Remote == nil {
-		repo.State.Message = "remote not set"
+		repo.State.SetMessage(i18n.T("remote not set"))
 		return nil
 	}
 	if repo.State.Branch.Name == "" {
-		repo.State.Message = "branch not set"
+		repo.State.SetMessage(i18n.T("branch not set"))
 		return nil
 	}
 	if message == "" {
@@ -236,7 +603,7 @@ func (m *Model) runPushForRepo(repo *git.Repository, force bool, suppressSuccess
 			message = "push queued"
 		}
 	}
-	repo.State.Message = message
+	repo.State.SetMessage(message)
 	repo.SetWorkStatus(git.Pending)
 	j := &job.Job{
 		Repository: repo,
@@ -254,7 +621,6 @@ func (m *Model) runPushForRepo(repo *git.Repository, force bool, suppressSuccess
 		repo.SetWorkStatus(git.Available)
 		return func() tea.Msg { return errMsg{err: err} }
 	}
-	repo.SetWorkStatus(git.Queued)
 	m.jobsRunning = true
 	return m.ensureTicking()
 }
@@ -283,7 +649,9 @@ func (m *Model) confirmForcePush() tea.Cmd {
 	if repo == nil {
 		return nil
 	}
-	return m.runPushForRepo(repo, true, true, "retrying push with --force")
+	return m.confirmDestructive(i18n.T("force-push %s", repo.Name), []*git.Repository{repo}, func() tea.Cmd {
+		return m.runPushForRepo(repo, true, true, "retrying push with --force")
+	})
 }
 
 func (m *Model) startFetchForRepos(repos []*git.Repository) tea.Cmd {
@@ -308,12 +676,12 @@ func (m *Model) startFetchForRepos(repos []*git.Repository) tea.Cmd {
 			continue
 		}
 		if repo.State == nil || repo.State.Remote == nil {
-			if repo.State != nil && repo.State.Message == "" {
-				repo.State.Message = "no remote configured"
+			if repo.State != nil && repo.State.Message() == "" {
+				repo.State.SetMessage(i18n.T("no remote configured"))
 			}
 			continue
 		}
-		repo.State.Message = ""
+		repo.State.SetMessage("")
 		repo.SetWorkStatus(git.Pending)
 		eligible = append(eligible, repo)
 	}
@@ -326,12 +694,13 @@ func (m *Model) startFetchForRepos(repos []*git.Repository) tea.Cmd {
 
 func fetchRepositoriesCmd(repos []*git.Repository) tea.Cmd {
 	return func() tea.Msg {
+		batch := command.NewBatchID()
 		for _, repo := range repos {
 			opts := &command.FetchOptions{
 				RemoteName: defaultRemoteName(repo),
 				Timeout:    command.DefaultFetchTimeout,
 			}
-			j := &job.Job{JobType: job.FetchJob, Repository: repo, Options: opts}
+			j := &job.Job{JobType: job.FetchJob, Repository: repo, Options: opts, Batch: batch}
 			if err := j.Start(); err != nil {
 				command.ScheduleStateEvaluation(repo, command.OperationOutcome{
 					Operation: command.OperationFetch,
@@ -346,6 +715,28 @@ func fetchRepositoriesCmd(repos []*git.Repository) tea.Cmd {
 	}
 }
 
+// skipStartupFetch starts the interface with the local state of the
+// repositories only; they are fetched when a job asks for it.
+var skipStartupFetch atomic.Bool
+
+// SetSkipStartupFetch configures whether the initial state probe leaves out
+// the fetch.
+func SetSkipStartupFetch(skip bool) {
+	skipStartupFetch.Store(skip)
+}
+
+// startupProbeOutcome returns the outcome that starts the initial state probe
+// of repo. Without the startup fetch, which pinned repositories never get,
+// it is complete right away, and only the ahead and behind counts against
+// the last fetched upstream and the working tree are evaluated.
+func startupProbeOutcome(repo *git.Repository) command.OperationOutcome {
+	outcome := command.OperationOutcome{Operation: command.OperationStateProbe}
+	if (skipStartupFetch.Load() || command.Pinned(repo)) && repo.State != nil && repo.State.Branch != nil && repo.State.Branch.Upstream != nil {
+		outcome.Message = i18n.T("not fetched yet")
+	}
+	return outcome
+}
+
 func (m *Model) maybeStartInitialStateEvaluation(repos []*git.Repository) tea.Cmd {
 	if m.initialStateProbeStarted || m.loading || m.terminalTooSmall() {
 		return nil
@@ -361,17 +752,23 @@ func (m *Model) maybeStartInitialStateEvaluation(repos []*git.Repository) tea.Cm
 	// Set flags synchronously in the Update goroutine to avoid races.
 	m.initialStateProbeStarted = true
 	m.jobsRunning = true
-	return func() tea.Msg {
+	return tea.Batch(m.identityCheckCmd(), func() tea.Msg {
+		now := time.Now()
+		agentStatus := loadAgentStatus(now)
 		for _, repo := range filtered {
 			if repo == nil {
 				continue
 			}
 			if repo.State != nil {
-				repo.State.Message = "waiting"
+				repo.State.SetMessage(i18n.T("waiting"))
 			}
 			repo.SetWorkStatusSilent(git.Pending)
-			command.ScheduleStateEvaluation(repo, command.OperationOutcome{Operation: command.OperationStateProbe})
+			outcome, ok := agentProbeOutcome(agentStatus, repo, now)
+			if !ok {
+				outcome = startupProbeOutcome(repo)
+			}
+			command.ScheduleStateEvaluation(repo, outcome)
 		}
 		return repositoriesWaitingMsg{}
-	}
+	})
 }
@@ -0,0 +1,256 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func queueTestRepo(name string, clean, upstream, remote bool) *git.Repository {
+	repo := testRepoWithBranch(name, "main")
+	repo.SetWorkStatusSilent(git.Available)
+	repo.State.Branch.Clean = clean
+	if upstream {
+		repo.State.Branch.Upstream = &git.RemoteBranch{Name: "origin/main"}
+	}
+	if remote {
+		repo.State.Remote = &git.Remote{Name: "origin"}
+	}
+	return repo
+}
+
+func TestQueueBlockerExplainsWhyARepositoryCannotBeQueued(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   Mode
+		repo   *git.Repository
+		reason string
+	}{
+		{"eligible", pullMode, queueTestRepo("ok", true, true, true), ""},
+		{"dirty", pullMode, queueTestRepo("dirty", false, true, true), "dirty"},
+		{"no remote", pullMode, queueTestRepo("local", true, false, false), "no remote"},
+		{"no upstream", rebaseMode, queueTestRepo("untracked", true, false, true), "no upstream"},
+		{"merge needs no remote", mergeMode, queueTestRepo("merge", true, true, false), ""},
+		{"push needs no upstream", pushMode, queueTestRepo("push", true, false, true), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := Model{mode: tt.mode}
+			reason, ok := model.queueBlocker(tt.repo)
+			assert.Equal(t, tt.reason == "", ok)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestQueueAllReportsTaggedAndSkippedRepositories(t *testing.T) {
+	ok := queueTestRepo("ok", true, true, true)
+	dirty := queueTestRepo("dirty", false, true, true)
+	local := queueTestRepo("local", true, false, false)
+	busy := queueTestRepo("busy", true, true, true)
+	busy.SetWorkStatusSilent(git.Working)
+
+	model := Model{mode: pullMode, repositories: []*git.Repository{ok, dirty, local, busy}}
+	cmd := model.queueAll()
+	require.NotNil(t, cmd)
+	result, isResult := cmd().(queueResultMsg)
+	require.True(t, isResult)
+
+	assert.Equal(t, git.Queued, ok.WorkStatus())
+	assert.Equal(t, git.Available, dirty.WorkStatus())
+	assert.Equal(t, "dirty", dirty.State.Message())
+	assert.Equal(t, "no remote", local.State.Message())
+	assert.Empty(t, busy.State.Message(), "running jobs keep their message")
+	assert.Equal(t, 1, result.tagged)
+	assert.Equal(t, []queueSkip{{"dirty", 1}, {"no remote", 1}, {"busy", 1}}, result.skipped)
+
+	model.Update(result)
+	assert.Equal(t, "tagged 1, skipped 3 (1 dirty, 1 no remote, 1 busy)", model.notice)
+
+	result, _ = model.queueAll()().(queueResultMsg)
+	assert.Equal(t, 1, result.tagged, "tagged repositories stay tagged")
+}
+
+func TestReleaseDependentsWaitsForSuccessAndSkipsAfterFailure(t *testing.T) {
+	lib := queueTestRepo("lib", true, true, true)
+	tools := queueTestRepo("tools", true, true, true)
+	app := queueTestRepo("app", true, true, true)
+	docs := queueTestRepo("docs", true, true, true)
+	lib.SetWorkStatusSilent(git.Working)
+	tools.SetWorkStatusSilent(git.Working)
+	app.SetWorkStatusSilent(git.Queued)
+	docs.SetWorkStatusSilent(git.Queued)
+
+	model := Model{repositories: []*git.Repository{app, docs, lib, tools}}
+	model.Update(batchStartedMsg{waits: map[*git.Repository][]*git.Repository{
+		app:  {lib},
+		docs: {tools},
+	}})
+
+	model.releaseDependents()
+	assert.Len(t, model.dependencyWaits, 2, "dependents wait while their dependencies run")
+	assert.True(t, model.updateJobsRunningFlag())
+
+	lib.SetWorkStatusSilent(git.Success)
+	tools.SetWorkStatusSilent(git.Fail)
+	model.releaseDependents()
+	assert.Empty(t, model.dependencyWaits)
+	assert.NotEqual(t, git.Queued, app.WorkStatus(), "app is released once lib succeeded")
+	assert.Equal(t, git.Available, docs.WorkStatus())
+	assert.Equal(t, "skipped: tools did not succeed", docs.State.Message())
+}
+
+func TestJobThatCannotStartFailsAndSkipsItsDependents(t *testing.T) {
+	lib := queueTestRepo("lib", true, false, true)
+	app := queueTestRepo("app", true, true, true)
+	lib.SetWorkStatusSilent(git.Queued)
+	app.SetWorkStatusSilent(git.Queued)
+	model := Model{repositories: []*git.Repository{app, lib}, mode: pullMode}
+	model.Update(batchStartedMsg{waits: map[*git.Repository][]*git.Repository{app: {lib}}})
+
+	require.False(t, model.startBatchJob(lib, 0))
+	assert.Equal(t, git.Fail, lib.WorkStatus(), "lib leaves the queue")
+	assert.Equal(t, "cannot pull: upstream not set", lib.State.Message())
+
+	model.releaseDependents()
+	assert.Empty(t, model.dependencyWaits)
+	assert.Equal(t, git.Available, app.WorkStatus())
+	assert.Equal(t, "skipped: lib did not succeed", app.State.Message())
+	assert.False(t, model.updateJobsRunningFlag(), "the batch is over")
+}
+
+func TestCompositeModesFollowTheBuiltinModes(t *testing.T) {
+	require.NoError(t, command.SetCompositeJobs(map[string][]string{"sync": {"fetch --prune", "pull --ff-only"}}))
+	t.Cleanup(func() { _ = command.SetCompositeJobs(nil) })
+
+	model := New("sync", nil)
+	require.Equal(t, ModeID("sync"), model.mode.ID)
+	assert.Equal(t, "Sync | m: switch", model.mode.DisplayString)
+
+	reason, ok := model.queueBlocker(queueTestRepo("local", true, false, false))
+	assert.True(t, ok, "composite steps decide what they need")
+	assert.Empty(t, reason)
+
+	model.cycleMode()
+	assert.Equal(t, PullMode, model.mode.ID, "the last mode wraps around")
+}
+
+func TestTaggedRepositoryRunsItsOwnMode(t *testing.T) {
+	alpha := queueTestRepo("alpha", true, true, true)
+	beta := queueTestRepo("beta", true, true, true)
+	model := Model{mode: pullMode, repositories: []*git.Repository{alpha, beta}, cursor: 1}
+
+	model.cycleRepositoryMode()
+	assert.Equal(t, "tag beta to give it its own mode", model.notice)
+
+	require.NoError(t, model.addToQueue(alpha))
+	require.NoError(t, model.addToQueue(beta))
+	model.cycleRepositoryMode()
+	model.cycleRepositoryMode()
+	assert.Equal(t, "beta runs rebase instead of pull", model.notice)
+	assert.Equal(t, RebaseMode, model.jobMode(beta).ID)
+	assert.Equal(t, PullMode, model.jobMode(alpha).ID)
+
+	model.mode = rebaseMode
+	assert.Equal(t, RebaseMode, model.jobMode(beta).ID, "an override matching the batch mode is no override")
+	model.mode = pullMode
+
+	require.NoError(t, model.removeFromQueue(beta))
+	assert.Equal(t, PullMode, model.jobMode(beta).ID, "untagging drops the mode")
+}
+
+func TestStatusBarCountsQueuedJobsPerMode(t *testing.T) {
+	alpha := queueTestRepo("alpha", true, true, true)
+	beta := queueTestRepo("beta", true, true, true)
+	gamma := queueTestRepo("gamma", true, true, true)
+	model := Model{mode: pullMode, repositories: []*git.Repository{alpha, beta, gamma}, styles: DefaultStyles(), width: 160}
+	for _, r := range model.repositories {
+		require.NoError(t, model.addToQueue(r))
+	}
+	assert.Contains(t, ansi.Strip(model.renderStatusBar()), "tagged: 3")
+
+	model.modeOverrides.set(gamma, pushMode)
+	statusBar := ansi.Strip(model.renderStatusBar())
+	assert.Contains(t, statusBar, "2 pull, 1 push queued")
+	assert.Contains(t, statusBar, "enter: start batch")
+
+	model.modeOverrides.set(alpha, pushMode)
+	model.modeOverrides.set(beta, pushMode)
+	statusBar = ansi.Strip(model.renderStatusBar())
+	assert.Contains(t, statusBar, "tagged: 3")
+	assert.Contains(t, statusBar, "enter: start push", "the hint names the mode all jobs run in")
+}
+
+func TestProtectedBranchesWarnOrBlockPushesAndCommits(t *testing.T) {
+	ahead := queueTestRepo("ahead", true, true, true)
+	ahead.AbsPath = filepath.Join(t.TempDir(), "ahead")
+	ahead.State.Branch.Pushables = "2"
+	synced := queueTestRepo("synced", true, true, true)
+	synced.AbsPath = filepath.Join(filepath.Dir(ahead.AbsPath), "synced")
+	synced.State.Branch.Pushables = "0"
+	model := Model{mode: pushMode, repositories: []*git.Repository{ahead, synced}}
+
+	command.SetBranchProtection([]command.BranchProtectionRule{{Path: filepath.Dir(ahead.AbsPath)}})
+	t.Cleanup(func() { command.SetBranchProtection(nil) })
+	require.NoError(t, model.addToQueue(ahead))
+	require.NoError(t, model.addToQueue(synced))
+	assert.Equal(t, git.Queued, ahead.WorkStatus())
+	assert.Equal(t, "pushes to protected branch main", ahead.State.Message())
+	assert.Empty(t, synced.State.Message(), "without local commits there is nothing to warn about")
+
+	require.NoError(t, model.removeFromQueue(ahead))
+	require.NoError(t, model.removeFromQueue(synced))
+
+	command.SetBranchProtection([]command.BranchProtectionRule{{Path: filepath.Dir(ahead.AbsPath), Block: true}})
+	reason, ok := model.queueBlocker(ahead)
+	assert.False(t, ok)
+	assert.Equal(t, "main is protected", reason)
+	_, ok = model.queueBlocker(synced)
+	assert.True(t, ok)
+
+	ahead.State.Branch.Pushables = "0"
+	ahead.State.Branch.HasLocalChanges = true
+	synced.State.Branch.Name = "feature"
+	synced.State.Branch.HasLocalChanges = true
+	require.NoError(t, model.addToQueue(synced))
+	require.NoError(t, model.addToQueue(ahead))
+	model.openCommitPrompt()
+	assert.Equal(t, "not committing on protected branches in ahead", model.notice)
+	assert.Equal(t, []*git.Repository{synced}, model.commitPromptRepos)
+}
+
+func TestNoFetchCompletesTheInitialProbeLocally(t *testing.T) {
+	tracked := queueTestRepo("api", true, true, true)
+	local := queueTestRepo("scratch", true, false, false)
+
+	require.Empty(t, startupProbeOutcome(tracked).Message, "the probe fetches by default")
+
+	SetSkipStartupFetch(true)
+	t.Cleanup(func() { SetSkipStartupFetch(false) })
+	outcome := startupProbeOutcome(tracked)
+	require.Equal(t, command.OperationStateProbe, outcome.Operation)
+	require.Equal(t, "not fetched yet", outcome.Message)
+	require.Empty(t, startupProbeOutcome(local).Message, "repositories without upstream are probed as before")
+}
+
+func TestLocalDependencyAdvice_SuggestsAnOrderAndWarnsOnCycles(t *testing.T) {
+	root := t.TempDir()
+	repo := func(name string, deps ...string) *git.Repository {
+		r := &git.Repository{Name: name, AbsPath: filepath.Join(root, name)}
+		for _, dep := range deps {
+			r.LocalDependencies = append(r.LocalDependencies, filepath.Join(root, dep))
+		}
+		return r
+	}
+	app, lib, docs := repo("app", "lib"), repo("lib"), repo("docs")
+	require.Empty(t, localDependencyAdvice([]*git.Repository{lib, docs}))
+	require.Equal(t, "local dependencies suggest the order lib, app", localDependencyAdvice([]*git.Repository{app, docs, lib}))
+
+	lib.LocalDependencies = []string{app.AbsPath}
+	require.Equal(t, "warning: local dependencies: dependency cycle: app -> lib -> app", localDependencyAdvice([]*git.Repository{app, lib}))
+}
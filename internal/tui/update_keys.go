@@ -3,13 +3,31 @@ package tui
 import (
 	"os/exec"
 	"sort"
+	"strconv"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
 )
 
 func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
+	m.notice = ""
+
+	if handled, cmd := m.handleAskpassKey(msg); handled {
+		return m, cmd
+	}
+
+	if handled, cmd := m.handleConfirmPromptKey(msg); handled {
+		return m, cmd
+	}
+
+	if m.branchSwitcherActive {
+		handled, cmd := m.handleBranchSwitcherKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
 
 	if m.commitPromptActive {
 		handled, cmd := m.handleCommitPromptKey(msg)
@@ -39,6 +57,90 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if m.comparePromptActive {
+		handled, cmd := m.handleComparePromptKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
+
+	if m.grepPromptActive {
+		handled, cmd := m.handleGrepPromptKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
+
+	if m.urlRewritePromptActive {
+		handled, cmd := m.handleURLRewritePromptKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
+
+	if m.patchPromptActive {
+		handled, cmd := m.handlePatchPromptKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
+
+	if m.rebaseOntoPromptActive {
+		handled, cmd := m.handleRebaseOntoPromptKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
+
+	if m.remotePromptActive {
+		handled, cmd := m.handleRemotePromptKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
+
+	if m.problemsActive {
+		handled, cmd := m.handleProblemsKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
+
+	if m.forecastActive {
+		handled, cmd := m.handleForecastKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
+
+	if m.cleanActive {
+		handled, cmd := m.handleCleanKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
+
+	if m.patchActive {
+		handled, cmd := m.handlePatchResultsKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
+
+	if m.bisectActive {
+		handled, cmd := m.handleBisectKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
+
+	if m.grepActive {
+		handled, cmd := m.handleGrepKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
+
 	if m.activeCredentialPrompt != nil {
 		handled, cmd := m.handleCredentialPromptKey(msg)
 		if handled {
@@ -58,19 +160,52 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if m.activeCheckoutPrompt != nil {
+		switch key {
+		case "y", "Y", "enter":
+			return m, m.confirmCheckoutStash()
+		case "n", "N", "esc":
+			m.abortCheckout()
+			return m, nil
+		default:
+			return m, nil
+		}
+	}
+
+	if m.autoModePromptActive {
+		return m, m.handleAutoModePromptKey(key)
+	}
+
+	if m.activeLockPrompt != nil {
+		switch key {
+		case "y", "Y", "enter":
+			return m, m.confirmLockRemoval()
+		case "n", "N", "esc":
+			m.activeLockPrompt = nil
+			return m, nil
+		default:
+			return m, nil
+		}
+	}
+
+	if handled, cmd := m.handleHelpKey(msg); handled {
+		return m, cmd
+	}
+	if handled, cmd := m.handleDiffKey(msg); handled {
+		return m, cmd
+	}
+
 	switch key {
 	case "ctrl+c", "q":
 		return m, tea.Quit
 
 	case "?":
-		m.showHelp = !m.showHelp
+		m.countPrefix = ""
+		m.showHelp = true
 		return m, nil
 
 	case "esc":
-		if m.showHelp {
-			m.showHelp = false
-			return m, nil
-		}
+		m.countPrefix = ""
 		if m.sidePanel != NonePanel {
 			m.sidePanel = NonePanel
 			m.clearSuccessFormatting()
@@ -81,18 +216,17 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "tab":
 		if r := m.currentRepository(); r != nil && isLazygitAvailable() {
-			var savedState git.RepositoryState
+			var savedState *git.RepositoryState
 			if r.State != nil {
-				savedState = *r.State
+				savedState = r.State.Copy()
 			}
 			r.SetWorkStatus(git.Working)
-			r.RefreshModTime()
-			originalModTime := r.ModTime
+			before := r.TakeSnapshot()
 			cmd := tea.ExecProcess(exec.Command("lazygit", "-p", r.AbsPath), func(err error) tea.Msg {
 				if err != nil {
 					return errMsg{err: err}
 				}
-				return lazygitClosedMsg{repo: r, originalModTime: originalModTime, originalState: savedState}
+				return lazygitClosedMsg{repo: r, before: before, originalState: savedState}
 			})
 			if m.updateJobsRunningFlag() {
 				return m, tea.Batch(cmd, m.ensureTicking())
@@ -109,202 +243,40 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) handleOverviewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "up", "k":
-		if m.overviewRowCount() == 0 {
-			return m, nil
-		}
-		m.moveOverviewCursor(-1)
-		m.resetCommitScrollForSelected()
-		return m, nil
-
-	case "down", "j":
-		if m.overviewRowCount() == 0 {
-			return m, nil
-		}
-		m.moveOverviewCursor(1)
-		m.resetCommitScrollForSelected()
-		return m, nil
-
-	case "g":
-		m.cursor = m.firstSelectableIndex()
-		m.resetCommitScrollForSelected()
-
-	case "G":
-		m.cursor = m.findLastNavigableIndex()
-		m.resetCommitScrollForSelected()
-
-	case "home":
-		m.cursor = m.firstSelectableIndex()
-		m.resetCommitScrollForSelected()
-
-	case "end":
-		m.cursor = m.findLastNavigableIndex()
-		m.resetCommitScrollForSelected()
-
-	case "ctrl+f", "pgdown":
-		count := m.overviewRowCount()
-		if count == 0 {
-			return m, nil
-		}
-		pageSize := m.height - 5
-		m.cursor = m.closestSelectableIndex(clampIndex(m.cursor+pageSize, count), 1)
-		m.resetCommitScrollForSelected()
-
-	case "ctrl+b", "pgup":
-		count := m.overviewRowCount()
-		if count == 0 {
-			return m, nil
-		}
-		pageSize := m.height - 5
-		m.cursor = m.closestSelectableIndex(clampIndex(m.cursor-pageSize, count), -1)
-		m.resetCommitScrollForSelected()
-
-	case "ctrl+d":
-		count := m.overviewRowCount()
-		if count == 0 {
-			return m, nil
-		}
-		halfPage := (m.height - 5) / 2
-		m.cursor = m.closestSelectableIndex(clampIndex(m.cursor+halfPage, count), 1)
-		m.resetCommitScrollForSelected()
-
-	case "ctrl+u":
-		count := m.overviewRowCount()
-		if count == 0 {
-			return m, nil
-		}
-		halfPage := (m.height - 5) / 2
-		m.cursor = m.closestSelectableIndex(clampIndex(m.cursor-halfPage, count), -1)
-		m.resetCommitScrollForSelected()
-
-	case "right", "l":
-		if m.adjustCommitScroll(12) {
-			return m, nil
-		}
-
-	case "left", "h":
-		if m.adjustCommitScroll(-12) {
-			return m, nil
-		}
-
-	case " ", "space":
-		return m, m.toggleQueue()
-
-	case "a":
-		return m, m.queueAll()
-
-	case "A":
-		return m, m.unqueueAll()
-
-	case "enter":
-		repo := m.currentRepository()
-		if repo != nil && repo.State != nil && repo.State.RequiresCredentials {
-			ws := repo.WorkStatus()
-			if ws != git.Working && ws != git.Queued && ws != git.Pending {
-				m.openCredentialDialog(repo)
-				return m, nil
-			}
-		}
-		return m, m.startQueue()
-
-	case "f":
-		repo := m.currentRepository()
-		if repo == nil || !repoIsActionable(repo) {
-			return m, nil
-		}
-		return m, m.runFetchForRepo(repo)
-
-	case "p":
-		repo := m.currentRepository()
-		if repo == nil || !repoIsActionable(repo) {
-			return m, nil
-		}
-		return m, m.runPullForRepo(repo, true)
-
-	case "P":
-		repo := m.currentRepository()
-		if repo == nil || !repoIsActionable(repo) {
-			return m, nil
-		}
-		return m, m.runPushForRepo(repo, false, true, "push queued")
-
-	case "m":
-		m.cycleMode()
-
-	case "W":
-		m.toggleWorktreeMode()
-
-	case "b":
-		m.activatePanel(BranchPanel)
-
-	case "B":
-		m.expandBranches = !m.expandBranches
-
-	case "c":
-		if m.err != nil {
-			m.err = nil
-			return m, nil
-		}
-		if repo := m.currentRepository(); repo != nil && repo.State != nil && repo.WorkStatus() == git.Fail {
-			repo.State.Message = ""
-			return m, nil
-		}
-		m.openCommitPrompt()
-		return m, nil
-
-	case "d":
-		if m.worktreeMode {
-			return m, m.deleteSelectedWorktreeCmd()
-		}
-
-	case "X":
-		if m.worktreeMode {
-			return m, m.pruneWorktreesCmd()
-		}
-
-	case "L":
-		if m.worktreeMode {
-			return m, m.toggleWorktreeLockCmd()
-		}
-
-	case "r":
-		m.activatePanel(RemotePanel)
-
-	case "R":
-		return m, m.focusRefreshCmd(true)
-
-	case "s":
-		if !m.requiresSingleSelection("Status view unavailable for tagged selection") {
-			return m, nil
-		}
-		m.activatePanel(StatusPanel)
-
-	case "S":
-		m.openStashPrompt()
-		return m, nil
-
-	case "O":
-		m.openStashAction(stashActionPop)
-		return m, nil
-
-	case "D":
-		m.openStashAction(stashActionDrop)
+	key := msg.String()
+	if isCountKey(key, m.countPrefix) {
+		m.countPrefix += key
 		return m, nil
+	}
+	count := m.takeCountPrefix()
 
-	case "n":
-		if m.worktreeMode {
-			m.openWorktreePrompt()
-			return m, nil
-		}
-		m.openBranchPrompt()
-		return m, nil
+	if action, ok := overviewActions[key]; ok {
+		return m, action(m, count)
+	}
+	return m, nil
+}
 
-	case "t":
-		m.toggleRepositorySort()
+// isCountKey reports whether key extends a numeric prefix such as the "5" in
+// "5<enter>". A prefix cannot start with zero.
+func isCountKey(key, prefix string) bool {
+	if len(key) != 1 || key[0] < '0' || key[0] > '9' {
+		return false
 	}
+	return key != "0" || prefix != ""
+}
 
-	return m, nil
+// takeCountPrefix returns the typed numeric prefix and clears it; 0 means
+// none was typed.
+func (m *Model) takeCountPrefix() int {
+	if m.countPrefix == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(m.countPrefix)
+	m.countPrefix = ""
+	if err != nil {
+		return 0
+	}
+	return n
 }
 
 func (m *Model) handleFocusKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -313,7 +285,20 @@ func (m *Model) handleFocusKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "esc", "backspace":
 		m.sidePanel = NonePanel
 		return m, nil
-	case "enter":
+	case "ctrl+z":
+		return m, m.undoCmd(m.currentRepository(), m.sidePanel)
+	}
+	switch m.sidePanel {
+	case StatusPanel:
+		return m.handleStatusPanelKey(key)
+	case ReflogPanel:
+		return m.handleReflogPanelKey(key)
+	case HistoryPanel:
+		return m.handleHistoryPanelKey(key)
+	case StashActionPanel:
+		return m.handleStashActionPanelKey(key)
+	}
+	if key == "enter" {
 		return m, m.startQueue()
 	}
 	switch m.sidePanel {
@@ -321,8 +306,6 @@ func (m *Model) handleFocusKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleBranchPanelKey(key)
 	case RemotePanel:
 		return m.handleRemotePanelKey(key)
-	case StashActionPanel:
-		return m.handleStashActionPanelKey(key)
 	default:
 		return m, nil
 	}
@@ -333,9 +316,11 @@ func (m *Model) cycleMode() {
 	if repo != nil && repo.IsLinkedWorktree() {
 		return
 	}
-	for i, mode := range modes {
+	m.autoModeChoice = nil
+	available := availableModes()
+	for i, mode := range available {
 		if mode.ID == m.mode.ID {
-			m.mode = modes[(i+1)%len(modes)]
+			m.mode = available[(i+1)%len(available)]
 			return
 		}
 	}
@@ -351,21 +336,52 @@ func (m *Model) sortByTime() {
 	m.applyRepositorySort()
 }
 
-func (m *Model) toggleRepositorySort() {
-	if m.sortMode == repositorySortByTime {
+// sortBySize orders by disk usage and collects the statistics of the
+// repositories that have none yet.
+func (m *Model) sortBySize() tea.Cmd {
+	m.sortMode = repositorySortBySize
+	m.applyRepositorySort()
+	return m.missingObjectStatsCmd()
+}
+
+// sortByBehind puts the repositories with the most incoming commits first.
+func (m *Model) sortByBehind() {
+	m.sortMode = repositorySortByBehind
+	m.applyRepositorySort()
+}
+
+// toggleRepositorySort cycles through name, time, size and behind order.
+func (m *Model) toggleRepositorySort() tea.Cmd {
+	// The age column switches between commit age and size.
+	m.cachedWidth = 0
+	switch m.sortMode {
+	case repositorySortByName:
+		m.sortByTime()
+	case repositorySortByTime:
+		return m.sortBySize()
+	case repositorySortBySize:
+		m.sortByBehind()
+	default:
 		m.sortByName()
-		return
 	}
-	m.sortByTime()
+	return nil
 }
 
 func (m *Model) applyRepositorySort() {
 	switch m.sortMode {
 	case repositorySortByTime:
 		sort.Sort(git.LastModified(m.repositories))
+	case repositorySortBySize:
+		m.sortRepositoriesBySize()
+	case repositorySortByBehind:
+		m.sortRepositoriesByBehind()
 	default:
 		sort.Sort(git.Alphabetical(m.repositories))
 	}
+	// Pinned repositories stay at the top in every order.
+	sort.SliceStable(m.repositories, func(i, j int) bool {
+		return command.Pinned(m.repositories[i]) && !command.Pinned(m.repositories[j])
+	})
 }
 
 func (m *Model) clearSuccessFormatting() {
@@ -391,13 +407,13 @@ func (m *Model) notifyMultiSelectionRestriction(message string) {
 	if len(repos) == 0 {
 		repo := m.currentRepository()
 		if repo != nil && repo.State != nil {
-			repo.State.Message = message
+			repo.State.SetMessage(message)
 		}
 		return
 	}
 	for _, repo := range repos {
 		if repo != nil && repo.State != nil {
-			repo.State.Message = message
+			repo.State.SetMessage(message)
 		}
 	}
 }
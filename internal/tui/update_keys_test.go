@@ -27,6 +27,19 @@ func TestHandleOverviewKeys_TTogglesRepositorySort(t *testing.T) {
 
 	updated, cmd = model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
 
+	require.Same(t, &model, updated)
+	require.NotNil(t, cmd, "sorting by size collects the missing statistics")
+	require.Equal(t, repositorySortBySize, model.sortMode)
+	require.Equal(t, []*git.Repository{alpha, beta}, model.repositories)
+
+	updated, cmd = model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+
+	require.Same(t, &model, updated)
+	require.Nil(t, cmd)
+	require.Equal(t, repositorySortByBehind, model.sortMode)
+
+	updated, cmd = model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+
 	require.Same(t, &model, updated)
 	require.Nil(t, cmd)
 	require.Equal(t, repositorySortByName, model.sortMode)
@@ -93,3 +106,63 @@ func TestRenderStatusBar_ShowsBranchHintInOverview(t *testing.T) {
 	require.Contains(t, statusBar, "n branch")
 	require.NotContains(t, statusBar, "n worktree")
 }
+
+func TestHandleOverviewKeys_XPrunesRemotes(t *testing.T) {
+	repo := &git.Repository{
+		Name: "alpha",
+		State: &git.RepositoryState{
+			Branch: &git.Branch{Name: "main"},
+		},
+	}
+
+	model := Model{repositories: []*git.Repository{repo}}
+
+	_, cmd := model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+
+	// Repositories without a remote are skipped rather than failing the batch.
+	require.Nil(t, cmd)
+	require.Equal(t, "no repository with a remote selected", model.notice)
+	require.Empty(t, repo.State.Message())
+	require.NotEqual(t, git.Pending, repo.WorkStatus())
+}
+
+func TestHandleOverviewKeys_CountPrefixStartsFirstNJobs(t *testing.T) {
+	repos := []*git.Repository{
+		initBranchCreationRepo(t, "alpha"),
+		initBranchCreationRepo(t, "beta"),
+		initBranchCreationRepo(t, "gamma"),
+	}
+	model := New("push", nil)
+	model.repositories = repos
+	for _, repo := range repos {
+		repo.SetWorkStatusSilent(git.Queued)
+	}
+
+	model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'0'}})
+	require.Empty(t, model.countPrefix)
+	model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}})
+	require.Equal(t, "2", model.countPrefix)
+
+	_, cmd := model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Empty(t, model.countPrefix)
+	require.NotNil(t, cmd)
+	cmd()
+
+	require.NotEqual(t, git.Queued, repos[0].WorkStatus())
+	require.NotEqual(t, git.Queued, repos[1].WorkStatus())
+	require.Equal(t, git.Queued, repos[2].WorkStatus())
+	require.Eventually(t, func() bool {
+		return !repos[0].WorkStatus().InFlight() && !repos[1].WorkStatus().InFlight()
+	}, 10*time.Second, 20*time.Millisecond)
+}
+
+func TestHandleOverviewKeys_OtherKeyDropsCountPrefix(t *testing.T) {
+	model := Model{repositories: []*git.Repository{{Name: "alpha"}}}
+
+	model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+	model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'0'}})
+	require.Equal(t, "10", model.countPrefix)
+
+	model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	require.Empty(t, model.countPrefix)
+}
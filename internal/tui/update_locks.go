@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// repoBlockedByLock reports whether the last operation of repo failed because
+// git found a lock file.
+func repoBlockedByLock(repo *git.Repository) bool {
+	if repo == nil || repo.State == nil || repo.WorkStatus() != git.Fail {
+		return false
+	}
+	return strings.Contains(repo.State.Message(), gerr.ErrLockFileExists.Error())
+}
+
+// openLockPrompt asks for confirmation before removing the lock files of repo.
+// When the lock is already gone the failure is cleared right away.
+func (m *Model) openLockPrompt(repo *git.Repository) tea.Cmd {
+	locks := repo.LockFiles()
+	if len(locks) == 0 {
+		m.notice = "lock file is gone, refreshing repository"
+		return m.clearLockFailure(repo)
+	}
+	stale := len(repo.StaleLockFiles()) == len(locks)
+	m.activeLockPrompt = &lockPrompt{repo: repo, locks: locks, stale: stale, unknown: !git.LockHoldersKnown()}
+	return nil
+}
+
+// confirmLockRemoval deletes the lock files of the active prompt and retries
+// the operation that failed on them, or refreshes the repository when there is
+// none to retry.
+func (m *Model) confirmLockRemoval() tea.Cmd {
+	prompt := m.activeLockPrompt
+	m.activeLockPrompt = nil
+	if prompt == nil || prompt.repo == nil {
+		return nil
+	}
+	if err := git.RemoveLockFiles(prompt.locks); err != nil {
+		m.err = err
+		return nil
+	}
+	m.notice = fmt.Sprintf("removed %d lock file(s) in %s", len(prompt.locks), prompt.repo.Name)
+	if prompt.repo.State != nil {
+		prompt.repo.State.SetMessage("")
+	}
+	prompt.repo.SetWorkStatus(git.Pending)
+	retried, err := command.RetryLockedOperation(prompt.repo)
+	if err != nil {
+		m.err = err
+	}
+	if !retried {
+		return m.clearLockFailure(prompt.repo)
+	}
+	m.jobsRunning = true
+	return m.ensureTicking()
+}
+
+func (m *Model) clearLockFailure(repo *git.Repository) tea.Cmd {
+	if repo.State != nil {
+		repo.State.SetMessage("")
+	}
+	repo.SetWorkStatus(git.Available)
+	return func() tea.Msg {
+		command.RequestExternalRefresh(repo)
+		return nil
+	}
+}
+
+// lockPromptText describes the lock files of the active prompt for the
+// status bar.
+func (m *Model) lockPromptText() string {
+	prompt := m.activeLockPrompt
+	if prompt == nil || len(prompt.locks) == 0 {
+		return ""
+	}
+	oldest := prompt.locks[0]
+	for _, lock := range prompt.locks[1:] {
+		if lock.ModTime.Before(oldest.ModTime) {
+			oldest = lock
+		}
+	}
+	name := filepath.Base(oldest.Path)
+	if len(prompt.locks) > 1 {
+		name = i18n.T("%s and %d more", name, len(prompt.locks)-1)
+	}
+	age := oldest.Age().Round(time.Second)
+	if prompt.unknown {
+		return i18n.T("Remove %s (%s old)? gitbatch cannot tell whether a git process still uses it.", name, age)
+	}
+	if !prompt.stale {
+		return i18n.T("Remove %s (%s old)? A git process is still running in this repository!", name, age)
+	}
+	return i18n.T("Remove stale %s (%s old) and retry?", name, age)
+}
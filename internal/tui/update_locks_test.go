@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestLockPrompt_RemovesStaleIndexLock(t *testing.T) {
+	dir := t.TempDir()
+	gitDir := filepath.Join(dir, ".git")
+	require.NoError(t, os.MkdirAll(gitDir, 0o755))
+	lockPath := filepath.Join(gitDir, "index.lock")
+	require.NoError(t, os.WriteFile(lockPath, nil, 0o644))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(lockPath, old, old))
+
+	repo := &git.Repository{RepoID: "alpha", Name: "alpha", AbsPath: dir, State: &git.RepositoryState{}}
+	repo.ApplyOperationError(gerr.ErrLockFileExists)
+	require.True(t, repoBlockedByLock(repo))
+
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 120, height: 30}
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")})
+	require.NotNil(t, m.activeLockPrompt)
+	require.True(t, m.activeLockPrompt.stale)
+	require.Contains(t, m.lockPromptText(), "stale index.lock")
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Nil(t, m.activeLockPrompt)
+	require.NoFileExists(t, lockPath)
+	require.Equal(t, git.Available, repo.WorkStatus())
+	require.Empty(t, repo.State.Message())
+}
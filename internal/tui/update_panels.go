@@ -1,17 +1,23 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/thorstenhirsch/gitbatch/internal/command"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+	"github.com/thorstenhirsch/gitbatch/internal/job"
 )
 
 // activatePanel switches to the given side panel (or back to overview for NonePanel).
 func (m *Model) activatePanel(panel SidePanelType) {
 	m.sidePanel = panel
 	if panel == NonePanel {
+		m.failureDetail = nil
 		return
 	}
 
@@ -20,6 +26,17 @@ func (m *Model) activatePanel(panel SidePanelType) {
 	if panel == StatusPanel && repo != nil && !repo.WorkStatus().InFlight() {
 		command.RequestExternalRefresh(repo)
 	}
+	if panel == StatusPanel {
+		m.statusFiles = nil
+		m.statusFileCursor = 0
+		m.loadObjectStats(repo)
+	}
+	if panel == ReflogPanel {
+		m.loadReflogEntries(repo)
+	}
+	if panel == HistoryPanel {
+		m.loadHistoryEntries(repo)
+	}
 
 	switch panel {
 	case BranchPanel:
@@ -139,7 +156,7 @@ func (m *Model) handleBranchPanelKey(key string) (tea.Model, tea.Cmd) {
 		}
 		repo := repos[0]
 		if repo.State != nil && repo.State.Branch != nil && repo.State.Branch.Name == branchName {
-			repo.State.Message = "cannot delete current branch"
+			repo.State.SetMessage(i18n.T("cannot delete current branch"))
 			break
 		}
 		branch := findBranchByName(repo, branchName)
@@ -151,6 +168,21 @@ func (m *Model) handleBranchPanelKey(key string) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) handleRemotePanelKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "x":
+		return m, m.pruneRemotesCmd(m.panelRepositories())
+	case "Y":
+		return m, m.syncForksCmd(m.panelRepositories())
+	case "e":
+		m.openURLRewritePrompt(m.panelRepositories())
+		return m, nil
+	case "a":
+		if !m.hasMultipleTagged() {
+			m.openRemotePrompt(m.currentRepository())
+		}
+		return m, nil
+	}
+
 	items := m.remotePanelItems()
 	count := len(items)
 	if count == 0 {
@@ -182,6 +214,9 @@ func (m *Model) handleRemotePanelKey(key string) (tea.Model, tea.Cmd) {
 		if len(repos) > 0 {
 			cmd = m.checkoutRemoteBranchCmd(repos[0], entry)
 		}
+	case "u":
+		entry := items[clampIndex(m.remoteBranchCursor, count)]
+		cmd = m.setUpstreamCmd(m.panelRepositories(), entry)
 	case "d":
 		entry := items[clampIndex(m.remoteBranchCursor, count)]
 		if m.hasMultipleTagged() {
@@ -200,40 +235,188 @@ func (m *Model) handleRemotePanelKey(key string) (tea.Model, tea.Cmd) {
 
 // --- Git operation commands ---
 
-func (m *Model) checkoutBranchCmd(repo *git.Repository, branch *git.Branch) tea.Cmd {
-	if repo == nil || branch == nil {
+// panelJob is a git operation a side panel starts in one repository.
+type panelJob struct {
+	repo    *git.Repository
+	jobType job.Type
+	options any
+	message string
+}
+
+// startPanelJobs hands the operations of a panel to the git queue of their
+// repositories, so they get the timeouts, lock handling and error
+// classification of the batch jobs. The queue runs them concurrently and each
+// repository shows its result once it has been evaluated. An operation in
+// several repositories reports its progress and a combined result as notice.
+func (m *Model) startPanelJobs(jobs []panelJob, panel SidePanelType, closePanel bool) tea.Cmd {
+	if len(jobs) == 0 {
 		return nil
 	}
+	m.jobsRunning = true
+	for _, pj := range jobs {
+		pj.repo.State.SetMessage(pj.message)
+		pj.repo.SetWorkStatus(git.Pending)
+	}
+	var batch *panelBatch
+	var batchID command.BatchID
+	if len(jobs) > 1 {
+		batch = newPanelBatch(jobs)
+		batchID = command.NewBatchID()
+		m.panelBatch = batch
+		m.notice = batch.progress()
+	}
 	return func() tea.Msg {
-		repo.State.Message = fmt.Sprintf("checking out %s", branch.Name)
-		if err := repo.Checkout(branch); err != nil {
-			repo.State.Message = err.Error()
-			return errMsg{err: fmt.Errorf("checkout branch %s: %w", branch.Name, err)}
+		var errs []error
+		for _, pj := range jobs {
+			j := &job.Job{Repository: pj.repo, JobType: pj.jobType, Options: pj.options, Batch: batchID}
+			if err := j.Start(); err != nil {
+				pj.repo.SetWorkStatus(git.Available)
+				pj.repo.State.SetMessage(err.Error())
+				batch.notStarted(pj.repo)
+				errs = append(errs, fmt.Errorf("%s in %s: %w", pj.message, pj.repo.Name, err))
+			}
 		}
-		repo.State.Message = fmt.Sprintf("switched to %s", branch.Name)
-		if err := scheduleRefresh(repo); err != nil {
-			return errMsg{err: err}
+		if len(errs) > 0 {
+			return errMsg{err: errors.Join(errs...)}
 		}
-		return repoActionResultMsg{panel: BranchPanel}
+		return repoActionResultMsg{panel: panel, closePanel: closePanel}
+	}
+}
+
+// panelBatch follows a panel operation started in several repositories.
+type panelBatch struct {
+	label string
+	repos []*git.Repository
+
+	mu sync.Mutex
+	// failed holds the repositories whose job could not be started.
+	failed map[*git.Repository]bool
+}
+
+func newPanelBatch(jobs []panelJob) *panelBatch {
+	b := &panelBatch{label: jobs[0].message, failed: make(map[*git.Repository]bool)}
+	for _, pj := range jobs {
+		b.repos = append(b.repos, pj.repo)
+	}
+	return b
+}
+
+func (b *panelBatch) notStarted(r *git.Repository) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.failed[r] = true
+	b.mu.Unlock()
+}
+
+// tally counts the repositories that are done and names those that failed.
+func (b *panelBatch) tally() (int, []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	done := 0
+	var failed []string
+	for _, r := range b.repos {
+		status := r.WorkStatus()
+		if status.InFlight() && !b.failed[r] {
+			continue
+		}
+		done++
+		if status == git.Fail || b.failed[r] {
+			failed = append(failed, r.Name)
+		}
+	}
+	return done, failed
+}
+
+func (b *panelBatch) progress() string {
+	done, _ := b.tally()
+	return fmt.Sprintf("%s in %d repos: %d done", b.label, len(b.repos), done)
+}
+
+// updatePanelBatch shows the progress of the running panel batch, and its
+// combined result once every repository is done.
+func (m *Model) updatePanelBatch() {
+	b := m.panelBatch
+	if b == nil {
+		return
+	}
+	done, failed := b.tally()
+	if done < len(b.repos) {
+		m.notice = b.progress()
+		return
+	}
+	m.panelBatch = nil
+	if len(failed) == 0 {
+		m.notice = fmt.Sprintf("%s in %d repos: all done", b.label, len(b.repos))
+		return
+	}
+	m.notice = fmt.Sprintf("%s in %d repos: %d succeeded, failed in %s, press ! to review", b.label, len(b.repos), len(b.repos)-len(failed), strings.Join(failed, ", "))
+}
+
+func checkoutJob(repo *git.Repository, branchName string) panelJob {
+	return panelJob{
+		repo:    repo,
+		jobType: job.CheckoutJob,
+		options: &command.CheckoutOptions{Branch: branchName},
+		message: fmt.Sprintf("checking out %s", branchName),
+	}
+}
+
+// remoteCheckoutJob switches to the local branch of a remote branch, and
+// creates it from the remote branch if it does not exist yet.
+func remoteCheckoutJob(repo *git.Repository, entry remotePanelEntry) panelJob {
+	pj := checkoutJob(repo, entry.BranchName)
+	if findBranchByName(repo, entry.BranchName) == nil {
+		pj.options = &command.CheckoutOptions{Branch: entry.BranchName, StartPoint: entry.FullName}
+	}
+	return pj
+}
+
+func deleteBranchJob(repo *git.Repository, remote, branchName string) panelJob {
+	name := branchName
+	if remote != "" {
+		name = remote + "/" + branchName
+	}
+	return panelJob{
+		repo:    repo,
+		jobType: job.DeleteBranchJob,
+		options: &command.DeleteBranchOptions{Branch: branchName, Remote: remote},
+		message: fmt.Sprintf("deleting %s", name),
+	}
+}
+
+func (m *Model) checkoutBranchCmd(repo *git.Repository, branch *git.Branch) tea.Cmd {
+	if repo == nil || branch == nil {
+		return nil
 	}
+	return m.startCheckoutJobs([]panelJob{checkoutJob(repo, branch.Name)}, BranchPanel, false)
 }
 
 func (m *Model) deleteBranchCmd(repo *git.Repository, branch *git.Branch) tea.Cmd {
 	if repo == nil || branch == nil {
 		return nil
 	}
-	return func() tea.Msg {
-		repo.State.Message = fmt.Sprintf("deleting %s", branch.Name)
-		if _, err := command.Run(repo.AbsPath, "git", []string{"branch", "-d", branch.Name}); err != nil {
-			repo.State.Message = err.Error()
-			return errMsg{err: fmt.Errorf("delete branch %s: %w", branch.Name, err)}
-		}
-		repo.State.Message = fmt.Sprintf("deleted %s", branch.Name)
-		if err := scheduleRefresh(repo); err != nil {
-			return errMsg{err: err}
-		}
-		return repoActionResultMsg{panel: BranchPanel}
+	return m.confirmDestructive(i18n.T("delete branch %s", branch.Name), []*git.Repository{repo}, func() tea.Cmd {
+		return m.startPanelJobs([]panelJob{deleteBranchJob(repo, "", branch.Name)}, BranchPanel, false)
+	})
+}
+
+// undoCmd reverts the most recent checkout or branch deletion in repo.
+func (m *Model) undoCmd(repo *git.Repository, panel SidePanelType) tea.Cmd {
+	if repo == nil {
+		return nil
 	}
+	entry, ok := command.PeekUndo(repo)
+	if !ok {
+		m.notice = "nothing to undo in " + repo.Name
+		return nil
+	}
+	return m.startPanelJobs([]panelJob{{
+		repo:    repo,
+		jobType: job.UndoJob,
+		message: fmt.Sprintf("undoing %s", entry.Description),
+	}}, panel, false)
 }
 
 func (m *Model) checkoutBranchMultiCmd(repos []*git.Repository, branchName string) tea.Cmd {
@@ -241,29 +424,15 @@ func (m *Model) checkoutBranchMultiCmd(repos []*git.Repository, branchName strin
 	if len(filtered) == 0 || branchName == "" {
 		return nil
 	}
-	return func() tea.Msg {
-		branchLookup := make(map[*git.Repository]*git.Branch, len(filtered))
-		for _, repo := range filtered {
-			branch := findBranchByName(repo, branchName)
-			if branch == nil {
-				repo.State.Message = fmt.Sprintf("branch %s not found", branchName)
-				return repoActionResultMsg{panel: BranchPanel}
-			}
-			branchLookup[repo] = branch
+	jobs := make([]panelJob, 0, len(filtered))
+	for _, repo := range filtered {
+		if findBranchByName(repo, branchName) == nil {
+			repo.State.SetMessage(fmt.Sprintf("branch %s not found", branchName))
+			return nil
 		}
-		for _, repo := range filtered {
-			repo.State.Message = fmt.Sprintf("checking out %s", branchName)
-			if err := repo.Checkout(branchLookup[repo]); err != nil {
-				repo.State.Message = err.Error()
-				return errMsg{err: fmt.Errorf("checkout branch %s in %s: %w", branchName, repo.Name, err)}
-			}
-			repo.State.Message = fmt.Sprintf("switched to %s", branchName)
-			if err := scheduleRefresh(repo); err != nil {
-				return errMsg{err: fmt.Errorf("refresh repository %s: %w", repo.Name, err)}
-			}
-		}
-		return repoActionResultMsg{panel: BranchPanel, closePanel: true}
+		jobs = append(jobs, checkoutJob(repo, branchName))
 	}
+	return m.startCheckoutJobs(jobs, BranchPanel, true)
 }
 
 func (m *Model) deleteBranchMultiCmd(repos []*git.Repository, branchName string) tea.Cmd {
@@ -271,72 +440,33 @@ func (m *Model) deleteBranchMultiCmd(repos []*git.Repository, branchName string)
 	if len(filtered) == 0 || branchName == "" {
 		return nil
 	}
-	return func() tea.Msg {
-		for _, repo := range filtered {
-			if repo.State != nil && repo.State.Branch != nil && repo.State.Branch.Name == branchName {
-				repo.State.Message = fmt.Sprintf("cannot delete current branch in %s", repo.Name)
-				return repoActionResultMsg{panel: BranchPanel}
-			}
-		}
-		for _, repo := range filtered {
-			repo.State.Message = fmt.Sprintf("deleting %s", branchName)
-			if _, err := command.Run(repo.AbsPath, "git", []string{"branch", "-d", branchName}); err != nil {
-				repo.State.Message = err.Error()
-				return errMsg{err: fmt.Errorf("delete branch %s in %s: %w", branchName, repo.Name, err)}
-			}
-			repo.State.Message = fmt.Sprintf("deleted %s", branchName)
-			if err := scheduleRefresh(repo); err != nil {
-				return errMsg{err: fmt.Errorf("refresh repository %s: %w", repo.Name, err)}
-			}
+	jobs := make([]panelJob, 0, len(filtered))
+	for _, repo := range filtered {
+		if repo.State != nil && repo.State.Branch != nil && repo.State.Branch.Name == branchName {
+			repo.State.SetMessage(fmt.Sprintf("cannot delete current branch in %s", repo.Name))
+			return nil
 		}
-		return repoActionResultMsg{panel: BranchPanel}
+		jobs = append(jobs, deleteBranchJob(repo, "", branchName))
 	}
+	return m.confirmDestructive(i18n.T("delete branch %s", branchName), filtered, func() tea.Cmd {
+		return m.startPanelJobs(jobs, BranchPanel, false)
+	})
 }
 
 func (m *Model) checkoutRemoteBranchCmd(repo *git.Repository, entry remotePanelEntry) tea.Cmd {
 	if repo == nil || entry.FullName == "" {
 		return nil
 	}
-	return func() tea.Msg {
-		branchName := entry.BranchName
-		repo.State.Message = fmt.Sprintf("checking out %s", branchName)
-		if existing := findBranchByName(repo, branchName); existing != nil {
-			if err := repo.Checkout(existing); err != nil {
-				repo.State.Message = err.Error()
-				return errMsg{err: fmt.Errorf("checkout branch %s: %w", existing.Name, err)}
-			}
-		} else {
-			args := []string{"checkout", "-b", branchName, entry.FullName}
-			if _, err := command.Run(repo.AbsPath, "git", args); err != nil {
-				repo.State.Message = err.Error()
-				return errMsg{err: fmt.Errorf("create branch %s from %s: %w", branchName, entry.FullName, err)}
-			}
-		}
-		repo.State.Message = fmt.Sprintf("switched to %s", branchName)
-		if err := scheduleRefresh(repo); err != nil {
-			return errMsg{err: err}
-		}
-		return repoActionResultMsg{panel: RemotePanel}
-	}
+	return m.startCheckoutJobs([]panelJob{remoteCheckoutJob(repo, entry)}, RemotePanel, false)
 }
 
 func (m *Model) deleteRemoteBranchCmd(repo *git.Repository, entry remotePanelEntry) tea.Cmd {
 	if repo == nil || entry.RemoteName == "" || entry.BranchName == "" {
 		return nil
 	}
-	return func() tea.Msg {
-		repo.State.Message = fmt.Sprintf("deleting %s/%s", entry.RemoteName, entry.BranchName)
-		args := []string{"push", entry.RemoteName, "--delete", entry.BranchName}
-		if _, err := command.Run(repo.AbsPath, "git", args); err != nil {
-			repo.State.Message = err.Error()
-			return errMsg{err: fmt.Errorf("delete remote branch %s/%s: %w", entry.RemoteName, entry.BranchName, err)}
-		}
-		repo.State.Message = fmt.Sprintf("deleted %s/%s", entry.RemoteName, entry.BranchName)
-		if err := scheduleRefresh(repo); err != nil {
-			return errMsg{err: err}
-		}
-		return repoActionResultMsg{panel: RemotePanel}
-	}
+	return m.confirmDestructive(i18n.T("delete branch %s", entry.RemoteName+"/"+entry.BranchName), []*git.Repository{repo}, func() tea.Cmd {
+		return m.startPanelJobs([]panelJob{deleteBranchJob(repo, entry.RemoteName, entry.BranchName)}, RemotePanel, false)
+	})
 }
 
 func (m *Model) checkoutRemoteBranchMultiCmd(repos []*git.Repository, entry remotePanelEntry) tea.Cmd {
@@ -344,28 +474,11 @@ func (m *Model) checkoutRemoteBranchMultiCmd(repos []*git.Repository, entry remo
 	if len(filtered) == 0 || entry.FullName == "" {
 		return nil
 	}
-	return func() tea.Msg {
-		for _, repo := range filtered {
-			repo.State.Message = fmt.Sprintf("checking out %s", entry.BranchName)
-			if existing := findBranchByName(repo, entry.BranchName); existing != nil {
-				if err := repo.Checkout(existing); err != nil {
-					repo.State.Message = err.Error()
-					return errMsg{err: fmt.Errorf("checkout branch %s in %s: %w", entry.BranchName, repo.Name, err)}
-				}
-			} else {
-				args := []string{"checkout", "-b", entry.BranchName, entry.FullName}
-				if _, err := command.Run(repo.AbsPath, "git", args); err != nil {
-					repo.State.Message = err.Error()
-					return errMsg{err: fmt.Errorf("create branch %s from %s in %s: %w", entry.BranchName, entry.FullName, repo.Name, err)}
-				}
-			}
-			repo.State.Message = fmt.Sprintf("switched to %s", entry.BranchName)
-			if err := scheduleRefresh(repo); err != nil {
-				return errMsg{err: fmt.Errorf("refresh repository %s: %w", repo.Name, err)}
-			}
-		}
-		return repoActionResultMsg{panel: RemotePanel, closePanel: true}
+	jobs := make([]panelJob, 0, len(filtered))
+	for _, repo := range filtered {
+		jobs = append(jobs, remoteCheckoutJob(repo, entry))
 	}
+	return m.startCheckoutJobs(jobs, RemotePanel, true)
 }
 
 func (m *Model) deleteRemoteBranchMultiCmd(repos []*git.Repository, entry remotePanelEntry) tea.Cmd {
@@ -373,19 +486,58 @@ func (m *Model) deleteRemoteBranchMultiCmd(repos []*git.Repository, entry remote
 	if len(filtered) == 0 || entry.RemoteName == "" || entry.BranchName == "" {
 		return nil
 	}
-	return func() tea.Msg {
-		for _, repo := range filtered {
-			repo.State.Message = fmt.Sprintf("deleting %s/%s", entry.RemoteName, entry.BranchName)
-			args := []string{"push", entry.RemoteName, "--delete", entry.BranchName}
-			if _, err := command.Run(repo.AbsPath, "git", args); err != nil {
-				repo.State.Message = err.Error()
-				return errMsg{err: fmt.Errorf("delete remote branch %s/%s in %s: %w", entry.RemoteName, entry.BranchName, repo.Name, err)}
-			}
-			repo.State.Message = fmt.Sprintf("deleted %s/%s", entry.RemoteName, entry.BranchName)
-			if err := scheduleRefresh(repo); err != nil {
-				return errMsg{err: fmt.Errorf("refresh repository %s: %w", repo.Name, err)}
-			}
-		}
-		return repoActionResultMsg{panel: RemotePanel}
+	jobs := make([]panelJob, 0, len(filtered))
+	for _, repo := range filtered {
+		jobs = append(jobs, deleteBranchJob(repo, entry.RemoteName, entry.BranchName))
+	}
+	return m.confirmDestructive(i18n.T("delete branch %s", entry.RemoteName+"/"+entry.BranchName), filtered, func() tea.Cmd {
+		return m.startPanelJobs(jobs, RemotePanel, false)
+	})
+}
+
+// setUpstreamCmd makes the remote branch the upstream of the checked out
+// branch in each repository. The queue refreshes them afterwards, so
+// ahead/behind counts follow the new upstream.
+func (m *Model) setUpstreamCmd(repos []*git.Repository, entry remotePanelEntry) tea.Cmd {
+	filtered := filterRepositories(repos)
+	if len(filtered) == 0 || entry.FullName == "" {
+		return nil
+	}
+	jobs := make([]panelJob, 0, len(filtered))
+	for _, repo := range filtered {
+		if repo.State == nil || repo.State.Branch == nil {
+			continue
+		}
+		branchName := repo.State.Branch.Name
+		jobs = append(jobs, panelJob{
+			repo:    repo,
+			jobType: job.SetUpstreamJob,
+			options: &command.SetUpstreamOptions{Branch: branchName, Upstream: entry.FullName},
+			message: fmt.Sprintf("setting upstream of %s to %s", branchName, entry.FullName),
+		})
+	}
+	return m.startPanelJobs(jobs, RemotePanel, false)
+}
+
+// pruneRemotesCmd removes stale remote-tracking refs in each repository; the
+// remotes are reloaded once a repository was pruned.
+func (m *Model) pruneRemotesCmd(repos []*git.Repository) tea.Cmd {
+	var jobs []panelJob
+	for _, repo := range filterRepositories(repos) {
+		if repo.State == nil || repo.State.Remote == nil {
+			continue
+		}
+		remoteName := defaultRemoteName(repo)
+		jobs = append(jobs, panelJob{
+			repo:    repo,
+			jobType: job.PruneRemoteJob,
+			options: remoteName,
+			message: fmt.Sprintf("pruning %s", remoteName),
+		})
+	}
+	if len(jobs) == 0 {
+		m.notice = i18n.T("no repository with a remote selected")
+		return nil
 	}
+	return m.startPanelJobs(jobs, RemotePanel, false)
 }
@@ -0,0 +1,258 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// patchResultsMsg delivers the outcome of applying a patch in the selected
+// repositories.
+type patchResultsMsg struct {
+	results []*command.PatchResult
+	err     error
+}
+
+func (m *Model) handlePatchPromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.patchPromptActive {
+		return false, nil
+	}
+	if msg.Paste {
+		m.patchPathBuffer += sanitizeCredentialPaste(string(msg.Runes))
+		return true, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc":
+		m.dismissPatchPrompt()
+		return true, nil
+	case "enter":
+		return true, m.submitPatchPrompt()
+	case "backspace", "ctrl+h":
+		runes := []rune(m.patchPathBuffer)
+		if len(runes) > 0 {
+			m.patchPathBuffer = string(runes[:len(runes)-1])
+		}
+		return true, nil
+	case " ":
+		m.patchPathBuffer += " "
+		return true, nil
+	default:
+		if len(msg.Runes) > 0 {
+			m.patchPathBuffer += string(msg.Runes)
+		}
+		return true, nil
+	}
+}
+
+// openPatchPrompt asks for the patch to apply in repos, prefilled with the
+// previous file.
+func (m *Model) openPatchPrompt(repos []*git.Repository) {
+	repos = filterRepositories(repos)
+	if len(repos) == 0 {
+		m.notice = i18n.T("no repository selected")
+		return
+	}
+	m.patchPromptActive = true
+	m.patchPromptRepos = repos
+	m.patchPathBuffer = m.patchSource
+}
+
+func (m *Model) dismissPatchPrompt() {
+	m.patchPromptActive = false
+	m.patchPromptRepos = nil
+	m.patchPathBuffer = ""
+}
+
+// submitPatchPrompt applies the entered patch file, or the clipboard when
+// none is entered, and shows the results.
+func (m *Model) submitPatchPrompt() tea.Cmd {
+	path := strings.TrimSpace(m.patchPathBuffer)
+	repos := m.patchPromptRepos
+	m.dismissPatchPrompt()
+	m.patchSource = path
+	return m.confirmDestructive(i18n.T("apply a patch"), repos, func() tea.Cmd {
+		m.patchActive = true
+		m.patchRunning = true
+		m.patchResults = nil
+		m.patchErr = nil
+		m.patchOffset = 0
+		return applyPatchCmd(path, repos)
+	})
+}
+
+func (m *Model) dismissPatchResults() {
+	m.patchActive = false
+	m.patchRunning = false
+	m.patchResults = nil
+	m.patchErr = nil
+	m.patchOffset = 0
+}
+
+func (m *Model) applyPatchResults(msg patchResultsMsg) {
+	if !m.patchActive {
+		return
+	}
+	m.patchRunning = false
+	m.patchResults = msg.results
+	m.patchErr = msg.err
+}
+
+func (m *Model) handlePatchResultsKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.patchActive {
+		return false, nil
+	}
+	viewport := m.patchViewportSize()
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc", "enter", "q":
+		m.dismissPatchResults()
+	case "up", "k":
+		m.patchOffset--
+	case "down", "j":
+		m.patchOffset++
+	}
+	m.patchOffset = clampHelpOffset(m.patchOffset, len(m.patchResults), viewport)
+	return true, nil
+}
+
+// applyPatchCmd applies the patch concurrently, bounded by the git semaphore,
+// and refreshes the repositories it changed.
+func applyPatchCmd(path string, repos []*git.Repository) tea.Cmd {
+	return func() tea.Msg {
+		file, cleanup, err := command.PatchFile(context.Background(), path)
+		if err != nil {
+			return patchResultsMsg{err: err}
+		}
+		defer cleanup()
+
+		var (
+			mu      sync.Mutex
+			wg      sync.WaitGroup
+			results []*command.PatchResult
+			work    = make(chan *git.Repository)
+		)
+		for range min(forceRefreshWorkers, max(len(repos), 1)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for r := range work {
+					result := &command.PatchResult{Repository: r}
+					if err := git.AcquireGitSemaphore(context.Background()); err != nil {
+						result.Err = err
+					} else {
+						result = command.ApplyPatch(context.Background(), r, file)
+						git.ReleaseGitSemaphore()
+					}
+					if result.Err == nil {
+						_ = scheduleRefresh(r)
+					}
+					mu.Lock()
+					results = append(results, result)
+					mu.Unlock()
+				}
+			}()
+		}
+		for _, r := range repos {
+			work <- r
+		}
+		close(work)
+		wg.Wait()
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].Repository.Name < results[j].Repository.Name
+		})
+		return patchResultsMsg{results: results}
+	}
+}
+
+// patchViewportSize is the number of results that fit next to the title and
+// the summary.
+func (m *Model) patchViewportSize() int {
+	if m.height <= 0 {
+		return 20
+	}
+	return max(m.height-10, 5)
+}
+
+func (m *Model) renderPatchPrompt() string {
+	if !m.patchPromptActive {
+		return ""
+	}
+	panelWidth := 60
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+
+	pathDisplay := m.patchPathBuffer
+	if runes := []rune(pathDisplay); len(runes) > contentWidth-8 {
+		pathDisplay = string(runes[len(runes)-contentWidth+8:])
+	}
+	lines := []string{
+		m.styles.PanelTitle.Render(i18n.T("Apply a patch in %d repositories", len(m.patchPromptRepos))),
+		"",
+		"> " + i18n.T("File: %s", pathDisplay),
+		"",
+		truncateString(i18n.T("leave empty to apply the patch on the clipboard"), contentWidth),
+		i18n.T("enter: apply | esc: cancel"),
+	}
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
+
+func (m *Model) renderPatchResults() string {
+	if !m.patchActive {
+		return ""
+	}
+	panelWidth := 72
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+
+	source := m.patchSource
+	if source == "" {
+		source = i18n.T("the clipboard")
+	}
+	lines := []string{m.styles.PanelTitle.Render(truncateString(i18n.T("Apply %s", source), contentWidth)), ""}
+	switch {
+	case m.patchRunning:
+		lines = append(lines, i18n.T("Applying the patch..."))
+	case m.patchErr != nil:
+		lines = append(lines, m.styles.Error.Render(truncateString(m.patchErr.Error(), contentWidth)))
+	default:
+		viewport := m.patchViewportSize()
+		offset := clampHelpOffset(m.patchOffset, len(m.patchResults), viewport)
+		applied := 0
+		for _, result := range m.patchResults {
+			if result.Err == nil {
+				applied++
+			}
+		}
+		for _, result := range m.patchResults[offset:min(offset+viewport, len(m.patchResults))] {
+			if result.Err != nil {
+				lines = append(lines, m.styles.Error.Render(truncateString(fmt.Sprintf("✗ %s: %v", result.Repository.Name, result.Err), contentWidth)))
+				continue
+			}
+			lines = append(lines, truncateString(i18n.T("✓ %s: %d file(s) changed", result.Repository.Name, result.Files), contentWidth))
+		}
+		lines = append(lines, "", i18n.T("applied cleanly in %d of %d repositories", applied, len(m.patchResults)))
+	}
+	lines = append(lines, "", m.styles.Help.Render(i18n.T("esc: close")))
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
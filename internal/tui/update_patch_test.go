@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestPatchPrompt_ReportsWhereThePatchApplied(t *testing.T) {
+	alpha := initBranchCreationRepo(t, "alpha")
+	beta := initBranchCreationRepo(t, "beta")
+	require.NoError(t, os.WriteFile(filepath.Join(beta.AbsPath, "README.md"), []byte("changed"), 0o644))
+	runBranchTestGit(t, beta.AbsPath, "commit", "-am", "diverge")
+
+	require.NoError(t, os.WriteFile(filepath.Join(alpha.AbsPath, "README.md"), []byte("patched"), 0o644))
+	patch := runBranchTestGit(t, alpha.AbsPath, "diff")
+	runBranchTestGit(t, alpha.AbsPath, "checkout", "--", "README.md")
+	patchPath := filepath.Join(t.TempDir(), "change.patch")
+	require.NoError(t, os.WriteFile(patchPath, []byte(patch), 0o644))
+
+	m := &Model{repositories: []*git.Repository{alpha, beta}, styles: DefaultStyles(), width: 100, height: 30}
+	m.openPatchPrompt([]*git.Repository{alpha, beta})
+	require.True(t, m.patchPromptActive)
+	handled, _ := m.handlePatchPromptKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(patchPath), Paste: true})
+	require.True(t, handled)
+	handled, cmd := m.handlePatchPromptKey(tea.KeyMsg{Type: tea.KeyEnter})
+	require.True(t, handled)
+	require.NotNil(t, cmd)
+	require.False(t, m.patchPromptActive)
+	require.True(t, m.patchActive)
+
+	m.applyPatchResults(cmd().(patchResultsMsg))
+	view := ansi.Strip(m.renderPatchResults())
+	require.Contains(t, view, "✓ alpha: 1 file(s) changed")
+	require.Contains(t, view, "✗ beta: patch failed: README.md:1")
+	require.Contains(t, view, "applied cleanly in 1 of 2 repositories")
+	content, err := os.ReadFile(filepath.Join(alpha.AbsPath, "README.md"))
+	require.NoError(t, err)
+	require.Equal(t, "patched", string(content))
+
+	handled, _ = m.handlePatchResultsKey(tea.KeyMsg{Type: tea.KeyEsc})
+	require.True(t, handled)
+	require.False(t, m.patchActive)
+}
@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+// togglePinned pins or unpins the given repositories for the session. A
+// mixed selection is pinned first. Pinned repositories move to the top and
+// the cursor follows the selected one.
+func (m *Model) togglePinned(repos []*git.Repository) {
+	repos = filterRepositories(repos)
+	if len(repos) == 0 {
+		return
+	}
+	pin := false
+	for _, r := range repos {
+		if !command.Pinned(r) {
+			pin = true
+			break
+		}
+	}
+	for _, r := range repos {
+		command.SetRepoPinned(r, pin)
+		if pin && r.WorkStatus() == git.Queued {
+			m.removeFromQueue(r)
+		}
+	}
+
+	current := m.currentRepository()
+	// The pin widens the repository column.
+	m.cachedWidth = 0
+	m.applyRepositorySort()
+	if current != nil {
+		m.focusRepository(current)
+	}
+
+	target := repos[0].Name
+	if len(repos) > 1 {
+		target = fmt.Sprintf("%d repos", len(repos))
+	}
+	if pin {
+		m.notice = i18n.T("pinned %s: only touched manually", target)
+	} else {
+		m.notice = i18n.T("unpinned %s", target)
+	}
+}
@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestPinnedRepositoriesStayOnTopAndOutOfTagAll(t *testing.T) {
+	root := t.TempDir()
+	command.SetPinned([]string{filepath.Join(root, "prod")})
+	t.Cleanup(func() { command.SetPinned(nil) })
+
+	repos := make([]*git.Repository, 0, 3)
+	for _, name := range []string{"alpha", "prod", "zulu"} {
+		repo := queueTestRepo(name, true, true, true)
+		repo.AbsPath = filepath.Join(root, name)
+		repos = append(repos, repo)
+	}
+	alpha, prod, zulu := repos[0], repos[1], repos[2]
+	model := &Model{mode: pullMode, repositories: repos, statusFilter: filterBehind, styles: DefaultStyles()}
+	model.applyRepositorySort()
+	require.Equal(t, []*git.Repository{prod, alpha, zulu}, model.repositories)
+	require.Equal(t, pinnedSymbol+" prod", repoDisplayName(prod))
+	require.Equal(t, []*git.Repository{prod}, model.visibleRepositories(), "the filter never hides pinned repositories")
+
+	model.statusFilter = filterNone
+	result, _ := model.queueAll()().(queueResultMsg)
+	assert.Equal(t, 2, result.tagged)
+	assert.Equal(t, []queueSkip{{"pinned", 1}}, result.skipped)
+	assert.Equal(t, git.Available, prod.WorkStatus())
+	assert.Equal(t, command.OperationOutcome{Operation: command.OperationStateProbe, Message: "not fetched yet"}, startupProbeOutcome(prod))
+
+	model.cursor = 2
+	model.togglePinned([]*git.Repository{zulu})
+	t.Cleanup(func() { command.SetRepoPinned(zulu, false) })
+	require.Equal(t, []*git.Repository{prod, zulu, alpha}, model.repositories)
+	assert.Equal(t, git.Available, zulu.WorkStatus(), "pinning untags")
+	assert.Same(t, zulu, model.currentRepository(), "the cursor follows the repository")
+	assert.Contains(t, model.notice, "pinned zulu")
+}
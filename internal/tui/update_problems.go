@@ -0,0 +1,364 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/load"
+)
+
+// identityProblemsMsg delivers the result of an identity check run.
+type identityProblemsMsg struct {
+	problems []*command.IdentityProblem
+}
+
+// recloneResultMsg delivers the result of cloning a quarantined repository
+// again.
+type recloneResultMsg struct {
+	skipped load.Skipped
+	repo    *git.Repository
+	backup  string
+	err     error
+}
+
+// openProblems shows the problems view and re-checks the identities of all
+// loaded repositories. The directories skipped while loading are listed
+// below the identities.
+func (m *Model) openProblems() tea.Cmd {
+	m.problemsActive = true
+	m.problemsCursor = 0
+	if !command.HasIdentityRules() {
+		m.identityProblems = nil
+		return nil
+	}
+	m.problemsChecking = true
+	return checkIdentitiesCmd(filterRepositories(m.repositories))
+}
+
+// identityCheckCmd checks the identities of the loaded repositories once they
+// are loaded and on an explicit refresh, or returns nil without rules.
+func (m *Model) identityCheckCmd() tea.Cmd {
+	if !command.HasIdentityRules() {
+		return nil
+	}
+	return checkIdentitiesCmd(filterRepositories(m.repositories))
+}
+
+func (m *Model) dismissProblems() {
+	m.problemsActive = false
+	m.problemsCursor = 0
+}
+
+func (m *Model) handleProblemsKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.problemsActive {
+		return false, nil
+	}
+	count := m.problemCount()
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc", "!", "q":
+		m.dismissProblems()
+	case "up", "k":
+		if count > 0 {
+			wrapCursor(&m.problemsCursor, count, -1)
+		}
+	case "down", "j":
+		if count > 0 {
+			wrapCursor(&m.problemsCursor, count, 1)
+		}
+	case "f":
+		if m.problemsCursor < len(m.identityProblems) {
+			problem := m.identityProblems[m.problemsCursor]
+			return true, m.fixIdentitiesCmd([]*command.IdentityProblem{problem})
+		}
+	case "c":
+		quarantined := m.quarantinedRepositories()
+		if i := m.problemsCursor - len(m.identityProblems); i >= 0 && i < len(quarantined) {
+			return true, m.recloneCmd(quarantined[i])
+		}
+	case "F":
+		return true, m.fixIdentitiesCmd(m.identityProblems)
+	case "enter":
+		failed := m.failedRepositories()
+		if i := m.problemsCursor - len(m.identityProblems) - len(m.quarantinedRepositories()); i >= 0 && i < len(failed) {
+			return true, m.showFailure(failed[i])
+		}
+	}
+	return true, nil
+}
+
+// problemCount is the number of entries the cursor of the problems view
+// moves over: identity problems, quarantined and failed repositories.
+func (m *Model) problemCount() int {
+	return len(m.identityProblems) + len(m.quarantinedRepositories()) + len(m.failedRepositories())
+}
+
+// failedRepositories returns the repositories whose last operation failed, in
+// the order of the overview.
+func (m *Model) failedRepositories() []*git.Repository {
+	var failed []*git.Repository
+	for _, r := range m.repositories {
+		if r != nil && r.WorkStatus() == git.Fail {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// failureMessage returns the error the status panel of r shows: the one
+// captured when jumping to r from the problems view, or the message of a
+// failed operation.
+func (m *Model) failureMessage(r *git.Repository) string {
+	if m.failureDetail != nil && m.failureDetail.repo == r {
+		return strings.TrimSpace(m.failureDetail.message)
+	}
+	if r.WorkStatus() == git.Fail {
+		return strings.TrimSpace(r.State.Message())
+	}
+	return ""
+}
+
+// showFailure leaves the problems view for the row of a failed repository
+// and opens its status panel with the error.
+func (m *Model) showFailure(r *git.Repository) tea.Cmd {
+	m.dismissProblems()
+	if !m.focusRepository(r) {
+		m.notice = r.Name + " is hidden by the current filter"
+		return nil
+	}
+	m.failureDetail = &failureDetail{repo: r, message: r.State.Message()}
+	m.activatePanel(StatusPanel)
+	return m.loadStatusFilesCmd(r)
+}
+
+// applyIdentityProblems stores the result of a check. Outside of the problems
+// view a notice points at it.
+func (m *Model) applyIdentityProblems(msg identityProblemsMsg) {
+	m.problemsChecking = false
+	m.identityProblems = msg.problems
+	m.problemsCursor = clampIndex(m.problemsCursor, m.problemCount())
+	if !m.problemsActive && len(msg.problems) > 0 {
+		m.notice = fmt.Sprintf("%d repo(s) commit with the wrong identity, press ! to review", len(msg.problems))
+	}
+}
+
+// applySkippedDirectories adds the directories that failed to load to the
+// ones skipped while discovering the repositories and points at the
+// problems view when there are any.
+func (m *Model) applySkippedDirectories(skipped []load.Skipped) {
+	m.skippedDirectories = append(m.skippedDirectories, skipped...)
+	if quarantined := len(m.quarantinedRepositories()); quarantined > 0 {
+		m.notice = fmt.Sprintf("%d repo(s) quarantined because they are corrupt, press ! to repair", quarantined)
+	} else if len(m.skippedDirectories) > 0 {
+		m.notice = fmt.Sprintf("%d director(ies) skipped, press ! to review", len(m.skippedDirectories))
+	}
+}
+
+// quarantinedRepositories returns the skipped repositories that are corrupt.
+func (m *Model) quarantinedRepositories() []load.Skipped {
+	var quarantined []load.Skipped
+	for _, skipped := range m.skippedDirectories {
+		if skipped.Corrupt {
+			quarantined = append(quarantined, skipped)
+		}
+	}
+	return quarantined
+}
+
+// recloneCmd moves a quarantined repository aside and clones it again from
+// its origin.
+func (m *Model) recloneCmd(skipped load.Skipped) tea.Cmd {
+	if skipped.Origin == "" {
+		m.notice = "no origin URL recorded for " + filepath.Base(skipped.Path) + ", clone it again by hand"
+		return nil
+	}
+	m.notice = "cloning " + skipped.Origin + " again"
+	return func() tea.Msg {
+		backup, err := command.Reclone(skipped.Path, skipped.Origin)
+		if err != nil {
+			return recloneResultMsg{skipped: skipped, err: err}
+		}
+		repo, err := git.InitializeRepo(skipped.Path)
+		return recloneResultMsg{skipped: skipped, repo: repo, backup: backup, err: err}
+	}
+}
+
+// applyRecloneResult lifts the quarantine of a repository that was cloned
+// again and adds it to the overview.
+func (m *Model) applyRecloneResult(msg recloneResultMsg) {
+	if msg.err != nil {
+		m.notice = fmt.Sprintf("re-clone of %s failed: %s", filepath.Base(msg.skipped.Path), msg.err)
+		return
+	}
+	m.skippedDirectories = slices.DeleteFunc(m.skippedDirectories, func(skipped load.Skipped) bool {
+		return skipped.Path == msg.skipped.Path
+	})
+	m.problemsCursor = clampIndex(m.problemsCursor, len(m.identityProblems)+len(m.quarantinedRepositories()))
+	m.addRepository(msg.repo)
+	command.ScheduleStateEvaluation(msg.repo, command.OperationOutcome{Operation: command.OperationStateProbe})
+	m.notice = fmt.Sprintf("cloned %s again, the damaged copy is at %s", msg.repo.Name, msg.backup)
+}
+
+// fixIdentitiesCmd applies the expected identity to every fixable problem
+// and checks all repositories again.
+func (m *Model) fixIdentitiesCmd(problems []*command.IdentityProblem) tea.Cmd {
+	var fixable []*command.IdentityProblem
+	for _, problem := range problems {
+		if problem.Fixable() {
+			fixable = append(fixable, problem)
+		}
+	}
+	if len(fixable) == 0 {
+		m.notice = "email patterns cannot be applied, set user.email manually"
+		return nil
+	}
+	m.problemsChecking = true
+	repos := filterRepositories(m.repositories)
+	return func() tea.Msg {
+		for _, problem := range fixable {
+			if err := command.FixIdentity(context.Background(), problem); err != nil {
+				return errMsg{err: fmt.Errorf("fix identity of %s: %w", problem.Repository.Name, err)}
+			}
+		}
+		return checkIdentitiesCmd(repos)()
+	}
+}
+
+// checkIdentitiesCmd checks the identities of repos using a small worker pool
+// that respects the global git semaphore.
+func checkIdentitiesCmd(repos []*git.Repository) tea.Cmd {
+	return func() tea.Msg {
+		var (
+			mu       sync.Mutex
+			wg       sync.WaitGroup
+			problems []*command.IdentityProblem
+			work     = make(chan *git.Repository)
+		)
+		for range min(forceRefreshWorkers, max(len(repos), 1)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for r := range work {
+					if err := git.AcquireGitSemaphore(context.Background()); err != nil {
+						continue
+					}
+					problem, _ := command.CheckIdentity(context.Background(), r)
+					git.ReleaseGitSemaphore()
+					if problem != nil {
+						mu.Lock()
+						problems = append(problems, problem)
+						mu.Unlock()
+					}
+				}
+			}()
+		}
+		for _, r := range repos {
+			if r != nil {
+				work <- r
+			}
+		}
+		close(work)
+		wg.Wait()
+		sort.Slice(problems, func(i, j int) bool {
+			return problems[i].Repository.Name < problems[j].Repository.Name
+		})
+		return identityProblemsMsg{problems: problems}
+	}
+}
+
+func (m *Model) renderProblems() string {
+	if !m.problemsActive {
+		return ""
+	}
+	panelWidth := 72
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+
+	lines := []string{m.styles.PanelTitle.Render("Problems"), ""}
+	switch {
+	case !command.HasIdentityRules():
+		lines = append(lines, "No identity rules configured (see identities in config.yml).")
+	case m.problemsChecking:
+		lines = append(lines, "Checking identities...")
+	case len(m.identityProblems) == 0:
+		lines = append(lines, "All repositories use the expected identity.")
+	default:
+		for i, problem := range m.identityProblems {
+			email := problem.Email
+			if email == "" {
+				email = "no user.email"
+			}
+			label := fmt.Sprintf("%s: %s, expected %s", problem.Repository.Name, email, problem.Rule.Email)
+			if i == m.problemsCursor {
+				lines = append(lines, m.styles.SelectedItem.Render(padToWidth(truncateString("> "+label, contentWidth), contentWidth)))
+			} else {
+				lines = append(lines, truncateString("  "+label, contentWidth))
+			}
+		}
+	}
+	if quarantined := m.quarantinedRepositories(); len(quarantined) > 0 {
+		lines = append(lines, "", m.styles.PanelTitle.Render("Quarantined repositories"))
+		for i, skipped := range quarantined {
+			label := fmt.Sprintf("%s: %s", skipped.Path, skipped.Reason)
+			if len(m.identityProblems)+i != m.problemsCursor {
+				lines = append(lines, truncateString("  "+label, contentWidth))
+				continue
+			}
+			lines = append(lines, m.styles.SelectedItem.Render(padToWidth(truncateString("> "+label, contentWidth), contentWidth)))
+			lines = append(lines, truncateString("    check:    git -C "+skipped.Path+" fsck --full", contentWidth))
+			if skipped.Origin != "" {
+				lines = append(lines, truncateString("    re-clone: c clones "+skipped.Origin+" and keeps the damaged copy", contentWidth))
+			} else {
+				lines = append(lines, truncateString("    re-clone: no origin URL recorded, clone it again by hand", contentWidth))
+			}
+		}
+	}
+	if failed := m.failedRepositories(); len(failed) > 0 {
+		offset := len(m.identityProblems) + len(m.quarantinedRepositories())
+		lines = append(lines, "", m.styles.PanelTitle.Render("Failed operations"))
+		for i, r := range failed {
+			label := fmt.Sprintf("%s: %s", r.Name, singleLineMessage(r.State.Message()))
+			if offset+i == m.problemsCursor {
+				lines = append(lines, m.styles.SelectedItem.Render(padToWidth(truncateString("> "+label, contentWidth), contentWidth)))
+			} else {
+				lines = append(lines, truncateString("  "+label, contentWidth))
+			}
+		}
+	}
+	var skippedLines []string
+	for _, skipped := range m.skippedDirectories {
+		if !skipped.Corrupt {
+			skippedLines = append(skippedLines, truncateString(fmt.Sprintf("  %s: %s", skipped.Path, skipped.Reason), contentWidth))
+		}
+	}
+	if len(skippedLines) > 0 {
+		lines = append(lines, "", m.styles.PanelTitle.Render("Skipped directories"))
+		lines = append(lines, skippedLines...)
+	}
+	hints := []string{"esc: close"}
+	if len(m.failedRepositories()) > 0 {
+		hints = append([]string{"enter: show failure"}, hints...)
+	}
+	if len(m.quarantinedRepositories()) > 0 {
+		hints = append([]string{"c: re-clone selected"}, hints...)
+	}
+	if len(m.identityProblems) > 0 {
+		hints = append([]string{"f: fix selected", "F: fix all"}, hints...)
+	}
+	lines = append(lines, "", m.styles.Help.Render(strings.Join(hints, " | ")))
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/load"
+)
+
+func TestProblems_FlagsAndFixesWrongIdentity(t *testing.T) {
+	alpha := initBranchCreationRepo(t, "alpha")
+	beta := initBranchCreationRepo(t, "beta")
+	command.SetIdentityRules([]command.IdentityRule{
+		{Path: alpha.AbsPath, Email: "alpha@corp.example"},
+		{Path: beta.AbsPath, Email: "test@example.com"},
+	})
+	t.Cleanup(func() { command.SetIdentityRules(nil) })
+
+	m := &Model{repositories: []*git.Repository{alpha, beta}, styles: DefaultStyles(), width: 100}
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	require.True(t, m.problemsActive)
+	require.NotNil(t, cmd)
+	m.applyIdentityProblems(cmd().(identityProblemsMsg))
+
+	require.Len(t, m.identityProblems, 1)
+	require.Same(t, alpha, m.identityProblems[0].Repository)
+	require.Contains(t, m.renderProblems(), "expected alpha@corp.example")
+
+	_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	require.NotNil(t, cmd)
+	m.applyIdentityProblems(cmd().(identityProblemsMsg))
+	require.Empty(t, m.identityProblems)
+	require.Equal(t, "alpha@corp.example\n", runBranchTestGit(t, alpha.AbsPath, "config", "user.email"))
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	require.False(t, m.problemsActive)
+}
+
+func TestProblems_IdentitiesAreCheckedOnceAfterLoading(t *testing.T) {
+	alpha := initBranchCreationRepo(t, "alpha")
+	command.SetIdentityRules([]command.IdentityRule{{Path: alpha.AbsPath, Email: "alpha@corp.example"}})
+	t.Cleanup(func() { command.SetIdentityRules(nil) })
+
+	m := &Model{repositories: []*git.Repository{alpha}, styles: DefaultStyles(), initialStateProbeStarted: true}
+	_, cmd := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	require.Nil(t, cmd, "a resize checks nothing")
+	require.NotNil(t, m.identityCheckCmd())
+
+	command.SetIdentityRules(nil)
+	require.Nil(t, m.identityCheckCmd())
+}
+
+func TestProblems_NoticeOutsideOfView(t *testing.T) {
+	m := &Model{}
+	m.applyIdentityProblems(identityProblemsMsg{problems: []*command.IdentityProblem{{Repository: &git.Repository{Name: "alpha"}}}})
+	require.True(t, strings.HasPrefix(m.notice, "1 repo(s)"))
+}
+
+func TestProblems_ListsSkippedDirectories(t *testing.T) {
+	m := &Model{styles: DefaultStyles(), width: 120, skippedDirectories: []load.Skipped{
+		{Path: "/work/legacy", Reason: "Mercurial working copy, not a git repository"},
+	}}
+	m.applySkippedDirectories([]load.Skipped{{Path: "/work/broken", Reason: "cannot be opened: object not found"}})
+	require.Equal(t, "2 director(ies) skipped, press ! to review", m.notice)
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	view := m.renderProblems()
+	require.Contains(t, view, "Skipped directories")
+	require.Contains(t, view, "/work/legacy: Mercurial working copy, not a git repository")
+	require.Contains(t, view, "/work/broken: cannot be opened: object not found")
+}
+
+func TestProblems_RecloneLiftsTheQuarantine(t *testing.T) {
+	damaged := initBranchCreationRepo(t, "alpha")
+	path, origin := damaged.AbsPath, damaged.AbsPath+".git"
+	m := &Model{styles: DefaultStyles(), width: 160}
+	m.applySkippedDirectories([]load.Skipped{{Path: path, Reason: "cannot be loaded: object not found", Corrupt: true, Origin: origin}})
+	require.Equal(t, "1 repo(s) quarantined because they are corrupt, press ! to repair", m.notice)
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	view := m.renderProblems()
+	require.Contains(t, view, "Quarantined repositories")
+	require.Contains(t, view, "check:    git -C ")
+	require.Contains(t, view, "re-clone: c clones ")
+	require.Contains(t, view, "c: re-clone selected")
+
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	require.NotNil(t, cmd)
+	m.Update(cmd())
+	require.Empty(t, m.skippedDirectories)
+	require.Len(t, m.repositories, 1)
+	require.Equal(t, path, m.repositories[0].AbsPath)
+	require.Contains(t, m.notice, "cloned alpha again, the damaged copy is at "+path+".corrupt-")
+}
+
+func TestProblems_EnterOnAFailureOpensItsStatus(t *testing.T) {
+	alpha := initBranchCreationRepo(t, "alpha")
+	beta := initBranchCreationRepo(t, "beta")
+	beta.SetWorkStatus(git.Fail)
+	beta.State.SetMessage("fetch failed: could not read from remote repository")
+	m := &Model{repositories: []*git.Repository{alpha, beta}, styles: DefaultStyles(), width: 120, height: 40}
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	view := m.renderProblems()
+	require.Contains(t, view, "Failed operations")
+	require.Contains(t, view, "beta: fetch failed: could not read from remote repository")
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.False(t, m.problemsActive)
+	require.Equal(t, beta, m.currentRepository())
+	require.Equal(t, StatusPanel, m.sidePanel)
+	// Opening the status panel refreshes beta; the cleanliness pass that
+	// follows the refresh leaves it available again.
+	require.Eventually(t, func() bool { return beta.WorkStatus() == git.Available }, 10*time.Second, 20*time.Millisecond)
+
+	beta.SetWorkStatus(git.Available)
+	beta.State.SetMessage("")
+	require.Contains(t, m.renderStatus(beta, 80, 40), "  fetch failed: could not read from remote repository")
+}
@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+	"github.com/thorstenhirsch/gitbatch/internal/job"
+)
+
+func (m *Model) handleRebaseOntoPromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.rebaseOntoPromptActive {
+		return false, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc":
+		m.dismissRebaseOntoPrompt()
+		return true, nil
+	case "tab":
+		m.switchRebaseOntoField()
+		return true, nil
+	case "enter":
+		if m.rebaseOntoField == rebaseOntoFieldOnto {
+			m.rebaseOntoField = rebaseOntoFieldUpstream
+			return true, nil
+		}
+		return true, m.submitRebaseOntoPrompt()
+	case "backspace", "ctrl+h":
+		buffer := m.rebaseOntoInputBuffer()
+		runes := []rune(*buffer)
+		if len(runes) > 0 {
+			*buffer = string(runes[:len(runes)-1])
+		}
+		return true, nil
+	case " ":
+		// Refs cannot contain spaces.
+		return true, nil
+	default:
+		if len(msg.Runes) > 0 {
+			*m.rebaseOntoInputBuffer() += string(msg.Runes)
+		}
+		return true, nil
+	}
+}
+
+// openRebaseOntoPrompt asks for the new and the old base of a rebase --onto
+// that runs in all tagged repositories, or the selected one.
+func (m *Model) openRebaseOntoPrompt() {
+	repos := filterRepositories(m.panelRepositories())
+	if len(repos) == 0 {
+		return
+	}
+	m.rebaseOntoPromptActive = true
+	m.rebaseOntoRepos = repos
+	m.rebaseOntoField = rebaseOntoFieldOnto
+	m.rebaseOntoBuffer = ""
+	m.rebaseUpstreamBuffer = ""
+}
+
+func (m *Model) dismissRebaseOntoPrompt() {
+	m.rebaseOntoPromptActive = false
+	m.rebaseOntoRepos = nil
+	m.rebaseOntoField = rebaseOntoFieldOnto
+	m.rebaseOntoBuffer = ""
+	m.rebaseUpstreamBuffer = ""
+}
+
+func (m *Model) switchRebaseOntoField() {
+	if m.rebaseOntoField == rebaseOntoFieldOnto {
+		m.rebaseOntoField = rebaseOntoFieldUpstream
+		return
+	}
+	m.rebaseOntoField = rebaseOntoFieldOnto
+}
+
+func (m *Model) rebaseOntoInputBuffer() *string {
+	if m.rebaseOntoField == rebaseOntoFieldOnto {
+		return &m.rebaseOntoBuffer
+	}
+	return &m.rebaseUpstreamBuffer
+}
+
+func (m *Model) submitRebaseOntoPrompt() tea.Cmd {
+	onto := strings.TrimSpace(m.rebaseOntoBuffer)
+	upstream := strings.TrimSpace(m.rebaseUpstreamBuffer)
+	if onto == "" || upstream == "" {
+		return nil
+	}
+	repos := m.rebaseOntoRepos
+	m.dismissRebaseOntoPrompt()
+	if len(repos) == 0 {
+		return nil
+	}
+
+	opts := &command.RebaseOntoOptions{Onto: onto, Upstream: upstream}
+	m.jobsRunning = true
+	return func() tea.Msg {
+		for _, repo := range repos {
+			if repo == nil || repo.State == nil {
+				continue
+			}
+			repo.State.SetMessage(i18n.T("rebasing.."))
+			repo.SetWorkStatus(git.Pending)
+			j := &job.Job{
+				Repository: repo,
+				JobType:    job.RebaseOntoJob,
+				Options:    opts,
+			}
+			if err := j.Start(); err != nil {
+				repo.SetWorkStatus(git.Available)
+				repo.State.SetMessage(i18n.T("rebase failed: %v", err))
+			}
+		}
+		return jobCompletedMsg{}
+	}
+}
+
+// rebaseOnDefaultCmd rebases the current branch of repos onto their freshly
+// fetched default branch. Repositories without a remote or with local
+// changes are skipped.
+func (m *Model) rebaseOnDefaultCmd(repos []*git.Repository) tea.Cmd {
+	var jobs []panelJob
+	var rebased []*git.Repository
+	for _, repo := range filterRepositories(repos) {
+		if repo.State == nil || repo.State.Remote == nil || repo.State.Branch == nil || repoHasLocalChanges(repo) {
+			continue
+		}
+		rebased = append(rebased, repo)
+		jobs = append(jobs, panelJob{
+			repo:    repo,
+			jobType: job.RebaseDefaultJob,
+			message: i18n.T("rebasing onto the default branch"),
+		})
+	}
+	if len(jobs) == 0 {
+		m.notice = i18n.T("nothing to rebase: no remote or local changes")
+		return nil
+	}
+	return m.confirmDestructive(i18n.T("rebase onto the default branch"), rebased, func() tea.Cmd {
+		return m.startPanelJobs(jobs, NonePanel, false)
+	})
+}
@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestRebaseOntoPrompt_RunsAcrossSelection(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	runBranchTestGit(t, repo.AbsPath, "branch", "new-base")
+	m := New("pull", nil)
+	m.repositories = []*git.Repository{repo}
+	m.width = 80
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	require.True(t, m.rebaseOntoPromptActive)
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("new-base")})
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, rebaseOntoFieldUpstream, m.rebaseOntoField)
+	require.Contains(t, m.renderRebaseOntoPrompt(), "new-base")
+
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Nil(t, cmd, "upstream is required")
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("HEAD")})
+	_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	require.False(t, m.rebaseOntoPromptActive)
+	cmd()
+	require.Eventually(t, func() bool {
+		return !repo.WorkStatus().InFlight() && repo.State.Message() == "rebased onto new-base"
+	}, 10*time.Second, 20*time.Millisecond)
+}
+
+func TestRebaseOnDefault_SkipsReposWithoutRemoteOrWithChanges(t *testing.T) {
+	feature := queueTestRepo("feature", true, true, true)
+	local := queueTestRepo("local", true, false, false)
+	dirty := queueTestRepo("dirty", false, true, true)
+	dirty.State.Branch.HasLocalChanges = true
+	model := Model{repositories: []*git.Repository{feature, local, dirty}}
+
+	require.Nil(t, model.rebaseOnDefaultCmd([]*git.Repository{local, dirty}))
+	require.Equal(t, "nothing to rebase: no remote or local changes", model.notice)
+
+	require.NotNil(t, model.rebaseOnDefaultCmd(model.repositories))
+	require.Equal(t, git.Pending, feature.WorkStatus())
+	require.Equal(t, "rebasing onto the default branch", feature.State.Message())
+	require.NotEqual(t, git.Pending, local.WorkStatus())
+	require.NotEqual(t, git.Pending, dirty.WorkStatus())
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/job"
+)
+
+// reflogLimit caps the reflog entries the panel loads.
+const reflogLimit = 200
+
+// loadReflogEntries reads the HEAD reflog listed in the reflog panel.
+func (m *Model) loadReflogEntries(r *git.Repository) {
+	m.reflogEntries = nil
+	m.reflogCursor = 0
+	m.reflogOffset = 0
+	if r == nil {
+		return
+	}
+	entries, err := r.LoadReflog(reflogLimit)
+	if err != nil {
+		m.notice = err.Error()
+		return
+	}
+	m.reflogEntries = entries
+}
+
+func (m *Model) handleReflogPanelKey(key string) (tea.Model, tea.Cmd) {
+	count := len(m.reflogEntries)
+	if count == 0 {
+		return m, nil
+	}
+	viewport := m.stashViewportSize(count)
+
+	switch key {
+	case "up", "k":
+		wrapCursor(&m.reflogCursor, count, -1)
+	case "down", "j":
+		wrapCursor(&m.reflogCursor, count, 1)
+	case "home", "g":
+		m.reflogCursor = 0
+	case "end", "G":
+		m.reflogCursor = count - 1
+	case "enter", " ", "space", "c":
+		entry := m.reflogEntries[clampIndex(m.reflogCursor, count)]
+		m.sidePanel = NonePanel
+		return m, m.checkoutReflogEntryCmd(m.currentRepository(), entry)
+	case "d":
+		return m, m.openCommitDiff(m.currentRepository(), m.reflogEntries[clampIndex(m.reflogCursor, count)])
+	case "n":
+		entry := m.reflogEntries[clampIndex(m.reflogCursor, count)]
+		if repo := m.currentRepository(); repo != nil {
+			m.sidePanel = NonePanel
+			m.openBranchPromptAt(repo, entry.Hash)
+		}
+		return m, nil
+	}
+
+	ensureCursorVisible(&m.reflogCursor, &m.reflogOffset, count, viewport)
+	return m, nil
+}
+
+// checkoutReflogEntryCmd detaches HEAD at a reflog entry, e.g. to inspect
+// the state before a bad reset. Git refuses when local changes are in the way.
+func (m *Model) checkoutReflogEntryCmd(repo *git.Repository, entry *git.ReflogEntry) tea.Cmd {
+	if repo == nil || repo.State == nil || entry == nil {
+		return nil
+	}
+	return m.startPanelJobs([]panelJob{{
+		repo:    repo,
+		jobType: job.CheckoutJob,
+		options: &command.CheckoutOptions{Branch: entry.Hash, Detach: true},
+		message: fmt.Sprintf("checking out %s", entry.Selector),
+	}}, ReflogPanel, false)
+}
+
+// renderReflog renders the reflog entries of the selected repository.
+func (m *Model) renderReflog(contentWidth, maxLines int) string {
+	if contentWidth <= 0 || maxLines <= 0 {
+		return ""
+	}
+	if len(m.reflogEntries) == 0 {
+		return padToWidth("No reflog entries", contentWidth)
+	}
+
+	viewport := min(maxLines, len(m.reflogEntries))
+	lines := make([]string, 0, viewport)
+	for i := m.reflogOffset; i < len(m.reflogEntries) && len(lines) < viewport; i++ {
+		entry := m.reflogEntries[i]
+		label := truncateString(fmt.Sprintf("%s %s %s (%s)", entry.Hash, entry.Selector, entry.Action, entry.When), contentWidth-2)
+		if i == m.reflogCursor {
+			lines = append(lines, m.styles.SelectedItem.Render(padToWidth("> "+label, contentWidth)))
+		} else {
+			lines = append(lines, padToWidth("  "+label, contentWidth))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestReflog_BranchesFromEntry(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	runBranchTestGit(t, repo.AbsPath, "commit", "--allow-empty", "-m", "lost work")
+	runBranchTestGit(t, repo.AbsPath, "reset", "--hard", "HEAD~1")
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 100, height: 30}
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	require.Equal(t, ReflogPanel, m.sidePanel)
+	require.NotEmpty(t, m.reflogEntries)
+	require.Contains(t, m.renderReflog(80, 10), "reset: moving to HEAD~1")
+
+	// The entry before the reset still points at the lost commit.
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+	lost := m.reflogEntries[1]
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	require.Equal(t, NonePanel, m.sidePanel)
+	require.True(t, m.branchPromptActive)
+	require.Contains(t, m.renderBranchPrompt(), "From:   "+lost.Hash)
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("rescue")})
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	cmd()
+	require.Equal(t, "rescue", currentBranchName(t, repo.AbsPath))
+	require.Contains(t, runBranchTestGit(t, repo.AbsPath, "log", "-1", "--format=%s"), "lost work")
+}
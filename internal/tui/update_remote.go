@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+)
+
+func (m *Model) handleRemotePromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.remotePromptActive {
+		return false, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc":
+		m.dismissRemotePrompt()
+		return true, nil
+	case "tab":
+		m.switchRemoteField()
+		return true, nil
+	case "enter":
+		if m.remotePromptField == remoteFieldName {
+			m.remotePromptField = remoteFieldURL
+			return true, nil
+		}
+		return true, m.submitRemotePrompt()
+	case "backspace", "ctrl+h":
+		buffer := m.remoteInputBuffer()
+		runes := []rune(*buffer)
+		if len(runes) > 0 {
+			*buffer = string(runes[:len(runes)-1])
+		}
+		return true, nil
+	case " ":
+		// Neither remote names nor URLs contain spaces.
+		return true, nil
+	default:
+		if len(msg.Runes) > 0 {
+			*m.remoteInputBuffer() += string(msg.Runes)
+		}
+		return true, nil
+	}
+}
+
+// openRemotePrompt asks for a remote name and URL for the given repository.
+func (m *Model) openRemotePrompt(repo *git.Repository) {
+	if repo == nil || repo.IsLinkedWorktree() {
+		return
+	}
+	m.remotePromptActive = true
+	m.remotePromptRepo = repo
+	m.remotePromptField = remoteFieldURL
+	m.remoteNameBuffer = "origin"
+	if repo.HasRemote() {
+		m.remotePromptField = remoteFieldName
+		m.remoteNameBuffer = ""
+	}
+	m.remoteURLBuffer = ""
+}
+
+func (m *Model) dismissRemotePrompt() {
+	m.remotePromptActive = false
+	m.remotePromptRepo = nil
+	m.remotePromptField = remoteFieldName
+	m.remoteNameBuffer = ""
+	m.remoteURLBuffer = ""
+}
+
+func (m *Model) switchRemoteField() {
+	if m.remotePromptField == remoteFieldName {
+		m.remotePromptField = remoteFieldURL
+		return
+	}
+	m.remotePromptField = remoteFieldName
+}
+
+func (m *Model) remoteInputBuffer() *string {
+	if m.remotePromptField == remoteFieldName {
+		return &m.remoteNameBuffer
+	}
+	return &m.remoteURLBuffer
+}
+
+func (m *Model) submitRemotePrompt() tea.Cmd {
+	repo := m.remotePromptRepo
+	name := strings.TrimSpace(m.remoteNameBuffer)
+	url := strings.TrimSpace(m.remoteURLBuffer)
+	m.dismissRemotePrompt()
+
+	if repo == nil {
+		return nil
+	}
+	if name == "" || url == "" {
+		if repo.State != nil {
+			repo.State.SetMessage(i18n.T("remote name and URL required"))
+		}
+		return nil
+	}
+	return m.addRemoteCmd(repo, name, url)
+}
+
+func (m *Model) addRemoteCmd(repo *git.Repository, name, url string) tea.Cmd {
+	if repo == nil || name == "" || url == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		if repo.State != nil {
+			repo.State.SetMessage(fmt.Sprintf("adding remote %s", name))
+		}
+		if _, err := command.RunRecorded(repo, command.OperationGit, []string{"remote", "add", name, url}); err != nil {
+			if repo.State != nil {
+				repo.State.SetMessage(err.Error())
+			}
+			return errMsg{err: fmt.Errorf("add remote %s in %s: %w", name, repo.Name, err)}
+		}
+		if repo.State != nil {
+			repo.State.NoUpstream = false
+			repo.State.SetMessage(fmt.Sprintf("added remote %s", name))
+		}
+		if err := scheduleRefresh(repo); err != nil {
+			return errMsg{err: fmt.Errorf("refresh repository %s: %w", repo.Name, err)}
+		}
+		return repoActionResultMsg{panel: RemotePanel}
+	}
+}
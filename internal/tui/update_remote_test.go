@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestHandleOverviewKeys_ROpensRemotePromptWithoutRemote(t *testing.T) {
+	repo := &git.Repository{
+		Name: "alpha",
+		State: &git.RepositoryState{
+			Branch: &git.Branch{Name: "main"},
+		},
+	}
+	model := Model{repositories: []*git.Repository{repo}}
+
+	_, cmd := model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+
+	require.Nil(t, cmd)
+	require.True(t, model.remotePromptActive)
+	require.Same(t, repo, model.remotePromptRepo)
+	require.Equal(t, "origin", model.remoteNameBuffer)
+	require.Equal(t, remoteFieldURL, model.remotePromptField)
+	require.Equal(t, NonePanel, model.sidePanel)
+}
+
+func TestRemotePrompt_AddsRemote(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "local")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	for _, args := range [][]string{
+		{"init", "--initial-branch=main"},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoErrorf(t, err, "git %v failed: %s", args, string(out))
+	}
+	repo, err := git.InitializeRepo(dir)
+	require.NoError(t, err)
+	require.False(t, repo.HasRemote())
+
+	model := Model{repositories: []*git.Repository{repo}}
+	model.openRemotePrompt(repo)
+	for _, r := range "https://example.com/org/local.git" {
+		handled, _ := model.handleRemotePromptKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		require.True(t, handled)
+	}
+	handled, cmd := model.handleRemotePromptKey(tea.KeyMsg{Type: tea.KeyEnter})
+	require.True(t, handled)
+	require.NotNil(t, cmd)
+	require.False(t, model.remotePromptActive)
+
+	repo.SetWorkStatusSilent(git.Queued)
+	msg := cmd()
+	require.Equal(t, repoActionResultMsg{panel: RemotePanel}, msg)
+
+	out, err := exec.Command("git", "-C", dir, "remote", "get-url", "origin").CombinedOutput()
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/org/local.git\n", string(out))
+	require.Eventually(t, func() bool {
+		return !repo.WorkStatus().InFlight()
+	}, 10*time.Second, 20*time.Millisecond)
+}
+
+func TestRemotePanel_USetsUpstreamForTaggedRepos(t *testing.T) {
+	alpha := initBranchCreationRepo(t, "alpha")
+	beta := initBranchCreationRepo(t, "beta")
+	for _, repo := range []*git.Repository{alpha, beta} {
+		runBranchTestGit(t, repo.AbsPath, "push", "origin", "main:release")
+		runBranchTestGit(t, repo.AbsPath, "fetch", "origin")
+		require.NoError(t, repo.Refresh())
+		repo.SetWorkStatusSilent(git.Queued)
+	}
+
+	model := Model{repositories: []*git.Repository{alpha, beta}, sidePanel: RemotePanel}
+	items := model.remotePanelItems()
+	for i, item := range items {
+		if item.FullName == "origin/release" {
+			model.remoteBranchCursor = i
+		}
+	}
+	require.Equal(t, "origin/release", items[model.remoteBranchCursor].FullName)
+
+	_, cmd := model.handleRemotePanelKey("u")
+	require.NotNil(t, cmd)
+	require.Equal(t, repoActionResultMsg{panel: RemotePanel}, cmd())
+	require.Eventually(t, func() bool {
+		return !alpha.WorkStatus().InFlight() && !beta.WorkStatus().InFlight()
+	}, 10*time.Second, 20*time.Millisecond)
+
+	for _, repo := range []*git.Repository{alpha, beta} {
+		out := runBranchTestGit(t, repo.AbsPath, "rev-parse", "--abbrev-ref", "main@{upstream}")
+		require.Equal(t, "origin/release\n", out)
+	}
+}
+
+func TestSyncForks_OnlyStartsInForks(t *testing.T) {
+	fork := &git.Repository{
+		Name:    "fork",
+		Remotes: []*git.Remote{{Name: "origin"}, {Name: "upstream"}},
+		State:   &git.RepositoryState{Branch: &git.Branch{Name: "main"}},
+	}
+	clone := &git.Repository{
+		Name:    "clone",
+		Remotes: []*git.Remote{{Name: "origin"}},
+		State:   &git.RepositoryState{Branch: &git.Branch{Name: "main"}},
+	}
+	model := Model{repositories: []*git.Repository{fork, clone}}
+
+	require.Nil(t, model.syncForksCmd([]*git.Repository{clone}))
+	require.Equal(t, "no fork selected (needs origin and upstream remotes)", model.notice)
+
+	require.NotNil(t, model.syncForksCmd([]*git.Repository{fork, clone}))
+	require.Equal(t, git.Pending, fork.WorkStatus())
+	require.Equal(t, "syncing fork", fork.State.Message())
+	require.NotEqual(t, git.Pending, clone.WorkStatus())
+}
+
+func TestURLRewritePrompt_PreviewsAndSetsTheNewURLs(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	runBranchTestGit(t, repo.AbsPath, "remote", "set-url", "origin", "git@github.com:old-org/alpha.git")
+	repo, err := git.InitializeRepo(repo.AbsPath)
+	require.NoError(t, err)
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 200, height: 40, sidePanel: RemotePanel}
+
+	_, _ = m.handleFocusKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	require.True(t, m.urlRewritePromptActive)
+	for _, r := range "old-org" {
+		_, _ = m.handleURLRewritePromptKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	_, cmd := m.handleURLRewritePromptKey(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Nil(t, cmd, "enter in the find field moves to the replacement")
+	_, _ = m.handleURLRewritePromptKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("new-org"), Paste: true})
+
+	prompt := ansi.Strip(m.renderURLRewritePrompt())
+	require.Contains(t, prompt, "alpha origin: git@github.com:old-org/alpha.git")
+	require.Contains(t, prompt, "→ git@github.com:new-org/alpha.git")
+
+	_, cmd = m.handleURLRewritePromptKey(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	require.False(t, m.urlRewritePromptActive)
+	require.Equal(t, git.Pending, repo.WorkStatus())
+	require.IsType(t, repoActionResultMsg{}, cmd())
+	require.Eventually(t, func() bool {
+		return !repo.WorkStatus().InFlight() && repo.State.Message() == "origin now points at git@github.com:new-org/alpha.git"
+	}, 10*time.Second, 20*time.Millisecond)
+	require.Equal(t, "git@github.com:new-org/alpha.git", strings.TrimSpace(runBranchTestGit(t, repo.AbsPath, "remote", "get-url", "origin")))
+}
+
+func TestSetRemoteURLJobs_OneJobPerRepository(t *testing.T) {
+	alpha := &git.Repository{Name: "alpha"}
+	beta := &git.Repository{Name: "beta"}
+	rewrites := []command.RemoteURLRewrite{
+		{Repository: alpha, Remote: "origin", New: "a"},
+		{Repository: beta, Remote: "origin", New: "b"},
+		{Repository: alpha, Remote: "upstream", New: "c"},
+	}
+
+	jobs := setRemoteURLJobs(rewrites)
+	require.Len(t, jobs, 2)
+	require.Equal(t, alpha, jobs[0].repo)
+	require.Equal(t, []command.RemoteURLRewrite{rewrites[0], rewrites[2]}, jobs[0].options)
+	require.Equal(t, beta, jobs[1].repo)
+	require.Equal(t, []command.RemoteURLRewrite{rewrites[1]}, jobs[1].options)
+}
+
+func TestPruneRemotes_RunsOnTheGitQueue(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	runBranchTestGit(t, repo.AbsPath, "push", "origin", "main:stale")
+	runBranchTestGit(t, repo.AbsPath, "fetch", "origin")
+	runBranchTestGit(t, repo.AbsPath, "push", "origin", "--delete", "stale")
+	runBranchTestGit(t, repo.AbsPath, "update-ref", "refs/remotes/origin/stale", "main")
+	require.NoError(t, repo.Refresh())
+	model := Model{repositories: []*git.Repository{repo}, sidePanel: RemotePanel}
+
+	cmd := model.pruneRemotesCmd([]*git.Repository{repo})
+	require.NotNil(t, cmd)
+	require.Equal(t, git.Pending, repo.WorkStatus())
+	require.Equal(t, "pruning origin", repo.State.Message())
+	require.Equal(t, repoActionResultMsg{panel: RemotePanel}, cmd())
+	require.Eventually(t, func() bool {
+		return !repo.WorkStatus().InFlight() && repo.State.Message() == "origin: pruned 1 stale ref"
+	}, 10*time.Second, 20*time.Millisecond)
+	require.Error(t, exec.Command("git", "-C", repo.AbsPath, "rev-parse", "--verify", "--quiet", "refs/remotes/origin/stale").Run())
+}
@@ -158,7 +158,7 @@ func (m *Model) adjustCommitScroll(delta int) bool {
 	if repo == nil {
 		return false
 	}
-	colWidths := calculateColumnWidths(m.width, m.repositories)
+	colWidths := columnWidthsWithAge(m.width, m.repositories, m.ageColumnForRepo)
 	contentWidth := colWidths.commitMsg - 1
 	if contentWidth <= 0 {
 		return false
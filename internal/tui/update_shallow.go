@@ -0,0 +1,29 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/job"
+)
+
+// unshallowCmd fetches the complete history of the shallow clones among
+// repos.
+func (m *Model) unshallowCmd(repos []*git.Repository) tea.Cmd {
+	var jobs []panelJob
+	for _, repo := range filterRepositories(repos) {
+		if repo.IsShallow() && repo.State != nil {
+			jobs = append(jobs, panelJob{
+				repo:    repo,
+				jobType: job.FetchJob,
+				options: &command.FetchOptions{Unshallow: true},
+				message: "unshallowing",
+			})
+		}
+	}
+	if len(jobs) == 0 {
+		m.notice = "no shallow clone selected"
+		return nil
+	}
+	return m.startPanelJobs(jobs, NonePanel, false)
+}
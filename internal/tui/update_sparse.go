@@ -0,0 +1,28 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+	"github.com/thorstenhirsch/gitbatch/internal/job"
+)
+
+// sparseReapplyCmd re-applies the sparse-checkout patterns of the sparse
+// repositories among repos.
+func (m *Model) sparseReapplyCmd(repos []*git.Repository) tea.Cmd {
+	var jobs []panelJob
+	for _, repo := range filterRepositories(repos) {
+		if repo.IsSparse() && repo.State != nil {
+			jobs = append(jobs, panelJob{
+				repo:    repo,
+				jobType: job.SparseReapplyJob,
+				message: i18n.T("reapplying sparse-checkout"),
+			})
+		}
+	}
+	if len(jobs) == 0 {
+		m.notice = "no sparse checkout selected"
+		return nil
+	}
+	return m.startPanelJobs(jobs, NonePanel, false)
+}
@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestSparseReapply_OnlySparseRepositories(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles()}
+	require.Nil(t, m.sparseReapplyCmd([]*git.Repository{repo}))
+	require.Equal(t, "no sparse checkout selected", m.notice)
+
+	runBranchTestGit(t, repo.AbsPath, "sparse-checkout", "set", "docs")
+	require.NoError(t, repo.Refresh())
+	require.Equal(t, "alpha "+sparseSymbol, repoDisplayName(repo))
+
+	repo.SetWorkStatusSilent(git.Queued)
+	cmd := m.sparseReapplyCmd([]*git.Repository{repo})
+	require.NotNil(t, cmd)
+	require.Equal(t, repoActionResultMsg{panel: NonePanel}, cmd())
+	require.Eventually(t, func() bool {
+		return !repo.WorkStatus().InFlight() && repo.State.Message() == "sparse-checkout reapplied"
+	}, 10*time.Second, 20*time.Millisecond)
+}
@@ -7,6 +7,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/thorstenhirsch/gitbatch/internal/command"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
 	"github.com/thorstenhirsch/gitbatch/internal/job"
 )
 
@@ -93,7 +94,7 @@ func (m *Model) submitStash() tea.Cmd {
 			if stashMsg == "" && repo.State.Branch != nil {
 				stashMsg = "WIP on " + repo.State.Branch.Name
 			}
-			repo.State.Message = "stashing.."
+			repo.State.SetMessage(i18n.T("stashing.."))
 			repo.SetWorkStatus(git.Pending)
 			j := &job.Job{
 				Repository: repo,
@@ -102,7 +103,7 @@ func (m *Model) submitStash() tea.Cmd {
 			}
 			if err := j.Start(); err != nil {
 				repo.SetWorkStatus(git.Available)
-				repo.State.Message = "stash failed: " + err.Error()
+				repo.State.SetMessage(i18n.T("stash failed: %v", err))
 				continue
 			}
 		}
@@ -177,11 +178,14 @@ func (m *Model) handleStashActionPanelKey(key string) (tea.Model, tea.Cmd) {
 		m.stashCursor = 0
 	case "end", "G":
 		m.stashCursor = count - 1
-	case "enter", " ", "space":
+	case "enter":
+		item := items[clampIndex(m.stashCursor, count)]
+		return m, m.openStashDiff(m.stashAction, m.stashActionRepos(), item)
+	case " ", "space":
 		item := items[clampIndex(m.stashCursor, count)]
 		repos := m.stashActionRepos()
 		m.sidePanel = NonePanel
-		return m, m.executeStashActionCmd(m.stashAction, repos, item)
+		return m, m.confirmStashAction(m.stashAction, repos, item)
 	}
 
 	m.ensureStashCursorVisible(count, viewport)
@@ -217,7 +221,7 @@ func (m *Model) executeStashAction(action stashActionType, repos []*git.Reposito
 			var j *job.Job
 			switch action {
 			case stashActionPop:
-				repo.State.Message = "popping stash.."
+				repo.State.SetMessage(i18n.T("popping stash.."))
 				repo.SetWorkStatus(git.Pending)
 				j = &job.Job{
 					Repository: repo,
@@ -225,7 +229,7 @@ func (m *Model) executeStashAction(action stashActionType, repos []*git.Reposito
 					Options:    &command.StashPopOptions{StashRef: stashRef},
 				}
 			case stashActionDrop:
-				repo.State.Message = "dropping stash.."
+				repo.State.SetMessage(i18n.T("dropping stash.."))
 				repo.SetWorkStatus(git.Pending)
 				j = &job.Job{
 					Repository: repo,
@@ -237,12 +241,23 @@ func (m *Model) executeStashAction(action stashActionType, repos []*git.Reposito
 			}
 			if err := j.Start(); err != nil {
 				repo.SetWorkStatus(git.Available)
-				repo.State.Message = "stash operation failed: " + err.Error()
+				repo.State.SetMessage(i18n.T("stash operation failed: %v", err))
 			}
 		}
 	}()
 }
 
+// confirmStashAction pops or drops the stash; dropping it may need a typed
+// confirmation.
+func (m *Model) confirmStashAction(action stashActionType, repos []*git.Repository, item stashPanelItem) tea.Cmd {
+	if action != stashActionDrop {
+		return m.executeStashActionCmd(action, repos, item)
+	}
+	return m.confirmDestructive(i18n.T("drop %s", item.Description), repos, func() tea.Cmd {
+		return m.executeStashActionCmd(action, repos, item)
+	})
+}
+
 func (m *Model) executeStashActionCmd(action stashActionType, repos []*git.Repository, item stashPanelItem) tea.Cmd {
 	if len(repos) == 0 {
 		return nil
@@ -257,7 +272,7 @@ func (m *Model) executeStashActionCmd(action stashActionType, repos []*git.Repos
 			var j *job.Job
 			switch action {
 			case stashActionPop:
-				repo.State.Message = "popping stash.."
+				repo.State.SetMessage(i18n.T("popping stash.."))
 				repo.SetWorkStatus(git.Pending)
 				j = &job.Job{
 					Repository: repo,
@@ -265,7 +280,7 @@ func (m *Model) executeStashActionCmd(action stashActionType, repos []*git.Repos
 					Options:    &command.StashPopOptions{StashRef: stashRef},
 				}
 			case stashActionDrop:
-				repo.State.Message = "dropping stash.."
+				repo.State.SetMessage(i18n.T("dropping stash.."))
 				repo.SetWorkStatus(git.Pending)
 				j = &job.Job{
 					Repository: repo,
@@ -277,7 +292,7 @@ func (m *Model) executeStashActionCmd(action stashActionType, repos []*git.Repos
 			}
 			if err := j.Start(); err != nil {
 				repo.SetWorkStatus(git.Available)
-				repo.State.Message = "stash operation failed: " + err.Error()
+				repo.State.SetMessage(i18n.T("stash operation failed: %v", err))
 			}
 		}
 		return jobCompletedMsg{}
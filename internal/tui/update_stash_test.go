@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestStashPanel_ShowsTheDiffBeforeDropping(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	for _, content := range []string{"first", "second"} {
+		require.NoError(t, os.WriteFile(filepath.Join(repo.AbsPath, "README.md"), []byte(content), 0o644))
+		runBranchTestGit(t, repo.AbsPath, "stash", "push", "-m", content)
+	}
+	repo, err := git.InitializeRepo(repo.AbsPath)
+	require.NoError(t, err)
+	require.Len(t, repo.Stasheds, 2)
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 120, height: 40}
+
+	m.openStashAction(stashActionDrop)
+	require.Equal(t, StashActionPanel, m.sidePanel)
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	require.True(t, m.diffActive)
+	m.Update(cmd())
+	view := ansi.Strip(m.renderDiff())
+	require.Contains(t, view, "stash@{1}")
+	require.Contains(t, view, "+first")
+	require.Contains(t, view, "enter: drop the stash | esc: back")
+
+	// Esc goes back to the panel without touching the stash.
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	require.False(t, m.diffActive)
+	require.Equal(t, StashActionPanel, m.sidePanel)
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.True(t, m.diffActive)
+	_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	require.False(t, m.diffActive)
+	require.Equal(t, NonePanel, m.sidePanel)
+	cmd()
+	require.Eventually(t, func() bool {
+		stashes := strings.TrimSpace(runBranchTestGit(t, repo.AbsPath, "stash", "list"))
+		return strings.Count(stashes, "\n") == 0 && strings.Contains(stashes, "second")
+	}, 5*time.Second, 50*time.Millisecond, "only the previewed stash is dropped")
+}
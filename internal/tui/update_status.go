@@ -0,0 +1,102 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// statusFileEditedMsg is sent when the editor opened from the status panel
+// exits.
+type statusFileEditedMsg struct {
+	repo *git.Repository
+}
+
+// statusFilesLoadedMsg carries the changed files of a repository for the
+// status panel.
+type statusFilesLoadedMsg struct {
+	repo  *git.Repository
+	files []*git.File
+}
+
+// loadStatusFilesCmd reads the changed files listed in the status panel in
+// the background, since git status can take a while in large worktrees.
+func (m *Model) loadStatusFilesCmd(r *git.Repository) tea.Cmd {
+	if r == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		files, err := r.LoadFiles()
+		if err != nil {
+			files = nil
+		}
+		return statusFilesLoadedMsg{repo: r, files: files}
+	}
+}
+
+// handleStatusFilesLoaded shows the files unless the panel was closed or
+// moved on to another repository meanwhile.
+func (m *Model) handleStatusFilesLoaded(msg statusFilesLoadedMsg) (tea.Model, tea.Cmd) {
+	if m.sidePanel != StatusPanel || m.currentRepository() != msg.repo {
+		return m, nil
+	}
+	m.statusFiles = msg.files
+	m.statusFileCursor = clampIndex(m.statusFileCursor, len(m.statusFiles))
+	return m, nil
+}
+
+func (m *Model) handleStatusPanelKey(key string) (tea.Model, tea.Cmd) {
+	count := len(m.statusFiles)
+	switch key {
+	case "up", "k":
+		if count > 0 {
+			wrapCursor(&m.statusFileCursor, count, -1)
+		}
+	case "down", "j":
+		if count > 0 {
+			wrapCursor(&m.statusFileCursor, count, 1)
+		}
+	case "enter":
+		return m, m.startQueue()
+	case "e":
+		if count > 0 {
+			return m, m.editStatusFile(m.statusFiles[clampIndex(m.statusFileCursor, count)])
+		}
+	case "d":
+		if count > 0 {
+			return m, m.openFileDiff(m.currentRepository(), m.statusFiles[clampIndex(m.statusFileCursor, count)])
+		}
+	}
+	return m, nil
+}
+
+// editStatusFile suspends the interface while the file is open in the
+// user's editor; the repository is refreshed when the editor exits.
+func (m *Model) editStatusFile(file *git.File) tea.Cmd {
+	r := m.currentRepository()
+	if r == nil || file == nil {
+		return nil
+	}
+	if file.X == git.StatusDeleted || file.Y == git.StatusDeleted {
+		m.notice = file.Name + " is deleted"
+		return nil
+	}
+	return tea.ExecProcess(editorCommand(file.AbsPath, 0), func(err error) tea.Msg {
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return statusFileEditedMsg{repo: r}
+	})
+}
+
+func (m *Model) handleStatusFileEdited(msg statusFileEditedMsg) (tea.Model, tea.Cmd) {
+	var load tea.Cmd
+	if m.sidePanel == StatusPanel && m.currentRepository() == msg.repo {
+		load = m.loadStatusFilesCmd(msg.repo)
+	}
+	command.RequestExternalRefresh(msg.repo)
+	if m.updateJobsRunningFlag() {
+		return m, tea.Batch(load, m.ensureTicking())
+	}
+	return m, load
+}
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestStatusPanel_OpensChangedFiles(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	require.NoError(t, os.WriteFile(filepath.Join(repo.AbsPath, "notes.txt"), []byte("new"), 0o644))
+	require.NoError(t, os.Remove(filepath.Join(repo.AbsPath, "README.md")))
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 120, height: 40}
+
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	require.NotNil(t, cmd)
+	require.Empty(t, m.statusFiles, "the files are read in the background")
+	m.Update(cmd())
+	require.Len(t, m.statusFiles, 2)
+	require.Contains(t, m.renderStatus(repo, 80, 40), "Changed files  2")
+
+	_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	require.NotNil(t, cmd)
+
+	// README.md is deleted, so there is nothing to open.
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+	require.Equal(t, 1, m.statusFileCursor)
+	_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	require.Nil(t, cmd)
+	require.Equal(t, "README.md is deleted", m.notice)
+
+	require.NoError(t, os.Remove(filepath.Join(repo.AbsPath, "notes.txt")))
+	m.handleStatusFileEdited(statusFileEditedMsg{repo: repo})
+	m.Update(m.loadStatusFilesCmd(repo)())
+	require.Len(t, m.statusFiles, 1)
+	require.Equal(t, 0, m.statusFileCursor)
+}
+
+func TestStatusPanel_ShowsTheDiffOfTheSelectedFile(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	require.NoError(t, os.WriteFile(filepath.Join(repo.AbsPath, "README.md"), []byte("changed"), 0o644))
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 120, height: 40}
+	m.activatePanel(StatusPanel)
+	m.Update(m.loadStatusFilesCmd(repo)())
+	require.Len(t, m.statusFiles, 1)
+
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	require.NotNil(t, cmd)
+	require.True(t, m.diffActive)
+	require.Contains(t, m.renderDiff(), "loading diff...")
+
+	m.Update(cmd())
+	require.Contains(t, m.diffLines, worktreeDiffAdditionStyle.Render("+changed"))
+	require.Contains(t, ansi.Strip(m.renderDiff()), "-hello")
+
+	// The overlay takes the keys until it is closed.
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	require.Nil(t, cmd)
+	require.False(t, m.diffActive)
+	require.Equal(t, StatusPanel, m.sidePanel)
+}
+
+func TestStatusPanel_EnterStartsTheQueue(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	require.NoError(t, os.WriteFile(filepath.Join(repo.AbsPath, "README.md"), []byte("changed"), 0o644))
+	m := &Model{repositories: []*git.Repository{repo}, styles: DefaultStyles(), width: 120, height: 40}
+	m.activatePanel(StatusPanel)
+	m.Update(m.loadStatusFilesCmd(repo)())
+	require.Len(t, m.statusFiles, 1)
+
+	_, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	require.IsType(t, batchStartedMsg{}, cmd(), "Enter starts the tagged jobs instead of opening the file")
+
+	// A closed panel ignores files that arrive late.
+	m.activatePanel(NonePanel)
+	m.Update(statusFilesLoadedMsg{repo: repo})
+	require.Len(t, m.statusFiles, 1)
+}
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+func TestRepositoryLoadedMsgStreamsRows(t *testing.T) {
+	beta := initBranchCreationRepo(t, "beta")
+	alpha := initBranchCreationRepo(t, "alpha")
+	broken := initBranchCreationRepo(t, "broken")
+
+	model := New("pull", []string{"/a", "/b", "/c"})
+
+	_, cmd := model.Update(repositoryLoadedMsg{repo: beta})
+	require.NotNil(t, cmd)
+	model.Update(repositoryLoadedMsg{repo: broken})
+	model.Update(repositoryLoadedMsg{repo: beta})
+	require.Equal(t, []*git.Repository{beta, broken}, model.repositories)
+	require.True(t, model.loading)
+
+	// broken failed to load its branches and is not part of the final set
+	model.Update(repositoriesLoadedMsg{repos: []*git.Repository{alpha, beta}})
+	require.Equal(t, []*git.Repository{alpha, beta}, model.repositories)
+	require.False(t, model.loading)
+
+	_, cmd = model.Update(repositoryLoadedMsg{repo: alpha})
+	require.Nil(t, cmd)
+	require.Len(t, model.repositories, 2)
+}
+
+func TestForceRefreshAllCmd_Debounces(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	model := New("pull", nil)
+	model.repositories = []*git.Repository{repo}
+
+	_, cmd := model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyCtrlR})
+	require.NotNil(t, cmd)
+	require.True(t, model.jobsRunning)
+
+	_, cmd = model.handleOverviewKeys(tea.KeyMsg{Type: tea.KeyCtrlR})
+	require.Nil(t, cmd)
+}
+
+func TestHandleLazygitClosed_RestoresStateWhenUnchanged(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	repo.State.SetMessage("up to date")
+	saved := repo.State.Copy()
+	before := repo.TakeSnapshot()
+	repo.SetWorkStatus(git.Working)
+
+	model := New("pull", nil)
+	model.handleLazygitClosed(lazygitClosedMsg{repo: repo, before: before, originalState: saved})
+
+	require.Empty(t, model.notice)
+	require.Equal(t, "up to date", repo.State.Message())
+}
+
+func TestHandleLazygitClosed_ReportsBranchChange(t *testing.T) {
+	repo := initBranchCreationRepo(t, "alpha")
+	before := repo.TakeSnapshot()
+	runBranchTestGit(t, repo.AbsPath, "checkout", "-b", "feature")
+	repo.SetWorkStatus(git.Working)
+
+	model := New("pull", nil)
+	_, cmd := model.handleLazygitClosed(lazygitClosedMsg{repo: repo, before: before, originalState: repo.State.Copy()})
+
+	require.NotNil(t, cmd)
+	require.Equal(t, "refreshed alpha: branch changed from main to feature", model.notice)
+	require.Equal(t, git.Available, repo.WorkStatus())
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	require.Empty(t, model.notice)
+}
+
+func TestNestedRepositoriesPointAtTheClosestEnclosingOne(t *testing.T) {
+	inner := &git.Repository{Name: "inner", AbsPath: "/src/outer/vendor/inner"}
+	outer := &git.Repository{Name: "outer", AbsPath: "/src/outer"}
+	vendor := &git.Repository{Name: "vendor", AbsPath: "/src/outer/vendor"}
+	sibling := &git.Repository{Name: "outer-docs", AbsPath: "/src/outer-docs"}
+
+	var rs []*git.Repository
+	for _, r := range []*git.Repository{inner, outer, sibling, vendor} {
+		rs = append(rs, r)
+		linkEnclosingRepository(r, rs)
+	}
+	require.Nil(t, outer.Enclosing)
+	require.Nil(t, sibling.Enclosing, "a common name prefix is no nesting")
+	require.Same(t, outer, vendor.Enclosing)
+	require.Same(t, vendor, inner.Enclosing)
+	require.Equal(t, "vendor/inner", repoDisplayName(inner))
+	require.Equal(t, "outer/vendor", repoDisplayName(vendor))
+}
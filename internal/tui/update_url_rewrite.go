@@ -0,0 +1,193 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
+	"github.com/thorstenhirsch/gitbatch/internal/job"
+)
+
+// urlRewritePreviewLines caps the rewrites the prompt previews.
+const urlRewritePreviewLines = 8
+
+func (m *Model) handleURLRewritePromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !m.urlRewritePromptActive {
+		return false, nil
+	}
+	if msg.Paste {
+		*m.urlRewriteInputBuffer() += sanitizeCredentialPaste(string(msg.Runes))
+		return true, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return true, tea.Quit
+	case "esc":
+		m.dismissURLRewritePrompt()
+		return true, nil
+	case "tab":
+		m.switchURLRewriteField()
+		return true, nil
+	case "enter":
+		if m.urlRewriteField == urlRewriteFieldFind {
+			m.urlRewriteField = urlRewriteFieldReplace
+			return true, nil
+		}
+		return true, m.submitURLRewritePrompt()
+	case "backspace", "ctrl+h":
+		buffer := m.urlRewriteInputBuffer()
+		runes := []rune(*buffer)
+		if len(runes) > 0 {
+			*buffer = string(runes[:len(runes)-1])
+		}
+		return true, nil
+	case " ":
+		// URLs cannot contain spaces.
+		return true, nil
+	default:
+		if len(msg.Runes) > 0 {
+			*m.urlRewriteInputBuffer() += string(msg.Runes)
+		}
+		return true, nil
+	}
+}
+
+// openURLRewritePrompt asks for a pattern and its replacement in the remote
+// URLs of repos, e.g. to follow a move to another host or organization.
+func (m *Model) openURLRewritePrompt(repos []*git.Repository) {
+	repos = filterRepositories(repos)
+	if len(repos) == 0 {
+		return
+	}
+	m.urlRewritePromptActive = true
+	m.urlRewriteRepos = repos
+	m.urlRewriteField = urlRewriteFieldFind
+	m.urlFindBuffer = ""
+	m.urlReplaceBuffer = ""
+}
+
+func (m *Model) dismissURLRewritePrompt() {
+	m.urlRewritePromptActive = false
+	m.urlRewriteRepos = nil
+	m.urlRewriteField = urlRewriteFieldFind
+	m.urlFindBuffer = ""
+	m.urlReplaceBuffer = ""
+}
+
+func (m *Model) switchURLRewriteField() {
+	if m.urlRewriteField == urlRewriteFieldFind {
+		m.urlRewriteField = urlRewriteFieldReplace
+		return
+	}
+	m.urlRewriteField = urlRewriteFieldFind
+}
+
+func (m *Model) urlRewriteInputBuffer() *string {
+	if m.urlRewriteField == urlRewriteFieldFind {
+		return &m.urlFindBuffer
+	}
+	return &m.urlReplaceBuffer
+}
+
+// urlRewrites previews the entered search and replace.
+func (m *Model) urlRewrites() ([]command.RemoteURLRewrite, error) {
+	if m.urlFindBuffer == "" {
+		return nil, nil
+	}
+	find, err := regexp.Compile(m.urlFindBuffer)
+	if err != nil {
+		return nil, err
+	}
+	return command.PlanRemoteURLRewrites(m.urlRewriteRepos, find, m.urlReplaceBuffer), nil
+}
+
+func (m *Model) submitURLRewritePrompt() tea.Cmd {
+	rewrites, err := m.urlRewrites()
+	if err != nil || len(rewrites) == 0 {
+		return nil
+	}
+	m.dismissURLRewritePrompt()
+	return m.startPanelJobs(setRemoteURLJobs(rewrites), RemotePanel, false)
+}
+
+// setRemoteURLJobs groups the rewrites by repository into one job each, in
+// the order of the rewrites. The git queue refreshes the repositories
+// afterwards, which picks up the new URLs.
+func setRemoteURLJobs(rewrites []command.RemoteURLRewrite) []panelJob {
+	var repos []*git.Repository
+	byRepo := make(map[*git.Repository][]command.RemoteURLRewrite)
+	for _, rewrite := range rewrites {
+		if _, ok := byRepo[rewrite.Repository]; !ok {
+			repos = append(repos, rewrite.Repository)
+		}
+		byRepo[rewrite.Repository] = append(byRepo[rewrite.Repository], rewrite)
+	}
+	jobs := make([]panelJob, 0, len(repos))
+	for _, repo := range repos {
+		jobs = append(jobs, panelJob{
+			repo:    repo,
+			jobType: job.SetRemoteURLJob,
+			options: byRepo[repo],
+			message: i18n.T("rewriting remote URLs"),
+		})
+	}
+	return jobs
+}
+
+func (m *Model) renderURLRewritePrompt() string {
+	if !m.urlRewritePromptActive {
+		return ""
+	}
+	panelWidth := 80
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+
+	findIndicator := " "
+	replaceIndicator := " "
+	if m.urlRewriteField == urlRewriteFieldFind {
+		findIndicator = ">"
+	} else {
+		replaceIndicator = ">"
+	}
+	lines := []string{
+		m.styles.PanelTitle.Render(i18n.T("Rewrite the remote URLs of %d repositories", len(m.urlRewriteRepos))),
+		"",
+		findIndicator + " " + i18n.T("Find (regexp): %s", truncateString(m.urlFindBuffer, contentWidth-17)),
+		replaceIndicator + " " + i18n.T("Replace:       %s", truncateString(m.urlReplaceBuffer, contentWidth-17)),
+		"",
+	}
+
+	rewrites, err := m.urlRewrites()
+	switch {
+	case err != nil:
+		lines = append(lines, m.styles.Error.Render(truncateString(err.Error(), contentWidth)))
+	case m.urlFindBuffer == "":
+		lines = append(lines, truncateString(i18n.T("e.g. old-org → new-org or github\\.com → gitlab.com; $1 is a submatch"), contentWidth))
+	case len(rewrites) == 0:
+		lines = append(lines, i18n.T("No remote URL matches."))
+	default:
+		for i, rewrite := range rewrites {
+			if i == urlRewritePreviewLines {
+				lines = append(lines, i18n.T("... and %d more", len(rewrites)-i))
+				break
+			}
+			lines = append(lines,
+				truncateString(fmt.Sprintf("%s %s: %s", rewrite.Repository.Name, rewrite.Remote, rewrite.Old), contentWidth),
+				truncateString("  → "+rewrite.New, contentWidth),
+			)
+		}
+	}
+
+	lines = append(lines, "", i18n.T("enter: next/rewrite | tab: switch field | esc: cancel"))
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
@@ -7,6 +7,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
 )
 
 func (m *Model) handleWorktreePromptKey(msg tea.KeyMsg) (bool, tea.Cmd) {
@@ -116,11 +117,11 @@ func (m *Model) submitWorktreePrompt() tea.Cmd {
 		return nil
 	}
 	if branchName == "" {
-		repo.State.Message = "worktree branch name required"
+		repo.State.SetMessage(i18n.T("worktree branch name required"))
 		return nil
 	}
 	if path == "" {
-		repo.State.Message = "worktree path required"
+		repo.State.SetMessage(i18n.T("worktree path required"))
 		return nil
 	}
 	return m.createWorktreeCmd(repo, branchName, path)
@@ -131,17 +132,17 @@ func (m *Model) createWorktreeCmd(repo *git.Repository, branchName, path string)
 		return nil
 	}
 	return func() tea.Msg {
-		repo.State.Message = fmt.Sprintf("creating worktree %s", branchName)
+		repo.State.SetMessage(fmt.Sprintf("creating worktree %s", branchName))
 		newBranch := !repo.LocalBranchExists(branchName)
 		if err := repo.CreateWorktree(git.WorktreeAddOptions{
 			Path:       path,
 			BranchName: branchName,
 			NewBranch:  newBranch,
 		}); err != nil {
-			repo.State.Message = err.Error()
+			repo.State.SetMessage(err.Error())
 			return errMsg{err: fmt.Errorf("create worktree %s: %w", branchName, err)}
 		}
-		repo.State.Message = fmt.Sprintf("created worktree %s", branchName)
+		repo.State.SetMessage(fmt.Sprintf("created worktree %s", branchName))
 		if err := scheduleRefresh(repo); err != nil {
 			return errMsg{err: err}
 		}
@@ -200,24 +201,31 @@ func (m *Model) deleteSelectedWorktreeCmd() tea.Cmd {
 	worktree := row.worktree
 	if worktree.IsPrimary {
 		if repo.State != nil {
-			repo.State.Message = "cannot delete [main] worktree"
+			repo.State.SetMessage(i18n.T("cannot delete [main] worktree"))
 		}
 		return nil
 	}
+	return m.confirmDestructive(i18n.T("delete worktree %s", worktree.DisplayName()), []*git.Repository{repo}, func() tea.Cmd {
+		return m.deleteWorktreeCmd(repo, refreshRepo, worktree)
+	})
+}
+
+func (m *Model) deleteWorktreeCmd(repo, refreshRepo *git.Repository, worktree *git.Worktree) tea.Cmd {
 	return func() tea.Msg {
-		repo.State.Message = fmt.Sprintf("deleting worktree %s", worktree.DisplayName())
+		repo.State.SetMessage(fmt.Sprintf("deleting worktree %s", worktree.DisplayName()))
 		if err := repo.RemoveWorktree(worktree, false); err != nil {
-			repo.State.Message = err.Error()
+			repo.State.SetMessage(err.Error())
 			return errMsg{err: fmt.Errorf("delete worktree %s: %w", worktree.DisplayName(), err)}
 		}
 		m.removeRepositoryByPath(worktree.Path)
 		if refreshRepo.State != nil {
-			refreshRepo.State.Message = fmt.Sprintf("deleted worktree %s", worktree.DisplayName())
+			refreshRepo.State.SetMessage(fmt.Sprintf("deleted worktree %s", worktree.DisplayName()))
 		}
+		// Move the cursor before the refresh starts rewriting the worktrees.
+		m.cursor = m.closestSelectableIndex(m.cursor, -1)
 		if err := scheduleRefresh(refreshRepo); err != nil {
 			return errMsg{err: err}
 		}
-		m.cursor = m.closestSelectableIndex(m.cursor, -1)
 		return jobCompletedMsg{}
 	}
 }
@@ -235,12 +243,12 @@ func (m *Model) pruneWorktreesCmd() tea.Cmd {
 		return nil
 	}
 	return func() tea.Msg {
-		repo.State.Message = "pruning stale worktrees"
+		repo.State.SetMessage(i18n.T("pruning stale worktrees"))
 		if err := repo.PruneWorktrees(); err != nil {
-			repo.State.Message = err.Error()
+			repo.State.SetMessage(err.Error())
 			return errMsg{err: fmt.Errorf("worktree prune: %w", err)}
 		}
-		repo.State.Message = "pruned stale worktrees"
+		repo.State.SetMessage(i18n.T("pruned stale worktrees"))
 		if err := scheduleRefresh(repo); err != nil {
 			return errMsg{err: err}
 		}
@@ -264,20 +272,20 @@ func (m *Model) toggleWorktreeLockCmd() tea.Cmd {
 	return func() tea.Msg {
 		var err error
 		if worktree.IsLocked {
-			repo.State.Message = fmt.Sprintf("unlocking worktree %s", worktree.DisplayName())
+			repo.State.SetMessage(fmt.Sprintf("unlocking worktree %s", worktree.DisplayName()))
 			err = repo.UnlockWorktree(worktree)
 			if err == nil {
-				repo.State.Message = fmt.Sprintf("unlocked worktree %s", worktree.DisplayName())
+				repo.State.SetMessage(fmt.Sprintf("unlocked worktree %s", worktree.DisplayName()))
 			}
 		} else {
-			repo.State.Message = fmt.Sprintf("locking worktree %s", worktree.DisplayName())
+			repo.State.SetMessage(fmt.Sprintf("locking worktree %s", worktree.DisplayName()))
 			err = repo.LockWorktree(worktree, "")
 			if err == nil {
-				repo.State.Message = fmt.Sprintf("locked worktree %s", worktree.DisplayName())
+				repo.State.SetMessage(fmt.Sprintf("locked worktree %s", worktree.DisplayName()))
 			}
 		}
 		if err != nil {
-			repo.State.Message = err.Error()
+			repo.State.SetMessage(err.Error())
 			return errMsg{err: fmt.Errorf("worktree lock toggle %s: %w", worktree.DisplayName(), err)}
 		}
 		if err := scheduleRefresh(repo); err != nil {
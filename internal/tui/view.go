@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -10,8 +11,12 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/thorstenhirsch/gitbatch/internal/command"
+	gerr "github.com/thorstenhirsch/gitbatch/internal/errors"
 	"github.com/thorstenhirsch/gitbatch/internal/git"
+	"github.com/thorstenhirsch/gitbatch/internal/i18n"
 )
 
 const (
@@ -20,6 +25,15 @@ const (
 	failSymbol         = "✗"
 	dirtySymbol        = "⚠"
 	localChangesSymbol = "~"
+	noRemoteSymbol     = "⊘"
+	credentialsSymbol  = "⚿"
+	networkSymbol      = "⌁"
+	conflictSymbol     = "≠"
+	hooksSymbol        = "⚙"
+	noVerifySymbol     = "⚐"
+	sparseSymbol       = "◐"
+	shallowSymbol      = "≈"
+	pinnedSymbol       = "⚲"
 
 	pullSymbol    = "↓"
 	mergeSymbol   = "↣"
@@ -32,7 +46,7 @@ const (
 
 	repoColPrefixWidth = 4 // cursor + space + status + space
 
-	minTerminalWidth  = 50
+	minTerminalWidth  = 30
 	minTerminalHeight = 8
 )
 
@@ -61,13 +75,16 @@ const panelHorizontalFrame = 4
 
 // getColumnWidths returns cached column widths, recalculating only when necessary
 func (m *Model) getColumnWidths() columnWidths {
+	if m.compactLayout() {
+		return compactColumnWidths(m.width)
+	}
 	needsRecalc := m.cachedWidth != m.width || m.cachedRepoCount != len(m.repositories)
 	// Age column starts at 0 while commits are still loading; re-check until it stabilises.
 	if !needsRecalc && m.width > ageColumnThreshold && m.cachedColWidths.age == 0 {
-		needsRecalc = maxAgeWidth(m.repositories) > 0
+		needsRecalc = maxAgeWidth(m.repositories, m.ageColumnForRepo) > 0
 	}
 	if needsRecalc {
-		m.cachedColWidths = calculateColumnWidths(m.width, m.repositories)
+		m.cachedColWidths = columnWidthsWithAge(m.width, m.repositories, m.ageColumnForRepo)
 		m.cachedWidth = m.width
 		m.cachedRepoCount = len(m.repositories)
 	}
@@ -97,9 +114,14 @@ func (m *Model) popupDimensions() (popupWidth, maxContentLines int) {
 }
 
 func calculateColumnWidths(totalWidth int, repos []*git.Repository) columnWidths {
+	return columnWidthsWithAge(totalWidth, repos, commitAgeForRepo)
+}
+
+// columnWidthsWithAge lays out the columns with age filling the last one.
+func columnWidthsWithAge(totalWidth int, repos []*git.Repository, age func(*git.Repository) string) columnWidths {
 	ageW := 0
 	if totalWidth > ageColumnThreshold {
-		ageW = maxAgeWidth(repos)
+		ageW = maxAgeWidth(repos, age)
 	}
 
 	// 4 border chars normally (│repo│branch│commit│); 5 when age column is present
@@ -149,14 +171,43 @@ func calculateColumnWidths(totalWidth int, repos []*git.Repository) columnWidths
 
 // repoDisplayName returns the repo name with a stash indicator suffix if stashes exist.
 // e.g. "myrepo {2}" for 3 stashes (highest index = 2), or just "myrepo" if none.
+// Sparse checkouts and shallow clones are marked, and repositories with active hooks get a hooks
+// marker, or the no-verify marker when their jobs bypass the hooks. With
+// icons enabled the provider and language icons lead the name, preceded by
+// the pin of pinned repositories. Nested repositories are named by their
+// path inside the enclosing repository.
 func repoDisplayName(r *git.Repository) string {
 	if r == nil {
 		return ""
 	}
-	if len(r.Stasheds) == 0 {
-		return r.Name
+	name := r.Name
+	if r.Enclosing != nil {
+		if rel, err := filepath.Rel(r.Enclosing.AbsPath, r.AbsPath); err == nil {
+			name = r.Enclosing.Name + "/" + filepath.ToSlash(rel)
+		}
+	}
+	if icons := repoIcons(r); icons != "" {
+		name = icons + " " + name
+	}
+	if command.Pinned(r) {
+		name = pinnedSymbol + " " + name
+	}
+	if len(r.Stasheds) > 0 {
+		name = fmt.Sprintf("%s {%d}", name, len(r.Stasheds)-1)
+	}
+	if r.IsSparse() {
+		name += " " + sparseSymbol
+	}
+	if r.IsShallow() {
+		name += " " + shallowSymbol
+	}
+	switch {
+	case command.NoVerify(r):
+		name += " " + noVerifySymbol
+	case r.Hooks.Enabled():
+		name += " " + hooksSymbol
 	}
-	return fmt.Sprintf("%s {%d}", r.Name, len(r.Stasheds)-1)
+	return name
 }
 
 func maxRepoNameLength(repos []*git.Repository) int {
@@ -232,10 +283,10 @@ func commitAgeForRepo(r *git.Repository) string {
 	return ""
 }
 
-func maxAgeWidth(repos []*git.Repository) int {
+func maxAgeWidth(repos []*git.Repository, age func(*git.Repository) string) int {
 	maxLen := 0
 	for _, r := range repos {
-		if s := commitAgeForRepo(r); len(s) > maxLen {
+		if s := age(r); len(s) > maxLen {
 			maxLen = len(s)
 		}
 	}
@@ -246,6 +297,15 @@ func maxAgeWidth(repos []*git.Repository) int {
 	return maxLen
 }
 
+// ageColumnForRepo is the last column of the overview: the commit age, or the
+// size of the git directory while sorting by size.
+func (m *Model) ageColumnForRepo(r *git.Repository) string {
+	if m.sortMode == repositorySortBySize {
+		return m.sizeColumnForRepo(r)
+	}
+	return commitAgeForRepo(r)
+}
+
 func formatAgeColumn(width int, age string) string {
 	if width <= 0 {
 		return ""
@@ -257,11 +317,11 @@ func formatAgeColumn(width int, age string) string {
 	return fmt.Sprintf(" %*s ", contentWidth, truncateString(age, contentWidth))
 }
 
-func branchContent(r *git.Repository) string {
+func (m *Model) branchColumnContent(r *git.Repository) string {
 	if r == nil || r.State == nil || r.State.Branch == nil {
 		return ""
 	}
-	return r.State.Branch.Name + syncSuffix(r.State.Branch)
+	return r.State.Branch.Name + m.branchSyncSuffix(r)
 }
 
 func renderRepoColumnBody(left string, width int, right string, rightWidth int) string {
@@ -323,6 +383,9 @@ func (m *Model) renderTableBorder(colWidths columnWidths, borderType string, lab
 	}
 
 	repoSeg := borderSegmentWithLeftLabel(colWidths.repo, horiz, label)
+	if colWidths.compact {
+		return m.styles.TableBorder.Render(left + repoSeg + right)
+	}
 	branchSeg := strings.Repeat(horiz, colWidths.branch)
 	commitSeg := strings.Repeat(horiz, colWidths.commitMsg)
 
@@ -337,6 +400,9 @@ func (m *Model) renderTableBorder(colWidths columnWidths, borderType string, lab
 
 func (m *Model) renderEmptyTableRow(colWidths columnWidths) string {
 	border := m.styles.TableBorder.Render("│")
+	if colWidths.compact {
+		return border + strings.Repeat(" ", colWidths.repo) + border
+	}
 	row := border +
 		strings.Repeat(" ", colWidths.repo) +
 		border +
@@ -533,7 +599,7 @@ func (m *Model) View() string {
 	var errorBanner string
 
 	// Show dedicated loading screen when scanning many repositories
-	if m.loading && len(m.directories) > loadingScreenThreshold {
+	if m.loading && len(m.repositories) == 0 && len(m.directories) > loadingScreenThreshold {
 		content = m.renderLoadingScreen()
 		statusBar := m.renderStatusBar()
 		return lipgloss.JoinVertical(lipgloss.Left, content, statusBar)
@@ -560,6 +626,13 @@ func (m *Model) View() string {
 		)
 	}
 
+	if m.diffActive {
+		diff := m.renderDiff()
+		content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, diff,
+			lipgloss.WithWhitespaceChars(" "),
+		)
+	}
+
 	if m.showHelp {
 		help := m.renderHelp()
 		content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, help,
@@ -603,12 +676,120 @@ func (m *Model) View() string {
 		}
 	}
 
+	if m.comparePromptActive {
+		if prompt := m.renderComparePrompt(); prompt != "" {
+			content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, prompt,
+				lipgloss.WithWhitespaceChars(" "),
+			)
+		}
+	}
+
+	if m.problemsActive {
+		if view := m.renderProblems(); view != "" {
+			content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, view,
+				lipgloss.WithWhitespaceChars(" "),
+			)
+		}
+	}
+
+	if m.forecastActive {
+		if view := m.renderForecast(); view != "" {
+			content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, view,
+				lipgloss.WithWhitespaceChars(" "),
+			)
+		}
+	}
+
+	if m.cleanActive {
+		if view := m.renderCleanPreview(); view != "" {
+			content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, view,
+				lipgloss.WithWhitespaceChars(" "),
+			)
+		}
+	}
+
+	if m.patchActive {
+		if view := m.renderPatchResults(); view != "" {
+			content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, view,
+				lipgloss.WithWhitespaceChars(" "),
+			)
+		}
+	}
+
+	if m.urlRewritePromptActive {
+		if prompt := m.renderURLRewritePrompt(); prompt != "" {
+			content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, prompt,
+				lipgloss.WithWhitespaceChars(" "),
+			)
+		}
+	}
+
+	if m.patchPromptActive {
+		if prompt := m.renderPatchPrompt(); prompt != "" {
+			content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, prompt,
+				lipgloss.WithWhitespaceChars(" "),
+			)
+		}
+	}
+
+	if m.bisectActive {
+		if view := m.renderBisect(); view != "" {
+			content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, view,
+				lipgloss.WithWhitespaceChars(" "),
+			)
+		}
+	}
+
+	if m.grepActive {
+		if view := m.renderGrep(); view != "" {
+			content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, view,
+				lipgloss.WithWhitespaceChars(" "),
+			)
+		}
+	}
+
+	if m.grepPromptActive {
+		if prompt := m.renderGrepPrompt(); prompt != "" {
+			content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, prompt,
+				lipgloss.WithWhitespaceChars(" "),
+			)
+		}
+	}
+
+	if m.rebaseOntoPromptActive {
+		if prompt := m.renderRebaseOntoPrompt(); prompt != "" {
+			content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, prompt,
+				lipgloss.WithWhitespaceChars(" "),
+			)
+		}
+	}
+
+	if m.remotePromptActive {
+		if prompt := m.renderRemotePrompt(); prompt != "" {
+			content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, prompt,
+				lipgloss.WithWhitespaceChars(" "),
+			)
+		}
+	}
+
 	if m.activeCredentialPrompt != nil {
 		if prompt := m.renderCredentialPrompt(); prompt != "" {
 			content = lipgloss.JoinVertical(lipgloss.Left, content, prompt)
 		}
 	}
 
+	if prompt := m.renderConfirmPrompt(); prompt != "" {
+		content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, prompt,
+			lipgloss.WithWhitespaceChars(" "),
+		)
+	}
+
+	if prompt := m.renderAskpassPrompt(); prompt != "" {
+		content = lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, prompt,
+			lipgloss.WithWhitespaceChars(" "),
+		)
+	}
+
 	// Status bar is always at the bottom
 	statusBar := m.renderStatusBar()
 
@@ -629,10 +810,18 @@ func (m *Model) renderOverviewTitleBar() string {
 	if m.width <= 0 {
 		return ""
 	}
-	leftTitle := fmt.Sprintf(" Repositories (%d)", len(m.repositories))
-	if m.worktreeMode {
+	leftTitle := fmt.Sprintf(" Repositories (%d)", len(m.visibleRepositories()))
+	if m.loading {
+		leftTitle = fmt.Sprintf(" Loading repositories (%d/%d)", m.loadedCount, len(m.directories))
+	} else if m.worktreeMode {
 		leftTitle = fmt.Sprintf(" Worktree mode (%d)", len(m.worktreeFamilies()))
 	}
+	if m.compareRef != "" {
+		leftTitle += " vs " + m.compareRef
+	}
+	if tabs := m.renderTabs(); tabs != "" {
+		leftTitle += "  " + tabs
+	}
 	rightTitle := fmt.Sprintf("Gitbatch %s ", m.version)
 	contentWidth := m.width - 2 // title style adds one space padding on each side
 	if contentWidth < 1 {
@@ -662,7 +851,8 @@ func (m *Model) renderOverviewTitleBar() string {
 }
 
 func (m *Model) renderOverview() string {
-	if len(m.repositories) == 0 {
+	repos := m.visibleRepositories()
+	if len(repos) == 0 {
 		if m.loading {
 			return m.styles.List.Render("Loading repositories...")
 		}
@@ -682,16 +872,16 @@ func (m *Model) renderOverview() string {
 	title := m.renderOverviewTitleBar()
 
 	// Compute cumulative row offsets for each repo (accounts for expanded branches)
-	repoRowStart := make([]int, len(m.repositories))
+	repoRowStart := make([]int, len(repos))
 	totalRows := 0
-	for i, r := range m.repositories {
+	for i, r := range repos {
 		repoRowStart[i] = totalRows
 		totalRows += m.repoRowCount(r)
 	}
 
 	// Determine the scroll window: which visual rows are visible
 	topRow := 0
-	if totalRows > visibleHeight && m.cursor < len(m.repositories) {
+	if totalRows > visibleHeight && m.cursor < len(repos) {
 		cursorRow := repoRowStart[m.cursor]
 		topRow = cursorRow - visibleHeight/2
 		if topRow < 0 {
@@ -711,7 +901,7 @@ func (m *Model) renderOverview() string {
 
 	// Find the first repo that has rows in the visible window
 	startIdx := 0
-	for i, r := range m.repositories {
+	for i, r := range repos {
 		if repoRowStart[i]+m.repoRowCount(r) > topRow {
 			startIdx = i
 			break
@@ -719,8 +909,8 @@ func (m *Model) renderOverview() string {
 	}
 
 	// Find the last repo that has rows in the visible window
-	endIdx := len(m.repositories)
-	for i := startIdx; i < len(m.repositories); i++ {
+	endIdx := len(repos)
+	for i := startIdx; i < len(repos); i++ {
 		if repoRowStart[i] >= bottomRow {
 			endIdx = i
 			break
@@ -741,7 +931,7 @@ func (m *Model) renderOverview() string {
 	// Render repositories with optional expanded branches
 	var lines []string
 	for i := startIdx; i < endIdx && len(lines) < visibleHeight; i++ {
-		r := m.repositories[i]
+		r := repos[i]
 		rowBase := repoRowStart[i]
 
 		// Primary repo line
@@ -750,6 +940,20 @@ func (m *Model) renderOverview() string {
 			lines = append(lines, m.renderRepositoryLine(r, selected, colWidths))
 		}
 
+		// Inline branch switcher replaces the expanded branch lines
+		if rows := m.branchSwitcherRows(r); rows > 0 {
+			for j, line := range m.renderBranchSwitcherLines(colWidths) {
+				if rowBase+1+j < topRow {
+					continue
+				}
+				if len(lines) >= visibleHeight {
+					break
+				}
+				lines = append(lines, line)
+			}
+			continue
+		}
+
 		// Expanded branch lines (non-HEAD branches)
 		if m.expandBranches && r.State != nil && r.State.Branch != nil {
 			style := m.repoUnselectedStyle(r)
@@ -867,6 +1071,8 @@ type repoVisualState struct {
 	requiresCredentials bool
 	hasLocalChanges     bool
 	noUpstream          bool
+	network             bool
+	conflict            bool
 }
 
 func (m *Model) repoVisualStateFor(r *git.Repository) repoVisualState {
@@ -905,12 +1111,19 @@ func (m *Model) repoVisualStateFor(r *git.Repository) repoVisualState {
 		state.style = m.styles.SuccessItem
 	case git.Fail:
 		if state.noUpstream {
-			if state.dirty {
+			if !r.HasRemote() {
+				state.statusIcon = noRemoteSymbol
+			} else if state.dirty {
 				state.statusIcon = localChangesSymbol
 			}
 			state.style = m.styles.DisabledItem
 		} else if state.requiresCredentials {
+			state.statusIcon = credentialsSymbol
 			state.style = m.styles.CredentialsItem
+		} else if r.State != nil && r.State.Failure == gerr.KindNetwork {
+			state.network = true
+			state.statusIcon = networkSymbol
+			state.style = m.styles.NetworkItem
 		} else {
 			state.statusIcon = failSymbol
 			state.style = m.styles.FailedItem
@@ -923,7 +1136,15 @@ func (m *Model) repoVisualStateFor(r *git.Repository) repoVisualState {
 	if state.dirty && !state.failed && status.Ready {
 		state.statusIcon = dirtySymbol
 		state.style = m.styles.DisabledItem
+		// A merge that stopped on a conflict leaves the tree dirty; it gets
+		// its own color until the tree is clean again.
+		if r.State != nil && r.State.Failure == gerr.KindConflict {
+			state.dirty, state.hasLocalChanges, state.conflict = false, false, true
+			state.statusIcon = conflictSymbol
+			state.style = m.styles.ConflictItem
+		}
 	}
+	state.statusIcon = statusIcon(state.statusIcon)
 	return state
 }
 
@@ -933,6 +1154,10 @@ func (m *Model) selectedHighlightForVisual(visual repoVisualState) lipgloss.Styl
 		return m.styles.DisabledSelectedItem
 	case visual.requiresCredentials:
 		return m.styles.CredentialsSelectedItem
+	case visual.network:
+		return m.styles.NetworkSelectedItem
+	case visual.conflict:
+		return m.styles.ConflictSelectedItem
 	case visual.failed:
 		return m.styles.FailedSelectedItem
 	case visual.dirty:
@@ -950,6 +1175,9 @@ func (m *Model) selectedHighlightForVisual(visual repoVisualState) lipgloss.Styl
 // Table format: │cursor status repo-name    │ branch-name │ commit tags/message │
 // Example:      │→ ●   example-repo         │  main       │ [v1.0.0] add feature │
 func (m *Model) renderRepositoryLine(r *git.Repository, selected bool, colWidths columnWidths) string {
+	if colWidths.compact {
+		return m.renderCompactRepositoryLine(r, selected, colWidths)
+	}
 	visual := m.repoVisualStateFor(r)
 
 	cursor := " "
@@ -971,7 +1199,7 @@ func (m *Model) renderRepositoryLine(r *git.Repository, selected bool, colWidths
 	if branchContentWidth < 0 {
 		branchContentWidth = 0
 	}
-	branchContent := truncateString(branchContent(r), branchContentWidth)
+	branchContent := truncateString(m.branchColumnContent(r), branchContentWidth)
 	branchColumn := m.applyUnselectedColumnStyle(
 		fmt.Sprintf("%-*s", colWidths.branch, " "+branchContent),
 		selected, visual.requiresCredentials, visual.hasLocalChanges, visual.dirty, visual.failed, visual.noUpstream,
@@ -1014,7 +1242,7 @@ func (m *Model) renderRepositoryLine(r *git.Repository, selected bool, colWidths
 	row := border + styledRepoCol + border + styledBranchCol + border + styledCommitCol
 	if colWidths.age > 0 {
 		ageColumn := m.applyUnselectedColumnStyle(
-			formatAgeColumn(colWidths.age, commitAgeForRepo(r)),
+			formatAgeColumn(colWidths.age, m.ageColumnForRepo(r)),
 			selected, visual.requiresCredentials, visual.hasLocalChanges, visual.dirty, visual.failed, visual.noUpstream,
 		)
 		var styledAgeCol string
@@ -1031,6 +1259,9 @@ func (m *Model) renderRepositoryLine(r *git.Repository, selected bool, colWidths
 func (m *Model) renderWorktreeRepositoryLine(row overviewRow, selected bool, colWidths columnWidths) string {
 	repo := row.repository()
 	visual := m.repoVisualStateFor(repo)
+	if colWidths.compact {
+		return m.renderCompactLine(visual, selected, repoDisplayName(repo), row.worktreeLabel(), colWidths)
+	}
 
 	cursor := " "
 	if selected {
@@ -1092,7 +1323,7 @@ func (m *Model) renderWorktreeRepositoryLine(row overviewRow, selected bool, col
 	wtRow := border + styledRepoCol + border + styledBranchCol + border + styledCommitCol
 	if colWidths.age > 0 {
 		ageColumn := m.applyUnselectedColumnStyle(
-			formatAgeColumn(colWidths.age, commitAgeForRepo(repo)),
+			formatAgeColumn(colWidths.age, m.ageColumnForRepo(repo)),
 			selected, visual.requiresCredentials, visual.hasLocalChanges, visual.dirty, visual.failed, visual.noUpstream,
 		)
 		var styledAgeCol string
@@ -1109,6 +1340,9 @@ func (m *Model) renderWorktreeRepositoryLine(row overviewRow, selected bool, col
 func (m *Model) renderWorktreeLine(row overviewRow, selected bool, colWidths columnWidths) string {
 	repo := row.repository()
 	visual := m.repoVisualStateFor(repo)
+	if colWidths.compact {
+		return m.renderCompactLine(visual, selected, "  "+m.worktreeBranchContent(row), "", colWidths)
+	}
 
 	cursor := " "
 	if selected {
@@ -1177,7 +1411,7 @@ func (m *Model) renderWorktreeLine(row overviewRow, selected bool, colWidths col
 	wtlRow := border + styledRepoCol + border + styledBranchCol + border + styledCommitCol
 	if colWidths.age > 0 {
 		ageColumn := m.applyUnselectedColumnStyle(
-			formatAgeColumn(colWidths.age, commitAgeForRepo(repo)),
+			formatAgeColumn(colWidths.age, m.ageColumnForRepo(repo)),
 			selected, visual.requiresCredentials, visual.hasLocalChanges, visual.dirty, visual.failed, visual.noUpstream,
 		)
 		var styledAgeCol string
@@ -1209,12 +1443,58 @@ func (m *Model) expandedBranchOffset(r *git.Repository, branchIdx int) int {
 
 // repoRowCount returns how many visual rows a repository occupies.
 func (m *Model) repoRowCount(r *git.Repository) int {
+	if rows := m.branchSwitcherRows(r); rows > 0 {
+		return 1 + rows
+	}
 	if !m.expandBranches || r == nil || len(r.Branches) <= 1 {
 		return 1
 	}
 	return len(r.Branches)
 }
 
+// renderBranchSwitcherLines renders the visible part of the inline branch
+// dropdown. The checked out branch is marked with "*".
+func (m *Model) renderBranchSwitcherLines(colWidths columnWidths) []string {
+	branches := m.branchSwitcherBranches()
+	end := min(m.branchSwitcherOffset+branchSwitcherMaxRows, len(branches))
+	headName := ""
+	if r := m.branchSwitcherRepo; r.State != nil && r.State.Branch != nil {
+		headName = r.State.Branch.Name
+	}
+	border := m.styles.TableBorder.Render("│")
+	lines := make([]string, 0, end-m.branchSwitcherOffset)
+	for i := m.branchSwitcherOffset; i < end; i++ {
+		branch := branches[i]
+		style := m.styles.ListItem
+		if i == m.branchSwitcherCursor {
+			style = m.styles.SelectedItem
+		}
+		marker := " "
+		if branch.Name == headName {
+			marker = "*"
+		}
+		if colWidths.compact {
+			lines = append(lines, m.renderCompactBranchLine(style, marker, branch, colWidths))
+			continue
+		}
+		hint := ""
+		if i == m.branchSwitcherOffset {
+			hint = "enter: checkout | esc: close"
+		}
+		repoColumn := m.styles.Help.Render(fmt.Sprintf("%*s", colWidths.repo, truncateString(hint+" ", colWidths.repo)))
+		branchStr := truncateString(marker+" "+branch.Name+syncSuffix(branch), max(colWidths.branch-1, 0))
+		branchColumn := style.Render(fmt.Sprintf("%-*s", colWidths.branch, " "+branchStr))
+		commitStr := truncateString(m.branchCommitContent(m.branchSwitcherRepo, branch), max(colWidths.commitMsg-1, 0))
+		commitColumn := m.styles.ListItem.Render(fmt.Sprintf("%-*s", colWidths.commitMsg, " "+commitStr))
+		line := border + repoColumn + border + branchColumn + border + commitColumn
+		if colWidths.age > 0 {
+			line += border + strings.Repeat(" ", colWidths.age)
+		}
+		lines = append(lines, line+border)
+	}
+	return lines
+}
+
 // repoUnselectedStyle determines the lipgloss style for a repo's rows (unselected).
 func (m *Model) repoUnselectedStyle(r *git.Repository) lipgloss.Style {
 	status := r.WorkStatus()
@@ -1239,6 +1519,8 @@ func (m *Model) repoUnselectedStyle(r *git.Repository) lipgloss.Style {
 			style = m.styles.DisabledItem
 		} else if requiresCredentials {
 			style = m.styles.CredentialsItem
+		} else if r.State != nil && r.State.Failure == gerr.KindNetwork {
+			style = m.styles.NetworkItem
 		} else {
 			style = m.styles.FailedItem
 		}
@@ -1248,6 +1530,9 @@ func (m *Model) repoUnselectedStyle(r *git.Repository) lipgloss.Style {
 	}
 	if dirty && !failed && status.Ready {
 		style = m.styles.DisabledItem
+		if r.State != nil && r.State.Failure == gerr.KindConflict {
+			style = m.styles.ConflictItem
+		}
 	}
 	return style
 }
@@ -1255,6 +1540,9 @@ func (m *Model) repoUnselectedStyle(r *git.Repository) lipgloss.Style {
 // renderExpandedBranchLine renders a single expanded branch row for a non-HEAD branch.
 // It uses the same color as the repo's primary row but without status icons or repo name.
 func (m *Model) renderExpandedBranchLine(r *git.Repository, branch *git.Branch, style lipgloss.Style, colWidths columnWidths) string {
+	if colWidths.compact {
+		return m.renderCompactBranchLine(style, " ", branch, colWidths)
+	}
 	// Empty repo column (spaces matching cursor + status + repo name width)
 	repoColumn := style.Render(strings.Repeat(" ", colWidths.repo))
 
@@ -1329,7 +1617,11 @@ func collectTags(r *git.Repository, commitHash plumbing.Hash) []string {
 		hash := ref.Hash()
 		if tagObj, err := r.Repo.TagObject(hash); err == nil {
 			if tagObj.Target == commitHash {
-				tags = append(tags, tagObj.Name)
+				name := tagObj.Name
+				if tagObj.PGPSignature != "" && r.VerifyTag(tagObj.Name) {
+					name += " " + i18n.T("(verified)")
+				}
+				tags = append(tags, name)
 			}
 			return nil
 		}
@@ -1494,9 +1786,16 @@ func (m *Model) renderPanelPopup() string {
 			panelTitle = "Common Remote Branches"
 		} else {
 			panelTitle = "Remotes"
+			if _, _, ok := m.currentRepository().ForkRemotes(); ok {
+				panelTitle += " (fork | Y: sync with upstream)"
+			}
 		}
 	case StatusPanel:
 		panelTitle = "Status"
+	case ReflogPanel:
+		panelTitle = "Reflog (enter: checkout | n: branch from entry | d: diff)"
+	case HistoryPanel:
+		panelTitle = "History"
 	case StashActionPanel:
 		if m.stashAction == stashActionPop {
 			panelTitle = "Pop Stash"
@@ -1506,6 +1805,7 @@ func (m *Model) renderPanelPopup() string {
 		if len(tagged) > 1 {
 			panelTitle = "Common " + panelTitle
 		}
+		panelTitle += " (enter: show diff first | space: at once)"
 	default:
 		return ""
 	}
@@ -1526,6 +1826,10 @@ func (m *Model) renderPanelPopup() string {
 		panelContent = m.renderRemotes(contentWidth, maxLines)
 	case StatusPanel:
 		panelContent = m.renderStatus(r, contentWidth, maxLines)
+	case ReflogPanel:
+		panelContent = m.renderReflog(contentWidth, maxLines)
+	case HistoryPanel:
+		panelContent = m.renderHistory(contentWidth, maxLines)
 	case StashActionPanel:
 		panelContent = m.renderStashActionPanel(contentWidth, maxLines)
 	}
@@ -1659,6 +1963,9 @@ func (m *Model) renderRemotes(contentWidth, maxLines int) string {
 		if m.hasMultipleTagged() {
 			return padToWidth("No common remote branches", contentWidth)
 		}
+		if repo := m.currentRepository(); repo != nil && !repo.HasRemote() {
+			return padToWidth("No remote configured (a: add remote)", contentWidth)
+		}
 		return padToWidth("No remote branches", contentWidth)
 	}
 
@@ -1694,9 +2001,10 @@ func (m *Model) renderRemotes(contentWidth, maxLines int) string {
 	}
 
 	lines := make([]string, 0, maxLines)
-	instructions := fmt.Sprintf("%s checkout  %s delete",
+	instructions := fmt.Sprintf("%s checkout  %s delete  %s set upstream",
 		m.styles.KeyBinding.Render("[space/c]"),
 		m.styles.KeyBinding.Render("[d]"),
+		m.styles.KeyBinding.Render("[u]"),
 	)
 	lines = append(lines, padToWidth(instructions, contentWidth))
 
@@ -1763,50 +2071,112 @@ func (m *Model) renderStatus(r *git.Repository, contentWidth, maxLines int) stri
 	}
 
 	// Branch & tracking
-	addLine("On branch " + m.styles.BranchInfo.Render(r.State.Branch.Name))
+	addLine(i18n.T("On branch ") + m.styles.BranchInfo.Render(r.State.Branch.Name))
 
 	pushables, _ := strconv.Atoi(r.State.Branch.Pushables)
 	pullables, _ := strconv.Atoi(r.State.Branch.Pullables)
 
 	switch {
 	case r.State.Branch.Upstream == nil:
-		addLine("Not tracking a remote branch")
+		addLine(i18n.T("Not tracking a remote branch"))
 	case pushables == 0 && pullables == 0:
-		addLine("Up to date with " + m.styles.BranchInfo.Render(r.State.Branch.Upstream.Name))
+		addLine(i18n.T("Up to date with ") + m.styles.BranchInfo.Render(r.State.Branch.Upstream.Name))
 	default:
 		if pushables > 0 && pullables > 0 {
-			addLine(fmt.Sprintf("Diverged from %s (ahead %d, behind %d)", r.State.Branch.Upstream.Name, pushables, pullables))
+			addLine(i18n.T("Diverged from %s (ahead %d, behind %d)", r.State.Branch.Upstream.Name, pushables, pullables))
 		} else if pushables > 0 {
-			addLine(fmt.Sprintf("Ahead of %s by %d commit(s)", r.State.Branch.Upstream.Name, pushables))
+			addLine(i18n.T("Ahead of %s by %d commit(s)", r.State.Branch.Upstream.Name, pushables))
 		} else {
-			addLine(fmt.Sprintf("Behind %s by %d commit(s)", r.State.Branch.Upstream.Name, pullables))
+			addLine(i18n.T("Behind %s by %d commit(s)", r.State.Branch.Upstream.Name, pullables))
 		}
 	}
 
 	if r.State.Branch.HasLocalChanges {
-		addLine("Working tree has uncommitted changes")
+		addLine(i18n.T("Working tree has uncommitted changes"))
 	} else if !r.State.Branch.Clean {
-		addLine("Working tree is dirty (conflicts with incoming)")
+		addLine(i18n.T("Working tree is dirty (conflicts with incoming)"))
+	}
+	if r.State.Branch.HasLocalChanges || !r.State.Branch.Clean {
+		policy := command.CurrentDirtyPolicy()
+		addLine(i18n.T("On dirty pull  %s (%s)", policy, policy.Description()))
+	}
+	if r.IsSparse() {
+		mode := "non-cone"
+		if r.Sparse.Cone {
+			mode = "cone"
+		}
+		addLine(i18n.T("Sparse         %s (%s)", strings.Join(r.Sparse.Patterns, ", "), mode))
+	}
+	if r.IsShallow() {
+		addLine(i18n.T("Shallow clone  ahead/behind and merges may be wrong; U fetches the full history"))
+	}
+	if r.Enclosing != nil {
+		addLine(i18n.T("Nested in      %s", r.Enclosing.AbsPath))
+	}
+	if deps := command.LocalDependencies(r, m.repositories); len(deps) > 0 {
+		addLine(i18n.T("Depends on     %s", repositoryNames(deps)))
+		if outdated := command.OutdatedDependencies(r, m.repositories); len(outdated) > 0 {
+			addLine(m.styles.Error.Render(padToWidth(i18n.T("Outdated deps  %s behind upstream, pull them first", repositoryNames(outdated)), contentWidth)))
+		}
+	}
+	if r.Hooks.Enabled() {
+		hooks := strings.Join(r.Hooks.Active, ", ")
+		if command.NoVerify(r) {
+			hooks += i18n.T(" (skipped with --no-verify)")
+		}
+		addLine(i18n.T("Hooks          %s", hooks))
+		if r.Hooks.CustomPath {
+			addLine(i18n.T("Hooks path     %s", r.Hooks.Dir))
+		}
+	}
+	if queues := queueSummary(r.QueueStats()); queues != "" {
+		addLine(i18n.T("Queues         %s", queues))
+	}
+	if failure := m.failureMessage(r); failure != "" {
+		addSection()
+		addLine(i18n.T("Last error"))
+		for _, line := range strings.Split(ansi.Wrap(failure, contentWidth-2, ""), "\n") {
+			addLine("  " + line)
+		}
+	}
+
+	if len(m.statusFiles) > 0 {
+		addSection()
+		addLine(i18n.T("Changed files  %d (e: open in $EDITOR | d: diff)", len(m.statusFiles)))
+		cursor := clampIndex(m.statusFileCursor, len(m.statusFiles))
+		start := 0
+		if cursor >= statusFileRows {
+			start = cursor - statusFileRows + 1
+		}
+		for i := start; i < len(m.statusFiles) && i < start+statusFileRows; i++ {
+			file := m.statusFiles[i]
+			label := truncateString(fmt.Sprintf("%c%c %s", file.X, file.Y, file.Name), contentWidth-2)
+			if i == cursor {
+				addLine(m.styles.SelectedItem.Render(padToWidth("> "+label, contentWidth)))
+			} else {
+				addLine("  " + label)
+			}
+		}
 	}
 
 	if current := r.CurrentWorktree(); current != nil {
 		addSection()
-		addLine(fmt.Sprintf("Worktree       %s", statusWorktreeLabel(current)))
+		addLine(i18n.T("Worktree       %s", statusWorktreeLabel(current)))
 		if current.IsPrimary {
-			addLine("Role           primary")
+			addLine(i18n.T("Role           primary"))
 		} else {
-			addLine("Role           linked")
+			addLine(i18n.T("Role           linked"))
 		}
 		if current.IsLocked {
-			addLine("Locked         yes")
+			addLine(i18n.T("Locked         yes"))
 			if reason := strings.TrimSpace(current.LockReason); reason != "" {
-				addLine("Lock reason    " + reason)
+				addLine(i18n.T("Lock reason    %s", reason))
 			}
 		}
 		if current.IsPrunable {
-			addLine("Prunable       yes")
+			addLine(i18n.T("Prunable       yes"))
 			if reason := strings.TrimSpace(current.PrunableReason); reason != "" {
-				addLine("Prune reason   " + reason)
+				addLine(i18n.T("Prune reason   %s", reason))
 			}
 		}
 	}
@@ -1817,26 +2187,31 @@ func (m *Model) renderStatus(r *git.Repository, contentWidth, maxLines int) stri
 	// Last commit
 	if stats.lastCommitInfo != "" {
 		addSection()
-		addLine("Last commit")
+		addLine(i18n.T("Last commit"))
 		addLine("  " + stats.lastCommitInfo)
 	}
 
 	// Counts section
 	addSection()
-	addLine(fmt.Sprintf("Branches       %d local, %d remote", stats.localBranches, stats.remoteBranches))
+	addLine(i18n.T("Branches       %d local, %d remote", stats.localBranches, stats.remoteBranches))
 	if stats.tags > 0 {
-		addLine(fmt.Sprintf("Tags           %d", stats.tags))
+		addLine(i18n.T("Tags           %d", stats.tags))
 	}
 	if stats.stashes > 0 {
-		addLine(fmt.Sprintf("Stashes        %d", stats.stashes))
+		addLine(i18n.T("Stashes        %d", stats.stashes))
 	}
-	addLine(fmt.Sprintf("Contributors   %d", stats.contributors))
-	addLine(fmt.Sprintf("Commits        %d", stats.commits))
+	addLine(i18n.T("Contributors   %d", stats.contributors))
+	addLine(i18n.T("Commits        %d", stats.commits))
 
 	// Size
-	if stats.repoSize != "" {
+	if objects, ok := m.objectStats[r.RepoID]; ok {
+		addSection()
+		for _, line := range objectStatsLines(objects) {
+			addLine(line)
+		}
+	} else if stats.repoSize != "" {
 		addSection()
-		addLine(fmt.Sprintf("Repo size      %s", stats.repoSize))
+		addLine(i18n.T("Repo size      %s", stats.repoSize))
 	}
 
 	// Path
@@ -1846,6 +2221,31 @@ func (m *Model) renderStatus(r *git.Repository, contentWidth, maxLines int) stri
 	return strings.Join(clampLines(lines, maxLines), "\n")
 }
 
+// statusFileRows is the number of changed files the status panel shows at
+// once; the list scrolls with the cursor.
+const statusFileRows = 8
+
+// queueSummary renders the depth of each event queue as "git 0/64", followed
+// by its blocked and dropped counts when there are any.
+func queueSummary(stats []git.QueueStats) string {
+	parts := make([]string, 0, len(stats))
+	for _, q := range stats {
+		part := fmt.Sprintf("%s %d/%d", q.Name, q.Depth, q.Capacity)
+		var overflow []string
+		if q.Blocked > 0 {
+			overflow = append(overflow, fmt.Sprintf("%d blocked", q.Blocked))
+		}
+		if q.Dropped > 0 {
+			overflow = append(overflow, fmt.Sprintf("%d dropped", q.Dropped))
+		}
+		if len(overflow) > 0 {
+			part += " (" + strings.Join(overflow, ", ") + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
 func statusWorktreeLabel(worktree *git.Worktree) string {
 	if worktree == nil {
 		return ""
@@ -1929,9 +2329,11 @@ func (m *Model) fetchRepoStats(r *git.Repository) repoStats {
 		}
 	}
 
-	// Repo size (du on .git directory)
-	if out, err := repoSizeCommand(r.AbsPath); err == nil {
-		stats.repoSize = out
+	// Repo size (du on .git directory), unless the object statistics have it
+	if _, ok := m.objectStats[r.RepoID]; !ok {
+		if out, err := repoSizeCommand(r.AbsPath); err == nil {
+			stats.repoSize = out
+		}
 	}
 
 	return stats
@@ -2014,9 +2416,9 @@ func (m *Model) renderStashPrompt() string {
 	repoCount := len(m.stashPromptRepos)
 	var title string
 	if repoCount == 1 && m.stashPromptRepos[0] != nil {
-		title = fmt.Sprintf("Stash in %s", truncateString(m.stashPromptRepos[0].Name, contentWidth-10))
+		title = i18n.T("Stash in %s", truncateString(m.stashPromptRepos[0].Name, contentWidth-10))
 	} else {
-		title = fmt.Sprintf("Stash in %d repos", repoCount)
+		title = i18n.T("Stash in %d repos", repoCount)
 	}
 
 	// Message field
@@ -2025,8 +2427,8 @@ func (m *Model) renderStashPrompt() string {
 		msgDisplay = msgDisplay[len(msgDisplay)-contentWidth+2:]
 	}
 
-	msgLine := fmt.Sprintf("> Message: %s_", msgDisplay)
-	hint := "(optional, Enter to stash, Esc to cancel)"
+	msgLine := "> " + i18n.T("Message: %s", msgDisplay+"_")
+	hint := i18n.T("(optional, Enter to stash, Esc to cancel)")
 
 	parts := []string{
 		m.styles.PanelTitle.Render(title),
@@ -2081,42 +2483,82 @@ func (m *Model) worktreeStatusHints() []string {
 		return nil
 	}
 
-	hints := []string{"W branches", "n worktree", "X prune"}
+	hints := []string{i18n.T("W branches"), i18n.T("n worktree"), i18n.T("X prune")}
 	if row, ok := m.currentOverviewRow(); ok && row.kind == overviewWorktreeRow && row.worktree != nil && !row.worktree.IsPrimary {
 		if row.worktree.IsLocked {
-			hints = append(hints, "L unlock")
+			hints = append(hints, i18n.T("L unlock"))
 		} else {
-			hints = append(hints, "d delete", "L lock")
+			hints = append(hints, i18n.T("d delete"), i18n.T("L lock"))
 		}
 	}
 	return hints
 }
 
-// renderStatusBar renders the bottom status bar
-func (m *Model) renderStatusBar() string {
-	modeSymbol := pullSymbol
-	statusBarStyle := m.styles.StatusBarPull
-	totalWidth := m.width
-	switch m.mode.ID {
+// modeStatusBar returns the symbol and the status bar style of a mode.
+// Composite modes look like pull.
+func (m *Model) modeStatusBar(id ModeID) (string, lipgloss.Style) {
+	switch id {
 	case MergeMode:
-		modeSymbol = mergeSymbol
-		statusBarStyle = m.styles.StatusBarMerge
+		return mergeSymbol, m.styles.StatusBarMerge
 	case RebaseMode:
-		modeSymbol = rebaseSymbol
-		statusBarStyle = m.styles.StatusBarRebase
+		return rebaseSymbol, m.styles.StatusBarRebase
 	case PushMode:
-		modeSymbol = pushSymbol
-		statusBarStyle = m.styles.StatusBarPush
+		return pushSymbol, m.styles.StatusBarPush
 	}
+	return pullSymbol, m.styles.StatusBarPull
+}
 
-	left := fmt.Sprintf(" %s %s", modeSymbol, m.mode.DisplayString)
+// modeCount is the number of queued repositories whose job runs in mode.
+type modeCount struct {
+	mode  Mode
+	count int
+}
 
-	queuedCount := 0
+// queuedModes counts the queued repositories per mode their job runs in, in
+// the order of the modes.
+func (m *Model) queuedModes() []modeCount {
+	counts := make(map[ModeID]int)
 	for _, r := range m.repositories {
 		if r.WorkStatus() == git.Queued {
-			queuedCount++
+			counts[m.jobMode(r).ID]++
+		}
+	}
+	var queued []modeCount
+	for _, mode := range availableModes() {
+		if counts[mode.ID] > 0 {
+			queued = append(queued, modeCount{mode: mode, count: counts[mode.ID]})
 		}
 	}
+	return queued
+}
+
+// inlineStyle renders text in style inside a line rendered in bar. The reset
+// at the end of text would drop the colors of the bar for the rest of the
+// line, so they are set again.
+func inlineStyle(bar, style lipgloss.Style, text string) string {
+	restore, _, _ := strings.Cut(bar.UnsetPadding().UnsetWidth().Render("\x00"), "\x00")
+	return style.UnsetPadding().UnsetWidth().Render(text) + restore
+}
+
+// renderStatusBar renders the bottom status bar
+func (m *Model) renderStatusBar() string {
+	modeSymbol, statusBarStyle := m.modeStatusBar(m.mode.ID)
+	totalWidth := m.width
+
+	display := m.mode.DisplayString
+	if style := command.CurrentMergeStyle(); m.mode.ID == MergeMode && style != command.MergeStyleFF {
+		display = strings.Replace(display, " |", " ("+string(style)+") |", 1)
+	}
+	left := fmt.Sprintf(" %s %s", modeSymbol, display)
+	if m.statusFilter != filterNone {
+		left += " | " + i18n.T("showing: %s", m.statusFilter)
+	}
+
+	queued := m.queuedModes()
+	queuedCount := 0
+	for _, q := range queued {
+		queuedCount += q.count
+	}
 
 	focusRepo := m.currentRepository()
 	linkedWorktree := focusRepo != nil && focusRepo.IsLinkedWorktree()
@@ -2128,14 +2570,14 @@ func (m *Model) renderStatusBar() string {
 
 	center := ""
 
-	right := "TAB: lazygit | ? for help"
+	right := i18n.T("TAB: lazygit | ? for help")
 
 	leftWidth := lipgloss.Width(left)
 	rightWidth := lipgloss.Width(right)
 	worktreeHints := m.worktreeStatusHints()
 	branchHints := []string(nil)
 	if !m.worktreeMode && m.hasBranchTargets() {
-		branchHints = append(branchHints, "n branch")
+		branchHints = append(branchHints, i18n.T("n branch"))
 	}
 
 	if linkedWorktree {
@@ -2151,38 +2593,55 @@ func (m *Model) renderStatusBar() string {
 			}
 		}
 		if worktreeName == "" {
-			worktreeName = "unknown"
+			worktreeName = i18n.T("unknown")
 		}
 		statusBarStyle = m.styles.StatusBarWorktree
 		left = " "
-		parts := append([]string{"worktree: " + worktreeName}, worktreeHints...)
+		parts := append([]string{i18n.T("worktree: %s", worktreeName)}, worktreeHints...)
 		center = strings.Join(parts, " | ")
 	} else if center == "" {
-		tagHint := "space: tag"
+		tagHint := i18n.T("space: tag")
 		if focusRepo != nil && focusRepo.WorkStatus() == git.Queued {
-			tagHint = "space: untag"
-		}
-		if queuedCount > 0 {
-			tagHint += " | enter: start batch"
-			parts := []string{fmt.Sprintf("tagged: %d", queuedCount)}
+			tagHint = i18n.T("space: untag")
+		}
+		if queuedCount > 0 && m.countPrefix != "" {
+			tagHint += " | " + i18n.T("enter: start first %s", m.countPrefix)
+		} else if queuedCount > 0 {
+			// Jobs that run in another mode than the batch are counted per
+			// mode in its color; Enter starts them all.
+			enterHint := i18n.T("enter: start batch")
+			parts := []string{i18n.T("tagged: %d", queuedCount)}
+			switch {
+			case len(queued) > 1:
+				counts := make([]string, 0, len(queued))
+				for _, q := range queued {
+					_, style := m.modeStatusBar(q.mode.ID)
+					counts = append(counts, inlineStyle(statusBarStyle, style, fmt.Sprintf("%d %s", q.count, q.mode.ID)))
+				}
+				parts[0] = i18n.T("%s queued", strings.Join(counts, ", "))
+			case queued[0].mode.ID != m.mode.ID:
+				_, style := m.modeStatusBar(queued[0].mode.ID)
+				enterHint = inlineStyle(statusBarStyle, style, i18n.T("enter: start %s", queued[0].mode.ID))
+			}
+			tagHint += " | " + enterHint
 			parts = append(parts, branchHints...)
 			if m.hasCommitTargets() {
-				parts = append(parts, "c commit", "S stash")
+				parts = append(parts, i18n.T("c commit"), i18n.T("S stash"))
 			}
 			if m.hasStashTargets() {
-				parts = append(parts, "O pop", "D drop")
+				parts = append(parts, i18n.T("O pop"), i18n.T("D drop"))
 			}
 			parts = append(parts, worktreeHints...)
 			parts = append(parts, tagHint)
 			center = strings.Join(parts, " | ")
 		} else if m.activeForcePrompt == nil && m.activeCredentialPrompt == nil {
-			parts := []string{"f fetch", "p pull", "P push"}
+			parts := stateHints(focusRepo)
 			parts = append(parts, branchHints...)
 			if m.hasCommitTargets() {
-				parts = append(parts, "c commit", "S stash")
+				parts = append(parts, i18n.T("c commit"), i18n.T("S stash"))
 			}
 			if m.hasStashTargets() {
-				parts = append(parts, "O pop", "D drop")
+				parts = append(parts, i18n.T("O pop"), i18n.T("D drop"))
 			}
 			parts = append(parts, worktreeHints...)
 			parts = append(parts, tagHint)
@@ -2194,15 +2653,19 @@ func (m *Model) renderStatusBar() string {
 		// Keep linked worktrees in their dedicated neutral state even when local
 		// file changes are present; remote actions are intentionally disabled.
 	} else if failed {
-		hasMessage := focusRepo != nil && focusRepo.State != nil && focusRepo.State.Message != ""
-		message := "Operation failed"
+		hasMessage := focusRepo != nil && focusRepo.State != nil && focusRepo.State.Message() != ""
+		message := i18n.T("Operation failed")
 		if hasMessage {
-			message = truncateString(singleLineMessage(focusRepo.State.Message), totalWidth)
+			message = truncateString(singleLineMessage(focusRepo.State.Message()), totalWidth)
 		}
 		if noUpstream {
 			statusBarStyle = m.styles.StatusBarDisabled
-			left = " no upstream"
-			right = "TAB: lazygit"
+			left = " " + i18n.T("no upstream")
+			right = i18n.T("TAB: lazygit")
+			if !focusRepo.HasRemote() {
+				left = " " + i18n.T("no remote")
+				right = i18n.T("r: add remote | TAB: lazygit")
+			}
 			rightWidth = lipgloss.Width(right)
 			maxCenter := totalWidth - lipgloss.Width(left) - rightWidth - 2
 			if maxCenter < 0 {
@@ -2211,10 +2674,10 @@ func (m *Model) renderStatusBar() string {
 			center = truncateString(message, maxCenter)
 		} else if requiresCredentials {
 			statusBarStyle = m.styles.StatusBarCredentials
-			left = " credentials required"
-			right = "enter: provide | TAB: lazygit"
+			left = " " + i18n.T("credentials required")
+			right = i18n.T("enter: provide | TAB: lazygit")
 			if hasMessage {
-				right = "enter: provide | c: clear | TAB: lazygit"
+				right = i18n.T("enter: provide | c: clear | TAB: lazygit")
 			}
 			rightWidth = lipgloss.Width(right)
 			maxCenter := totalWidth - lipgloss.Width(left) - rightWidth - 2
@@ -2224,11 +2687,13 @@ func (m *Model) renderStatusBar() string {
 			center = truncateString(message, maxCenter)
 		} else {
 			statusBarStyle = m.styles.StatusBarError
-			left = " repo failed"
-			if hasMessage {
-				right = "c: clear | TAB: lazygit"
+			left = " " + i18n.T("repo failed")
+			if repoBlockedByLock(focusRepo) {
+				right = i18n.T("K: remove lock | c: clear | TAB: lazygit")
+			} else if hasMessage {
+				right = i18n.T("c: clear | TAB: lazygit")
 			} else {
-				right = "TAB: lazygit | ? for help"
+				right = i18n.T("TAB: lazygit | ? for help")
 			}
 			rightWidth = lipgloss.Width(right)
 			maxCenter := totalWidth - lipgloss.Width(left) - rightWidth - 2
@@ -2239,22 +2704,22 @@ func (m *Model) renderStatusBar() string {
 		}
 	} else if dirty {
 		statusBarStyle = m.styles.StatusBarDisabled
-		left = " repo disabled"
-		parts := []string{"working tree has conflicting changes"}
+		left = " " + i18n.T("repo disabled")
+		parts := []string{i18n.T("working tree has conflicting changes")}
 		parts = append(parts, branchHints...)
 		if m.hasStashTargets() {
-			parts = append(parts, "O: pop stash", "D: drop stash")
+			parts = append(parts, i18n.T("O: pop stash"), i18n.T("D: drop stash"))
 		}
 		parts = append(parts, worktreeHints...)
 		center = strings.Join(parts, " | ")
-		right = "TAB: lazygit"
+		right = i18n.T("TAB: lazygit")
 	} else if hasLocalChanges {
 		statusBarStyle = m.styles.StatusBarLocalChanges
-		left = " ~ local changes"
-		parts := []string{"c: commit", "S: stash"}
+		left = " ~ " + i18n.T("local changes")
+		parts := []string{i18n.T("c: commit"), i18n.T("S: stash")}
 		parts = append(parts, branchHints...)
 		if m.hasStashTargets() {
-			parts = append(parts, "O: pop stash", "D: drop stash")
+			parts = append(parts, i18n.T("O: pop stash"), i18n.T("D: drop stash"))
 		}
 		parts = append(parts, worktreeHints...)
 		center = strings.Join(parts, " | ")
@@ -2265,20 +2730,43 @@ func (m *Model) renderStatusBar() string {
 			maxCenter = 0
 		}
 		center = truncateString(formatErrorForDisplay(m.err), maxCenter)
+	} else if m.notice != "" {
+		maxCenter := totalWidth - leftWidth - rightWidth - 2
+		if maxCenter < 0 {
+			maxCenter = 0
+		}
+		center = truncateString(m.notice, maxCenter)
 	}
 	if m.activeForcePrompt != nil && m.activeForcePrompt.repo != nil {
 		statusBarStyle = m.styles.StatusBarPush
 		repoName := truncateString(m.activeForcePrompt.repo.Name, 20)
-		left = fmt.Sprintf(" %s %s push failed", pushSymbol, repoName)
-		center = "Retry push with --force?"
-		right = "return: confirm | esc: cancel"
+		left = " " + i18n.T("%s %s push failed", pushSymbol, repoName)
+		center = i18n.T("Retry push with --force?")
+		right = i18n.T("return: confirm | esc: cancel")
+	}
+	if m.activeLockPrompt != nil && m.activeLockPrompt.repo != nil {
+		statusBarStyle = m.styles.StatusBarError
+		left = " " + i18n.T("%s locked", truncateString(m.activeLockPrompt.repo.Name, 20))
+		center = m.lockPromptText()
+		right = i18n.T("return: confirm | esc: cancel")
+	}
+	if m.autoModePromptActive {
+		left = " " + modeSymbol + " " + i18n.T("auto mode")
+		center = m.autoModePromptText()
+		right = i18n.T("return: start | esc: cancel")
+	}
+	if m.activeCheckoutPrompt != nil {
+		statusBarStyle = m.styles.StatusBarLocalChanges
+		left = " ~ " + i18n.T("local changes")
+		center = m.checkoutPromptText()
+		right = i18n.T("return: stash | esc: abort")
 	}
 
-	if m.sidePanel != NonePanel && m.activeCredentialPrompt == nil && m.activeForcePrompt == nil {
+	if m.sidePanel != NonePanel && m.activeCredentialPrompt == nil && m.activeForcePrompt == nil && m.activeLockPrompt == nil && m.activeCheckoutPrompt == nil {
 		if right == "" {
-			right = "esc: back"
-		} else if !strings.Contains(strings.ToLower(right), "esc: back") {
-			right = right + " | esc: back"
+			right = i18n.T("esc: back")
+		} else if !strings.Contains(right, i18n.T("esc: back")) {
+			right = right + " | " + i18n.T("esc: back")
 		}
 	}
 
@@ -2301,46 +2789,35 @@ func (m *Model) renderStatusBar() string {
 	if lipgloss.Width(statusText) > totalWidth {
 		statusText = left + strings.Repeat(" ", max(0, totalWidth-leftWidth-rightWidth-1)) + right
 	}
+	// The compact layout has no room for the hints in the center, and maybe
+	// not even for the ones on the right; the state on the left matters more.
+	if m.compactLayout() && lipgloss.Width(statusText) > totalWidth-2 {
+		if leftWidth+rightWidth+1 > totalWidth-2 {
+			statusText = truncateString(left, max(totalWidth-2, 0))
+		} else {
+			statusText = left + strings.Repeat(" ", totalWidth-2-leftWidth-rightWidth) + right
+		}
+	}
 
 	return statusBarStyle.Width(totalWidth).Render(statusText)
 }
 
-// renderHelp renders the help screen
-func (m *Model) renderHelp() string {
-	help := `
-Navigation:  up/k up   g/Home top        down/j down  G/End bottom
-             PgUp/Ctrl+B page up         PgDn/Ctrl+F  page down
-             Ctrl+U half page up         Ctrl+D half page down
-             left/h scroll left          right/l scroll right
-
-Actions:     Space   tag/untag repo      Enter   process tagged
-             a       tag all             A       untag all
-             m       cycle mode          Tab     open lazygit
-
-Views:       b  branches           s  status       r  remotes
-             B  expand branches    W  worktrees    R  refresh
-             ESC back
-
-Sorting:     t  toggle name/time
-
-Git:         f  fetch repo   p  pull repo   P  push repo
-             n  new branch / worktree       d  delete worktree
-             L  lock/unlock worktree        X  prune stale worktrees
-             c  commit / clear error        S  stash
-             O  pop stash    D  drop stash
-
-Other:       ?  help         q/Ctrl+C  quit
-`
-
-	title := m.styles.PanelTitle.Render("Help")
-	body := lipgloss.JoinVertical(lipgloss.Left, title, m.styles.Help.Render(help))
-
-	panelWidth := 68
-	if m.width > 0 && panelWidth > m.width-4 {
-		panelWidth = m.width - 4
+// gitVersionSummary names the installed git and the features that fall back
+// because it is too old.
+func gitVersionSummary() string {
+	v, err := git.InstalledVersion()
+	if err != nil {
+		return i18n.T("git version unknown")
 	}
-
-	return m.styles.Panel.Width(panelWidth).Render(body)
+	summary := "git " + v.String()
+	var degraded []string
+	for _, feature := range git.DegradedFeatures() {
+		degraded = append(degraded, feature.Name)
+	}
+	if len(degraded) > 0 {
+		summary += " " + i18n.T("(fallbacks for %s; see gitbatch doctor)", strings.Join(degraded, ", "))
+	}
+	return summary
 }
 
 func (m *Model) renderCommitPrompt() string {
@@ -2363,9 +2840,9 @@ func (m *Model) renderCommitPrompt() string {
 	repoCount := len(m.commitPromptRepos)
 	var title string
 	if repoCount == 1 && m.commitPromptRepos[0] != nil {
-		title = fmt.Sprintf("Commit in %s", truncateString(m.commitPromptRepos[0].Name, contentWidth-10))
+		title = i18n.T("Commit in %s", truncateString(m.commitPromptRepos[0].Name, contentWidth-10))
 	} else {
-		title = fmt.Sprintf("Commit in %d repos", repoCount)
+		title = i18n.T("Commit in %d repos", repoCount)
 	}
 
 	// Commit message field
@@ -2392,21 +2869,25 @@ func (m *Model) renderCommitPrompt() string {
 		descLines = []string{""}
 	}
 
-	lines := []string{
-		m.styles.PanelTitle.Render(title),
+	lines := []string{m.styles.PanelTitle.Render(title)}
+	if protected := m.protectedCommitRepos(); len(protected) > 0 {
+		warning := i18n.T("protected branch in %s, changes should go through a pull request", strings.Join(protected, ", "))
+		lines = append(lines, m.styles.Error.Render(truncateString(warning, contentWidth)))
+	}
+	lines = append(lines,
 		"",
-		fmt.Sprintf("%s Summary:     %s", msgIndicator, msgDisplay),
+		msgIndicator+" "+i18n.T("Summary:     %s", msgDisplay),
 		"",
-		fmt.Sprintf("%s Description:", descIndicator),
-	}
+		descIndicator+" "+i18n.T("Description:"),
+	)
 	for _, dl := range descLines {
 		lines = append(lines, fmt.Sprintf("  %s", dl))
 	}
 	var hint string
 	if m.commitPromptField == commitFieldMessage {
-		hint = "enter: commit | tab: switch field | esc: cancel"
+		hint = i18n.T("enter: commit | tab: switch field | esc: cancel")
 	} else {
-		hint = "tab: switch field | esc: cancel"
+		hint = i18n.T("tab: switch field | esc: cancel")
 	}
 	lines = append(lines,
 		"",
@@ -2435,9 +2916,9 @@ func (m *Model) renderBranchPrompt() string {
 	}
 
 	repoCount := len(m.branchPromptRepos)
-	title := fmt.Sprintf("Create branch in %d repos", repoCount)
+	title := i18n.T("Create branch in %d repos", repoCount)
 	if repoCount == 1 && m.branchPromptRepos[0] != nil {
-		title = fmt.Sprintf("Create branch in %s", truncateString(m.branchPromptRepos[0].Name, contentWidth-17))
+		title = i18n.T("Create branch in %s", truncateString(m.branchPromptRepos[0].Name, contentWidth-17))
 	}
 
 	branchDisplay := m.branchNameBuffer
@@ -2448,10 +2929,12 @@ func (m *Model) renderBranchPrompt() string {
 	lines := []string{
 		m.styles.PanelTitle.Render(title),
 		"",
-		fmt.Sprintf("> Branch: %s", branchDisplay),
-		"",
-		"enter: create | esc: cancel",
+		"> " + i18n.T("Branch: %s", branchDisplay),
 	}
+	if m.branchStartPoint != "" {
+		lines = append(lines, "  "+i18n.T("From:   %s", m.branchStartPoint))
+	}
+	lines = append(lines, "", i18n.T("enter: create | esc: cancel"))
 
 	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
 }
@@ -2491,12 +2974,132 @@ func (m *Model) renderWorktreePrompt() string {
 	}
 
 	lines := []string{
-		m.styles.PanelTitle.Render(fmt.Sprintf("Create worktree in %s", truncateString(m.worktreePromptRepo.Name, contentWidth-20))),
+		m.styles.PanelTitle.Render(i18n.T("Create worktree in %s", truncateString(m.worktreePromptRepo.Name, contentWidth-20))),
+		"",
+		branchIndicator + " " + i18n.T("Branch: %s", branchDisplay),
+		pathIndicator + " " + i18n.T("Path:   %s", pathDisplay),
+		"",
+		i18n.T("enter: next/create | tab: switch field | esc: cancel"),
+	}
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
+
+func (m *Model) renderRemotePrompt() string {
+	if !m.remotePromptActive || m.remotePromptRepo == nil {
+		return ""
+	}
+
+	panelWidth := 60
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+	if contentWidth < 10 {
+		contentWidth = 10
+	}
+
+	nameIndicator := " "
+	urlIndicator := " "
+	if m.remotePromptField == remoteFieldName {
+		nameIndicator = ">"
+	} else {
+		urlIndicator = ">"
+	}
+
+	nameDisplay := m.remoteNameBuffer
+	if len(nameDisplay) > contentWidth-2 {
+		nameDisplay = nameDisplay[len(nameDisplay)-contentWidth+2:]
+	}
+	urlDisplay := m.remoteURLBuffer
+	if len(urlDisplay) > contentWidth-2 {
+		urlDisplay = urlDisplay[len(urlDisplay)-contentWidth+2:]
+	}
+
+	lines := []string{
+		m.styles.PanelTitle.Render(i18n.T("Add remote to %s", truncateString(m.remotePromptRepo.Name, contentWidth-14))),
+		"",
+		nameIndicator + " " + i18n.T("Name: %s", nameDisplay),
+		urlIndicator + " " + i18n.T("URL:  %s", urlDisplay),
+		"",
+		i18n.T("enter: next/add | tab: switch field | esc: cancel"),
+	}
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
+
+func (m *Model) renderRebaseOntoPrompt() string {
+	if !m.rebaseOntoPromptActive || len(m.rebaseOntoRepos) == 0 {
+		return ""
+	}
+
+	panelWidth := 60
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+	if contentWidth < 10 {
+		contentWidth = 10
+	}
+
+	ontoIndicator := " "
+	upstreamIndicator := " "
+	if m.rebaseOntoField == rebaseOntoFieldOnto {
+		ontoIndicator = ">"
+	} else {
+		upstreamIndicator = ">"
+	}
+
+	target := m.rebaseOntoRepos[0].Name
+	if len(m.rebaseOntoRepos) > 1 {
+		target = i18n.T("%d repos", len(m.rebaseOntoRepos))
+	}
+	lines := []string{
+		m.styles.PanelTitle.Render(i18n.T("Rebase --onto in %s", truncateString(target, contentWidth-17))),
+		"",
+		ontoIndicator + " " + i18n.T("Onto (new base): %s", truncateString(m.rebaseOntoBuffer, contentWidth-19)),
+		upstreamIndicator + " " + i18n.T("Upstream (old):  %s", truncateString(m.rebaseUpstreamBuffer, contentWidth-19)),
+		"",
+		i18n.T("Commits after upstream move onto the new base."),
+		"",
+		i18n.T("enter: next/rebase | tab: switch field | esc: cancel"),
+	}
+	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
+}
+
+func (m *Model) renderComparePrompt() string {
+	if !m.comparePromptActive {
+		return ""
+	}
+
+	panelWidth := 60
+	if m.width > 0 && m.width-4 < panelWidth {
+		panelWidth = m.width - 4
+	}
+	if panelWidth < 30 {
+		panelWidth = 30
+	}
+	contentWidth := panelWidth - 4
+	if contentWidth < 10 {
+		contentWidth = 10
+	}
+
+	refDisplay := m.compareRefBuffer
+	if len(refDisplay) > contentWidth-7 {
+		refDisplay = refDisplay[len(refDisplay)-contentWidth+7:]
+	}
+
+	lines := []string{
+		m.styles.PanelTitle.Render(i18n.T("Compare all repositories against")),
 		"",
-		fmt.Sprintf("%s Branch: %s", branchIndicator, branchDisplay),
-		fmt.Sprintf("%s Path:   %s", pathIndicator, pathDisplay),
+		"> " + i18n.T("Ref: %s", refDisplay),
 		"",
-		"enter: next/create | tab: switch field | esc: cancel",
+		i18n.T("e.g. origin/main or a tag; leave empty to compare with upstream"),
+		i18n.T("enter: compare | esc: cancel"),
 	}
 	return m.styles.Panel.Width(panelWidth).Render(strings.Join(lines, "\n"))
 }
@@ -2527,17 +3130,7 @@ func (m *Model) renderCredentialPrompt() string {
 	}
 	server := ""
 	if prompt.repo != nil && prompt.repo.State != nil && prompt.repo.State.Remote != nil && len(prompt.repo.State.Remote.URL) > 0 {
-		server = prompt.repo.State.Remote.URL[0]
-		server = strings.TrimPrefix(server, "https://")
-		server = strings.TrimPrefix(server, "http://")
-		server = strings.TrimPrefix(server, "ssh://")
-		server = strings.TrimPrefix(server, "git@")
-		if idx := strings.Index(server, "/"); idx != -1 {
-			server = server[:idx]
-		}
-		if idx := strings.Index(server, ":"); idx != -1 {
-			server = server[:idx]
-		}
+		server = git.RemoteHost(prompt.repo.State.Remote.URL[0])
 	}
 
 	panelWidth := m.width
@@ -2557,6 +3150,12 @@ func (m *Model) renderCredentialPrompt() string {
 		passwordLen = len([]rune(m.credentialInputBuffer))
 	}
 	passwordDisplay := strings.Repeat("*", passwordLen)
+	if m.credentialReveal {
+		passwordDisplay = prompt.password
+		if m.credentialInputField == credentialFieldPassword {
+			passwordDisplay = m.credentialInputBuffer
+		}
+	}
 	usernameIndicator := " "
 	passwordIndicator := " "
 	if m.credentialInputField == credentialFieldUsername {
@@ -2564,19 +3163,44 @@ func (m *Model) renderCredentialPrompt() string {
 	} else {
 		passwordIndicator = ">"
 	}
+	title := "Credentials required for %s"
+	secretLabel := "Password"
+	if prompt.passphrase {
+		title = "SSH key passphrase required for %s"
+		secretLabel = "Passphrase"
+	}
 	lines := []string{
-		fmt.Sprintf("Credentials required for %s", truncateString(repoName, contentWidth)),
+		fmt.Sprintf(title, truncateString(repoName, contentWidth)),
 	}
 	if server != "" {
 		lines = append(lines, fmt.Sprintf("Server: %s", truncateString(server, contentWidth)))
+		if waiting := heldJobs(server); waiting > 0 {
+			lines = append(lines, truncateString(fmt.Sprintf("%d more jobs on this server wait for these credentials", waiting), contentWidth))
+		}
+	}
+	lines = append(lines, "")
+	if !prompt.passphrase {
+		lines = append(lines, fmt.Sprintf("%s Username: %s", usernameIndicator, truncateString(usernameDisplay, contentWidth-11)))
 	}
 	lines = append(lines,
+		fmt.Sprintf("%s %s: %s", passwordIndicator, secretLabel, truncateString(passwordDisplay, contentWidth-len(secretLabel)-3)),
 		"",
-		fmt.Sprintf("%s Username: %s", usernameIndicator, truncateString(usernameDisplay, contentWidth-11)),
-		fmt.Sprintf("%s Password: %s", passwordIndicator, truncateString(passwordDisplay, contentWidth-11)),
-		"",
-		"enter: submit | esc: cancel",
 	)
+	if m.credentialError != "" {
+		lines = append(lines, m.styles.Error.Render(truncateString(m.credentialError, contentWidth)), "")
+	}
+	if guidance, ok := m.activeCredentialGuidance(); ok {
+		lines = append(lines, guidance.text)
+		if guidance.tokenURL != "" {
+			lines = append(lines, truncateString("ctrl+o: open "+guidance.tokenURL, contentWidth))
+		}
+		lines = append(lines, "")
+	}
+	revealHint := "ctrl+r: show " + strings.ToLower(secretLabel)
+	if m.credentialReveal {
+		revealHint = "ctrl+r: hide " + strings.ToLower(secretLabel)
+	}
+	lines = append(lines, truncateString("enter: submit | "+revealHint+" | ctrl+u: clear | esc: cancel", contentWidth))
 	content := strings.Join(lines, "\n")
 	return m.styles.Panel.Width(panelWidth).Render(content)
 }
@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thorstenhirsch/gitbatch/internal/git"
+)
+
+// compactLayoutWidth is the terminal width below which the overview table
+// collapses into a single column with the status glyph, the repository name
+// and its ahead/behind counts, e.g. in a tmux side pane.
+const compactLayoutWidth = 50
+
+func (m *Model) compactLayout() bool {
+	return m.width < compactLayoutWidth
+}
+
+// compactColumnWidths spans the single column of the compact layout across
+// the terminal, inside the left and right table borders.
+func compactColumnWidths(totalWidth int) columnWidths {
+	return columnWidths{repo: max(totalWidth-2, 0), compact: true}
+}
+
+// renderCompactLine renders a row of the compact layout. right is aligned to
+// the right edge and survives truncation of a long label.
+func (m *Model) renderCompactLine(visual repoVisualState, selected bool, label, right string, colWidths columnWidths) string {
+	cursor := " "
+	if selected {
+		cursor = "→"
+	}
+	right = strings.TrimSpace(right)
+	bodyWidth := max(colWidths.repo-repoColPrefixWidth, 0)
+	body := renderRepoColumnBody(label, bodyWidth, right, lipgloss.Width(right))
+	column := m.applyUnselectedColumnStyle(
+		fmt.Sprintf("%s %s %s", cursor, visual.statusIcon, body),
+		selected, visual.requiresCredentials, visual.hasLocalChanges, visual.dirty, visual.failed, visual.noUpstream,
+	)
+	if selected {
+		column = m.selectedHighlightForVisual(visual).Render(column)
+	} else {
+		column = visual.style.Render(column)
+	}
+	border := m.styles.TableBorder.Render("│")
+	return border + column + border
+}
+
+func (m *Model) renderCompactRepositoryLine(r *git.Repository, selected bool, colWidths columnWidths) string {
+	return m.renderCompactLine(m.repoVisualStateFor(r), selected, repoDisplayName(r), m.branchSyncSuffix(r), colWidths)
+}
+
+// renderCompactBranchLine renders an expanded or switcher branch below its
+// repository, indented instead of placed in a branch column.
+func (m *Model) renderCompactBranchLine(style lipgloss.Style, marker string, branch *git.Branch, colWidths columnWidths) string {
+	width := max(colWidths.repo, 0)
+	label := "  " + marker + " " + branch.Name
+	right := strings.TrimSpace(syncSuffix(branch))
+	body := renderRepoColumnBody(label, width-1, right, lipgloss.Width(right))
+	border := m.styles.TableBorder.Render("│")
+	return border + style.Render(fmt.Sprintf("%-*s", width, body+" ")) + border
+}
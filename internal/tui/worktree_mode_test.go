@@ -79,7 +79,7 @@ func TestOverviewRows_WorktreeModeRendersWorktreeLabelWithoutBranchSuffix(t *tes
 func TestRenderWorktreeLine_PrimaryWorktreeShowsDiffAndMainLabel(t *testing.T) {
 	primary := testRepoWithWorktree("app", "/repos/app", "/repos/app/.git", "main", true)
 	linked := testRepoWithWorktree("app-feature", "/worktrees/app-feature", "/repos/app/.git", "feature/demo", false)
-	primary.State.Message = "1 file changed, 3 insertions(+), 1 deletion(-)"
+	primary.State.SetMessage("1 file changed, 3 insertions(+), 1 deletion(-)")
 
 	model := Model{
 		repositories: []*git.Repository{primary, linked},
@@ -160,7 +160,7 @@ func TestRenderWorktreeLine_LinkedWorktreeShowsAheadIndicatorAndKeepsTrimmedLabe
 func TestRenderWorktreeLine_SelectedPrimaryWorktreeUsesPlainDiffContent(t *testing.T) {
 	primary := testRepoWithWorktree("app", "/repos/app", "/repos/app/.git", "main", true)
 	primary.State.Branch.HasLocalChanges = true
-	primary.State.Message = "1 file changed, 3 insertions(+), 1 deletion(-)"
+	primary.State.SetMessage("1 file changed, 3 insertions(+), 1 deletion(-)")
 
 	model := Model{
 		repositories: []*git.Repository{primary},
@@ -307,7 +307,7 @@ func TestDeleteSelectedWorktreeCmd_BlocksMainWorktree(t *testing.T) {
 
 	cmd := model.deleteSelectedWorktreeCmd()
 	require.Nil(t, cmd)
-	require.Equal(t, "cannot delete [main] worktree", primary.State.Message)
+	require.Equal(t, "cannot delete [main] worktree", primary.State.Message())
 }
 
 func TestDeleteSelectedWorktreeCmd_RefreshesPrimaryRepository(t *testing.T) {
@@ -2,7 +2,6 @@ package watch
 
 import (
 	"os"
-	"path/filepath"
 	"sync"
 	"time"
 
@@ -13,7 +12,7 @@ import (
 // pollEntry holds per-repository state for the polling watcher.
 type pollEntry struct {
 	repo   *git.Repository
-	gitDir string
+	paths  []string
 	mtimes map[string]time.Time
 	timer  *time.Timer
 }
@@ -47,16 +46,15 @@ func (pw *pollingWatcher) register(r *git.Repository) {
 	if r == nil || r.AbsPath == "" {
 		return
 	}
-	gitDir := filepath.Join(r.AbsPath, ".git")
-	info, err := os.Stat(gitDir)
-	if err != nil || !info.IsDir() {
+	gitDir, commonGitDir, ok := gitDirs(r)
+	if !ok {
 		return
 	}
+	paths := trackedGitPaths(gitDir, commonGitDir)
 
 	// Stat initial file times before taking the lock to avoid I/O under lock.
-	initial := make(map[string]time.Time, len(trackedGitFiles))
-	for _, f := range trackedGitFiles {
-		path := filepath.Join(gitDir, f)
+	initial := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
 		if fi, err := os.Stat(path); err == nil {
 			initial[path] = fi.ModTime()
 		}
@@ -72,7 +70,7 @@ func (pw *pollingWatcher) register(r *git.Repository) {
 	}
 	pw.entries[r] = &pollEntry{
 		repo:   r,
-		gitDir: gitDir,
+		paths:  paths,
 		mtimes: initial,
 	}
 }
@@ -137,9 +135,8 @@ func (pw *pollingWatcher) checkEntry(e *pollEntry) {
 	}
 
 	// Stat files outside the lock.
-	results := make([]statResult, 0, len(trackedGitFiles))
-	for _, f := range trackedGitFiles {
-		path := filepath.Join(e.gitDir, f)
+	results := make([]statResult, 0, len(e.paths))
+	for _, path := range e.paths {
 		fi, err := os.Stat(path)
 		if err != nil {
 			continue
@@ -15,6 +15,7 @@ package watch
 import (
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -35,6 +36,35 @@ var trackedGitFiles = []string{
 	"HEAD", "index", "FETCH_HEAD", "ORIG_HEAD", "MERGE_HEAD", "packed-refs", "config",
 }
 
+// sharedGitFiles are the tracked files a linked worktree shares with the main
+// worktree; they live in the common git directory.
+var sharedGitFiles = map[string]struct{}{"packed-refs": {}, "config": {}}
+
+// gitDirs returns the git directory and the common git directory of r, which
+// differ from <worktree>/.git for linked worktrees, submodules and separated
+// git directories. It reports false when r has no git directory.
+func gitDirs(r *git.Repository) (gitDir, commonGitDir string, ok bool) {
+	gitDir, commonGitDir = r.GitDirs()
+	info, err := os.Stat(gitDir)
+	if err != nil || !info.IsDir() {
+		return "", "", false
+	}
+	return gitDir, commonGitDir, true
+}
+
+// trackedGitPaths returns the paths of the tracked files of a repository.
+func trackedGitPaths(gitDir, commonGitDir string) []string {
+	paths := make([]string, 0, len(trackedGitFiles))
+	for _, f := range trackedGitFiles {
+		dir := gitDir
+		if _, shared := sharedGitFiles[f]; shared {
+			dir = commonGitDir
+		}
+		paths = append(paths, filepath.Join(dir, f))
+	}
+	return paths
+}
+
 // trackedGitFilesSet is the map form of trackedGitFiles for O(1) lookup in
 // the fsnotify event handler.
 var trackedGitFilesSet = func() map[string]struct{} {
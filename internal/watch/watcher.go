@@ -15,15 +15,16 @@ import (
 
 // fsEntry holds per-repository state for the fsnotify watcher.
 type fsEntry struct {
-	repo   *git.Repository
-	gitDir string
-	timer  *time.Timer
+	repo         *git.Repository
+	gitDir       string
+	commonGitDir string
+	timer        *time.Timer
 }
 
 type fsWatcher struct {
 	w       *fsnotify.Watcher
 	mu      sync.Mutex
-	byDir   map[string]*fsEntry
+	byDir   map[string][]*fsEntry
 	byRepo  map[*git.Repository]*fsEntry
 	closeCh chan struct{}
 	closed  bool
@@ -36,7 +37,7 @@ func newFSWatcher() (*fsWatcher, error) {
 	}
 	fw := &fsWatcher{
 		w:       w,
-		byDir:   make(map[string]*fsEntry),
+		byDir:   make(map[string][]*fsEntry),
 		byRepo:  make(map[*git.Repository]*fsEntry),
 		closeCh: make(chan struct{}),
 	}
@@ -48,18 +49,22 @@ func (fw *fsWatcher) register(r *git.Repository) {
 	if r == nil || r.AbsPath == "" {
 		return
 	}
-	gitDir := filepath.Join(r.AbsPath, ".git")
-	info, err := os.Stat(gitDir)
-	if err != nil || !info.IsDir() {
-		// Worktrees and submodules use a .git file pointing elsewhere — skipped for v1.
+	gitDir, commonGitDir, ok := gitDirs(r)
+	if !ok {
 		return
 	}
 
-	dirs := []string{gitDir, filepath.Join(gitDir, "refs", "heads")}
-	if entries, err := os.ReadDir(filepath.Join(gitDir, "refs", "remotes")); err == nil {
+	// Branches live in the common git directory, which linked worktrees
+	// share with the main worktree.
+	dirs := []string{gitDir}
+	if commonGitDir != gitDir {
+		dirs = append(dirs, commonGitDir)
+	}
+	dirs = append(dirs, filepath.Join(commonGitDir, "refs", "heads"))
+	if entries, err := os.ReadDir(filepath.Join(commonGitDir, "refs", "remotes")); err == nil {
 		for _, e := range entries {
 			if e.IsDir() {
-				dirs = append(dirs, filepath.Join(gitDir, "refs", "remotes", e.Name()))
+				dirs = append(dirs, filepath.Join(commonGitDir, "refs", "remotes", e.Name()))
 			}
 		}
 	}
@@ -72,17 +77,11 @@ func (fw *fsWatcher) register(r *git.Repository) {
 	if _, ok := fw.byRepo[r]; ok {
 		return // already registered
 	}
-	entry := &fsEntry{repo: r, gitDir: gitDir}
+	entry := &fsEntry{repo: r, gitDir: gitDir, commonGitDir: commonGitDir}
 	fw.byRepo[r] = entry
 	for _, d := range dirs {
-		if _, already := fw.byDir[d]; already {
-			continue
-		}
-		if err := fw.w.Add(d); err != nil {
-			// refs/heads may not exist yet on a brand-new repo — silently skip.
-			continue
-		}
-		fw.byDir[d] = entry
+		// refs/heads may not exist yet on a brand-new repo — silently skip.
+		fw.addDirLocked(d, entry)
 	}
 }
 
@@ -131,33 +130,38 @@ func (fw *fsWatcher) handle(ev fsnotify.Event) {
 	base := filepath.Base(ev.Name)
 
 	fw.mu.Lock()
-	entry, ok := fw.byDir[dir]
+	entries := append([]*fsEntry(nil), fw.byDir[dir]...)
 	fw.mu.Unlock()
-	if !ok {
-		return
-	}
-
-	// Top-level .git/ events: only react to the basenames we care about.
-	// Subdir events (refs/heads/*, refs/remotes/*) accept any change.
-	if dir == entry.gitDir {
-		if _, want := trackedGitFilesSet[base]; !want {
-			return
-		}
-	} else if ev.Op&fsnotify.Create != 0 {
-		// New subdir under a watched refs tree (e.g. refs/heads/feature/)
-		// — extend the watch so nested refs don't silently miss updates.
-		if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
-			fw.addDir(ev.Name, entry)
-		}
-	}
 
 	// .lock files appear transiently during ref updates; the real write that
 	// follows triggers us properly.
-	if strings.HasSuffix(base, ".lock") {
-		return
+	lock := strings.HasSuffix(base, ".lock")
+
+	for _, entry := range entries {
+		// Top-level git directory events: only react to the basenames we
+		// care about. Subdir events (refs/heads/*, refs/remotes/*) accept any
+		// change.
+		if dir == entry.gitDir || dir == entry.commonGitDir {
+			if _, want := trackedGitFilesSet[base]; !want {
+				continue
+			}
+			if dir != entry.gitDir {
+				// The per-worktree files of the main worktree are not ours.
+				if _, shared := sharedGitFiles[base]; !shared {
+					continue
+				}
+			}
+		} else if ev.Op&fsnotify.Create != 0 {
+			// New subdir under a watched refs tree (e.g. refs/heads/feature/)
+			// — extend the watch so nested refs don't silently miss updates.
+			if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+				fw.addDir(ev.Name, entry)
+			}
+		}
+		if !lock {
+			fw.scheduleRefresh(entry)
+		}
 	}
-
-	fw.scheduleRefresh(entry)
 }
 
 func (fw *fsWatcher) addDir(dir string, entry *fsEntry) {
@@ -166,13 +170,24 @@ func (fw *fsWatcher) addDir(dir string, entry *fsEntry) {
 	if fw.closed {
 		return
 	}
-	if _, already := fw.byDir[dir]; already {
-		return
+	fw.addDirLocked(dir, entry)
+}
+
+// addDirLocked watches dir for entry. Directories shared by several
+// worktrees are watched once and notify every one of them.
+func (fw *fsWatcher) addDirLocked(dir string, entry *fsEntry) {
+	entries, watched := fw.byDir[dir]
+	for _, e := range entries {
+		if e == entry {
+			return
+		}
 	}
-	if err := fw.w.Add(dir); err != nil {
-		return
+	if !watched {
+		if err := fw.w.Add(dir); err != nil {
+			return
+		}
 	}
-	fw.byDir[dir] = entry
+	fw.byDir[dir] = append(entries, entry)
 }
 
 func (fw *fsWatcher) scheduleRefresh(entry *fsEntry) {
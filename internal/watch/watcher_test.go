@@ -2,6 +2,7 @@ package watch
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 	"time"
@@ -11,8 +12,8 @@ import (
 	"github.com/thorstenhirsch/gitbatch/internal/gittest"
 )
 
-// TestFSWatcherRegisterNonRepo verifies non-directory .git (worktrees) is
-// silently skipped, and a missing .git is also tolerated.
+// TestFSWatcherRegisterNonRepo verifies a directory without .git is silently
+// skipped.
 func TestFSWatcherRegisterNonRepo(t *testing.T) {
 	tmp := t.TempDir()
 	repo := &git.Repository{
@@ -237,3 +238,32 @@ func TestNewWatcherUsesFSNotifyOnNativeSystem(t *testing.T) {
 	_, ok := w.(*fsWatcher)
 	require.True(t, ok, "expected fsWatcher on a native (non-container) system")
 }
+
+// TestFSWatcherFollowsLinkedWorktree verifies a linked worktree, whose .git is
+// a file, is watched through the git directory it points at.
+func TestFSWatcherFollowsLinkedWorktree(t *testing.T) {
+	helper := gittest.InitTestRepositoryFromLocal(t)
+	defer helper.CleanUp(t)
+	linkedPath := filepath.Join(t.TempDir(), "linked")
+	out, err := exec.Command("git", "-C", helper.Repository.AbsPath, "worktree", "add", "-b", "linked", linkedPath).CombinedOutput()
+	require.NoError(t, err, string(out))
+	repo := &git.Repository{AbsPath: linkedPath, State: &git.RepositoryState{}}
+	repo.SetWorkStatus(git.Available)
+
+	fw, err := newFSWatcher()
+	require.NoError(t, err)
+	defer fw.close()
+
+	fw.register(repo)
+
+	gitDir, _ := repo.GitDirs()
+	headPath := filepath.Join(gitDir, "HEAD")
+	content, err := os.ReadFile(headPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(headPath, content, 0644))
+
+	require.Eventually(t, func() bool {
+		return repo.WorkStatus() == git.Pending
+	}, fsnotifyDebounce+2*time.Second, 50*time.Millisecond,
+		"writing the HEAD of a linked worktree should trigger a Pending refresh")
+}